@@ -24,6 +24,271 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/anilist/accounts": {
+            "get": {
+                "description": "Returns every configured AniList account with whether it is logged in for write-back, plus the redirect URI to register in the AniList API client",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "anilist"
+                ],
+                "summary": "List the AniList accounts and their logins",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.AnilistAccountsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/anilist/accounts/{username}/login": {
+            "post": {
+                "description": "Returns the AniList authorization URL for a configured account. With a client secret it is the code login, which comes back to /anilist/oauth/callback; without one it is the implicit login, whose token is pasted into PUT /anilist/accounts/{username}/token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "anilist"
+                ],
+                "summary": "Start an AniList login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Configured AniList username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.AnilistLoginResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/anilist/accounts/{username}/token": {
+            "put": {
+                "description": "PUT saves a token pasted from the implicit login, after checking it belongs to the account; DELETE logs the account out (only the saved token is removed, AniList keeps the app authorized until revoked there)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "anilist"
+                ],
+                "summary": "Save or remove an AniList login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Configured AniList username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Token (PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.AnilistTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "PUT saves a token pasted from the implicit login, after checking it belongs to the account; DELETE logs the account out (only the saved token is removed, AniList keeps the app authorized until revoked there)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "anilist"
+                ],
+                "summary": "Save or remove an AniList login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Configured AniList username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Token (PUT)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.AnilistTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/anilist/oauth/callback": {
+            "get": {
+                "description": "Where AniList sends the browser back after a code login: exchanges the code for a token, checks that the logged-in account is the one the login was started for, saves the token and redirects to the config page",
+                "tags": [
+                    "anilist"
+                ],
+                "summary": "AniList login callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State from the login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    }
+                }
+            }
+        },
         "/anilist/search": {
             "get": {
                 "description": "Searches AniList by term and flags which results can be added as standalone animes",
@@ -516,49 +781,174 @@ const docTemplate = `{
                 }
             }
         },
-        "/animes/{id}/replace": {
+        "/animes/{id}/episodes/{episodeNumber}/watched": {
             "post": {
-                "description": "Deletes all existing torrents for the anime and downloads using the provided magnet link, marking all aired episodes as downloaded",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Moves the anime's progress up to the episode: a standalone anime's saved progress, or the AniList progress of every configured account that tracks it and is logged in (moving the entry to COMPLETED on the last episode with anilist_complete_on_last_episode). Progress never moves down. Watched-episode pruning runs on the next pass",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "animes"
                 ],
-                "summary": "Replace all downloaded episodes of an anime with a user-supplied batch magnet link",
+                "summary": "Mark an episode as watched",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Anime ID (AniList MediaList ID)",
+                        "description": "Anime ID (AniList media ID)",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Magnet link",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object"
-                        }
+                        "type": "integer",
+                        "description": "Episode number (1-based, as aired)",
+                        "name": "episodeNumber",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
-                        }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/daemon.WatchedResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/animes/{id}/ids": {
+            "get": {
+                "description": "Looks the AniList media id up in the offline id mapping dataset (anime-offline-database) and returns its MyAnimeList, AniDB and Kitsu ids. A site the dataset does not know is 0. 404 when the dataset is not loaded or does not have this anime.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "animes"
+                ],
+                "summary": "Get the ids of an anime on other sites",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "AniList media ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/idmap.IDs"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/animes/{id}/replace": {
+            "post": {
+                "description": "Deletes all existing torrents for the anime and downloads using the provided magnet link, marking all aired episodes as downloaded",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "animes"
+                ],
+                "summary": "Replace all downloaded episodes of an anime with a user-supplied batch magnet link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Anime ID (AniList MediaList ID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Magnet link",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
                     },
                     "405": {
                         "description": "Method Not Allowed",
@@ -689,6 +1079,53 @@ const docTemplate = `{
                 }
             }
         },
+        "/auto-subscribe/matches": {
+            "get": {
+                "description": "Evaluates every rule (enabled or not) against its AniList season and lists the matches, why each one matched, and whether the pass would add it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auto-subscribe"
+                ],
+                "summary": "What each auto-subscribe rule matches",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/api.AutoSubscribeRuleResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/check": {
             "post": {
                 "description": "Triggers a manual anime verification check",
@@ -720,7 +1157,7 @@ const docTemplate = `{
         },
         "/config": {
             "get": {
-                "description": "Returns the current daemon configuration",
+                "description": "Returns the current daemon configuration. A saved anilist_client_secret comes back as \"********\"",
                 "consumes": [
                     "application/json"
                 ],
@@ -765,7 +1202,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Updates the daemon configuration with the provided values",
+                "description": "Updates the daemon configuration with the provided values. anilist_client_secret \"********\" keeps the saved secret; \"\" clears it",
                 "consumes": [
                     "application/json"
                 ],
@@ -929,19 +1366,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/last-check": {
+        "/data-usage": {
             "get": {
-                "description": "Returns why the last automatic pass did not download episodes, aggregated per anime. ` + "`" + `problems` + "`" + ` are things that should have downloaded and did not; ` + "`" + `limits` + "`" + ` are the configuration working as configured. ` + "`" + `pass_error` + "`" + ` is non-empty when the pass itself aborted, and then both lists are empty. A clean pass answers 200 with two empty lists; a ` + "`" + `finished_at` + "`" + ` of zero means the daemon has not completed a pass yet. Manual downloads are out of scope — those report their failure in their own HTTP response.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Returns the traffic ledger: the current billing period's total, its days and the per-anime split, plus the totals of the previous periods. With anime_id every number is that anime's share. Download and upload both count toward data_cap_gb.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "status"
+                    "data-usage"
+                ],
+                "summary": "Get data usage",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restrict the numbers to one anime (AniList media ID)",
+                        "name": "anime_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get the last verification report",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -954,43 +1396,44 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/daemon.CheckReport"
+                                            "$ref": "#/definitions/api.DataUsageResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
                     "405": {
                         "description": "Method Not Allowed",
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
                     }
                 }
             }
         },
-        "/logs": {
+        "/id-mapping": {
             "get": {
-                "description": "Returns the last N lines from the daemon log file",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "GET returns what is loaded. POST downloads the dataset from id_mapping_url right away (ignoring its weekly schedule) and reloads it; without a URL it re-reads anime-offline-database.json from the config folder. A failed download keeps the previous dataset.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "logs"
-                ],
-                "summary": "Get daemon logs",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Number of lines to return (default: 1000, max: 10000)",
-                        "name": "lines",
-                        "in": "query"
-                    }
+                    "id-mapping"
                 ],
+                "summary": "Get or refresh the id mapping dataset",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1003,48 +1446,13 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/api.LogsResponse"
+                                            "$ref": "#/definitions/idmap.Status"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/notifications/webhooks/{name}/test": {
-            "post": {
-                "description": "Fires a named webhook with sample variables to verify connectivity",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "notifications"
-                ],
-                "summary": "Test a webhook preset",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Webhook preset name",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1057,8 +1465,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "502": {
+                        "description": "Bad Gateway",
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
@@ -1066,30 +1474,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/standalone-animes": {
+        "/id-mapping/refresh": {
             "post": {
-                "description": "Adds a standalone anime and immediately downloads the episodes already aired",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "GET returns what is loaded. POST downloads the dataset from id_mapping_url right away (ignoring its weekly schedule) and reloads it; without a URL it re-reads anime-offline-database.json from the config folder. A failed download keeps the previous dataset.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "standalone"
-                ],
-                "summary": "Track an anime that is not in any AniList list",
-                "parameters": [
-                    {
-                        "description": "Media ID",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.StandaloneAnimeRequest"
-                        }
-                    }
+                    "id-mapping"
                 ],
+                "summary": "Get or refresh the id mapping dataset",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1102,19 +1496,13 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/api.StandaloneAnimeAddResponse"
+                                            "$ref": "#/definitions/idmap.Status"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1127,14 +1515,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "502": {
+                        "description": "Bad Gateway",
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
@@ -1142,28 +1524,32 @@ const docTemplate = `{
                 }
             }
         },
-        "/standalone-animes/{id}": {
-            "delete": {
-                "description": "Removes the standalone record; optionally deletes the downloaded episodes",
+        "/integrations/{source}/playback": {
+            "post": {
+                "description": "Accepts a Jellyfin Webhook plugin payload (source \"jellyfin\") or a Plex webhook (source \"plex\", multipart with a \"payload\" field). A watched episode is mapped back to its record through its library path, and a standalone anime's progress moves up to it. The item's file is asked to the media server named by \"server\" (default: the first one of the source's type). Events that are not \"watched\" answer 200 with action \"ignored\"",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "standalone"
+                    "media-servers"
                 ],
-                "summary": "Stop tracking a standalone anime",
+                "summary": "Receive a playback webhook",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "AniList media ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "jellyfin or plex",
+                        "name": "source",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "boolean",
-                        "description": "Delete the downloaded episodes (default false)",
-                        "name": "delete_episodes",
+                        "type": "string",
+                        "description": "Media server name",
+                        "name": "server",
                         "in": "query"
                     }
                 ],
@@ -1171,7 +1557,19 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/daemon.PlaybackResult"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -1180,6 +1578,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
                     "405": {
                         "description": "Method Not Allowed",
                         "schema": {
@@ -1191,13 +1595,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
                     }
                 }
             }
         },
-        "/status": {
+        "/last-check": {
             "get": {
-                "description": "Returns the current status of the daemon, including last check time, error state, and disk space for the configured save path",
+                "description": "Returns why the last automatic pass did not download episodes, aggregated per anime. ` + "`" + `problems` + "`" + ` are things that should have downloaded and did not; ` + "`" + `limits` + "`" + ` are the configuration working as configured. ` + "`" + `pass_error` + "`" + ` is non-empty when the pass itself aborted, and then both lists are empty. A clean pass answers 200 with two empty lists; a ` + "`" + `finished_at` + "`" + ` of zero means the daemon has not completed a pass yet. Manual downloads are out of scope — those report their failure in their own HTTP response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1207,7 +1617,7 @@ const docTemplate = `{
                 "tags": [
                     "status"
                 ],
-                "summary": "Get daemon status",
+                "summary": "Get the last verification report",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1220,7 +1630,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/api.StatusResponse"
+                                            "$ref": "#/definitions/daemon.CheckReport"
                                         }
                                     }
                                 }
@@ -1236,19 +1646,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/torrents": {
+        "/library/audit": {
             "get": {
-                "description": "Returns a live snapshot of every torrent in the embedded client — progress, speed, ETA, peers and status — joined with the anime/episode it belongs to. Batch torrents appear once, with a null episode_number. Responds with an empty list (not an error) when no session exists yet, i.e. before completed_anime_path is configured.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Cross-checks downloaded_episodes, the library files on disk (present and still the file the torrent seeds), the torrent client's list and the unknown files and folders in completed_anime_path and its .torrents download folder. Returns the findings by category: broken_link, record_without_torrent, orphan_torrent, duplicate and orphan_file. Changes nothing. The .torrents folder is only checked with the embedded client.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "torrents"
+                    "library"
                 ],
-                "summary": "List torrents",
+                "summary": "Audit the library",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1261,10 +1668,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/api.TorrentResponse"
-                                            }
+                                            "$ref": "#/definitions/api.LibraryAuditResponse"
                                         }
                                     }
                                 }
@@ -1276,13 +1680,31 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
                     }
                 }
             }
         },
-        "/torrents/prioritize": {
+        "/library/audit/repair": {
             "post": {
-                "description": "Moves every listed torrent to the FRONT of the download queue, in the order received, and starts as many as max_concurrent_downloads allows — whatever is pushed past the limit pauses. Unknown or already-completed hashes are ignored rather than rejected: a list of episodes must not fail whole because one of them finished between the render and the click. Use this instead of N calls to /torrents/{hash}/prioritize, which would reverse the batch.",
+                "description": "Audits the library again and fixes the findings of the selected categories: broken_link clears the torrent's library paths and organizes it again (without a second completion webhook); record_without_torrent drops the record and keeps the library files, so the loop downloads the episode again if it is still wanted; orphan_torrent removes the torrent and its data; orphan_file and duplicate delete the file or folder. Findings that could not be fixed are listed with the error.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1290,17 +1712,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "torrents"
+                    "library"
                 ],
-                "summary": "Prioritize several torrents at once",
+                "summary": "Repair the library",
                 "parameters": [
                     {
-                        "description": "Info hashes, most important first",
+                        "description": "Categories to repair",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.PrioritizeRequest"
+                            "$ref": "#/definitions/api.LibraryRepairRequest"
                         }
                     }
                 ],
@@ -1308,7 +1730,19 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/daemon.LibraryRepairResult"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -1323,18 +1757,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
                     }
                 }
             }
         },
-        "/torrents/{hash}": {
-            "delete": {
-                "description": "Removes a torrent and every saved episode sharing its hash, as a single unit (the deletion boundary is the torrent, not the episode, so a batch's episodes always leave together). By default this frees both the seeding copy and the library hardlink (same inode); keep_data=true keeps both instead. block=true additionally blocks every episode in the group against automatic re-download.",
+        "/library/link-probe": {
+            "post": {
+                "description": "Tries every library_link_mode (hardlink, reflink, symlink, copy) on the library's filesystem with a small probe file and reports which ones work, so the config screen can offer only those. Creates the library and its download folder if missing, like saving the config does.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1342,35 +1788,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "torrents"
+                    "library"
                 ],
-                "summary": "Delete a torrent",
+                "summary": "Probe library link modes",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Torrent info hash",
-                        "name": "hash",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Keep the seeding copy and library hardlinks on disk (default false)",
-                        "name": "keep_data",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Block the torrent's episodes against automatic re-download (default false)",
-                        "name": "block",
-                        "in": "query"
+                        "description": "Library to probe",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.LinkProbeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.LinkProbeResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -1379,12 +1826,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
-                        }
-                    },
                     "405": {
                         "description": "Method Not Allowed",
                         "schema": {
@@ -1400,9 +1841,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/torrents/{hash}/announce": {
+        "/library/naming/preview": {
             "post": {
-                "description": "Re-announces the torrent to all trackers and DHT — the way out of \"stuck at 0 peers\". It does not override the trackers' minimum interval, so repeated calls have no extra effect.",
+                "description": "Renders library_folder_template and library_file_template against the episodes already organized into the library, without touching the disk. With library_season_folders, an entry whose series the relink has not resolved yet still shows in its own folder; likewise with library_movie_layout, a movie organized before the format was recorded shows as a series until the relink resolves it. Fields left out of the body use the saved config. Saving templates that differ from the current ones moves the library to these names in the background.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1410,23 +1851,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "torrents"
+                    "library"
                 ],
-                "summary": "Force a torrent re-announce",
+                "summary": "Preview library naming templates",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Torrent info hash",
-                        "name": "hash",
-                        "in": "path",
-                        "required": true
+                        "description": "Templates to preview",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.NamingPreviewRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.NamingPreviewResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -1435,14 +1889,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "405": {
+                        "description": "Method Not Allowed",
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
-                    "405": {
-                        "description": "Method Not Allowed",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
@@ -1450,33 +1904,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/torrents/{hash}/pause": {
-            "post": {
-                "description": "Stops a torrent. Does not block: the torrent enters the \"stopping\" state and only reaches \"stopped\" up to ~5s later, once the stop event reaches the trackers. The paused state is persisted and survives a daemon restart.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/list-changes": {
+            "get": {
+                "description": "What changed in each account's list between verification passes (added, removed, status or progress changed), newest first. Kept up to the last 500 changes",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "torrents"
+                    "list-changes"
                 ],
-                "summary": "Pause a torrent",
+                "summary": "List change feed",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of changes (default: 100, max: 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Torrent info hash",
-                        "name": "hash",
-                        "in": "path",
-                        "required": true
+                        "description": "Only this account (AniList username, or myanimelist:user / kitsu:user)",
+                        "name": "account",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/api.ListChangeResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -1485,14 +1956,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "405": {
+                        "description": "Method Not Allowed",
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
-                    "405": {
-                        "description": "Method Not Allowed",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
@@ -1500,9 +1971,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/torrents/{hash}/prioritize": {
-            "post": {
-                "description": "Moves a torrent to the FRONT of the download queue and starts it immediately, pausing the least-progressed active torrent when that would exceed max_concurrent_downloads. Nothing is lost by the demotion — rain keeps the piece bitfield across a stop. A torrent that is already downloading is a no-op; an already-completed one is a 500.",
+        "/logs": {
+            "get": {
+                "description": "Returns the last N lines from the daemon log file",
                 "consumes": [
                     "application/json"
                 ],
@@ -1510,14 +1981,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "torrents"
+                    "logs"
                 ],
-                "summary": "Prioritize a torrent",
+                "summary": "Get daemon logs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of lines to return (default: 1000, max: 10000)",
+                        "name": "lines",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.LogsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/media-servers/{name}/test": {
+            "post": {
+                "description": "Calls a configured Jellyfin, Emby or Plex server with its token and returns the name and version it reports",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "media-servers"
+                ],
+                "summary": "Test a media server connection",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Torrent info hash",
-                        "name": "hash",
+                        "description": "Media server name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
@@ -1525,12 +2042,71 @@ const docTemplate = `{
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/mediaserver.Info"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/webhooks/{name}/test": {
+            "post": {
+                "description": "Fires a named webhook with sample variables to verify connectivity",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Test a webhook preset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook preset name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
@@ -1546,13 +2122,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
                     }
                 }
             }
         },
-        "/torrents/{hash}/resume": {
+        "/standalone-animes": {
             "post": {
-                "description": "Puts a paused torrent at the BACK of the download queue and starts it if a slot is free — with max_concurrent_downloads set, resuming does not mean \"start now\" (use /prioritize for that). Re-arms the completion listener. A completed (seeding) torrent bypasses the queue.",
+                "description": "Adds a standalone anime and immediately downloads the episodes already aired",
                 "consumes": [
                     "application/json"
                 ],
@@ -1560,23 +2142,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "torrents"
+                    "standalone"
                 ],
-                "summary": "Resume a torrent",
+                "summary": "Track an anime that is not in any AniList list",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Torrent info hash",
-                        "name": "hash",
-                        "in": "path",
-                        "required": true
+                        "description": "Media ID",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.StandaloneAnimeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.StandaloneAnimeAddResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -1596,52 +2192,1064 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.SuccessResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.AniListSearchResult": {
-            "type": "object",
-            "properties": {
-                "block_reason": {
-                    "description": "BlockReason e \"\" quando o anime pode ser adicionado como avulso, senao um dos motivos de\nstandalone_guard.go. E UM CAMPO, nao quatro booleanos: os motivos sao mutuamente\nexclusivos por precedencia e o card precisa de um rotulo so. Vem do mesmo blockReason que\no POST usa para decidir o 409 — e o que faz front e back concordarem por construcao.",
-                    "type": "string"
-                },
-                "cover": {
-                    "type": "string"
-                },
-                "episodes": {
-                    "description": "Episodes e 0 quando a AniList nao sabe o total (o comum em anime ainda no ar).",
-                    "type": "integer",
-                    "example": 24
-                },
-                "format": {
-                    "type": "string",
-                    "example": "TV"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 21
-                },
-                "status": {
-                    "type": "string",
-                    "example": "RELEASING"
-                },
-                "title": {
-                    "type": "string",
-                    "example": "One Piece"
-                },
-                "year": {
-                    "type": "integer",
-                    "example": 1999
+        },
+        "/standalone-animes/{id}": {
+            "delete": {
+                "description": "Removes the standalone record; optionally deletes the downloaded episodes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standalone"
+                ],
+                "summary": "Stop tracking a standalone anime",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "AniList media ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Delete the downloaded episodes (default false)",
+                        "name": "delete_episodes",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
                 }
             }
         },
-        "api.AnimeDetailResponse": {
-            "type": "object",
-            "properties": {
+        "/standalone-animes/{id}/list": {
+            "post": {
+                "description": "Adds the standalone anime to a logged-in account's AniList list with its saved progress. The standalone record stays until a pass sees the anime in a list the daemon processes (download_statuses), and is dropped then",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standalone"
+                ],
+                "summary": "Add a standalone anime to an AniList list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "AniList media ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Account and status",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.StandaloneAnimeListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "Returns the current status of the daemon, including last check time, error state, and disk space for the configured save path",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "status"
+                ],
+                "summary": "Get daemon status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.StatusResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents": {
+            "get": {
+                "description": "Returns a live snapshot of every torrent in the embedded client — progress, speed, ETA, peers and status — joined with the anime/episode it belongs to. Batch torrents appear once, with a null episode_number. Responds with an empty list (not an error) when no session exists yet, i.e. before completed_anime_path is configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "List torrents",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/api.TorrentResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents/pause-all": {
+            "post": {
+                "description": "Stops every torrent, downloading and seeding, until resume-all — or for duration_minutes, after which everything resumes on its own. Torrents the user had already paused one by one stay paused after the resume. Survives a daemon restart. Calling it again while paused replaces the deadline.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Pause every torrent",
+                "parameters": [
+                    {
+                        "description": "Optional duration",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.PauseAllRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.PauseAllResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents/prioritize": {
+            "post": {
+                "description": "Moves every listed torrent to the FRONT of the download queue, in the order received, and starts as many as max_concurrent_downloads allows — whatever is pushed past the limit pauses. Unknown or already-completed hashes are ignored rather than rejected: a list of episodes must not fail whole because one of them finished between the render and the click. Use this instead of N calls to /torrents/{hash}/prioritize, which would reverse the batch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Prioritize several torrents at once",
+                "parameters": [
+                    {
+                        "description": "Info hashes, most important first",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PrioritizeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents/resume-all": {
+            "post": {
+                "description": "Ends a pause-all: downloads start again within max_concurrent_downloads and the seeders pause-all stopped resume. Torrents the user had paused one by one stay paused. No-op when nothing is paused.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Resume every torrent",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.PauseAllResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents/{hash}": {
+            "get": {
+                "description": "Returns one torrent — the same row as GET /torrents — plus its trackers with the last announce result of each (status, seeders, leechers, error). This is where the effect of the extra trackers shows: a dead tracker stays not_working, a live one reports seeders.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Get a torrent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Torrent info hash",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.TorrentDetailResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a torrent and every saved episode sharing its hash, as a single unit (the deletion boundary is the torrent, not the episode, so a batch's episodes always leave together). By default this frees both the seeding copy and the library hardlink (same inode); keep_data=true keeps both instead. block=true additionally blocks every episode in the group against automatic re-download.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Delete a torrent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Torrent info hash",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep the seeding copy and library hardlinks on disk (default false)",
+                        "name": "keep_data",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Block the torrent's episodes against automatic re-download (default false)",
+                        "name": "block",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents/{hash}/announce": {
+            "post": {
+                "description": "Re-announces the torrent to all trackers and DHT — the way out of \"stuck at 0 peers\". It does not override the trackers' minimum interval, so repeated calls have no extra effect. Answers with the trackers as they stand when the announce is queued: the announce itself is asynchronous, so the fresh results show up a few seconds later in GET /torrents/{hash}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Force a torrent re-announce",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Torrent info hash",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.TrackersResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents/{hash}/pause": {
+            "post": {
+                "description": "Stops a torrent. Does not block: the torrent enters the \"stopping\" state and only reaches \"stopped\" up to ~5s later, once the stop event reaches the trackers. The paused state is persisted and survives a daemon restart.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Pause a torrent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Torrent info hash",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents/{hash}/prioritize": {
+            "post": {
+                "description": "Moves a torrent to the FRONT of the download queue and starts it immediately, pausing the least-progressed active torrent when that would exceed max_concurrent_downloads. Nothing is lost by the demotion — rain keeps the piece bitfield across a stop. A torrent that is already downloading is a no-op; an already-completed one is a 500.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Prioritize a torrent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Torrent info hash",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents/{hash}/recheck": {
+            "post": {
+                "description": "Re-verifies every piece on disk against its hash and answers when that is done — seconds for an episode, minutes for a large batch. Failed pieces are downloaded again: a torrent that was seeding goes to the front of the download queue, one the user paused stays paused, and one still downloading keeps its place. Fails with 500 when the torrent has no metadata yet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Recheck a torrent's data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Torrent info hash",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.RecheckResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents/{hash}/resume": {
+            "post": {
+                "description": "Puts a paused torrent at the BACK of the download queue and starts it if a slot is free — with max_concurrent_downloads set, resuming does not mean \"start now\" (use /prioritize for that). Re-arms the completion listener. A completed (seeding) torrent bypasses the queue.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Resume a torrent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Torrent info hash",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/torrents/{hash}/trackers": {
+            "post": {
+                "description": "Adds the configured extra trackers (extra_trackers plus the list downloaded from trackers_list_url) to a torrent already in the session, skipping the ones it already has, and re-announces when any was added. New magnets get them automatically; this is for torrents added before the list was configured. Answers with how many were added and the resulting tracker list.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "torrents"
+                ],
+                "summary": "Add the extra trackers to a torrent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Torrent info hash",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.TrackersResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trials": {
+            "get": {
+                "description": "Every anime an auto-subscribe rule added, running trials first, then newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auto-subscribe"
+                ],
+                "summary": "List auto-subscribe trials",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/api.TrialResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trials/{id}/keep": {
+            "post": {
+                "description": "Ends the trial: the episode limit is lifted and the anime never expires. It stays a standalone anime",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auto-subscribe"
+                ],
+                "summary": "Keep a trial anime",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "AniList media ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.AniListSearchResult": {
+            "type": "object",
+            "properties": {
+                "block_reason": {
+                    "description": "BlockReason e \"\" quando o anime pode ser adicionado como avulso, senao um dos motivos de\ndaemon/standalone_guard.go. E UM CAMPO, nao quatro booleanos: os motivos sao mutuamente\nexclusivos por precedencia e o card precisa de um rotulo so. Vem do mesmo BlockReason que\no POST usa para decidir o 409 — e o que faz front e back concordarem por construcao.",
+                    "type": "string"
+                },
+                "cover": {
+                    "type": "string"
+                },
+                "episodes": {
+                    "description": "Episodes e 0 quando a AniList nao sabe o total (o comum em anime ainda no ar).",
+                    "type": "integer",
+                    "example": 24
+                },
+                "format": {
+                    "type": "string",
+                    "example": "TV"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 21
+                },
+                "status": {
+                    "type": "string",
+                    "example": "RELEASING"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "One Piece"
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 1999
+                }
+            }
+        },
+        "api.AnilistAccountStatus": {
+            "type": "object",
+            "properties": {
+                "expired": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "logged_in": {
+                    "type": "boolean"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "someone"
+                }
+            }
+        },
+        "api.AnilistAccountsResponse": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AnilistAccountStatus"
+                    }
+                },
+                "client_configured": {
+                    "description": "ClientConfigured is false until anilist_client_id is set: no login is possible before.",
+                    "type": "boolean"
+                },
+                "implicit": {
+                    "description": "Implicit is true when no client secret is set: the login shows a token to paste.",
+                    "type": "boolean"
+                },
+                "redirect_uri": {
+                    "description": "RedirectURI is the URL to register in the AniList API client for the code login.",
+                    "type": "string",
+                    "example": "http://localhost:8091/api/v1/anilist/oauth/callback"
+                }
+            }
+        },
+        "api.AnilistLoginResponse": {
+            "type": "object",
+            "properties": {
+                "implicit": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://anilist.co/api/v2/oauth/authorize?client_id=1\u0026response_type=code"
+                }
+            }
+        },
+        "api.AnilistTokenRequest": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.AnimeDetailResponse": {
+            "type": "object",
+            "properties": {
                 "anime_id": {
                     "description": "AnimeID e o id da MIDIA na AniList — a mesma chave usada em episodes.json e nas rotas\n/animes/{id}/*, e tambem o id do link anilist.co/anime/{id} (ver decisions.md #43).",
                     "type": "integer"
@@ -1649,137 +3257,592 @@ const docTemplate = `{
                 "cover_image": {
                     "type": "string"
                 },
-                "custom_search_query": {
+                "custom_search_query": {
+                    "type": "string"
+                },
+                "episodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AnimeEpisodeInfo"
+                    }
+                },
+                "follow_sequels": {
+                    "description": "FollowSequels e o override do anime (\"always\"/\"never\"); vazio segue o global.",
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "integer"
+                },
+                "queue_weight": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_episodes": {
+                    "type": "integer"
+                },
+                "trial": {
+                    "description": "Trial so vem enquanto o anime esta em teste de uma regra de auto-subscribe.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.TrialResponse"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.AnimeEpisodeInfo": {
+            "type": "object",
+            "properties": {
+                "airing_at": {
+                    "type": "integer"
+                },
+                "download_date": {
+                    "type": "string"
+                },
+                "episode_hash": {
+                    "description": "EpisodeHash is the saved episode's torrent info hash. It is what lets the anime detail\nscreen join in live torrent progress exactly: a batch torrent's saved episodes all carry\nthe same hash but episode_number is meaningless for matching a torrent to them (the\ntorrent itself has no single episode number), so joining by number would miss batches\nentirely. Joining by hash instead, single and batch episodes use the same path. Empty\n(and omitted via omitempty) when the episode has no saved record.",
+                    "type": "string"
+                },
+                "episode_name": {
+                    "type": "string"
+                },
+                "episode_number": {
+                    "description": "EpisodeNumber e a identidade do episodio nas rotas /animes/{id}/episodes/{episodeNumber}/*:\no id de no da AniList saiu de cena porque nao existe para episodio fora da janela de agenda\nque ela guarda (ver decisions.md #52).",
+                    "type": "integer"
+                },
+                "is_aired": {
+                    "type": "boolean"
+                },
+                "is_blocked": {
+                    "type": "boolean"
+                },
+                "is_downloaded": {
+                    "type": "boolean"
+                },
+                "is_manually_managed": {
+                    "type": "boolean"
+                },
+                "is_watched": {
+                    "type": "boolean"
+                },
+                "time_until_airing": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.AnimeInfo": {
+            "type": "object",
+            "properties": {
+                "anime_id": {
+                    "type": "integer",
+                    "example": 12345
+                },
+                "cover_image": {
+                    "type": "string"
+                },
+                "episodes_downloaded": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "episodes_pending": {
+                    "description": "EpisodesPending conta episodios ja lancados, ainda nao assistidos e ainda nao baixados.\nEpisodio assistido nunca e baixado (daemon.shouldSkipEpisode), entao ele nao pode contar\ncomo atraso — era isso que marcava meia lista como \"atrasado\" no frontend.",
+                    "type": "integer",
+                    "example": 2
+                },
+                "episodes_released": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "episodes_watched": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "is_blacklisted": {
+                    "type": "boolean"
+                },
+                "is_standalone": {
+                    "description": "IsStandalone marca os animes acompanhados pelo arquivo standalone_animes, e nao por uma\nlista da AniList. E ORIGEM, nao estado de download — por isso a tela o mostra num chip\nproprio ao lado do chip derivado, nunca dentro dele.",
+                    "type": "boolean"
+                },
+                "last_download_date": {
+                    "type": "string",
+                    "example": "2026-02-24T10:30:00Z"
+                },
+                "latest_episode_number": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Naruto"
+                },
+                "total_episodes": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "api.AutoSubscribeMatchResponse": {
+            "type": "object",
+            "properties": {
+                "average_score": {
+                    "type": "integer",
+                    "example": 78
+                },
+                "block_reason": {
+                    "description": "BlockReason e por que o passe nao o adiciona, com os mesmos valores da busca. Vazio e sem\nTrialState: entra no proximo passe, se a regra estiver ligada.",
+                    "type": "string",
+                    "enum": [
+                        "blacklist",
+                        "standalone",
+                        "tracked",
+                        "downloaded"
+                    ]
+                },
+                "cover": {
                     "type": "string"
                 },
-                "episodes": {
+                "format": {
+                    "type": "string",
+                    "example": "TV"
+                },
+                "media_id": {
+                    "type": "integer",
+                    "example": 21
+                },
+                "popularity": {
+                    "type": "integer",
+                    "example": 52000
+                },
+                "reasons": {
+                    "description": "Reasons tem um motivo por criterio configurado na regra (\"format TV\", \"score 78 \u003e= 70\").",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/api.AnimeEpisodeInfo"
+                        "type": "string"
                     }
                 },
-                "progress": {
-                    "type": "integer"
+                "title": {
+                    "type": "string",
+                    "example": "One Piece"
                 },
-                "status": {
+                "trial_state": {
+                    "description": "TrialState e o estado do trial deste anime, se ja houve um: trial, kept, expired ou left.",
+                    "type": "string",
+                    "enum": [
+                        "trial",
+                        "kept",
+                        "expired",
+                        "left"
+                    ]
+                }
+            }
+        },
+        "api.AutoSubscribeRuleResponse": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "description": "Error e a falha ao ler a temporada da AniList; Matches vem vazio.",
                     "type": "string"
                 },
-                "total_episodes": {
-                    "type": "integer"
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AutoSubscribeMatchResponse"
+                    }
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "Action TV"
+                },
+                "season": {
+                    "type": "string",
+                    "example": "SPRING"
+                },
+                "season_year": {
+                    "type": "integer",
+                    "example": 2026
+                }
+            }
+        },
+        "api.DataUsageAnime": {
+            "type": "object",
+            "properties": {
+                "anime_id": {
+                    "type": "integer",
+                    "example": 269
+                },
+                "anime_name": {
+                    "type": "string",
+                    "example": "Bleach"
+                },
+                "downloaded": {
+                    "type": "integer",
+                    "example": 1073741824
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1610612736
+                },
+                "uploaded": {
+                    "type": "integer",
+                    "example": 536870912
+                }
+            }
+        },
+        "api.DataUsageBytes": {
+            "type": "object",
+            "properties": {
+                "downloaded": {
+                    "type": "integer",
+                    "example": 1073741824
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1610612736
+                },
+                "uploaded": {
+                    "type": "integer",
+                    "example": 536870912
+                }
+            }
+        },
+        "api.DataUsageDay": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2026-10-18"
+                },
+                "downloaded": {
+                    "type": "integer",
+                    "example": 1073741824
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1610612736
+                },
+                "uploaded": {
+                    "type": "integer",
+                    "example": 536870912
+                }
+            }
+        },
+        "api.DataUsagePeriod": {
+            "type": "object",
+            "properties": {
+                "downloaded": {
+                    "type": "integer",
+                    "example": 1073741824
+                },
+                "end": {
+                    "type": "string",
+                    "example": "2026-11-01T00:00:00-03:00"
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2026-10-01T00:00:00-03:00"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1610612736
+                },
+                "uploaded": {
+                    "type": "integer",
+                    "example": 536870912
+                }
+            }
+        },
+        "api.DataUsageResponse": {
+            "type": "object",
+            "properties": {
+                "anime_id": {
+                    "type": "integer",
+                    "example": 269
+                },
+                "animes": {
+                    "description": "Animes e o ciclo atual por anime, o que mais gastou primeiro. Trafego de torrent sem\nanime (adicionado a mao) entra em Period mas em nenhuma linha daqui.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.DataUsageAnime"
+                    }
+                },
+                "billing_day": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "cap_bytes": {
+                    "description": "CapBytes e Config.DataCapGB em bytes; 0 = sem teto.",
+                    "type": "integer",
+                    "example": 107374182400
+                },
+                "cap_reached": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "days": {
+                    "description": "Days sao todos os dias do ciclo atual ate hoje, em ordem, os sem trafego inclusive.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.DataUsageDay"
+                    }
+                },
+                "period": {
+                    "$ref": "#/definitions/api.DataUsageBytes"
+                },
+                "period_end": {
+                    "description": "PeriodEnd e a virada: o teto atingido so e liberado nela.",
+                    "type": "string",
+                    "example": "2026-11-01T00:00:00-03:00"
+                },
+                "period_start": {
+                    "type": "string",
+                    "example": "2026-10-01T00:00:00-03:00"
+                },
+                "periods": {
+                    "description": "Periods sao os ultimos ciclos, o atual primeiro.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.DataUsagePeriod"
+                    }
+                }
+            }
+        },
+        "api.ErrorInfo": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.LibraryAuditResponse": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "description": "Counts has every category, zero included, so the screen can list them all.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "findings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/files.AuditFinding"
+                    }
+                }
+            }
+        },
+        "api.LibraryRepairRequest": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "broken_link",
+                        "orphan_torrent"
+                    ]
+                }
+            }
+        },
+        "api.LinkProbeRequest": {
+            "type": "object",
+            "properties": {
+                "completed_anime_path": {
+                    "type": "string",
+                    "example": "/media/Animes"
+                }
+            }
+        },
+        "api.LinkProbeResponse": {
+            "type": "object",
+            "properties": {
+                "completed_anime_path": {
+                    "type": "string",
+                    "example": "/media/Animes"
+                },
+                "current": {
+                    "description": "Current is the saved library_link_mode.",
+                    "type": "string",
+                    "example": "hardlink"
+                },
+                "modes": {
+                    "description": "Modes are every mode library_link_mode accepts.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "hardlink",
+                        "reflink",
+                        "symlink",
+                        "copy"
+                    ]
+                },
+                "supported": {
+                    "description": "Supported are the modes that worked, in the order of Modes.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "symlink",
+                        "copy"
+                    ]
                 }
             }
         },
-        "api.AnimeEpisodeInfo": {
+        "api.ListChangeResponse": {
             "type": "object",
             "properties": {
-                "airing_at": {
-                    "type": "integer"
-                },
-                "download_date": {
-                    "type": "string"
-                },
-                "episode_hash": {
-                    "description": "EpisodeHash is the saved episode's torrent info hash. It is what lets the anime detail\nscreen join in live torrent progress exactly: a batch torrent's saved episodes all carry\nthe same hash but episode_number is meaningless for matching a torrent to them (the\ntorrent itself has no single episode number), so joining by number would miss batches\nentirely. Joining by hash instead, single and batch episodes use the same path. Empty\n(and omitted via omitempty) when the episode has no saved record.",
-                    "type": "string"
+                "account": {
+                    "description": "Account e o usuario da AniList, ou \"myanimelist:usuario\"/\"kitsu:usuario\".",
+                    "type": "string",
+                    "example": "user1"
                 },
-                "episode_name": {
+                "at": {
                     "type": "string"
                 },
-                "episode_number": {
-                    "description": "EpisodeNumber e a identidade do episodio nas rotas /animes/{id}/episodes/{episodeNumber}/*:\no id de no da AniList saiu de cena porque nao existe para episodio fora da janela de agenda\nque ela guarda (ver decisions.md #52).",
-                    "type": "integer"
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "added",
+                        "removed",
+                        "status_changed",
+                        "progress_changed"
+                    ]
                 },
-                "is_aired": {
-                    "type": "boolean"
+                "media_id": {
+                    "type": "integer",
+                    "example": 21
                 },
-                "is_blocked": {
-                    "type": "boolean"
+                "new_progress": {
+                    "type": "integer",
+                    "example": 6
                 },
-                "is_downloaded": {
-                    "type": "boolean"
+                "new_status": {
+                    "type": "string",
+                    "example": "DROPPED"
                 },
-                "is_manually_managed": {
-                    "type": "boolean"
+                "old_progress": {
+                    "type": "integer",
+                    "example": 5
                 },
-                "is_watched": {
-                    "type": "boolean"
+                "old_status": {
+                    "description": "OldStatus vem vazio em \"added\", NewStatus em \"removed\".",
+                    "type": "string",
+                    "example": "CURRENT"
                 },
-                "time_until_airing": {
-                    "type": "integer"
+                "title": {
+                    "type": "string",
+                    "example": "One Piece"
                 }
             }
         },
-        "api.AnimeInfo": {
+        "api.LogsResponse": {
             "type": "object",
             "properties": {
-                "anime_id": {
-                    "type": "integer",
-                    "example": 12345
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.NamingPreviewRequest": {
+            "type": "object",
+            "properties": {
+                "library_file_template": {
+                    "type": "string",
+                    "example": "{title} - S{season:02}E{episode:02}"
                 },
-                "cover_image": {
-                    "type": "string"
+                "library_folder_template": {
+                    "type": "string",
+                    "example": "{title} ({year})"
                 },
-                "episodes_downloaded": {
-                    "type": "integer",
-                    "example": 8
+                "library_movie_layout": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "episodes_pending": {
-                    "description": "EpisodesPending conta episodios ja lancados, ainda nao assistidos e ainda nao baixados.\nEpisodio assistido nunca e baixado (daemon.shouldSkipEpisode), entao ele nao pode contar\ncomo atraso — era isso que marcava meia lista como \"atrasado\" no frontend.",
-                    "type": "integer",
-                    "example": 2
+                "library_movies_path": {
+                    "type": "string",
+                    "example": "/media/Movies"
                 },
-                "episodes_released": {
-                    "type": "integer",
-                    "example": 10
+                "library_season_folders": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "episodes_watched": {
+                "limit": {
+                    "description": "Limit caps Items; 0 means 50.",
                     "type": "integer",
-                    "example": 5
+                    "example": 50
                 },
-                "is_blacklisted": {
-                    "type": "boolean"
-                },
-                "is_standalone": {
-                    "description": "IsStandalone marca os animes acompanhados pelo arquivo standalone_animes, e nao por uma\nlista da AniList. E ORIGEM, nao estado de download — por isso a tela o mostra num chip\nproprio ao lado do chip derivado, nunca dentro dele.",
-                    "type": "boolean"
-                },
-                "last_download_date": {
-                    "type": "string",
-                    "example": "2026-02-24T10:30:00Z"
-                },
-                "latest_episode_number": {
+                "rename_files_for_jellyfin": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "api.NamingPreviewResponse": {
+            "type": "object",
+            "properties": {
+                "changed": {
                     "type": "integer",
-                    "example": 12
+                    "example": 118
                 },
-                "name": {
-                    "type": "string",
-                    "example": "Naruto"
+                "items": {
+                    "description": "Items are the files that would move first, then the ones that stay, up to limit.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/files.LibraryMove"
+                    }
                 },
-                "total_episodes": {
+                "tokens": {
+                    "description": "Tokens lists the tokens the templates accept.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "total": {
+                    "description": "Total is every organized library file; Changed, the ones the templates would move.",
                     "type": "integer",
-                    "example": 12
+                    "example": 120
                 }
             }
         },
-        "api.ErrorInfo": {
+        "api.PauseAllRequest": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "string"
-                },
-                "message": {
-                    "type": "string"
+                "duration_minutes": {
+                    "description": "DurationMinutes \u003e 0 resumes everything on its own after that long; 0 (or no body)\npauses until resume-all.",
+                    "type": "integer",
+                    "example": 120
                 }
             }
         },
-        "api.LogsResponse": {
+        "api.PauseAllResponse": {
             "type": "object",
             "properties": {
-                "lines": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "paused": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "until": {
+                    "description": "Until is null when the pause lasts until resume-all.",
+                    "type": "string"
                 }
             }
         },
@@ -1798,6 +3861,29 @@ const docTemplate = `{
                 }
             }
         },
+        "api.RecheckResponse": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "description": "Completed reports whether every piece passed.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "pieces_failed": {
+                    "description": "PiecesFailed is how many pieces the torrent had and no longer has: the ones whose bytes\non disk did not match their hash. They are being downloaded again.",
+                    "type": "integer",
+                    "example": 2
+                },
+                "pieces_have": {
+                    "type": "integer",
+                    "example": 1198
+                },
+                "pieces_total": {
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
         "api.StandaloneAnimeAddResponse": {
             "type": "object",
             "properties": {
@@ -1808,6 +3894,20 @@ const docTemplate = `{
                 }
             }
         },
+        "api.StandaloneAnimeListRequest": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "description": "Status is the list status of the new entry; \"\" is CURRENT.",
+                    "type": "string",
+                    "example": "CURRENT"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "someone"
+                }
+            }
+        },
         "api.StandaloneAnimeRequest": {
             "type": "object",
             "properties": {
@@ -1820,6 +3920,11 @@ const docTemplate = `{
         "api.StatusResponse": {
             "type": "object",
             "properties": {
+                "data_cap_reached": {
+                    "description": "DataCapReached marca o teto mensal de dados atingido: downloads e seeding parados ate a\nvirada do ciclo (GET /data-usage tem os numeros e a data).",
+                    "type": "boolean",
+                    "example": false
+                },
                 "disk_free": {
                     "type": "integer",
                     "example": 128849018880
@@ -1829,37 +3934,133 @@ const docTemplate = `{
                     "type": "boolean",
                     "example": false
                 },
-                "disk_total": {
+                "disk_total": {
+                    "type": "integer",
+                    "example": 500107862016
+                },
+                "downloads_paused": {
+                    "description": "DownloadsPaused marca uma pausa global (POST /torrents/pause-all) em vigor;\nDownloadsPausedUntil e o prazo dela, ausente quando vale ate o resume-all.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "downloads_paused_until": {
+                    "type": "string"
+                },
+                "has_error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "last_check": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.2.0"
+                }
+            }
+        },
+        "api.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error": {
+                    "$ref": "#/definitions/api.ErrorInfo"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.TorrentDetailResponse": {
+            "type": "object",
+            "properties": {
+                "anime_id": {
+                    "type": "integer",
+                    "example": 154587
+                },
+                "anime_name": {
+                    "type": "string",
+                    "example": "Sousou no Frieren"
+                },
+                "bytes_completed": {
+                    "type": "integer",
+                    "example": 524288000
+                },
+                "bytes_total": {
+                    "description": "BytesTotal is 0 until the torrent's metadata arrives.",
+                    "type": "integer",
+                    "example": 1073741824
+                },
+                "bytes_uploaded": {
+                    "type": "integer",
+                    "example": 104857600
+                },
+                "completed": {
+                    "description": "Completed is piece-derived (TorrentInfo.Completed), not Status-derived — it stays true\nfor a torrent paused after finishing, which Status alone cannot tell apart from a\npaused, unfinished one. Used to key the list sort instead of Status.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "download_speed": {
+                    "type": "integer",
+                    "example": 2097152
+                },
+                "episode_number": {
+                    "description": "EpisodeNumber is null for batch torrents (they map to several episodes).",
+                    "type": "integer"
+                },
+                "eta_seconds": {
+                    "description": "EtaSeconds is null when unknown or infinite.",
+                    "type": "integer",
+                    "example": 240
+                },
+                "hash": {
+                    "type": "string",
+                    "example": "0123456789abcdef0123456789abcdef01234567"
+                },
+                "is_batch": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "example": "[SubsPlease] Frieren - 07 (1080p).mkv"
+                },
+                "peers_total": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "progress": {
+                    "description": "Progress is 0..1: BytesCompleted/BytesTotal normally, falling back to the piece ratio\n(PiecesHave/PiecesTotal) when BytesCompleted reads 0 with a paused torrent — see\nbuildTorrentResponse. 0 while BytesTotal and PiecesTotal are both unknown.",
+                    "type": "number",
+                    "example": 0.48
+                },
+                "queue_position": {
+                    "description": "QueuePosition is the 1-based place in the download queue's waiting line; 0 means the\ntorrent is not waiting (active, completed, or paused by the user). Not a pointer: 0\nalready says \"not queued\" without ambiguity — there is no position 0.",
                     "type": "integer",
-                    "example": 500107862016
-                },
-                "has_error": {
-                    "type": "boolean",
-                    "example": false
+                    "example": 3
                 },
-                "last_check": {
-                    "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                "seeded_for_seconds": {
+                    "type": "integer",
+                    "example": 3600
                 },
                 "status": {
                     "type": "string",
-                    "example": "running"
+                    "example": "downloading"
                 },
-                "version": {
-                    "type": "string",
-                    "example": "1.2.0"
-                }
-            }
-        },
-        "api.SuccessResponse": {
-            "type": "object",
-            "properties": {
-                "data": {},
-                "error": {
-                    "$ref": "#/definitions/api.ErrorInfo"
+                "trackers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.TrackerResponse"
+                    }
                 },
-                "success": {
-                    "type": "boolean"
+                "upload_speed": {
+                    "type": "integer",
+                    "example": 524288
                 }
             }
         },
@@ -1945,93 +4146,504 @@ const docTemplate = `{
                 }
             }
         },
-        "api.animeSettingsRequest": {
+        "api.TrackerResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error is the last announce error; empty when the last announce succeeded.",
+                    "type": "string",
+                    "example": "connection refused"
+                },
+                "last_announce": {
+                    "description": "LastAnnounce and NextAnnounce are null until the first announce.",
+                    "type": "string"
+                },
+                "leechers": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "next_announce": {
+                    "type": "string"
+                },
+                "seeders": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "status": {
+                    "description": "Status is one of not_contacted, contacting, working, not_working (or unknown).",
+                    "type": "string",
+                    "example": "working"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "udp://tracker.opentrackr.org:1337/announce"
+                }
+            }
+        },
+        "api.TrackersResponse": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "description": "Added is how many extra trackers were new to the torrent. Always 0 for announce.",
+                    "type": "integer",
+                    "example": 20
+                },
+                "trackers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.TrackerResponse"
+                    }
+                }
+            }
+        },
+        "api.TrialResponse": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "ended_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt e quando o passe remove o trial; ausente quando ele nao expira.",
+                    "type": "string"
+                },
+                "media_id": {
+                    "type": "integer",
+                    "example": 21
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "Action TV"
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "trial",
+                        "kept",
+                        "expired",
+                        "left"
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                },
+                "trial_episodes": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "api.animeSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "custom_search_query": {
+                    "type": "string"
+                },
+                "follow_sequels": {
+                    "description": "FollowSequels e \"always\", \"never\" ou \"\" (segue o follow_sequels global).",
+                    "type": "string",
+                    "enum": [
+                        "always",
+                        "never",
+                        ""
+                    ]
+                },
+                "progress": {
+                    "type": "integer"
+                },
+                "queue_weight": {
+                    "description": "QueueWeight e o peso do anime na politica de fila fair_share. 0 volta ao padrao (1).",
+                    "type": "integer"
+                }
+            }
+        },
+        "daemon.AccountWriteBack": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/daemon.WriteBackAction"
+                        }
+                    ],
+                    "example": "updated"
+                },
+                "completed": {
+                    "description": "Completed is true when the entry was also moved to COMPLETED.",
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "someone"
+                }
+            }
+        },
+        "daemon.CheckReport": {
+            "type": "object",
+            "properties": {
+                "anilist_stale": {
+                    "description": "AniListStale marca o passe que rodou, no todo ou em parte, com respostas salvas porque a\nAniList nao respondeu; AniListStaleSince e de quando e a mais velha delas.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "anilist_stale_since": {
+                    "type": "string",
+                    "example": "2026-08-19T11:00:00Z"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2026-08-19T12:00:00Z"
+                },
+                "limits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/daemon.Issue"
+                    }
+                },
+                "pass_error": {
+                    "type": "string",
+                    "example": ""
+                },
+                "problems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/daemon.Issue"
+                    }
+                }
+            }
+        },
+        "daemon.Issue": {
+            "type": "object",
+            "properties": {
+                "anime_id": {
+                    "type": "integer",
+                    "example": 269
+                },
+                "anime_name": {
+                    "type": "string",
+                    "example": "Bleach"
+                },
+                "bad_pieces": {
+                    "description": "BadPieces e quantas pecas falharam na verificacao de integridade (data_corrupted). Ao\ncontrario dos outros detalhes, e SOMADO na agregacao: dois torrents do mesmo anime com 2 e\n3 pecas ruins sao 5 pecas ruins, um numero que existiu de fato.",
+                    "type": "integer",
+                    "example": 2
+                },
+                "batch_skipped": {
+                    "type": "string",
+                    "example": "no_result"
+                },
+                "candidates": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "code": {
+                    "type": "string",
+                    "example": "all_above_size_limit"
+                },
+                "downloaded": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "episodes": {
+                    "description": "Episodes so e preenchido em PROBLEMA: um problema acontece num episodio (ele foi buscado,\nos candidatos foram avaliados, aquele episodio nao baixou). O limite e o contrario — o\ndaemon parou de considerar episodios ao atingir a conta, entao nao existe \"os episodios\nafetados\", existe uma quantidade que sobrou (Downloaded/Pending).",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "limit_gb": {
+                    "type": "number",
+                    "example": 3
+                },
+                "min_seeders": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pending": {
+                    "type": "integer",
+                    "example": 35
+                }
+            }
+        },
+        "daemon.LibraryRepairFailure": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "permission denied"
+                },
+                "finding": {
+                    "$ref": "#/definitions/files.AuditFinding"
+                }
+            }
+        },
+        "daemon.LibraryRepairResult": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/daemon.LibraryRepairFailure"
+                    }
+                },
+                "repaired": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "daemon.PlaybackAction": {
+            "type": "string",
+            "enum": [
+                "progress_updated",
+                "already_watched",
+                "list_anime",
+                "not_tracked",
+                "ignored"
+            ],
+            "x-enum-varnames": [
+                "PlaybackProgressUpdated",
+                "PlaybackAlreadyWatched",
+                "PlaybackListAnime",
+                "PlaybackNotTracked",
+                "PlaybackIgnored"
+            ]
+        },
+        "daemon.PlaybackResult": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "description": "Accounts is the AniList write of a list anime, with anilist_playback_sync on.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/daemon.AccountWriteBack"
+                    }
+                },
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/daemon.PlaybackAction"
+                        }
+                    ],
+                    "example": "progress_updated"
+                },
+                "anime_id": {
+                    "type": "integer",
+                    "example": 154587
+                },
+                "anime_name": {
+                    "type": "string",
+                    "example": "Sousou no Frieren"
+                },
+                "episode_number": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "progress": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "daemon.WatchedResult": {
             "type": "object",
             "properties": {
-                "custom_search_query": {
-                    "type": "string"
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/daemon.AccountWriteBack"
+                    }
                 },
                 "progress": {
-                    "type": "integer"
+                    "type": "integer",
+                    "example": 5
+                },
+                "standalone": {
+                    "type": "boolean"
                 }
             }
         },
-        "daemon.CheckReport": {
+        "daemon.WriteBackAction": {
+            "type": "string",
+            "enum": [
+                "updated",
+                "already_watched",
+                "not_in_list",
+                "no_token",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "WriteBackUpdated",
+                "WriteBackAlreadyWatched",
+                "WriteBackNotInList",
+                "WriteBackNoToken",
+                "WriteBackFailed"
+            ]
+        },
+        "files.AccountProfile": {
             "type": "object",
             "properties": {
-                "finished_at": {
-                    "type": "string",
-                    "example": "2026-08-19T12:00:00Z"
+                "account": {
+                    "description": "Account e o usuario da AniList, ou \"myanimelist:usuario\"/\"kitsu:usuario\" — a mesma chave\nde conta do passe (lists.Account.Key).",
+                    "type": "string"
                 },
-                "limits": {
+                "delete_statuses": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/daemon.Issue"
+                        "type": "string"
                     }
                 },
-                "pass_error": {
-                    "type": "string",
-                    "example": ""
+                "delete_watched_episodes": {
+                    "type": "boolean"
                 },
-                "problems": {
+                "download_statuses": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/daemon.Issue"
+                        "type": "string"
+                    }
+                },
+                "excluded_lists": {
+                    "description": "ExcludedLists soma as listas customizadas da conta a Config.ExcludedLists: a global e da\ncasa toda, esta so do perfil.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
+                },
+                "library_subpath": {
+                    "description": "LibrarySubpath e a biblioteca do perfil, relativa a CompletedAnimePath (e a\nLibraryMoviesPath, para os filmes). \"\" divide a raiz com as contas sem perfil.",
+                    "type": "string"
+                },
+                "max_episodes_per_anime": {
+                    "description": "MaxEpisodesPerAnime, DeleteWatchedEpisodes e WatchedEpisodesToKeep valem por anime para\no conjunto de perfis que o quer: o mais folgado vence (ver daemon.configsForAnime).",
+                    "type": "integer"
+                },
+                "watched_episodes_to_keep": {
+                    "type": "integer"
                 }
             }
         },
-        "daemon.Issue": {
+        "files.AuditFinding": {
             "type": "object",
             "properties": {
                 "anime_id": {
                     "type": "integer",
-                    "example": 269
+                    "example": 154587
                 },
                 "anime_name": {
                     "type": "string",
-                    "example": "Bleach"
+                    "example": "Frieren"
                 },
-                "batch_skipped": {
+                "category": {
                     "type": "string",
-                    "example": "no_result"
+                    "example": "broken_link"
                 },
-                "candidates": {
-                    "type": "integer",
-                    "example": 8
+                "detail": {
+                    "description": "Detail diz o que esta errado, em ingles, para a tela.",
+                    "type": "string",
+                    "example": "library file is missing"
                 },
-                "code": {
+                "episode_numbers": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "hash": {
+                    "description": "Hash e o torrent envolvido, quando ha um.",
                     "type": "string",
-                    "example": "all_above_size_limit"
+                    "example": "c9e15763f722f23e98a29decdfae341b98d53056"
                 },
-                "downloaded": {
-                    "type": "integer",
-                    "example": 12
+                "path": {
+                    "description": "Path e o arquivo ou pasta do achado; vazio nos que sao so de torrent ou de registro.",
+                    "type": "string",
+                    "example": "/media/Animes/Frieren/Frieren - E05.mkv"
+                }
+            }
+        },
+        "files.AutoSubscribeRule": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "description": "Country e o countryOfOrigin da AniList (\"JP\", \"CN\", \"KR\").",
+                    "type": "string"
                 },
-                "episodes": {
-                    "description": "Episodes so e preenchido em PROBLEMA: um problema acontece num episodio (ele foi buscado,\nos candidatos foram avaliados, aquele episodio nao baixou). O limite e o contrario — o\ndaemon parou de considerar episodios ao atingir a conta, entao nao existe \"os episodios\nafetados\", existe uma quantidade que sobrou (Downloaded/Pending).",
+                "enabled": {
+                    "description": "Enabled = false deixa a regra so na previa de GET /auto-subscribe/matches: da para ver o\nque ela pegaria antes de ela adicionar qualquer coisa.",
+                    "type": "boolean"
+                },
+                "exclude_adult": {
+                    "type": "boolean"
+                },
+                "formats": {
+                    "description": "Formats e Genres sao \"qualquer um de\": TV ou ONA, Action ou Comedy.",
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "type": "string"
                     }
                 },
-                "limit_gb": {
-                    "type": "number",
-                    "example": 3
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "min_seeders": {
-                    "type": "integer",
-                    "example": 1
+                "min_popularity": {
+                    "description": "MinPopularity e o numero de usuarios da AniList com o anime em alguma lista.",
+                    "type": "integer"
                 },
-                "pending": {
-                    "type": "integer",
-                    "example": 35
+                "min_score": {
+                    "description": "MinScore e a nota media, 0..100. Anime ainda sem nota nao passa quando MinScore \u003e 0 — ele\npassa num passe seguinte, quando a nota aparecer.",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name identifica a regra na tela e no registro de cada trial. Unico e obrigatorio.",
+                    "type": "string"
+                },
+                "season": {
+                    "description": "Season (WINTER, SPRING, SUMMER, FALL) e SeasonYear escolhem a temporada. \"\" e 0 seguem a\ntemporada corrente, e a regra anda sozinha de uma temporada para a outra.",
+                    "type": "string"
+                },
+                "season_year": {
+                    "type": "integer"
+                },
+                "trial_episodes": {
+                    "description": "TrialEpisodes e quantos episodios do comeco o trial baixa. \u003e= 1.",
+                    "type": "integer"
                 }
             }
         },
         "files.Config": {
             "type": "object",
             "properties": {
+                "account_profiles": {
+                    "description": "AccountProfiles dao a uma conta da casa a sua biblioteca e os seus status e limites\n(AccountProfile). Conta sem perfil segue os campos globais, na raiz da biblioteca.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/files.AccountProfile"
+                    }
+                },
+                "anilist_client_id": {
+                    "description": "AnilistClientID e AnilistClientSecret sao o cliente de API que o usuario registra na\nAniList (Settings \u003e Developer) para o login que permite escrever na lista. Sem segredo o\nlogin e o implicito: a AniList mostra o token e o usuario o cola (decisions.md #82).",
+                    "type": "string"
+                },
+                "anilist_client_secret": {
+                    "type": "string"
+                },
+                "anilist_complete_on_last_episode": {
+                    "description": "AnilistCompleteOnLastEpisode move a entrada para COMPLETED quando o episodio marcado como\nassistido e o ultimo do anime.",
+                    "type": "boolean"
+                },
+                "anilist_playback_sync": {
+                    "description": "AnilistPlaybackSync faz o webhook de reproducao (#81) avancar tambem o progresso do anime\nde lista, na AniList, em cada conta com login. Desligado, so o avulso avanca.",
+                    "type": "boolean"
+                },
                 "anilist_username": {
                     "type": "string"
                 },
@@ -2045,12 +4657,27 @@ const docTemplate = `{
                     "description": "AnimeIDsAreMediaIDs marca que daemon.MigrateAnimeIDsToMedia ja converteu os AnimeID\ngravados de id de ENTRADA (MediaList, por conta) para id de MIDIA (ver decisions.md #43).\nO default e false de proposito: um config.json anterior a este campo desserializa por\ncima do default e precisa migrar. Numa instalacao nova a migracao roda sem nada a fazer\ne liga o campo no primeiro passe.",
                     "type": "boolean"
                 },
+                "auto_subscribe_rules": {
+                    "description": "AutoSubscribeRules adicionam como avulso em trial os animes da temporada que casam com\nelas (ver daemon.autoSubscribe).",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/files.AutoSubscribeRule"
+                    }
+                },
                 "check_interval": {
                     "type": "integer"
                 },
                 "completed_anime_path": {
                     "type": "string"
                 },
+                "data_cap_billing_day": {
+                    "description": "DataCapBillingDay e o dia do mes em que o ciclo da operadora vira, de 1 a 28 — 28 e o\nmaior dia que todo mes tem. Fora disso (config.json editado a mao) vale 1.",
+                    "type": "integer"
+                },
+                "data_cap_gb": {
+                    "description": "DataCapGB e o teto mensal de trafego, download e upload somados — e o que a operadora de\numa conexao medida cobra. Atingido, nenhum download novo e adicionado e tudo, seeding\ninclusive, fica parado ate o dia de virada (ver daemon.ApplyDataCap). 0 desliga; o\nledger de uso (data_usage) e gravado mesmo assim.",
+                    "type": "number"
+                },
                 "delete_statuses": {
                     "type": "array",
                     "items": {
@@ -2084,6 +4711,64 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "extra_trackers": {
+                    "description": "ExtraTrackers sao URLs de announce acrescentadas a TODO magnet antes do Add. Existem\nporque magnet antigo do Nyaa costuma listar so trackers mortos ha anos — boa parte do\nIssueNoSeeders de anime antigo e isso, e nao falta de gente semeando.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "follow_sequels": {
+                    "description": "FollowSequels faz o passe seguir a SEQUEL de todo anime acompanhado que terminou: ela entra\ncomo avulso assim que estiver no ar (ver daemon.followSequels). AnimeSettings.FollowSequels\nvence por anime. Opt-in: seguir e baixar uma temporada inteira que ninguem pediu.",
+                    "type": "boolean"
+                },
+                "id_mapping_url": {
+                    "description": "IDMappingURL aponta para o JSON do anime-offline-database, baixado para a pasta do config\nno maximo uma vez por semana (ver daemon.refreshIDMapping). \"\" nao baixa, mas um arquivo\nposto la a mao continua valendo.",
+                    "type": "string"
+                },
+                "integrity_check_days": {
+                    "description": "IntegrityCheckDays e de quantos em quantos dias cada torrent completo tem os dados\nre-verificados contra os hashes das pecas (ver daemon.integritySweep). Pega bit rot e\narquivo mexido por fora antes que o episodio chegue ao player corrompido. 0 desliga: a\nverificacao le o torrent inteiro, e nem todo disco aguenta isso de graca.",
+                    "type": "integer"
+                },
+                "kitsu_usernames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "library_file_template": {
+                    "type": "string"
+                },
+                "library_folder_template": {
+                    "description": "LibraryFolderTemplate e LibraryFileTemplate dao nome a pasta de cada anime e aos arquivos\nda biblioteca (tokens em naming.go). O de arquivo so vale com RenameFilesForJellyfin; sem\nele o nome cru do torrent fica. \"\" = o default, que reproduz o nome de antes dos templates.",
+                    "type": "string"
+                },
+                "library_link_mode": {
+                    "description": "LibraryLinkMode e como o episodio completo entra na biblioteca: \"hardlink\" (default),\n\"reflink\", \"symlink\" ou \"copy\" (LinkMode). Os tres ultimos existem para filesystems sem\nhardlink (exFAT, alguns shares SMB/NFS). \"\" vale hardlink.",
+                    "type": "string"
+                },
+                "library_movie_layout": {
+                    "description": "LibraryMovieLayout poe os filmes da AniList (formato MOVIE) no layout de filme do\nJellyfin: \"Titulo (Ano)/Titulo (Ano).mkv\" com movie.nfo, em LibraryMoviesPath (\"\" = dentro\nde CompletedAnimePath). Opt-in; ligar ou desligar migra os filmes ja organizados pelo\nrelink (decisions.md #79).",
+                    "type": "boolean"
+                },
+                "library_movies_path": {
+                    "type": "string"
+                },
+                "library_season_folders": {
+                    "description": "LibrarySeasonFolders junta as temporadas de uma serie (cadeia de PREQUEL da AniList) numa\npasta so, com subpastas Season NN. Opt-in: o default segue uma pasta por entrada da\nAniList (decisions.md #45 e #72).",
+                    "type": "boolean"
+                },
+                "mal_client_id": {
+                    "description": "MALClientID e o cliente da API v2 do MyAnimeList. Vazio, a lista e lida pelo endpoint\npublico da pagina de lista, que nao tem contrato e pode mudar sem aviso.",
+                    "type": "string"
+                },
+                "mal_usernames": {
+                    "description": "MALUsernames e KitsuUsernames sao contas de fora da AniList cujas listas entram no passe\njunto com as de AnilistUsernames, com os ids mapeados para a AniList (pacote lists).",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "max_batch_torrent_size_gb": {
                     "description": "MaxBatchTorrentSizeGB / MaxEpisodeTorrentSizeGB descartam da busca torrents acima do teto,\nem GiB. 0 desliga. O de pack e a guarda UNICA de pack desde que a elegibilidade deixou de\nser contagem de episodios: 100 cabe pack completo de serie de temporada em 1080p e nao cabe\npack completo de One Piece — para serie longa o que passa e pack parcial.",
                     "type": "number"
@@ -2103,6 +4788,12 @@ const docTemplate = `{
                     "description": "MaxSearchPages e o teto de paginas do Nyaa por busca. A busca desce para a pagina\nseguinte apenas enquanto tiver poucos candidatos aceitos, entao o teto e um limite, nao\num custo fixo. \u003c= 1 significa buscar so a pagina 1.",
                     "type": "integer"
                 },
+                "media_servers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/files.MediaServer"
+                    }
+                },
                 "min_free_disk_percent": {
                     "description": "MinFreeDiskPercent barra a adicao de novos torrents abaixo dessa porcentagem de espaco\nlivre no volume da biblioteca. 0 desliga.",
                     "type": "integer"
@@ -2117,14 +4808,101 @@ const docTemplate = `{
                 "priorities": {
                     "$ref": "#/definitions/nyaa.Priorities"
                 },
+                "queue_policy": {
+                    "description": "QueuePolicy e a ordem em que a fila comeca os torrents que esperam (torrents.QueuePolicy):\n\"fifo\", \"smallest_first\", \"airing_first\" ou \"fair_share\". Priorizar a mao vence qualquer\numa. \"\" (config.json editado a mao) vale fifo.",
+                    "type": "string"
+                },
                 "rename_files_for_jellyfin": {
                     "type": "boolean"
                 },
+                "sequel_lead_days": {
+                    "description": "SequelLeadDays antecipa o seguir: uma sequencia anunciada com estreia a ate N dias ja\nentra, para a primeira busca nao esperar o proximo passe depois da estreia. So vale com a\ndata completa na AniList. 0 = so quando ela estiver no ar.",
+                    "type": "integer"
+                },
+                "torrent_client": {
+                    "description": "TorrentClient escolhe quem baixa: \"embedded\" (a rain, dentro do processo),\n\"qbittorrent\" ou \"transmission\" (torrents.RemoteBackend, por HTTP). So vale no boot — o\nbackend e criado uma vez em cmd/daemon e injetado em todo lugar. \"\" vale embedded.",
+                    "type": "string"
+                },
+                "torrent_client_category": {
+                    "description": "TorrentClientCategory e a categoria (qBittorrent) ou label (Transmission) dos torrents\nda daemon. E tambem a fronteira do que ela gerencia: torrent do cliente fora dela nunca e\npausado pela fila nem removido pela limpeza.",
+                    "type": "string"
+                },
+                "torrent_client_password": {
+                    "type": "string"
+                },
+                "torrent_client_save_path": {
+                    "description": "TorrentClientSavePath e a pasta de download como o CLIENTE a enxerga, quando ele roda em\noutro container ou maquina com a pasta montada em outro caminho. Os caminhos que ele\nreporta sob ela sao traduzidos para DownloadPath() antes do hardlink. \"\" = mesmo caminho.",
+                    "type": "string"
+                },
+                "torrent_client_url": {
+                    "description": "TorrentClientURL e a raiz da WebUI do qBittorrent (http://host:8080) ou o endpoint RPC\ndo Transmission (http://host:9091/transmission/rpc).",
+                    "type": "string"
+                },
+                "torrent_client_username": {
+                    "type": "string"
+                },
+                "trackers_list_url": {
+                    "description": "TrackersListURL aponta para uma lista publica de trackers (uma URL por linha), baixada\npara o arquivo trackers_list no maximo uma vez por dia e somada a ExtraTrackers. \"\" desliga.",
+                    "type": "string"
+                },
+                "trial_keep_days": {
+                    "description": "TrialKeepDays e o prazo de um trial: passado ele sem o usuario ficar com o anime, o passe\ntira o avulso e apaga os episodios. 0 = trial nunca expira.",
+                    "type": "integer"
+                },
                 "watched_episodes_to_keep": {
                     "type": "integer"
                 }
             }
         },
+        "files.LibraryMove": {
+            "type": "object",
+            "properties": {
+                "anime_id": {
+                    "type": "integer"
+                },
+                "anime_name": {
+                    "type": "string"
+                },
+                "episode_number": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "files.MediaServer": {
+            "type": "object",
+            "properties": {
+                "library_path": {
+                    "description": "LibraryPath e MoviesPath sao CompletedAnimePath e LibraryMoviesPath como o SERVIDOR os\nenxerga, quando ele roda em outro container ou maquina, como TorrentClientSavePath. \"\" =\nmesmo caminho.",
+                    "type": "string"
+                },
+                "movies_path": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Token e a API key do Jellyfin/Emby ou o X-Plex-Token.",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type e \"jellyfin\", \"emby\" ou \"plex\".",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "files.NotificationsConfig": {
             "type": "object",
             "properties": {
@@ -2169,6 +4947,59 @@ const docTemplate = `{
                 }
             }
         },
+        "idmap.IDs": {
+            "type": "object",
+            "properties": {
+                "anidb": {
+                    "type": "integer",
+                    "example": 17617
+                },
+                "anilist": {
+                    "type": "integer",
+                    "example": 154587
+                },
+                "kitsu": {
+                    "type": "integer",
+                    "example": 46474
+                },
+                "myanimelist": {
+                    "type": "integer",
+                    "example": 52991
+                }
+            }
+        },
+        "idmap.Status": {
+            "type": "object",
+            "properties": {
+                "dataset_date": {
+                    "description": "DatasetDate is the dataset's own lastUpdate, as it wrote it.",
+                    "type": "string",
+                    "example": "2026-10-12"
+                },
+                "entries": {
+                    "type": "integer",
+                    "example": 39000
+                },
+                "loaded": {
+                    "type": "boolean"
+                },
+                "saved_at": {
+                    "description": "SavedAt is when the file on disk was written (downloaded or dropped in the config folder).",
+                    "type": "string"
+                }
+            }
+        },
+        "mediaserver.Info": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
         "nyaa.Priorities": {
             "type": "object",
             "properties": {
@@ -19,6 +19,7 @@ package main
 import (
 	"AutoAnimeDownloader/src/internal/api"
 	processcli "AutoAnimeDownloader/src/internal/cli"
+	"AutoAnimeDownloader/src/internal/files"
 	"AutoAnimeDownloader/src/internal/logger"
 	"AutoAnimeDownloader/src/internal/version"
 	"bufio"
@@ -148,6 +149,69 @@ func main() {
 					return handleCheck()
 				},
 			},
+			{
+				Name:  "pause-all",
+				Usage: "Pause every torrent (downloads and seeding)",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:  "for",
+						Usage: "Resume on its own after this long (e.g. 2h, 30m); omit to pause until resume-all",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return handlePauseAll(c.Duration("for"))
+				},
+			},
+			{
+				Name:  "resume-all",
+				Usage: "Resume every torrent paused by pause-all",
+				Action: func(c *cli.Context) error {
+					return handleResumeAll()
+				},
+			},
+			{
+				Name:  "data-usage",
+				Usage: "Show the traffic of the current billing period and the data cap",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "anime",
+						Usage: "Only this anime's traffic (AniList media ID)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return handleDataUsage(c.Int("anime"))
+				},
+			},
+			{
+				Name:  "library",
+				Usage: "Audit and repair the library",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "audit",
+						Usage: "Cross-check episode records, library files and torrents (changes nothing)",
+						Action: func(c *cli.Context) error {
+							return handleLibraryAudit()
+						},
+					},
+					{
+						Name:      "repair",
+						Usage:     "Fix the findings of the given categories",
+						ArgsUsage: "<category>...",
+						Description: `Audits again and fixes the findings of the given categories:
+  - broken_link: link the seeding file into the library again
+  - record_without_torrent: drop the record, keep the library files
+  - orphan_torrent: remove the torrent and its data
+  - duplicate: delete the extra name
+  - orphan_file: delete the unknown file or folder`,
+						Action: func(c *cli.Context) error {
+							if c.NArg() == 0 {
+								return fmt.Errorf("usage: library repair <category>...\n\nCategories: broken_link, record_without_torrent, orphan_torrent, duplicate, orphan_file")
+							}
+							return handleLibraryRepair(c.Args().Slice())
+						},
+					},
+				},
+			},
 			{
 				Name:  "animes",
 				Usage: "List downloaded animes",
@@ -285,6 +349,15 @@ func handleStatus() error {
 		t.AppendRow(table.Row{"Last Check", status.LastCheck.Format(time.RFC3339)})
 		t.AppendRow(table.Row{"Has Error", status.HasError})
 		t.AppendRow(table.Row{"Version", status.Version})
+		switch {
+		case status.DownloadsPausedUntil != nil:
+			t.AppendRow(table.Row{"Downloads Paused", "until " + status.DownloadsPausedUntil.Local().Format(time.RFC3339)})
+		case status.DownloadsPaused:
+			t.AppendRow(table.Row{"Downloads Paused", "until resume-all"})
+		}
+		if status.DataCapReached {
+			t.AppendRow(table.Row{"Data Cap", "reached (see data-usage)"})
+		}
 		t.Render()
 	}
 	return nil
@@ -394,6 +467,148 @@ func handleCheck() error {
 	return nil
 }
 
+func handlePauseAll(d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("--for must be positive")
+	}
+	if d > 0 && d < time.Minute {
+		// A API conta em minutos; menos que isso viraria "ate o resume-all".
+		return fmt.Errorf("--for must be at least 1m")
+	}
+	client := getClient()
+	result, err := client.PauseAll(d)
+	if err != nil {
+		return fmt.Errorf("failed to pause all torrents: %w", err)
+	}
+
+	if outputJSON {
+		outputJSONResponse(result)
+	} else if result.Until != nil {
+		fmt.Printf("All torrents paused until %s\n", result.Until.Local().Format(time.RFC3339))
+	} else {
+		fmt.Println("All torrents paused until resume-all")
+	}
+	return nil
+}
+
+func handleResumeAll() error {
+	client := getClient()
+	if err := client.ResumeAll(); err != nil {
+		return fmt.Errorf("failed to resume all torrents: %w", err)
+	}
+
+	if outputJSON {
+		outputJSONResponse(map[string]string{"message": "All torrents resumed"})
+	} else {
+		fmt.Println("All torrents resumed")
+	}
+	return nil
+}
+
+func handleDataUsage(animeID int) error {
+	client := getClient()
+	usage, err := client.GetDataUsage(animeID)
+	if err != nil {
+		return fmt.Errorf("failed to get data usage: %w", err)
+	}
+
+	if outputJSON {
+		outputJSONResponse(usage)
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Field", "Value"})
+	t.AppendRow(table.Row{"Period", usage.PeriodStart.Format("2006-01-02") + " to " + usage.PeriodEnd.Format("2006-01-02")})
+	t.AppendRow(table.Row{"Downloaded", formatGB(usage.Period.Downloaded)})
+	t.AppendRow(table.Row{"Uploaded", formatGB(usage.Period.Uploaded)})
+	t.AppendRow(table.Row{"Total", formatGB(usage.Period.Total)})
+	if usage.CapBytes > 0 {
+		t.AppendRow(table.Row{"Data Cap", formatGB(usage.CapBytes)})
+		t.AppendRow(table.Row{"Cap Reached", usage.CapReached})
+	} else {
+		t.AppendRow(table.Row{"Data Cap", "off"})
+	}
+	t.Render()
+
+	if len(usage.Animes) > 0 {
+		fmt.Println()
+		at := table.NewWriter()
+		at.SetOutputMirror(os.Stdout)
+		at.AppendHeader(table.Row{"Anime", "Downloaded", "Uploaded", "Total"})
+		for _, a := range usage.Animes {
+			at.AppendRow(table.Row{a.AnimeName, formatGB(a.Downloaded), formatGB(a.Uploaded), formatGB(a.Total)})
+		}
+		at.Render()
+	}
+	return nil
+}
+
+// formatGB mostra bytes em GB na base 1024, a mesma de data_cap_gb.
+func formatGB(b int64) string {
+	return fmt.Sprintf("%.2f GB", float64(b)/(1024*1024*1024))
+}
+
+func handleLibraryAudit() error {
+	client := getClient()
+	audit, err := client.AuditLibrary()
+	if err != nil {
+		return fmt.Errorf("failed to audit library: %w", err)
+	}
+
+	if outputJSON {
+		outputJSONResponse(audit)
+		return nil
+	}
+	if len(audit.Findings) == 0 {
+		fmt.Println("No problems found")
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Category", "Anime", "Episodes", "Path / Hash", "Detail"})
+	for _, f := range audit.Findings {
+		target := f.Path
+		if target == "" {
+			target = f.Hash
+		}
+		episodes := make([]string, 0, len(f.EpisodeNumbers))
+		for _, n := range f.EpisodeNumbers {
+			episodes = append(episodes, fmt.Sprint(n))
+		}
+		t.AppendRow(table.Row{f.Category, f.AnimeName, strings.Join(episodes, ","), target, f.Detail})
+	}
+	t.Render()
+	fmt.Println("\nFix with: library repair <category>...")
+	return nil
+}
+
+func handleLibraryRepair(categories []string) error {
+	client := getClient()
+	result, err := client.RepairLibrary(categories)
+	if err != nil {
+		return fmt.Errorf("failed to repair library: %w", err)
+	}
+
+	if outputJSON {
+		outputJSONResponse(result)
+		return nil
+	}
+	for _, c := range categories {
+		fmt.Printf("%s: %d repaired\n", c, result.Repaired[files.AuditCategory(c)])
+	}
+	for _, f := range result.Failed {
+		target := f.Finding.Path
+		if target == "" {
+			target = f.Finding.Hash
+		}
+		fmt.Printf("failed (%s) %s: %s\n", f.Finding.Category, target, f.Error)
+	}
+	return nil
+}
+
 func handleAnimes() error {
 	client := getClient()
 	animes, err := client.GetAnimes()
@@ -216,11 +216,41 @@ func runDebugAnime(animeId int) {
 	os.Exit(0)
 }
 
-// ensureStartupSession creates the embedded torrent session at boot when a save path is
-// already configured, so seeding starts with the process instead of waiting for the first
+// newTorrentBackend builds the backend Config.TorrentClient asks for: the embedded rain
+// session by default, or qBittorrent/Transmission over HTTP. It is chosen once, here — every
+// call site gets the same instance — so switching clients takes a restart.
+//
+// A remote client the config cannot describe is fatal rather than a fallback to the embedded
+// one: the embedded session would not see any of the remote torrents, and the next pass would
+// download the whole library a second time.
+func newTorrentBackend(fileManager *files.FileManager, sessionDBPath string) torrents.TorrentBackend {
+	configs, err := fileManager.LoadConfigs()
+	if err != nil || configs == nil || !torrents.IsRemoteClient(configs.TorrentClient) {
+		return torrents.NewSessionManager(sessionDBPath)
+	}
+	backend, err := torrents.NewRemoteBackend(torrents.RemoteOptions{
+		Client:         configs.TorrentClient,
+		URL:            configs.TorrentClientURL,
+		Username:       configs.TorrentClientUsername,
+		Password:       configs.TorrentClientPassword,
+		Category:       configs.TorrentClientCategory,
+		RemoteSavePath: configs.TorrentClientSavePath,
+	}, filepath.Dir(sessionDBPath))
+	if err != nil {
+		logger.Logger.Fatal().Err(err).Str("torrent_client", configs.TorrentClient).
+			Msg("Invalid external torrent client settings; fix torrent_client_url in config.json or set torrent_client to embedded")
+	}
+	logger.Logger.Info().Str("torrent_client", configs.TorrentClient).Str("url", configs.TorrentClientURL).
+		Msg("Using an external torrent client")
+	return backend
+}
+
+// ensureStartupSession creates the torrent session at boot when a save path is already
+// configured, so seeding starts with the process instead of waiting for the first
 // verification pass. An incomplete config is not an error here — the session stays lazy and
-// the verification pass creates it once the config is saved.
-func ensureStartupSession(manager *torrents.SessionManager, fileManager *files.FileManager) {
+// the verification pass creates it once the config is saved. For an external client,
+// "creating the session" is connecting to it.
+func ensureStartupSession(manager torrents.TorrentBackend, fileManager *files.FileManager) {
 	configs, err := fileManager.LoadConfigs()
 	if err != nil {
 		logger.Logger.Warn().Err(err).Msg("Failed to load configs at startup; torrent session will be created on the first verification pass")
@@ -233,12 +263,17 @@ func ensureStartupSession(manager *torrents.SessionManager, fileManager *files.F
 	// Antes do Ensure: a sessao nova roda a adocao da fila assim que nasce, e ela precisa
 	// ja saber o limite para nao promover tudo o que a rain reabriu parado.
 	manager.SetMaxActiveDownloads(configs.MaxConcurrentDownloads)
+	daemon.ApplyExtraTrackers(fileManager, manager, configs)
+	daemon.ApplyQueuePolicy(manager, configs)
+	// Sem isto, o teto atingido antes do restart so voltaria a valer na primeira amostra do
+	// medidor, e a sessao nova semearia livre ate la.
+	daemon.ApplyDataCap(fileManager, manager, configs)
 	downloadPath := configs.DownloadPath()
 	if _, err := manager.Ensure(downloadPath); err != nil {
-		logger.Logger.Error().Err(err).Str("download_path", downloadPath).Msg("Failed to create the embedded torrent session at startup; the verification pass will retry")
+		logger.Logger.Error().Err(err).Str("download_path", downloadPath).Msg("Failed to create the torrent session at startup; the verification pass will retry")
 		return
 	}
-	logger.Logger.Info().Str("download_path", downloadPath).Msg("Embedded torrent session started; seeding is active independently of the daemon loop")
+	logger.Logger.Info().Str("download_path", downloadPath).Msg("Torrent session started; seeding is active independently of the daemon loop")
 }
 
 func main() {
@@ -284,14 +319,14 @@ func main() {
 	}
 	jobQueue := daemon.NewJobQueue(fileManager, jobsFilePath)
 
-	// Embedded torrent client (rain) and the library organizer (hardlinks into the
-	// completed-anime folder). The session is created lazily by SessionManager once the
-	// save path is known (config may be incomplete at startup).
+	// Torrent client (embedded rain, or an external qBittorrent/Transmission) and the library
+	// organizer (hardlinks into the completed-anime folder). The session is created lazily by
+	// Ensure once the save path is known (config may be incomplete at startup).
 	sessionDBPath, err := getSessionDBPath()
 	if err != nil {
 		logger.Logger.Fatal().Err(err).Msg("Failed to determine session database path")
 	}
-	torrentManager := torrents.NewSessionManager(sessionDBPath)
+	torrentManager := newTorrentBackend(fileManager, sessionDBPath)
 	librarian := files.NewLibrarian(files.NewOSFileSystem())
 
 	// Completion events enqueue a durable JobOrganize; failures notify and drop the torrent
@@ -328,8 +363,11 @@ func main() {
 		logger.Logger.Warn().Err(err).Msg("Failed to load saved episodes for the tvshow.nfo backfill")
 	} else {
 		// So depois da migracao acima ter dado certo: com id de entrada o nfo sairia com o id
-		// errado, e ele nunca e reescrito. Idempotente (nao sobrescreve), entao roda todo boot.
+		// errado, e ele nunca e reescrito (o job de metadados preserva o id do nfo minimo ao
+		// completa-lo). Idempotente (nao sobrescreve), entao roda todo boot. O job completa os
+		// nfo com os dados da AniList, cria os de episodio que faltam e baixa a arte das series.
 		librarian.BackfillShowNFOs(saved)
+		jobQueue.EnqueueMetadata()
 	}
 	// Defers run LIFO: register Close first so jobQueue.Stop() (which may run organize jobs
 	// that use the session) runs before the session is closed.
@@ -357,6 +395,12 @@ func main() {
 		ensureStartupSession(torrentManager, fileManager)
 	}
 
+	// O medidor de trafego vive com o processo, nao com o loop de verificacao: o seeding
+	// continua com o loop parado, e a conta e o teto precisam continuar junto.
+	meterCtx, stopMeter := context.WithCancel(context.Background())
+	defer stopMeter()
+	go daemon.RunDataUsageMeter(meterCtx, fileManager, torrentManager)
+
 	state := daemon.NewState()
 
 	apiPort := getPort()
@@ -80,6 +80,10 @@ var aniListAPIURL = "https://graphql.anilist.co"
 // ErrNotFound sinaliza que a AniList respondeu 404: o objeto consultado nao existe.
 var ErrNotFound = errors.New("anilist: not found")
 
+// ErrUnauthorized sinaliza que a AniList recusou o token (401): expirado, revogado no site ou de
+// outro cliente. Quem escreve precisa pedir um login novo, e nao tentar de novo.
+var ErrUnauthorized = errors.New("anilist: token rejected")
+
 func init() {
 	if url := os.Getenv("ANILIST_API_URL"); url != "" {
 		aniListAPIURL = url
@@ -89,18 +93,25 @@ func init() {
 // MockAniListDo troca o transporte HTTP e limpa os caches nas duas pontas: um teste que instala
 // um mock precisa ver as respostas dele, nao as do teste anterior.
 func MockAniListDo(fn func(*http.Request) (*http.Response, error)) (restore func()) {
-	prev := httpDo
+	prev, prevSleep := httpDo, rateSleep
 	clearCaches()
 	if fn != nil {
 		httpDo = fn
+		rateSleep = func(time.Duration) {}
 	}
-	return func() { httpDo = prev; clearCaches() }
+	return func() { httpDo, rateSleep = prev, prevSleep; clearCaches() }
 }
 
 func clearCaches() {
 	customListsCache.clear()
 	frontendListCache.clear()
 	mediaByIDCache.clear()
+	seasonChainCache.clear()
+	seasonalCache.clear()
+	malMappingCache.clear()
+	diskCache.clear()
+	budget.reset()
+	ResetStaleUse()
 }
 
 type AniListResponse struct {
@@ -150,8 +161,10 @@ func MediaStatusAllowed(allowedStatuses []string, status MediaStatus) bool {
 }
 
 type CoverImage struct {
-	Large  string `json:"large"`
-	Medium string `json:"medium"`
+	// ExtraLarge so vem de GetMediaByID: e o poster.jpg da biblioteca.
+	ExtraLarge string `json:"extraLarge"`
+	Large      string `json:"large"`
+	Medium     string `json:"medium"`
 }
 
 type Media struct {
@@ -164,21 +177,66 @@ type Media struct {
 	Relations      MediaRelations `json:"relations"`
 	CoverImage     CoverImage     `json:"coverImage"`
 	AiringSchedule AiringSchedule `json:"airingSchedule"`
+	// SeasonYear e o ano da temporada de estreia; alimenta o {year} dos templates de nome da
+	// biblioteca. nil quando a AniList nao sabe (anuncios sem data).
+	SeasonYear *int `json:"seasonYear"`
+	// Description, Genres, Studios e StreamingEpisodes so vem de GetMediaByID: alimentam os
+	// .nfo da biblioteca. Description e texto com <br> da AniList (asHtml: false).
+	Description       *string            `json:"description"`
+	Genres            []string           `json:"genres"`
+	Studios           MediaStudios       `json:"studios"`
+	StreamingEpisodes []StreamingEpisode `json:"streamingEpisodes"`
+	// BannerImage so vem de GetMediaByID: e o fanart.jpg da biblioteca. nil para boa parte
+	// dos animes antigos.
+	BannerImage *string `json:"bannerImage"`
 	// NextAiringEpisode e a fonte de "qual foi o ultimo episodio no ar" para os animes cujo
 	// airingSchedule a AniList ja clipou (ver EpisodeList e decisions.md #52). nil quando o
 	// anime terminou ou nao tem data marcada.
 	NextAiringEpisode *AiringNode `json:"nextAiringEpisode"`
 }
 
+type MediaStudios struct {
+	Nodes []struct {
+		Name string `json:"name"`
+	} `json:"nodes"`
+}
+
+// StreamingEpisode e um episodio num site de streaming oficial. E a unica fonte de titulo de
+// episodio na AniList: "Episode 3 - The Title", sem numero proprio.
+type StreamingEpisode struct {
+	Title string `json:"title"`
+}
+
 type Title struct {
 	English *string `json:"english"`
 	Romaji  *string `json:"romaji"`
 }
 
 type MediaRelationNode struct {
-	Title    Title    `json:"title"`
-	Synonyms []string `json:"synonyms"`
-	Episodes *int     `json:"episodes"`
+	Id       int         `json:"id"`
+	Format   MediaFormat `json:"format"`
+	Title    Title       `json:"title"`
+	Synonyms []string    `json:"synonyms"`
+	Episodes *int        `json:"episodes"`
+	// Status e StartDate sao o que o daemon precisa para seguir uma SEQUEL sem consultar a
+	// sequencia: se ela ja esta no ar, ou quando estreia.
+	Status    MediaStatus `json:"status"`
+	StartDate FuzzyDate   `json:"startDate"`
+}
+
+// FuzzyDate e a data parcial da AniList: um anuncio costuma ter so o ano, ou ano e mes.
+type FuzzyDate struct {
+	Year  *int `json:"year"`
+	Month *int `json:"month"`
+	Day   *int `json:"day"`
+}
+
+// Time returns the date at midnight UTC; false unless year, month and day are all known.
+func (d FuzzyDate) Time() (time.Time, bool) {
+	if d.Year == nil || d.Month == nil || d.Day == nil {
+		return time.Time{}, false
+	}
+	return time.Date(*d.Year, time.Month(*d.Month), *d.Day, 0, 0, 0, 0, time.UTC), true
 }
 
 type MediaRelationEdge struct {
@@ -258,51 +316,118 @@ func DedupeByMedia(list []MediaList) []MediaList {
 type RequestVariables map[string]any
 
 func sendAnilistRequest[T any](query string, variables RequestVariables) (*T, error) {
-	jsonData, err := json.Marshal(GraphQLRequest{Query: query, Variables: variables})
+	return sendAnilistRequestAs[T]("", query, variables)
+}
+
+// sendAnilistRequestAs e o sendAnilistRequest com o token de uma conta: as mutations exigem, e
+// as consultas de Viewer so respondem com ele. token "" e o request anonimo de sempre.
+func sendAnilistRequestAs[T any](token, query string, variables RequestVariables) (*T, error) {
+	body, err := fetchAnilist(token, query, variables, rateLimitMaxWait)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %v", err)
+		return nil, err
 	}
+	return decodeAnilistResponse[T](body)
+}
 
-	req, err := http.NewRequest("POST", aniListAPIURL, bytes.NewBuffer(jsonData))
+// fetchAnilist faz o request dentro do orcamento (rateBudget) e devolve o corpo de um 200. Um
+// 429 espera o Retry-After e tenta UMA vez mais; o segundo 429 vira ErrRateLimited. As falhas de
+// disponibilidade (rede, 5xx, 429) saem como unavailableError, as que o cache de respostas cobre.
+func fetchAnilist(token, query string, variables RequestVariables, maxWait time.Duration) ([]byte, error) {
+	jsonData, err := json.Marshal(GraphQLRequest{Query: query, Variables: variables})
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
+		return nil, fmt.Errorf("error marshaling request: %v", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	logger.Logger.Debug().Str("url", aniListAPIURL).Msg("Sending Anilist request")
+	for attempt := 1; ; attempt++ {
+		if err := budget.acquire(maxWait); err != nil {
+			return nil, &unavailableError{err}
+		}
 
-	resp, err := httpDo(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		req, err := http.NewRequest("POST", aniListAPIURL, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		logger.Logger.Debug().Str("url", aniListAPIURL).Bool("authenticated", token != "").Msg("Sending Anilist request")
+
+		resp, err := httpDo(req)
+		if err != nil {
+			return nil, &unavailableError{fmt.Errorf("error making request: %v", err)}
+		}
+		budget.observe(resp)
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt == 1 {
+			resp.Body.Close()
+			continue
+		}
+		body, err := readAnilistResponse(resp)
+		resp.Body.Close()
+		return body, err
 	}
-	defer resp.Body.Close()
+}
 
+func readAnilistResponse(resp *http.Response) ([]byte, error) {
 	if resp.StatusCode == http.StatusNotFound {
 		// A AniList responde 404 quando o objeto pedido nao existe (por exemplo, uma entrada de
 		// lista que o usuario apagou). E uma resposta valida, nao uma falha — quem consulta por
 		// id precisa distinguir isso de "a AniList caiu".
 		return nil, ErrNotFound
 	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrUnauthorized
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &unavailableError{fmt.Errorf("API returned status code: %d: %w", resp.StatusCode, ErrRateLimited)}
+	}
 	if resp.StatusCode != http.StatusOK {
 		logger.Logger.Warn().Int("status_code", resp.StatusCode).Msg("Anilist returned non-200 status")
-		return nil, fmt.Errorf("API returned status code: %d", resp.StatusCode)
+		// Numa mutation o 400 traz o motivo ("validation"), que vale mais que o codigo.
+		err := fmt.Errorf("API returned status code: %d", resp.StatusCode)
+		if msg := graphQLErrorMessage(resp.Body); msg != "" {
+			err = fmt.Errorf("API returned status code: %d: %s", resp.StatusCode, msg)
+		}
+		if resp.StatusCode >= 500 {
+			return nil, &unavailableError{err}
+		}
+		return nil, err
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
+		return nil, &unavailableError{fmt.Errorf("error reading response: %v", err)}
 	}
 
 	logger.Logger.Debug().Int("status_code", resp.StatusCode).Int("body_size", len(body)).Msg("Anilist response received")
+	return body, nil
+}
 
+func decodeAnilistResponse[T any](body []byte) (*T, error) {
 	var response T
-	if err = json.Unmarshal(body, &response); err != nil {
+	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("error unmarshaling response: %v", err)
 	}
-
 	return &response, nil
 }
 
+// graphQLErrorMessage le a primeira mensagem de um corpo {"errors":[{"message":...}]}. "" quando
+// o corpo nao tem esse formato.
+func graphQLErrorMessage(body io.Reader) string {
+	var out struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	data, err := io.ReadAll(io.LimitReader(body, 64<<10))
+	if err != nil || json.Unmarshal(data, &out) != nil || len(out.Errors) == 0 {
+		return ""
+	}
+	return out.Errors[0].Message
+}
+
 // GetCustomListsMap fetches a lightweight map of MediaList ID → CustomLists via a minimal query.
 // Results are cached for 5 minutes so repeated calls (e.g. from the API endpoint) don't hit
 // Anilist's rate limit. Only a response with at least one non-null CustomLists entry is cached.
@@ -326,7 +451,7 @@ func GetCustomListsMap(userName string, statuses []string) map[int]CustomLists {
 	}
 
 	query := `query($u:String,$t:MediaType,$s:[MediaListStatus]){Page{mediaList(userName:$u,type:$t,status_in:$s){id customLists}}}`
-	resp, err := sendAnilistRequest[miniResponse](query, RequestVariables{
+	resp, err := sendCachedAnilistRequest[miniResponse](query, RequestVariables{
 		"u": userName,
 		"t": "ANIME",
 		"s": statuses,
@@ -368,6 +493,7 @@ func GetAllCurrentAnime(userName string, statuses []string) (*AniListResponse, e
 						format
 						status
 						episodes
+						seasonYear
 						title {
 							english
 							romaji
@@ -376,6 +502,14 @@ func GetAllCurrentAnime(userName string, statuses []string) (*AniListResponse, e
 						relations {
 							edges {
 								node {
+									id
+									format
+									status
+									startDate {
+										year
+										month
+										day
+									}
 									title {
 										english
 										romaji
@@ -414,7 +548,7 @@ func GetAllCurrentAnime(userName string, statuses []string) (*AniListResponse, e
 		"statuses": statuses,
 	}
 
-	return sendAnilistRequest[AniListResponse](query, variables)
+	return sendCachedAnilistRequest[AniListResponse](query, variables)
 }
 
 // GetFrontendAnimeList alimenta /api/v1/animes, que o frontend faz poll a cada 30s por aba.
@@ -474,7 +608,7 @@ func GetFrontendAnimeList(userName string, statuses []string) (*AniListResponse,
 		"statuses": statuses,
 	}
 
-	resp, err := sendAnilistRequest[AniListResponse](query, variables)
+	resp, err := sendCachedAnilistRequest[AniListResponse](query, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -576,7 +710,7 @@ func GetMediaListStatus(username string, mediaId int) (MediaListStatus, bool, er
 		}
 	`
 
-	resp, err := sendAnilistRequest[AniListResponse](query, RequestVariables{
+	resp, err := sendCachedAnilistRequest[AniListResponse](query, RequestVariables{
 		"userName": username,
 		"mediaId":  mediaId,
 	})
@@ -589,6 +723,20 @@ func GetMediaListStatus(username string, mediaId int) (MediaListStatus, bool, er
 	return resp.Data.Page.MediaList[0].Status, true, nil
 }
 
+// GetMediaListEntry returns one account's list entry for a media, with its progress and the
+// media's episode count, or nil when the account does not track the media. It reads the public
+// list, so it needs no token.
+func GetMediaListEntry(username string, mediaId int) (*MediaList, error) {
+	resp, err := getMediaListEntry(username, mediaId)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data.Page.MediaList) == 0 {
+		return nil, nil
+	}
+	return &resp.Data.Page.MediaList[0], nil
+}
+
 func getMediaListEntry(userName string, mediaId int) (*AniListResponse, error) {
 	query := `
 		query GetAnimeEpisodes($userName: String, $mediaId: Int) {
@@ -603,6 +751,7 @@ func getMediaListEntry(userName string, mediaId int) (*AniListResponse, error) {
 						episodes
 						format
 						status
+						seasonYear
 						title {
 							english
 							romaji
@@ -611,6 +760,14 @@ func getMediaListEntry(userName string, mediaId int) (*AniListResponse, error) {
 						relations {
 							edges {
 								node {
+									id
+									format
+									status
+									startDate {
+										year
+										month
+										day
+									}
 									title {
 										english
 										romaji
@@ -644,7 +801,7 @@ func getMediaListEntry(userName string, mediaId int) (*AniListResponse, error) {
 		}
 	`
 
-	return sendAnilistRequest[AniListResponse](query, RequestVariables{
+	return sendCachedAnilistRequest[AniListResponse](query, RequestVariables{
 		"userName": userName,
 		"mediaId":  mediaId,
 	})
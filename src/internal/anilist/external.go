@@ -0,0 +1,158 @@
+package anilist
+
+import (
+	"strconv"
+	"time"
+)
+
+// Leituras em lote por id, para as listas de fora da AniList (MyAnimeList, Kitsu). Uma lista do
+// MAL traz centenas de ids; uma query por anime estouraria o limite da AniList no primeiro passe,
+// entao tudo aqui vai em paginas de mediaPageSize ids.
+
+// mediaPageSize e o maximo de itens por pagina que a AniList entrega.
+const mediaPageSize = 50
+
+// malMappingCache guarda id do MAL -> media id. O mapeamento nao muda; o TTL longo so existe
+// para um id que a AniList ainda nao conhecia (0) ser tentado de novo algum dia.
+var malMappingCache = newTTLCache[int]()
+
+const malMappingTTL = 24 * time.Hour
+
+// MediaIDsByMAL maps MyAnimeList anime ids to AniList media ids through AniList's idMal. Ids
+// AniList does not know are absent from the result.
+func MediaIDsByMAL(malIDs []int) (map[int]int, error) {
+	out := make(map[int]int, len(malIDs))
+	var missing []int
+	for _, id := range malIDs {
+		if mediaID, ok := malMappingCache.get(strconv.Itoa(id)); ok {
+			if mediaID != 0 {
+				out[id] = mediaID
+			}
+			continue
+		}
+		missing = append(missing, id)
+	}
+
+	query := `
+		query MediaIDsByMAL($ids: [Int]) {
+			Page(perPage: 50) {
+				media(idMal_in: $ids, type: ANIME) {
+					id
+					idMal
+				}
+			}
+		}
+	`
+	type response struct {
+		Data struct {
+			Page struct {
+				Media []struct {
+					Id    int `json:"id"`
+					IdMal int `json:"idMal"`
+				} `json:"media"`
+			} `json:"Page"`
+		} `json:"data"`
+	}
+
+	for start := 0; start < len(missing); start += mediaPageSize {
+		chunk := missing[start:min(start+mediaPageSize, len(missing))]
+		resp, err := sendCachedAnilistRequest[response](query, RequestVariables{"ids": chunk})
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[int]int, len(chunk))
+		for _, m := range resp.Data.Page.Media {
+			found[m.IdMal] = m.Id
+		}
+		for _, id := range chunk {
+			// O 0 tambem vai para o cache: sem ele um id que a AniList nao tem seria pedido de
+			// novo a cada passe.
+			malMappingCache.set(strconv.Itoa(id), found[id], malMappingTTL)
+			if found[id] != 0 {
+				out[id] = found[id]
+			}
+		}
+	}
+	return out, nil
+}
+
+// GetMediaByIDs reads many media at once, with the fields of the list queries (GetAllCurrentAnime),
+// as SYNTHETIC MediaLists like GetMediaByID's: only Media filled. The caller sets Progress and
+// Status from its own list. Ids AniList does not know are absent from the result.
+func GetMediaByIDs(mediaIDs []int) (map[int]*MediaList, error) {
+	query := `
+		query GetMediaByIDs($ids: [Int]) {
+			Page(perPage: 50) {
+				media(id_in: $ids, type: ANIME) {
+					id
+					format
+					status
+					episodes
+					seasonYear
+					title {
+						english
+						romaji
+					}
+					synonyms
+					coverImage {
+						large
+						medium
+					}
+					relations {
+						edges {
+							node {
+								id
+								format
+								status
+								startDate {
+									year
+									month
+									day
+								}
+								title {
+									english
+									romaji
+								}
+								synonyms
+								episodes
+							}
+							relationType
+						}
+					}
+					airingSchedule {
+						nodes {
+							id
+							episode
+							timeUntilAiring
+						}
+					}
+					nextAiringEpisode {
+						episode
+						airingAt
+						timeUntilAiring
+					}
+				}
+			}
+		}
+	`
+	type response struct {
+		Data struct {
+			Page struct {
+				Media []Media `json:"media"`
+			} `json:"Page"`
+		} `json:"data"`
+	}
+
+	out := make(map[int]*MediaList, len(mediaIDs))
+	for start := 0; start < len(mediaIDs); start += mediaPageSize {
+		chunk := mediaIDs[start:min(start+mediaPageSize, len(mediaIDs))]
+		resp, err := sendCachedAnilistRequest[response](query, RequestVariables{"ids": chunk})
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range resp.Data.Page.Media {
+			out[m.Id] = &MediaList{Media: m}
+		}
+	}
+	return out, nil
+}
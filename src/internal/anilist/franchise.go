@@ -0,0 +1,139 @@
+package anilist
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// seasonChainCache guarda a cadeia de temporadas por media id. A cadeia so muda quando a AniList
+// ganha uma temporada nova no fim — e quem pede a cadeia e sempre a entrada mais nova, que
+// entao ja esta nela —, por isso o TTL pode ser longo: o relink de uma biblioteca grande pede a
+// cadeia de cada anime e cada uma custa um request por temporada.
+var seasonChainCache = newTTLCache[[]SeasonEntry]()
+
+const seasonChainTTL = 6 * time.Hour
+
+// maxSeasonChainHops limita a caminhada pelos PREQUEL: franquias longas passam de 10 temporadas,
+// mas nenhuma chega a 20, e o limite segura um ciclo que o visited nao pegue.
+const maxSeasonChainHops = 20
+
+// SeasonEntry e uma entrada da AniList na cadeia de temporadas de uma serie.
+type SeasonEntry struct {
+	Id         int         `json:"id"`
+	Format     MediaFormat `json:"format"`
+	Title      Title       `json:"title"`
+	Synonyms   []string    `json:"synonyms"`
+	Episodes   *int        `json:"episodes"`
+	SeasonYear *int        `json:"seasonYear"`
+}
+
+// IsSeasonFormat diz se o formato conta como temporada de uma serie. Filme, OVA e especial
+// tambem aparecem como PREQUEL/SEQUEL, mas nao sao temporada — o Jellyfin os trata como extras.
+func IsSeasonFormat(format MediaFormat) bool {
+	switch format {
+	case MediaFormatTV, MediaFormatTVShort, MediaFormatONA:
+		return true
+	}
+	return false
+}
+
+// GetSeasonChain devolve as temporadas da serie de mediaID, da primeira ate mediaID, seguindo
+// as relacoes PREQUEL so por entradas de formato de temporada (IsSeasonFormat). Um cour
+// dividido em "Part 2" aparece como entrada propria; quem numera decide se e temporada nova.
+//
+// (nil, nil) quando a AniList nao conhece o id.
+func GetSeasonChain(mediaID int) ([]SeasonEntry, error) {
+	key := strconv.Itoa(mediaID)
+	if cached, ok := seasonChainCache.get(key); ok {
+		return append([]SeasonEntry(nil), cached...), nil
+	}
+
+	var chain []SeasonEntry
+	visited := make(map[int]bool)
+	for id, hops := mediaID, 0; id != 0 && !visited[id] && hops < maxSeasonChainHops; hops++ {
+		visited[id] = true
+		entry, prequel, err := getSeasonEntry(id)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			break
+		}
+		chain = append(chain, *entry)
+		if !IsSeasonFormat(entry.Format) {
+			// Filme ou OVA pedido direto: nao e temporada de nada, a cadeia e so ele.
+			break
+		}
+		id = prequel
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	// Cada prefixo e a cadeia da temporada em que termina: o relink pede as temporadas de uma
+	// serie em sequencia, e so a primeira precisa caminhar.
+	for i := range chain {
+		seasonChainCache.set(strconv.Itoa(chain[i].Id), chain[:i+1:i+1], seasonChainTTL)
+	}
+	seasonChainCache.set(key, chain, seasonChainTTL)
+	return append([]SeasonEntry(nil), chain...), nil
+}
+
+// getSeasonEntry busca uma entrada e o id do PREQUEL de formato de temporada (0 se nao ha).
+func getSeasonEntry(mediaID int) (*SeasonEntry, int, error) {
+	query := `
+		query GetSeasonEntry($id: Int) {
+			Media(id: $id, type: ANIME) {
+				id
+				format
+				title {
+					english
+					romaji
+				}
+				synonyms
+				episodes
+				seasonYear
+				relations {
+					edges {
+						node {
+							id
+							format
+						}
+						relationType
+					}
+				}
+			}
+		}
+	`
+
+	type response struct {
+		Data struct {
+			Media *struct {
+				SeasonEntry
+				Relations MediaRelations `json:"relations"`
+			} `json:"Media"`
+		} `json:"data"`
+	}
+
+	resp, err := sendCachedAnilistRequest[response](query, RequestVariables{"id": mediaID})
+	if errors.Is(err, ErrNotFound) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.Data.Media == nil {
+		return nil, 0, nil
+	}
+
+	prequel := 0
+	for _, edge := range resp.Data.Media.Relations.Edges {
+		if edge.RelationType == "PREQUEL" && IsSeasonFormat(edge.Node.Format) {
+			prequel = edge.Node.Id
+			break
+		}
+	}
+	entry := resp.Data.Media.SeasonEntry
+	return &entry, prequel, nil
+}
@@ -0,0 +1,102 @@
+package anilist
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// mockByID responde cada request com o corpo de bodies[id] e conta as chamadas.
+func mockByID(t *testing.T, calls *int, bodies map[int]string) func() {
+	t.Helper()
+	return MockAniListDo(func(r *http.Request) (*http.Response, error) {
+		*calls++
+		var req struct {
+			Variables struct {
+				Id int `json:"id"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		body, ok := bodies[req.Variables.Id]
+		if !ok {
+			body = `{"data":{"Media":null}}`
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})
+}
+
+// A cadeia sai da primeira temporada ate a pedida, pulando o filme que tambem e PREQUEL.
+func TestGetSeasonChain_FollowsSeasonPrequels(t *testing.T) {
+	calls := 0
+	defer mockByID(t, &calls, map[int]string{
+		3: `{"data":{"Media":{"id":3,"format":"TV","title":{"romaji":"Show 3rd Season"},"episodes":12,
+			"relations":{"edges":[
+				{"relationType":"PREQUEL","node":{"id":9,"format":"MOVIE"}},
+				{"relationType":"PREQUEL","node":{"id":2,"format":"TV"}}]}}}}`,
+		2: `{"data":{"Media":{"id":2,"format":"TV","title":{"romaji":"Show 2nd Season"},"episodes":12,
+			"relations":{"edges":[
+				{"relationType":"SEQUEL","node":{"id":3,"format":"TV"}},
+				{"relationType":"PREQUEL","node":{"id":1,"format":"TV"}}]}}}}`,
+		1: `{"data":{"Media":{"id":1,"format":"TV","title":{"romaji":"Show"},"episodes":24,"seasonYear":2019,
+			"relations":{"edges":[{"relationType":"SEQUEL","node":{"id":2,"format":"TV"}}]}}}}`,
+	})()
+
+	chain, err := GetSeasonChain(3)
+	if err != nil {
+		t.Fatalf("GetSeasonChain: %v", err)
+	}
+	if len(chain) != 3 || chain[0].Id != 1 || chain[1].Id != 2 || chain[2].Id != 3 {
+		t.Fatalf("cadeia errada: %+v", chain)
+	}
+	if chain[0].SeasonYear == nil || *chain[0].SeasonYear != 2019 || *chain[0].Episodes != 24 {
+		t.Errorf("primeira temporada mapeada errado: %+v", chain[0])
+	}
+
+	if _, err := GetSeasonChain(3); err != nil {
+		t.Fatalf("segunda busca: %v", err)
+	}
+	// A cadeia da segunda temporada e um prefixo da terceira: ja esta no cache.
+	if second, err := GetSeasonChain(2); err != nil || len(second) != 2 || second[1].Id != 2 {
+		t.Fatalf("cadeia da segunda temporada: %+v, %v", second, err)
+	}
+	if calls != 3 {
+		t.Errorf("quero 3 requests (um por temporada, depois cache), veio %d", calls)
+	}
+}
+
+// Um ciclo de PREQUEL mal cadastrado nao pode prender a caminhada.
+func TestGetSeasonChain_StopsOnCycle(t *testing.T) {
+	calls := 0
+	defer mockByID(t, &calls, map[int]string{
+		1: `{"data":{"Media":{"id":1,"format":"TV","relations":{"edges":[{"relationType":"PREQUEL","node":{"id":2,"format":"TV"}}]}}}}`,
+		2: `{"data":{"Media":{"id":2,"format":"TV","relations":{"edges":[{"relationType":"PREQUEL","node":{"id":1,"format":"TV"}}]}}}}`,
+	})()
+
+	chain, err := GetSeasonChain(1)
+	if err != nil {
+		t.Fatalf("GetSeasonChain: %v", err)
+	}
+	if len(chain) != 2 || calls != 2 {
+		t.Errorf("quero 2 entradas em 2 requests, veio %d em %d", len(chain), calls)
+	}
+}
+
+// Um filme pedido direto nao caminha pelos PREQUEL: nao e temporada.
+func TestGetSeasonChain_NonSeasonFormat(t *testing.T) {
+	calls := 0
+	defer mockByID(t, &calls, map[int]string{
+		5: `{"data":{"Media":{"id":5,"format":"MOVIE","relations":{"edges":[{"relationType":"PREQUEL","node":{"id":1,"format":"TV"}}]}}}}`,
+	})()
+
+	chain, err := GetSeasonChain(5)
+	if err != nil {
+		t.Fatalf("GetSeasonChain: %v", err)
+	}
+	if len(chain) != 1 || chain[0].Format != MediaFormatMovie || calls != 1 {
+		t.Errorf("quero so o filme em 1 request, veio %+v em %d", chain, calls)
+	}
+}
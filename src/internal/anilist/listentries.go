@@ -0,0 +1,79 @@
+package anilist
+
+// ListEntry e uma entrada da lista inteira de uma conta, em qualquer status: o que o diff de
+// mudancas da lista compara de um passe para o outro.
+type ListEntry struct {
+	MediaID  int
+	Title    Title
+	Status   MediaListStatus
+	Progress int
+}
+
+// GetListEntries devolve a lista inteira da conta, todos os status, uma entrada por anime.
+//
+// E uma consulta propria, e nao a do passe, porque a do passe so traz DownloadStatuses: um
+// anime que foi de CURRENT para DROPPED simplesmente sumiria dela, e o diff diria "removido" em
+// vez de "mudou de status". Os campos sao so os do diff, entao ela e leve mesmo numa lista de
+// mil animes. MediaListCollection nao pagina.
+func GetListEntries(userName string) ([]ListEntry, error) {
+	query := `
+		query GetListEntries($userName: String) {
+			MediaListCollection(userName: $userName, type: ANIME) {
+				lists {
+					isCustomList
+					entries {
+						status
+						progress
+						media {
+							id
+							title {
+								english
+								romaji
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	type response struct {
+		Data struct {
+			MediaListCollection struct {
+				Lists []struct {
+					IsCustomList bool `json:"isCustomList"`
+					Entries      []struct {
+						Status   MediaListStatus `json:"status"`
+						Progress int             `json:"progress"`
+						Media    struct {
+							Id    int   `json:"id"`
+							Title Title `json:"title"`
+						} `json:"media"`
+					} `json:"entries"`
+				} `json:"lists"`
+			} `json:"MediaListCollection"`
+		} `json:"data"`
+	}
+
+	resp, err := sendCachedAnilistRequest[response](query, RequestVariables{"userName": userName})
+	if err != nil {
+		return nil, err
+	}
+
+	// As listas customizadas repetem entradas que ja estao na lista do status delas.
+	var entries []ListEntry
+	for _, list := range resp.Data.MediaListCollection.Lists {
+		if list.IsCustomList {
+			continue
+		}
+		for _, e := range list.Entries {
+			entries = append(entries, ListEntry{
+				MediaID:  e.Media.Id,
+				Title:    e.Media.Title,
+				Status:   e.Status,
+				Progress: e.Progress,
+			})
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,34 @@
+package anilist
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// A lista customizada repete entradas das listas de status e fica de fora.
+func TestGetListEntries_SkipsCustomLists(t *testing.T) {
+	defer MockAniListDo(func(r *http.Request) (*http.Response, error) {
+		body := `{"data":{"MediaListCollection":{"lists":[
+			{"isCustomList":false,"entries":[{"status":"CURRENT","progress":3,"media":{"id":21,"title":{"romaji":"One Piece"}}}]},
+			{"isCustomList":false,"entries":[{"status":"DROPPED","progress":1,"media":{"id":22,"title":{"romaji":"Other"}}}]},
+			{"isCustomList":true,"entries":[{"status":"CURRENT","progress":3,"media":{"id":21,"title":{"romaji":"One Piece"}}}]}
+		]}}}`
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})()
+
+	entries, err := GetListEntries("user1")
+	if err != nil {
+		t.Fatalf("GetListEntries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("quero 2 entradas, veio %+v", entries)
+	}
+	if entries[0].MediaID != 21 || entries[0].Status != MediaListStatusCurrent || entries[0].Progress != 3 {
+		t.Errorf("primeira entrada errada: %+v", entries[0])
+	}
+	if entries[1].Status != MediaListStatusDropped {
+		t.Errorf("segunda entrada errada: %+v", entries[1])
+	}
+}
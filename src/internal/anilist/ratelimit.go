@@ -0,0 +1,160 @@
+package anilist
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// ErrRateLimited sinaliza que a AniList respondeu 429, ou que o orcamento de requests mandaria
+// esperar mais do que quem chamou aceita. E uma falha de disponibilidade: quem tem resposta
+// salva usa a salva (ver sendCachedAnilistRequest).
+var ErrRateLimited = errors.New("anilist: rate limited")
+
+const (
+	// rateLimitWindow e a janela do X-RateLimit-Remaining quando a AniList nao manda o
+	// X-RateLimit-Reset (ela so manda no 429), e o Retry-After de um 429 que veio sem ele.
+	rateLimitWindow = time.Minute
+	// rateLimitReserve e quantos requests da janela ficam guardados: com a conta chegando nisso,
+	// o proximo espera a janela virar. Dois requests concorrentes que sairam antes da resposta
+	// do primeiro ainda cabem, sem virar 429.
+	rateLimitReserve = 2
+	// rateLimitPaceBelow e a partir de quantos restantes os requests passam a ser espacados pelo
+	// resto da janela, em vez de sair em rajada e parar no reserve.
+	rateLimitPaceBelow = 15
+	// rateLimitMaxWait e o maximo que um request sem resposta salva espera na fila. Cobre um
+	// Retry-After normal da AniList (60s); mais que isso e melhor falhar e tentar no passe
+	// seguinte do que prender o passe ou o handler.
+	rateLimitMaxWait = 90 * time.Second
+	// staleMaxWait e o maximo que um request COM resposta salva espera: passou disso, a salva
+	// responde na hora (marcada como velha) em vez de a tela ficar um minuto carregando.
+	staleMaxWait = 5 * time.Second
+)
+
+// rateBudget e o orcamento de requests da AniList, um so para o processo inteiro: os handlers
+// da API e o passe do daemon saem do mesmo IP e gastam o mesmo limite. Ele le os headers de
+// cada resposta (X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After) e faz o request
+// seguinte esperar quando a conta esta acabando, em vez de descobrir o limite pelo 429.
+//
+// queue serializa a espera: quem chega depois espera atras de quem ja esta esperando, entao os
+// requests saem na ordem em que chegaram. mu protege so os campos, para que observe (chamado
+// por quem ja esta com a resposta na mao) nunca espere a fila.
+type rateBudget struct {
+	queue sync.Mutex
+
+	mu           sync.Mutex
+	remaining    int
+	resetAt      time.Time
+	blockedUntil time.Time
+	last         time.Time
+}
+
+var budget = &rateBudget{}
+
+// Relogio do orcamento; trocado nos testes. MockAniListDo desliga a espera: o mock responde na
+// hora, e um 429 mockado nao pode fazer o teste dormir um minuto.
+var (
+	rateNow   = time.Now
+	rateSleep = time.Sleep
+)
+
+func (b *rateBudget) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remaining = 0
+	b.resetAt = time.Time{}
+	b.blockedUntil = time.Time{}
+	b.last = time.Time{}
+}
+
+// delay e quanto o proximo request precisa esperar agora.
+func (b *rateBudget) delay(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if now.Before(b.blockedUntil) {
+		return b.blockedUntil.Sub(now)
+	}
+	// Sem janela conhecida (nenhuma resposta ainda, ou a janela ja virou) nao ha o que pesar.
+	if !now.Before(b.resetAt) {
+		return 0
+	}
+	if b.remaining <= rateLimitReserve {
+		return b.resetAt.Sub(now)
+	}
+	if b.remaining < rateLimitPaceBelow {
+		spacing := b.resetAt.Sub(now) / time.Duration(b.remaining)
+		if next := b.last.Add(spacing); next.After(now) {
+			return next.Sub(now)
+		}
+	}
+	return 0
+}
+
+// acquire espera a vez do request. Quando a espera passaria de maxWait, devolve ErrRateLimited
+// sem esperar nada.
+func (b *rateBudget) acquire(maxWait time.Duration) error {
+	// Checagem antes da fila: um request que aceita pouca espera nao pode ficar preso atras de
+	// um que aceita muita.
+	if b.delay(rateNow()) > maxWait {
+		return ErrRateLimited
+	}
+	b.queue.Lock()
+	defer b.queue.Unlock()
+
+	wait := b.delay(rateNow())
+	if wait > maxWait {
+		return ErrRateLimited
+	}
+	if wait > 0 {
+		logger.Logger.Debug().Dur("wait", wait).Msg("Waiting for the AniList rate limit budget")
+		rateSleep(wait)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.last = rateNow()
+	// Desconta ja: os requests que saem antes desta resposta voltar tambem contam.
+	if b.last.Before(b.resetAt) && b.remaining > 0 {
+		b.remaining--
+	}
+	return nil
+}
+
+// observe atualiza o orcamento com os headers de uma resposta.
+func (b *rateBudget) observe(resp *http.Response) {
+	now := rateNow()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		b.remaining = n
+		if !now.Before(b.resetAt) {
+			b.resetAt = now.Add(rateLimitWindow)
+		}
+	}
+	if n, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		b.resetAt = time.Unix(n, 0)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retry := retryAfter(resp.Header.Get("Retry-After"), now)
+		b.blockedUntil = now.Add(retry)
+		b.remaining = 0
+		logger.Logger.Warn().Dur("retry_after", retry).Msg("AniList rate limit reached")
+	}
+}
+
+// retryAfter le o Retry-After em segundos ou como data HTTP. Ausente ou ilegivel e a janela
+// inteira: e o que a AniList usa.
+func retryAfter(v string, now time.Time) time.Duration {
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return rateLimitWindow
+}
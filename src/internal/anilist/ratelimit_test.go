@@ -0,0 +1,125 @@
+package anilist
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock troca o relogio do orcamento: rateSleep avanca o tempo em vez de dormir, e o total
+// dormido fica em slept.
+func fakeClock(t *testing.T) (slept *time.Duration) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	slept = new(time.Duration)
+	prevNow, prevSleep := rateNow, rateSleep
+	rateNow = func() time.Time { return now }
+	rateSleep = func(d time.Duration) { now = now.Add(d); *slept += d }
+	budget.reset()
+	t.Cleanup(func() { rateNow, rateSleep = prevNow, prevSleep; budget.reset() })
+	return slept
+}
+
+func rateResponse(status int, remaining string, header ...string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+	if remaining != "" {
+		resp.Header.Set("X-RateLimit-Remaining", remaining)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		resp.Header.Set(header[i], header[i+1])
+	}
+	return resp
+}
+
+// Com folga na janela os requests saem na hora; abaixo de rateLimitPaceBelow passam a ser
+// espacados, e no reserve esperam a janela virar.
+func TestRateBudget_PacesAsTheWindowRunsOut(t *testing.T) {
+	slept := fakeClock(t)
+
+	budget.observe(rateResponse(http.StatusOK, "80"))
+	if err := budget.acquire(rateLimitMaxWait); err != nil || *slept != 0 {
+		t.Fatalf("com folga: err=%v, dormiu %v", err, *slept)
+	}
+
+	budget.observe(rateResponse(http.StatusOK, "10"))
+	if err := budget.acquire(rateLimitMaxWait); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if err := budget.acquire(rateLimitMaxWait); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if *slept == 0 {
+		t.Fatal("abaixo de rateLimitPaceBelow o segundo request deveria esperar")
+	}
+	if *slept >= rateLimitWindow {
+		t.Fatalf("espacamento nao deveria esperar a janela inteira, esperou %v", *slept)
+	}
+
+	*slept = 0
+	budget.observe(rateResponse(http.StatusOK, strconv.Itoa(rateLimitReserve)))
+	if err := budget.acquire(rateLimitMaxWait); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if *slept == 0 {
+		t.Fatal("no reserve o request deveria esperar a janela virar")
+	}
+}
+
+// Espera maior que o aceito nao dorme: devolve ErrRateLimited na hora, para quem tem resposta
+// salva usar a salva.
+func TestRateBudget_RefusesWaitsOverMax(t *testing.T) {
+	slept := fakeClock(t)
+
+	budget.observe(rateResponse(http.StatusTooManyRequests, "0", "Retry-After", "30"))
+	if err := budget.acquire(staleMaxWait); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("esperava ErrRateLimited, obteve %v", err)
+	}
+	if *slept != 0 {
+		t.Fatalf("recusa nao deveria dormir, dormiu %v", *slept)
+	}
+	if err := budget.acquire(rateLimitMaxWait); err != nil {
+		t.Fatalf("30s cabe no maximo: %v", err)
+	}
+	if *slept != 30*time.Second {
+		t.Fatalf("esperava dormir o Retry-After (30s), dormiu %v", *slept)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"12":                            12 * time.Second,
+		"":                              rateLimitWindow,
+		"lixo":                          rateLimitWindow,
+		"Thu, 01 Oct 2026 12:00:20 GMT": 20 * time.Second,
+	}
+	for v, want := range cases {
+		if got := retryAfter(v, now); got != want {
+			t.Errorf("retryAfter(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+// Um 429 e tentado de novo uma vez, depois do Retry-After, em vez de virar erro do passe.
+func TestFetchAnilist_RetriesOnceAfter429(t *testing.T) {
+	calls := 0
+	defer MockAniListDo(func(_ *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return rateResponse(http.StatusTooManyRequests, "0", "Retry-After", "1"), nil
+		}
+		resp := rateResponse(http.StatusOK, "89")
+		resp.Body = io.NopCloser(strings.NewReader(`{"data":{"Page":{"mediaList":[]}}}`))
+		return resp, nil
+	})()
+
+	if _, err := GetAllCurrentAnime("user", []string{"CURRENT"}); err != nil {
+		t.Fatalf("esperava sucesso na segunda tentativa: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("esperava 2 requests, obteve %d", calls)
+	}
+}
@@ -0,0 +1,184 @@
+package anilist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// responseCacheMaxAge e quanto uma resposta salva ainda serve. Passado isso ela e descartada no
+// proximo snapshot: uma consulta que ninguem mais faz (um anime que saiu da lista) nao pode
+// crescer o arquivo para sempre, e dados de mais de um mes ja nao dizem o que baixar.
+const responseCacheMaxAge = 30 * 24 * time.Hour
+
+// cachedResponse e a ultima resposta boa de uma consulta, guardada crua: quem le e o tipo da
+// funcao que pediu, e guardar o corpo evita um tipo por consulta no arquivo.
+type cachedResponse struct {
+	SavedAt time.Time       `json:"saved_at"`
+	Body    json.RawMessage `json:"body"`
+}
+
+// responseCache e o cache de respostas que sobrevive ao restart e a queda da AniList. Ao
+// contrario dos ttlCache, ele nunca responde no lugar da AniList quando ela responde: so quando
+// ela falhou por indisponibilidade (rede, 5xx, 429). A persistencia e do daemon (LoadResponseCache
+// no boot, ResponseCacheSnapshot ao fim de cada passe); o pacote so guarda em memoria.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+	dirty   bool
+}
+
+var diskCache = &responseCache{entries: map[string]cachedResponse{}}
+
+func (c *responseCache) get(key string) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	return e, ok
+}
+
+func (c *responseCache) put(key string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cachedResponse{SavedAt: time.Now(), Body: append(json.RawMessage(nil), body...)}
+	c.dirty = true
+}
+
+func (c *responseCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	c.dirty = false
+}
+
+// LoadResponseCache fills the in-memory response cache from the daemon's saved file. Entries
+// already in memory (fetched before the file was read) win over the saved ones.
+func LoadResponseCache(data []byte) error {
+	var saved map[string]cachedResponse
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse anilist response cache: %w", err)
+	}
+	diskCache.mu.Lock()
+	defer diskCache.mu.Unlock()
+	for k, e := range saved {
+		if _, ok := diskCache.entries[k]; !ok {
+			diskCache.entries[k] = e
+		}
+	}
+	return nil
+}
+
+// ResponseCacheSnapshot returns the cache to be saved, pruned of entries older than 30 days.
+// The bool is false when nothing changed since the last snapshot.
+func ResponseCacheSnapshot() ([]byte, bool, error) {
+	diskCache.mu.Lock()
+	defer diskCache.mu.Unlock()
+	if !diskCache.dirty {
+		return nil, false, nil
+	}
+	cutoff := time.Now().Add(-responseCacheMaxAge)
+	for k, e := range diskCache.entries {
+		if e.SavedAt.Before(cutoff) {
+			delete(diskCache.entries, k)
+		}
+	}
+	data, err := json.Marshal(diskCache.entries)
+	if err != nil {
+		return nil, false, err
+	}
+	diskCache.dirty = false
+	return data, true, nil
+}
+
+// staleUse e o que foi respondido do cache desde o ultimo ResetStaleUse: o passe do daemon zera
+// no inicio e le no fim para marcar o CheckReport.
+//
+// ponytail: e do processo, nao do passe. Um handler da API que respondeu velho durante o passe
+// tambem marca o passe. Os dois so acontecem com a AniList fora, entao o passe quase sempre foi
+// velho de fato; separar exigiria levar um contexto por todas as funcoes do pacote.
+var staleUse struct {
+	sync.Mutex
+	count  int
+	oldest time.Time
+}
+
+// ResetStaleUse starts a new window for StaleSince.
+func ResetStaleUse() {
+	staleUse.Lock()
+	defer staleUse.Unlock()
+	staleUse.count = 0
+	staleUse.oldest = time.Time{}
+}
+
+// StaleSince reports whether any response since ResetStaleUse came from the saved cache
+// because AniList was unavailable, and when the oldest of those was fetched.
+func StaleSince() (time.Time, bool) {
+	staleUse.Lock()
+	defer staleUse.Unlock()
+	return staleUse.oldest, staleUse.count > 0
+}
+
+func markStale(savedAt time.Time) {
+	staleUse.Lock()
+	defer staleUse.Unlock()
+	staleUse.count++
+	if staleUse.oldest.IsZero() || savedAt.Before(staleUse.oldest) {
+		staleUse.oldest = savedAt
+	}
+}
+
+// unavailableError e a falha que o cache de respostas cobre: a AniList nao respondeu (rede, 5xx,
+// 429). 404, 401 e 400 sao respostas de verdade e passam direto. Embrulha sem mudar a mensagem.
+type unavailableError struct{ err error }
+
+func (e *unavailableError) Error() string { return e.err.Error() }
+func (e *unavailableError) Unwrap() error { return e.err }
+
+func isUnavailable(err error) bool {
+	var u *unavailableError
+	return errors.As(err, &u)
+}
+
+func responseCacheKey(query string, variables RequestVariables) string {
+	vars, _ := json.Marshal(variables) // mapa: chaves ordenadas, a chave e estavel
+	sum := sha256.Sum256(append([]byte(query+"\x00"), vars...))
+	return hex.EncodeToString(sum[:16])
+}
+
+// sendCachedAnilistRequest e o sendAnilistRequest das consultas de lista e de midia, as que o
+// passe precisa para rodar: a resposta boa e guardada, e uma falha de disponibilidade devolve a
+// ultima guardada (marcada em staleUse) em vez do erro. Com resposta guardada, o orcamento
+// tambem espera menos (staleMaxWait): servir velho na hora e melhor que segurar a tela.
+func sendCachedAnilistRequest[T any](query string, variables RequestVariables) (*T, error) {
+	key := responseCacheKey(query, variables)
+	saved, hasSaved := diskCache.get(key)
+	maxWait := rateLimitMaxWait
+	if hasSaved {
+		maxWait = staleMaxWait
+	}
+
+	body, err := fetchAnilist("", query, variables, maxWait)
+	if err == nil {
+		resp, err := decodeAnilistResponse[T](body)
+		if err == nil {
+			diskCache.put(key, body)
+		}
+		return resp, err
+	}
+	if !hasSaved || !isUnavailable(err) {
+		return nil, err
+	}
+	resp, decodeErr := decodeAnilistResponse[T](saved.Body)
+	if decodeErr != nil {
+		return nil, err
+	}
+	markStale(saved.SavedAt)
+	logger.Logger.Warn().Err(err).Time("saved_at", saved.SavedAt).Msg("AniList unavailable; using the saved response")
+	return resp, nil
+}
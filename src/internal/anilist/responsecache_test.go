@@ -0,0 +1,100 @@
+package anilist
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const cachedListBody = `{"data":{"Page":{"mediaList":[{"id":1,"media":{"id":10}}]}}}`
+
+// mockStatus responde body com 200 enquanto *status for 200, e so o status depois.
+func mockStatus(status *int) func() {
+	return MockAniListDo(func(_ *http.Request) (*http.Response, error) {
+		body := ""
+		if *status == http.StatusOK {
+			body = cachedListBody
+		}
+		return &http.Response{StatusCode: *status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})
+}
+
+// A AniList fora do ar (5xx) devolve a ultima resposta boa, e o uso fica marcado para o
+// CheckReport.
+func TestSendCachedAnilistRequest_FallsBackWhenUnavailable(t *testing.T) {
+	status := http.StatusOK
+	defer mockStatus(&status)()
+	statuses := []string{"CURRENT"}
+
+	if _, err := GetAllCurrentAnime("user", statuses); err != nil {
+		t.Fatalf("primeira busca falhou: %v", err)
+	}
+	if _, stale := StaleSince(); stale {
+		t.Fatal("resposta nova nao e velha")
+	}
+
+	status = http.StatusServiceUnavailable
+	resp, err := GetAllCurrentAnime("user", statuses)
+	if err != nil {
+		t.Fatalf("esperava a resposta salva, obteve erro: %v", err)
+	}
+	if len(resp.Data.Page.MediaList) != 1 {
+		t.Fatalf("resposta salva com %d entradas, esperava 1", len(resp.Data.Page.MediaList))
+	}
+	if _, stale := StaleSince(); !stale {
+		t.Fatal("uso da resposta salva deveria ficar marcado")
+	}
+
+	// Consulta que nunca respondeu nao tem o que devolver.
+	if _, err := GetAllCurrentAnime("outra", statuses); err == nil {
+		t.Fatal("sem resposta salva o erro deveria passar")
+	}
+}
+
+// 404 e resposta de verdade: a salva nao pode esconder que a entrada sumiu.
+func TestSendCachedAnilistRequest_NoFallbackOnNotFound(t *testing.T) {
+	status := http.StatusOK
+	defer mockStatus(&status)()
+	statuses := []string{"CURRENT"}
+
+	if _, err := GetAllCurrentAnime("user", statuses); err != nil {
+		t.Fatalf("primeira busca falhou: %v", err)
+	}
+	status = http.StatusNotFound
+	if _, err := GetAllCurrentAnime("user", statuses); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("esperava ErrNotFound, obteve %v", err)
+	}
+	if _, stale := StaleSince(); stale {
+		t.Fatal("404 nao deveria marcar uso de resposta salva")
+	}
+}
+
+// O snapshot so sai quando algo mudou, e carregado de volta serve de fallback depois de um
+// restart (memoria limpa).
+func TestResponseCacheSnapshot_RoundTrip(t *testing.T) {
+	status := http.StatusOK
+	defer mockStatus(&status)()
+	statuses := []string{"CURRENT"}
+
+	if _, err := GetAllCurrentAnime("user", statuses); err != nil {
+		t.Fatalf("busca falhou: %v", err)
+	}
+	data, changed, err := ResponseCacheSnapshot()
+	if err != nil || !changed {
+		t.Fatalf("snapshot: changed=%v err=%v", changed, err)
+	}
+	if _, changed, _ := ResponseCacheSnapshot(); changed {
+		t.Fatal("segundo snapshot sem mudanca deveria vir vazio")
+	}
+
+	clearCaches()
+	if err := LoadResponseCache(data); err != nil {
+		t.Fatalf("LoadResponseCache: %v", err)
+	}
+	status = http.StatusBadGateway
+	if _, err := GetAllCurrentAnime("user", statuses); err != nil {
+		t.Fatalf("esperava a resposta carregada do arquivo: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package anilist
+
+import (
+	"fmt"
+	"time"
+)
+
+// seasonalCache guarda a temporada inteira por (temporada, ano). As regras de auto-inscricao
+// sao avaliadas a cada passe e a cada abertura da tela de regras, e todas as regras de uma
+// mesma temporada leem a mesma lista: uma hora de atraso para um anime recem-anunciado e
+// barato perto de paginar a temporada inteira a cada check_interval.
+var seasonalCache = newTTLCache[[]SeasonalMedia]()
+
+const seasonalTTL = time.Hour
+
+// maxSeasonalPages limita a paginacao: uma temporada tem 150 a 300 entradas somando TV, ONA,
+// filmes e curtas. Dez paginas de 50 sobram; o limite so segura uma pageInfo que nunca acaba.
+const maxSeasonalPages = 10
+
+type MediaSeason string
+
+const (
+	MediaSeasonWinter MediaSeason = "WINTER"
+	MediaSeasonSpring MediaSeason = "SPRING"
+	MediaSeasonSummer MediaSeason = "SUMMER"
+	MediaSeasonFall   MediaSeason = "FALL"
+)
+
+// SeasonalMedia e uma entrada da consulta de temporada: so os campos que as regras de
+// auto-inscricao avaliam e a tela de regras mostra. Quem vira avulso e lido de novo com
+// GetMediaByID, como qualquer avulso.
+type SeasonalMedia struct {
+	Id              int         `json:"id"`
+	Title           Title       `json:"title"`
+	Format          MediaFormat `json:"format"`
+	Status          MediaStatus `json:"status"`
+	Episodes        *int        `json:"episodes"`
+	Genres          []string    `json:"genres"`
+	Popularity      int         `json:"popularity"`
+	AverageScore    *int        `json:"averageScore"`
+	CountryOfOrigin string      `json:"countryOfOrigin"`
+	IsAdult         bool        `json:"isAdult"`
+	CoverImage      CoverImage  `json:"coverImage"`
+}
+
+// CurrentSeason e a temporada da AniList em que now cai: inverno de janeiro a marco, e assim
+// por diante. Dezembro e FALL do proprio ano, como a AniList conta.
+func CurrentSeason(now time.Time) (MediaSeason, int) {
+	switch now.Month() {
+	case time.January, time.February, time.March:
+		return MediaSeasonWinter, now.Year()
+	case time.April, time.May, time.June:
+		return MediaSeasonSpring, now.Year()
+	case time.July, time.August, time.September:
+		return MediaSeasonSummer, now.Year()
+	}
+	return MediaSeasonFall, now.Year()
+}
+
+// GetSeasonalMedia devolve todos os animes de uma temporada, do mais popular para o menos.
+// Cada pagina passa pelo cache de respostas: com a AniList fora, as regras avaliam a ultima
+// temporada boa em vez de nao avaliar nada.
+//
+// Nenhum filtro vai na query. As regras sao avaliadas aqui, em Go, porque a tela precisa dizer
+// POR QUE cada anime casou — e porque N regras da mesma temporada custam uma consulta so.
+func GetSeasonalMedia(season MediaSeason, year int) ([]SeasonalMedia, error) {
+	key := fmt.Sprintf("%s-%d", season, year)
+	if cached, ok := seasonalCache.get(key); ok {
+		return append([]SeasonalMedia(nil), cached...), nil
+	}
+
+	query := `
+		query GetSeasonalMedia($season: MediaSeason, $year: Int, $page: Int) {
+			Page(page: $page, perPage: 50) {
+				pageInfo {
+					hasNextPage
+				}
+				media(season: $season, seasonYear: $year, type: ANIME, sort: POPULARITY_DESC) {
+					id
+					title {
+						english
+						romaji
+					}
+					format
+					status
+					episodes
+					genres
+					popularity
+					averageScore
+					countryOfOrigin
+					isAdult
+					coverImage {
+						large
+						medium
+					}
+				}
+			}
+		}
+	`
+
+	type response struct {
+		Data struct {
+			Page struct {
+				PageInfo struct {
+					HasNextPage bool `json:"hasNextPage"`
+				} `json:"pageInfo"`
+				Media []SeasonalMedia `json:"media"`
+			} `json:"Page"`
+		} `json:"data"`
+	}
+
+	var all []SeasonalMedia
+	for page := 1; page <= maxSeasonalPages; page++ {
+		resp, err := sendCachedAnilistRequest[response](query, RequestVariables{
+			"season": string(season),
+			"year":   year,
+			"page":   page,
+		})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Data.Page.Media...)
+		if !resp.Data.Page.PageInfo.HasNextPage {
+			break
+		}
+	}
+
+	seasonalCache.set(key, all, seasonalTTL)
+	return append([]SeasonalMedia(nil), all...), nil
+}
@@ -0,0 +1,72 @@
+package anilist
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A temporada vem paginada ate hasNextPage=false, e a segunda leitura sai do cache.
+func TestGetSeasonalMedia_PagesAndCaches(t *testing.T) {
+	calls := 0
+	defer MockAniListDo(func(r *http.Request) (*http.Response, error) {
+		calls++
+		var req struct {
+			Variables struct {
+				Season string `json:"season"`
+				Year   int    `json:"year"`
+				Page   int    `json:"page"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Variables.Season != "SPRING" || req.Variables.Year != 2026 {
+			t.Fatalf("temporada errada na query: %+v", req.Variables)
+		}
+		body := fmt.Sprintf(`{"data":{"Page":{"pageInfo":{"hasNextPage":%t},"media":[
+			{"id":%d,"format":"TV","genres":["Action"],"popularity":100,"averageScore":null,"countryOfOrigin":"JP"}]}}}`,
+			req.Variables.Page < 2, req.Variables.Page)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})()
+
+	media, err := GetSeasonalMedia(MediaSeasonSpring, 2026)
+	if err != nil {
+		t.Fatalf("GetSeasonalMedia: %v", err)
+	}
+	if len(media) != 2 || media[0].Id != 1 || media[1].Id != 2 {
+		t.Fatalf("quero as duas paginas em ordem, veio %+v", media)
+	}
+	if media[0].AverageScore != nil || media[0].CountryOfOrigin != "JP" || media[0].Genres[0] != "Action" {
+		t.Errorf("campos mapeados errado: %+v", media[0])
+	}
+
+	if _, err := GetSeasonalMedia(MediaSeasonSpring, 2026); err != nil {
+		t.Fatalf("segunda leitura: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("quero 2 requests (uma por pagina, depois cache), veio %d", calls)
+	}
+}
+
+func TestCurrentSeason(t *testing.T) {
+	tests := []struct {
+		month  time.Month
+		season MediaSeason
+	}{
+		{time.January, MediaSeasonWinter},
+		{time.April, MediaSeasonSpring},
+		{time.September, MediaSeasonSummer},
+		{time.December, MediaSeasonFall},
+	}
+	for _, tt := range tests {
+		season, year := CurrentSeason(time.Date(2026, tt.month, 15, 0, 0, 0, 0, time.UTC))
+		if season != tt.season || year != 2026 {
+			t.Errorf("%s: quero %s 2026, veio %s %d", tt.month, tt.season, season, year)
+		}
+	}
+}
@@ -93,7 +93,9 @@ func SearchMedia(term string, includeUnreleased bool) ([]MediaSearchResult, erro
 // Os campos pedidos sao os MESMOS de getMediaListEntry (inclusive synonyms, relations e o id
 // de cada no do airingSchedule): a busca por anime e searchNyaaForSingleEpisode dependem
 // de synonyms e relations (offset de temporada dividida via PREQUEL), e todo o resto do app
-// chaveia episodio pelo id do no.
+// chaveia episodio pelo id do no. Alem deles vem o que so os metadados da biblioteca usam
+// (description, genres, studios, streamingEpisodes, e a arte: extraLarge, bannerImage) — nas
+// queries de lista isso pesaria em todo passe, aqui e uma midia por vez.
 //
 // (nil, nil) quando a AniList nao conhece o id.
 func GetMediaByID(mediaID int) (*MediaList, error) {
@@ -109,14 +111,33 @@ func GetMediaByID(mediaID int) (*MediaList, error) {
 				episodes
 				format
 				status
+				seasonYear
 				title {
 					english
 					romaji
 				}
 				synonyms
+				description(asHtml: false)
+				genres
+				studios(isMain: true) {
+					nodes {
+						name
+					}
+				}
+				streamingEpisodes {
+					title
+				}
 				relations {
 					edges {
 						node {
+							id
+							format
+							status
+							startDate {
+								year
+								month
+								day
+							}
 							title {
 								english
 								romaji
@@ -128,9 +149,11 @@ func GetMediaByID(mediaID int) (*MediaList, error) {
 					}
 				}
 				coverImage {
+					extraLarge
 					large
 					medium
 				}
+				bannerImage
 				airingSchedule {
 					nodes {
 						airingAt
@@ -154,7 +177,7 @@ func GetMediaByID(mediaID int) (*MediaList, error) {
 		} `json:"data"`
 	}
 
-	resp, err := sendAnilistRequest[response](query, RequestVariables{"id": mediaID})
+	resp, err := sendCachedAnilistRequest[response](query, RequestVariables{"id": mediaID})
 	if errors.Is(err, ErrNotFound) {
 		mediaByIDCache.set(key, nil, mediaByIDTTL)
 		return nil, nil
@@ -0,0 +1,197 @@
+package anilist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// Escrita na lista da AniList (decisions.md #82). A leitura continua anonima, pelas listas
+// publicas: o token so entra nas mutations e no Viewer, e uma conta sem login segue funcionando
+// como antes.
+
+var aniListOAuthURL = "https://anilist.co/api/v2/oauth"
+
+// Token is an AniList access token. AniList issues no refresh token: when it expires (a year
+// after the login) the account logs in again.
+type Token struct {
+	AccessToken string
+	// ExpiresAt is zero when the expiry is unknown.
+	ExpiresAt time.Time
+}
+
+// Viewer is the account a token belongs to.
+type Viewer struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// AuthorizeURL is the AniList login page for the app's API client. With implicit, AniList
+// answers with the token itself, shown on the client's redirect page (the "pin" page when the
+// client was registered with https://anilist.co/api/v2/oauth/pin), for the user to paste; it needs
+// no client secret. Otherwise it answers with a code at redirectURI, which ExchangeCode trades for
+// the token.
+func AuthorizeURL(clientID, redirectURI, state string, implicit bool) string {
+	q := url.Values{"client_id": {clientID}}
+	if implicit {
+		q.Set("response_type", "token")
+	} else {
+		q.Set("response_type", "code")
+		q.Set("redirect_uri", redirectURI)
+		q.Set("state", state)
+	}
+	return aniListOAuthURL + "/authorize?" + q.Encode()
+}
+
+// ExchangeCode trades the code of an authorization-code login for the token. redirectURI must be
+// the one the login was started with, byte for byte.
+func ExchangeCode(clientID, clientSecret, redirectURI, code string) (Token, error) {
+	body, err := json.Marshal(map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     clientID,
+		"client_secret": clientSecret,
+		"redirect_uri":  redirectURI,
+		"code":          code,
+	})
+	if err != nil {
+		return Token{}, err
+	}
+	req, err := http.NewRequest(http.MethodPost, aniListOAuthURL+"/token", bytes.NewReader(body))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpDo(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("error exchanging the AniList code: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Token{}, fmt.Errorf("error reading the AniList token response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// O corpo de erro do OAuth e {"error":"invalid_request","message":"..."}.
+		var e struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &e)
+		return Token{}, fmt.Errorf("AniList refused the code (%d): %s", resp.StatusCode, strings.TrimSpace(e.Error+" "+e.Message))
+	}
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Token{}, fmt.Errorf("error unmarshaling the AniList token response: %v", err)
+	}
+	if out.AccessToken == "" {
+		return Token{}, errors.New("AniList answered without an access token")
+	}
+	tok := Token{AccessToken: out.AccessToken, ExpiresAt: TokenExpiry(out.AccessToken)}
+	if tok.ExpiresAt.IsZero() && out.ExpiresIn > 0 {
+		tok.ExpiresAt = time.Now().Add(time.Duration(out.ExpiresIn) * time.Second)
+	}
+	return tok, nil
+}
+
+// TokenExpiry reads the expiry of an AniList token, a JWT, from its "exp" claim without
+// checking the signature (AniList checks it on every request). Zero when the token is not a JWT.
+func TokenExpiry(accessToken string) time.Time {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}
+	}
+	// O exp vem como numero, as vezes com fracao.
+	secs, err := strconv.ParseFloat(claims.Exp.String(), 64)
+	if err != nil || secs <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(secs), 0)
+}
+
+// GetViewer returns the account the token belongs to. It is how a login is checked against the
+// configured username: whoever logs in on the AniList page is who the token writes as.
+func GetViewer(token string) (Viewer, error) {
+	type response struct {
+		Data struct {
+			Viewer *Viewer `json:"Viewer"`
+		} `json:"data"`
+	}
+	resp, err := sendAnilistRequestAs[response](token, `query{Viewer{id name}}`, nil)
+	if err != nil {
+		return Viewer{}, err
+	}
+	if resp.Data.Viewer == nil {
+		return Viewer{}, ErrUnauthorized
+	}
+	return *resp.Data.Viewer, nil
+}
+
+// SetProgress sets the watched-episode count of the token's list entry for the media.
+func SetProgress(token string, mediaID, progress int) error {
+	return saveMediaListEntry(token, RequestVariables{"mediaId": mediaID, "progress": progress})
+}
+
+// SetStatus moves the token's list entry for the media to another status (COMPLETED, ...).
+func SetStatus(token string, mediaID int, status MediaListStatus) error {
+	return saveMediaListEntry(token, RequestVariables{"mediaId": mediaID, "status": status})
+}
+
+// AddToList adds the media to the token's list with a status and a progress. For an anime
+// already on the list it updates both, which is what SaveMediaListEntry does either way.
+func AddToList(token string, mediaID int, status MediaListStatus, progress int) error {
+	return saveMediaListEntry(token, RequestVariables{"mediaId": mediaID, "status": status, "progress": progress})
+}
+
+// saveMediaListEntry roda a mutation com so as variaveis dadas: um campo ausente na mutation
+// fica como esta na AniList, e e assim que SetProgress nao mexe no status.
+func saveMediaListEntry(token string, variables RequestVariables) error {
+	if token == "" {
+		return ErrUnauthorized
+	}
+	type response struct {
+		Data struct {
+			SaveMediaListEntry *struct {
+				ID int `json:"id"`
+			} `json:"SaveMediaListEntry"`
+		} `json:"data"`
+	}
+	query := `mutation($mediaId:Int,$status:MediaListStatus,$progress:Int){SaveMediaListEntry(mediaId:$mediaId,status:$status,progress:$progress){id}}`
+	resp, err := sendAnilistRequestAs[response](token, query, variables)
+	if err != nil {
+		return err
+	}
+	if resp.Data.SaveMediaListEntry == nil {
+		return fmt.Errorf("AniList did not save the list entry of media %v", variables["mediaId"])
+	}
+	// A tela le as listas por cache; sem limpar, o progresso novo so apareceria em um minuto.
+	customListsCache.clear()
+	frontendListCache.clear()
+	logger.Logger.Debug().Any("variables", variables).Msg("AniList list entry saved")
+	return nil
+}
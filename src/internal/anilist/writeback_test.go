@@ -0,0 +1,183 @@
+package anilist
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordedRequest e um request que o mock recebeu, ja decodificado.
+type recordedRequest struct {
+	url       string
+	auth      string
+	query     string
+	variables map[string]any
+	body      map[string]any
+}
+
+func mockAniList(t *testing.T, respond func(r recordedRequest) (int, string)) (*[]recordedRequest, func()) {
+	t.Helper()
+	var reqs []recordedRequest
+	restore := MockAniListDo(func(req *http.Request) (*http.Response, error) {
+		raw, _ := io.ReadAll(req.Body)
+		rec := recordedRequest{url: req.URL.String(), auth: req.Header.Get("Authorization")}
+		var gql GraphQLRequest
+		if json.Unmarshal(raw, &gql) == nil {
+			rec.query, rec.variables = gql.Query, gql.Variables
+		}
+		_ = json.Unmarshal(raw, &rec.body)
+		reqs = append(reqs, rec)
+		status, body := respond(rec)
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})
+	return &reqs, restore
+}
+
+// fakeJWT monta um token com o exp dado; a assinatura nao importa para TokenExpiry.
+func fakeJWT(exp int64) string {
+	payload, _ := json.Marshal(map[string]any{"exp": exp, "sub": "1"})
+	return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func TestSetProgressSendsTheTokenAndOnlyTheProgress(t *testing.T) {
+	reqs, restore := mockAniList(t, func(recordedRequest) (int, string) {
+		return http.StatusOK, `{"data":{"SaveMediaListEntry":{"id":99}}}`
+	})
+	defer restore()
+
+	if err := SetProgress("tok", 21, 5); err != nil {
+		t.Fatalf("SetProgress: %v", err)
+	}
+	r := (*reqs)[0]
+	if r.auth != "Bearer tok" {
+		t.Errorf("Authorization = %q", r.auth)
+	}
+	if !strings.HasPrefix(r.query, "mutation") {
+		t.Errorf("query = %q", r.query)
+	}
+	if r.variables["mediaId"] != float64(21) || r.variables["progress"] != float64(5) {
+		t.Errorf("variables = %v", r.variables)
+	}
+	// Sem status nas variaveis, a AniList mantem o da entrada.
+	if _, ok := r.variables["status"]; ok {
+		t.Errorf("SetProgress must not send a status, got %v", r.variables)
+	}
+}
+
+func TestSaveMediaListEntryErrors(t *testing.T) {
+	_, restore := mockAniList(t, func(recordedRequest) (int, string) {
+		return http.StatusUnauthorized, `{"errors":[{"message":"Invalid token"}]}`
+	})
+	if err := SetStatus("tok", 21, MediaListStatusCompleted); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("401: esperava ErrUnauthorized, obteve %v", err)
+	}
+	restore()
+
+	_, restore = mockAniList(t, func(recordedRequest) (int, string) {
+		return http.StatusBadRequest, `{"errors":[{"message":"validation"}]}`
+	})
+	defer restore()
+	if err := AddToList("tok", 21, MediaListStatusCurrent, 0); err == nil || !strings.Contains(err.Error(), "validation") {
+		t.Errorf("400: esperava a mensagem da AniList, obteve %v", err)
+	}
+	if err := SetProgress("", 21, 1); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("sem token: esperava ErrUnauthorized, obteve %v", err)
+	}
+}
+
+// A escrita limpa o cache da lista: sem isso a tela mostraria o progresso antigo por um minuto.
+func TestWriteInvalidatesTheListCache(t *testing.T) {
+	listCalls := 0
+	_, restore := mockAniList(t, func(r recordedRequest) (int, string) {
+		if strings.HasPrefix(r.query, "mutation") {
+			return http.StatusOK, `{"data":{"SaveMediaListEntry":{"id":1}}}`
+		}
+		listCalls++
+		return http.StatusOK, `{"data":{"Page":{"mediaList":[{"id":1,"progress":2,"media":{"id":10}}]}}}`
+	})
+	defer restore()
+
+	statuses := []string{"CURRENT"}
+	_, _ = GetFrontendAnimeList("user", statuses)
+	if err := SetProgress("tok", 10, 3); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = GetFrontendAnimeList("user", statuses)
+	if listCalls != 2 {
+		t.Errorf("esperava 2 buscas da lista, obteve %d", listCalls)
+	}
+}
+
+func TestExchangeCode(t *testing.T) {
+	exp := time.Now().Add(365 * 24 * time.Hour).Unix()
+	jwt := fakeJWT(exp)
+	reqs, restore := mockAniList(t, func(recordedRequest) (int, string) {
+		return http.StatusOK, `{"token_type":"Bearer","expires_in":31536000,"access_token":"` + jwt + `"}`
+	})
+	defer restore()
+
+	tok, err := ExchangeCode("7", "secret", "http://localhost:8091/api/v1/anilist/oauth/callback", "the-code")
+	if err != nil {
+		t.Fatalf("ExchangeCode: %v", err)
+	}
+	if tok.AccessToken != jwt || tok.ExpiresAt.Unix() != exp {
+		t.Errorf("token = %+v", tok)
+	}
+	r := (*reqs)[0]
+	if !strings.HasSuffix(r.url, "/oauth/token") || r.body["code"] != "the-code" || r.body["grant_type"] != "authorization_code" || r.body["client_secret"] != "secret" {
+		t.Errorf("request = %+v", r)
+	}
+}
+
+func TestExchangeCodeRefused(t *testing.T) {
+	_, restore := mockAniList(t, func(recordedRequest) (int, string) {
+		return http.StatusBadRequest, `{"error":"invalid_request","message":"The authorization code has expired"}`
+	})
+	defer restore()
+	if _, err := ExchangeCode("7", "secret", "http://x/cb", "old"); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("esperava o motivo da AniList, obteve %v", err)
+	}
+}
+
+func TestAuthorizeURL(t *testing.T) {
+	u, _ := url.Parse(AuthorizeURL("7", "http://localhost:8091/cb", "st", false))
+	q := u.Query()
+	if q.Get("response_type") != "code" || q.Get("redirect_uri") != "http://localhost:8091/cb" || q.Get("state") != "st" || q.Get("client_id") != "7" {
+		t.Errorf("code login URL = %s", u)
+	}
+	u, _ = url.Parse(AuthorizeURL("7", "http://localhost:8091/cb", "", true))
+	if u.Query().Get("response_type") != "token" || u.Query().Has("redirect_uri") {
+		t.Errorf("implicit login URL = %s", u)
+	}
+}
+
+func TestGetViewerAndTokenExpiry(t *testing.T) {
+	_, restore := mockAniList(t, func(r recordedRequest) (int, string) {
+		if r.auth != "Bearer tok" {
+			return http.StatusUnauthorized, `{}`
+		}
+		return http.StatusOK, `{"data":{"Viewer":{"id":42,"name":"Someone"}}}`
+	})
+	defer restore()
+
+	v, err := GetViewer("tok")
+	if err != nil || v.ID != 42 || v.Name != "Someone" {
+		t.Errorf("viewer = %+v, %v", v, err)
+	}
+	if _, err := GetViewer("bad"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("esperava ErrUnauthorized, obteve %v", err)
+	}
+
+	if got := TokenExpiry(fakeJWT(1700000000)); got.Unix() != 1700000000 {
+		t.Errorf("TokenExpiry = %v", got)
+	}
+	if !TokenExpiry("not-a-jwt").IsZero() {
+		t.Error("um token que nao e JWT tem validade desconhecida")
+	}
+}
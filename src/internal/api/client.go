@@ -1,12 +1,14 @@
 package api
 
 import (
+	"AutoAnimeDownloader/src/internal/daemon"
 	"AutoAnimeDownloader/src/internal/files"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -208,3 +210,89 @@ func (c *Client) StopLoop() error {
 
 	return c.parseResponse(resp, nil)
 }
+
+// GetDataUsage devolve o trafego do ciclo atual e dos anteriores; animeID > 0 restringe os
+// numeros a um anime.
+func (c *Client) GetDataUsage(animeID int) (*DataUsageResponse, error) {
+	path := "/api/v1/data-usage"
+	if animeID > 0 {
+		path += "?anime_id=" + strconv.Itoa(animeID)
+	}
+	resp, err := c.doRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var usage DataUsageResponse
+	if err := c.parseResponse(resp, &usage); err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+// PauseAll pausa todos os torrents; d > 0 retoma sozinho depois desse tempo.
+func (c *Client) PauseAll(d time.Duration) (*PauseAllResponse, error) {
+	resp, err := c.doRequest(http.MethodPost, "/api/v1/torrents/pause-all", PauseAllRequest{DurationMinutes: int(d / time.Minute)})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var pauseResp PauseAllResponse
+	if err := c.parseResponse(resp, &pauseResp); err != nil {
+		return nil, err
+	}
+
+	return &pauseResp, nil
+}
+
+func (c *Client) ResumeAll() error {
+	resp, err := c.doRequest(http.MethodPost, "/api/v1/torrents/resume-all", nil)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return c.parseResponse(resp, nil)
+}
+
+// AuditLibrary devolve os achados da auditoria da biblioteca. Sem o cheque de status antes do
+// parse: o 409 (biblioteca nao configurada) e o 503 (cliente de torrent fora) trazem a causa na
+// mensagem, e e ela que a CLI deve mostrar.
+func (c *Client) AuditLibrary() (*LibraryAuditResponse, error) {
+	resp, err := c.doRequest(http.MethodGet, "/api/v1/library/audit", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var audit LibraryAuditResponse
+	if err := c.parseResponse(resp, &audit); err != nil {
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// RepairLibrary corrige os achados das categorias dadas.
+func (c *Client) RepairLibrary(categories []string) (*daemon.LibraryRepairResult, error) {
+	resp, err := c.doRequest(http.MethodPost, "/api/v1/library/audit/repair", LibraryRepairRequest{Categories: categories})
+	if err != nil {
+		return nil, err
+	}
+
+	var result daemon.LibraryRepairResult
+	if err := c.parseResponse(resp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// anilistLoginTTL e quanto um login iniciado espera a volta da AniList. Passado isso o state
+// some e o callback responde como um state desconhecido.
+const anilistLoginTTL = 10 * time.Minute
+
+// anilistCallbackPath e a URL de volta do login por codigo. O usuario a registra, igual, no
+// cliente de API da AniList.
+const anilistCallbackPath = "/api/v1/anilist/oauth/callback"
+
+// anilistLogins guarda os logins por codigo em andamento, pelo state. Vive em memoria: um
+// restart no meio do login so obriga a clicar de novo. O valor zero e utilizavel.
+type anilistLogins struct {
+	mu      sync.Mutex
+	pending map[string]pendingAnilistLogin
+}
+
+type pendingAnilistLogin struct {
+	username    string
+	redirectURI string
+	expires     time.Time
+}
+
+func (l *anilistLogins) start(username, redirectURI string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	state := hex.EncodeToString(b)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending == nil {
+		l.pending = map[string]pendingAnilistLogin{}
+	}
+	for k, p := range l.pending {
+		if now.After(p.expires) {
+			delete(l.pending, k)
+		}
+	}
+	l.pending[state] = pendingAnilistLogin{username: username, redirectURI: redirectURI, expires: now.Add(anilistLoginTTL)}
+	return state, nil
+}
+
+// take consome o state: cada login volta uma vez so.
+func (l *anilistLogins) take(state string) (pendingAnilistLogin, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p, ok := l.pending[state]
+	delete(l.pending, state)
+	if !ok || time.Now().After(p.expires) {
+		return pendingAnilistLogin{}, false
+	}
+	return p, true
+}
+
+// AnilistAccountStatus is one configured account and its login.
+type AnilistAccountStatus struct {
+	Username  string     `json:"username" example:"someone"`
+	LoggedIn  bool       `json:"logged_in"`
+	Expired   bool       `json:"expired,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// AnilistAccountsResponse lists the accounts and how a login is done.
+type AnilistAccountsResponse struct {
+	Accounts []AnilistAccountStatus `json:"accounts"`
+	// ClientConfigured is false until anilist_client_id is set: no login is possible before.
+	ClientConfigured bool `json:"client_configured"`
+	// Implicit is true when no client secret is set: the login shows a token to paste.
+	Implicit bool `json:"implicit"`
+	// RedirectURI is the URL to register in the AniList API client for the code login.
+	RedirectURI string `json:"redirect_uri" example:"http://localhost:8091/api/v1/anilist/oauth/callback"`
+}
+
+// AnilistLoginResponse is where to send the browser to log in.
+type AnilistLoginResponse struct {
+	URL      string `json:"url" example:"https://anilist.co/api/v2/oauth/authorize?client_id=1&response_type=code"`
+	Implicit bool   `json:"implicit"`
+}
+
+// AnilistTokenRequest is a token pasted from the implicit login.
+type AnilistTokenRequest struct {
+	AccessToken string `json:"access_token"`
+}
+
+// anilistRedirectURI monta a URL do callback a partir de como o navegador chegou aqui, com os
+// headers do proxy reverso quando houver: e ela que a AniList compara com a registrada.
+func anilistRedirectURI(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	host := r.Host
+	if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" {
+		host = fwd
+	}
+	return scheme + "://" + host + anilistCallbackPath
+}
+
+// @Summary      List the AniList accounts and their logins
+// @Description  Returns every configured AniList account with whether it is logged in for write-back, plus the redirect URI to register in the AniList API client
+// @Tags         anilist
+// @Produce      json
+// @Success      200  {object}  SuccessResponse{data=AnilistAccountsResponse}
+// @Failure      405  {object}  SuccessResponse
+// @Failure      500  {object}  SuccessResponse
+// @Router       /anilist/accounts [get]
+func handleAnilistAccounts(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+			return
+		}
+		cfg, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load configs for AniList accounts")
+			JSONInternalError(w, err)
+			return
+		}
+		tokens, err := server.FileManager.LoadAnilistTokens()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load AniList tokens")
+			JSONInternalError(w, err)
+			return
+		}
+
+		now := time.Now()
+		resp := AnilistAccountsResponse{
+			Accounts:         make([]AnilistAccountStatus, 0, len(cfg.AnilistUsernames)),
+			ClientConfigured: cfg.AnilistClientID != "",
+			Implicit:         cfg.AnilistClientSecret == "",
+			RedirectURI:      anilistRedirectURI(r),
+		}
+		for _, username := range cfg.AnilistUsernames {
+			st := AnilistAccountStatus{Username: username}
+			if tok, ok := tokens[username]; ok && tok.AccessToken != "" {
+				st.LoggedIn = tok.Usable(now)
+				st.Expired = !st.LoggedIn
+				if !tok.ExpiresAt.IsZero() {
+					exp := tok.ExpiresAt
+					st.ExpiresAt = &exp
+				}
+			}
+			resp.Accounts = append(resp.Accounts, st)
+		}
+		JSONSuccess(w, http.StatusOK, resp)
+	}
+}
+
+// @Summary      Start an AniList login
+// @Description  Returns the AniList authorization URL for a configured account. With a client secret it is the code login, which comes back to /anilist/oauth/callback; without one it is the implicit login, whose token is pasted into PUT /anilist/accounts/{username}/token
+// @Tags         anilist
+// @Produce      json
+// @Param        username  path      string  true  "Configured AniList username"
+// @Success      200       {object}  SuccessResponse{data=AnilistLoginResponse}
+// @Failure      404       {object}  SuccessResponse
+// @Failure      405       {object}  SuccessResponse
+// @Failure      409       {object}  SuccessResponse
+// @Failure      500       {object}  SuccessResponse
+// @Router       /anilist/accounts/{username}/login [post]
+func handleAnilistLogin(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST is allowed")
+			return
+		}
+		cfg, ok := loadAnilistAccountConfig(server, w, r.PathValue("username"))
+		if !ok {
+			return
+		}
+		if cfg.AnilistClientID == "" {
+			JSONError(w, http.StatusConflict, "ANILIST_CLIENT_NOT_CONFIGURED", "Set the AniList client ID before logging in")
+			return
+		}
+
+		implicit := cfg.AnilistClientSecret == ""
+		redirectURI := anilistRedirectURI(r)
+		state := ""
+		if !implicit {
+			var err error
+			if state, err = server.anilistLogins.start(r.PathValue("username"), redirectURI); err != nil {
+				JSONInternalError(w, err)
+				return
+			}
+		}
+		JSONSuccess(w, http.StatusOK, AnilistLoginResponse{
+			URL:      anilist.AuthorizeURL(cfg.AnilistClientID, redirectURI, state, implicit),
+			Implicit: implicit,
+		})
+	}
+}
+
+// @Summary      AniList login callback
+// @Description  Where AniList sends the browser back after a code login: exchanges the code for a token, checks that the logged-in account is the one the login was started for, saves the token and redirects to the config page
+// @Tags         anilist
+// @Param        code   query  string  true  "Authorization code"
+// @Param        state  query  string  true  "State from the login"
+// @Success      302
+// @Router       /anilist/oauth/callback [get]
+func handleAnilistCallback(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET is allowed")
+			return
+		}
+		// O navegador chega aqui vindo da AniList: o resultado volta para a tela por query, e
+		// nao como JSON que ninguem veria.
+		back := func(param, value string) {
+			w.Header().Del("Content-Type")
+			http.Redirect(w, r, "/#/config?"+url.Values{param: {value}}.Encode(), http.StatusFound)
+		}
+
+		q := r.URL.Query()
+		if e := q.Get("error"); e != "" {
+			back("anilist_login_error", e)
+			return
+		}
+		pending, ok := server.anilistLogins.take(q.Get("state"))
+		if !ok || q.Get("code") == "" {
+			back("anilist_login_error", "unknown or expired login, start it again")
+			return
+		}
+		cfg, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load configs for the AniList callback")
+			back("anilist_login_error", err.Error())
+			return
+		}
+
+		tok, err := anilist.ExchangeCode(cfg.AnilistClientID, cfg.AnilistClientSecret, pending.redirectURI, q.Get("code"))
+		if err != nil {
+			logger.Logger.Warn().Err(err).Str("username", pending.username).Msg("AniList code exchange failed")
+			back("anilist_login_error", err.Error())
+			return
+		}
+		if err := saveAnilistToken(server.FileManager, pending.username, tok); err != nil {
+			back("anilist_login_error", err.Error())
+			return
+		}
+		back("anilist_login", pending.username)
+	}
+}
+
+// @Summary      Save or remove an AniList login
+// @Description  PUT saves a token pasted from the implicit login, after checking it belongs to the account; DELETE logs the account out (only the saved token is removed, AniList keeps the app authorized until revoked there)
+// @Tags         anilist
+// @Accept       json
+// @Produce      json
+// @Param        username  path  string               true   "Configured AniList username"
+// @Param        body      body  AnilistTokenRequest  false  "Token (PUT)"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  SuccessResponse
+// @Failure      404  {object}  SuccessResponse
+// @Failure      405  {object}  SuccessResponse
+// @Failure      500  {object}  SuccessResponse
+// @Router       /anilist/accounts/{username}/token [put]
+// @Router       /anilist/accounts/{username}/token [delete]
+func handleAnilistToken(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := r.PathValue("username")
+		switch r.Method {
+		case http.MethodPut:
+			if _, ok := loadAnilistAccountConfig(server, w, username); !ok {
+				return
+			}
+			var req AnilistTokenRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.AccessToken) == "" {
+				JSONError(w, http.StatusBadRequest, "INVALID_BODY", "Body must be {\"access_token\": \"...\"}")
+				return
+			}
+			token := strings.TrimSpace(req.AccessToken)
+			err := saveAnilistToken(server.FileManager, username, anilist.Token{AccessToken: token, ExpiresAt: anilist.TokenExpiry(token)})
+			if err != nil {
+				JSONError(w, http.StatusBadRequest, "INVALID_TOKEN", err.Error())
+				return
+			}
+			JSONSuccess(w, http.StatusOK, map[string]string{"message": "Logged in"})
+
+		case http.MethodDelete:
+			tokens, err := server.FileManager.LoadAnilistTokens()
+			if err != nil {
+				JSONInternalError(w, err)
+				return
+			}
+			delete(tokens, username)
+			if err := server.FileManager.SaveAnilistTokens(tokens); err != nil {
+				JSONInternalError(w, err)
+				return
+			}
+			JSONSuccess(w, http.StatusOK, map[string]string{"message": "Logged out"})
+
+		default:
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only PUT and DELETE are allowed")
+		}
+	}
+}
+
+// loadAnilistAccountConfig carrega a config e confere que a conta esta configurada; responde o
+// erro e devolve false quando nao.
+func loadAnilistAccountConfig(server *Server, w http.ResponseWriter, username string) (*files.Config, bool) {
+	cfg, err := server.FileManager.LoadConfigs()
+	if err != nil {
+		logger.Logger.Error().Err(err).Msg("Failed to load configs for AniList login")
+		JSONInternalError(w, err)
+		return nil, false
+	}
+	if !slices.Contains(cfg.AnilistUsernames, username) {
+		JSONError(w, http.StatusNotFound, "ACCOUNT_NOT_FOUND", "AniList account \""+username+"\" is not configured")
+		return nil, false
+	}
+	return cfg, true
+}
+
+// errWrongAccount: o login foi feito com outra conta da AniList, e o token escreveria na lista
+// errada.
+var errWrongAccount = errors.New("the AniList login belongs to another account")
+
+// saveAnilistToken confere de quem e o token e o grava para a conta. Logar com outra conta no
+// site da AniList e facil (a sessao do navegador e a de quem usou por ultimo), e aceitar o
+// token ali faria o "assistido" de uma pessoa avancar a lista de outra.
+func saveAnilistToken(fm FileManagerInterface, username string, tok anilist.Token) error {
+	viewer, err := anilist.GetViewer(tok.AccessToken)
+	if err != nil {
+		return fmt.Errorf("AniList did not accept the token: %w", err)
+	}
+	if !strings.EqualFold(viewer.Name, username) {
+		return fmt.Errorf("%w: logged in as %q, expected %q", errWrongAccount, viewer.Name, username)
+	}
+	tokens, err := fm.LoadAnilistTokens()
+	if err != nil {
+		return err
+	}
+	if tokens == nil {
+		tokens = map[string]files.AnilistToken{}
+	}
+	tokens[username] = files.AnilistToken{
+		AccessToken: tok.AccessToken,
+		ExpiresAt:   tok.ExpiresAt,
+		ViewerID:    viewer.ID,
+		ViewerName:  viewer.Name,
+	}
+	if err := fm.SaveAnilistTokens(tokens); err != nil {
+		return err
+	}
+	logger.Logger.Info().Str("username", username).Int("viewer_id", viewer.ID).Msg("AniList account logged in for write-back")
+	return nil
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockAniListViewer responde o Viewer de cada token, a troca do codigo pelo token e as
+// mutations; as mutations ficam em writes.
+func mockAniListViewer(viewers map[string]string, writes *[]string) func() {
+	return anilist.MockAniListDo(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		resp := func(status int, s string) (*http.Response, error) {
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(s))}, nil
+		}
+		if strings.HasSuffix(req.URL.Path, "/oauth/token") {
+			var tr struct {
+				Code string `json:"code"`
+			}
+			_ = json.Unmarshal(body, &tr)
+			if tr.Code == "" {
+				return resp(http.StatusBadRequest, `{"error":"invalid_request"}`)
+			}
+			return resp(http.StatusOK, `{"access_token":"tok-`+tr.Code+`","expires_in":31536000}`)
+		}
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if strings.Contains(string(body), "SaveMediaListEntry") {
+			*writes = append(*writes, token+" "+string(body))
+			return resp(http.StatusOK, `{"data":{"SaveMediaListEntry":{"id":1}}}`)
+		}
+		name, ok := viewers[token]
+		if !ok {
+			return resp(http.StatusUnauthorized, `{"errors":[{"message":"Invalid token"}]}`)
+		}
+		return resp(http.StatusOK, `{"data":{"Viewer":{"id":5,"name":"`+name+`"}}}`)
+	})
+}
+
+func anilistAuthFM() *mockFileManager {
+	return &mockFileManager{configs: &files.Config{
+		AnilistUsernames: []string{"someone", "other"},
+		AnilistClientID:  "7",
+	}}
+}
+
+func TestHandleAnilistAccounts(t *testing.T) {
+	fm := anilistAuthFM()
+	fm.anilistTokens = map[string]files.AnilistToken{
+		"someone": {AccessToken: "a", ExpiresAt: time.Now().Add(time.Hour)},
+		"other":   {AccessToken: "b", ExpiresAt: time.Now().Add(-time.Hour)},
+	}
+	req := httptest.NewRequest(http.MethodGet, "http://aad.local:8091/api/v1/anilist/accounts", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec := httptest.NewRecorder()
+	handleAnilistAccounts(&Server{FileManager: fm})(rec, req)
+
+	var resp struct {
+		Data AnilistAccountsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+	d := resp.Data
+	if !d.ClientConfigured || !d.Implicit || d.RedirectURI != "https://aad.local:8091/api/v1/anilist/oauth/callback" {
+		t.Errorf("response = %+v", d)
+	}
+	if len(d.Accounts) != 2 || !d.Accounts[0].LoggedIn || d.Accounts[1].LoggedIn || !d.Accounts[1].Expired {
+		t.Errorf("accounts = %+v", d.Accounts)
+	}
+	// O token nunca sai pela API.
+	if strings.Contains(rec.Body.String(), `"a"`) {
+		t.Errorf("the token leaked: %s", rec.Body.String())
+	}
+}
+
+func TestHandleAnilistLogin(t *testing.T) {
+	login := func(s *Server, username string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/anilist/accounts/"+username+"/login", nil)
+		req.SetPathValue("username", username)
+		rec := httptest.NewRecorder()
+		handleAnilistLogin(s)(rec, req)
+		return rec
+	}
+
+	fm := anilistAuthFM()
+	fm.configs.AnilistClientID = ""
+	if rec := login(&Server{FileManager: fm}, "someone"); rec.Code != http.StatusConflict {
+		t.Errorf("without a client id: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := login(&Server{FileManager: anilistAuthFM()}, "stranger"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown account: %d %s", rec.Code, rec.Body.String())
+	}
+
+	fm = anilistAuthFM()
+	fm.configs.AnilistClientSecret = "secret"
+	s := &Server{FileManager: fm}
+	rec := login(s, "someone")
+	var resp struct {
+		Data AnilistLoginResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK || resp.Data.Implicit {
+		t.Fatalf("code login: %d %s", rec.Code, rec.Body.String())
+	}
+	u, _ := url.Parse(resp.Data.URL)
+	state := u.Query().Get("state")
+	if _, ok := s.anilistLogins.take(state); !ok {
+		t.Errorf("the login state %q was not kept for the callback", state)
+	}
+}
+
+func TestHandleAnilistCallback(t *testing.T) {
+	var writes []string
+	defer mockAniListViewer(map[string]string{"tok-good": "Someone", "tok-wrong": "SomeoneElse"}, &writes)()
+
+	fm := anilistAuthFM()
+	fm.configs.AnilistClientSecret = "secret"
+	s := &Server{FileManager: fm}
+	callback := func(query string) *url.URL {
+		req := httptest.NewRequest(http.MethodGet, anilistCallbackPath+"?"+query, nil)
+		rec := httptest.NewRecorder()
+		handleAnilistCallback(s)(rec, req)
+		if rec.Code != http.StatusFound {
+			t.Fatalf("callback: %d %s", rec.Code, rec.Body.String())
+		}
+		loc, _ := url.Parse(rec.Header().Get("Location"))
+		// O resultado vem depois do #, na rota do frontend.
+		back, _ := url.Parse(loc.Fragment)
+		return back
+	}
+
+	if back := callback("code=good&state=unknown"); back.Query().Get("anilist_login_error") == "" {
+		t.Errorf("unknown state: redirected to %s", back)
+	}
+
+	state, _ := s.anilistLogins.start("someone", "http://x"+anilistCallbackPath)
+	back := callback("code=good&state=" + state)
+	if back.Path != "/config" || back.Query().Get("anilist_login") != "someone" {
+		t.Fatalf("login: redirected to %s", back)
+	}
+	if tok := fm.anilistTokens["someone"]; tok.AccessToken != "tok-good" || tok.ViewerName != "Someone" {
+		t.Errorf("saved token = %+v", tok)
+	}
+	// O state e de uso unico.
+	if back := callback("code=good&state=" + state); back.Query().Get("anilist_login_error") == "" {
+		t.Errorf("reused state: redirected to %s", back)
+	}
+
+	state, _ = s.anilistLogins.start("other", "http://x"+anilistCallbackPath)
+	if back := callback("code=wrong&state=" + state); !strings.Contains(back.Query().Get("anilist_login_error"), "another account") {
+		t.Errorf("wrong account: redirected to %s", back)
+	}
+	if _, ok := fm.anilistTokens["other"]; ok {
+		t.Error("a login with another account must not be saved")
+	}
+}
+
+func TestHandleAnilistToken(t *testing.T) {
+	var writes []string
+	defer mockAniListViewer(map[string]string{"pasted": "someone"}, &writes)()
+
+	fm := anilistAuthFM()
+	s := &Server{FileManager: fm}
+	call := func(method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/anilist/accounts/someone/token", strings.NewReader(body))
+		req.SetPathValue("username", "someone")
+		rec := httptest.NewRecorder()
+		handleAnilistToken(s)(rec, req)
+		return rec
+	}
+
+	if rec := call(http.MethodPut, `{"access_token":"bogus"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("rejected token: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := call(http.MethodPut, `{"access_token":" pasted "}`); rec.Code != http.StatusOK {
+		t.Fatalf("pasted token: %d %s", rec.Code, rec.Body.String())
+	}
+	if fm.anilistTokens["someone"].AccessToken != "pasted" {
+		t.Errorf("tokens = %+v", fm.anilistTokens)
+	}
+	if rec := call(http.MethodDelete, ""); rec.Code != http.StatusOK {
+		t.Fatalf("logout: %d %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := fm.anilistTokens["someone"]; ok {
+		t.Error("logout kept the token")
+	}
+}
+
+func TestHandleStandaloneAnimeAddToList(t *testing.T) {
+	var writes []string
+	defer mockAniListViewer(nil, &writes)()
+
+	fm := anilistAuthFM()
+	fm.standaloneAnimes = []int{21}
+	fm.animeSettings = map[int]files.AnimeSettings{21: {Progress: 4}}
+	s := standaloneServer(fm)
+	call := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/standalone-animes/"+id+"/list", bytes.NewReader([]byte(body)))
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		handleStandaloneAnimeAddToList(s)(rec, req)
+		return rec
+	}
+
+	if rec := call("21", `{"username":"someone"}`); rec.Code != http.StatusConflict {
+		t.Errorf("not logged in: %d %s", rec.Code, rec.Body.String())
+	}
+	fm.anilistTokens = map[string]files.AnilistToken{"someone": {AccessToken: "tok"}}
+	if rec := call("22", `{"username":"someone"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("not standalone: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := call("21", `{"username":"someone","status":"WATCHING"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad status: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := call("21", `{"username":"someone"}`); rec.Code != http.StatusOK {
+		t.Fatalf("add: %d %s", rec.Code, rec.Body.String())
+	}
+	if len(writes) != 1 || !strings.Contains(writes[0], `"progress":4`) || !strings.Contains(writes[0], `"status":"CURRENT"`) {
+		t.Errorf("writes = %v, want CURRENT with the saved progress", writes)
+	}
+}
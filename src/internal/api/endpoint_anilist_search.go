@@ -22,8 +22,8 @@ type AniListSearchResult struct {
 	Episodes int    `json:"episodes" example:"24"`
 	Cover    string `json:"cover,omitempty"`
 	// BlockReason e "" quando o anime pode ser adicionado como avulso, senao um dos motivos de
-	// standalone_guard.go. E UM CAMPO, nao quatro booleanos: os motivos sao mutuamente
-	// exclusivos por precedencia e o card precisa de um rotulo so. Vem do mesmo blockReason que
+	// daemon/standalone_guard.go. E UM CAMPO, nao quatro booleanos: os motivos sao mutuamente
+	// exclusivos por precedencia e o card precisa de um rotulo so. Vem do mesmo BlockReason que
 	// o POST usa para decidir o 409 — e o que faz front e back concordarem por construcao.
 	BlockReason string `json:"block_reason"`
 }
@@ -98,7 +98,7 @@ func handleAniListSearch(server *Server) http.HandlerFunc {
 				Year:        r.SeasonYear,
 				Episodes:    episodes,
 				Cover:       cover,
-				BlockReason: guard.blockReason(r.Id, episodes),
+				BlockReason: guard.BlockReason(r.Id, episodes),
 			})
 		}
 
@@ -42,6 +42,11 @@ type AnimeDetailResponse struct {
 	CoverImage        string             `json:"cover_image,omitempty"`
 	Episodes          []AnimeEpisodeInfo `json:"episodes"`
 	CustomSearchQuery string             `json:"custom_search_query,omitempty"`
+	QueueWeight       int                `json:"queue_weight,omitempty"`
+	// FollowSequels e o override do anime ("always"/"never"); vazio segue o global.
+	FollowSequels string `json:"follow_sequels,omitempty"`
+	// Trial so vem enquanto o anime esta em teste de uma regra de auto-subscribe.
+	Trial *TrialResponse `json:"trial,omitempty"`
 }
 
 // @Summary      Get detail and episodes for a specific anime
@@ -76,7 +81,7 @@ func handleAnimeEpisodes(server *Server) http.HandlerFunc {
 			return
 		}
 
-		mediaList, err := resolveMediaList(server.FileManager, id, config.AnilistUsernames, loadStandaloneSet(server.FileManager))
+		mediaList, err := resolveMediaList(server.FileManager, config, id, loadStandaloneSet(server.FileManager))
 		if err != nil {
 			logger.Logger.Error().Err(err).Int("anime_id", id).Msg("Failed to fetch anime detail from AniList")
 			JSONInternalError(w, err)
@@ -173,6 +178,16 @@ func handleAnimeEpisodes(server *Server) http.HandlerFunc {
 			CoverImage:        coverImage,
 			Episodes:          episodes,
 			CustomSearchQuery: animeSettings.CustomSearchQuery,
+			QueueWeight:       animeSettings.QueueWeight,
+			FollowSequels:     animeSettings.FollowSequels,
+		}
+
+		trials, err := server.FileManager.LoadTrials()
+		if err != nil {
+			logger.Logger.Warn().Err(err).Int("anime_id", id).Msg("Failed to load trials")
+		} else if t, ok := trials[id]; ok && t.State == files.TrialActive {
+			trial := newTrialResponse(id, t, config.TrialKeepDays)
+			response.Trial = &trial
 		}
 
 		JSONSuccess(w, http.StatusOK, response)
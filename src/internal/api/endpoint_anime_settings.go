@@ -8,12 +8,16 @@ import (
 	"strconv"
 )
 
-// Os dois campos sao PONTEIROS porque o PUT e parcial: a tela dispara
+// Os campos sao PONTEIROS porque o PUT e parcial: a tela dispara
 // updateAnimeSettings(id, { custom_search_query }) e, com um segundo campo no struct, montar um
 // AnimeSettings do zero zeraria o progresso salvo (e vice-versa).
 type animeSettingsRequest struct {
 	CustomSearchQuery *string `json:"custom_search_query"`
 	Progress          *int    `json:"progress"`
+	// QueueWeight e o peso do anime na politica de fila fair_share. 0 volta ao padrao (1).
+	QueueWeight *int `json:"queue_weight"`
+	// FollowSequels e "always", "never" ou "" (segue o follow_sequels global).
+	FollowSequels *string `json:"follow_sequels" enums:"always,never,"`
 }
 
 // @Summary      Get or update anime-specific settings
@@ -58,6 +62,19 @@ func handleAnimeSettings(server *Server) http.HandlerFunc {
 				JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Progress must be non-negative")
 				return
 			}
+			if req.QueueWeight != nil && *req.QueueWeight < 0 {
+				JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Queue weight must be non-negative")
+				return
+			}
+
+			if req.FollowSequels != nil {
+				switch *req.FollowSequels {
+				case "", files.FollowSequelsAlways, files.FollowSequelsNever:
+				default:
+					JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "follow_sequels must be \"always\", \"never\" or empty")
+					return
+				}
+			}
 
 			existing, err := server.FileManager.LoadAnimeSettings(id)
 			if err != nil {
@@ -76,6 +93,12 @@ func handleAnimeSettings(server *Server) http.HandlerFunc {
 			if req.Progress != nil {
 				settings.Progress = *req.Progress
 			}
+			if req.QueueWeight != nil {
+				settings.QueueWeight = *req.QueueWeight
+			}
+			if req.FollowSequels != nil {
+				settings.FollowSequels = *req.FollowSequels
+			}
 
 			if err := server.FileManager.SaveAnimeSettings(id, settings); err != nil {
 				logger.Logger.Error().Err(err).Int("anime_id", id).Msg("Failed to save anime settings")
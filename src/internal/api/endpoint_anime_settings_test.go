@@ -56,3 +56,43 @@ func TestPutAnimeSettings_RejectsNegativeProgress(t *testing.T) {
 		t.Errorf("esperava 400 para progresso negativo, obteve %d", rec.Code)
 	}
 }
+
+func TestPutAnimeSettings_QueueWeight(t *testing.T) {
+	server, fm := newSettingsTestServer(t)
+	fm.animeSettings = map[int]files.AnimeSettings{7: {Progress: 48}}
+
+	if rec := putSettings(t, server, 7, `{"queue_weight":3}`); rec.Code != http.StatusOK {
+		t.Fatalf("esperava 200, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := fm.animeSettings[7]; got.QueueWeight != 3 || got.Progress != 48 {
+		t.Errorf("esperava peso 3 e progresso preservado, obteve %+v", got)
+	}
+
+	if rec := putSettings(t, server, 7, `{"queue_weight":-1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("esperava 400 para peso negativo, obteve %d", rec.Code)
+	}
+}
+
+// "" e um valor valido: e como a tela volta o anime para o global. FollowedSequels e do daemon
+// e nao pode sumir num PUT da tela.
+func TestPutAnimeSettings_FollowSequels(t *testing.T) {
+	server, fm := newSettingsTestServer(t)
+	fm.animeSettings = map[int]files.AnimeSettings{7: {FollowedSequels: []int{8}}}
+
+	if rec := putSettings(t, server, 7, `{"follow_sequels":"never"}`); rec.Code != http.StatusOK {
+		t.Fatalf("esperava 200, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := fm.animeSettings[7]; got.FollowSequels != files.FollowSequelsNever || len(got.FollowedSequels) != 1 {
+		t.Errorf("esperava never e a sequencia seguida preservada, obteve %+v", got)
+	}
+	if rec := putSettings(t, server, 7, `{"follow_sequels":""}`); rec.Code != http.StatusOK {
+		t.Fatalf("esperava 200, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := fm.animeSettings[7]; got.FollowSequels != "" {
+		t.Errorf("esperava volta ao global, obteve %+v", got)
+	}
+
+	if rec := putSettings(t, server, 7, `{"follow_sequels":"sometimes"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("esperava 400 para valor desconhecido, obteve %d", rec.Code)
+	}
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/lists"
 	"AutoAnimeDownloader/src/internal/logger"
 	"fmt"
 	"net/http"
@@ -156,8 +158,13 @@ func handleAnimes(server *Server) http.HandlerFunc {
 		covered := make(map[int]bool)
 		mergeFailed := false
 		for _, username := range config.AnilistUsernames {
+			// Cada conta com os status do seu perfil, como no passe (searchAnilist).
+			statuses := config.ProfileFor(username).DownloadStatuses
+			if config.HasProfile(username) && len(statuses) == 0 {
+				continue
+			}
 			// nil (e nao uma lista vazia) significa que a busca falhou — ver fetchAniListEntries.
-			list := fetchAniListEntries(username, config.DownloadStatuses, config.DownloadMediaStatuses)
+			list := fetchAniListEntries(username, statuses, config.DownloadMediaStatuses)
 			if list == nil {
 				mergeFailed = true
 				continue
@@ -167,6 +174,21 @@ func handleAnimes(server *Server) http.HandlerFunc {
 			}
 			entries = append(entries, list...)
 		}
+		for _, acc := range lists.Accounts(config) {
+			list, err := lists.MediaLists(acc, config, config.ProfileFor(acc.Key()).DownloadStatuses)
+			if err != nil {
+				logger.Logger.Warn().Err(err).Str("account", acc.Key()).Msg("Failed to fetch list animes, skipping merge")
+				mergeFailed = true
+				continue
+			}
+			for _, ml := range list {
+				if !anilist.MediaStatusAllowed(config.DownloadMediaStatuses, ml.Media.Status) {
+					continue
+				}
+				covered[ml.Media.Id] = true
+				entries = append(entries, ml)
+			}
+		}
 
 		// Os avulsos entram DEPOIS do dedupe pelo mesmo motivo do daemon: quando o anime tambem
 		// esta numa lista, a entrada real (com progresso) tem de vencer a sintetica.
@@ -182,7 +204,7 @@ func handleAnimes(server *Server) http.HandlerFunc {
 		if mergeFailed {
 			logger.Logger.Warn().Msg("Skipping orphan refresh: AniList list fetch failed, coverage unknown")
 		} else {
-			refreshOrphanAnimes(server.FileManager, animeMap, covered, config.ExcludedLists, config.AnilistUsernames, standaloneSet)
+			refreshOrphanAnimes(server.FileManager, config, animeMap, covered, standaloneSet)
 		}
 
 		animes := make([]AnimeInfo, 0, len(animeMap))
@@ -218,7 +240,7 @@ const maxConcurrentOrphanRefresh = 5
 // status fell outside the configured allowed sets). These animes stay visible regardless —
 // this only tries to keep their cover/progress/blacklist fields fresh instead of stale/blank.
 // A failed refresh is logged and left as-is; it never fails the overall request.
-func refreshOrphanAnimes(fm FileManagerInterface, animeMap map[string]*AnimeInfo, covered map[int]bool, excludedLists []string, usernames []string, standalone map[int]bool) {
+func refreshOrphanAnimes(fm FileManagerInterface, cfg *files.Config, animeMap map[string]*AnimeInfo, covered map[int]bool, standalone map[int]bool) {
 	var orphans []*AnimeInfo
 	for _, info := range animeMap {
 		if info.AnimeID != 0 && !covered[info.AnimeID] {
@@ -238,7 +260,7 @@ func refreshOrphanAnimes(fm FileManagerInterface, animeMap map[string]*AnimeInfo
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			ml, err := resolveMediaList(fm, info.AnimeID, usernames, standalone)
+			ml, err := resolveMediaList(fm, cfg, info.AnimeID, standalone)
 			if err != nil {
 				logger.Logger.Warn().Err(err).Int("anime_id", info.AnimeID).Msg("Failed to refresh orphaned anime, keeping existing data")
 				return
@@ -250,7 +272,7 @@ func refreshOrphanAnimes(fm FileManagerInterface, animeMap map[string]*AnimeInfo
 			}
 
 			name, totalEpisodes, episodesReleased, coverImage, isBlacklisted := computeAnimeFields(
-				ml.Media.Title, ml.Media.Status, ml.Media.Episodes, ml.Media.CoverImage, ml.Media.AiringSchedule, ml.CustomLists, excludedLists,
+				ml.Media.Title, ml.Media.Status, ml.Media.Episodes, ml.Media.CoverImage, ml.Media.AiringSchedule, ml.CustomLists, cfg.ExcludedLists,
 			)
 
 			if name != "" {
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/daemon"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+type AutoSubscribeMatchResponse struct {
+	MediaID      int    `json:"media_id" example:"21"`
+	Title        string `json:"title" example:"One Piece"`
+	Format       string `json:"format" example:"TV"`
+	Popularity   int    `json:"popularity" example:"52000"`
+	AverageScore *int   `json:"average_score" example:"78"`
+	Cover        string `json:"cover,omitempty"`
+	// Reasons tem um motivo por criterio configurado na regra ("format TV", "score 78 >= 70").
+	Reasons []string `json:"reasons"`
+	// TrialState e o estado do trial deste anime, se ja houve um: trial, kept, expired ou left.
+	TrialState string `json:"trial_state,omitempty" enums:"trial,kept,expired,left"`
+	// BlockReason e por que o passe nao o adiciona, com os mesmos valores da busca. Vazio e sem
+	// TrialState: entra no proximo passe, se a regra estiver ligada.
+	BlockReason string `json:"block_reason,omitempty" enums:"blacklist,standalone,tracked,downloaded"`
+}
+
+type AutoSubscribeRuleResponse struct {
+	Rule       string `json:"rule" example:"Action TV"`
+	Enabled    bool   `json:"enabled"`
+	Season     string `json:"season" example:"SPRING"`
+	SeasonYear int    `json:"season_year" example:"2026"`
+	// Error e a falha ao ler a temporada da AniList; Matches vem vazio.
+	Error   string                       `json:"error,omitempty"`
+	Matches []AutoSubscribeMatchResponse `json:"matches"`
+}
+
+type TrialResponse struct {
+	MediaID       int        `json:"media_id" example:"21"`
+	Rule          string     `json:"rule" example:"Action TV"`
+	Title         string     `json:"title"`
+	TrialEpisodes int        `json:"trial_episodes" example:"3"`
+	State         string     `json:"state" enums:"trial,kept,expired,left"`
+	AddedAt       time.Time  `json:"added_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+	// ExpiresAt e quando o passe remove o trial; ausente quando ele nao expira.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func newTrialResponse(mediaID int, t files.Trial, keepDays int) TrialResponse {
+	return TrialResponse{
+		MediaID:       mediaID,
+		Rule:          t.Rule,
+		Title:         t.Title,
+		TrialEpisodes: t.TrialEpisodes,
+		State:         t.State,
+		AddedAt:       t.AddedAt,
+		EndedAt:       t.EndedAt,
+		ExpiresAt:     daemon.TrialExpiresAt(t, keepDays),
+	}
+}
+
+// @Summary      What each auto-subscribe rule matches
+// @Description  Evaluates every rule (enabled or not) against its AniList season and lists the matches, why each one matched, and whether the pass would add it
+// @Tags         auto-subscribe
+// @Produce      json
+// @Success      200  {object}  SuccessResponse{data=[]AutoSubscribeRuleResponse}
+// @Failure      405  {object}  SuccessResponse
+// @Failure      500  {object}  SuccessResponse
+// @Router       /auto-subscribe/matches [get]
+func handleAutoSubscribeMatches(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET method is allowed")
+			return
+		}
+
+		config, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load config")
+			JSONInternalError(w, err)
+			return
+		}
+		trials, err := server.FileManager.LoadTrials()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load trials")
+			JSONInternalError(w, err)
+			return
+		}
+
+		// O guard so e montado se alguma regra casou com algo: ele le a lista de cada conta.
+		// Falha aqui so deixa o block_reason vazio — a previa continua util sem ele.
+		var guard *daemon.StandaloneGuard
+		guardFor := func() *daemon.StandaloneGuard {
+			if guard == nil {
+				g, err := newStandaloneGuard(server.FileManager, config)
+				if err != nil {
+					logger.Logger.Warn().Err(err).Msg("Failed to build the standalone guard for the rule preview")
+					g = daemon.StandaloneGuard{}
+				}
+				guard = &g
+			}
+			return guard
+		}
+
+		evals := daemon.EvaluateRules(config.AutoSubscribeRules, time.Now())
+		resp := make([]AutoSubscribeRuleResponse, 0, len(evals))
+		for _, eval := range evals {
+			rule := AutoSubscribeRuleResponse{
+				Rule:       eval.Rule.Name,
+				Enabled:    eval.Rule.Enabled,
+				Season:     string(eval.Season),
+				SeasonYear: eval.Year,
+				Matches:    []AutoSubscribeMatchResponse{},
+			}
+			if eval.Err != nil {
+				rule.Error = eval.Err.Error()
+			}
+			for _, match := range eval.Matches {
+				cover := match.Media.CoverImage.Large
+				if cover == "" {
+					cover = match.Media.CoverImage.Medium
+				}
+				m := AutoSubscribeMatchResponse{
+					MediaID:      match.Media.Id,
+					Title:        resolveTitle(match.Media.Title),
+					Format:       string(match.Media.Format),
+					Popularity:   match.Media.Popularity,
+					AverageScore: match.Media.AverageScore,
+					Cover:        cover,
+					Reasons:      match.Reasons,
+				}
+				if t, ok := trials[match.Media.Id]; ok {
+					m.TrialState = t.State
+				} else {
+					total := 0
+					if match.Media.Episodes != nil {
+						total = *match.Media.Episodes
+					}
+					m.BlockReason = guardFor().BlockReason(match.Media.Id, total)
+				}
+				rule.Matches = append(rule.Matches, m)
+			}
+			resp = append(resp, rule)
+		}
+
+		JSONSuccess(w, http.StatusOK, resp)
+	}
+}
+
+// @Summary      List auto-subscribe trials
+// @Description  Every anime an auto-subscribe rule added, running trials first, then newest first
+// @Tags         auto-subscribe
+// @Produce      json
+// @Success      200  {object}  SuccessResponse{data=[]TrialResponse}
+// @Failure      405  {object}  SuccessResponse
+// @Failure      500  {object}  SuccessResponse
+// @Router       /trials [get]
+func handleTrials(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET method is allowed")
+			return
+		}
+
+		config, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load config")
+			JSONInternalError(w, err)
+			return
+		}
+		trials, err := server.FileManager.LoadTrials()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load trials")
+			JSONInternalError(w, err)
+			return
+		}
+
+		resp := make([]TrialResponse, 0, len(trials))
+		for id, t := range trials {
+			resp = append(resp, newTrialResponse(id, t, config.TrialKeepDays))
+		}
+		sort.Slice(resp, func(i, j int) bool {
+			ai, aj := resp[i].State == files.TrialActive, resp[j].State == files.TrialActive
+			if ai != aj {
+				return ai
+			}
+			if !resp[i].AddedAt.Equal(resp[j].AddedAt) {
+				return resp[i].AddedAt.After(resp[j].AddedAt)
+			}
+			return resp[i].MediaID < resp[j].MediaID
+		})
+
+		JSONSuccess(w, http.StatusOK, resp)
+	}
+}
+
+// @Summary      Keep a trial anime
+// @Description  Ends the trial: the episode limit is lifted and the anime never expires. It stays a standalone anime
+// @Tags         auto-subscribe
+// @Produce      json
+// @Param        id   path int true "AniList media ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  SuccessResponse
+// @Failure      404  {object}  SuccessResponse
+// @Failure      405  {object}  SuccessResponse
+// @Failure      500  {object}  SuccessResponse
+// @Router       /trials/{id}/keep [post]
+func handleTrialKeep(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST method is allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id <= 0 {
+			JSONError(w, http.StatusBadRequest, "INVALID_ID", "Invalid anime ID")
+			return
+		}
+
+		found, err := daemon.KeepTrial(server.FileManager, id, time.Now())
+		if err != nil {
+			logger.Logger.Error().Err(err).Int("media_id", id).Msg("Failed to keep the trial")
+			JSONInternalError(w, err)
+			return
+		}
+		if !found {
+			JSONError(w, http.StatusNotFound, "TRIAL_NOT_FOUND", "This anime is not in a running trial")
+			return
+		}
+
+		logger.Logger.Info().Int("media_id", id).Msg("Trial kept")
+		JSONSuccess(w, http.StatusOK, nil)
+	}
+}
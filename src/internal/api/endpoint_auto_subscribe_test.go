@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/files"
+)
+
+// TestTrials_ActiveFirstWithExpiry: trial em curso vem antes e com o prazo; o que acabou nao expira.
+func TestTrials_ActiveFirstWithExpiry(t *testing.T) {
+	added := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	cfg := standaloneConfig()
+	cfg.TrialKeepDays = 14
+	fm := &mockFileManager{
+		configs: cfg,
+		trials: map[int]files.Trial{
+			21: {Rule: "r", AddedAt: added.AddDate(0, 0, 1), State: files.TrialExpired},
+			22: {Rule: "r", AddedAt: added, State: files.TrialActive, TrialEpisodes: 3},
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	handleTrials(&Server{FileManager: fm})(rec, httptest.NewRequest(http.MethodGet, "/api/v1/trials", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("quero 200, veio %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		Data []TrialResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("resposta invalida: %v", err)
+	}
+	if len(resp.Data) != 2 || resp.Data[0].MediaID != 22 {
+		t.Fatalf("quero o trial em curso primeiro, veio %+v", resp.Data)
+	}
+	if resp.Data[0].ExpiresAt == nil || !resp.Data[0].ExpiresAt.Equal(added.AddDate(0, 0, 14)) {
+		t.Errorf("prazo errado: %v", resp.Data[0].ExpiresAt)
+	}
+	if resp.Data[1].ExpiresAt != nil {
+		t.Errorf("trial expirado nao tem prazo, veio %v", resp.Data[1].ExpiresAt)
+	}
+}
+
+func TestTrialKeep(t *testing.T) {
+	fm := &mockFileManager{
+		configs: standaloneConfig(),
+		trials:  map[int]files.Trial{21: {State: files.TrialActive}, 22: {State: files.TrialExpired}},
+	}
+	keep := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/trials/"+id+"/keep", nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		handleTrialKeep(&Server{FileManager: fm})(rec, req)
+		return rec
+	}
+
+	if rec := keep("21"); rec.Code != http.StatusOK {
+		t.Fatalf("quero 200, veio %d: %s", rec.Code, rec.Body.String())
+	}
+	if fm.trials[21].State != files.TrialKept {
+		t.Fatalf("quero kept, veio %+v", fm.trials[21])
+	}
+	if rec := keep("22"); rec.Code != http.StatusNotFound || errorCode(t, rec) != "TRIAL_NOT_FOUND" {
+		t.Fatalf("trial expirado: quero 404 TRIAL_NOT_FOUND, veio %d", rec.Code)
+	}
+	if rec := keep("abc"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("id invalido: quero 400, veio %d", rec.Code)
+	}
+}
@@ -1,10 +1,15 @@
 package api
 
 import (
+	"AutoAnimeDownloader/src/internal/daemon"
 	"AutoAnimeDownloader/src/internal/files"
 	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/mediaserver"
+	"AutoAnimeDownloader/src/internal/torrents"
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"path/filepath"
 )
 
 // @Summary      Get and update configuration
@@ -31,7 +36,7 @@ func handleConfig(server *Server) http.HandlerFunc {
 }
 
 // @Summary      Get configuration
-// @Description  Returns the current daemon configuration
+// @Description  Returns the current daemon configuration. A saved anilist_client_secret comes back as "********"
 // @Tags         config
 // @Accept       json
 // @Produce      json
@@ -48,12 +53,27 @@ func handleGetConfig(server *Server) http.HandlerFunc {
 			return
 		}
 
-		JSONSuccess(w, http.StatusOK, configs)
+		JSONSuccess(w, http.StatusOK, redactConfig(configs))
 	}
 }
 
+// redactedSecret e o que o GET devolve no lugar de um secret gravado. O PUT que o devolve
+// igual mantem o valor salvo; vazio apaga.
+const redactedSecret = "********"
+
+// redactConfig tira o secret do cliente da AniList da resposta: com ele, qualquer um que le a
+// config troca codigo por token em nome do usuario. Copia, porque a config pode vir de cache.
+func redactConfig(configs *files.Config) *files.Config {
+	if configs.AnilistClientSecret == "" {
+		return configs
+	}
+	c := *configs
+	c.AnilistClientSecret = redactedSecret
+	return &c
+}
+
 // @Summary      Update configuration
-// @Description  Updates the daemon configuration with the provided values
+// @Description  Updates the daemon configuration with the provided values. anilist_client_secret "********" keeps the saved secret; "" clears it
 // @Tags         config
 // @Accept       json
 // @Produce      json
@@ -92,15 +112,40 @@ func handleUpdateConfig(server *Server) http.HandlerFunc {
 			return
 		}
 
-		// A biblioteca e montada com hardlinks; nem todo filesystem suporta. Verifica no
-		// momento do save, com a mesma funcao que o runtime usa.
+		// "" vem de cliente anterior ao campo, como queue_policy "".
+		if config.LibraryLinkMode == "" {
+			config.LibraryLinkMode = string(files.LinkHardlink)
+		}
+		if !files.IsLinkMode(config.LibraryLinkMode) {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Library link mode must be one of hardlink, reflink, symlink, copy")
+			return
+		}
+
+		// A biblioteca e montada com hardlinks (ou o modo escolhido); nem todo filesystem
+		// suporta. Verifica no momento do save, com a mesma funcao que o runtime usa. O erro
+		// lista os modos que funcionam ali.
 		if server.Librarian != nil {
-			if err := server.Librarian.ProbePath(config.CompletedAnimePath); err != nil {
+			if err := server.Librarian.ProbePath(config.CompletedAnimePath, config.LinkMode()); err != nil {
 				JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
 				return
 			}
 		}
 
+		// A pasta de filmes pode ser outro volume: o mesmo cheque, do diretorio de download ate
+		// ela. Desligado o layout, o caminho fica guardado sem uso e nao e sondado.
+		if config.LibraryMovieLayout && config.LibraryMoviesPath != "" {
+			if !filepath.IsAbs(config.LibraryMoviesPath) {
+				JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Library movies path must be an absolute path")
+				return
+			}
+			if server.Librarian != nil {
+				if err := server.Librarian.ProbeMoviesPath(config.CompletedAnimePath, config.LibraryMoviesPath, config.LinkMode()); err != nil {
+					JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+					return
+				}
+			}
+		}
+
 		if config.CheckInterval <= 0 {
 			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Check interval must be greater than 0")
 			return
@@ -128,6 +173,15 @@ func handleUpdateConfig(server *Server) http.HandlerFunc {
 			return
 		}
 
+		// "" vem de cliente anterior ao campo; grava o default em vez de recusar.
+		if config.QueuePolicy == "" {
+			config.QueuePolicy = string(torrents.QueueFIFO)
+		}
+		if !torrents.IsQueuePolicy(config.QueuePolicy) {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Queue policy must be one of fifo, smallest_first, airing_first, fair_share")
+			return
+		}
+
 		if config.MaxBatchTorrentSizeGB < 0 || config.MaxEpisodeTorrentSizeGB < 0 {
 			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Torrent size limits must be non-negative")
 			return
@@ -149,11 +203,139 @@ func handleUpdateConfig(server *Server) http.HandlerFunc {
 			return
 		}
 
+		for _, tr := range config.ExtraTrackers {
+			if !torrents.IsTrackerURL(tr) {
+				JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid tracker URL: "+tr)
+				return
+			}
+		}
+
+		if config.TrackersListURL != "" {
+			if u, err := url.Parse(config.TrackersListURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Trackers list URL must be an http(s) URL")
+				return
+			}
+		}
+
+		if config.IDMappingURL != "" {
+			if u, err := url.Parse(config.IDMappingURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Id mapping URL must be an http(s) URL")
+				return
+			}
+		}
+
+		if config.SequelLeadDays < 0 {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Sequel lead time must be non-negative")
+			return
+		}
+		if config.TrialKeepDays < 0 {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Trial keep days must be non-negative")
+			return
+		}
+		if config.AutoSubscribeRules == nil {
+			config.AutoSubscribeRules = []files.AutoSubscribeRule{}
+		}
+		if err := files.ValidateAutoSubscribeRules(config.AutoSubscribeRules); err != nil {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid auto-subscribe rule: "+err.Error())
+			return
+		}
+
+		if config.AccountProfiles == nil {
+			config.AccountProfiles = []files.AccountProfile{}
+		}
+		if err := files.ValidateAccountProfiles(&config); err != nil {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid account profile: "+err.Error())
+			return
+		}
+
+		if config.IntegrityCheckDays < 0 {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Integrity check interval must be non-negative")
+			return
+		}
+
+		if config.DataCapGB < 0 {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Data cap must be non-negative")
+			return
+		}
+		// 0 vem de cliente anterior ao campo, como queue_policy "".
+		if config.DataCapBillingDay == 0 {
+			config.DataCapBillingDay = 1
+		}
+		if config.DataCapBillingDay < 1 || config.DataCapBillingDay > 28 {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Data cap billing day must be between 1 and 28")
+			return
+		}
+
+		// "" vem de cliente anterior ao campo, como queue_policy "".
+		if config.TorrentClient == "" {
+			config.TorrentClient = torrents.ClientEmbedded
+		}
+		if !torrents.IsTorrentClient(config.TorrentClient) {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Torrent client must be one of embedded, qbittorrent, transmission")
+			return
+		}
+		if torrents.IsRemoteClient(config.TorrentClient) {
+			if u, err := url.Parse(config.TorrentClientURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Torrent client URL must be an http(s) URL")
+				return
+			}
+		}
+		// Categoria vazia no qBittorrent e "sem categoria": a daemon passaria a gerenciar todo
+		// torrent solto do cliente.
+		if config.TorrentClientCategory == "" {
+			config.TorrentClientCategory = torrents.DefaultCategory
+		}
+
 		if config.Notifications.BatchWindowSeconds < 0 {
 			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Notification batch window must be non-negative")
 			return
 		}
 
+		// null vem de cliente anterior ao campo.
+		if config.MediaServers == nil {
+			config.MediaServers = []files.MediaServer{}
+		}
+		if err := mediaserver.Validate(config.MediaServers); err != nil {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid media server: "+err.Error())
+			return
+		}
+
+		// "" vem de cliente anterior aos templates: grava o default, que reproduz os nomes de
+		// antes, para a tela de config mostrar o template em vigor.
+		if config.LibraryFolderTemplate == "" {
+			config.LibraryFolderTemplate = files.DefaultFolderTemplate
+		}
+		if config.LibraryFileTemplate == "" {
+			config.LibraryFileTemplate = files.DefaultFileTemplate
+		}
+		if err := files.ValidateFolderTemplate(config.LibraryFolderTemplate); err != nil {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid library folder template: "+err.Error())
+			return
+		}
+		if err := files.ValidateFileTemplate(config.LibraryFileTemplate); err != nil {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid library file template: "+err.Error())
+			return
+		}
+
+		previous, prevErr := server.FileManager.LoadConfigs()
+
+		// O front devolve a mascara do GET quando o campo nao foi mexido.
+		if config.AnilistClientSecret == redactedSecret {
+			if prevErr != nil {
+				logger.Logger.Error().Err(prevErr).Msg("Failed to load configs to keep the AniList client secret")
+				JSONInternalError(w, prevErr)
+				return
+			}
+			config.AnilistClientSecret = previous.AnilistClientSecret
+		}
+
+		// O backend de torrent e escolhido no boot; trocar de cliente com a daemon rodando
+		// exigiria migrar a fila e os callbacks de uma implementacao para outra.
+		if prevErr == nil && torrentClientChanged(previous, &config) {
+			logger.Logger.Warn().Str("torrent_client", config.TorrentClient).
+				Msg("Torrent client settings changed; they take effect when the daemon restarts")
+		}
+
 		if err := server.FileManager.SaveConfigs(&config); err != nil {
 			logger.Logger.Error().Err(err).Msg("Failed to save configs")
 			JSONInternalError(w, err)
@@ -165,8 +347,33 @@ func handleUpdateConfig(server *Server) http.HandlerFunc {
 		// campo nao ter funcionado.
 		if server.Torrents != nil {
 			server.Torrents.SetMaxActiveDownloads(config.MaxConcurrentDownloads)
+			// Mesmo raciocinio: o proximo magnet ja sai com os trackers novos. A lista da
+			// URL so e baixada no passe de verificacao; aqui vale a que ja esta em disco.
+			daemon.ApplyExtraTrackers(server.FileManager, server.Torrents, &config)
+			daemon.ApplyQueuePolicy(server.Torrents, &config)
+			// Subir o teto (ou desliga-lo) libera os torrents agora, e nao no proximo minuto.
+			daemon.ApplyDataCap(server.FileManager, server.Torrents, &config)
+		}
+
+		// Nome novo vale tambem para o que ja esta na biblioteca: sem o relink, a pasta de um
+		// anime em andamento ficaria metade com o nome velho e metade com o novo. Compara a
+		// naming efetiva, entao gravar os defaults por cima de uma config sem os campos nao
+		// move nada.
+		if prevErr == nil && server.JobQueue != nil && !previous.LibraryNaming().Equal(config.LibraryNaming()) {
+			server.JobQueue.EnqueueRelink()
 		}
 
 		JSONSuccess(w, http.StatusOK, map[string]string{"message": "Configuration updated successfully"})
 	}
 }
+
+// torrentClientChanged reports whether a config update touches the settings the torrent backend
+// was built from.
+func torrentClientChanged(old, updated *files.Config) bool {
+	return old.TorrentClient != updated.TorrentClient ||
+		old.TorrentClientURL != updated.TorrentClientURL ||
+		old.TorrentClientUsername != updated.TorrentClientUsername ||
+		old.TorrentClientPassword != updated.TorrentClientPassword ||
+		old.TorrentClientCategory != updated.TorrentClientCategory ||
+		old.TorrentClientSavePath != updated.TorrentClientSavePath
+}
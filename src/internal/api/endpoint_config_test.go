@@ -9,17 +9,48 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // stubLibrarian is a files.Librarian for testing config validation (ProbePath behavior).
 type stubLibrarian struct {
 	probeErr error
+	// probedMode records the mode of the last ProbePath; supported is what ProbeLinkModes
+	// returns.
+	probedMode files.LinkMode
+	supported  []files.LinkMode
+	// moviesProbeErr is what ProbeMoviesPath returns; probedMovies records its folder.
+	moviesProbeErr error
+	probedMovies   string
 }
 
 func (s *stubLibrarian) Organize(files.OrganizeRequest) ([]string, error) { return nil, nil }
 func (s *stubLibrarian) RemoveFromLibrary(string) error                   { return nil }
-func (s *stubLibrarian) ProbePath(completedPath string) error             { return s.probeErr }
+func (s *stubLibrarian) ProbePath(completedPath string, mode files.LinkMode) error {
+	s.probedMode = mode
+	return s.probeErr
+}
+func (s *stubLibrarian) ProbeLinkModes(string) ([]files.LinkMode, error) {
+	return s.supported, s.probeErr
+}
+func (s *stubLibrarian) ProbeMoviesPath(completedPath, moviesPath string, mode files.LinkMode) error {
+	s.probedMovies = moviesPath
+	return s.moviesProbeErr
+}
+func (s *stubLibrarian) MoveInLibrary(string, string) error            { return nil }
+func (s *stubLibrarian) EnsureShowNFO(string, string, int)             {}
+func (s *stubLibrarian) MissingNFOs([]files.LibraryMove) bool          { return false }
+func (s *stubLibrarian) WriteNFOs([]files.LibraryMove, *files.NFOInfo) {}
+func (s *stubLibrarian) HasArtwork(string) bool                        { return false }
+func (s *stubLibrarian) SaveArtwork(string, []byte) error              { return nil }
+func (s *stubLibrarian) AuditLibrary(files.AuditRequest) ([]files.AuditFinding, error) {
+	return nil, nil
+}
+func (s *stubLibrarian) RemoveOrphan(string, string) error { return nil }
 
 type mockFileManager struct {
 	configs           *files.Config
@@ -33,6 +64,13 @@ type mockFileManager struct {
 	saveEpisodesErr   error
 	deleteEpisodesErr error
 	animeSettings     map[int]files.AnimeSettings
+	trackersList      []string
+	dataUsage         *files.DataUsageLedger
+	anilistTokens     map[string]files.AnilistToken
+	idMapping         []byte
+	idMappingSavedAt  time.Time
+	trials            map[int]files.Trial
+	listChanges       []files.ListChange
 }
 
 func (m *mockFileManager) LoadConfigs() (*files.Config, error) {
@@ -206,6 +244,105 @@ func (m *mockFileManager) RemoveStandaloneAnime(mediaID int) error {
 	return nil
 }
 
+func (m *mockFileManager) LoadTrackersList() ([]string, string, time.Time, error) {
+	return m.trackersList, "", time.Time{}, nil
+}
+
+func (m *mockFileManager) SaveTrackersList(_ string, trackers []string) error {
+	m.trackersList = trackers
+	return nil
+}
+
+func (m *mockFileManager) LoadIntegrityChecks() (map[string]time.Time, error) {
+	return map[string]time.Time{}, nil
+}
+
+func (m *mockFileManager) SaveIntegrityChecks(map[string]time.Time) error {
+	return nil
+}
+
+func (m *mockFileManager) LoadDataUsage() (*files.DataUsageLedger, error) {
+	if m.dataUsage == nil {
+		return &files.DataUsageLedger{Days: map[string]files.DayUsage{}, Counters: map[string]files.ByteCounts{}, AnimeNames: map[int]string{}}, nil
+	}
+	return m.dataUsage, nil
+}
+
+func (m *mockFileManager) SaveDataUsage(ledger *files.DataUsageLedger) error {
+	m.dataUsage = ledger
+	return nil
+}
+
+func (m *mockFileManager) LoadArtworkSources() (map[int]files.ArtworkSource, error) {
+	return map[int]files.ArtworkSource{}, nil
+}
+
+func (m *mockFileManager) SaveArtworkSources(map[int]files.ArtworkSource) error {
+	return nil
+}
+
+func (m *mockFileManager) LoadAnilistTokens() (map[string]files.AnilistToken, error) {
+	out := map[string]files.AnilistToken{}
+	for k, v := range m.anilistTokens {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (m *mockFileManager) SaveAnilistTokens(tokens map[string]files.AnilistToken) error {
+	m.anilistTokens = tokens
+	return nil
+}
+
+func (m *mockFileManager) IDMappingSavedAt() (time.Time, error) { return m.idMappingSavedAt, nil }
+
+func (m *mockFileManager) LoadIDMapping() ([]byte, time.Time, error) {
+	return m.idMapping, m.idMappingSavedAt, nil
+}
+
+func (m *mockFileManager) SaveIDMapping(data []byte) error {
+	m.idMapping = data
+	m.idMappingSavedAt = time.Now()
+	return nil
+}
+
+func (m *mockFileManager) LoadAniListCache() ([]byte, error) { return nil, nil }
+
+func (m *mockFileManager) SaveAniListCache([]byte) error { return nil }
+
+func (m *mockFileManager) LoadTrials() (map[int]files.Trial, error) {
+	if m.trials == nil {
+		return map[int]files.Trial{}, nil
+	}
+	return m.trials, nil
+}
+
+func (m *mockFileManager) UpdateTrials(fn func(map[int]files.Trial) bool) error {
+	if m.trials == nil {
+		m.trials = map[int]files.Trial{}
+	}
+	fn(m.trials)
+	return nil
+}
+
+func (m *mockFileManager) LoadListSnapshot() (files.ListSnapshot, error) {
+	return files.ListSnapshot{}, nil
+}
+
+func (m *mockFileManager) SaveListSnapshot(files.ListSnapshot) error { return nil }
+
+func (m *mockFileManager) LoadListChanges() ([]files.ListChange, error) {
+	if m.listChanges == nil {
+		return []files.ListChange{}, nil
+	}
+	return m.listChanges, nil
+}
+
+func (m *mockFileManager) AppendListChanges(changes []files.ListChange) error {
+	m.listChanges = append(m.listChanges, changes...)
+	return nil
+}
+
 func TestHandleGetConfig(t *testing.T) {
 	state := daemon.NewState()
 	mockFM := &mockFileManager{}
@@ -247,6 +384,57 @@ func TestHandleGetConfig(t *testing.T) {
 	})
 }
 
+// O secret do cliente da AniList nao sai no GET; a mascara devolvida no PUT mantem o salvo.
+func TestHandleConfig_AnilistClientSecret(t *testing.T) {
+	mockFM := &mockFileManager{configs: &files.Config{
+		CompletedAnimePath:  "/tmp/completed",
+		CheckInterval:       10,
+		AnilistClientID:     "123",
+		AnilistClientSecret: "s3cret",
+	}}
+	server := &Server{State: daemon.NewState(), FileManager: mockFM}
+
+	w := httptest.NewRecorder()
+	handleGetConfig(server)(w, httptest.NewRequest(http.MethodGet, "/api/v1/config", nil))
+	var got struct {
+		Data files.Config `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if got.Data.AnilistClientSecret != redactedSecret {
+		t.Fatalf("GET devolveu o secret %q, quero a mascara", got.Data.AnilistClientSecret)
+	}
+	if mockFM.configs.AnilistClientSecret != "s3cret" {
+		t.Fatal("o GET nao pode mascarar a config carregada")
+	}
+
+	put := func(secret string) {
+		t.Helper()
+		cfg := got.Data
+		cfg.AnilistClientSecret = secret
+		body, _ := json.Marshal(cfg)
+		w := httptest.NewRecorder()
+		handleUpdateConfig(server)(w, httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("PUT com secret %q: %d %s", secret, w.Code, w.Body.String())
+		}
+	}
+
+	put(redactedSecret)
+	if mockFM.configs.AnilistClientSecret != "s3cret" {
+		t.Errorf("a mascara devolvida devia manter o secret, gravou %q", mockFM.configs.AnilistClientSecret)
+	}
+	put("novo")
+	if mockFM.configs.AnilistClientSecret != "novo" {
+		t.Errorf("secret novo nao gravou: %q", mockFM.configs.AnilistClientSecret)
+	}
+	put("")
+	if mockFM.configs.AnilistClientSecret != "" {
+		t.Errorf("vazio devia apagar o secret, ficou %q", mockFM.configs.AnilistClientSecret)
+	}
+}
+
 func TestHandleUpdateConfig(t *testing.T) {
 	state := daemon.NewState()
 	mockFM := &mockFileManager{}
@@ -303,6 +491,7 @@ func TestHandleUpdateConfig(t *testing.T) {
 			MaxEpisodesPerAnime:    20,
 			EpisodeRetryLimit:      3,
 			MaxConcurrentDownloads: 7,
+			QueuePolicy:            "smallest_first",
 		}
 
 		jsonData, _ := json.Marshal(config)
@@ -316,6 +505,208 @@ func TestHandleUpdateConfig(t *testing.T) {
 		if backend.MaxActiveDownloads != 7 {
 			t.Errorf("MaxActiveDownloads = %d, want 7", backend.MaxActiveDownloads)
 		}
+		if backend.QueuePolicy != torrents.QueueSmallestFirst {
+			t.Errorf("QueuePolicy = %q, want smallest_first", backend.QueuePolicy)
+		}
+	})
+
+	t.Run("PUT with unknown queue_policy returns 400", func(t *testing.T) {
+		config := files.Config{
+			AnilistUsernames:    []string{"newuser"},
+			CompletedAnimePath:  "/tmp/newcompleted",
+			CheckInterval:       15,
+			MaxEpisodesPerAnime: 20,
+			QueuePolicy:         "random",
+		}
+
+		jsonData, _ := json.Marshal(config)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("PUT with data_cap_billing_day out of range returns 400", func(t *testing.T) {
+		config := files.Config{
+			AnilistUsernames:    []string{"newuser"},
+			CompletedAnimePath:  "/tmp/newcompleted",
+			CheckInterval:       15,
+			MaxEpisodesPerAnime: 20,
+			DataCapGB:           100,
+			DataCapBillingDay:   31,
+		}
+
+		jsonData, _ := json.Marshal(config)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("PUT with an external torrent client and no URL returns 400", func(t *testing.T) {
+		for _, client := range []string{"qbittorrent", "transmission", "deluge"} {
+			config := files.Config{
+				AnilistUsernames:    []string{"newuser"},
+				CompletedAnimePath:  "/tmp/newcompleted",
+				CheckInterval:       15,
+				MaxEpisodesPerAnime: 20,
+				TorrentClient:       client,
+				TorrentClientURL:    "localhost:8080",
+			}
+
+			jsonData, _ := json.Marshal(config)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: Expected status code %d, got %d", client, http.StatusBadRequest, w.Code)
+			}
+		}
+	})
+
+	t.Run("PUT with an invalid media server returns 400", func(t *testing.T) {
+		for _, server := range []files.MediaServer{
+			{Name: "home", Type: "kodi", URL: "http://kodi:8080", Token: "k"},
+			{Name: "home", Type: "plex", URL: "plex:32400", Token: "k"},
+			{Name: "home", Type: "jellyfin", URL: "http://jellyfin:8096"},
+		} {
+			config := files.Config{
+				AnilistUsernames:    []string{"newuser"},
+				CompletedAnimePath:  "/tmp/newcompleted",
+				CheckInterval:       15,
+				MaxEpisodesPerAnime: 20,
+				MediaServers:        []files.MediaServer{server},
+			}
+
+			jsonData, _ := json.Marshal(config)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%+v: Expected status code %d, got %d", server, http.StatusBadRequest, w.Code)
+			}
+		}
+	})
+
+	t.Run("PUT defaults torrent_client and its category", func(t *testing.T) {
+		// Cliente anterior aos campos: sem torrent_client nem categoria.
+		config := files.Config{
+			AnilistUsernames:    []string{"newuser"},
+			CompletedAnimePath:  "/tmp/newcompleted",
+			CheckInterval:       15,
+			MaxEpisodesPerAnime: 20,
+		}
+
+		jsonData, _ := json.Marshal(config)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+		if mockFM.configs.TorrentClient != torrents.ClientEmbedded {
+			t.Errorf("TorrentClient = %q, want %q", mockFM.configs.TorrentClient, torrents.ClientEmbedded)
+		}
+		if mockFM.configs.TorrentClientCategory != torrents.DefaultCategory {
+			t.Errorf("TorrentClientCategory = %q, want %q", mockFM.configs.TorrentClientCategory, torrents.DefaultCategory)
+		}
+	})
+
+	t.Run("PUT with an invalid library template returns 400", func(t *testing.T) {
+		for _, tc := range []struct{ folder, file string }{
+			{"{title} - {episode}", ""},
+			{"", "{title} [{group}]"},
+			{"{title}", "{title} - E{episode:99}"},
+		} {
+			config := files.Config{
+				CompletedAnimePath:    "/tmp/newcompleted",
+				CheckInterval:         15,
+				LibraryFolderTemplate: tc.folder,
+				LibraryFileTemplate:   tc.file,
+			}
+
+			jsonData, _ := json.Marshal(config)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("folder %q, file %q: Expected status code %d, got %d", tc.folder, tc.file, http.StatusBadRequest, w.Code)
+			}
+		}
+	})
+
+	t.Run("PUT enqueues a relink only when the library naming changes", func(t *testing.T) {
+		jobsPath := filepath.Join(t.TempDir(), "jobs.json")
+		fm := &mockFileManager{configs: &files.Config{CompletedAnimePath: "/tmp/completed", CheckInterval: 10}}
+		srv := &Server{State: state, FileManager: fm, JobQueue: daemon.NewJobQueue(fm, jobsPath)}
+
+		put := func(config files.Config) {
+			t.Helper()
+			jsonData, _ := json.Marshal(config)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+			w := httptest.NewRecorder()
+			handleUpdateConfig(srv)(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+		}
+
+		// Saving the defaults over a config without the fields moves nothing.
+		put(files.Config{CompletedAnimePath: "/tmp/completed", CheckInterval: 10})
+		if _, err := os.Stat(jobsPath); !os.IsNotExist(err) {
+			t.Fatalf("no job expected for an unchanged naming, stat err = %v", err)
+		}
+		if fm.configs.LibraryFolderTemplate != files.DefaultFolderTemplate || fm.configs.LibraryFileTemplate != files.DefaultFileTemplate {
+			t.Errorf("templates saved as %q / %q, want the defaults", fm.configs.LibraryFolderTemplate, fm.configs.LibraryFileTemplate)
+		}
+
+		put(files.Config{CompletedAnimePath: "/tmp/completed", CheckInterval: 10, LibraryFolderTemplate: "{title} ({year})"})
+		data, err := os.ReadFile(jobsPath)
+		if err != nil {
+			t.Fatalf("expected a persisted relink job: %v", err)
+		}
+		if !strings.Contains(string(data), `"type":"relink"`) {
+			t.Errorf("jobs = %s, want a relink job", data)
+		}
+	})
+
+	t.Run("PUT applies data_cap_gb to the backend right away", func(t *testing.T) {
+		backend := torrents.NewFakeBackend()
+		backend.SetDataCapReached(true)
+		srv := &Server{State: state, FileManager: mockFM, Torrents: backend}
+
+		// Desligar o teto libera os torrents na hora; billing day 0 (cliente antigo) vira 1.
+		config := files.Config{
+			AnilistUsernames:    []string{"newuser"},
+			CompletedAnimePath:  "/tmp/newcompleted",
+			CheckInterval:       15,
+			MaxEpisodesPerAnime: 20,
+		}
+
+		jsonData, _ := json.Marshal(config)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+		w := httptest.NewRecorder()
+		handleUpdateConfig(srv)(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+		if backend.DataCapped {
+			t.Error("turning the data cap off must lift it right away")
+		}
+		if mockFM.configs.DataCapBillingDay != 1 {
+			t.Errorf("DataCapBillingDay = %d, want the default 1", mockFM.configs.DataCapBillingDay)
+		}
 	})
 
 	t.Run("PUT with negative max_concurrent_downloads returns 400", func(t *testing.T) {
@@ -337,6 +728,116 @@ func TestHandleUpdateConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("PUT with negative integrity_check_days returns 400", func(t *testing.T) {
+		config := files.Config{
+			AnilistUsernames:    []string{"newuser"},
+			CompletedAnimePath:  "/tmp/newcompleted",
+			CheckInterval:       15,
+			MaxEpisodesPerAnime: 20,
+			IntegrityCheckDays:  -1,
+		}
+
+		jsonData, _ := json.Marshal(config)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("PUT with negative sequel_lead_days returns 400", func(t *testing.T) {
+		config := files.Config{
+			AnilistUsernames:    []string{"newuser"},
+			CompletedAnimePath:  "/tmp/newcompleted",
+			CheckInterval:       15,
+			MaxEpisodesPerAnime: 20,
+			FollowSequels:       true,
+			SequelLeadDays:      -1,
+		}
+
+		jsonData, _ := json.Marshal(config)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("PUT with invalid auto-subscribe rule or trial_keep_days returns 400", func(t *testing.T) {
+		for name, config := range map[string]files.Config{
+			"rule without name":   {AutoSubscribeRules: []files.AutoSubscribeRule{{TrialEpisodes: 3}}},
+			"zero trial episodes": {AutoSubscribeRules: []files.AutoSubscribeRule{{Name: "r"}}},
+			"negative keep days":  {TrialKeepDays: -1},
+		} {
+			config.AnilistUsernames = []string{"newuser"}
+			config.CompletedAnimePath = "/tmp/newcompleted"
+			config.CheckInterval = 15
+			config.MaxEpisodesPerAnime = 20
+
+			jsonData, _ := json.Marshal(config)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status code %d, got %d", name, http.StatusBadRequest, w.Code)
+			}
+		}
+	})
+
+	t.Run("PUT with invalid account profile returns 400", func(t *testing.T) {
+		for name, profile := range map[string]files.AccountProfile{
+			"unknown account":     {Account: "someoneelse"},
+			"escaping subpath":    {Account: "newuser", LibrarySubpath: "../outside"},
+			"unknown list status": {Account: "newuser", DownloadStatuses: []string{"WATCHING"}},
+		} {
+			config := files.Config{
+				AnilistUsernames:    []string{"newuser"},
+				CompletedAnimePath:  "/tmp/newcompleted",
+				CheckInterval:       15,
+				MaxEpisodesPerAnime: 20,
+				AccountProfiles:     []files.AccountProfile{profile},
+			}
+
+			jsonData, _ := json.Marshal(config)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status code %d, got %d", name, http.StatusBadRequest, w.Code)
+			}
+		}
+	})
+
+	t.Run("PUT with invalid extra tracker or trackers list URL returns 400", func(t *testing.T) {
+		for name, config := range map[string]files.Config{
+			"wss tracker":      {ExtraTrackers: []string{"wss://tracker.webtorrent.dev"}},
+			"not a url":        {ExtraTrackers: []string{"tracker.example.com"}},
+			"udp list url":     {TrackersListURL: "udp://tracker.example.com:1337"},
+			"list url no host": {TrackersListURL: "https://"},
+			"ftp mapping url":  {IDMappingURL: "ftp://example.com/anime-offline-database.json"},
+		} {
+			config.AnilistUsernames = []string{"newuser"}
+			config.CompletedAnimePath = "/tmp/newcompleted"
+			config.CheckInterval = 15
+			config.MaxEpisodesPerAnime = 20
+
+			jsonData, _ := json.Marshal(config)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData))
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status code %d, got %d", name, http.StatusBadRequest, w.Code)
+			}
+		}
+	})
+
 	t.Run("PUT with missing anilist_username returns 400", func(t *testing.T) {
 		config := files.Config{
 			SavePath:            "/tmp/test",
@@ -438,6 +939,68 @@ func TestHandleUpdateConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("PUT probes the chosen library_link_mode and defaults it", func(t *testing.T) {
+		lib := &stubLibrarian{}
+		probeHandler := handleUpdateConfig(&Server{State: state, FileManager: mockFM, Librarian: lib})
+
+		for _, tc := range []struct{ mode, want string }{{"symlink", "symlink"}, {"", "hardlink"}} {
+			config := files.Config{
+				AnilistUsernames:    []string{"testuser"},
+				CompletedAnimePath:  "/tmp/completed",
+				CheckInterval:       10,
+				MaxEpisodesPerAnime: 12,
+				LibraryLinkMode:     tc.mode,
+			}
+			jsonData, _ := json.Marshal(config)
+			w := httptest.NewRecorder()
+			probeHandler(w, httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData)))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("%q: status = %d: %s", tc.mode, w.Code, w.Body.String())
+			}
+			if string(lib.probedMode) != tc.want || mockFM.configs.LibraryLinkMode != tc.want {
+				t.Errorf("%q: probed %q, saved %q; want %q", tc.mode, lib.probedMode, mockFM.configs.LibraryLinkMode, tc.want)
+			}
+		}
+
+		config := files.Config{CompletedAnimePath: "/tmp/completed", CheckInterval: 10, LibraryLinkMode: "junction"}
+		jsonData, _ := json.Marshal(config)
+		w := httptest.NewRecorder()
+		probeHandler(w, httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("unknown mode: status = %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("PUT probes the movies path only with the movie layout on", func(t *testing.T) {
+		lib := &stubLibrarian{}
+		moviesHandler := handleUpdateConfig(&Server{State: state, FileManager: mockFM, Librarian: lib})
+		put := func(config files.Config) int {
+			jsonData, _ := json.Marshal(config)
+			w := httptest.NewRecorder()
+			moviesHandler(w, httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBuffer(jsonData)))
+			return w.Code
+		}
+		config := files.Config{CompletedAnimePath: "/tmp/completed", CheckInterval: 10, LibraryMoviesPath: "/tmp/movies"}
+
+		if code := put(config); code != http.StatusOK || lib.probedMovies != "" {
+			t.Errorf("layout off: status = %d, probed %q; want 200 and no probe", code, lib.probedMovies)
+		}
+		config.LibraryMovieLayout = true
+		if code := put(config); code != http.StatusOK || lib.probedMovies != "/tmp/movies" {
+			t.Errorf("layout on: status = %d, probed %q", code, lib.probedMovies)
+		}
+		lib.moviesProbeErr = fmt.Errorf("the movies path does not support hardlinks")
+		if code := put(config); code != http.StatusBadRequest {
+			t.Errorf("failing probe: status = %d, want 400", code)
+		}
+		lib.moviesProbeErr = nil
+		config.LibraryMoviesPath = "movies"
+		if code := put(config); code != http.StatusBadRequest {
+			t.Errorf("relative movies path: status = %d, want 400", code)
+		}
+	})
+
 	t.Run("PUT with invalid JSON returns 400", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBufferString("invalid json"))
 		req.Header.Set("Content-Type", "application/json")
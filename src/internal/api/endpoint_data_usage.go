@@ -0,0 +1,166 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/daemon"
+	"AutoAnimeDownloader/src/internal/files"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// dataUsageHistoryPeriods e quantos ciclos, o atual incluido, entram em DataUsageResponse.Periods.
+const dataUsageHistoryPeriods = 12
+
+// DataUsageBytes e um par download/upload em bytes, com a soma — que e o que conta para o teto.
+type DataUsageBytes struct {
+	Downloaded int64 `json:"downloaded" example:"1073741824"`
+	Uploaded   int64 `json:"uploaded" example:"536870912"`
+	Total      int64 `json:"total" example:"1610612736"`
+}
+
+func toDataUsageBytes(b files.ByteCounts) DataUsageBytes {
+	return DataUsageBytes{Downloaded: b.Downloaded, Uploaded: b.Uploaded, Total: b.Total()}
+}
+
+type DataUsageDay struct {
+	Date string `json:"date" example:"2026-10-18"`
+	DataUsageBytes
+}
+
+type DataUsagePeriod struct {
+	Start time.Time `json:"start" example:"2026-10-01T00:00:00-03:00"`
+	End   time.Time `json:"end" example:"2026-11-01T00:00:00-03:00"`
+	DataUsageBytes
+}
+
+type DataUsageAnime struct {
+	AnimeID   int    `json:"anime_id" example:"269"`
+	AnimeName string `json:"anime_name" example:"Bleach"`
+	DataUsageBytes
+}
+
+// DataUsageResponse e o trafego do ciclo atual da operadora (Config.DataCapBillingDay) e dos
+// anteriores. Com ?anime_id, todos os numeros sao so daquele anime e Animes vem vazio.
+type DataUsageResponse struct {
+	// CapBytes e Config.DataCapGB em bytes; 0 = sem teto.
+	CapBytes    int64     `json:"cap_bytes" example:"107374182400"`
+	CapReached  bool      `json:"cap_reached" example:"false"`
+	BillingDay  int       `json:"billing_day" example:"1"`
+	PeriodStart time.Time `json:"period_start" example:"2026-10-01T00:00:00-03:00"`
+	// PeriodEnd e a virada: o teto atingido so e liberado nela.
+	PeriodEnd time.Time      `json:"period_end" example:"2026-11-01T00:00:00-03:00"`
+	AnimeID   int            `json:"anime_id,omitempty" example:"269"`
+	Period    DataUsageBytes `json:"period"`
+	// Days sao todos os dias do ciclo atual ate hoje, em ordem, os sem trafego inclusive.
+	Days []DataUsageDay `json:"days"`
+	// Periods sao os ultimos ciclos, o atual primeiro.
+	Periods []DataUsagePeriod `json:"periods"`
+	// Animes e o ciclo atual por anime, o que mais gastou primeiro. Trafego de torrent sem
+	// anime (adicionado a mao) entra em Period mas em nenhuma linha daqui.
+	Animes []DataUsageAnime `json:"animes"`
+}
+
+// @Summary      Get data usage
+// @Description  Returns the traffic ledger: the current billing period's total, its days and the per-anime split, plus the totals of the previous periods. With anime_id every number is that anime's share. Download and upload both count toward data_cap_gb.
+// @Tags         data-usage
+// @Produce      json
+// @Param        anime_id  query     int  false  "Restrict the numbers to one anime (AniList media ID)"
+// @Success      200       {object}  SuccessResponse{data=DataUsageResponse}
+// @Failure      400       {object}  SuccessResponse
+// @Failure      405       {object}  SuccessResponse
+// @Failure      500       {object}  SuccessResponse
+// @Router       /data-usage [get]
+func handleDataUsage(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET method is allowed")
+			return
+		}
+
+		animeID := 0
+		if raw := r.URL.Query().Get("anime_id"); raw != "" {
+			id, err := strconv.Atoi(raw)
+			if err != nil || id <= 0 {
+				JSONError(w, http.StatusBadRequest, "INVALID_ANIME_ID", "anime_id must be a positive integer")
+				return
+			}
+			animeID = id
+		}
+
+		configs, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			JSONInternalError(w, err)
+			return
+		}
+		ledger, err := server.FileManager.LoadDataUsage()
+		if err != nil {
+			JSONInternalError(w, err)
+			return
+		}
+
+		now := time.Now()
+		start, end := daemon.BillingPeriod(now, configs.DataCapBillingDay)
+		response := DataUsageResponse{
+			CapBytes:    daemon.DataCapBytes(configs),
+			BillingDay:  configs.DataCapBillingDay,
+			PeriodStart: start,
+			PeriodEnd:   end,
+			AnimeID:     animeID,
+			Period:      toDataUsageBytes(daemon.UsageBetween(ledger, start, end, animeID)),
+			Days:        []DataUsageDay{},
+			Periods:     []DataUsagePeriod{},
+			Animes:      []DataUsageAnime{},
+		}
+		if server.Torrents != nil {
+			response.CapReached = server.Torrents.DataCapReached()
+		}
+
+		for d := start; !d.After(now); d = d.AddDate(0, 0, 1) {
+			key := d.Format(files.DataUsageDateLayout)
+			day := ledger.Days[key]
+			counts := day.ByteCounts
+			if animeID > 0 {
+				counts = day.Animes[animeID]
+			}
+			response.Days = append(response.Days, DataUsageDay{Date: key, DataUsageBytes: toDataUsageBytes(counts)})
+		}
+
+		// Um dia antes do inicio cai sempre no ciclo anterior.
+		pStart, pEnd := start, end
+		for range dataUsageHistoryPeriods {
+			response.Periods = append(response.Periods, DataUsagePeriod{
+				Start:          pStart,
+				End:            pEnd,
+				DataUsageBytes: toDataUsageBytes(daemon.UsageBetween(ledger, pStart, pEnd, animeID)),
+			})
+			pStart, pEnd = daemon.BillingPeriod(pStart.AddDate(0, 0, -1), configs.DataCapBillingDay)
+		}
+
+		if animeID == 0 {
+			perAnime := map[int]files.ByteCounts{}
+			for d := start; !d.After(now); d = d.AddDate(0, 0, 1) {
+				for id, counts := range ledger.Days[d.Format(files.DataUsageDateLayout)].Animes {
+					total := perAnime[id]
+					total.Add(counts)
+					perAnime[id] = total
+				}
+			}
+			for id, counts := range perAnime {
+				response.Animes = append(response.Animes, DataUsageAnime{
+					AnimeID:        id,
+					AnimeName:      ledger.AnimeNames[id],
+					DataUsageBytes: toDataUsageBytes(counts),
+				})
+			}
+			sort.Slice(response.Animes, func(i, j int) bool {
+				if response.Animes[i].Total != response.Animes[j].Total {
+					return response.Animes[i].Total > response.Animes[j].Total
+				}
+				return response.Animes[i].AnimeID < response.Animes[j].AnimeID
+			})
+		}
+
+		JSONSuccess(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/daemon"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func getDataUsage(t *testing.T, server *Server, query string) (int, DataUsageResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/data-usage"+query, nil)
+	w := httptest.NewRecorder()
+	handleDataUsage(server)(w, req)
+
+	var body struct {
+		Data DataUsageResponse `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return w.Code, body.Data
+}
+
+func TestHandleDataUsage(t *testing.T) {
+	now := time.Now()
+	start, _ := daemon.BillingPeriod(now, 1)
+	today := now.Format(files.DataUsageDateLayout)
+	lastPeriod := start.AddDate(0, 0, -1).Format(files.DataUsageDateLayout)
+
+	fm := &mockFileManager{
+		configs: &files.Config{DataCapGB: 10, DataCapBillingDay: 1},
+		dataUsage: &files.DataUsageLedger{
+			Days: map[string]files.DayUsage{
+				today: {
+					ByteCounts: files.ByteCounts{Downloaded: 900, Uploaded: 100},
+					Animes: map[int]files.ByteCounts{
+						7: {Downloaded: 600, Uploaded: 100},
+						9: {Downloaded: 200},
+					},
+				},
+				lastPeriod: {ByteCounts: files.ByteCounts{Downloaded: 50}},
+			},
+			AnimeNames: map[int]string{7: "Frieren", 9: "Dandadan"},
+		},
+	}
+	backend := torrents.NewFakeBackend()
+	backend.SetDataCapReached(true)
+	server := &Server{State: daemon.NewState(), FileManager: fm, Torrents: backend}
+
+	t.Run("overall", func(t *testing.T) {
+		code, data := getDataUsage(t, server, "")
+		if code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, code)
+		}
+		if data.Period.Total != 1000 || !data.CapReached || data.CapBytes != 10*1024*1024*1024 {
+			t.Errorf("period = %+v, cap_reached = %v, cap_bytes = %d", data.Period, data.CapReached, data.CapBytes)
+		}
+		if last := data.Days[len(data.Days)-1]; last.Date != today || last.Total != 1000 {
+			t.Errorf("last day = %+v, want today with 1000 bytes", last)
+		}
+		if len(data.Periods) != 12 || data.Periods[0].Total != 1000 || data.Periods[1].Total != 50 {
+			t.Errorf("periods = %+v", data.Periods)
+		}
+		if len(data.Animes) != 2 || data.Animes[0].AnimeName != "Frieren" || data.Animes[0].Total != 700 {
+			t.Errorf("animes = %+v, want Frieren first with 700 bytes", data.Animes)
+		}
+	})
+
+	t.Run("one anime", func(t *testing.T) {
+		code, data := getDataUsage(t, server, "?anime_id=9")
+		if code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, code)
+		}
+		if data.Period.Downloaded != 200 || data.Period.Uploaded != 0 || len(data.Animes) != 0 {
+			t.Errorf("period = %+v, animes = %+v; want only Dandadan's 200 bytes", data.Period, data.Animes)
+		}
+	})
+
+	t.Run("invalid anime_id", func(t *testing.T) {
+		if code, _ := getDataUsage(t, server, "?anime_id=abc"); code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, code)
+		}
+	})
+}
@@ -480,3 +480,52 @@ func handleReplaceAnimeWithMagnet(server *Server) http.HandlerFunc {
 		JSONSuccess(w, http.StatusOK, map[string]string{"message": "Anime replacement started"})
 	}
 }
+
+// @Summary      Mark an episode as watched
+// @Description  Moves the anime's progress up to the episode: a standalone anime's saved progress, or the AniList progress of every configured account that tracks it and is logged in (moving the entry to COMPLETED on the last episode with anilist_complete_on_last_episode). Progress never moves down. Watched-episode pruning runs on the next pass
+// @Tags         animes
+// @Produce      json
+// @Param        id        path int true "Anime ID (AniList media ID)"
+// @Param        episodeNumber path int true "Episode number (1-based, as aired)"
+// @Success      200  {object}  SuccessResponse{data=daemon.WatchedResult}
+// @Failure      400  {object}  SuccessResponse
+// @Failure      405  {object}  SuccessResponse
+// @Failure      500  {object}  SuccessResponse
+// @Router       /animes/{id}/episodes/{episodeNumber}/watched [post]
+func handleMarkEpisodeWatched(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST method is allowed")
+			return
+		}
+
+		animeId, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || animeId <= 0 {
+			JSONError(w, http.StatusBadRequest, "INVALID_ID", "Invalid anime ID")
+			return
+		}
+
+		episodeNumber, err := strconv.Atoi(r.PathValue("episodeNumber"))
+		if err != nil || episodeNumber <= 0 {
+			JSONError(w, http.StatusBadRequest, "INVALID_EPISODE_NUMBER", "Invalid episode number")
+			return
+		}
+
+		configs, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load configs")
+			JSONInternalError(w, err)
+			return
+		}
+
+		// Conta sem login ou que a AniList recusou nao e erro do request: vem por conta em
+		// accounts, e a tela mostra qual ficou de fora.
+		result, err := daemon.MarkWatched(server.FileManager, configs, animeId, episodeNumber)
+		if err != nil {
+			logger.Logger.Error().Err(err).Int("anime_id", animeId).Int("episode", episodeNumber).Msg("Failed to mark episode as watched")
+			JSONInternalError(w, err)
+			return
+		}
+		JSONSuccess(w, http.StatusOK, result)
+	}
+}
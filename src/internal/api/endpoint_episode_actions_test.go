@@ -325,3 +325,30 @@ func TestHandleDownloadEpisode_UpdatesExistingRecord(t *testing.T) {
 		t.Errorf("EpisodeNumber deve continuar 5, obteve %d", ep.EpisodeNumber)
 	}
 }
+
+func TestHandleMarkEpisodeWatched_StandaloneAdvancesProgress(t *testing.T) {
+	fm := &mockFileManager{
+		configs:          &files.Config{},
+		standaloneAnimes: []int{21},
+		animeSettings:    map[int]files.AnimeSettings{21: {Progress: 2}},
+	}
+	watched := func(ep string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/animes/21/episodes/"+ep+"/watched", nil)
+		req.SetPathValue("id", "21")
+		req.SetPathValue("episodeNumber", ep)
+		rec := httptest.NewRecorder()
+		handleMarkEpisodeWatched(standaloneServer(fm))(rec, req)
+		return rec
+	}
+
+	if rec := watched("0"); rec.Code != http.StatusBadRequest {
+		t.Errorf("episode 0: %d", rec.Code)
+	}
+	if rec := watched("5"); rec.Code != http.StatusOK || fm.animeSettings[21].Progress != 5 {
+		t.Fatalf("status %d, progress %d: %s", rec.Code, fm.animeSettings[21].Progress, rec.Body.String())
+	}
+	// Marcar um episodio anterior nao volta o progresso.
+	if rec := watched("3"); rec.Code != http.StatusOK || fm.animeSettings[21].Progress != 5 {
+		t.Errorf("status %d, progress %d", rec.Code, fm.animeSettings[21].Progress)
+	}
+}
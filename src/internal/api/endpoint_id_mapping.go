@@ -0,0 +1,87 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/daemon"
+	"AutoAnimeDownloader/src/internal/idmap"
+	"AutoAnimeDownloader/src/internal/logger"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// @Summary      Get the ids of an anime on other sites
+// @Description  Looks the AniList media id up in the offline id mapping dataset (anime-offline-database) and returns its MyAnimeList, AniDB and Kitsu ids. A site the dataset does not know is 0. 404 when the dataset is not loaded or does not have this anime.
+// @Tags         animes
+// @Produce      json
+// @Param        id   path      int  true  "AniList media ID"
+// @Success      200  {object}  SuccessResponse{data=idmap.IDs}
+// @Failure      400  {object}  SuccessResponse
+// @Failure      404  {object}  SuccessResponse
+// @Failure      405  {object}  SuccessResponse
+// @Router       /animes/{id}/ids [get]
+func handleAnimeIDs(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET method is allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id <= 0 {
+			JSONError(w, http.StatusBadRequest, "INVALID_ID", "Invalid anime ID")
+			return
+		}
+		if !idmap.CurrentStatus().Loaded {
+			JSONError(w, http.StatusNotFound, "ID_MAPPING_NOT_LOADED", "No id mapping dataset is loaded")
+			return
+		}
+		entry, ok := idmap.ByAniList(id)
+		if !ok {
+			JSONError(w, http.StatusNotFound, "ANIME_NOT_FOUND", "The id mapping dataset does not have this media id")
+			return
+		}
+		JSONSuccess(w, http.StatusOK, entry.IDs)
+	}
+}
+
+// @Summary      Get or refresh the id mapping dataset
+// @Description  GET returns what is loaded. POST downloads the dataset from id_mapping_url right away (ignoring its weekly schedule) and reloads it; without a URL it re-reads anime-offline-database.json from the config folder. A failed download keeps the previous dataset.
+// @Tags         id-mapping
+// @Produce      json
+// @Success      200  {object}  SuccessResponse{data=idmap.Status}
+// @Failure      404  {object}  SuccessResponse
+// @Failure      405  {object}  SuccessResponse
+// @Failure      502  {object}  SuccessResponse
+// @Router       /id-mapping [get]
+// @Router       /id-mapping/refresh [post]
+func handleIDMapping(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/id-mapping" {
+			if r.Method != http.MethodGet {
+				JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET method is allowed")
+				return
+			}
+			JSONSuccess(w, http.StatusOK, idmap.CurrentStatus())
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST method is allowed")
+			return
+		}
+		configs, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			JSONInternalError(w, err)
+			return
+		}
+		status, err := daemon.RefreshIDMapping(server.FileManager, configs)
+		if errors.Is(err, daemon.ErrNoIDMapping) {
+			JSONError(w, http.StatusNotFound, "ID_MAPPING_NOT_FOUND", err.Error())
+			return
+		} else if err != nil {
+			logger.Logger.Warn().Err(err).Msg("Id mapping refresh failed")
+			JSONError(w, http.StatusBadGateway, "ID_MAPPING_REFRESH_FAILED", err.Error())
+			return
+		}
+		JSONSuccess(w, http.StatusOK, status)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/daemon"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/idmap"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const idMappingDataset = `{
+	"lastUpdate": "2026-10-12",
+	"data": [{
+		"sources": ["https://anilist.co/anime/154587", "https://myanimelist.net/anime/52991", "https://anidb.net/anime/17617", "https://kitsu.app/anime/46474"],
+		"title": "Sousou no Frieren"
+	}]
+}`
+
+func TestHandleAnimeIDs(t *testing.T) {
+	t.Cleanup(idmap.Reset)
+	server := &Server{State: daemon.NewState(), FileManager: &mockFileManager{configs: &files.Config{}}}
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/animes/"+id+"/ids", nil)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handleAnimeIDs(server)(w, req)
+		return w
+	}
+
+	if w := get("154587"); w.Code != http.StatusNotFound {
+		t.Errorf("without dataset: expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	if err := idmap.Load([]byte(idMappingDataset), time.Now()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	w := get("154587")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var body struct {
+		Data idmap.IDs `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if want := (idmap.IDs{AniList: 154587, MyAnimeList: 52991, AniDB: 17617, Kitsu: 46474}); body.Data != want {
+		t.Errorf("ids = %+v, want %+v", body.Data, want)
+	}
+
+	if w := get("1"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown media: expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+	if w := get("abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid id: expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandleIDMappingRefresh(t *testing.T) {
+	t.Cleanup(idmap.Reset)
+	fm := &mockFileManager{configs: &files.Config{}}
+	server := &Server{State: daemon.NewState(), FileManager: fm}
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		handleIDMapping(server)(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, "/api/v1/id-mapping/refresh"); w.Code != http.StatusNotFound {
+		t.Errorf("without URL nor file: expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	// Sem URL, o refresh le o arquivo posto a mao na pasta do config.
+	fm.idMapping = []byte(idMappingDataset)
+	fm.idMappingSavedAt = time.Now()
+	if w := do(http.MethodPost, "/api/v1/id-mapping/refresh"); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+
+	w := do(http.MethodGet, "/api/v1/id-mapping")
+	var body struct {
+		Data idmap.Status `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !body.Data.Loaded || body.Data.Entries != 1 || body.Data.DatasetDate != "2026-10-12" {
+		t.Errorf("status = %+v", body.Data)
+	}
+
+	if w := do(http.MethodGet, "/api/v1/id-mapping/refresh"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET refresh: expected status code %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
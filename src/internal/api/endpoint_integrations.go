@@ -0,0 +1,108 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/daemon"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/mediaserver"
+	"io"
+	"net/http"
+)
+
+// maxPlaybackPayload limita o corpo do webhook. O do Plex e multipart e pode trazer a capa do
+// episodio, que fica de fora do que interessa mas entra no corpo.
+const maxPlaybackPayload = 10 << 20
+
+// @Summary      Receive a playback webhook
+// @Description  Accepts a Jellyfin Webhook plugin payload (source "jellyfin") or a Plex webhook (source "plex", multipart with a "payload" field). A watched episode is mapped back to its record through its library path, and a standalone anime's progress moves up to it. The item's file is asked to the media server named by "server" (default: the first one of the source's type). Events that are not "watched" answer 200 with action "ignored"
+// @Tags         media-servers
+// @Accept       json
+// @Accept       mpfd
+// @Produce      json
+// @Param        source  path      string  true   "jellyfin or plex"
+// @Param        server  query     string  false  "Media server name"
+// @Success      200     {object}  SuccessResponse{data=daemon.PlaybackResult}
+// @Failure      400     {object}  SuccessResponse
+// @Failure      404     {object}  SuccessResponse
+// @Failure      405     {object}  SuccessResponse
+// @Failure      500     {object}  SuccessResponse
+// @Failure      502     {object}  SuccessResponse
+// @Router       /integrations/{source}/playback [post]
+func handlePlaybackWebhook(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST is allowed")
+			return
+		}
+		source := r.PathValue("source")
+		r.Body = http.MaxBytesReader(w, r.Body, maxPlaybackPayload)
+
+		var playback mediaserver.Playback
+		var err error
+		switch source {
+		case mediaserver.TypeJellyfin:
+			var data []byte
+			if data, err = io.ReadAll(r.Body); err == nil {
+				playback, err = mediaserver.ParseJellyfinPlayback(data)
+			}
+		case mediaserver.TypePlex:
+			if err = r.ParseMultipartForm(maxPlaybackPayload); err == nil {
+				playback, err = mediaserver.ParsePlexPlayback([]byte(r.FormValue("payload")))
+			}
+		default:
+			JSONError(w, http.StatusNotFound, "UNKNOWN_SOURCE", "Playback source must be jellyfin or plex")
+			return
+		}
+		if err != nil {
+			JSONError(w, http.StatusBadRequest, "INVALID_BODY", err.Error())
+			return
+		}
+		if !playback.Watched {
+			JSONSuccess(w, http.StatusOK, daemon.PlaybackResult{Action: daemon.PlaybackIgnored})
+			return
+		}
+
+		cfg, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load configs for playback webhook")
+			JSONInternalError(w, err)
+			return
+		}
+		srv, found := playbackServer(cfg.MediaServers, source, r.URL.Query().Get("server"))
+		remote := playback.Path
+		if remote == "" {
+			// Sem o caminho no payload, quem sabe o arquivo e o servidor.
+			if !found {
+				JSONError(w, http.StatusNotFound, "MEDIA_SERVER_NOT_FOUND", "no "+source+" media server is configured to resolve the played item")
+				return
+			}
+			if remote, err = mediaserver.ItemPath(srv, playback.ItemID); err != nil {
+				JSONError(w, http.StatusBadGateway, "MEDIA_SERVER_UNREACHABLE", err.Error())
+				return
+			}
+		}
+		// Sem servidor configurado, nao ha mapeamento e o caminho vale como esta.
+		local := mediaserver.LocalPath(srv, cfg, remote)
+
+		result, err := daemon.RecordPlayback(server.FileManager, cfg, local)
+		if err != nil {
+			logger.Logger.Error().Err(err).Str("path", local).Msg("Failed to record playback")
+			JSONInternalError(w, err)
+			return
+		}
+		logger.Logger.Debug().Str("source", source).Str("user", playback.User).Str("item_type", playback.ItemType).
+			Str("path", local).Str("action", string(result.Action)).Msg("Playback webhook handled")
+		JSONSuccess(w, http.StatusOK, result)
+	}
+}
+
+// playbackServer escolhe o servidor que resolve o item: o do parametro server, ou o primeiro do
+// tipo da origem. O webhook do Jellyfin nao serve para o Emby, que manda outro payload.
+func playbackServer(servers []files.MediaServer, source, name string) (files.MediaServer, bool) {
+	for _, s := range servers {
+		if (name != "" && s.Name == name) || (name == "" && s.Type == source) {
+			return s, true
+		}
+	}
+	return files.MediaServer{}, false
+}
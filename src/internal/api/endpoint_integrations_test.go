@@ -0,0 +1,111 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandlePlaybackWebhook(t *testing.T) {
+	video := filepath.Join("/srv/anime", "Frieren", "Frieren - S01E05.mkv")
+	plex := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/library/metadata/42" || r.Header.Get("X-Plex-Token") != "k" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"MediaContainer":{"Metadata":[{"Media":[{"Part":[{"file":"/data/Frieren/Frieren - S01E05.mkv"}]}]}]}}`))
+	}))
+	defer plex.Close()
+
+	newFM := func() *mockFileManager {
+		return &mockFileManager{
+			configs: &files.Config{
+				CompletedAnimePath: "/srv/anime",
+				MediaServers:       []files.MediaServer{{Name: "plex", Type: "plex", URL: plex.URL, Token: "k", LibraryPath: "/data"}},
+			},
+			episodes:         []files.EpisodeStruct{{AnimeID: 7, AnimeName: "Frieren", EpisodeNumber: 5, LibraryPaths: []string{video}}},
+			standaloneAnimes: []int{7},
+			animeSettings:    map[int]files.AnimeSettings{7: {Progress: 4}},
+		}
+	}
+	call := func(fm *mockFileManager, source, contentType string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/integrations/"+source+"/playback", bytes.NewReader(body))
+		req.SetPathValue("source", source)
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		handlePlaybackWebhook(&Server{FileManager: fm})(rec, req)
+		return rec
+	}
+	action := func(t *testing.T, rec *httptest.ResponseRecorder) string {
+		t.Helper()
+		var resp struct {
+			Data struct {
+				Action string `json:"action"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode %s: %v", rec.Body.String(), err)
+		}
+		return resp.Data.Action
+	}
+
+	t.Run("Plex scrobble resolves the item and advances the progress", func(t *testing.T) {
+		fm := newFM()
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		mw.WriteField("payload", `{"event":"media.scrobble","Metadata":{"ratingKey":"42","type":"episode"}}`)
+		mw.Close()
+
+		rec := call(fm, "plex", mw.FormDataContentType(), body.Bytes())
+		if rec.Code != http.StatusOK || action(t, rec) != "progress_updated" {
+			t.Fatalf("expected progress_updated, got %d %s", rec.Code, rec.Body.String())
+		}
+		if got := fm.animeSettings[7].Progress; got != 5 {
+			t.Errorf("progress = %d, want 5", got)
+		}
+	})
+
+	t.Run("Jellyfin payload with the path needs no server call", func(t *testing.T) {
+		fm := newFM()
+		fm.configs.MediaServers = nil
+		body := `{"NotificationType":"PlaybackStop","PlayedToCompletion":true,"Path":"` + filepath.ToSlash(video) + `"}`
+		rec := call(fm, "jellyfin", "application/json", []byte(body))
+		if rec.Code != http.StatusOK || action(t, rec) != "progress_updated" {
+			t.Fatalf("expected progress_updated, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("Jellyfin event that is not a watch is ignored", func(t *testing.T) {
+		rec := call(newFM(), "jellyfin", "application/json", []byte(`{"NotificationType":"PlaybackStart","ItemId":"abc"}`))
+		if rec.Code != http.StatusOK || action(t, rec) != "ignored" {
+			t.Fatalf("expected ignored, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("Jellyfin item without a Jellyfin server returns 404", func(t *testing.T) {
+		rec := call(newFM(), "jellyfin", "application/json", []byte(`{"NotificationType":"PlaybackStop","PlayedToCompletion":true,"ItemId":"abc"}`))
+		if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "MEDIA_SERVER_NOT_FOUND") {
+			t.Fatalf("expected 404, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("malformed payload returns 400", func(t *testing.T) {
+		rec := call(newFM(), "jellyfin", "application/json", []byte(`{`))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("unknown source returns 404", func(t *testing.T) {
+		rec := call(newFM(), "kodi", "application/json", []byte(`{}`))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", rec.Code)
+		}
+	})
+}
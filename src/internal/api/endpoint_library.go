@@ -0,0 +1,339 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/daemon"
+	"AutoAnimeDownloader/src/internal/files"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// defaultNamingPreviewLimit e quantos itens o preview devolve sem limit: o bastante para ver
+// o template em varios animes sem mandar a biblioteca inteira para a tela de config.
+const defaultNamingPreviewLimit = 50
+
+// NamingPreviewRequest is the optional body of the naming preview. A field left out uses the
+// saved config, so an empty body previews what the next relink would do.
+type NamingPreviewRequest struct {
+	LibraryFolderTemplate  *string `json:"library_folder_template" example:"{title} ({year})"`
+	LibraryFileTemplate    *string `json:"library_file_template" example:"{title} - S{season:02}E{episode:02}"`
+	RenameFilesForJellyfin *bool   `json:"rename_files_for_jellyfin" example:"true"`
+	LibrarySeasonFolders   *bool   `json:"library_season_folders" example:"false"`
+	LibraryMovieLayout     *bool   `json:"library_movie_layout" example:"true"`
+	LibraryMoviesPath      *string `json:"library_movies_path" example:"/media/Movies"`
+	// Limit caps Items; 0 means 50.
+	Limit int `json:"limit" example:"50"`
+}
+
+// NamingPreviewResponse is the library as the templates would name it.
+type NamingPreviewResponse struct {
+	// Total is every organized library file; Changed, the ones the templates would move.
+	Total   int `json:"total" example:"120"`
+	Changed int `json:"changed" example:"118"`
+	// Tokens lists the tokens the templates accept.
+	Tokens []string `json:"tokens"`
+	// Items are the files that would move first, then the ones that stay, up to limit.
+	Items []files.LibraryMove `json:"items"`
+}
+
+// @Summary      Preview library naming templates
+// @Description  Renders library_folder_template and library_file_template against the episodes already organized into the library, without touching the disk. With library_season_folders, an entry whose series the relink has not resolved yet still shows in its own folder; likewise with library_movie_layout, a movie organized before the format was recorded shows as a series until the relink resolves it. Fields left out of the body use the saved config. Saving templates that differ from the current ones moves the library to these names in the background.
+// @Tags         library
+// @Accept       json
+// @Produce      json
+// @Param        request  body      NamingPreviewRequest  false  "Templates to preview"
+// @Success      200      {object}  SuccessResponse{data=NamingPreviewResponse}
+// @Failure      400      {object}  SuccessResponse
+// @Failure      405      {object}  SuccessResponse
+// @Failure      500      {object}  SuccessResponse
+// @Router       /library/naming/preview [post]
+func handleLibraryNamingPreview(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST method is allowed")
+			return
+		}
+
+		var req NamingPreviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			JSONError(w, http.StatusBadRequest, "INVALID_BODY", "Invalid JSON body")
+			return
+		}
+		if req.Limit < 0 {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Limit must be non-negative")
+			return
+		}
+		if req.Limit == 0 {
+			req.Limit = defaultNamingPreviewLimit
+		}
+
+		saved, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			JSONInternalError(w, err)
+			return
+		}
+		// Copia: o preview nunca pode vazar para a config carregada.
+		configs := *saved
+		if req.LibraryFolderTemplate != nil {
+			configs.LibraryFolderTemplate = *req.LibraryFolderTemplate
+		}
+		if req.LibraryFileTemplate != nil {
+			configs.LibraryFileTemplate = *req.LibraryFileTemplate
+		}
+		if req.RenameFilesForJellyfin != nil {
+			configs.RenameFilesForJellyfin = *req.RenameFilesForJellyfin
+		}
+		if req.LibrarySeasonFolders != nil {
+			configs.LibrarySeasonFolders = *req.LibrarySeasonFolders
+		}
+		if req.LibraryMovieLayout != nil {
+			configs.LibraryMovieLayout = *req.LibraryMovieLayout
+		}
+		if req.LibraryMoviesPath != nil {
+			configs.LibraryMoviesPath = *req.LibraryMoviesPath
+		}
+
+		// Mesma validacao do PUT /config: o preview e para o usuario ver o erro antes de salvar.
+		naming := configs.LibraryNaming()
+		if err := files.ValidateFolderTemplate(naming.FolderTemplate); err != nil {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid library folder template: "+err.Error())
+			return
+		}
+		if err := files.ValidateFileTemplate(naming.FileTemplate); err != nil {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid library file template: "+err.Error())
+			return
+		}
+
+		episodes, err := server.FileManager.LoadSavedEpisodes()
+		if err != nil {
+			JSONInternalError(w, err)
+			return
+		}
+
+		moves := files.PlanLibraryMoves(episodes, configs.CompletedAnimePath, naming)
+		response := NamingPreviewResponse{
+			Total:  len(moves),
+			Tokens: files.NamingTokens,
+			Items:  []files.LibraryMove{},
+		}
+		var unchanged []files.LibraryMove
+		for _, mv := range moves {
+			if mv.From == mv.To {
+				unchanged = append(unchanged, mv)
+				continue
+			}
+			response.Changed++
+			if len(response.Items) < req.Limit {
+				response.Items = append(response.Items, mv)
+			}
+		}
+		for _, mv := range unchanged {
+			if len(response.Items) >= req.Limit {
+				break
+			}
+			response.Items = append(response.Items, mv)
+		}
+
+		JSONSuccess(w, http.StatusOK, response)
+	}
+}
+
+// LinkProbeRequest is the optional body of the link probe. An empty path probes the saved
+// library.
+type LinkProbeRequest struct {
+	CompletedAnimePath string `json:"completed_anime_path" example:"/media/Animes"`
+}
+
+// LinkProbeResponse lists the library link modes that work on a library's filesystem.
+type LinkProbeResponse struct {
+	CompletedAnimePath string `json:"completed_anime_path" example:"/media/Animes"`
+	// Supported are the modes that worked, in the order of Modes.
+	Supported []files.LinkMode `json:"supported" swaggertype:"array,string" example:"symlink,copy"`
+	// Modes are every mode library_link_mode accepts.
+	Modes []files.LinkMode `json:"modes" swaggertype:"array,string" example:"hardlink,reflink,symlink,copy"`
+	// Current is the saved library_link_mode.
+	Current files.LinkMode `json:"current" swaggertype:"string" example:"hardlink"`
+}
+
+// @Summary      Probe library link modes
+// @Description  Tries every library_link_mode (hardlink, reflink, symlink, copy) on the library's filesystem with a small probe file and reports which ones work, so the config screen can offer only those. Creates the library and its download folder if missing, like saving the config does.
+// @Tags         library
+// @Accept       json
+// @Produce      json
+// @Param        request  body      LinkProbeRequest  false  "Library to probe"
+// @Success      200      {object}  SuccessResponse{data=LinkProbeResponse}
+// @Failure      400      {object}  SuccessResponse
+// @Failure      405      {object}  SuccessResponse
+// @Failure      500      {object}  SuccessResponse
+// @Router       /library/link-probe [post]
+func handleLibraryLinkProbe(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST method is allowed")
+			return
+		}
+
+		var req LinkProbeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			JSONError(w, http.StatusBadRequest, "INVALID_BODY", "Invalid JSON body")
+			return
+		}
+
+		saved, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			JSONInternalError(w, err)
+			return
+		}
+		path := req.CompletedAnimePath
+		if path == "" {
+			path = saved.CompletedAnimePath
+		}
+		if server.Librarian == nil {
+			JSONInternalError(w, errors.New("librarian not initialized"))
+			return
+		}
+
+		// Caminho invalido (vazio, sem permissao) e erro do usuario, como no PUT /config.
+		supported, err := server.Librarian.ProbeLinkModes(path)
+		if err != nil {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+
+		JSONSuccess(w, http.StatusOK, LinkProbeResponse{
+			CompletedAnimePath: path,
+			Supported:          supported,
+			Modes:              files.LinkModes,
+			Current:            saved.LinkMode(),
+		})
+	}
+}
+
+// LibraryAuditResponse lists what the audit found, with a count per category.
+type LibraryAuditResponse struct {
+	Findings []files.AuditFinding `json:"findings"`
+	// Counts has every category, zero included, so the screen can list them all.
+	Counts map[files.AuditCategory]int `json:"counts"`
+}
+
+// LibraryRepairRequest selects the categories the repair fixes.
+type LibraryRepairRequest struct {
+	Categories []string `json:"categories" example:"broken_link,orphan_torrent"`
+}
+
+// @Summary      Audit the library
+// @Description  Cross-checks downloaded_episodes, the library files on disk (present and still the file the torrent seeds), the torrent client's list and the unknown files and folders in completed_anime_path and its .torrents download folder. Returns the findings by category: broken_link, record_without_torrent, orphan_torrent, duplicate and orphan_file. Changes nothing. The .torrents folder is only checked with the embedded client.
+// @Tags         library
+// @Produce      json
+// @Success      200  {object}  SuccessResponse{data=LibraryAuditResponse}
+// @Failure      405  {object}  SuccessResponse
+// @Failure      409  {object}  SuccessResponse
+// @Failure      500  {object}  SuccessResponse
+// @Failure      503  {object}  SuccessResponse
+// @Router       /library/audit [get]
+func handleLibraryAudit(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET method is allowed")
+			return
+		}
+		if !libraryAuditReady(server, w) {
+			return
+		}
+
+		findings, err := daemon.AuditLibrary(server.FileManager, server.Torrents, server.Librarian)
+		if err != nil {
+			jsonAuditError(w, err)
+			return
+		}
+
+		response := LibraryAuditResponse{Findings: []files.AuditFinding{}, Counts: map[files.AuditCategory]int{}}
+		for _, c := range files.AuditCategories {
+			response.Counts[c] = 0
+		}
+		for _, f := range findings {
+			response.Findings = append(response.Findings, f)
+			response.Counts[f.Category]++
+		}
+		JSONSuccess(w, http.StatusOK, response)
+	}
+}
+
+// @Summary      Repair the library
+// @Description  Audits the library again and fixes the findings of the selected categories: broken_link clears the torrent's library paths and organizes it again (without a second completion webhook); record_without_torrent drops the record and keeps the library files, so the loop downloads the episode again if it is still wanted; orphan_torrent removes the torrent and its data; orphan_file and duplicate delete the file or folder. Findings that could not be fixed are listed with the error.
+// @Tags         library
+// @Accept       json
+// @Produce      json
+// @Param        request  body      LibraryRepairRequest  true  "Categories to repair"
+// @Success      200      {object}  SuccessResponse{data=daemon.LibraryRepairResult}
+// @Failure      400      {object}  SuccessResponse
+// @Failure      405      {object}  SuccessResponse
+// @Failure      409      {object}  SuccessResponse
+// @Failure      500      {object}  SuccessResponse
+// @Failure      503      {object}  SuccessResponse
+// @Router       /library/audit/repair [post]
+func handleLibraryRepair(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST method is allowed")
+			return
+		}
+
+		var req LibraryRepairRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			JSONError(w, http.StatusBadRequest, "INVALID_BODY", "Invalid JSON body")
+			return
+		}
+		if len(req.Categories) == 0 {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Select at least one category to repair")
+			return
+		}
+		categories := make([]files.AuditCategory, 0, len(req.Categories))
+		for _, c := range req.Categories {
+			if !files.IsAuditCategory(c) {
+				JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", fmt.Sprintf("Unknown category %q", c))
+				return
+			}
+			categories = append(categories, files.AuditCategory(c))
+		}
+		if !libraryAuditReady(server, w) {
+			return
+		}
+
+		result, err := daemon.RepairLibrary(server.FileManager, server.Torrents, server.Librarian, server.JobQueue, categories)
+		if err != nil {
+			jsonAuditError(w, err)
+			return
+		}
+		JSONSuccess(w, http.StatusOK, result)
+	}
+}
+
+// libraryAuditReady responde 409 sem biblioteca configurada: sem ela o Ensure da auditoria
+// falharia e o usuario receberia um 500 opaco no lugar da causa.
+func libraryAuditReady(server *Server, w http.ResponseWriter) bool {
+	if server.Librarian == nil || server.Torrents == nil {
+		JSONInternalError(w, errors.New("library audit not initialized"))
+		return false
+	}
+	configs, err := server.FileManager.LoadConfigs()
+	if err != nil {
+		JSONInternalError(w, err)
+		return false
+	}
+	if configs.DownloadPath() == "" {
+		JSONError(w, http.StatusConflict, "LIBRARY_NOT_CONFIGURED", "Set completed_anime_path before auditing the library")
+		return false
+	}
+	return true
+}
+
+func jsonAuditError(w http.ResponseWriter, err error) {
+	if errors.Is(err, daemon.ErrTorrentListUnavailable) {
+		JSONError(w, http.StatusServiceUnavailable, "TORRENT_CLIENT_UNAVAILABLE", err.Error())
+		return
+	}
+	JSONInternalError(w, err)
+}
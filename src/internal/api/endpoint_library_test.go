@@ -0,0 +1,195 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func namingPreviewRequest(t *testing.T, srv *Server, body string) (*httptest.ResponseRecorder, NamingPreviewResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/library/naming/preview", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	handleLibraryNamingPreview(srv)(w, req)
+
+	var resp struct {
+		Data NamingPreviewResponse `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return w, resp.Data
+}
+
+func TestHandleLibraryNamingPreview(t *testing.T) {
+	completed := "/library"
+	fm := &mockFileManager{
+		configs: &files.Config{CompletedAnimePath: completed, RenameFilesForJellyfin: true},
+		episodes: []files.EpisodeStruct{
+			{AnimeID: 1, AnimeName: "Show", EpisodeHash: "a", EpisodeNumber: 1, Meta: &files.AnimeMeta{Year: 2024},
+				LibraryPaths: []string{filepath.Join(completed, "Show", "Show - E01.mkv")}},
+			{AnimeID: 1, AnimeName: "Show", EpisodeHash: "b", EpisodeNumber: 2, Meta: &files.AnimeMeta{Year: 2024},
+				LibraryPaths: []string{filepath.Join(completed, "Show", "Show - E02.mkv")}},
+			{AnimeID: 2, AnimeName: "Pending", EpisodeHash: "c", EpisodeNumber: 1},
+		},
+	}
+	srv := &Server{FileManager: fm}
+
+	t.Run("empty body previews the saved config", func(t *testing.T) {
+		w, resp := namingPreviewRequest(t, srv, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		if resp.Total != 2 || resp.Changed != 0 || len(resp.Items) != 2 {
+			t.Errorf("preview = %+v, want 2 files and nothing changed", resp)
+		}
+		if len(resp.Tokens) != len(files.NamingTokens) {
+			t.Errorf("tokens = %v", resp.Tokens)
+		}
+	})
+
+	t.Run("templates in the body override the config", func(t *testing.T) {
+		w, resp := namingPreviewRequest(t, srv, `{"library_folder_template":"{title} ({year})","limit":1}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		if resp.Total != 2 || resp.Changed != 2 || len(resp.Items) != 1 {
+			t.Fatalf("preview = %+v, want 2 changed and 1 item", resp)
+		}
+		if want := filepath.Join(completed, "Show (2024)", "Show - E01.mkv"); resp.Items[0].To != want {
+			t.Errorf("to = %q, want %q", resp.Items[0].To, want)
+		}
+		if fm.configs.LibraryFolderTemplate != "" {
+			t.Error("preview must not save the config")
+		}
+	})
+
+	t.Run("season folders use the resolved series", func(t *testing.T) {
+		fm.episodes[1].Meta = &files.AnimeMeta{Year: 2024, Show: &files.ShowMeta{ID: 10, Title: "Franchise", Season: 2}}
+		defer func() { fm.episodes[1].Meta = &files.AnimeMeta{Year: 2024} }()
+
+		w, resp := namingPreviewRequest(t, srv, `{"library_season_folders":true}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		if resp.Changed != 1 || len(resp.Items) != 2 {
+			t.Fatalf("preview = %+v, want only the resolved record to move", resp)
+		}
+		if want := filepath.Join(completed, "Franchise", "Season 02", "Franchise - E02.mkv"); resp.Items[0].To != want {
+			t.Errorf("to = %q, want %q", resp.Items[0].To, want)
+		}
+	})
+
+	t.Run("invalid template returns 400", func(t *testing.T) {
+		w, _ := namingPreviewRequest(t, srv, `{"library_file_template":"{title}"}`)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("GET returns 405", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/library/naming/preview", nil)
+		w := httptest.NewRecorder()
+		handleLibraryNamingPreview(srv)(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("status = %d, want 405", w.Code)
+		}
+	})
+}
+
+func TestHandleLibraryLinkProbe(t *testing.T) {
+	fm := &mockFileManager{configs: &files.Config{CompletedAnimePath: "/library", LibraryLinkMode: "copy"}}
+	srv := &Server{FileManager: fm, Librarian: &stubLibrarian{supported: []files.LinkMode{files.LinkSymlink, files.LinkCopy}}}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/library/link-probe", bytes.NewBufferString(""))
+	w := httptest.NewRecorder()
+	handleLibraryLinkProbe(srv)(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data LinkProbeResponse `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Data.CompletedAnimePath != "/library" || len(resp.Data.Supported) != 2 || resp.Data.Current != files.LinkCopy || len(resp.Data.Modes) != 4 {
+		t.Errorf("response = %+v", resp.Data)
+	}
+}
+
+func TestHandleLibraryAudit(t *testing.T) {
+	completed := t.TempDir()
+	fm := &mockFileManager{
+		configs:  &files.Config{CompletedAnimePath: completed},
+		episodes: []files.EpisodeStruct{{AnimeID: 1, AnimeName: "Show", EpisodeNumber: 1, EpisodeHash: "gone"}},
+	}
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted("orphan", filepath.Join(completed, ".torrents", "orphan"))
+	srv := &Server{FileManager: fm, Torrents: backend, Librarian: files.NewLibrarian(files.NewOSFileSystem())}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/library/audit", nil)
+	w := httptest.NewRecorder()
+	handleLibraryAudit(srv)(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data LibraryAuditResponse `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Data.Findings) != 2 || resp.Data.Counts[files.AuditRecordWithoutTorrent] != 1 ||
+		resp.Data.Counts[files.AuditOrphanTorrent] != 1 || len(resp.Data.Counts) != len(files.AuditCategories) {
+		t.Errorf("response = %+v", resp.Data)
+	}
+
+	// Sem biblioteca configurada: 409 com a causa, nao um 500 do Ensure.
+	fm.configs.CompletedAnimePath = ""
+	w = httptest.NewRecorder()
+	handleLibraryAudit(srv)(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("status without library = %d, want 409", w.Code)
+	}
+}
+
+func TestHandleLibraryRepair(t *testing.T) {
+	completed := t.TempDir()
+	fm := &mockFileManager{configs: &files.Config{CompletedAnimePath: completed}}
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted("orphan", filepath.Join(completed, ".torrents", "orphan"))
+	srv := &Server{FileManager: fm, Torrents: backend, Librarian: files.NewLibrarian(files.NewOSFileSystem())}
+
+	for body, want := range map[string]int{
+		`{}`:                             http.StatusBadRequest,
+		`{"categories":["nope"]}`:        http.StatusBadRequest,
+		`not json`:                       http.StatusBadRequest,
+		`{"categories":["orphan_file"]}`: http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		handleLibraryRepair(srv)(w, httptest.NewRequest(http.MethodPost, "/api/v1/library/audit/repair", bytes.NewBufferString(body)))
+		if w.Code != want {
+			t.Errorf("%s: status = %d, want %d: %s", body, w.Code, want, w.Body.String())
+		}
+	}
+	if _, ok := backend.Get("orphan"); !ok {
+		t.Fatal("orphan torrent was removed without orphan_torrent selected")
+	}
+
+	w := httptest.NewRecorder()
+	handleLibraryRepair(srv)(w, httptest.NewRequest(http.MethodPost, "/api/v1/library/audit/repair", bytes.NewBufferString(`{"categories":["orphan_torrent"]}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if _, ok := backend.Get("orphan"); ok {
+		t.Error("orphan torrent should be removed")
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+type ListChangeResponse struct {
+	At time.Time `json:"at"`
+	// Account e o usuario da AniList, ou "myanimelist:usuario"/"kitsu:usuario".
+	Account string `json:"account" example:"user1"`
+	MediaID int    `json:"media_id" example:"21"`
+	Title   string `json:"title" example:"One Piece"`
+	Kind    string `json:"kind" enums:"added,removed,status_changed,progress_changed"`
+	// OldStatus vem vazio em "added", NewStatus em "removed".
+	OldStatus   string `json:"old_status,omitempty" example:"CURRENT"`
+	NewStatus   string `json:"new_status,omitempty" example:"DROPPED"`
+	OldProgress int    `json:"old_progress" example:"5"`
+	NewProgress int    `json:"new_progress" example:"6"`
+}
+
+// @Summary      List change feed
+// @Description  What changed in each account's list between verification passes (added, removed, status or progress changed), newest first. Kept up to the last 500 changes
+// @Tags         list-changes
+// @Produce      json
+// @Param        limit    query     int     false  "Maximum number of changes (default: 100, max: 500)"
+// @Param        account  query     string  false  "Only this account (AniList username, or myanimelist:user / kitsu:user)"
+// @Success      200      {object}  SuccessResponse{data=[]ListChangeResponse}
+// @Failure      400      {object}  SuccessResponse
+// @Failure      405      {object}  SuccessResponse
+// @Failure      500      {object}  SuccessResponse
+// @Router       /list-changes [get]
+func handleListChanges(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET method is allowed")
+			return
+		}
+
+		limit := 100
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				JSONError(w, http.StatusBadRequest, "INVALID_PARAMETER", "limit must be a positive integer")
+				return
+			}
+			limit = min(n, 500)
+		}
+		account := r.URL.Query().Get("account")
+
+		changes, err := server.FileManager.LoadListChanges()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load list changes")
+			JSONInternalError(w, err)
+			return
+		}
+
+		// O arquivo guarda o mais antigo primeiro; o feed mostra o mais novo.
+		resp := make([]ListChangeResponse, 0, min(limit, len(changes)))
+		for i := len(changes) - 1; i >= 0 && len(resp) < limit; i-- {
+			c := changes[i]
+			if account != "" && c.Account != account {
+				continue
+			}
+			resp = append(resp, ListChangeResponse{
+				At:          c.At,
+				Account:     c.Account,
+				MediaID:     c.MediaID,
+				Title:       c.Title,
+				Kind:        c.Kind,
+				OldStatus:   c.OldStatus,
+				NewStatus:   c.NewStatus,
+				OldProgress: c.OldProgress,
+				NewProgress: c.NewProgress,
+			})
+		}
+
+		JSONSuccess(w, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"AutoAnimeDownloader/src/internal/files"
+)
+
+// O arquivo vem do mais antigo para o mais novo; o feed inverte, filtra e corta.
+func TestListChanges_NewestFirstFiltered(t *testing.T) {
+	fm := &mockFileManager{listChanges: []files.ListChange{
+		{Account: "user1", MediaID: 1, Kind: files.ListChangeAdded},
+		{Account: "myanimelist:user2", MediaID: 2, Kind: files.ListChangeRemoved},
+		{Account: "user1", MediaID: 3, Kind: files.ListChangeStatus},
+		{Account: "user1", MediaID: 4, Kind: files.ListChangeProgress},
+	}}
+	get := func(query string) []ListChangeResponse {
+		rec := httptest.NewRecorder()
+		handleListChanges(&Server{FileManager: fm})(rec, httptest.NewRequest(http.MethodGet, "/api/v1/list-changes"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: quero 200, veio %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Data []ListChangeResponse `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("resposta invalida: %v", err)
+		}
+		return resp.Data
+	}
+
+	if got := get(""); len(got) != 4 || got[0].MediaID != 4 || got[3].MediaID != 1 {
+		t.Errorf("quero o mais novo primeiro, veio %+v", got)
+	}
+	if got := get("?account=user1&limit=2"); len(got) != 2 || got[0].MediaID != 4 || got[1].MediaID != 3 {
+		t.Errorf("filtro por conta com limite, veio %+v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	handleListChanges(&Server{FileManager: fm})(rec, httptest.NewRequest(http.MethodGet, "/api/v1/list-changes?limit=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("limit=0: quero 400, veio %d", rec.Code)
+	}
+}
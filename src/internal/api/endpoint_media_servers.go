@@ -0,0 +1,47 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/mediaserver"
+	"net/http"
+)
+
+// @Summary      Test a media server connection
+// @Description  Calls a configured Jellyfin, Emby or Plex server with its token and returns the name and version it reports
+// @Tags         media-servers
+// @Produce      json
+// @Param        name  path      string  true  "Media server name"
+// @Success      200   {object}  SuccessResponse{data=mediaserver.Info}
+// @Failure      404   {object}  SuccessResponse
+// @Failure      405   {object}  SuccessResponse
+// @Failure      500   {object}  SuccessResponse
+// @Failure      502   {object}  SuccessResponse
+// @Router       /media-servers/{name}/test [post]
+func handleMediaServerTest(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST is allowed")
+			return
+		}
+		name := r.PathValue("name")
+		cfg, err := server.FileManager.LoadConfigs()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load configs for media server test")
+			JSONInternalError(w, err)
+			return
+		}
+		for _, s := range cfg.MediaServers {
+			if s.Name != name {
+				continue
+			}
+			info, err := mediaserver.Test(s)
+			if err != nil {
+				JSONError(w, http.StatusBadGateway, "MEDIA_SERVER_UNREACHABLE", err.Error())
+				return
+			}
+			JSONSuccess(w, http.StatusOK, info)
+			return
+		}
+		JSONError(w, http.StatusNotFound, "MEDIA_SERVER_NOT_FOUND", "media server \""+name+"\" not found")
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleMediaServerTest(t *testing.T) {
+	jellyfin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/System/Info" || r.Header.Get("Authorization") != `MediaBrowser Token="good"` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"ServerName":"living-room","Version":"10.10.3"}`))
+	}))
+	defer jellyfin.Close()
+
+	fm := &mockFileManager{configs: &files.Config{
+		MediaServers: []files.MediaServer{
+			{Name: "home", Type: "jellyfin", URL: jellyfin.URL, Token: "good"},
+			{Name: "stale", Type: "jellyfin", URL: jellyfin.URL, Token: "revoked"},
+		},
+	}}
+	handler := handleMediaServerTest(&Server{FileManager: fm})
+
+	cases := []struct {
+		name, method string
+		status       int
+		body         string
+	}{
+		{"home", http.MethodPost, http.StatusOK, "living-room"},
+		{"stale", http.MethodPost, http.StatusBadGateway, "MEDIA_SERVER_UNREACHABLE"},
+		{"missing", http.MethodPost, http.StatusNotFound, "MEDIA_SERVER_NOT_FOUND"},
+		{"home", http.MethodGet, http.StatusMethodNotAllowed, ""},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, "/api/v1/media-servers/"+c.name+"/test", nil)
+		req.SetPathValue("name", c.name)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		if rec.Code != c.status {
+			t.Errorf("%s %s: expected %d, got %d — body: %s", c.method, c.name, c.status, rec.Code, rec.Body.String())
+		}
+		if !strings.Contains(rec.Body.String(), c.body) {
+			t.Errorf("%s %s: body %s should contain %q", c.method, c.name, rec.Body.String(), c.body)
+		}
+	}
+}
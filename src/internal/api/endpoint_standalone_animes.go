@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"AutoAnimeDownloader/src/internal/anilist"
 	"AutoAnimeDownloader/src/internal/daemon"
@@ -25,10 +26,10 @@ type StandaloneAnimeAddResponse struct {
 // blockReasonToErrorCode traduz o motivo de bloqueio no codigo do 409. Sao os mesmos quatro
 // motivos que a busca devolve em block_reason.
 var blockReasonToErrorCode = map[string]string{
-	blockReasonBlacklist:  "ALREADY_BLACKLISTED",
-	blockReasonStandalone: "ALREADY_STANDALONE",
-	blockReasonTracked:    "ALREADY_TRACKED",
-	blockReasonDownloaded: "ALREADY_DOWNLOADED",
+	daemon.BlockReasonBlacklist:  "ALREADY_BLACKLISTED",
+	daemon.BlockReasonStandalone: "ALREADY_STANDALONE",
+	daemon.BlockReasonTracked:    "ALREADY_TRACKED",
+	daemon.BlockReasonDownloaded: "ALREADY_DOWNLOADED",
 }
 
 // @Summary      Track an anime that is not in any AniList list
@@ -73,7 +74,7 @@ func handleStandaloneAnimeAdd(server *Server) http.HandlerFunc {
 		}
 
 		// E tambem o que valida que o id existe e e ANIME, e de onde sai o total de episodios
-		// que blockReason precisa.
+		// que BlockReason precisa.
 		media, err := anilist.GetMediaByID(body.MediaID)
 		if err != nil {
 			logger.Logger.Error().Err(err).Int("media_id", body.MediaID).Msg("Failed to fetch media from AniList")
@@ -95,7 +96,7 @@ func handleStandaloneAnimeAdd(server *Server) http.HandlerFunc {
 			JSONInternalError(w, err)
 			return
 		}
-		if reason := guard.blockReason(body.MediaID, totalEpisodes); reason != "" {
+		if reason := guard.BlockReason(body.MediaID, totalEpisodes); reason != "" {
 			JSONError(w, http.StatusConflict, blockReasonToErrorCode[reason], standaloneBlockMessage(reason))
 			return
 		}
@@ -121,13 +122,13 @@ func handleStandaloneAnimeAdd(server *Server) http.HandlerFunc {
 
 func standaloneBlockMessage(reason string) string {
 	switch reason {
-	case blockReasonBlacklist:
+	case daemon.BlockReasonBlacklist:
 		return "This anime is in an excluded AniList list"
-	case blockReasonStandalone:
+	case daemon.BlockReasonStandalone:
 		return "This anime is already tracked as standalone"
-	case blockReasonTracked:
+	case daemon.BlockReasonTracked:
 		return "This anime is already in one of your AniList lists"
-	case blockReasonDownloaded:
+	case daemon.BlockReasonDownloaded:
 		return "This anime is already fully downloaded"
 	}
 	return "This anime cannot be added"
@@ -211,3 +212,86 @@ func handleStandaloneAnimeRemove(server *Server) http.HandlerFunc {
 		JSONSuccess(w, http.StatusOK, nil)
 	}
 }
+
+// StandaloneAnimeListRequest picks the account and status for AddToList.
+type StandaloneAnimeListRequest struct {
+	Username string `json:"username" example:"someone"`
+	// Status is the list status of the new entry; "" is CURRENT.
+	Status string `json:"status" example:"CURRENT"`
+}
+
+// @Summary      Add a standalone anime to an AniList list
+// @Description  Adds the standalone anime to a logged-in account's AniList list with its saved progress. The standalone record stays until a pass sees the anime in a list the daemon processes (download_statuses), and is dropped then
+// @Tags         standalone
+// @Accept       json
+// @Produce      json
+// @Param        id   path int true "AniList media ID"
+// @Param        body body StandaloneAnimeListRequest true "Account and status"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  SuccessResponse
+// @Failure      404  {object}  SuccessResponse
+// @Failure      405  {object}  SuccessResponse
+// @Failure      409  {object}  SuccessResponse
+// @Failure      500  {object}  SuccessResponse
+// @Failure      502  {object}  SuccessResponse
+// @Router       /standalone-animes/{id}/list [post]
+func handleStandaloneAnimeAddToList(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST method is allowed")
+			return
+		}
+
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id <= 0 {
+			JSONError(w, http.StatusBadRequest, "INVALID_ID", "Invalid anime ID")
+			return
+		}
+		var body StandaloneAnimeListRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Username == "" {
+			JSONError(w, http.StatusBadRequest, "INVALID_BODY", "Body must be {\"username\": \"...\", \"status\": \"CURRENT\"}")
+			return
+		}
+		status := anilist.MediaListStatus(body.Status)
+		switch status {
+		case "":
+			status = anilist.MediaListStatusCurrent
+		case anilist.MediaListStatusCurrent, anilist.MediaListStatusPlanning, anilist.MediaListStatusCompleted,
+			anilist.MediaListStatusPaused, anilist.MediaListStatusDropped, anilist.MediaListStatusRepeating:
+		default:
+			JSONError(w, http.StatusBadRequest, "INVALID_STATUS", "Unknown AniList list status")
+			return
+		}
+
+		if !loadStandaloneSet(server.FileManager)[id] {
+			JSONError(w, http.StatusNotFound, "ANIME_NOT_FOUND", "Not a standalone anime")
+			return
+		}
+		tokens, err := server.FileManager.LoadAnilistTokens()
+		if err != nil {
+			logger.Logger.Error().Err(err).Msg("Failed to load AniList tokens")
+			JSONInternalError(w, err)
+			return
+		}
+		tok, ok := tokens[body.Username]
+		if !ok || !tok.Usable(time.Now()) {
+			JSONError(w, http.StatusConflict, "ANILIST_NOT_LOGGED_IN", "Log in to this AniList account first")
+			return
+		}
+
+		// O progresso vai junto: e o que o usuario ja marcou aqui, e a entrada nova nao pode
+		// nascer em 0 e fazer o passe rebaixar o que ja foi visto.
+		progress := 0
+		if s, err := server.FileManager.LoadAnimeSettings(id); err == nil && s != nil {
+			progress = s.Progress
+		}
+		if err := anilist.AddToList(tok.AccessToken, id, status, progress); err != nil {
+			logger.Logger.Warn().Err(err).Int("media_id", id).Str("username", body.Username).Msg("Failed to add the standalone anime to AniList")
+			JSONError(w, http.StatusBadGateway, "ANILIST_WRITE_FAILED", err.Error())
+			return
+		}
+		logger.Logger.Info().Int("media_id", id).Str("username", body.Username).Str("status", string(status)).
+			Msg("Standalone anime added to the AniList list")
+		JSONSuccess(w, http.StatusOK, map[string]string{"message": "Added to the AniList list"})
+	}
+}
@@ -307,8 +307,8 @@ func TestAniListSearch_FillsBlockReason(t *testing.T) {
 	if len(resp.Data) != 2 {
 		t.Fatalf("quero 2 resultados, veio %d", len(resp.Data))
 	}
-	if resp.Data[0].BlockReason != blockReasonStandalone {
-		t.Fatalf("quero %q, veio %q", blockReasonStandalone, resp.Data[0].BlockReason)
+	if resp.Data[0].BlockReason != daemon.BlockReasonStandalone {
+		t.Fatalf("quero %q, veio %q", daemon.BlockReasonStandalone, resp.Data[0].BlockReason)
 	}
 	if resp.Data[1].BlockReason != "" {
 		t.Fatalf("resultado adicionavel precisa vir com block_reason vazio, veio %q", resp.Data[1].BlockReason)
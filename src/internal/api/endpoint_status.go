@@ -18,6 +18,13 @@ type StatusResponse struct {
 	// PAROU de adicionar torrents. Calculado no servidor de proposito: um limiar duplicado no
 	// frontend acabaria discordando do que o daemon esta fazendo.
 	DiskLow bool `json:"disk_low" example:"false"`
+	// DownloadsPaused marca uma pausa global (POST /torrents/pause-all) em vigor;
+	// DownloadsPausedUntil e o prazo dela, ausente quando vale ate o resume-all.
+	DownloadsPaused      bool       `json:"downloads_paused" example:"false"`
+	DownloadsPausedUntil *time.Time `json:"downloads_paused_until,omitempty"`
+	// DataCapReached marca o teto mensal de dados atingido: downloads e seeding parados ate a
+	// virada do ciclo (GET /data-usage tem os numeros e a data).
+	DataCapReached bool `json:"data_cap_reached" example:"false"`
 }
 
 // @Summary      Get daemon status
@@ -56,6 +63,14 @@ func handleStatus(server *Server) http.HandlerFunc {
 			DiskFree:  diskFree,
 			DiskLow:   diskLow,
 		}
+		if server.Torrents != nil {
+			paused, until := server.Torrents.PausedAll()
+			response.DownloadsPaused = paused
+			if paused && !until.IsZero() {
+				response.DownloadsPausedUntil = &until
+			}
+			response.DataCapReached = server.Torrents.DataCapReached()
+		}
 
 		JSONSuccess(w, http.StatusOK, response)
 	}
@@ -6,10 +6,13 @@ import (
 	"AutoAnimeDownloader/src/internal/logger"
 	"AutoAnimeDownloader/src/internal/torrents"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"sort"
 	"strconv"
+	"time"
 )
 
 // TorrentResponse is one row of the downloads screen: a torrent's live progress joined with
@@ -157,6 +160,15 @@ func buildTorrentResponse(t torrents.TorrentInfo, eps []files.EpisodeStruct) Tor
 // backend was set then. Resolving it per request keeps the handler honest if the field is
 // assigned later (as tests do).
 func torrentAction(server *Server, action func(s *Server, hash string) error) http.HandlerFunc {
+	return torrentActionWithResult(server, func(s *Server, hash string) (any, error) {
+		return nil, action(s, hash)
+	})
+}
+
+// torrentActionWithResult is torrentAction for the controls that answer with data — the
+// tracker actions return the tracker list, so the effect is visible in the same response, and
+// recheck returns the piece counts.
+func torrentActionWithResult(server *Server, action func(s *Server, hash string) (any, error)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST method is allowed")
@@ -176,12 +188,13 @@ func torrentAction(server *Server, action func(s *Server, hash string) error) ht
 			return
 		}
 
-		if err := action(server, hash); err != nil {
+		data, err := action(server, hash)
+		if err != nil {
 			JSONInternalError(w, err)
 			return
 		}
 
-		JSONSuccess(w, http.StatusOK, nil)
+		JSONSuccess(w, http.StatusOK, data)
 	}
 }
 
@@ -274,19 +287,286 @@ func handleTorrentsPrioritize(server *Server) http.HandlerFunc {
 	}
 }
 
+// PauseAllRequest is the optional body of the pause-all endpoint.
+type PauseAllRequest struct {
+	// DurationMinutes > 0 resumes everything on its own after that long; 0 (or no body)
+	// pauses until resume-all.
+	DurationMinutes int `json:"duration_minutes" example:"120"`
+}
+
+// PauseAllResponse is the global pause state after the action.
+type PauseAllResponse struct {
+	Paused bool `json:"paused" example:"true"`
+	// Until is null when the pause lasts until resume-all.
+	Until *time.Time `json:"until"`
+}
+
+func pauseAllResponse(b torrents.TorrentBackend) PauseAllResponse {
+	paused, until := b.PausedAll()
+	resp := PauseAllResponse{Paused: paused}
+	if paused && !until.IsZero() {
+		resp.Until = &until
+	}
+	return resp
+}
+
+// @Summary      Pause every torrent
+// @Description  Stops every torrent, downloading and seeding, until resume-all — or for duration_minutes, after which everything resumes on its own. Torrents the user had already paused one by one stay paused after the resume. Survives a daemon restart. Calling it again while paused replaces the deadline.
+// @Tags         torrents
+// @Accept       json
+// @Produce      json
+// @Param        request  body      PauseAllRequest  false  "Optional duration"
+// @Success      200      {object}  SuccessResponse{data=PauseAllResponse}
+// @Failure      400      {object}  SuccessResponse
+// @Failure      405      {object}  SuccessResponse
+// @Router       /torrents/pause-all [post]
+func handleTorrentsPauseAll(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST method is allowed")
+			return
+		}
+
+		// The body is optional: "pause everything" is the common case, and the tray and the
+		// CLI send nothing for it.
+		var req PauseAllRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			JSONError(w, http.StatusBadRequest, "INVALID_BODY", "Invalid JSON body")
+			return
+		}
+		if req.DurationMinutes < 0 {
+			JSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Duration must be non-negative")
+			return
+		}
+
+		server.Torrents.PauseAll(time.Duration(req.DurationMinutes) * time.Minute)
+		JSONSuccess(w, http.StatusOK, pauseAllResponse(server.Torrents))
+	}
+}
+
+// @Summary      Resume every torrent
+// @Description  Ends a pause-all: downloads start again within max_concurrent_downloads and the seeders pause-all stopped resume. Torrents the user had paused one by one stay paused. No-op when nothing is paused.
+// @Tags         torrents
+// @Produce      json
+// @Success      200  {object}  SuccessResponse{data=PauseAllResponse}
+// @Failure      405  {object}  SuccessResponse
+// @Router       /torrents/resume-all [post]
+func handleTorrentsResumeAll(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only POST method is allowed")
+			return
+		}
+		server.Torrents.ResumeAll()
+		JSONSuccess(w, http.StatusOK, pauseAllResponse(server.Torrents))
+	}
+}
+
+// TrackerResponse is one tracker of a torrent with its last announce result.
+type TrackerResponse struct {
+	URL string `json:"url" example:"udp://tracker.opentrackr.org:1337/announce"`
+	// Status is one of not_contacted, contacting, working, not_working (or unknown).
+	Status   string `json:"status" example:"working"`
+	Seeders  int    `json:"seeders" example:"12"`
+	Leechers int    `json:"leechers" example:"3"`
+	// Error is the last announce error; empty when the last announce succeeded.
+	Error string `json:"error,omitempty" example:"connection refused"`
+	// LastAnnounce and NextAnnounce are null until the first announce.
+	LastAnnounce *time.Time `json:"last_announce"`
+	NextAnnounce *time.Time `json:"next_announce"`
+}
+
+// TrackersResponse is the answer of the tracker actions (announce, add trackers).
+type TrackersResponse struct {
+	// Added is how many extra trackers were new to the torrent. Always 0 for announce.
+	Added    int               `json:"added" example:"20"`
+	Trackers []TrackerResponse `json:"trackers"`
+}
+
+// TorrentDetailResponse is a torrent row plus what is too heavy for the 2s list poll: the
+// per-tracker announce results.
+type TorrentDetailResponse struct {
+	TorrentResponse
+	Trackers []TrackerResponse `json:"trackers"`
+}
+
+func buildTrackerResponses(trackers []torrents.TrackerInfo) []TrackerResponse {
+	out := make([]TrackerResponse, 0, len(trackers))
+	for _, tr := range trackers {
+		resp := TrackerResponse{
+			URL:      tr.URL,
+			Status:   tr.Status,
+			Seeders:  tr.Seeders,
+			Leechers: tr.Leechers,
+			Error:    tr.Error,
+		}
+		if !tr.LastAnnounce.IsZero() {
+			t := tr.LastAnnounce
+			resp.LastAnnounce = &t
+		}
+		if !tr.NextAnnounce.IsZero() {
+			t := tr.NextAnnounce
+			resp.NextAnnounce = &t
+		}
+		out = append(out, resp)
+	}
+	return out
+}
+
+// torrentTrackers reads the tracker list for a response. It is best-effort on purpose: the
+// action it follows already happened, and a 500 would tell the user it had not.
+func torrentTrackers(s *Server, hash string) []TrackerResponse {
+	trackers, err := s.Torrents.Trackers(hash)
+	if err != nil {
+		logger.Logger.Warn().Err(err).Str("hash", hash).Msg("Failed to read torrent trackers")
+		return []TrackerResponse{}
+	}
+	return buildTrackerResponses(trackers)
+}
+
 // @Summary      Force a torrent re-announce
-// @Description  Re-announces the torrent to all trackers and DHT — the way out of "stuck at 0 peers". It does not override the trackers' minimum interval, so repeated calls have no extra effect.
+// @Description  Re-announces the torrent to all trackers and DHT — the way out of "stuck at 0 peers". It does not override the trackers' minimum interval, so repeated calls have no extra effect. Answers with the trackers as they stand when the announce is queued: the announce itself is asynchronous, so the fresh results show up a few seconds later in GET /torrents/{hash}.
 // @Tags         torrents
 // @Accept       json
 // @Produce      json
 // @Param        hash  path      string  true  "Torrent info hash"
-// @Success      200   {object}  SuccessResponse
+// @Success      200   {object}  SuccessResponse{data=TrackersResponse}
 // @Failure      400   {object}  SuccessResponse
 // @Failure      404   {object}  SuccessResponse
 // @Failure      405   {object}  SuccessResponse
 // @Router       /torrents/{hash}/announce [post]
 func handleTorrentAnnounce(server *Server) http.HandlerFunc {
-	return torrentAction(server, func(s *Server, hash string) error { return s.Torrents.Announce(hash) })
+	return torrentActionWithResult(server, func(s *Server, hash string) (any, error) {
+		if err := s.Torrents.Announce(hash); err != nil {
+			return nil, err
+		}
+		return TrackersResponse{Trackers: torrentTrackers(s, hash)}, nil
+	})
+}
+
+// @Summary      Add the extra trackers to a torrent
+// @Description  Adds the configured extra trackers (extra_trackers plus the list downloaded from trackers_list_url) to a torrent already in the session, skipping the ones it already has, and re-announces when any was added. New magnets get them automatically; this is for torrents added before the list was configured. Answers with how many were added and the resulting tracker list.
+// @Tags         torrents
+// @Accept       json
+// @Produce      json
+// @Param        hash  path      string  true  "Torrent info hash"
+// @Success      200   {object}  SuccessResponse{data=TrackersResponse}
+// @Failure      400   {object}  SuccessResponse
+// @Failure      404   {object}  SuccessResponse
+// @Failure      405   {object}  SuccessResponse
+// @Failure      500   {object}  SuccessResponse
+// @Router       /torrents/{hash}/trackers [post]
+func handleTorrentAddTrackers(server *Server) http.HandlerFunc {
+	return torrentActionWithResult(server, func(s *Server, hash string) (any, error) {
+		added, err := s.Torrents.AddExtraTrackers(hash)
+		if err != nil {
+			return nil, err
+		}
+		return TrackersResponse{Added: added, Trackers: torrentTrackers(s, hash)}, nil
+	})
+}
+
+// RecheckResponse is the outcome of a data verification.
+type RecheckResponse struct {
+	PiecesTotal uint32 `json:"pieces_total" example:"1200"`
+	PiecesHave  uint32 `json:"pieces_have" example:"1198"`
+	// PiecesFailed is how many pieces the torrent had and no longer has: the ones whose bytes
+	// on disk did not match their hash. They are being downloaded again.
+	PiecesFailed uint32 `json:"pieces_failed" example:"2"`
+	// Completed reports whether every piece passed.
+	Completed bool `json:"completed" example:"false"`
+}
+
+// @Summary      Recheck a torrent's data
+// @Description  Re-verifies every piece on disk against its hash and answers when that is done — seconds for an episode, minutes for a large batch. Failed pieces are downloaded again: a torrent that was seeding goes to the front of the download queue, one the user paused stays paused, and one still downloading keeps its place. Fails with 500 when the torrent has no metadata yet.
+// @Tags         torrents
+// @Accept       json
+// @Produce      json
+// @Param        hash  path      string  true  "Torrent info hash"
+// @Success      200   {object}  SuccessResponse{data=RecheckResponse}
+// @Failure      400   {object}  SuccessResponse
+// @Failure      404   {object}  SuccessResponse
+// @Failure      405   {object}  SuccessResponse
+// @Failure      500   {object}  SuccessResponse
+// @Router       /torrents/{hash}/recheck [post]
+func handleTorrentRecheck(server *Server) http.HandlerFunc {
+	return torrentActionWithResult(server, func(s *Server, hash string) (any, error) {
+		res, err := s.Torrents.Recheck(hash)
+		if err != nil {
+			return nil, err
+		}
+		return RecheckResponse{
+			PiecesTotal:  res.PiecesTotal,
+			PiecesHave:   res.PiecesHave,
+			PiecesFailed: res.PiecesFailed,
+			Completed:    res.Completed,
+		}, nil
+	})
+}
+
+// handleTorrent serves the two methods of "/api/v1/torrents/{hash}": the detail and the
+// delete. One pattern, dispatched here, for the reason given in SetupRoutes.
+func handleTorrent(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleTorrentDetail(server)(w, r)
+		case http.MethodDelete:
+			handleTorrentDelete(server)(w, r)
+		default:
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET and DELETE methods are allowed")
+		}
+	}
+}
+
+// @Summary      Get a torrent
+// @Description  Returns one torrent — the same row as GET /torrents — plus its trackers with the last announce result of each (status, seeders, leechers, error). This is where the effect of the extra trackers shows: a dead tracker stays not_working, a live one reports seeders.
+// @Tags         torrents
+// @Accept       json
+// @Produce      json
+// @Param        hash  path      string  true  "Torrent info hash"
+// @Success      200   {object}  SuccessResponse{data=TorrentDetailResponse}
+// @Failure      400   {object}  SuccessResponse
+// @Failure      404   {object}  SuccessResponse
+// @Failure      405   {object}  SuccessResponse
+// @Router       /torrents/{hash} [get]
+func handleTorrentDetail(server *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			JSONError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET method is allowed")
+			return
+		}
+
+		hash := r.PathValue("hash")
+		if hash == "" {
+			JSONError(w, http.StatusBadRequest, "INVALID_HASH", "Torrent hash is required")
+			return
+		}
+
+		t, ok := server.Torrents.Get(hash)
+		if !ok {
+			JSONError(w, http.StatusNotFound, "TORRENT_NOT_FOUND", "Torrent not found")
+			return
+		}
+
+		// Same best-effort join as the list.
+		var eps []files.EpisodeStruct
+		episodes, err := server.FileManager.LoadSavedEpisodes()
+		if err != nil {
+			logger.Logger.Warn().Err(err).Msg("Failed to load saved episodes for torrent detail; returning the torrent without anime metadata")
+		} else {
+			for _, ep := range episodes {
+				if ep.EpisodeHash == hash {
+					eps = append(eps, ep)
+				}
+			}
+		}
+
+		JSONSuccess(w, http.StatusOK, TorrentDetailResponse{
+			TorrentResponse: buildTorrentResponse(t, eps),
+			Trackers:        torrentTrackers(server, hash),
+		})
+	}
 }
 
 // parseBoolQueryParam reads a boolean query parameter, defaulting to false when absent
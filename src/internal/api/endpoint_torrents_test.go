@@ -277,6 +277,67 @@ func TestHandleTorrentAnnounce(t *testing.T) {
 	if calls := backend.AnnounceCalls(); len(calls) != 1 || calls[0] != hashA {
 		t.Errorf("AnnounceCalls() = %v, want [%s]", calls, hashA)
 	}
+	var response struct {
+		Data TrackersResponse `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Data.Trackers == nil {
+		t.Error("Expected the tracker list in the response, got null")
+	}
+}
+
+func TestHandleTorrentAddTrackers(t *testing.T) {
+	server, backend := newTorrentActionServer(t)
+	backend.SetExtraTrackers([]string{"udp://a.tracker:1337/announce", "udp://b.tracker:1337/announce"})
+
+	w := postTorrentAction(handleTorrentAddTrackers(server), hashA)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response struct {
+		Data TrackersResponse `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Data.Added != 2 || len(response.Data.Trackers) != 2 {
+		t.Errorf("added = %d, trackers = %v; want 2 and 2", response.Data.Added, response.Data.Trackers)
+	}
+
+	// The second call finds both trackers already there.
+	w = postTorrentAction(handleTorrentAddTrackers(server), hashA)
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Data.Added != 0 {
+		t.Errorf("second call added = %d, want 0", response.Data.Added)
+	}
+}
+
+func TestHandleTorrentRecheck(t *testing.T) {
+	server, backend := newTorrentActionServer(t)
+	backend.CorruptPieces[hashA] = 2
+
+	w := postTorrentAction(handleTorrentRecheck(server), hashA)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+	}
+	if calls := backend.RecheckCalls(); len(calls) != 1 || calls[0] != hashA {
+		t.Errorf("RecheckCalls() = %v, want [%s]", calls, hashA)
+	}
+	var response struct {
+		Data RecheckResponse `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Data.PiecesFailed != 2 || response.Data.Completed {
+		t.Errorf("response = %+v, want 2 failed pieces and not completed", response.Data)
+	}
 }
 
 func TestHandleTorrentPrioritize(t *testing.T) {
@@ -363,6 +424,50 @@ func TestHandleTorrentsPrioritizeRejectsAnEmptyList(t *testing.T) {
 	}
 }
 
+func TestHandleTorrentsPauseAllAndResumeAll(t *testing.T) {
+	server, backend := newTorrentActionServer(t)
+
+	// No body: pause until resume-all.
+	w := postPrioritizeBatch(handleTorrentsPauseAll(server), "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if paused, until := backend.PausedAll(); !paused || !until.IsZero() {
+		t.Errorf("PausedAll() = %v, %v; want paused without a deadline", paused, until)
+	}
+
+	w = postPrioritizeBatch(handleTorrentsPauseAll(server), `{"duration_minutes":120}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected %d, got %d", http.StatusOK, w.Code)
+	}
+	var resp struct {
+		Data PauseAllResponse `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !resp.Data.Paused || resp.Data.Until == nil {
+		t.Errorf("response = %+v, want paused with a deadline", resp.Data)
+	}
+
+	if w := postPrioritizeBatch(handleTorrentsResumeAll(server), ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected %d, got %d", http.StatusOK, w.Code)
+	}
+	if paused, _ := backend.PausedAll(); paused {
+		t.Error("resume-all left the backend paused")
+	}
+}
+
+func TestHandleTorrentsPauseAllRejectsNegativeDuration(t *testing.T) {
+	server, _ := newTorrentActionServer(t)
+
+	for _, body := range []string{`{"duration_minutes":-5}`, `not json`} {
+		if w := postPrioritizeBatch(handleTorrentsPauseAll(server), body); w.Code != http.StatusBadRequest {
+			t.Errorf("body %q: expected %d, got %d", body, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
 func TestHandleTorrentActionsUnknownHashReturn404(t *testing.T) {
 	server, _ := newTorrentActionServer(t)
 
@@ -371,6 +476,8 @@ func TestHandleTorrentActionsUnknownHashReturn404(t *testing.T) {
 		"resume":     handleTorrentResume(server),
 		"announce":   handleTorrentAnnounce(server),
 		"prioritize": handleTorrentPrioritize(server),
+		"trackers":   handleTorrentAddTrackers(server),
+		"recheck":    handleTorrentRecheck(server),
 	}
 	for name, handler := range handlers {
 		w := postTorrentAction(handler, "ffffffffffffffffffffffffffffffffffffffff")
@@ -418,7 +525,21 @@ func (l *trackingLibrarian) RemoveFromLibrary(path string) error {
 	l.removedPaths = append(l.removedPaths, path)
 	return nil
 }
-func (l *trackingLibrarian) ProbePath(string) error { return nil }
+func (l *trackingLibrarian) ProbePath(string, files.LinkMode) error { return nil }
+func (l *trackingLibrarian) ProbeLinkModes(string) ([]files.LinkMode, error) {
+	return files.LinkModes, nil
+}
+func (l *trackingLibrarian) ProbeMoviesPath(string, string, files.LinkMode) error { return nil }
+func (l *trackingLibrarian) MoveInLibrary(string, string) error                   { return nil }
+func (l *trackingLibrarian) EnsureShowNFO(string, string, int)                    {}
+func (l *trackingLibrarian) MissingNFOs([]files.LibraryMove) bool                 { return false }
+func (l *trackingLibrarian) WriteNFOs([]files.LibraryMove, *files.NFOInfo)        {}
+func (l *trackingLibrarian) HasArtwork(string) bool                               { return false }
+func (l *trackingLibrarian) SaveArtwork(string, []byte) error                     { return nil }
+func (l *trackingLibrarian) AuditLibrary(files.AuditRequest) ([]files.AuditFinding, error) {
+	return nil, nil
+}
+func (l *trackingLibrarian) RemoveOrphan(string, string) error { return nil }
 
 func deleteTorrentRequest(hash, query string) *http.Request {
 	url := "/api/v1/torrents/" + hash
@@ -581,3 +702,78 @@ func TestHandleTorrentDeleteInvalidKeepDataReturns400(t *testing.T) {
 		t.Errorf("Expected %d, got %d", http.StatusBadRequest, w.Code)
 	}
 }
+
+// --- GET /api/v1/torrents/{hash} ---
+
+func getTorrentRequest(hash string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/torrents/"+hash, nil)
+	req.SetPathValue("hash", hash)
+	return req
+}
+
+func TestHandleTorrentDetailIncludesTrackersAndEpisode(t *testing.T) {
+	backend := torrents.NewFakeBackend()
+	backend.SetExtraTrackers([]string{"udp://a.tracker:1337/announce"})
+	if _, err := backend.Add(magnetA); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	fm := &mockFileManager{episodes: []files.EpisodeStruct{
+		{EpisodeNumber: 3, AnimeID: 42, AnimeName: "My Anime", EpisodeHash: hashA},
+	}}
+	server := &Server{Torrents: backend, FileManager: fm}
+
+	w := httptest.NewRecorder()
+	handleTorrent(server)(w, getTorrentRequest(hashA))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response struct {
+		Data TorrentDetailResponse `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Data.Hash != hashA || response.Data.AnimeID != 42 {
+		t.Errorf("Expected the torrent row joined with its episode, got %+v", response.Data.TorrentResponse)
+	}
+	trackers := response.Data.Trackers
+	if len(trackers) != 1 || trackers[0].URL != "udp://a.tracker:1337/announce" || trackers[0].Status != "not_contacted" {
+		t.Errorf("Trackers = %+v, want the extra tracker, not contacted", trackers)
+	}
+	if trackers[0].LastAnnounce != nil {
+		t.Errorf("LastAnnounce = %v, want null before the first announce", trackers[0].LastAnnounce)
+	}
+}
+
+func TestHandleTorrentDetailUnknownHashReturns404(t *testing.T) {
+	server, _ := newTorrentActionServer(t)
+
+	w := httptest.NewRecorder()
+	handleTorrent(server)(w, getTorrentRequest("ffffffffffffffffffffffffffffffffffffffff"))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestHandleTorrentRoutesByMethod(t *testing.T) {
+	server, backend := newTorrentActionServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/torrents/"+hashA, nil)
+	req.SetPathValue("hash", hashA)
+	w := httptest.NewRecorder()
+	handleTorrent(server)(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: expected %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handleTorrent(server)(w, deleteTorrentRequest(hashA, ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("DELETE: expected %d, got %d (body: %s)", http.StatusOK, w.Code, w.Body.String())
+	}
+	if _, ok := backend.Get(hashA); ok {
+		t.Error("DELETE through handleTorrent did not remove the torrent")
+	}
+}
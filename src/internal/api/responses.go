@@ -55,12 +55,16 @@ func JSONInternalError(w http.ResponseWriter, err error) {
 	JSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 }
 
-// JSONDownloadError responde a falha de um download manual. Disco cheio vira 409 e nao 500: a
-// causa e conhecida e acionavel pelo usuario.
+// JSONDownloadError responde a falha de um download manual. Disco cheio e teto de dados viram
+// 409 e nao 500: a causa e conhecida e acionavel pelo usuario.
 func JSONDownloadError(w http.ResponseWriter, err error, code string) {
 	if errors.Is(err, daemon.ErrInsufficientDiskSpace) {
 		JSONError(w, http.StatusConflict, "INSUFFICIENT_DISK_SPACE", err.Error())
 		return
 	}
+	if errors.Is(err, daemon.ErrDataCapReached) {
+		JSONError(w, http.StatusConflict, "DATA_CAP_REACHED", err.Error())
+		return
+	}
 	JSONError(w, http.StatusInternalServerError, code, err.Error())
 }
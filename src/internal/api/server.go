@@ -37,6 +37,27 @@ type FileManagerInterface interface {
 	LoadStandaloneAnimes() ([]int, error)
 	AddStandaloneAnime(mediaID int) error
 	RemoveStandaloneAnime(mediaID int) error
+	LoadTrackersList() (trackers []string, source string, savedAt time.Time, err error)
+	SaveTrackersList(source string, trackers []string) error
+	LoadIntegrityChecks() (map[string]time.Time, error)
+	SaveIntegrityChecks(checks map[string]time.Time) error
+	LoadDataUsage() (*files.DataUsageLedger, error)
+	SaveDataUsage(ledger *files.DataUsageLedger) error
+	LoadArtworkSources() (map[int]files.ArtworkSource, error)
+	SaveArtworkSources(sources map[int]files.ArtworkSource) error
+	LoadAnilistTokens() (map[string]files.AnilistToken, error)
+	SaveAnilistTokens(tokens map[string]files.AnilistToken) error
+	IDMappingSavedAt() (time.Time, error)
+	LoadIDMapping() ([]byte, time.Time, error)
+	SaveIDMapping(data []byte) error
+	LoadAniListCache() ([]byte, error)
+	SaveAniListCache(data []byte) error
+	LoadTrials() (map[int]files.Trial, error)
+	UpdateTrials(fn func(trials map[int]files.Trial) bool) error
+	LoadListSnapshot() (files.ListSnapshot, error)
+	SaveListSnapshot(snapshot files.ListSnapshot) error
+	LoadListChanges() ([]files.ListChange, error)
+	AppendListChanges(changes []files.ListChange) error
 }
 
 type Server struct {
@@ -52,6 +73,9 @@ type Server struct {
 	mu                 sync.Mutex
 	currentLoopControl *daemon.LoopControl
 
+	// anilistLogins are the AniList code logins waiting for the callback, by state.
+	anilistLogins anilistLogins
+
 	// checks tracks the manual-verification goroutines started by handleCheck. The endpoint
 	// is fire-and-forget by design, so production never waits on it — the tests do
 	// (waitForChecks). A verification that outlives its test keeps calling into package-level
@@ -99,32 +123,56 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 	apiMux.HandleFunc("/api/v1/animes/{id}/episodes/{episodeNumber}/redownload", handleRedownloadEpisode(s))
 	apiMux.HandleFunc("/api/v1/animes/{id}/episodes/{episodeNumber}/release", handleReleaseEpisode(s))
 	apiMux.HandleFunc("/api/v1/animes/{id}/episodes/{episodeNumber}/replace", handleReplaceEpisodeWithMagnet(s))
+	apiMux.HandleFunc("/api/v1/animes/{id}/episodes/{episodeNumber}/watched", handleMarkEpisodeWatched(s))
 	apiMux.HandleFunc("/api/v1/animes/{id}/episodes/{episodeNumber}", handleDeleteEpisode(s))
 	apiMux.HandleFunc("/api/v1/animes/{id}/replace", handleReplaceAnimeWithMagnet(s))
 	apiMux.HandleFunc("/api/v1/animes/{id}/settings", handleAnimeSettings(s))
+	apiMux.HandleFunc("/api/v1/animes/{id}/ids", handleAnimeIDs(s))
 	apiMux.HandleFunc("/api/v1/anilist/search", handleAniListSearch(s))
+	apiMux.HandleFunc("/api/v1/anilist/accounts", handleAnilistAccounts(s))
+	apiMux.HandleFunc("/api/v1/anilist/accounts/{username}/login", handleAnilistLogin(s))
+	apiMux.HandleFunc("/api/v1/anilist/accounts/{username}/token", handleAnilistToken(s))
+	apiMux.HandleFunc("/api/v1/anilist/oauth/callback", handleAnilistCallback(s))
 	apiMux.HandleFunc("/api/v1/standalone-animes", handleStandaloneAnimeAdd(s))
 	apiMux.HandleFunc("/api/v1/standalone-animes/{id}", handleStandaloneAnimeRemove(s))
+	apiMux.HandleFunc("/api/v1/standalone-animes/{id}/list", handleStandaloneAnimeAddToList(s))
+	apiMux.HandleFunc("/api/v1/auto-subscribe/matches", handleAutoSubscribeMatches(s))
+	apiMux.HandleFunc("/api/v1/trials", handleTrials(s))
+	apiMux.HandleFunc("/api/v1/list-changes", handleListChanges(s))
+	apiMux.HandleFunc("/api/v1/trials/{id}/keep", handleTrialKeep(s))
 	apiMux.HandleFunc("/api/v1/check", handleCheck(s))
 	apiMux.HandleFunc("/api/v1/daemon/start", handleDaemonStart(s))
 	apiMux.HandleFunc("/api/v1/daemon/stop", handleDaemonStop(s))
 	apiMux.HandleFunc("/api/v1/logs", handleLogs(s))
+	apiMux.HandleFunc("/api/v1/data-usage", handleDataUsage(s))
+	apiMux.HandleFunc("/api/v1/id-mapping", handleIDMapping(s))
+	apiMux.HandleFunc("/api/v1/id-mapping/refresh", handleIDMapping(s))
+	apiMux.HandleFunc("/api/v1/library/naming/preview", handleLibraryNamingPreview(s))
+	apiMux.HandleFunc("/api/v1/library/link-probe", handleLibraryLinkProbe(s))
+	apiMux.HandleFunc("/api/v1/library/audit", handleLibraryAudit(s))
+	apiMux.HandleFunc("/api/v1/library/audit/repair", handleLibraryRepair(s))
 	apiMux.HandleFunc("/api/v1/torrents", handleTorrents(s))
 	// Single pattern for every method on this path: Go 1.22+ ServeMux patterns without a
-	// method prefix match all verbs, so handleTorrentDelete's own method check is what turns
-	// a non-DELETE request into a 405 instead of the mux ever seeing an unmatched pattern.
+	// method prefix match all verbs, so handleTorrent's dispatch (GET detail, DELETE) is what
+	// turns any other method into a 405 instead of the mux ever seeing an unmatched pattern.
 	// This does not collide with "/api/v1/torrents" (different segment count), nor with the
-	// "/pause", "/resume", "/announce", "/prioritize" sub-paths below (a bare "{hash}" pattern
+	// "/pause", "/resume", "/announce", "/prioritize", "/trackers", "/recheck" sub-paths below (a bare "{hash}" pattern
 	// only matches a single path segment), nor with the literal "/api/v1/torrents/prioritize"
 	// batch route: Go 1.22+ gives a literal segment precedence over a wildcard, and no info
 	// hash is the string "prioritize" anyway (they are 40 hex chars).
 	apiMux.HandleFunc("/api/v1/torrents/prioritize", handleTorrentsPrioritize(s))
-	apiMux.HandleFunc("/api/v1/torrents/{hash}", handleTorrentDelete(s))
+	apiMux.HandleFunc("/api/v1/torrents/pause-all", handleTorrentsPauseAll(s))
+	apiMux.HandleFunc("/api/v1/torrents/resume-all", handleTorrentsResumeAll(s))
+	apiMux.HandleFunc("/api/v1/torrents/{hash}", handleTorrent(s))
 	apiMux.HandleFunc("/api/v1/torrents/{hash}/pause", handleTorrentPause(s))
 	apiMux.HandleFunc("/api/v1/torrents/{hash}/resume", handleTorrentResume(s))
 	apiMux.HandleFunc("/api/v1/torrents/{hash}/announce", handleTorrentAnnounce(s))
 	apiMux.HandleFunc("/api/v1/torrents/{hash}/prioritize", handleTorrentPrioritize(s))
+	apiMux.HandleFunc("/api/v1/torrents/{hash}/trackers", handleTorrentAddTrackers(s))
+	apiMux.HandleFunc("/api/v1/torrents/{hash}/recheck", handleTorrentRecheck(s))
 	apiMux.HandleFunc("/api/v1/notifications/webhooks/{name}/test", handleNotificationWebhookTest(s))
+	apiMux.HandleFunc("/api/v1/media-servers/{name}/test", handleMediaServerTest(s))
+	apiMux.HandleFunc("/api/v1/integrations/{source}/playback", handlePlaybackWebhook(s))
 
 	// WebSocket route (no JSON middleware)
 	mux.HandleFunc("/api/v1/ws", s.handleWebSocket())
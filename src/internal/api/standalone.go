@@ -2,6 +2,8 @@ package api
 
 import (
 	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/lists"
 	"AutoAnimeDownloader/src/internal/logger"
 )
 
@@ -21,19 +23,39 @@ func loadStandaloneSet(fm FileManagerInterface) map[int]bool {
 	return set
 }
 
-// resolveMediaList le um anime pelo media id com o fallback dos avulsos.
+// resolveMediaList le um anime pelo media id com o fallback das contas do MAL/Kitsu e dos
+// avulsos.
 //
-// anilist.GetAnimeInfo devolve (nil, nil) para quem nao esta na lista de conta nenhuma, e um
-// avulso e exatamente esse caso — sem o fallback a tela de detalhe dele nao abre. O fallback e
-// condicionado ao conjunto de avulsos de proposito: sem isso qualquer media id da AniList
-// passaria a responder pelas rotas /animes/{id}/*, e o 404 de "esse anime nao e seu" sumiria.
-func resolveMediaList(fm FileManagerInterface, id int, usernames []string, standalone map[int]bool) (*anilist.MediaList, error) {
-	ml, err := anilist.GetAnimeInfo(id, usernames)
+// anilist.GetAnimeInfo devolve (nil, nil) para quem nao esta na lista de conta nenhuma da
+// AniList, e um avulso e exatamente esse caso — sem o fallback a tela de detalhe dele nao abre.
+// O fallback e condicionado ao conjunto de avulsos de proposito: sem isso qualquer media id da
+// AniList passaria a responder pelas rotas /animes/{id}/*, e o 404 de "esse anime nao e seu"
+// sumiria. As contas de fora entram antes dos avulsos porque tem progresso proprio.
+func resolveMediaList(fm FileManagerInterface, cfg *files.Config, id int, standalone map[int]bool) (*anilist.MediaList, error) {
+	ml, err := anilist.GetAnimeInfo(id, cfg.AnilistUsernames)
 	if err != nil {
 		return nil, err
 	}
-	if ml != nil || !standalone[id] {
-		return ml, err
+	if ml != nil {
+		return ml, nil
+	}
+	for _, acc := range lists.Accounts(cfg) {
+		entry, err := lists.Find(acc, cfg, id)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			continue
+		}
+		if ml, err = anilist.GetMediaByID(id); err != nil || ml == nil {
+			return ml, err
+		}
+		ml.Status = entry.Status
+		ml.Progress = entry.Progress
+		return ml, nil
+	}
+	if !standalone[id] {
+		return nil, nil
 	}
 	ml, err = anilist.GetMediaByID(id)
 	if err != nil {
@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/logger"
+	"sync/atomic"
+)
+
+// anilistCacheLoaded marca que o arquivo ja foi lido neste processo. Depois disso a memoria do
+// pacote anilist e a fonte, e o arquivo so e escrito.
+var anilistCacheLoaded atomic.Bool
+
+// loadAniListCache le o cache de respostas salvo, uma vez por processo: e ele que deixa o
+// primeiro passe depois de um restart rodar com a AniList fora do ar. Falha so e logada e tenta
+// de novo no passe seguinte; sem o arquivo o daemon so perde o fallback.
+func loadAniListCache(fm FileManagerInterface) {
+	if anilistCacheLoaded.Load() {
+		return
+	}
+	data, err := fm.LoadAniListCache()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to read the saved AniList responses")
+		return
+	}
+	if data != nil {
+		if err := anilist.LoadResponseCache(data); err != nil {
+			// Arquivo corrompido: o proximo save o substitui, nao adianta tentar de novo.
+			logger.Logger.Warn().Err(err).Msg("Ignoring the saved AniList responses")
+		}
+	}
+	anilistCacheLoaded.Store(true)
+}
+
+// saveAniListCache grava as respostas novas ao fim do passe, as dos handlers da API inclusive.
+// Nada novo desde o ultimo save, nada escrito.
+func saveAniListCache(fm FileManagerInterface) {
+	data, changed, err := anilist.ResponseCacheSnapshot()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to serialize the AniList responses")
+		return
+	}
+	if !changed {
+		return
+	}
+	if err := fm.SaveAniListCache(data); err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to save the AniList responses")
+	}
+}
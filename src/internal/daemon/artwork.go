@@ -0,0 +1,154 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// artworkHTTPDo baixa a arte; trocado nos testes.
+var artworkHTTPDo = (&http.Client{Timeout: 30 * time.Second}).Do
+
+// maxArtworkBytes corta uma resposta que nao e uma capa: as da AniList ficam abaixo de 1 MB.
+const maxArtworkBytes = 20 << 20
+
+// artworkURLs escolhe a maior capa que a AniList tem para o poster e o banner para o fanart.
+func artworkURLs(m anilist.Media) (poster, fanart string) {
+	for _, u := range []string{m.CoverImage.ExtraLarge, m.CoverImage.Large, m.CoverImage.Medium} {
+		if u != "" {
+			poster = u
+			break
+		}
+	}
+	if m.BannerImage != nil {
+		fanart = *m.BannerImage
+	}
+	return poster, fanart
+}
+
+// posterOutdated diz se a capa que o daemon baixou (rec.Poster) nao e mais a da AniList. Compara
+// so o nome do arquivo: as queries de lista trazem a capa large e o registro guarda a
+// extraLarge, e os tamanhos de uma mesma capa so mudam a pasta da URL
+// (.../cover/large/bx21-x.jpg, .../cover/extraLarge/bx21-x.jpg). Sem registro e do usuario.
+func posterOutdated(rec files.ArtworkSource, m anilist.Media) bool {
+	poster, _ := artworkURLs(m)
+	return rec.Poster != "" && poster != "" && path.Base(poster) != path.Base(rec.Poster)
+}
+
+// outdatedPosters consulta de uma vez as series com poster registrado e devolve as que tem capa
+// nova. Falha e so log: a arte fica como esta ate o proximo job.
+func outdatedPosters(sources map[int]files.ArtworkSource) map[int]bool {
+	var ids []int
+	for id, rec := range sources {
+		if rec.Poster != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	slices.Sort(ids)
+	media, err := anilist.GetMediaByIDs(ids)
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Metadata: failed to check the artwork for new covers")
+		return nil
+	}
+	outdated := make(map[int]bool)
+	for _, id := range ids {
+		if ml, ok := media[id]; ok && ml != nil && posterOutdated(sources[id], ml.Media) {
+			outdated[id] = true
+		}
+	}
+	return outdated
+}
+
+// checkArtworkSources roda no passe de verificacao, com as capas que a lista ja trouxe: um anime
+// do passe com capa nova enfileira o job de metadados, que confere a arte de toda serie. So
+// leitura local; a serie de uma temporada posterior (Season NN) fica para o proximo job.
+func checkArtworkSources(fm FileManagerInterface, jobQueue *JobQueue, animes []anilist.MediaList) {
+	if jobQueue == nil {
+		return
+	}
+	sources, err := fm.LoadArtworkSources()
+	if err != nil || len(sources) == 0 {
+		return
+	}
+	for _, anime := range animes {
+		if posterOutdated(sources[anime.Media.Id], anime.Media) {
+			logger.Logger.Debug().Int("anime_id", anime.Media.Id).Msg("Cover changed on AniList; refreshing the library artwork")
+			jobQueue.EnqueueMetadata()
+			return
+		}
+	}
+}
+
+func downloadArtwork(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := artworkHTTPDo(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("artwork %s: HTTP %d", url, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "image/") {
+		return nil, fmt.Errorf("artwork %s: unexpected content type %q", url, ct)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArtworkBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || len(data) > maxArtworkBytes {
+		return nil, fmt.Errorf("artwork %s: unexpected size %d", url, len(data))
+	}
+	return data, nil
+}
+
+// syncShowArtwork grava o poster.jpg e o fanart.jpg da serie showID em showDir. Uma imagem
+// presente fica quando nao ha URL registrada para ela (e do usuario) ou quando a registrada e
+// a atual; falta ou URL nova, baixa. Atualiza sources e diz se mudou. Para no primeiro erro.
+func syncShowArtwork(librarian files.Librarian, sources map[int]files.ArtworkSource, showDir string, showID int, m anilist.Media) (bool, error) {
+	poster, fanart := artworkURLs(m)
+	rec := sources[showID]
+	changed := false
+	for _, a := range []struct {
+		name     string
+		url      string
+		recorded *string
+	}{
+		{files.PosterFileName, poster, &rec.Poster},
+		{files.FanartFileName, fanart, &rec.Fanart},
+	} {
+		if a.url == "" {
+			continue
+		}
+		path := filepath.Join(showDir, a.name)
+		if librarian.HasArtwork(path) && (*a.recorded == "" || *a.recorded == a.url) {
+			continue
+		}
+		data, err := downloadArtwork(a.url)
+		if err != nil {
+			return changed, err
+		}
+		if err := librarian.SaveArtwork(path, data); err != nil {
+			return changed, err
+		}
+		*a.recorded = a.url
+		sources[showID] = rec
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+)
+
+func TestSyncShowArtwork(t *testing.T) {
+	downloads := 0
+	defer mockArtwork(t, map[string]string{"https://img/new.jpg": "new-poster", "https://img/banner.jpg": "banner"}, &downloads)()
+
+	banner := "https://img/banner.jpg"
+	media := anilist.Media{CoverImage: anilist.CoverImage{Large: "https://img/new.jpg"}, BannerImage: &banner}
+	lib := files.NewLibrarian(files.NewOSFileSystem())
+
+	// Capa trocada na AniList: o nosso poster (URL registrada) e substituido.
+	ours := t.TempDir()
+	if err := os.WriteFile(filepath.Join(ours, files.PosterFileName), []byte("old-poster"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sources := map[int]files.ArtworkSource{1: {Poster: "https://img/old.jpg"}}
+	changed, err := syncShowArtwork(lib, sources, ours, 1, media)
+	if err != nil || !changed {
+		t.Fatalf("syncShowArtwork = %v, %v", changed, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(ours, files.PosterFileName)); string(data) != "new-poster" {
+		t.Errorf("poster = %q, want the new cover", data)
+	}
+	if sources[1].Poster != "https://img/new.jpg" || sources[1].Fanart != banner {
+		t.Errorf("sources = %+v", sources[1])
+	}
+
+	// Poster do usuario (sem URL registrada): fica; so o fanart que falta e baixado.
+	user := t.TempDir()
+	if err := os.WriteFile(filepath.Join(user, files.PosterFileName), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	downloads = 0
+	if _, err := syncShowArtwork(lib, sources, user, 2, media); err != nil {
+		t.Fatalf("syncShowArtwork: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(user, files.PosterFileName)); string(data) != "mine" {
+		t.Errorf("user poster was replaced: %q", data)
+	}
+	if downloads != 1 || sources[2].Poster != "" || sources[2].Fanart != banner {
+		t.Errorf("downloads = %d, sources = %+v; want only the fanart", downloads, sources[2])
+	}
+
+	// Falha no download volta como erro, para o job tentar de novo.
+	media.CoverImage.Large = "https://img/missing.jpg"
+	if _, err := syncShowArtwork(lib, map[int]files.ArtworkSource{}, t.TempDir(), 3, media); err == nil {
+		t.Error("a failed download should return an error")
+	}
+}
@@ -0,0 +1,178 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/torrents"
+
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrTorrentListUnavailable e devolvido pela auditoria quando o cliente de torrent nao
+// respondeu a listagem. Sem ela todo registro pareceria sem torrent, e o reparo apagaria a
+// biblioteca inteira dos registros.
+var ErrTorrentListUnavailable = errors.New("the torrent client did not return its torrent list")
+
+// LibraryRepairFailure e um achado que o reparo nao conseguiu corrigir.
+type LibraryRepairFailure struct {
+	Finding files.AuditFinding `json:"finding"`
+	Error   string             `json:"error" example:"permission denied"`
+}
+
+// LibraryRepairResult conta o que RepairLibrary corrigiu por categoria e lista o que falhou.
+type LibraryRepairResult struct {
+	Repaired map[files.AuditCategory]int `json:"repaired"`
+	Failed   []LibraryRepairFailure      `json:"failed"`
+}
+
+// AuditLibrary confronta downloaded_episodes, os LibraryPaths em disco, a lista do cliente de
+// torrent e o que sobra na biblioteca e no diretorio de download (files.Librarian.AuditLibrary).
+// So le; quem corrige e RepairLibrary.
+func AuditLibrary(fm FileManagerInterface, backend torrents.TorrentBackend, librarian files.Librarian) ([]files.AuditFinding, error) {
+	findings, _, err := auditLibrary(fm, backend, librarian)
+	return findings, err
+}
+
+func auditLibrary(fm FileManagerInterface, backend torrents.TorrentBackend, librarian files.Librarian) ([]files.AuditFinding, *files.Config, error) {
+	configs, err := fm.LoadConfigs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if _, err := backend.Ensure(configs.DownloadPath()); err != nil {
+		return nil, nil, fmt.Errorf("failed to start torrent session: %w", err)
+	}
+	list := backend.List()
+	if list == nil {
+		return nil, nil, ErrTorrentListUnavailable
+	}
+	saved, err := fm.LoadSavedEpisodes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load saved episodes: %w", err)
+	}
+
+	req := files.AuditRequest{
+		CompletedPath: configs.CompletedAnimePath,
+		LinkMode:      configs.LinkMode(),
+		Episodes:      saved,
+		ScanDownloads: !torrents.IsRemoteClient(configs.TorrentClient),
+	}
+	for _, t := range list {
+		req.Torrents = append(req.Torrents, files.AuditTorrent{Hash: t.Hash, Name: t.Name, DataDir: t.DataDir, Completed: t.Completed})
+	}
+	findings, err := librarian.AuditLibrary(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return findings, configs, nil
+}
+
+// RepairLibrary audita de novo e corrige os achados das categorias escolhidas:
+//
+//   - broken_link: zera os LibraryPaths do torrent e enfileira um organize (sem webhook), que
+//     linka de novo o arquivo que semeia;
+//   - record_without_torrent: apaga o registro e deixa os arquivos da biblioteca, que podem ser a
+//     unica copia; o loop baixa o episodio de novo se ele ainda for desejado, e o organize
+//     substitui o arquivo antigo. Se nao for, o arquivo aparece como orphan_file na proxima
+//     auditoria;
+//   - orphan_torrent: remove o torrent com os dados;
+//   - orphan_file e duplicate: apaga o arquivo ou a pasta (Librarian.RemoveOrphan).
+//
+// Auditar de novo, em vez de receber os achados do cliente, garante que o reparo age sobre o
+// estado de agora e nunca sobre um caminho arbitrario vindo da API.
+func RepairLibrary(fm FileManagerInterface, backend torrents.TorrentBackend, librarian files.Librarian, jobQueue *JobQueue, categories []files.AuditCategory) (*LibraryRepairResult, error) {
+	findings, configs, err := auditLibrary(fm, backend, librarian)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := fm.LoadSavedEpisodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saved episodes: %w", err)
+	}
+
+	result := &LibraryRepairResult{Repaired: map[files.AuditCategory]int{}, Failed: []LibraryRepairFailure{}}
+	relinked := make(map[string]bool)
+	var removed []string
+	for _, f := range findings {
+		if !slices.Contains(categories, f.Category) {
+			continue
+		}
+		var err error
+		switch f.Category {
+		case files.AuditBrokenLink:
+			// Um batch pode ter varios links quebrados: o organize do torrent refaz todos.
+			if relinked[f.Hash] {
+				result.Repaired[f.Category]++
+				continue
+			}
+			if err = relinkTorrent(fm, jobQueue, saved, f.Hash); err == nil {
+				relinked[f.Hash] = true
+			}
+		case files.AuditRecordWithoutTorrent:
+			err = fm.DeleteEpisodesFromFile(findingKeys(saved, f))
+		case files.AuditOrphanTorrent:
+			err = backend.Remove(f.Hash, false)
+		case files.AuditOrphanFile, files.AuditDuplicate:
+			if err = librarian.RemoveOrphan(configs.CompletedAnimePath, f.Path); err == nil {
+				removed = append(removed, f.Path)
+			}
+		}
+		if err != nil {
+			logger.Logger.Warn().Err(err).Str("category", string(f.Category)).Str("path", f.Path).Str("hash", f.Hash).Msg("Library repair: failed to fix finding")
+			result.Failed = append(result.Failed, LibraryRepairFailure{Finding: f, Error: err.Error()})
+			continue
+		}
+		result.Repaired[f.Category]++
+	}
+	if jobQueue != nil && len(removed) > 0 {
+		jobQueue.EnqueueMediaScan(libraryShowDirs(removed))
+	}
+
+	logger.Logger.Info().Interface("repaired", result.Repaired).Int("failed", len(result.Failed)).Msg("Library repair finished")
+	return result, nil
+}
+
+// relinkTorrent zera os LibraryPaths dos registros do torrent e enfileira o organize dele.
+//
+// E a excecao pedida pelo usuario a decisao #29 (nunca zerar LibraryPaths por arquivo
+// faltando): o que a #29 evita e um cheque automatico que ressuscita a cada passe um episodio
+// apagado de proposito; aqui o usuario viu o link quebrado e pediu o reparo.
+func relinkTorrent(fm FileManagerInterface, jobQueue *JobQueue, saved []files.EpisodeStruct, hash string) error {
+	if jobQueue == nil {
+		return errors.New("job queue is not running")
+	}
+	var group []files.EpisodeStruct
+	for _, ep := range saved {
+		if ep.EpisodeHash == hash {
+			ep.LibraryPaths = nil
+			group = append(group, ep)
+		}
+	}
+	if len(group) == 0 {
+		return fmt.Errorf("no episode record for torrent %s", hash)
+	}
+	if err := fm.UpsertEpisodes(group); err != nil {
+		return fmt.Errorf("failed to clear library paths: %w", err)
+	}
+	jobQueue.EnqueueReorganize(hash)
+	return nil
+}
+
+// findingKeys devolve as chaves dos registros de um achado: os do torrent quando ha hash (um
+// batch pode cobrir mais de uma entrada da AniList), senao os do anime e episodios do achado.
+func findingKeys(saved []files.EpisodeStruct, f files.AuditFinding) []files.EpisodeKey {
+	var keys []files.EpisodeKey
+	if f.Hash != "" {
+		for _, ep := range saved {
+			if ep.EpisodeHash == f.Hash {
+				keys = append(keys, ep.Key())
+			}
+		}
+		return keys
+	}
+	for _, n := range f.EpisodeNumbers {
+		keys = append(keys, files.EpisodeKey{AnimeID: f.AnimeID, Episode: n})
+	}
+	return keys
+}
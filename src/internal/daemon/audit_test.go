@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+)
+
+// O reparo corrige so as categorias pedidas: o link quebrado volta pelo organize (sem webhook
+// de novo), o torrent orfao sai com os dados, o arquivo orfao e apagado e o registro sem
+// torrent, que nao foi pedido, fica.
+func TestRepairLibrary(t *testing.T) {
+	completed := t.TempDir()
+	seeding := filepath.Join(completed, ".torrents", "t1")
+	if err := os.MkdirAll(seeding, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(seeding, "episode.mkv"), []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	orphan := filepath.Join(completed, "Other", "random.mkv")
+	if err := os.MkdirAll(filepath.Dir(orphan), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(orphan, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const h1, h2, h3 = "1111111111111111111111111111111111111111", "2222222222222222222222222222222222222222", "3333333333333333333333333333333333333333"
+	deleted := filepath.Join(completed, "My Anime", "My Anime - E05.mkv")
+	spy := newWebhookSpy(t)
+	fm := &orchestrationFM{
+		saved: []files.EpisodeStruct{
+			{EpisodeHash: h1, AnimeID: 1, AnimeName: "My Anime", EpisodeNumber: 5, LibraryPaths: []string{deleted}},
+			{EpisodeHash: h2, AnimeID: 1, AnimeName: "My Anime", EpisodeNumber: 6},
+		},
+		configs: configWithCompletedWebhook(completed, spy.server.URL),
+	}
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted(h1, seeding)
+	backend.AddCompleted(h3, filepath.Join(completed, ".torrents", "t3"))
+	lib := files.NewLibrarian(files.NewOSFileSystem())
+	q := NewJobQueue(fm, filepath.Join(t.TempDir(), "jobs.json"))
+	q.SetOrchestration(backend, lib)
+
+	findings, err := AuditLibrary(fm, backend, lib)
+	if err != nil {
+		t.Fatalf("AuditLibrary: %v", err)
+	}
+	if len(findings) != 4 {
+		t.Fatalf("findings = %+v, want broken link, record without torrent, orphan torrent and orphan file", findings)
+	}
+
+	result, err := RepairLibrary(fm, backend, lib, q, []files.AuditCategory{files.AuditBrokenLink, files.AuditOrphanTorrent, files.AuditOrphanFile})
+	if err != nil {
+		t.Fatalf("RepairLibrary: %v", err)
+	}
+	if len(result.Failed) != 0 || result.Repaired[files.AuditBrokenLink] != 1 || result.Repaired[files.AuditOrphanTorrent] != 1 || result.Repaired[files.AuditOrphanFile] != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if _, ok := backend.Get(h3); ok || backend.RemovedKeepData[h3] {
+		t.Error("orphan torrent should be removed with its data")
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("orphan file should be removed, stat err = %v", err)
+	}
+	if len(fm.deleted) != 0 {
+		t.Errorf("record_without_torrent was not selected, but records were deleted: %v", fm.deleted)
+	}
+	if queued := queuedOrganizeHashes(t, q); len(queued) != 1 || queued[0] != h1 {
+		t.Fatalf("broken link should enqueue one organize for %s, queue has %v", h1, queued)
+	}
+
+	q.processDueJobs()
+	if _, err := os.Stat(deleted); err != nil {
+		t.Errorf("library file should be linked again: %v", err)
+	}
+	if len(fm.saved[0].LibraryPaths) != 1 || fm.saved[0].LibraryPaths[0] != deleted {
+		t.Errorf("LibraryPaths = %v, want %s", fm.saved[0].LibraryPaths, deleted)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if spy.callCount() != 0 {
+		t.Error("the repair organize must not fire the completion webhook again")
+	}
+
+	// Agora o registro sem torrent: sai do arquivo de episodios.
+	if _, err := RepairLibrary(fm, backend, lib, q, []files.AuditCategory{files.AuditRecordWithoutTorrent}); err != nil {
+		t.Fatalf("RepairLibrary: %v", err)
+	}
+	if len(fm.deleted) != 1 || fm.deleted[0] != (files.EpisodeKey{AnimeID: 1, Episode: 6}) {
+		t.Errorf("deleted = %v, want episode 6", fm.deleted)
+	}
+}
+
+// Sem a lista do cliente todo registro pareceria sem torrent: a auditoria recusa em vez de
+// mandar o reparo apagar a biblioteca dos registros.
+func TestAuditLibraryRequiresTorrentList(t *testing.T) {
+	fm := &orchestrationFM{configs: &files.Config{CompletedAnimePath: t.TempDir()}}
+	_, err := AuditLibrary(fm, nilListBackend{torrents.NewFakeBackend()}, files.NewLibrarian(files.NewOSFileSystem()))
+	if !errors.Is(err, ErrTorrentListUnavailable) {
+		t.Errorf("err = %v, want ErrTorrentListUnavailable", err)
+	}
+}
+
+// nilListBackend e um cliente que nao respondeu a listagem.
+type nilListBackend struct{ *torrents.FakeBackend }
+
+func (nilListBackend) List() []torrents.TorrentInfo { return nil }
@@ -0,0 +1,375 @@
+package daemon
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/notifications"
+	"AutoAnimeDownloader/src/internal/torrents"
+)
+
+// RuleMatch is one anime of the season that passed a rule, with one reason per criterion.
+type RuleMatch struct {
+	Media   anilist.SeasonalMedia
+	Reasons []string
+}
+
+// RuleEvaluation is a rule evaluated against its season. Err is set when the season could not
+// be read; Matches is then empty.
+type RuleEvaluation struct {
+	Rule    files.AutoSubscribeRule
+	Season  anilist.MediaSeason
+	Year    int
+	Matches []RuleMatch
+	Err     error
+}
+
+// EvaluateRules avalia todas as regras, ligadas ou nao, na ordem do config. E o que a tela de
+// regras mostra e o que o passe usa: os dois leem a temporada do mesmo cache, entao a previa e
+// o passe concordam sobre o que casou.
+func EvaluateRules(rules []files.AutoSubscribeRule, now time.Time) []RuleEvaluation {
+	evals := make([]RuleEvaluation, 0, len(rules))
+	for _, rule := range rules {
+		eval := RuleEvaluation{Rule: rule}
+		eval.Season, eval.Year = ruleSeason(rule, now)
+
+		media, err := anilist.GetSeasonalMedia(eval.Season, eval.Year)
+		if err != nil {
+			eval.Err = err
+			evals = append(evals, eval)
+			continue
+		}
+		for _, m := range media {
+			if reasons, ok := matchRule(rule, m); ok {
+				eval.Matches = append(eval.Matches, RuleMatch{Media: m, Reasons: reasons})
+			}
+		}
+		evals = append(evals, eval)
+	}
+	return evals
+}
+
+// ruleSeason resolve a temporada da regra: "" e 0 sao a temporada corrente, e o ano sozinho em 0
+// e o ano da temporada corrente.
+func ruleSeason(rule files.AutoSubscribeRule, now time.Time) (anilist.MediaSeason, int) {
+	season, year := anilist.CurrentSeason(now)
+	if rule.Season != "" {
+		season = anilist.MediaSeason(rule.Season)
+	}
+	if rule.SeasonYear > 0 {
+		year = rule.SeasonYear
+	}
+	return season, year
+}
+
+// matchRule diz se o anime passa em todos os criterios da regra, e por que. Um motivo por
+// criterio configurado — criterio vazio nao aparece, porque nao decidiu nada.
+func matchRule(rule files.AutoSubscribeRule, m anilist.SeasonalMedia) ([]string, bool) {
+	var reasons []string
+
+	if len(rule.Formats) > 0 {
+		if !slices.Contains(rule.Formats, string(m.Format)) {
+			return nil, false
+		}
+		reasons = append(reasons, "format "+string(m.Format))
+	}
+	if len(rule.Genres) > 0 {
+		var hit []string
+		for _, g := range m.Genres {
+			if slices.ContainsFunc(rule.Genres, func(want string) bool { return strings.EqualFold(want, g) }) {
+				hit = append(hit, g)
+			}
+		}
+		if len(hit) == 0 {
+			return nil, false
+		}
+		reasons = append(reasons, "genre "+strings.Join(hit, ", "))
+	}
+	if rule.MinPopularity > 0 {
+		if m.Popularity < rule.MinPopularity {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("popularity %d >= %d", m.Popularity, rule.MinPopularity))
+	}
+	if rule.MinScore > 0 {
+		if m.AverageScore == nil || *m.AverageScore < rule.MinScore {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("score %d >= %d", *m.AverageScore, rule.MinScore))
+	}
+	if rule.Country != "" {
+		if !strings.EqualFold(m.CountryOfOrigin, rule.Country) {
+			return nil, false
+		}
+		reasons = append(reasons, "country "+m.CountryOfOrigin)
+	}
+	if rule.ExcludeAdult {
+		if m.IsAdult {
+			return nil, false
+		}
+		reasons = append(reasons, "not adult")
+	}
+	return reasons, true
+}
+
+// autoSubscribe adiciona como avulso em trial os animes que as regras ligadas casam, e os
+// devolve como MediaList para serem processados neste mesmo passe, como followSequels.
+//
+// Um anime entra uma vez na vida: o registro em trials fica depois do trial terminar, e e ele
+// que impede a regra de trazer de volta o anime que o usuario tirou ou deixou expirar. O resto
+// do bloqueio e o mesmo guard do POST de avulso — anime ja numa lista, ja avulso, ja baixado ou
+// numa lista excluida nao vira trial.
+func autoSubscribe(fm FileManagerInterface, configs *files.Config, animes []anilist.MediaList, now time.Time) []anilist.MediaList {
+	if !slices.ContainsFunc(configs.AutoSubscribeRules, func(r files.AutoSubscribeRule) bool { return r.Enabled }) {
+		return nil
+	}
+	trials, err := fm.LoadTrials()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to load trials; not evaluating auto-subscribe rules this pass")
+		return nil
+	}
+
+	var (
+		guard *StandaloneGuard
+		added []anilist.MediaList
+	)
+	for _, eval := range EvaluateRules(configs.AutoSubscribeRules, now) {
+		if !eval.Rule.Enabled {
+			continue
+		}
+		if eval.Err != nil {
+			logger.Logger.Warn().Err(eval.Err).Str("rule", eval.Rule.Name).Msg("Failed to read the season of an auto-subscribe rule")
+			continue
+		}
+
+		for _, match := range eval.Matches {
+			id := match.Media.Id
+			if _, seen := trials[id]; seen {
+				continue
+			}
+
+			if guard == nil {
+				g, err := NewStandaloneGuard(fm, configs.ExcludedLists, animes)
+				if err != nil {
+					logger.Logger.Warn().Err(err).Msg("Failed to build the standalone guard; not auto-subscribing this pass")
+					return added
+				}
+				guard = &g
+			}
+			total := 0
+			if match.Media.Episodes != nil {
+				total = *match.Media.Episodes
+			}
+			if reason := guard.BlockReason(id, total); reason != "" {
+				continue
+			}
+
+			if err := fm.AddStandaloneAnime(id); err != nil {
+				logger.Logger.Warn().Err(err).Int("media_id", id).Msg("Failed to add the auto-subscribed anime as standalone")
+				continue
+			}
+			guard.standalone[id] = true
+
+			title := getAnimeTitleSafe(anilist.MediaList{Media: anilist.Media{Title: match.Media.Title}})
+			trial := files.Trial{
+				Rule:          eval.Rule.Name,
+				Title:         title,
+				TrialEpisodes: eval.Rule.TrialEpisodes,
+				AddedAt:       now,
+				State:         files.TrialActive,
+			}
+			trials[id] = trial
+			if err := fm.UpdateTrials(func(saved map[int]files.Trial) bool {
+				saved[id] = trial
+				return true
+			}); err != nil {
+				logger.Logger.Warn().Err(err).Int("media_id", id).Msg("Failed to record the trial")
+			}
+
+			logger.Logger.Info().Int("media_id", id).Str("anime", title).Str("rule", eval.Rule.Name).
+				Strs("reasons", match.Reasons).Msg("Auto-subscribed anime as a trial")
+			notifications.Notify(configs, notifications.TrialAdded, title, 0, eval.Rule.Name)
+
+			// Falha aqui so adia, como em followSequels.
+			ml, err := anilist.GetMediaByID(id)
+			if err != nil || ml == nil {
+				logger.Logger.Warn().Err(err).Int("media_id", id).
+					Msg("Failed to fetch the auto-subscribed anime; it will be processed next pass")
+				continue
+			}
+			added = append(added, *withStandaloneProgress(fm, ml))
+		}
+	}
+	return added
+}
+
+// activeTrialEpisodes e o limite de episodios de cada trial em curso, para processAnimeEpisodes.
+// Falha de leitura vale como "nenhum trial": o anime e baixado como avulso comum, que e o erro
+// barato — o contrario deixaria de baixar o que o usuario ja mantem.
+func activeTrialEpisodes(fm FileManagerInterface) map[int]int {
+	trials, err := fm.LoadTrials()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to load trials, downloading trial animes without their limit")
+		return nil
+	}
+	limits := make(map[int]int, len(trials))
+	for id, t := range trials {
+		if t.State == files.TrialActive {
+			limits[id] = t.TrialEpisodes
+		}
+	}
+	return limits
+}
+
+// TrialExpiresAt e quando o trial expira, ou nil se ele nao expira (trial_keep_days = 0 ou trial
+// que ja terminou).
+func TrialExpiresAt(t files.Trial, keepDays int) *time.Time {
+	if t.State != files.TrialActive || keepDays <= 0 {
+		return nil
+	}
+	at := t.AddedAt.AddDate(0, 0, keepDays)
+	return &at
+}
+
+// expireTrials encerra os trials que acabaram e devolve standaloneIDs sem os que sairam.
+//
+// Roda antes do fan-out do passe, com a lista de avulsos ja lida: um trial expirado nao pode
+// ser buscado e processado de novo neste mesmo passe.
+//
+//   - Trial que nao esta mais em standalone_animes vira TrialLeft: o usuario o removeu, ou ele
+//     entrou numa lista e appendStandaloneAnimes consumiu o registro. Nada a apagar.
+//   - Trial com trial_keep_days vencido vira TrialExpired: sai de standalone_animes e os
+//     episodios saem do disco, biblioteca e seed, como no DELETE com delete_episodes=true.
+//     Apagar e o ponto: o trial existe para experimentar sem gastar disco com o que nao ficou.
+//
+// A transicao para expired e gravada antes de apagar qualquer coisa (ver endTrial).
+func expireTrials(fm FileManagerInterface, backend torrents.TorrentBackend, librarian files.Librarian, configs *files.Config, standaloneIDs []int, now time.Time) []int {
+	trials, err := fm.LoadTrials()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to load trials; not expiring trials this pass")
+		return standaloneIDs
+	}
+
+	var left, expired []int
+	for id, t := range trials {
+		if t.State != files.TrialActive {
+			continue
+		}
+		if !slices.Contains(standaloneIDs, id) {
+			left = append(left, id)
+			continue
+		}
+		if at := TrialExpiresAt(t, configs.TrialKeepDays); at != nil && !now.Before(*at) {
+			expired = append(expired, id)
+		}
+	}
+	if len(left) == 0 && len(expired) == 0 {
+		return standaloneIDs
+	}
+
+	var saved []files.EpisodeStruct
+	if len(expired) > 0 {
+		if saved, err = fm.LoadSavedEpisodes(); err != nil {
+			logger.Logger.Warn().Err(err).Msg("Failed to load saved episodes; not expiring trials this pass")
+			expired = nil
+		}
+	}
+
+	// O estado muda antes de qualquer delecao, dentro do UpdateTrials: um keep que chegou
+	// depois do LoadTrials ja deixou o trial como kept, endTrial recusa, e o anime fica. So
+	// o que de fato virou expired e apagado.
+	var ended []int
+	if err := fm.UpdateTrials(func(trials map[int]files.Trial) bool {
+		for _, id := range left {
+			endTrial(trials, id, files.TrialLeft, now)
+		}
+		for _, id := range expired {
+			if endTrial(trials, id, files.TrialExpired, now) {
+				ended = append(ended, id)
+			}
+		}
+		return true
+	}); err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to record the ended trials; not expiring trials this pass")
+		return standaloneIDs
+	}
+
+	var removed, restore []int
+	for _, id := range ended {
+		if err := fm.RemoveStandaloneAnime(id); err != nil {
+			logger.Logger.Warn().Err(err).Int("media_id", id).Msg("Failed to remove the expired trial")
+			restore = append(restore, id)
+			continue
+		}
+		removed = append(removed, id)
+
+		var keys []files.EpisodeKey
+		for _, ep := range saved {
+			if ep.AnimeID == id {
+				keys = append(keys, files.EpisodeKey{AnimeID: id, Episode: ep.EpisodeNumber})
+			}
+		}
+		// O avulso ja saiu: um erro aqui deixa episodios sem dono, que a poda de
+		// identifyEpisodesNotInWatching recolhe como os de qualquer anime que saiu da lista.
+		if err := RemoveEpisodesWithLinks(fm, backend, librarian, keys); err != nil {
+			logger.Logger.Warn().Err(err).Int("media_id", id).Msg("Failed to delete the episodes of the expired trial")
+		}
+
+		logger.Logger.Info().Int("media_id", id).Str("anime", trials[id].Title).Int("episodes", len(keys)).
+			Msg("Trial expired without being kept; removed it")
+		notifications.Notify(configs, notifications.TrialExpired, trials[id].Title, 0, trials[id].Rule)
+	}
+
+	// O avulso que nao saiu volta a ser trial em curso, e o passe seguinte tenta de novo. Como
+	// expired ele ficaria no disco para sempre: nada mais o expira.
+	if len(restore) > 0 {
+		if err := fm.UpdateTrials(func(trials map[int]files.Trial) bool {
+			for _, id := range restore {
+				if t, ok := trials[id]; ok && t.State == files.TrialExpired {
+					t.State = files.TrialActive
+					t.EndedAt = nil
+					trials[id] = t
+				}
+			}
+			return true
+		}); err != nil {
+			logger.Logger.Warn().Err(err).Msg("Failed to restore the trials that could not be removed")
+		}
+	}
+
+	return slices.DeleteFunc(standaloneIDs, func(id int) bool { return slices.Contains(removed, id) })
+}
+
+// endTrial so encerra trial em curso: entre o LoadTrials e o UpdateTrials o usuario pode ter
+// ficado com ele, e "kept" vence. Devolve se encerrou.
+func endTrial(trials map[int]files.Trial, id int, state string, now time.Time) bool {
+	t, ok := trials[id]
+	if !ok || t.State != files.TrialActive {
+		return false
+	}
+	t.State = state
+	t.EndedAt = &now
+	trials[id] = t
+	return true
+}
+
+// KeepTrial tira o anime do trial: o limite de episodios sai e ele nunca expira. Devolve false
+// quando o anime nao esta em trial.
+func KeepTrial(fm FileManagerInterface, mediaID int, now time.Time) (bool, error) {
+	found := false
+	err := fm.UpdateTrials(func(trials map[int]files.Trial) bool {
+		t, ok := trials[mediaID]
+		if !ok || t.State != files.TrialActive {
+			return false
+		}
+		found = true
+		endTrial(trials, mediaID, files.TrialKept, now)
+		return true
+	})
+	return found, err
+}
@@ -0,0 +1,232 @@
+package daemon
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/nyaa"
+	"AutoAnimeDownloader/src/internal/torrents"
+)
+
+func TestMatchRule(t *testing.T) {
+	score := 75
+	media := anilist.SeasonalMedia{
+		Id: 1, Format: anilist.MediaFormatTV, Genres: []string{"Action", "Comedy"},
+		Popularity: 20000, AverageScore: &score, CountryOfOrigin: "JP",
+	}
+
+	reasons, ok := matchRule(files.AutoSubscribeRule{
+		Formats: []string{"TV", "ONA"}, Genres: []string{"comedy"}, MinPopularity: 10000,
+		MinScore: 70, Country: "JP", ExcludeAdult: true,
+	}, media)
+	want := []string{"format TV", "genre Comedy", "popularity 20000 >= 10000", "score 75 >= 70", "country JP", "not adult"}
+	if !ok || !slices.Equal(reasons, want) {
+		t.Fatalf("quero casar com %v, veio %v (%v)", want, reasons, ok)
+	}
+
+	if reasons, ok := matchRule(files.AutoSubscribeRule{}, media); !ok || len(reasons) != 0 {
+		t.Errorf("regra sem criterio casa com tudo e sem motivo, veio %v (%v)", reasons, ok)
+	}
+
+	fails := map[string]files.AutoSubscribeRule{
+		"formato":      {Formats: []string{"MOVIE"}},
+		"genero":       {Genres: []string{"Horror"}},
+		"popularidade": {MinPopularity: 30000},
+		"nota":         {MinScore: 80},
+		"pais":         {Country: "CN"},
+	}
+	for name, rule := range fails {
+		if _, ok := matchRule(rule, media); ok {
+			t.Errorf("%s: nao devia casar", name)
+		}
+	}
+
+	// Anime ainda sem nota nao passa num min_score.
+	media.AverageScore = nil
+	if _, ok := matchRule(files.AutoSubscribeRule{MinScore: 1}, media); ok {
+		t.Error("anime sem nota passou num min_score")
+	}
+}
+
+const seasonalTwoShows = `{"data": {"Page": {"pageInfo": {"hasNextPage": false}, "media": [
+	{"id": 300, "format": "TV", "popularity": 50000, "countryOfOrigin": "JP", "title": {"romaji": "Popular"}},
+	{"id": 301, "format": "TV", "popularity": 100, "countryOfOrigin": "JP", "title": {"romaji": "Obscure"}}
+]}}}`
+
+const trialMedia = `{"data": {"Media": {
+	"id": 300, "format": "TV", "status": "RELEASING", "episodes": 12,
+	"title": {"english": "Popular", "romaji": "Popular"}
+}}}`
+
+// A regra ligada adiciona o que casa, uma vez na vida; a desligada so aparece na previa.
+func TestAutoSubscribe_AddsMatchOnceAsTrial(t *testing.T) {
+	defer mockAniListRouter(t, seasonalTwoShows, trialMedia)()
+	fm := &mockFileManagerForEpisodes{}
+	cfg := &files.Config{AutoSubscribeRules: []files.AutoSubscribeRule{
+		{Name: "populares", Enabled: true, MinPopularity: 10000, TrialEpisodes: 3},
+		{Name: "tudo", Enabled: false, TrialEpisodes: 1},
+	}}
+	now := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+
+	added := autoSubscribe(fm, cfg, nil, now)
+	if len(added) != 1 || added[0].Media.Id != 300 {
+		t.Fatalf("quero so o 300 no passe, veio %+v", added)
+	}
+	if !slices.Equal(fm.standaloneAnimes, []int{300}) {
+		t.Fatalf("quero o 300 em standalone_animes, veio %v", fm.standaloneAnimes)
+	}
+	trial := fm.trials[300]
+	if trial.State != files.TrialActive || trial.Rule != "populares" || trial.TrialEpisodes != 3 || !trial.AddedAt.Equal(now) {
+		t.Fatalf("trial registrado errado: %+v", trial)
+	}
+
+	// O usuario tira o anime: o registro do trial impede a regra de traze-lo de volta.
+	fm.standaloneAnimes = nil
+	if again := autoSubscribe(fm, cfg, nil, now); len(again) != 0 || len(fm.standaloneAnimes) != 0 {
+		t.Fatalf("a regra trouxe o anime de volta: %+v", again)
+	}
+}
+
+// Anime que ja esta numa lista nao vira trial.
+func TestAutoSubscribe_SkipsTrackedAnime(t *testing.T) {
+	defer mockAniListRouter(t, seasonalTwoShows, trialMedia)()
+	fm := &mockFileManagerForEpisodes{}
+	cfg := &files.Config{AutoSubscribeRules: []files.AutoSubscribeRule{{Name: "todos", Enabled: true, TrialEpisodes: 2}}}
+	tracked := []anilist.MediaList{{Media: anilist.Media{Id: 300}}}
+
+	// So o 301 entra. O mock de GetMediaByID responde o 300 para qualquer id, entao o que o
+	// teste olha e o registro de trials, nao o MediaList devolvido.
+	if added := autoSubscribe(fm, cfg, tracked, time.Now()); len(added) != 1 {
+		t.Fatalf("quero um anime adicionado, veio %d", len(added))
+	}
+	if _, ok := fm.trials[300]; ok {
+		t.Fatalf("anime de lista virou trial: %+v", fm.trials)
+	}
+	if _, ok := fm.trials[301]; !ok {
+		t.Fatalf("quero o 301 em trial, veio %+v", fm.trials)
+	}
+}
+
+func TestExpireTrials(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	fm := &mockFileManagerForEpisodes{
+		standaloneAnimes: []int{300, 301, 400},
+		savedEpisodes: []files.EpisodeStruct{
+			{AnimeID: 300, EpisodeNumber: 1}, {AnimeID: 300, EpisodeNumber: 2}, {AnimeID: 301, EpisodeNumber: 1},
+		},
+		trials: map[int]files.Trial{
+			300: {Rule: "r", AddedAt: now.AddDate(0, 0, -15), State: files.TrialActive},
+			301: {Rule: "r", AddedAt: now.AddDate(0, 0, -2), State: files.TrialActive},
+			302: {Rule: "r", AddedAt: now.AddDate(0, 0, -2), State: files.TrialActive},
+			303: {Rule: "r", AddedAt: now.AddDate(0, 0, -90), State: files.TrialKept},
+		},
+	}
+	cfg := &files.Config{TrialKeepDays: 14}
+
+	remaining := expireTrials(fm, torrents.NewFakeBackend(), nil, cfg, []int{300, 301, 400}, now)
+
+	if !slices.Equal(remaining, []int{301, 400}) {
+		t.Fatalf("quero o 300 fora do passe, veio %v", remaining)
+	}
+	if !slices.Equal(fm.removedStandalone, []int{300}) {
+		t.Fatalf("quero so o 300 removido de standalone_animes, veio %v", fm.removedStandalone)
+	}
+	if len(fm.deletedEpisodeKeys) != 2 || fm.deletedEpisodeKeys[0].AnimeID != 300 {
+		t.Fatalf("quero os 2 episodios do 300 apagados, veio %v", fm.deletedEpisodeKeys)
+	}
+	for id, want := range map[int]string{300: files.TrialExpired, 301: files.TrialActive, 302: files.TrialLeft, 303: files.TrialKept} {
+		if got := fm.trials[id].State; got != want {
+			t.Errorf("trial %d: quero %s, veio %s", id, want, got)
+		}
+	}
+	if fm.trials[300].EndedAt == nil || fm.trials[301].EndedAt != nil {
+		t.Errorf("EndedAt errado: %+v", fm.trials)
+	}
+
+	// trial_keep_days = 0: nada expira.
+	fm.trials[301] = files.Trial{AddedAt: now.AddDate(-1, 0, 0), State: files.TrialActive}
+	if remaining := expireTrials(fm, torrents.NewFakeBackend(), nil, &files.Config{}, []int{301}, now); !slices.Equal(remaining, []int{301}) {
+		t.Fatalf("com prazo 0 o trial expirou: %v", remaining)
+	}
+}
+
+func TestKeepTrial(t *testing.T) {
+	now := time.Now()
+	fm := &mockFileManagerForEpisodes{trials: map[int]files.Trial{300: {State: files.TrialActive}, 301: {State: files.TrialExpired}}}
+
+	if found, err := KeepTrial(fm, 300, now); !found || err != nil {
+		t.Fatalf("KeepTrial(300) = %v, %v", found, err)
+	}
+	if fm.trials[300].State != files.TrialKept {
+		t.Fatalf("quero kept, veio %+v", fm.trials[300])
+	}
+	if found, _ := KeepTrial(fm, 301, now); found {
+		t.Error("trial expirado nao pode ser mantido")
+	}
+	if limits := activeTrialEpisodes(fm); len(limits) != 0 {
+		t.Errorf("trial mantido ainda limita: %v", limits)
+	}
+}
+
+// O trial baixa so os primeiros N, e nunca por pack — o pack da temporada traria o resto.
+func TestProcessAnimeEpisodes_TrialLimit(t *testing.T) {
+	anime := animeWithEpisodes(12, anilist.MediaStatusFinished, true, "")
+	searcher := searcherFor([]nyaa.TorrentResult{{Name: "batch", MagnetLink: fakeMagnet(9001)}}, multipleFor(12, 1), nil, nil)
+
+	result := processAnimeEpisodes(limitsConfig(), torrents.NewFakeBackend(), anime, nil, nil, map[files.EpisodeKey]bool{}, "", 3, searcher)
+
+	var got []int
+	for _, ep := range result.newEpisodes {
+		if ep.IsBatch {
+			t.Fatalf("trial usou pack: %+v", ep)
+		}
+		got = append(got, ep.EpisodeNumber)
+	}
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("quero os episodios 1 a 3, veio %v", got)
+	}
+	if len(result.checkedEpisodes) != 3 {
+		t.Errorf("quero so 3 episodios checados, veio %d", len(result.checkedEpisodes))
+	}
+}
+
+// keepRaceFM simula o POST .../keep chegando entre o LoadTrials e o UpdateTrials do passe.
+type keepRaceFM struct {
+	mockFileManagerForEpisodes
+	keep int
+}
+
+func (m *keepRaceFM) LoadTrials() (map[int]files.Trial, error) {
+	loaded := maps.Clone(m.trials)
+	if _, err := KeepTrial(&m.mockFileManagerForEpisodes, m.keep, time.Now()); err != nil {
+		return nil, err
+	}
+	return loaded, nil
+}
+
+// O keep que chegou depois da leitura vence: o anime nao sai de standalone_animes e os
+// episodios ficam.
+func TestExpireTrials_KeepDuringPass(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	fm := &keepRaceFM{keep: 300, mockFileManagerForEpisodes: mockFileManagerForEpisodes{
+		standaloneAnimes: []int{300},
+		savedEpisodes:    []files.EpisodeStruct{{AnimeID: 300, EpisodeNumber: 1}},
+		trials:           map[int]files.Trial{300: {Rule: "r", AddedAt: now.AddDate(0, 0, -15), State: files.TrialActive}},
+	}}
+
+	remaining := expireTrials(fm, torrents.NewFakeBackend(), nil, &files.Config{TrialKeepDays: 14}, []int{300}, now)
+
+	if !slices.Equal(remaining, []int{300}) {
+		t.Fatalf("o trial mantido saiu do passe: %v", remaining)
+	}
+	if len(fm.removedStandalone) != 0 || len(fm.deletedEpisodeKeys) != 0 {
+		t.Fatalf("o trial mantido foi apagado: standalone %v, episodios %v", fm.removedStandalone, fm.deletedEpisodeKeys)
+	}
+	if got := fm.trials[300].State; got != files.TrialKept {
+		t.Errorf("quero kept, veio %s", got)
+	}
+}
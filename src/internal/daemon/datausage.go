@@ -0,0 +1,266 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/torrents"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// dataUsageSampleInterval e de quanto em quanto tempo o medidor le os contadores dos torrents.
+// O teto e checado na mesma cadencia, entao e tambem o quanto ele pode ser ultrapassado: um
+// minuto de banda cheia, nao um CheckInterval inteiro.
+const dataUsageSampleInterval = time.Minute
+
+// dataUsageRetentionDays e quantos dias de historico o ledger guarda. Um pouco mais de um ano,
+// para o mesmo ciclo do ano anterior continuar comparavel.
+const dataUsageRetentionDays = 400
+
+// ErrDataCapReached e devolvido por checkDataCap quando o teto mensal de dados foi atingido.
+var ErrDataCapReached = errors.New("monthly data cap reached")
+
+// checkDataCap barra a ADICAO de novos torrents com o teto atingido, como checkDiskSpace faz
+// com o disco cheio. O teto ja segura os torrents que estao na sessao (SetDataCapReached);
+// esta guarda so evita que o passe encha a fila de torrents que nao vao comecar ate a virada.
+func checkDataCap(backend torrents.TorrentBackend, configs *files.Config) error {
+	if !backend.DataCapReached() {
+		return nil
+	}
+	_, end := BillingPeriod(time.Now(), configs.DataCapBillingDay)
+	return fmt.Errorf("%w: %.1f GB per billing period, resets on %s", ErrDataCapReached, configs.DataCapGB, end.Format(files.DataUsageDateLayout))
+}
+
+// RunDataUsageMeter amostra o trafego a cada dataUsageSampleInterval e aplica o teto, ate ctx
+// ser cancelado. Roda fora do loop de verificacao pelo mesmo motivo do seeding: parar o loop
+// pela WebUI nao para os torrents, entao tambem nao pode parar a conta nem o teto.
+func RunDataUsageMeter(ctx context.Context, fm FileManagerInterface, backend torrents.TorrentBackend) {
+	ticker := time.NewTicker(dataUsageSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		sampleDataUsage(fm, backend, time.Now())
+		configs, err := fm.LoadConfigs()
+		if err != nil {
+			logger.Logger.Warn().Err(err).Msg("Data usage meter: failed to load configs; data cap left as it was")
+			continue
+		}
+		ApplyDataCap(fm, backend, configs)
+	}
+}
+
+// sampleDataUsage le os contadores acumulados de cada torrent (TorrentInfo.BytesDownloaded e
+// BytesUploaded, que a rain persiste) e lanca a diferenca desde a amostra anterior no dia de
+// hoje — no total e no anime dono do torrent, pelo EpisodeHash dos episodios salvos.
+//
+// Tres casos nao lancam nada, so anotam o contador:
+//   - a primeira amostra da instalacao (SampledAt zero): o contador e o trafego da vida toda
+//     do torrent, e cairia inteiro no dia de hoje;
+//   - um torrent que o ledger nao conhece mas que foi adicionado antes da amostra anterior —
+//     a sessao estava fechada ou foi recriada, e pelo mesmo motivo o contador dele e antigo;
+//   - um contador que voltou (a rain grava o resume data periodicamente, entao um crash perde
+//     os ultimos segundos): nao ha diferenca negativa, so um novo ponto de partida.
+func sampleDataUsage(fm FileManagerInterface, backend torrents.TorrentBackend, now time.Time) {
+	list := backend.List()
+	// Sem sessao List devolve nil: "nao sei nada", e podar os contadores aqui faria a proxima
+	// amostra tratar todos os torrents como novos.
+	if list == nil {
+		return
+	}
+
+	ledger, err := fm.LoadDataUsage()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Data usage meter: failed to load the ledger; skipping this sample")
+		return
+	}
+
+	baseline := ledger.SampledAt.IsZero()
+	dayKey := now.Format(files.DataUsageDateLayout)
+	day := ledger.Days[dayKey]
+	counters := make(map[string]files.ByteCounts, len(list))
+	changed := false
+	var owners map[string]ownerAnime
+
+	for _, t := range list {
+		cur := files.ByteCounts{Downloaded: t.BytesDownloaded, Uploaded: t.BytesUploaded}
+		prev, known := ledger.Counters[t.Hash]
+		counters[t.Hash] = cur
+		if cur != prev {
+			changed = true
+		}
+		if baseline || (!known && !t.AddedAt.After(ledger.SampledAt)) {
+			continue
+		}
+		delta := files.ByteCounts{
+			Downloaded: max(cur.Downloaded-prev.Downloaded, 0),
+			Uploaded:   max(cur.Uploaded-prev.Uploaded, 0),
+		}
+		if delta.Total() == 0 {
+			continue
+		}
+		day.Add(delta)
+
+		// Episodios so sao lidos quando ha o que atribuir: a cada minuto, quase sempre nao ha.
+		if owners == nil {
+			owners = torrentOwners(fm)
+		}
+		owner, ok := owners[t.Hash]
+		if !ok {
+			continue
+		}
+		if day.Animes == nil {
+			day.Animes = map[int]files.ByteCounts{}
+		}
+		a := day.Animes[owner.id]
+		a.Add(delta)
+		day.Animes[owner.id] = a
+		ledger.AnimeNames[owner.id] = owner.name
+	}
+	// Um torrent que saiu da sessao tambem e mudanca: o contador dele sai do arquivo.
+	if !baseline && !changed && len(counters) == len(ledger.Counters) {
+		return
+	}
+
+	if day.Total() > 0 {
+		ledger.Days[dayKey] = day
+	}
+	ledger.Counters = counters
+	ledger.SampledAt = now
+	pruneDataUsage(ledger, now)
+	if err := fm.SaveDataUsage(ledger); err != nil {
+		logger.Logger.Warn().Err(err).Msg("Data usage meter: failed to save the ledger")
+	}
+}
+
+type ownerAnime struct {
+	id   int
+	name string
+}
+
+// torrentOwners liga cada hash ao anime dos episodios salvos. Um batch tem um hash para varios
+// episodios do mesmo anime, entao o primeiro basta.
+func torrentOwners(fm FileManagerInterface) map[string]ownerAnime {
+	owners := map[string]ownerAnime{}
+	saved, err := fm.LoadSavedEpisodes()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Data usage meter: failed to load saved episodes; traffic counted without an anime")
+		return owners
+	}
+	for _, ep := range saved {
+		if ep.EpisodeHash == "" {
+			continue
+		}
+		if _, ok := owners[ep.EpisodeHash]; !ok {
+			owners[ep.EpisodeHash] = ownerAnime{id: ep.AnimeID, name: ep.AnimeName}
+		}
+	}
+	return owners
+}
+
+// pruneDataUsage tira do ledger os dias alem de dataUsageRetentionDays e os nomes de anime que
+// nenhum dia restante cita. Chave que nao parseia (arquivo editado a mao) sai junto.
+func pruneDataUsage(ledger *files.DataUsageLedger, now time.Time) {
+	cutoff := now.AddDate(0, 0, -dataUsageRetentionDays)
+	cited := map[int]bool{}
+	for key, day := range ledger.Days {
+		d, err := time.ParseInLocation(files.DataUsageDateLayout, key, now.Location())
+		if err != nil || d.Before(cutoff) {
+			delete(ledger.Days, key)
+			continue
+		}
+		for id := range day.Animes {
+			cited[id] = true
+		}
+	}
+	for id := range ledger.AnimeNames {
+		if !cited[id] {
+			delete(ledger.AnimeNames, id)
+		}
+	}
+}
+
+// BillingPeriod devolve o ciclo da operadora que contem now: comeca a meia-noite local do dia
+// billingDay e termina (exclusivo) no mesmo dia do mes seguinte. billingDay fora de 1..28 vale 1.
+func BillingPeriod(now time.Time, billingDay int) (start, end time.Time) {
+	if billingDay < 1 || billingDay > 28 {
+		billingDay = 1
+	}
+	y, m, d := now.Date()
+	if d < billingDay {
+		m--
+	}
+	// time.Date normaliza o mes 0 para dezembro do ano anterior.
+	start = time.Date(y, m, billingDay, 0, 0, 0, 0, now.Location())
+	return start, start.AddDate(0, 1, 0)
+}
+
+// UsageBetween soma os dias do ledger em [start, end). animeID > 0 soma so a parte daquele anime.
+func UsageBetween(ledger *files.DataUsageLedger, start, end time.Time, animeID int) files.ByteCounts {
+	var total files.ByteCounts
+	for key, day := range ledger.Days {
+		d, err := time.ParseInLocation(files.DataUsageDateLayout, key, start.Location())
+		if err != nil || d.Before(start) || !d.Before(end) {
+			continue
+		}
+		if animeID > 0 {
+			total.Add(day.Animes[animeID])
+			continue
+		}
+		total.Add(day.ByteCounts)
+	}
+	return total
+}
+
+// DataCapBytes converte Config.DataCapGB para bytes, na mesma base 1024 de
+// MaxEpisodeTorrentSizeGB. 0 = sem teto.
+func DataCapBytes(configs *files.Config) int64 {
+	if configs.DataCapGB <= 0 {
+		return 0
+	}
+	return int64(configs.DataCapGB * 1024 * 1024 * 1024)
+}
+
+// ApplyDataCap compara o trafego do ciclo atual com Config.DataCapGB e liga ou desliga a
+// retencao do teto no backend. Mesmos lugares de ApplyQueuePolicy — boot, PUT /config e o topo
+// de todo passe — e mais o medidor, a cada amostra: e ele quem percebe o teto sendo atingido
+// no meio de um passe e a virada do ciclo de madrugada. Devolve se o teto esta atingido.
+//
+// Ledger ilegivel nao muda nada: liberar o teto por causa de um erro de leitura gastaria
+// justamente a banda que o usuario pediu para nao gastar, e prender tudo por ele pararia o
+// seeding de quem nem chegou perto.
+func ApplyDataCap(fm FileManagerInterface, backend torrents.TorrentBackend, configs *files.Config) bool {
+	capBytes := DataCapBytes(configs)
+	reached := false
+	if capBytes > 0 {
+		ledger, err := fm.LoadDataUsage()
+		if err != nil {
+			logger.Logger.Warn().Err(err).Msg("Failed to load the data usage ledger; data cap left as it was")
+			return backend.DataCapReached()
+		}
+		now := time.Now()
+		start, end := BillingPeriod(now, configs.DataCapBillingDay)
+		used := UsageBetween(ledger, start, end, 0).Total()
+		reached = used >= capBytes
+		if reached && !backend.DataCapReached() {
+			logger.Logger.Warn().
+				Int64("used_bytes", used).
+				Int64("cap_bytes", capBytes).
+				Time("resets_at", end).
+				Msg("Monthly data cap reached; downloads and seeding are paused until the billing period resets")
+		}
+	}
+	if !reached && backend.DataCapReached() {
+		logger.Logger.Info().Msg("Data cap lifted; resuming downloads and seeding")
+	}
+	// So na mudanca: SetDataCapReached roda um enforce, e o medidor chama isto a cada minuto.
+	if reached != backend.DataCapReached() {
+		backend.SetDataCapReached(reached)
+	}
+	return reached
+}
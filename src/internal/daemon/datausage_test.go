@@ -0,0 +1,120 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+	"errors"
+	"testing"
+	"time"
+)
+
+// The first sample only records the counters; later samples book the deltas on the day and on
+// the anime that owns the torrent. A torrent added after the previous sample counts in full.
+func TestSampleDataUsageBooksDeltas(t *testing.T) {
+	fm := tempFileManager(t)
+	if err := fm.SaveEpisodesToFile([]files.EpisodeStruct{{AnimeID: 7, AnimeName: "Frieren", EpisodeNumber: 1, EpisodeHash: "old"}}); err != nil {
+		t.Fatalf("SaveEpisodesToFile: %v", err)
+	}
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted("old", "/dl/old")
+	backend.SetTraffic("old", 5000, 800)
+	// Before the fake's Add, which stamps AddedAt with the real clock.
+	now := time.Now().Add(-time.Hour)
+
+	sampleDataUsage(fm, backend, now)
+	ledger, _ := fm.LoadDataUsage()
+	if len(ledger.Days) != 0 {
+		t.Fatalf("the baseline sample booked traffic: %+v", ledger.Days)
+	}
+
+	backend.SetTraffic("old", 5100, 1000)
+	hash, _ := backend.Add("magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567")
+	backend.SetTraffic(hash, 300, 0)
+	sampleDataUsage(fm, backend, now.Add(time.Minute))
+
+	ledger, _ = fm.LoadDataUsage()
+	day := ledger.Days[now.Add(time.Minute).Format(files.DataUsageDateLayout)]
+	if want := (files.ByteCounts{Downloaded: 400, Uploaded: 200}); day.ByteCounts != want {
+		t.Errorf("day total = %+v, want %+v", day.ByteCounts, want)
+	}
+	if want := (files.ByteCounts{Downloaded: 100, Uploaded: 200}); day.Animes[7] != want {
+		t.Errorf("Frieren = %+v, want %+v (the new torrent has no anime)", day.Animes[7], want)
+	}
+	if ledger.AnimeNames[7] != "Frieren" {
+		t.Errorf("AnimeNames = %v", ledger.AnimeNames)
+	}
+}
+
+// A counter that went backwards (resume data older than the last sample) books nothing and
+// becomes the new starting point.
+func TestSampleDataUsageCounterReset(t *testing.T) {
+	fm := tempFileManager(t)
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted("h", "/dl/h")
+	backend.SetTraffic("h", 1000, 1000)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	sampleDataUsage(fm, backend, now)
+
+	backend.SetTraffic("h", 900, 1000)
+	sampleDataUsage(fm, backend, now.Add(time.Minute))
+	backend.SetTraffic("h", 950, 1000)
+	sampleDataUsage(fm, backend, now.Add(2*time.Minute))
+
+	ledger, _ := fm.LoadDataUsage()
+	if got := ledger.Days["2026-10-18"].ByteCounts; got != (files.ByteCounts{Downloaded: 50}) {
+		t.Errorf("day total = %+v, want only the 50 bytes after the reset", got)
+	}
+}
+
+func TestBillingPeriod(t *testing.T) {
+	tests := []struct {
+		now        time.Time
+		day        int
+		start, end string
+	}{
+		{time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local), 1, "2026-10-01", "2026-11-01"},
+		{time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local), 20, "2026-09-20", "2026-10-20"},
+		{time.Date(2026, 1, 5, 12, 0, 0, 0, time.Local), 15, "2025-12-15", "2026-01-15"},
+		{time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local), 31, "2026-10-01", "2026-11-01"},
+	}
+	for _, tt := range tests {
+		start, end := BillingPeriod(tt.now, tt.day)
+		if got := start.Format(files.DataUsageDateLayout); got != tt.start {
+			t.Errorf("BillingPeriod(%v, %d) start = %s, want %s", tt.now, tt.day, got, tt.start)
+		}
+		if got := end.Format(files.DataUsageDateLayout); got != tt.end {
+			t.Errorf("BillingPeriod(%v, %d) end = %s, want %s", tt.now, tt.day, got, tt.end)
+		}
+	}
+}
+
+// The cap holds the backend once the period's traffic reaches it, blocks new downloads, and
+// lets go when the cap is raised.
+func TestApplyDataCap(t *testing.T) {
+	fm := tempFileManager(t)
+	today := time.Now().Format(files.DataUsageDateLayout)
+	if err := fm.SaveDataUsage(&files.DataUsageLedger{
+		Days:      map[string]files.DayUsage{today: {ByteCounts: files.ByteCounts{Downloaded: 1 << 30, Uploaded: 1 << 29}}},
+		Counters:  map[string]files.ByteCounts{},
+		SampledAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("SaveDataUsage: %v", err)
+	}
+	backend := torrents.NewFakeBackend()
+	configs := &files.Config{DataCapGB: 1.5, DataCapBillingDay: 1}
+
+	if !ApplyDataCap(fm, backend, configs) || !backend.DataCapped {
+		t.Fatal("expected the cap to be reached at exactly 1.5 GB")
+	}
+	if err := checkDataCap(backend, configs); !errors.Is(err, ErrDataCapReached) {
+		t.Errorf("checkDataCap = %v, want ErrDataCapReached", err)
+	}
+	if hash := attemptDownloadWithRetries(&files.Config{EpisodeRetryLimit: 3, DataCapGB: 1.5}, backend, []string{"magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567"}, "ep"); hash != "" || len(backend.List()) != 0 {
+		t.Errorf("a download was added over the data cap (hash %q)", hash)
+	}
+
+	configs.DataCapGB = 2
+	if ApplyDataCap(fm, backend, configs) || backend.DataCapped {
+		t.Error("raising the cap above the usage must lift it")
+	}
+}
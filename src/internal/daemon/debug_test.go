@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"AutoAnimeDownloader/src/internal/anilist"
 	"AutoAnimeDownloader/src/internal/files"
@@ -44,6 +45,42 @@ func (m *debugMockFileManager) SaveAnimeSettings(id int, s files.AnimeSettings)
 func (m *debugMockFileManager) LoadStandaloneAnimes() ([]int, error)                  { return nil, nil }
 func (m *debugMockFileManager) AddStandaloneAnime(int) error                          { return nil }
 func (m *debugMockFileManager) RemoveStandaloneAnime(int) error                       { return nil }
+func (m *debugMockFileManager) LoadTrackersList() ([]string, string, time.Time, error) {
+	return nil, "", time.Time{}, nil
+}
+func (m *debugMockFileManager) SaveTrackersList(string, []string) error { return nil }
+func (m *debugMockFileManager) LoadIntegrityChecks() (map[string]time.Time, error) {
+	return map[string]time.Time{}, nil
+}
+func (m *debugMockFileManager) SaveIntegrityChecks(map[string]time.Time) error { return nil }
+func (m *debugMockFileManager) LoadDataUsage() (*files.DataUsageLedger, error) {
+	return &files.DataUsageLedger{}, nil
+}
+func (m *debugMockFileManager) SaveDataUsage(*files.DataUsageLedger) error { return nil }
+func (m *debugMockFileManager) LoadArtworkSources() (map[int]files.ArtworkSource, error) {
+	return map[int]files.ArtworkSource{}, nil
+}
+func (m *debugMockFileManager) SaveArtworkSources(map[int]files.ArtworkSource) error { return nil }
+func (m *debugMockFileManager) LoadAnilistTokens() (map[string]files.AnilistToken, error) {
+	return map[string]files.AnilistToken{}, nil
+}
+func (m *debugMockFileManager) SaveAnilistTokens(map[string]files.AnilistToken) error { return nil }
+func (m *debugMockFileManager) IDMappingSavedAt() (time.Time, error)                  { return time.Time{}, nil }
+func (m *debugMockFileManager) LoadIDMapping() ([]byte, time.Time, error) {
+	return nil, time.Time{}, nil
+}
+func (m *debugMockFileManager) SaveIDMapping([]byte) error        { return nil }
+func (m *debugMockFileManager) LoadAniListCache() ([]byte, error) { return nil, nil }
+func (m *debugMockFileManager) SaveAniListCache([]byte) error     { return nil }
+func (m *debugMockFileManager) LoadTrials() (map[int]files.Trial, error) {
+	return map[int]files.Trial{}, nil
+}
+func (m *debugMockFileManager) UpdateTrials(func(map[int]files.Trial) bool) error { return nil }
+func (m *debugMockFileManager) LoadListSnapshot() (files.ListSnapshot, error) {
+	return files.ListSnapshot{}, nil
+}
+func (m *debugMockFileManager) SaveListSnapshot(files.ListSnapshot) error  { return nil }
+func (m *debugMockFileManager) AppendListChanges([]files.ListChange) error { return nil }
 
 func TestRunAnimeDebug_NoNyaaResults_NoError(t *testing.T) {
 	anilistJSON := `{"data": {"Page": {"mediaList": [{"id": 1, "status": "CURRENT", "progress": 0, "media": {
@@ -10,14 +10,18 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"time"
 )
 
 type handleEpisodesData struct {
-	savedEpisodes   []files.EpisodeStruct
-	keysToDelete    []files.EpisodeKey
-	checkedEpisodes []files.EpisodeKey
-	newEpisodes     []files.EpisodeStruct
+	savedEpisodes []files.EpisodeStruct
+	keysToDelete  []files.EpisodeKey
+	// profileKeysToDelete vem de animes cuja config de perfil ja aprovou a delecao (ver
+	// configsForAnime): apagados mesmo com DeleteWatchedEpisodes global desligado.
+	profileKeysToDelete []files.EpisodeKey
+	checkedEpisodes     []files.EpisodeKey
+	newEpisodes         []files.EpisodeStruct
 }
 
 // episodeSelection e o resultado do laco de selecao de um anime.
@@ -104,6 +108,7 @@ func processAnimeEpisodes(
 	savedEpisodes []files.EpisodeStruct,
 	blockedMap map[files.EpisodeKey]bool,
 	customQuery string,
+	trialEpisodes int,
 	searcher nyaaSearcher,
 ) animeProcessResult {
 	var result animeProcessResult
@@ -117,6 +122,11 @@ func processAnimeEpisodes(
 	savedEpisodesFullMap := buildSavedEpisodesFullMap(savedEpisodes)
 
 	episodes := anilist.EpisodeList(anime, firstEpisodeToConsider(anime, savedEpisodes))
+	// Trial (ver autoSubscribe): para o passe, o anime so tem os primeiros N episodios. Cortar a
+	// lista aqui faz o resto do caminho — selecao, poda, relatorio — valer sem nenhum "if trial".
+	if trialEpisodes > 0 {
+		episodes = slices.DeleteFunc(episodes, func(ep anilist.AiringNode) bool { return ep.Episode > trialEpisodes })
+	}
 	keepSet := buildWatchedKeepSet(configs.WatchedEpisodesToKeep, anime.Media.Id, episodes, savedEpisodesFullMap, anime.Progress)
 
 	totalEpisodes := 0
@@ -139,12 +149,14 @@ func processAnimeEpisodes(
 	}
 
 	if magnetsForEpisodes == nil && len(sel.toDownload) > 0 {
-		packs, singles, packStats := partitionSearchResults(configs, searcher.searchAnime(anime.Media.Title, anime.Media.Synonyms, episodeNumbers(sel.toDownload), customQuery))
+		packs, singles, packStats := partitionSearchResults(configs, searcher.searchAnime(anime.Media.Id, anime.Media.Title, anime.Media.Synonyms, episodeNumbers(sel.toDownload), customQuery))
 
 		// Elegibilidade a pack: nao e filme, tem mais de um episodio pendente e a busca FILTRADA
 		// devolveu pack que cobre a janela. Nada disso e metadado do AniList — e o torrent que
 		// esta la que decide (ver decisions.md).
-		if !isAnimeMovie(anime) && len(sel.toDownload) > 1 {
+		// Trial nunca usa pack: o pack da temporada traria os episodios que o trial existe para
+		// nao baixar.
+		if !isAnimeMovie(anime) && len(sel.toDownload) > 1 && trialEpisodes == 0 {
 			firstPending := sel.toDownload[0].Episode
 			batches := pickBatches(packs, firstPending, windowEnd(configs, firstPending))
 			switch {
@@ -213,7 +225,7 @@ func processAnimeEpisodes(
 		var searchStats dropStats
 		if len(magnets) == 0 {
 			var singleResults []nyaa.TorrentResult
-			singleResults, searchStats = filterSearchResults(searcher.searchSingleEpisode(ep, anime.Media.Title, anime.Media.Synonyms, anime.Media.Relations, customQuery, seriesLength), configs.MaxEpisodeTorrentSizeGB, configs.MinSeeders)
+			singleResults, searchStats = filterSearchResults(searcher.searchSingleEpisode(anime.Media.Id, ep, anime.Media.Title, anime.Media.Synonyms, anime.Media.Relations, customQuery, seriesLength), configs.MaxEpisodeTorrentSizeGB, configs.MinSeeders)
 			for _, tr := range singleResults {
 				magnets = append(magnets, tr.MagnetLink)
 			}
@@ -246,6 +258,7 @@ func processAnimeEpisodes(
 				EpisodeNumber:      ep.Episode,
 				IsBatch:            skipSubfolder,
 				DownloadDate:       time.Now(),
+				Meta:               animeMeta(anime),
 			})
 			// Completion is handled event-driven: the session's onComplete callback (and
 			// the reconciliation pass as a safety net) enqueue JobOrganize, which hardlinks
@@ -259,12 +272,18 @@ func processAnimeEpisodes(
 				Code:       IssueTorrentRejected,
 				Candidates: len(magnets),
 			}
-			if errors.Is(checkDiskSpace(configs), ErrInsufficientDiskSpace) {
+			if errors.Is(checkDiskSpace(backend, configs), ErrInsufficientDiskSpace) {
 				reason = notifications.ReasonNoDiskSpace
 				issue.Code = IssueDiskFull
 				// Disco cheio nao e sobre os magnets: nenhum foi tentado (attemptDownloadWithRetries
 				// sai antes do primeiro Add), entao "N candidatos" seria numero sem significado.
 				issue.Candidates = 0
+			} else if errors.Is(checkDataCap(backend, configs), ErrDataCapReached) {
+				// Mesma ordem das guardas em attemptDownloadWithRetries, e pelo mesmo motivo zera
+				// os candidatos.
+				reason = notifications.ReasonDataCapReached
+				issue.Code = IssueDataCapReached
+				issue.Candidates = 0
 			}
 			result.issues = append(result.issues, issue)
 			// O batch de notificacoes (BatchWindowSeconds) junta os N episodios do passe numa
@@ -290,7 +309,7 @@ func resolveMovie(configs *files.Config, anime anilist.MediaList, animeTitle str
 		Str("anime", animeTitle).
 		Msg("Detected movie - searching for movie torrent")
 
-	movieResult, _ := filterSearchResults(searcher.searchMovie(anime.Media.Title, true, customQuery), configs.MaxEpisodeTorrentSizeGB, configs.MinSeeders)
+	movieResult, _ := filterSearchResults(searcher.searchMovie(anime.Media.Id, anime.Media.Title, true, customQuery), configs.MaxEpisodeTorrentSizeGB, configs.MinSeeders)
 	if len(movieResult) == 0 {
 		return episodes, nil
 	}
@@ -599,10 +618,14 @@ func handleAlreadySavedEpisode(maxEpisodes int, downloadedEpisodes *int, isInTor
 func attemptDownloadWithRetries(configs *files.Config, backend torrents.TorrentBackend, magnets []string, fileName string) (hash string) {
 	// Disco cheio: nem um magnet e tentado e nao ha retry — o magnets[i] nao e o problema, e
 	// tentar 3 vezes so encheria o log.
-	if err := checkDiskSpace(configs); err != nil {
+	if err := checkDiskSpace(backend, configs); err != nil {
 		logger.Logger.Warn().Err(err).Str("episode", fileName).Msg("Skipping download: insufficient free disk space")
 		return ""
 	}
+	if err := checkDataCap(backend, configs); err != nil {
+		logger.Logger.Warn().Err(err).Str("episode", fileName).Msg("Skipping download: monthly data cap reached")
+		return ""
+	}
 
 	maxAttempts := min(configs.EpisodeRetryLimit, len(magnets))
 
@@ -743,12 +766,13 @@ func handleSavedEpisodes(fileManager FileManagerInterface, configs *files.Config
 
 	saveEpisodesToFile(fileManager, data.newEpisodes)
 
+	allKeys := append([]files.EpisodeKey{}, data.profileKeysToDelete...)
 	if configs.DeleteWatchedEpisodes {
-		allKeys := append(append([]files.EpisodeKey{}, data.keysToDelete...), episodesNotInWatching...)
-		// Best-effort: a failure here must not abort the verification pass.
-		if err := removeEpisodesAndLinks(fileManager, backend, librarian, allKeys, data.savedEpisodes, false); err != nil {
-			logger.Logger.Warn().Err(err).Msg("Failed to delete episodes from file")
-		}
+		allKeys = append(append(allKeys, data.keysToDelete...), episodesNotInWatching...)
+	}
+	// Best-effort: a failure here must not abort the verification pass.
+	if err := removeEpisodesAndLinks(fileManager, backend, librarian, allKeys, data.savedEpisodes, false); err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to delete episodes from file")
 	}
 }
 
@@ -783,6 +807,7 @@ func removeEpisodesAndLinks(fm FileManagerInterface, backend torrents.TorrentBac
 
 	// Remove library hardlinks (skipped when keepData: keeping the library copy while the
 	// torrent is removed anyway does not double as "keep everything" since it's the same inode).
+	var removed []string
 	if !keepData {
 		for _, ep := range savedEpisodes {
 			if !deleteSet[ep.Key()] {
@@ -797,7 +822,9 @@ func removeEpisodesAndLinks(fm FileManagerInterface, backend torrents.TorrentBac
 			for _, p := range ep.LibraryPaths {
 				if err := librarian.RemoveFromLibrary(p); err != nil {
 					logger.Logger.Warn().Err(err).Str("path", p).Msg("Failed to remove library hardlink")
+					continue
 				}
+				removed = append(removed, p)
 			}
 		}
 	}
@@ -822,6 +849,10 @@ func removeEpisodesAndLinks(fm FileManagerInterface, backend torrents.TorrentBac
 		return fmt.Errorf("failed to delete episodes from file: %w", err)
 	}
 
+	// O servidor de midia so tira o episodio da biblioteca dele no proximo scan.
+	if len(removed) > 0 {
+		requestMediaScan(libraryShowDirs(removed))
+	}
 	return nil
 }
 
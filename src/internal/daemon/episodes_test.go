@@ -37,6 +37,13 @@ type mockFileManagerForEpisodes struct {
 	removedStandalone  []int
 	savedEpisodes      []files.EpisodeStruct
 	settings           map[int]files.AnimeSettings
+	anilistTokens      map[string]files.AnilistToken
+	idMapping          []byte
+	idMappingSavedAt   time.Time
+	anilistCache       []byte
+	trials             map[int]files.Trial
+	listSnapshot       files.ListSnapshot
+	listChanges        []files.ListChange
 }
 
 func (m *mockFileManagerForEpisodes) LoadConfigs() (*files.Config, error) { return nil, nil }
@@ -88,6 +95,76 @@ func (m *mockFileManagerForEpisodes) RemoveStandaloneAnime(id int) error {
 	m.standaloneAnimes = kept
 	return nil
 }
+func (m *mockFileManagerForEpisodes) LoadTrackersList() ([]string, string, time.Time, error) {
+	return nil, "", time.Time{}, nil
+}
+func (m *mockFileManagerForEpisodes) SaveTrackersList(string, []string) error { return nil }
+func (m *mockFileManagerForEpisodes) LoadIntegrityChecks() (map[string]time.Time, error) {
+	return map[string]time.Time{}, nil
+}
+func (m *mockFileManagerForEpisodes) SaveIntegrityChecks(map[string]time.Time) error { return nil }
+func (m *mockFileManagerForEpisodes) LoadDataUsage() (*files.DataUsageLedger, error) {
+	return &files.DataUsageLedger{}, nil
+}
+func (m *mockFileManagerForEpisodes) SaveDataUsage(*files.DataUsageLedger) error { return nil }
+func (m *mockFileManagerForEpisodes) LoadArtworkSources() (map[int]files.ArtworkSource, error) {
+	return map[int]files.ArtworkSource{}, nil
+}
+func (m *mockFileManagerForEpisodes) SaveArtworkSources(map[int]files.ArtworkSource) error {
+	return nil
+}
+func (m *mockFileManagerForEpisodes) LoadAnilistTokens() (map[string]files.AnilistToken, error) {
+	return m.anilistTokens, nil
+}
+func (m *mockFileManagerForEpisodes) SaveAnilistTokens(tokens map[string]files.AnilistToken) error {
+	m.anilistTokens = tokens
+	return nil
+}
+func (m *mockFileManagerForEpisodes) IDMappingSavedAt() (time.Time, error) {
+	return m.idMappingSavedAt, nil
+}
+func (m *mockFileManagerForEpisodes) LoadIDMapping() ([]byte, time.Time, error) {
+	return m.idMapping, m.idMappingSavedAt, nil
+}
+func (m *mockFileManagerForEpisodes) SaveIDMapping(data []byte) error {
+	m.idMapping = data
+	m.idMappingSavedAt = time.Now()
+	return nil
+}
+func (m *mockFileManagerForEpisodes) LoadAniListCache() ([]byte, error) {
+	return m.anilistCache, nil
+}
+func (m *mockFileManagerForEpisodes) SaveAniListCache(data []byte) error {
+	m.anilistCache = data
+	return nil
+}
+func (m *mockFileManagerForEpisodes) LoadTrials() (map[int]files.Trial, error) {
+	if m.trials == nil {
+		return map[int]files.Trial{}, nil
+	}
+	return m.trials, nil
+}
+func (m *mockFileManagerForEpisodes) UpdateTrials(fn func(map[int]files.Trial) bool) error {
+	if m.trials == nil {
+		m.trials = map[int]files.Trial{}
+	}
+	fn(m.trials)
+	return nil
+}
+func (m *mockFileManagerForEpisodes) LoadListSnapshot() (files.ListSnapshot, error) {
+	if m.listSnapshot == nil {
+		return files.ListSnapshot{}, nil
+	}
+	return m.listSnapshot, nil
+}
+func (m *mockFileManagerForEpisodes) SaveListSnapshot(snapshot files.ListSnapshot) error {
+	m.listSnapshot = snapshot
+	return nil
+}
+func (m *mockFileManagerForEpisodes) AppendListChanges(changes []files.ListChange) error {
+	m.listChanges = append(m.listChanges, changes...)
+	return nil
+}
 
 func containsHash(hashes []string, target string) bool {
 	for _, h := range hashes {
@@ -325,7 +402,7 @@ func TestProcessAnimeEpisodes_BlacklistedAnime_PopulatesKeysToDelete(t *testing.
 
 	backend := torrents.NewFakeBackend()
 
-	result := processAnimeEpisodes(configs, backend, anime, nil, savedEpisodes, map[files.EpisodeKey]bool{}, "", defaultNyaaSearcher())
+	result := processAnimeEpisodes(configs, backend, anime, nil, savedEpisodes, map[files.EpisodeKey]bool{}, "", 0, defaultNyaaSearcher())
 
 	if !containsID(result.keysToDelete, epKey(animeID, episodeNumber)) {
 		t.Errorf("esperava episódio %d em keysToDelete, obteve %v", episodeNumber, result.keysToDelete)
@@ -424,14 +501,14 @@ func TestProcessAnimeEpisodes_BatchNoRedownload(t *testing.T) {
 	// Mock do Nyaa: se a busca por anime for chamada, o teste deve falhar
 	searchAnimeCalled := false
 	mockSearcher := nyaaSearcher{
-		searchAnime: func(_ anilist.Title, _ []string, _ []int, _ string) []nyaa.TorrentResult {
+		searchAnime: func(_ int, _ anilist.Title, _ []string, _ []int, _ string) []nyaa.TorrentResult {
 			searchAnimeCalled = true
 			return []nyaa.TorrentResult{{MagnetLink: "magnet:?xt=urn:btih:fakehash", IsBatch: true}}
 		},
-		searchSingleEpisode: func(_ anilist.AiringNode, _ anilist.Title, _ []string, _ anilist.MediaRelations, _ string, _ int) []nyaa.TorrentResult {
+		searchSingleEpisode: func(_ int, _ anilist.AiringNode, _ anilist.Title, _ []string, _ anilist.MediaRelations, _ string, _ int) []nyaa.TorrentResult {
 			return nil
 		},
-		searchMovie: func(_ anilist.Title, _ bool, _ string) []nyaa.TorrentResult {
+		searchMovie: func(_ int, _ anilist.Title, _ bool, _ string) []nyaa.TorrentResult {
 			return nil
 		},
 	}
@@ -443,7 +520,7 @@ func TestProcessAnimeEpisodes_BatchNoRedownload(t *testing.T) {
 
 	backend := torrents.NewFakeBackend()
 
-	result := processAnimeEpisodes(configs, backend, anime, dlTorrents, savedEpisodes, map[files.EpisodeKey]bool{}, "", mockSearcher)
+	result := processAnimeEpisodes(configs, backend, anime, dlTorrents, savedEpisodes, map[files.EpisodeKey]bool{}, "", 0, mockSearcher)
 
 	if searchAnimeCalled {
 		t.Error("a busca por anime não deve ser chamada: todos os episódios já estão no cliente pelo hash")
@@ -888,7 +965,19 @@ func (s *spyLibrarian) RemoveFromLibrary(string) error {
 	s.called = true
 	return nil
 }
-func (s *spyLibrarian) ProbePath(string) error { return nil }
+func (s *spyLibrarian) ProbePath(string, files.LinkMode) error               { return nil }
+func (s *spyLibrarian) ProbeLinkModes(string) ([]files.LinkMode, error)      { return files.LinkModes, nil }
+func (s *spyLibrarian) ProbeMoviesPath(string, string, files.LinkMode) error { return nil }
+func (s *spyLibrarian) MoveInLibrary(string, string) error                   { return nil }
+func (s *spyLibrarian) EnsureShowNFO(string, string, int)                    {}
+func (s *spyLibrarian) MissingNFOs([]files.LibraryMove) bool                 { return false }
+func (s *spyLibrarian) WriteNFOs([]files.LibraryMove, *files.NFOInfo)        {}
+func (s *spyLibrarian) HasArtwork(string) bool                               { return false }
+func (s *spyLibrarian) SaveArtwork(string, []byte) error                     { return nil }
+func (s *spyLibrarian) AuditLibrary(files.AuditRequest) ([]files.AuditFinding, error) {
+	return nil, nil
+}
+func (s *spyLibrarian) RemoveOrphan(string, string) error { return nil }
 
 // TestRemoveTorrentWithEpisodes_OrphanTorrentCallsBackendOnly verifica o caso de torrent órfão
 // (nenhum episódio salvo casa com o hash): backend.Remove é chamado, nada é bloqueado, sem erro.
@@ -979,11 +1068,11 @@ func TestProcessAnimeEpisodes_NoMagnets_SkipsNewEpisodeWebhook(t *testing.T) {
 	}
 
 	noResults := nyaaSearcher{
-		searchAnime: func(anilist.Title, []string, []int, string) []nyaa.TorrentResult { return nil },
-		searchSingleEpisode: func(anilist.AiringNode, anilist.Title, []string, anilist.MediaRelations, string, int) []nyaa.TorrentResult {
+		searchAnime: func(int, anilist.Title, []string, []int, string) []nyaa.TorrentResult { return nil },
+		searchSingleEpisode: func(int, anilist.AiringNode, anilist.Title, []string, anilist.MediaRelations, string, int) []nyaa.TorrentResult {
 			return nil
 		},
-		searchMovie: func(anilist.Title, bool, string) []nyaa.TorrentResult { return nil },
+		searchMovie: func(int, anilist.Title, bool, string) []nyaa.TorrentResult { return nil },
 	}
 
 	configs := &files.Config{
@@ -999,7 +1088,7 @@ func TestProcessAnimeEpisodes_NoMagnets_SkipsNewEpisodeWebhook(t *testing.T) {
 	}
 
 	backend := torrents.NewFakeBackend()
-	result := processAnimeEpisodes(configs, backend, anime, nil, nil, map[files.EpisodeKey]bool{}, "", noResults)
+	result := processAnimeEpisodes(configs, backend, anime, nil, nil, map[files.EpisodeKey]bool{}, "", 0, noResults)
 
 	if len(result.newEpisodes) > 0 {
 		t.Errorf("nenhum episódio deve ser salvo sem magnet, obteve %d", len(result.newEpisodes))
@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"time"
 )
 
 type FileManagerInterface interface {
@@ -32,6 +33,26 @@ type FileManagerInterface interface {
 	LoadStandaloneAnimes() ([]int, error)
 	AddStandaloneAnime(mediaID int) error
 	RemoveStandaloneAnime(mediaID int) error
+	LoadTrackersList() (trackers []string, source string, savedAt time.Time, err error)
+	SaveTrackersList(source string, trackers []string) error
+	LoadIntegrityChecks() (map[string]time.Time, error)
+	SaveIntegrityChecks(checks map[string]time.Time) error
+	LoadDataUsage() (*files.DataUsageLedger, error)
+	SaveDataUsage(ledger *files.DataUsageLedger) error
+	LoadArtworkSources() (map[int]files.ArtworkSource, error)
+	SaveArtworkSources(sources map[int]files.ArtworkSource) error
+	LoadAnilistTokens() (map[string]files.AnilistToken, error)
+	SaveAnilistTokens(tokens map[string]files.AnilistToken) error
+	IDMappingSavedAt() (time.Time, error)
+	LoadIDMapping() ([]byte, time.Time, error)
+	SaveIDMapping(data []byte) error
+	LoadAniListCache() ([]byte, error)
+	SaveAniListCache(data []byte) error
+	LoadTrials() (map[int]files.Trial, error)
+	UpdateTrials(fn func(trials map[int]files.Trial) bool) error
+	LoadListSnapshot() (files.ListSnapshot, error)
+	SaveListSnapshot(snapshot files.ListSnapshot) error
+	AppendListChanges(changes []files.ListChange) error
 }
 
 // ErrInsufficientDiskSpace e devolvido por checkDiskSpace quando o volume da biblioteca esta
@@ -46,7 +67,11 @@ var ErrInsufficientDiskSpace = errors.New("insufficient free disk space")
 //
 // Erro de statfs NAO bloqueia: um volume que nao responde (rede, permissao) nao e prova de disco
 // cheio, e transformar isso em "para de baixar tudo" e pior que o risco que a guarda cobre.
-func checkDiskSpace(configs *files.Config) error {
+//
+// Com library_link_mode copy, cada torrent ainda baixando vai ocupar o tamanho dele uma segunda
+// vez quando o organize o copiar para a biblioteca. Esses bytes saem do espaco livre antes da
+// conta: sem isso a guarda so veria o disco encher depois que as copias ja estivessem feitas.
+func checkDiskSpace(backend torrents.TorrentBackend, configs *files.Config) error {
 	if configs.MinFreeDiskPercent <= 0 || configs.CompletedAnimePath == "" {
 		return nil
 	}
@@ -59,12 +84,36 @@ func checkDiskSpace(configs *files.Config) error {
 	if total == 0 {
 		return nil
 	}
+	if configs.LinkMode().Duplicates() {
+		pending := pendingCopyBytes(backend)
+		if pending >= free {
+			free = 0
+		} else {
+			free -= pending
+		}
+	}
 	if float64(free)/float64(total)*100 < float64(configs.MinFreeDiskPercent) {
 		return fmt.Errorf("%w: %d%% free required on %s", ErrInsufficientDiskSpace, configs.MinFreeDiskPercent, configs.CompletedAnimePath)
 	}
 	return nil
 }
 
+// pendingCopyBytes soma o tamanho dos torrents que ainda nao terminaram: e o que o organize vai
+// copiar para a biblioteca. Um torrent completo ja foi copiado (o organize roda na conclusao), e
+// um sem metadados ainda reporta 0.
+func pendingCopyBytes(backend torrents.TorrentBackend) uint64 {
+	if backend == nil {
+		return 0
+	}
+	var pending uint64
+	for _, t := range backend.List() {
+		if !t.Completed && t.BytesTotal > 0 {
+			pending += uint64(t.BytesTotal)
+		}
+	}
+	return pending
+}
+
 // HandleTorrentFailure reacts to a torrent the embedded client stopped with an error.
 //
 // rain leaves a failed torrent Stopped *inside* the session and never restarts it, and the
@@ -0,0 +1,118 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/idmap"
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// idMappingMaxAge e de quanto em quanto tempo o dataset de Config.IDMappingURL e baixado de
+// novo. O anime-offline-database sai uma vez por semana; baixar mais que isso so gasta dezenas
+// de MB de quem hospeda.
+const idMappingMaxAge = 7 * 24 * time.Hour
+
+// idMappingMaxBytes limita o corpo lido da URL. O dataset completo passa de 40 MB e cresce;
+// o teto so existe para uma URL errada nao encher a memoria do daemon.
+const idMappingMaxBytes = 256 << 20
+
+var idMappingClient = &http.Client{Timeout: 5 * time.Minute}
+
+// ErrNoIDMapping e o refresh sem URL configurada e sem arquivo na pasta do config.
+var ErrNoIDMapping = errors.New("no id mapping dataset: set id_mapping_url or put anime-offline-database.json in the config folder")
+
+// refreshIDMapping baixa o dataset quando o salvo tem mais de idMappingMaxAge e carrega o
+// arquivo em memoria quando ele e mais novo que o indice. Falha so e logada, como a lista de
+// trackers: o dataset anterior continua valendo, e o mapeamento nunca e motivo para abortar o
+// passe.
+func refreshIDMapping(fm FileManagerInterface, configs *files.Config) {
+	savedAt, err := fm.IDMappingSavedAt()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to stat the saved id mapping dataset")
+		return
+	}
+	if configs.IDMappingURL != "" && (savedAt.IsZero() || time.Since(savedAt) >= idMappingMaxAge) {
+		if err := downloadIDMapping(fm, configs.IDMappingURL); err != nil {
+			logger.Logger.Warn().Err(err).Str("url", configs.IDMappingURL).Msg("Failed to fetch the id mapping dataset; keeping the saved one")
+		}
+	}
+	if err := loadIDMapping(fm, false); err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to load the id mapping dataset")
+	}
+}
+
+// RefreshIDMapping is the on-demand refresh: downloads the dataset from id_mapping_url (when
+// set) regardless of its age, then reloads the file into memory. Without a URL it just
+// re-reads the file, for a dataset dropped in the config folder by hand.
+func RefreshIDMapping(fm FileManagerInterface, configs *files.Config) (idmap.Status, error) {
+	if configs.IDMappingURL != "" {
+		if err := downloadIDMapping(fm, configs.IDMappingURL); err != nil {
+			return idmap.CurrentStatus(), err
+		}
+	}
+	if err := loadIDMapping(fm, true); err != nil {
+		return idmap.CurrentStatus(), err
+	}
+	return idmap.CurrentStatus(), nil
+}
+
+// downloadIDMapping baixa e so grava o que o idmap consegue ler: uma pagina de erro do GitHub
+// salva no lugar do dataset apagaria o ultimo bom.
+func downloadIDMapping(fm FileManagerInterface, url string) error {
+	resp, err := idMappingClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch id mapping dataset: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch id mapping dataset: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, idMappingMaxBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read id mapping dataset: %w", err)
+	}
+	if _, err := idmap.Parse(data); err != nil {
+		return err
+	}
+	if err := fm.SaveIDMapping(data); err != nil {
+		return err
+	}
+	logger.Logger.Info().Int("bytes", len(data)).Str("url", url).Msg("Id mapping dataset downloaded")
+	return nil
+}
+
+// loadIDMapping le o arquivo para o indice em memoria. Sem force, um arquivo com a mesma hora
+// do indice carregado nao e lido de novo: sao dezenas de MB a cada passe por nada.
+func loadIDMapping(fm FileManagerInterface, force bool) error {
+	savedAt, err := fm.IDMappingSavedAt()
+	if err != nil {
+		return err
+	}
+	if savedAt.IsZero() {
+		if force {
+			return ErrNoIDMapping
+		}
+		return nil
+	}
+	if st := idmap.CurrentStatus(); !force && st.Loaded && st.SavedAt.Equal(savedAt) {
+		return nil
+	}
+	data, savedAt, err := fm.LoadIDMapping()
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return ErrNoIDMapping
+	}
+	if err := idmap.Load(data, savedAt); err != nil {
+		return err
+	}
+	st := idmap.CurrentStatus()
+	logger.Logger.Info().Int("entries", st.Entries).Str("dataset_date", st.DatasetDate).Msg("Id mapping dataset loaded")
+	return nil
+}
@@ -0,0 +1,119 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/idmap"
+)
+
+const idMappingDataset = `{
+	"lastUpdate": "2026-10-12",
+	"data": [{
+		"sources": ["https://anilist.co/anime/154587", "https://myanimelist.net/anime/52991", "https://anidb.net/anime/17617"],
+		"title": "Sousou no Frieren",
+		"synonyms": ["SnF", "Frieren: Beyond Journey's End", "Frieren - Nach dem Ende der Reise", "Frieren: Remnants of the Departed", "Frieren Extra"]
+	}]
+}`
+
+// A fresh dataset is downloaded once, loaded into memory, and not fetched again on the next pass.
+func TestRefreshIDMappingDownloadsAndLoads(t *testing.T) {
+	t.Cleanup(idmap.Reset)
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		fmt.Fprint(w, idMappingDataset)
+	}))
+	defer srv.Close()
+	fm := tempFileManager(t)
+	configs := &files.Config{IDMappingURL: srv.URL}
+
+	refreshIDMapping(fm, configs)
+	refreshIDMapping(fm, configs)
+
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("dataset fetched %d times, want 1 (the saved one is fresh)", got)
+	}
+	e, ok := idmap.ByMyAnimeList(52991)
+	if !ok || e.AniList != 154587 || e.AniDB != 17617 {
+		t.Errorf("ByMyAnimeList(52991) = %+v, %v", e, ok)
+	}
+
+	// O refresh manual baixa de novo mesmo com o arquivo fresco.
+	st, err := RefreshIDMapping(fm, configs)
+	if err != nil || !st.Loaded || st.Entries != 1 || st.DatasetDate != "2026-10-12" {
+		t.Errorf("RefreshIDMapping() = %+v, %v", st, err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Errorf("dataset fetched %d times after the manual refresh, want 2", got)
+	}
+}
+
+// Something that is not the dataset (an HTML error page served with 200) must not overwrite
+// the saved file nor the index in memory.
+func TestRefreshIDMappingKeepsDatasetOnBadDownload(t *testing.T) {
+	t.Cleanup(idmap.Reset)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html>rate limited</html>")
+	}))
+	defer srv.Close()
+	fm := tempFileManager(t)
+	if err := fm.SaveIDMapping([]byte(idMappingDataset)); err != nil {
+		t.Fatalf("SaveIDMapping: %v", err)
+	}
+	configs := &files.Config{IDMappingURL: srv.URL}
+
+	if _, err := RefreshIDMapping(fm, configs); err == nil {
+		t.Fatal("want an error for a download that is not the dataset")
+	}
+	if data, _, _ := fm.LoadIDMapping(); string(data) != idMappingDataset {
+		t.Error("a bad download replaced the saved dataset")
+	}
+
+	// Sem URL, o refresh so rele o arquivo que ja esta na pasta.
+	if _, err := RefreshIDMapping(fm, &files.Config{}); err != nil {
+		t.Fatalf("RefreshIDMapping without URL: %v", err)
+	}
+	if _, ok := idmap.ByAniList(154587); !ok {
+		t.Error("the saved dataset was not loaded")
+	}
+}
+
+func TestRefreshIDMappingWithoutDataset(t *testing.T) {
+	t.Cleanup(idmap.Reset)
+	if _, err := RefreshIDMapping(tempFileManager(t), &files.Config{}); err != ErrNoIDMapping {
+		t.Errorf("RefreshIDMapping() error = %v, want ErrNoIDMapping", err)
+	}
+}
+
+// The dataset titles come after the AniList ones, cleaned, without abbreviations or repeats,
+// and capped; a custom query stays the only variant.
+func TestBuildTitleVariantsAppendsMappingSynonyms(t *testing.T) {
+	t.Cleanup(idmap.Reset)
+	if err := idmap.Load([]byte(idMappingDataset), time.Now()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	romaji, english := "Sousou no Frieren", "Frieren: Beyond Journey's End"
+	titles := anilist.Title{Romaji: &romaji, English: &english}
+
+	base := buildTitleVariants(0, titles, "")
+	got := buildTitleVariants(154587, titles, "")
+	if !reflect.DeepEqual(got[:len(base)], base) {
+		t.Fatalf("AniList variants changed: %q, want prefix %q", got, base)
+	}
+	want := []string{"frieren nach dem ende der reise", "frieren remnants of the departed", "frieren extra"}
+	if extra := got[len(base):]; !reflect.DeepEqual(extra, want) {
+		t.Errorf("mapping variants = %q, want %q", extra, want)
+	}
+
+	if got := buildTitleVariants(154587, titles, "custom"); !reflect.DeepEqual(got, []string{"custom"}) {
+		t.Errorf("custom query variants = %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/notifications"
+	"AutoAnimeDownloader/src/internal/torrents"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// integritySweepBudget e quanto tempo um passe dedica a verificacao. Recheck le o torrent
+// inteiro do disco e o passe espera por ele, entao verificar a biblioteca toda de uma vez
+// atrasaria a busca de episodios novos em horas. Com o orcamento, a varredura anda alguns
+// torrents por passe e os devidos ficam para o seguinte. Pelo menos um torrent e verificado por
+// passe mesmo que passe do orcamento — senao um batch maior que ele nunca seria verificado.
+const integritySweepBudget = 2 * time.Minute
+
+// integritySweep re-verifica os torrents completos cuja ultima verificacao tem mais de
+// Config.IntegrityCheckDays, os nunca verificados primeiro e depois os mais antigos. Devolve um
+// IssueDataCorrupted por torrent com pecas ruins e dispara notifications.DataCorrupted; o reparo
+// em si e do backend (Recheck poe o torrent na frente da fila para baixar as pecas de novo).
+//
+// A hora e gravada mesmo quando o Recheck falha: um torrent que sempre falha (sem metadata,
+// arquivo sumido) iria para o topo da fila de devidos todo passe e tomaria o orcamento inteiro.
+func integritySweep(ctx context.Context, fm FileManagerInterface, backend torrents.TorrentBackend, configs *files.Config, savedEpisodes []files.EpisodeStruct) []Issue {
+	if configs.IntegrityCheckDays <= 0 {
+		return nil
+	}
+
+	checks, err := fm.LoadIntegrityChecks()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to load integrity checks; treating every torrent as never checked")
+		checks = map[string]time.Time{}
+	}
+
+	list := backend.List()
+	present := make(map[string]bool, len(list))
+	var due []torrents.TorrentInfo
+	interval := time.Duration(configs.IntegrityCheckDays) * 24 * time.Hour
+	for _, t := range list {
+		present[t.Hash] = true
+		if t.Completed && time.Since(checks[t.Hash]) >= interval {
+			due = append(due, t)
+		}
+	}
+	// Torrent que saiu da sessao sai do mapa, senao o arquivo so cresceria.
+	changed := false
+	for hash := range checks {
+		if !present[hash] {
+			delete(checks, hash)
+			changed = true
+		}
+	}
+
+	// Hora zero (nunca verificado) e a menor de todas, entao ja vem primeiro.
+	sort.SliceStable(due, func(i, j int) bool {
+		return checks[due[i].Hash].Before(checks[due[j].Hash])
+	})
+
+	var issues []Issue
+	start := time.Now()
+	checked := 0
+	for _, t := range due {
+		if ctx.Err() != nil || (checked > 0 && time.Since(start) >= integritySweepBudget) {
+			break
+		}
+		checked++
+		res, err := backend.Recheck(t.Hash)
+		checks[t.Hash] = time.Now()
+		changed = true
+		if err != nil {
+			logger.Logger.Warn().Err(err).Str("hash", t.Hash).Msg("Integrity check failed")
+			continue
+		}
+		if res.PiecesFailed == 0 {
+			continue
+		}
+		logger.Logger.Warn().
+			Str("hash", t.Hash).
+			Str("name", t.Name).
+			Uint32("pieces_failed", res.PiecesFailed).
+			Msg("Integrity check found corrupted pieces; downloading them again")
+		issue := corruptionIssue(t, int(res.PiecesFailed), savedEpisodes)
+		issues = append(issues, issue)
+
+		// Episodio 0 quando o torrent nao e de um episodio so (batch, ou adicionado a mao).
+		episode := 0
+		if len(issue.Episodes) == 1 {
+			episode = issue.Episodes[0]
+		}
+		reason := fmt.Sprintf("%d de %d peças falharam na verificação; baixando de novo", res.PiecesFailed, res.PiecesTotal)
+		notifications.Notify(configs, notifications.DataCorrupted, issue.AnimeName, episode, reason)
+	}
+
+	if changed {
+		if err := fm.SaveIntegrityChecks(checks); err != nil {
+			logger.Logger.Warn().Err(err).Msg("Failed to save integrity checks")
+		}
+	}
+	if checked > 0 {
+		logger.Logger.Info().
+			Int("checked", checked).
+			Int("due", len(due)).
+			Int("corrupted", len(issues)).
+			Dur("elapsed", time.Since(start)).
+			Msg("Integrity sweep finished")
+	}
+	return issues
+}
+
+// corruptionIssue liga o torrent aos episodios salvos pelo EpisodeHash. Torrent sem episodio
+// salvo vira anime 0 com o nome do torrent — aggregateIssues separa esses pelo nome.
+func corruptionIssue(t torrents.TorrentInfo, badPieces int, savedEpisodes []files.EpisodeStruct) Issue {
+	issue := Issue{AnimeName: t.Name, Code: IssueDataCorrupted, BadPieces: badPieces}
+	for _, ep := range savedEpisodes {
+		if ep.EpisodeHash != t.Hash {
+			continue
+		}
+		if issue.AnimeID == 0 {
+			issue.AnimeID = ep.AnimeID
+			if ep.AnimeName != "" {
+				issue.AnimeName = ep.AnimeName
+			}
+		}
+		issue.Episodes = append(issue.Episodes, ep.EpisodeNumber)
+	}
+	sort.Ints(issue.Episodes)
+	return issue
+}
@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// A corrupted torrent becomes a data_corrupted issue joined to its saved episodes, and every
+// checked torrent gets its check time recorded.
+func TestIntegritySweepReportsCorruptedTorrent(t *testing.T) {
+	fm := tempFileManager(t)
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted("good", "/dl/good")
+	backend.AddCompleted("bad", "/dl/bad")
+	backend.CorruptPieces["bad"] = 3
+	saved := []files.EpisodeStruct{
+		{AnimeID: 7, AnimeName: "Frieren", EpisodeNumber: 2, EpisodeHash: "bad"},
+		{AnimeID: 7, AnimeName: "Frieren", EpisodeNumber: 1, EpisodeHash: "bad"},
+		{AnimeID: 9, AnimeName: "Dandadan", EpisodeNumber: 1, EpisodeHash: "good"},
+	}
+
+	issues := integritySweep(context.Background(), fm, backend, &files.Config{IntegrityCheckDays: 7}, saved)
+
+	want := []Issue{{AnimeID: 7, AnimeName: "Frieren", Episodes: []int{1, 2}, Code: IssueDataCorrupted, BadPieces: 3}}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %+v, want %+v", issues, want)
+	}
+	checks, err := fm.LoadIntegrityChecks()
+	if err != nil {
+		t.Fatalf("LoadIntegrityChecks: %v", err)
+	}
+	if len(checks) != 2 {
+		t.Errorf("checks = %v, want both torrents recorded", checks)
+	}
+}
+
+// A torrent checked within the interval is skipped, and a hash no longer in the session is
+// dropped from the file.
+func TestIntegritySweepSkipsRecentAndPrunesRemoved(t *testing.T) {
+	fm := tempFileManager(t)
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted("recent", "/dl/recent")
+	backend.AddCompleted("old", "/dl/old")
+	if err := fm.SaveIntegrityChecks(map[string]time.Time{
+		"recent":  time.Now().Add(-time.Hour),
+		"old":     time.Now().Add(-30 * 24 * time.Hour),
+		"removed": time.Now().Add(-30 * 24 * time.Hour),
+	}); err != nil {
+		t.Fatalf("SaveIntegrityChecks: %v", err)
+	}
+
+	integritySweep(context.Background(), fm, backend, &files.Config{IntegrityCheckDays: 7}, nil)
+
+	if got := backend.RecheckCalls(); !reflect.DeepEqual(got, []string{"old"}) {
+		t.Errorf("rechecked %v, want only the overdue torrent", got)
+	}
+	checks, _ := fm.LoadIntegrityChecks()
+	if _, ok := checks["removed"]; ok {
+		t.Error("a torrent gone from the session was kept in the integrity checks file")
+	}
+}
+
+func TestIntegritySweepDisabled(t *testing.T) {
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted("h", "/dl/h")
+
+	integritySweep(context.Background(), tempFileManager(t), backend, &files.Config{}, nil)
+
+	if got := backend.RecheckCalls(); len(got) != 0 {
+		t.Errorf("rechecked %v with IntegrityCheckDays 0", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"sync"
 	"time"
 )
@@ -20,16 +21,33 @@ const (
 	// completion webhook. It is idempotent and replaces the former poll-based
 	// rename/move/notify jobs.
 	JobOrganize JobType = "organize"
+	// JobRelink moves the already-organized library files to the names the current naming
+	// templates give them. Enqueued by PUT /config when the templates change.
+	JobRelink JobType = "relink"
+	// JobMetadata writes the AniList-backed metadata of the library: the .nfo files (tvshow.nfo
+	// and one per episode) and the show artwork. Enqueued at boot, after each organize, after
+	// each relink and by a verification pass that sees a new cover.
+	JobMetadata JobType = "metadata"
+	// JobMediaScan asks the configured media servers (Jellyfin, Emby, Plex) to rescan the
+	// library folders an organize, relink or removal changed. Enqueued with a delay and merged
+	// while it waits, so a batch of organizes becomes one scan per server.
+	JobMediaScan JobType = "media_scan"
 )
 
 const (
 	jobTickInterval    = 5 * time.Second
 	maxRetriesOrganize = 20
+	maxRetriesRelink   = 5
+	maxRetriesMetadata = 5
+	maxRetriesScan     = 5
 )
 
 // OrganizePayload carries the torrent hash to organize into the library.
 type OrganizePayload struct {
 	Hash string `json:"hash"`
+	// Repair marca o organize que o reparo da biblioteca pede (RepairLibrary): o torrent ja
+	// tinha pousado e notificado uma vez, entao o webhook de conclusao nao sai de novo.
+	Repair bool `json:"repair,omitempty"`
 }
 
 // Job is a single unit of deferred work.
@@ -80,11 +98,13 @@ func (q *JobQueue) SetOrchestration(backend torrents.TorrentBackend, librarian f
 // Start loads persisted jobs and begins the background processing ticker.
 func (q *JobQueue) Start() {
 	q.loadFromDisk()
+	mediaScanQueue.Store(q)
 	go q.run()
 }
 
 // Stop signals the background goroutine to exit and waits for it to finish.
 func (q *JobQueue) Stop() {
+	mediaScanQueue.CompareAndSwap(q, nil)
 	close(q.stopCh)
 	<-q.done
 }
@@ -93,21 +113,65 @@ func (q *JobQueue) Stop() {
 // if a JobOrganize for the same hash is already pending (avoids pile-up when both the
 // completion event and the reconciliation pass enqueue).
 func (q *JobQueue) EnqueueOrganize(hash string) {
+	q.enqueueOrganize(OrganizePayload{Hash: hash})
+}
+
+// EnqueueReorganize is EnqueueOrganize for a torrent whose library links the repair cleared:
+// the job links it again without firing the completion webhook a second time.
+func (q *JobQueue) EnqueueReorganize(hash string) {
+	q.enqueueOrganize(OrganizePayload{Hash: hash, Repair: true})
+}
+
+func (q *JobQueue) enqueueOrganize(payload OrganizePayload) {
 	q.mu.Lock()
 	for _, j := range q.jobs {
 		if j.Type == JobOrganize {
 			var p OrganizePayload
-			if json.Unmarshal(j.Payload, &p) == nil && p.Hash == hash {
+			if json.Unmarshal(j.Payload, &p) == nil && p.Hash == payload.Hash {
 				q.mu.Unlock()
 				return
 			}
 		}
 	}
 	q.mu.Unlock()
-	q.enqueue(JobOrganize, OrganizePayload{Hash: hash}, maxRetriesOrganize)
+	q.enqueue(JobOrganize, payload, maxRetriesOrganize)
+}
+
+// EnqueueRelink schedules moving the library to the current naming. No payload: the job reads
+// the templates when it runs, so a pending relink already covers any later change and a second
+// one is a no-op.
+func (q *JobQueue) EnqueueRelink() {
+	q.mu.Lock()
+	for _, j := range q.jobs {
+		if j.Type == JobRelink {
+			q.mu.Unlock()
+			return
+		}
+	}
+	q.mu.Unlock()
+	q.enqueue(JobRelink, struct{}{}, maxRetriesRelink)
+}
+
+// EnqueueMetadata schedules writing the library .nfo files and artwork. Like the relink it has
+// no payload and reads the library when it runs, so one pending job covers every request.
+func (q *JobQueue) EnqueueMetadata() {
+	q.mu.Lock()
+	for _, j := range q.jobs {
+		if j.Type == JobMetadata {
+			q.mu.Unlock()
+			return
+		}
+	}
+	q.mu.Unlock()
+	q.enqueue(JobMetadata, struct{}{}, maxRetriesMetadata)
 }
 
 func (q *JobQueue) enqueue(jobType JobType, payload any, maxRetries int) {
+	q.enqueueAt(jobType, payload, maxRetries, time.Now())
+}
+
+// enqueueAt is enqueue for a job that must not run before nextRun.
+func (q *JobQueue) enqueueAt(jobType JobType, payload any, maxRetries int, nextRun time.Time) {
 	raw, err := json.Marshal(payload)
 	if err != nil {
 		logger.Logger.Error().Err(err).Str("type", string(jobType)).Msg("Job queue: failed to marshal payload")
@@ -119,7 +183,7 @@ func (q *JobQueue) enqueue(jobType JobType, payload any, maxRetries int) {
 		Type:       jobType,
 		Payload:    raw,
 		MaxRetries: maxRetries,
-		NextRun:    time.Now(),
+		NextRun:    nextRun,
 		CreatedAt:  time.Now(),
 	}
 
@@ -250,7 +314,36 @@ func (q *JobQueue) executeJob(job *Job, backend torrents.TorrentBackend, librari
 			logger.Logger.Error().Err(err).Str("id", job.ID).Msg("Job queue: failed to unmarshal organize payload")
 			return true // drop malformed job
 		}
-		return organizeTorrent(p.Hash, backend, librarian, q.fileManager, configs)
+		done, created := organizePayload(p, backend, librarian, q.fileManager, configs)
+		if done {
+			// Organize nao consulta a AniList: nfo e arte dos episodios novos vem do job.
+			q.EnqueueMetadata()
+			if len(created) > 0 {
+				q.EnqueueMediaScan(libraryShowDirs(created))
+			}
+		}
+		return done
+
+	case JobRelink:
+		done := relinkLibrary(librarian, q.fileManager, configs)
+		if done {
+			// MoveInLibrary apaga os nfo de episodio gerados; o job os regera no nome novo.
+			q.EnqueueMetadata()
+			// O relink mexe em pastas de toda a biblioteca: o scan e o completo.
+			q.EnqueueLibraryScan()
+		}
+		return done
+
+	case JobMetadata:
+		return writeLibraryMetadata(librarian, q.fileManager, configs)
+
+	case JobMediaScan:
+		var p MediaScanPayload
+		if err := json.Unmarshal(job.Payload, &p); err != nil {
+			logger.Logger.Error().Err(err).Str("id", job.ID).Msg("Job queue: failed to unmarshal media scan payload")
+			return true
+		}
+		return scanMediaServers(p, configs)
 
 	default:
 		logger.Logger.Warn().Str("type", string(job.Type)).Msg("Job queue: unknown job type, dropping")
@@ -312,19 +405,27 @@ func (q *JobQueue) loadFromDisk() {
 //
 // Returns true when done/dropped; false to retry with backoff.
 func organizeTorrent(hash string, backend torrents.TorrentBackend, librarian files.Librarian, fm FileManagerInterface, configs *files.Config) bool {
+	done, _ := organizePayload(OrganizePayload{Hash: hash}, backend, librarian, fm, configs)
+	return done
+}
+
+// organizePayload e o organizeTorrent do job: devolve tambem os arquivos que entraram na
+// biblioteca nesta execucao, para o scan dos servidores de midia. Nada quando ja estava feito.
+func organizePayload(p OrganizePayload, backend torrents.TorrentBackend, librarian files.Librarian, fm FileManagerInterface, configs *files.Config) (bool, []string) {
+	hash := p.Hash
 	info, ok := backend.Get(hash)
 	if !ok {
 		logger.Logger.Debug().Str("hash", hash).Msg("Organize: torrent no longer present, dropping")
-		return true
+		return true, nil
 	}
 	if !info.Completed {
-		return false // not seeding yet, retry
+		return false, nil // not seeding yet, retry
 	}
 
 	saved, err := fm.LoadSavedEpisodes()
 	if err != nil {
 		logger.Logger.Warn().Err(err).Msg("Organize: failed to load saved episodes")
-		return false
+		return false, nil
 	}
 
 	var matched []files.EpisodeStruct
@@ -337,22 +438,47 @@ func organizeTorrent(hash string, backend torrents.TorrentBackend, librarian fil
 		// The episode record may not be persisted yet (fast-completing torrent). Retry;
 		// bounded by MaxRetries.
 		logger.Logger.Debug().Str("hash", hash).Msg("Organize: no saved episode matches hash yet, retrying")
-		return false
+		return false, nil
 	}
 
 	// The webhook + write-back run only when at least one matched episode is not yet
-	// organized (empty LibraryPaths). This makes reconciliation re-runs no-ops.
+	// organized (empty LibraryPaths), or its links disagree with the profile libraries it
+	// belongs in. This makes reconciliation re-runs no-ops.
 	needsOrganize := false
 	partiallyOrganized := false
 	for _, ep := range matched {
-		if len(ep.LibraryPaths) == 0 {
+		if configs.LibraryLinksOutOfDate(ep) {
 			needsOrganize = true
-		} else {
+		}
+		if len(ep.LibraryPaths) > 0 {
 			partiallyOrganized = true
 		}
 	}
 	if !needsOrganize {
-		return true // already organized on a previous run
+		return true, nil // already organized on a previous run
+	}
+
+	// Com Season NN a pasta depende da serie, que vem da AniList: resolve antes de linkar, ou o
+	// episodio cairia na pasta da entrada e so um relink o tiraria de la. O Meta resolvido vai
+	// para o disco junto com os LibraryPaths, no upsert abaixo.
+	if configs.LibrarySeasonFolders {
+		for i := range matched {
+			if _, err := ensureShowMeta(&matched[i]); err != nil {
+				logger.Logger.Warn().Err(err).Str("hash", hash).Int("anime_id", matched[i].AnimeID).Msg("Organize: failed to resolve the series for season folders, retrying")
+				return false, nil
+			}
+		}
+	}
+
+	// O layout de filme depende do formato; registro anterior ao campo o resolve aqui, pelo
+	// mesmo motivo.
+	if configs.LibraryMovieLayout {
+		for i := range matched {
+			if _, err := ensureMediaFormat(&matched[i]); err != nil {
+				logger.Logger.Warn().Err(err).Str("hash", hash).Int("anime_id", matched[i].AnimeID).Msg("Organize: failed to resolve the media format for the movie layout, retrying")
+				return false, nil
+			}
+		}
 	}
 
 	isBatch := matched[0].IsBatch || len(matched) > 1
@@ -360,40 +486,87 @@ func organizeTorrent(hash string, backend torrents.TorrentBackend, librarian fil
 		TorrentDataDir: info.DataDir,
 		AnimeName:      matched[0].AnimeName,
 		AnimeID:        matched[0].AnimeID,
-		CompletedPath:  configs.CompletedAnimePath,
 		IsBatch:        isBatch,
 		RenameJellyfin: configs.RenameFilesForJellyfin,
+		FolderTemplate: configs.LibraryFolderTemplate,
+		FileTemplate:   configs.LibraryFileTemplate,
+		SeasonFolders:  configs.LibrarySeasonFolders,
+		MovieLayout:    configs.LibraryMovieLayout,
+		TorrentName:    info.Name,
+		Meta:           matched[0].Meta,
+		LinkMode:       configs.LinkMode(),
 	}
 	if !isBatch {
 		ep := matched[0].EpisodeNumber
 		req.EpisodeNumber = &ep
 	}
 
-	created, err := librarian.Organize(req)
-	if err != nil {
-		logger.Logger.Warn().Err(err).Str("hash", hash).Str("anime", matched[0].AnimeName).Msg("Organize: failed to hardlink into library")
-		return false // retry with backoff; permanent errors drop after MaxRetries
+	// Um torrent, uma copia semeando, um link em cada biblioteca de perfil que o quer.
+	var libraries []string
+	for _, ep := range matched {
+		for _, sub := range ep.LibrarySubpaths() {
+			if !slices.Contains(libraries, sub) {
+				libraries = append(libraries, sub)
+			}
+		}
+	}
+	var created []string
+	for _, sub := range libraries {
+		req.CompletedPath = configs.LibraryRoot(sub)
+		req.MoviesPath = configs.MoviesRoot(sub)
+		paths, err := librarian.Organize(req)
+		if err != nil {
+			logger.Logger.Warn().Err(err).Str("hash", hash).Str("anime", matched[0].AnimeName).Str("library", sub).Msg("Organize: failed to link into library")
+			return false, nil // retry with backoff; permanent errors drop after MaxRetries
+		}
+		created = append(created, paths...)
+	}
+
+	// Os links de uma biblioteca que o anime deixou (o perfil parou de querer) saem depois dos
+	// novos estarem no lugar: o dado continua semeando, so a biblioteca daquele perfil o perde.
+	var unlinked []string
+	for _, ep := range matched {
+		for _, path := range ep.LibraryPaths {
+			sub, ok := configs.LibrarySubpathOf(path)
+			if !ok || slices.Contains(libraries, sub) || slices.Contains(unlinked, path) {
+				continue
+			}
+			if err := librarian.RemoveFromLibrary(path); err != nil {
+				logger.Logger.Warn().Err(err).Str("path", path).Msg("Organize: failed to remove a link from a library the anime left")
+				continue
+			}
+			unlinked = append(unlinked, path)
+		}
 	}
 
 	// Write back LibraryPaths (the "organized" marker) before firing the webhook, so a
 	// crash after the webhook can't re-fire it, and a crash before write-back re-runs once.
+	// O nome do torrent vai junto: o relink recalcula {group}/{resolution} a partir dele
+	// quando o arquivo ja renomeado nao os tem mais.
 	for i := range matched {
 		matched[i].LibraryPaths = created
+		if info.Name != "" {
+			matched[i].TorrentName = info.Name
+		}
 	}
 	if err := fm.UpsertEpisodes(matched); err != nil {
 		logger.Logger.Warn().Err(err).Str("hash", hash).Msg("Organize: failed to persist library paths")
-		return false // retry; hardlinks already exist so Organize will no-op next time
+		return false, nil // retry; hardlinks already exist so Organize will no-op next time
 	}
 
 	// Se PARTE do grupo ja tinha LibraryPaths, o torrent ja pousou e o webhook ja saiu: organiza
 	// (e idempotente), grava o marcador nos registros novos, mas nao notifica de novo. Sem isso,
 	// registros criados depois para um torrent ja organizado — como os que a regra de batch cria
 	// no primeiro passe pos-upgrade de uma biblioteca existente — duplicariam a notificacao.
-	if partiallyOrganized {
+	switch {
+	case partiallyOrganized:
 		logger.Logger.Debug().Str("hash", hash).Msg("Organize: group was already partially organized, skipping the completion webhook")
-	} else {
+	case p.Repair:
+		logger.Logger.Debug().Str("hash", hash).Msg("Organize: relinked by the library repair, skipping the completion webhook")
+	default:
 		notifications.Notify(configs, notifications.DownloadCompleted, matched[0].AnimeName, matched[0].EpisodeNumber, "")
 	}
-	logger.Logger.Info().Str("hash", hash).Str("anime", matched[0].AnimeName).Int("files", len(created)).Msg("Organized torrent into library")
-	return true
+	logger.Logger.Info().Str("hash", hash).Str("anime", matched[0].AnimeName).Int("files", len(created)).Int("unlinked", len(unlinked)).Msg("Organized torrent into library")
+	// O servidor de midia do perfil que perdeu o anime tambem precisa ver a pasta esvaziar.
+	return true, append(created, unlinked...)
 }
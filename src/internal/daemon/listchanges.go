@@ -0,0 +1,205 @@
+package daemon
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/lists"
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/notifications"
+)
+
+// collectListSnapshot le a lista inteira de cada conta, em todos os status. Conta cuja busca
+// falhou fica AUSENTE do resultado — e ausente, no diff, e "nao sei", nunca "lista vazia".
+func collectListSnapshot(configs *files.Config) files.ListSnapshot {
+	snapshot := files.ListSnapshot{}
+	for _, username := range configs.AnilistUsernames {
+		entries, err := anilist.GetListEntries(username)
+		if err != nil {
+			logger.Logger.Warn().Err(err).Str("username", username).Msg("Failed to fetch the list for the change feed")
+			continue
+		}
+		byMedia := make(map[int]files.ListSnapshotEntry, len(entries))
+		for _, e := range entries {
+			byMedia[e.MediaID] = files.ListSnapshotEntry{
+				Status:   string(e.Status),
+				Progress: e.Progress,
+				Title:    getAnimeTitleSafe(anilist.MediaList{Media: anilist.Media{Title: e.Title}}),
+			}
+		}
+		snapshot[username] = byMedia
+	}
+	// Vem do cache de 60s de lists.Entries: a busca de DeleteStatuses do mesmo passe ja leu.
+	for _, acc := range lists.Accounts(configs) {
+		entries, err := lists.Entries(acc, configs)
+		if err != nil {
+			logger.Logger.Warn().Err(err).Str("account", acc.Key()).Msg("Failed to fetch the list for the change feed")
+			continue
+		}
+		byMedia := make(map[int]files.ListSnapshotEntry, len(entries))
+		for _, e := range entries {
+			byMedia[e.MediaID] = files.ListSnapshotEntry{Status: string(e.Status), Progress: e.Progress}
+		}
+		snapshot[acc.Key()] = byMedia
+	}
+	return snapshot
+}
+
+// configuredListAccounts devolve a chave de snapshot de toda conta configurada.
+func configuredListAccounts(configs *files.Config) []string {
+	keys := append([]string(nil), configs.AnilistUsernames...)
+	for _, acc := range lists.Accounts(configs) {
+		keys = append(keys, acc.Key())
+	}
+	return keys
+}
+
+// diffListSnapshots compara o snapshot do passe anterior com o deste e devolve as mudancas e o
+// snapshot a gravar.
+//
+// Conta que nao esta em previous (primeiro passe, ou conta recem-configurada) entra no snapshot
+// sem evento nenhum: a lista inteira dela apareceria como "added". Conta que esta em previous mas
+// nao em current teve a busca falhando, e mantem o snapshot antigo — senao o passe seguinte,
+// com a busca de volta, veria a lista inteira como adicionada de novo. Conta que saiu da config
+// some do snapshot.
+func diffListSnapshots(previous, current files.ListSnapshot, accounts []string, now time.Time) ([]files.ListChange, files.ListSnapshot) {
+	next := files.ListSnapshot{}
+	var changes []files.ListChange
+
+	for _, account := range accounts {
+		old, hadOld := previous[account]
+		cur, hasCur := current[account]
+		switch {
+		case !hasCur:
+			if hadOld {
+				next[account] = old
+			}
+			continue
+		case !hadOld:
+			next[account] = cur
+			continue
+		}
+		next[account] = cur
+
+		for id, c := range cur {
+			o, ok := old[id]
+			change := files.ListChange{
+				At: now, Account: account, MediaID: id, Title: c.Title,
+				NewStatus: c.Status, NewProgress: c.Progress,
+			}
+			switch {
+			case !ok:
+				change.Kind = files.ListChangeAdded
+			case o.Status != c.Status:
+				change.Kind = files.ListChangeStatus
+			case o.Progress != c.Progress:
+				change.Kind = files.ListChangeProgress
+			default:
+				continue
+			}
+			if ok {
+				change.OldStatus, change.OldProgress = o.Status, o.Progress
+				if change.Title == "" {
+					change.Title = o.Title
+				}
+			}
+			changes = append(changes, change)
+		}
+		for id, o := range old {
+			if _, ok := cur[id]; ok {
+				continue
+			}
+			changes = append(changes, files.ListChange{
+				At: now, Account: account, MediaID: id, Title: o.Title, Kind: files.ListChangeRemoved,
+				OldStatus: o.Status, OldProgress: o.Progress,
+			})
+		}
+	}
+
+	// Os mapas nao tem ordem; o feed e as notificacoes precisam ter.
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Account != changes[j].Account {
+			return changes[i].Account < changes[j].Account
+		}
+		return changes[i].MediaID < changes[j].MediaID
+	})
+	return changes, next
+}
+
+// recordListChanges grava no feed e notifica o que mudou nas listas desde o passe anterior, e
+// troca o snapshot pelo deste passe.
+//
+// Passe que usou resposta salva da AniList nao compara nada e nao grava o snapshot: a lista
+// pode ser a de horas atras, e o diff contra ela inventaria e depois desfaria mudancas.
+func recordListChanges(fm FileManagerInterface, configs *files.Config, current files.ListSnapshot, now time.Time) {
+	if _, stale := anilist.StaleSince(); stale {
+		logger.Logger.Debug().Msg("AniList data is stale; skipping the list change feed this pass")
+		return
+	}
+	previous, err := fm.LoadListSnapshot()
+	if err != nil {
+		// Sem o anterior todo anime pareceria novo; melhor pular o passe que sobrescrever.
+		logger.Logger.Warn().Err(err).Msg("Failed to load the list snapshot; skipping the list change feed")
+		return
+	}
+
+	changes, next := diffListSnapshots(previous, current, configuredListAccounts(configs), now)
+	resolveListChangeTitles(changes)
+
+	if err := fm.AppendListChanges(changes); err != nil {
+		// Sem o feed gravado o snapshot tambem nao avanca: o proximo passe tenta as mesmas.
+		logger.Logger.Warn().Err(err).Msg("Failed to save the list changes")
+		return
+	}
+	if err := fm.SaveListSnapshot(next); err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to save the list snapshot")
+	}
+
+	for _, c := range changes {
+		logger.Logger.Info().Str("account", c.Account).Int("media_id", c.MediaID).Str("kind", c.Kind).
+			Str("old_status", c.OldStatus).Str("new_status", c.NewStatus).Msg("List changed")
+		switch c.Kind {
+		case files.ListChangeAdded:
+			notifications.Notify(configs, notifications.ListAdded, c.Title, c.NewProgress, c.Account)
+		case files.ListChangeRemoved:
+			notifications.Notify(configs, notifications.ListRemoved, c.Title, c.OldProgress, c.Account)
+		case files.ListChangeStatus:
+			notifications.Notify(configs, notifications.ListStatusChanged, c.Title, c.NewProgress,
+				fmt.Sprintf("%s: %s → %s", c.Account, c.OldStatus, c.NewStatus))
+		case files.ListChangeProgress:
+			notifications.Notify(configs, notifications.ListProgressChanged, c.Title, c.NewProgress, c.Account)
+		}
+	}
+}
+
+// resolveListChangeTitles preenche o titulo das mudancas das contas do MAL/Kitsu, cujo
+// snapshot nao tem titulo. So o que mudou e consultado, numa chamada so; falha deixa "#id".
+func resolveListChangeTitles(changes []files.ListChange) {
+	var missing []int
+	for _, c := range changes {
+		if c.Title == "" {
+			missing = append(missing, c.MediaID)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	media, err := anilist.GetMediaByIDs(missing)
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to resolve titles for the list change feed")
+	}
+	for i := range changes {
+		if changes[i].Title != "" {
+			continue
+		}
+		if m, ok := media[changes[i].MediaID]; ok && m != nil {
+			changes[i].Title = getAnimeTitleSafe(*m)
+		} else {
+			changes[i].Title = fmt.Sprintf("#%d", changes[i].MediaID)
+		}
+	}
+}
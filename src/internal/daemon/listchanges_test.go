@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/files"
+)
+
+func TestDiffListSnapshots(t *testing.T) {
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	previous := files.ListSnapshot{
+		"user1": {
+			1: {Status: "CURRENT", Progress: 3, Title: "Mantido"},
+			2: {Status: "CURRENT", Progress: 5, Title: "Dropado"},
+			3: {Status: "CURRENT", Progress: 1, Title: "Assistido"},
+			4: {Status: "PLANNING", Title: "Removido"},
+		},
+		"falhou": {10: {Status: "CURRENT"}},
+		"saiu":   {20: {Status: "CURRENT"}},
+	}
+	current := files.ListSnapshot{
+		"user1": {
+			1: {Status: "CURRENT", Progress: 3, Title: "Mantido"},
+			2: {Status: "DROPPED", Progress: 6, Title: "Dropado"},
+			3: {Status: "CURRENT", Progress: 2, Title: "Assistido"},
+			5: {Status: "PLANNING", Title: "Novo"},
+		},
+		"nova": {30: {Status: "CURRENT"}},
+	}
+
+	changes, next := diffListSnapshots(previous, current, []string{"user1", "falhou", "nova"}, now)
+
+	want := []files.ListChange{
+		{At: now, Account: "user1", MediaID: 2, Title: "Dropado", Kind: files.ListChangeStatus, OldStatus: "CURRENT", NewStatus: "DROPPED", OldProgress: 5, NewProgress: 6},
+		{At: now, Account: "user1", MediaID: 3, Title: "Assistido", Kind: files.ListChangeProgress, OldStatus: "CURRENT", NewStatus: "CURRENT", OldProgress: 1, NewProgress: 2},
+		{At: now, Account: "user1", MediaID: 4, Title: "Removido", Kind: files.ListChangeRemoved, OldStatus: "PLANNING"},
+		{At: now, Account: "user1", MediaID: 5, Title: "Novo", Kind: files.ListChangeAdded, NewStatus: "PLANNING"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("quero %d mudancas, veio %+v", len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("mudanca %d = %+v, quero %+v", i, changes[i], want[i])
+		}
+	}
+
+	// A conta nova entra sem evento, a que falhou mantem o antigo, a que saiu da config some.
+	if _, ok := next["nova"]; !ok {
+		t.Error("conta nova devia entrar no snapshot")
+	}
+	if next["falhou"][10].Status != "CURRENT" {
+		t.Error("conta cuja busca falhou devia manter o snapshot antigo")
+	}
+	if _, ok := next["saiu"]; ok {
+		t.Error("conta fora da config devia sair do snapshot")
+	}
+}
+
+// O feed so avanca junto com o snapshot: o passe seguinte, com a mesma lista, nao repete nada.
+func TestRecordListChanges_AdvancesSnapshot(t *testing.T) {
+	fm := &mockFileManagerForEpisodes{listSnapshot: files.ListSnapshot{
+		"user1": {1: {Status: "CURRENT", Title: "Frieren"}},
+	}}
+	configs := &files.Config{AnilistUsernames: []string{"user1"}}
+	current := files.ListSnapshot{"user1": {1: {Status: "COMPLETED", Progress: 28, Title: "Frieren"}}}
+
+	recordListChanges(fm, configs, current, time.Now())
+	if len(fm.listChanges) != 1 || fm.listChanges[0].Kind != files.ListChangeStatus {
+		t.Fatalf("quero uma mudanca de status, veio %+v", fm.listChanges)
+	}
+
+	recordListChanges(fm, configs, current, time.Now())
+	if len(fm.listChanges) != 1 {
+		t.Errorf("segundo passe sem mudanca gravou %+v", fm.listChanges[1:])
+	}
+}
@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/lists"
+)
+
+// mockMALAccount responde o load.json publico do MAL com os itens dados e a AniList com o
+// mapeamento 10->100, 20->200; o resto (a lista de user1) e listWithAnime100.
+func mockMALAccount(t *testing.T, malItems string) func() {
+	t.Helper()
+	restoreAL := anilist.MockAniListDo(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		payload := listWithAnime100
+		switch {
+		case strings.Contains(string(body), "MediaIDsByMAL"):
+			payload = `{"data":{"Page":{"media":[{"id":100,"idMal":10},{"id":200,"idMal":20}]}}}`
+		case strings.Contains(string(body), "GetMediaByIDs"):
+			payload = `{"data":{"Page":{"media":[
+				{"id":100,"format":"TV","status":"RELEASING","episodes":12,"title":{"romaji":"Airing Anime"}},
+				{"id":200,"format":"TV","status":"RELEASING","episodes":12,"title":{"romaji":"Other Anime"}}
+			]}}}`
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(payload)), Header: make(http.Header)}, nil
+	})
+	restoreLists := lists.MockListsDo(func(req *http.Request) (*http.Response, error) {
+		if !strings.HasSuffix(req.URL.Path, "/animelist/maluser/load.json") {
+			t.Errorf("unexpected list request %s", req.URL)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(malItems))}, nil
+	})
+	return func() { restoreLists(); restoreAL() }
+}
+
+func TestSearchAnilist_MergesMyAnimeListAccount(t *testing.T) {
+	defer mockMALAccount(t, `[
+		{"anime_id":10,"status":1,"num_watched_episodes":3},
+		{"anime_id":20,"status":1,"num_watched_episodes":0},
+		{"anime_id":20,"status":2,"num_watched_episodes":12}
+	]`)()
+
+	configs := standaloneTestConfig()
+	configs.MALUsernames = []string{"maluser"}
+
+	resp, _, err := searchAnilist(&mockFileManagerForEpisodes{}, configs, nil)
+	if err != nil {
+		t.Fatalf("searchAnilist: %v", err)
+	}
+	progress := map[int]int{}
+	for _, ml := range resp.Data.Page.MediaList {
+		progress[ml.Media.Id] = ml.Progress
+	}
+	// 100 esta nas duas contas: o dedupe fica com o menor progresso, o do MAL. 200 so existe no
+	// MAL; o COMPLETED repetido nao passa por DownloadStatuses.
+	if len(progress) != 2 || progress[100] != 3 || progress[200] != 0 {
+		t.Fatalf("merged progress = %v, want map[100:3 200:0]", progress)
+	}
+}
+
+func TestDeletableMediaIDs_ListAccountVetoes(t *testing.T) {
+	defer mockMALAccount(t, `[{"anime_id":10,"status":1,"num_watched_episodes":3}]`)()
+
+	cfg := &files.Config{
+		AnilistUsernames: []string{},
+		MALUsernames:     []string{"maluser"},
+		DeleteStatuses:   []string{"DROPPED", "COMPLETED"},
+	}
+	acc := lists.Account{Provider: lists.ProviderMyAnimeList, Username: "maluser"}
+
+	// Uma outra conta largou o anime; a do MAL ainda assiste e veta.
+	got := deletableMediaIDs(cfg, map[string]map[int]bool{
+		"other":   {100: true},
+		acc.Key(): {},
+	}, []files.EpisodeStruct{{EpisodeNumber: 1, AnimeID: 100}})
+	if got[100] {
+		t.Error("a MAL account watching the anime must veto its deletion")
+	}
+
+	// Sem a lista da conta (busca falhou) tambem nao ha unanimidade.
+	got = deletableMediaIDs(cfg, map[string]map[int]bool{"other": {100: true}},
+		[]files.EpisodeStruct{{EpisodeNumber: 1, AnimeID: 100}})
+	if got[100] {
+		t.Error("an unavailable list account must block the deletion")
+	}
+}
@@ -22,7 +22,10 @@ func addAndPrioritize(backend torrents.TorrentBackend, magnet string, configs *f
 	// A guarda de espaco em disco fica aqui (e nao em torrents.Session.Add) porque o pacote
 	// torrents nao conhece files.Config — passar a config para la so para ler uma porcentagem
 	// inverteria a dependencia. Este e o unico Add dos caminhos manuais.
-	if err := checkDiskSpace(configs); err != nil {
+	if err := checkDiskSpace(backend, configs); err != nil {
+		return "", err
+	}
+	if err := checkDataCap(backend, configs); err != nil {
 		return "", err
 	}
 	hash, err := backend.Add(magnet)
@@ -134,6 +137,7 @@ func ManualDownloadEpisodeWithMagnet(fm FileManagerInterface, backend torrents.T
 		EpisodeNumber:   targetNode.Episode,
 		DownloadDate:    time.Now(),
 		ManuallyManaged: true,
+		Meta:            animeMeta(details.mediaList),
 	}, nil
 }
 
@@ -170,6 +174,7 @@ func ManualDownloadAnimeWithMagnet(fm FileManagerInterface, backend torrents.Tor
 			IsBatch:         true,
 			DownloadDate:    now,
 			ManuallyManaged: true,
+			Meta:            animeMeta(details.mediaList),
 		})
 	}
 
@@ -201,11 +206,14 @@ func ManualDownloadEpisode(fm FileManagerInterface, backend torrents.TorrentBack
 
 	// Checado antes da busca no Nyaa para o handler receber ErrInsufficientDiskSpace em vez do
 	// "falhou apos N tentativas" genrico (que viraria 500 em vez de 409).
-	if err := checkDiskSpace(configs); err != nil {
+	if err := checkDiskSpace(backend, configs); err != nil {
+		return files.EpisodeStruct{}, err
+	}
+	if err := checkDataCap(backend, configs); err != nil {
 		return files.EpisodeStruct{}, err
 	}
 
-	results := searchNyaaForSingleEpisode(*targetNode, details.mediaList.Media.Title, nil, anilist.MediaRelations{}, customQuery, anilist.LastAiredEpisode(details.mediaList))
+	results := searchNyaaForSingleEpisode(details.mediaList.Media.Id, *targetNode, details.mediaList.Media.Title, nil, anilist.MediaRelations{}, customQuery, anilist.LastAiredEpisode(details.mediaList))
 	var magnets []string
 	for _, result := range results {
 		magnets = append(magnets, result.MagnetLink)
@@ -237,5 +245,6 @@ func ManualDownloadEpisode(fm FileManagerInterface, backend torrents.TorrentBack
 		EpisodeNumber:   targetNode.Episode,
 		DownloadDate:    time.Now(),
 		ManuallyManaged: true,
+		Meta:            animeMeta(details.mediaList),
 	}, nil
 }
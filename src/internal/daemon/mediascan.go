@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"encoding/json"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/mediaserver"
+)
+
+// MediaScanPayload carries the library folders a JobMediaScan rescans, in the daemon's view.
+// Full asks for the whole library and wins over the folders when two requests merge.
+type MediaScanPayload struct {
+	Dirs []string `json:"dirs,omitempty"`
+	Full bool     `json:"full,omitempty"`
+}
+
+// mediaScanDelay e quanto o scan espera antes de rodar. Os organizes de um pack, ou de varios
+// torrents que terminam juntos, caem na mesma janela e viram um scan so por servidor.
+var mediaScanDelay = 30 * time.Second
+
+// mediaScanQueue e a fila que recebe o scan das remocoes. Elas vem da API e do loop por
+// removeEpisodesAndLinks, que nao recebe a JobQueue; Start a registra aqui, como as janelas do
+// pacote notifications. Sem fila (testes, CLI) a remocao nao pede scan.
+var mediaScanQueue atomic.Pointer[JobQueue]
+
+// EnqueueMediaScan schedules a rescan of the given library folders on every configured media
+// server. The job waits mediaScanDelay; a request arriving meanwhile joins it instead of
+// queueing a second scan. No-op when no media server is configured.
+func (q *JobQueue) EnqueueMediaScan(dirs []string) {
+	if len(dirs) == 0 {
+		return
+	}
+	q.enqueueMediaScan(MediaScanPayload{Dirs: dirs})
+}
+
+// EnqueueLibraryScan is EnqueueMediaScan for the whole library, for changes that touch folders
+// all over it (the relink).
+func (q *JobQueue) EnqueueLibraryScan() {
+	q.enqueueMediaScan(MediaScanPayload{Full: true})
+}
+
+func (q *JobQueue) enqueueMediaScan(payload MediaScanPayload) {
+	configs, err := q.fileManager.LoadConfigs()
+	if err != nil || len(configs.MediaServers) == 0 {
+		return
+	}
+
+	now := time.Now()
+	q.mu.Lock()
+	for _, j := range q.jobs {
+		// So entra num job que ainda nao venceu: um vencido pode estar rodando agora, fora do
+		// lock (processDueJobs), e a pasta nova ficaria de fora do scan.
+		if j.Type != JobMediaScan || !j.NextRun.After(now) {
+			continue
+		}
+		var pending MediaScanPayload
+		if json.Unmarshal(j.Payload, &pending) != nil {
+			continue
+		}
+		raw, err := json.Marshal(mergeMediaScan(pending, payload))
+		if err != nil {
+			break
+		}
+		j.Payload = raw
+		q.mu.Unlock()
+		q.saveToDisk()
+		return
+	}
+	q.mu.Unlock()
+	q.enqueueAt(JobMediaScan, payload, maxRetriesScan, now.Add(mediaScanDelay))
+}
+
+func mergeMediaScan(a, b MediaScanPayload) MediaScanPayload {
+	if a.Full || b.Full {
+		return MediaScanPayload{Full: true}
+	}
+	out := MediaScanPayload{Dirs: slices.Clone(a.Dirs)}
+	for _, d := range b.Dirs {
+		if !slices.Contains(out.Dirs, d) {
+			out.Dirs = append(out.Dirs, d)
+		}
+	}
+	return out
+}
+
+// scanMediaServers pede o scan a cada servidor configurado. Um que falha faz o job voltar com
+// backoff; o scan e idempotente, entao repetir nos que ja aceitaram nao custa nada alem dele.
+func scanMediaServers(p MediaScanPayload, configs *files.Config) bool {
+	var dirs []string
+	if !p.Full {
+		dirs = p.Dirs
+	}
+	ok := true
+	for _, server := range configs.MediaServers {
+		if err := mediaserver.Refresh(server, configs, dirs); err != nil {
+			logger.Logger.Warn().Err(err).Str("media_server", server.Name).Msg("Media server scan failed")
+			ok = false
+			continue
+		}
+		logger.Logger.Info().Str("media_server", server.Name).Int("folders", len(dirs)).Msg("Media server scan requested")
+	}
+	return ok
+}
+
+// requestMediaScan pede, pela fila registrada, o scan das pastas de onde saiu algum arquivo.
+func requestMediaScan(dirs []string) {
+	if q := mediaScanQueue.Load(); q != nil {
+		q.EnqueueMediaScan(dirs)
+	}
+}
+
+// libraryShowDirs devolve as pastas de serie dos arquivos, sem repetir.
+func libraryShowDirs(paths []string) []string {
+	var out []string
+	for _, p := range paths {
+		if dir := files.LibraryShowDir(p); !slices.Contains(out, dir) {
+			out = append(out, dir)
+		}
+	}
+	return out
+}
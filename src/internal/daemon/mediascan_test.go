@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+)
+
+// fakeJellyfin records the folders each /Library/Media/Updated asks to rescan.
+type fakeJellyfin struct {
+	srv *httptest.Server
+
+	mu      sync.Mutex
+	updates [][]string
+	status  int
+}
+
+func newFakeJellyfin(t *testing.T) *fakeJellyfin {
+	t.Helper()
+	f := &fakeJellyfin{status: http.StatusNoContent}
+	f.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.URL.Path == "/Library/Media/Updated" {
+			body, _ := io.ReadAll(r.Body)
+			var req struct {
+				Updates []struct{ Path string }
+			}
+			_ = json.Unmarshal(body, &req)
+			var paths []string
+			for _, u := range req.Updates {
+				paths = append(paths, u.Path)
+			}
+			f.updates = append(f.updates, paths)
+		}
+		w.WriteHeader(f.status)
+	}))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func mediaScanJobs(t *testing.T, q *JobQueue) []*Job {
+	t.Helper()
+	var out []*Job
+	for _, j := range q.jobs {
+		if j.Type == JobMediaScan {
+			out = append(out, j)
+		}
+	}
+	return out
+}
+
+func mediaScanPayload(t *testing.T, j *Job) MediaScanPayload {
+	t.Helper()
+	var p MediaScanPayload
+	if err := json.Unmarshal(j.Payload, &p); err != nil {
+		t.Fatalf("unmarshal media scan payload: %v", err)
+	}
+	return p
+}
+
+// O organize pede o scan da pasta do anime, com atraso; o pedido seguinte entra no mesmo job, e
+// o job roda um scan so no servidor.
+func TestOrganizeEnqueuesDebouncedMediaScan(t *testing.T) {
+	dataDir := makeTorrentDataDir(t)
+	completed := t.TempDir()
+	const hash = "0123456789abcdef0123456789abcdef01234567"
+	jf := newFakeJellyfin(t)
+
+	cfg := &files.Config{
+		CompletedAnimePath:     completed,
+		RenameFilesForJellyfin: true,
+		MediaServers:           []files.MediaServer{{Name: "home", Type: "jellyfin", URL: jf.srv.URL, Token: "k"}},
+	}
+	fm := &orchestrationFM{
+		saved:   []files.EpisodeStruct{{EpisodeHash: hash, AnimeName: "My Anime", EpisodeNumber: 5}},
+		configs: cfg,
+	}
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted(hash, dataDir)
+	lib := files.NewLibrarian(files.NewOSFileSystem())
+
+	q := NewJobQueue(fm, filepath.Join(t.TempDir(), "jobs.json"))
+	q.SetOrchestration(backend, lib)
+	q.EnqueueOrganize(hash)
+	q.processDueJobs()
+
+	jobs := mediaScanJobs(t, q)
+	if len(jobs) != 1 {
+		t.Fatalf("expected one media scan job after the organize, got %d", len(jobs))
+	}
+	if !jobs[0].NextRun.After(time.Now()) {
+		t.Error("the media scan must wait for the debounce window")
+	}
+	showDir := filepath.Join(completed, "My Anime")
+	if p := mediaScanPayload(t, jobs[0]); !slices.Equal(p.Dirs, []string{showDir}) {
+		t.Fatalf("scan dirs = %v, want [%s]", p.Dirs, showDir)
+	}
+
+	other := filepath.Join(completed, "Other")
+	q.EnqueueMediaScan([]string{other, showDir})
+	if jobs := mediaScanJobs(t, q); len(jobs) != 1 {
+		t.Fatalf("a request inside the window must join the pending job, got %d jobs", len(jobs))
+	}
+	if p := mediaScanPayload(t, jobs[0]); !slices.Equal(p.Dirs, []string{showDir, other}) {
+		t.Errorf("merged dirs = %v", p.Dirs)
+	}
+
+	if !q.executeJob(jobs[0], backend, lib, cfg) {
+		t.Fatal("media scan job should succeed against the fake server")
+	}
+	jf.mu.Lock()
+	updates := jf.updates
+	jf.mu.Unlock()
+	if len(updates) != 1 || !slices.Equal(updates[0], []string{showDir, other}) {
+		t.Errorf("server got %v, want one scan of both folders", updates)
+	}
+
+	// Servidor fora do ar: o job volta para a fila.
+	jf.mu.Lock()
+	jf.status = http.StatusServiceUnavailable
+	jf.mu.Unlock()
+	if q.executeJob(jobs[0], backend, lib, cfg) {
+		t.Error("a failed scan must be retried")
+	}
+}
+
+func TestEnqueueMediaScanWithoutServers(t *testing.T) {
+	q := NewJobQueue(&orchestrationFM{configs: &files.Config{}}, filepath.Join(t.TempDir(), "jobs.json"))
+	q.EnqueueMediaScan([]string{"/library/Show"})
+	q.EnqueueLibraryScan()
+	if len(q.jobs) != 0 {
+		t.Errorf("no media server configured, queue has %d jobs", len(q.jobs))
+	}
+}
+
+func TestMergeMediaScan(t *testing.T) {
+	got := mergeMediaScan(MediaScanPayload{Dirs: []string{"a"}}, MediaScanPayload{Full: true})
+	if !got.Full || len(got.Dirs) != 0 {
+		t.Errorf("a full scan wins over the folders, got %+v", got)
+	}
+}
+
+// A remocao de um episodio pede o scan da pasta de onde ele saiu, pela fila registrada no Start.
+func TestRemovalRequestsMediaScan(t *testing.T) {
+	completed := t.TempDir()
+	showDir := filepath.Join(completed, "Show")
+	video := filepath.Join(showDir, "Season 01", "Show - S01E01.mkv")
+	if err := os.MkdirAll(filepath.Dir(video), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(video, []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saved := []files.EpisodeStruct{{EpisodeNumber: 1, AnimeID: 1, LibraryPaths: []string{video}}}
+	fm := &orchestrationFM{
+		saved:   saved,
+		configs: &files.Config{MediaServers: []files.MediaServer{{Name: "home", Type: "jellyfin", URL: "http://jellyfin:8096", Token: "k"}}},
+	}
+
+	q := NewJobQueue(fm, filepath.Join(t.TempDir(), "jobs.json"))
+	mediaScanQueue.Store(q)
+	t.Cleanup(func() { mediaScanQueue.Store(nil) })
+
+	lib := files.NewLibrarian(files.NewOSFileSystem())
+	if err := removeEpisodesAndLinks(fm, torrents.NewFakeBackend(), lib, []files.EpisodeKey{saved[0].Key()}, saved, false); err != nil {
+		t.Fatalf("removeEpisodesAndLinks: %v", err)
+	}
+	jobs := mediaScanJobs(t, q)
+	if len(jobs) != 1 {
+		t.Fatalf("expected one media scan job after the removal, got %d", len(jobs))
+	}
+	if p := mediaScanPayload(t, jobs[0]); !slices.Equal(p.Dirs, []string{showDir}) {
+		t.Errorf("scan dirs = %v, want the show folder %s", p.Dirs, showDir)
+	}
+}
@@ -0,0 +1,134 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+
+	"path/filepath"
+	"time"
+)
+
+// metadataLookupThrottle espaca as consultas do job de metadados, pelo mesmo motivo da
+// migracao (migrateAnimeIDsThrottle): o backfill de uma biblioteca grande e uma rajada de
+// GetMediaByID.
+var metadataLookupThrottle = 250 * time.Millisecond
+
+// fetchLibraryMedia busca a entrada (episodios dos nfo) e a serie da pasta (tvshow.nfo e
+// arte). Sem Season NN as duas sao a mesma; com ela a serie e a primeira temporada. Entrada
+// desconhecida da AniList devolve (nil, nil, nil).
+func fetchLibraryMedia(animeID, showID int) (entry, show *anilist.Media, err error) {
+	ml, err := anilist.GetMediaByID(animeID)
+	if err != nil || ml == nil {
+		return nil, nil, err
+	}
+	entry, show = &ml.Media, &ml.Media
+	if showID > 0 && showID != animeID {
+		root, err := anilist.GetMediaByID(showID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if root != nil {
+			show = &root.Media
+		}
+	}
+	return entry, show, nil
+}
+
+// writeLibraryMetadata escreve os metadados da biblioteca com os dados da AniList: os .nfo
+// (decisions.md #73) e a arte da serie (#74). Roda como job (JobMetadata): no boot, para o
+// backfill das bibliotecas anteriores a eles, depois de cada organize e depois de cada relink.
+// So consulta a AniList por anime para os que tem algo a fazer (MissingNFOs, poster ausente ou
+// com capa nova), entao os passes seguintes custam a leitura do disco e uma consulta so, a das
+// capas (outdatedPosters).
+//
+// Returns true when every anime got its metadata; false to retry with backoff.
+func writeLibraryMetadata(librarian files.Librarian, fm FileManagerInterface, configs *files.Config) bool {
+	if configs.CompletedAnimePath == "" {
+		return true
+	}
+	saved, err := fm.LoadSavedEpisodes()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Metadata: failed to load saved episodes")
+		return false
+	}
+	sources, err := fm.LoadArtworkSources()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Metadata: failed to load artwork sources")
+		return false
+	}
+
+	// So os arquivos ja no lugar: um move pendente e do relink, que enfileira este job ao
+	// terminar.
+	byAnime := make(map[int][]files.LibraryMove)
+	var order []int
+	for _, mv := range files.PlanLibraryMoves(saved, configs.CompletedAnimePath, configs.LibraryNaming()) {
+		if mv.From != mv.To || mv.AnimeID <= 0 {
+			continue
+		}
+		if _, ok := byAnime[mv.AnimeID]; !ok {
+			order = append(order, mv.AnimeID)
+		}
+		byAnime[mv.AnimeID] = append(byAnime[mv.AnimeID], mv)
+	}
+
+	// A capa trocada na AniList so e vista consultando a serie; sem isto, com o poster ja em
+	// disco, a serie nunca mais seria consultada.
+	outdated := outdatedPosters(sources)
+
+	// Com Season NN varias entradas dividem a pasta da serie: a arte dela sai uma vez por passe.
+	artworkDone := make(map[string]bool)
+	sourcesChanged := false
+	written, failed := 0, 0
+	for _, animeID := range order {
+		moves := byAnime[animeID]
+		showDir, showID := moves[0].ShowDir, moves[0].ShowID
+		needsArtwork := showID > 0 && !artworkDone[showDir] &&
+			(outdated[showID] || !librarian.HasArtwork(filepath.Join(showDir, files.PosterFileName)))
+		if !needsArtwork && !librarian.MissingNFOs(moves) {
+			continue
+		}
+		entry, show, err := fetchLibraryMedia(animeID, showID)
+		if err != nil {
+			logger.Logger.Warn().Err(err).Int("anime_id", animeID).Msg("Metadata: failed to fetch AniList data")
+			failed++
+			continue
+		}
+		if entry == nil {
+			// Id que a AniList nao conhece (removido ou mesclado): retry nao resolve.
+			logger.Logger.Debug().Int("anime_id", animeID).Msg("Metadata: anime not found on AniList, skipping")
+			continue
+		}
+		librarian.WriteNFOs(moves, &files.NFOInfo{Show: showInfo(*show), Episodes: episodeInfos(*entry)})
+
+		// A arte e conferida sempre que a serie foi consultada, nao so quando falta: e assim
+		// que uma capa trocada na AniList chega a biblioteca.
+		if showID > 0 && !artworkDone[showDir] {
+			changed, err := syncShowArtwork(librarian, sources, showDir, showID, *show)
+			if changed {
+				sourcesChanged = true
+			}
+			if err != nil {
+				logger.Logger.Warn().Err(err).Int("anime_id", showID).Str("dir", showDir).Msg("Metadata: failed to download artwork")
+				failed++
+			} else {
+				artworkDone[showDir] = true
+			}
+		}
+		written++
+		time.Sleep(metadataLookupThrottle)
+	}
+
+	if sourcesChanged {
+		if err := fm.SaveArtworkSources(sources); err != nil {
+			// A arte ja esta em disco; sem o registro ela passa por do usuario e nao e trocada se
+			// a capa mudar. Um retry nao resolveria: com a imagem presente, o passe seguinte nem
+			// consulta a AniList.
+			logger.Logger.Warn().Err(err).Msg("Metadata: failed to save artwork sources")
+		}
+	}
+	if written > 0 || failed > 0 {
+		logger.Logger.Info().Int("animes", written).Int("failed", failed).Msg("Wrote library metadata")
+	}
+	return failed == 0
+}
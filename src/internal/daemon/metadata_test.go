@@ -0,0 +1,167 @@
+package daemon
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+)
+
+// mockArtwork responde cada URL de images com os bytes dela (404 para o resto) e conta os
+// downloads.
+func mockArtwork(t *testing.T, images map[string]string, downloads *int) func() {
+	t.Helper()
+	prev := artworkHTTPDo
+	artworkHTTPDo = func(req *http.Request) (*http.Response, error) {
+		*downloads++
+		body, ok := images[req.URL.String()]
+		if !ok {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"image/jpeg"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+	return func() { artworkHTTPDo = prev }
+}
+
+// O job escreve os metadados so dos animes em que falta algo: o segundo passe nao consulta a
+// AniList nem baixa nada.
+func TestWriteLibraryMetadata(t *testing.T) {
+	metadataLookupThrottle = 0
+	calls, downloads := 0, 0
+	// mockSeasonChain responde por id, o que serve tambem para GetMediaByID.
+	defer mockSeasonChain(t, map[int]string{
+		5: `{"data":{"Media":{"id":5,"status":"RELEASING","title":{"romaji":"Show"},"description":"About the show.",
+			"genres":["Comedy"],"streamingEpisodes":[{"title":"Episode 2 - Title Two"}],
+			"coverImage":{"extraLarge":"https://img/xl.jpg","large":"https://img/l.jpg"},"bannerImage":"https://img/banner.jpg"}}}`,
+	})()
+	defer mockArtwork(t, map[string]string{"https://img/xl.jpg": "poster-bytes", "https://img/banner.jpg": "banner-bytes"}, &downloads)()
+
+	completed := t.TempDir()
+	path := filepath.Join(completed, "Show", "Show - E02.mkv")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fm := &orchestrationFM{
+		saved:   []files.EpisodeStruct{{EpisodeHash: "h", AnimeID: 5, AnimeName: "Show", EpisodeNumber: 2, LibraryPaths: []string{path}}},
+		configs: &files.Config{CompletedAnimePath: completed, RenameFilesForJellyfin: true},
+	}
+	lib := &countingLibrarian{Librarian: files.NewLibrarian(files.NewOSFileSystem()), writes: &calls}
+
+	if ok := writeLibraryMetadata(lib, fm, fm.configs); !ok {
+		t.Fatal("writeLibraryMetadata should succeed")
+	}
+	data, err := os.ReadFile(filepath.Join(completed, "Show", "Show - E02.nfo"))
+	if err != nil || !strings.Contains(string(data), "<title>Title Two</title>") {
+		t.Errorf("episode nfo = %q, %v", data, err)
+	}
+	data, err = os.ReadFile(filepath.Join(completed, "Show", "tvshow.nfo"))
+	if err != nil || !strings.Contains(string(data), "<plot>About the show.</plot>") {
+		t.Errorf("tvshow.nfo = %q, %v", data, err)
+	}
+	for name, want := range map[string]string{files.PosterFileName: "poster-bytes", files.FanartFileName: "banner-bytes"} {
+		if data, err := os.ReadFile(filepath.Join(completed, "Show", name)); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v", name, data, err)
+		}
+	}
+	if got := fm.artwork[5]; got.Poster != "https://img/xl.jpg" || got.Fanart != "https://img/banner.jpg" {
+		t.Errorf("artwork sources = %+v", got)
+	}
+
+	if ok := writeLibraryMetadata(lib, fm, fm.configs); !ok {
+		t.Fatal("second pass should succeed")
+	}
+	if calls != 1 || downloads != 2 {
+		t.Errorf("second pass wrote nfo %d times and downloaded %d images, want 1 and 2 (nothing missing)", calls, downloads)
+	}
+}
+
+// Capa trocada na AniList com o poster ja em disco: o passe ve a capa nova na lista e enfileira
+// o job, e o job substitui o poster que o daemon baixou.
+func TestWriteLibraryMetadata_NewCover(t *testing.T) {
+	metadataLookupThrottle = 0
+	downloads, cover := 0, "old"
+	defer anilist.MockAniListDo(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		payload := `{"data":{"Media":{"id":5,"status":"RELEASING","title":{"romaji":"Show"},
+			"coverImage":{"extraLarge":"https://img/extraLarge/bx5-` + cover + `.jpg"}}}}`
+		if strings.Contains(string(body), "GetMediaByIDs") {
+			payload = `{"data":{"Page":{"media":[{"id":5,"title":{"romaji":"Show"},"coverImage":{"large":"https://img/large/bx5-` + cover + `.jpg"}}]}}}`
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(payload))}, nil
+	})()
+	defer mockArtwork(t, map[string]string{"https://img/extraLarge/bx5-new.jpg": "new-poster"}, &downloads)()
+
+	completed := t.TempDir()
+	showDir := filepath.Join(completed, "Show")
+	path := filepath.Join(showDir, "Show - E02.mkv")
+	if err := os.MkdirAll(showDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{path: "video", filepath.Join(showDir, files.PosterFileName): "old-poster"} {
+		if err := os.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fm := &orchestrationFM{
+		saved:   []files.EpisodeStruct{{EpisodeHash: "h", AnimeID: 5, AnimeName: "Show", EpisodeNumber: 2, LibraryPaths: []string{path}}},
+		configs: &files.Config{CompletedAnimePath: completed},
+		artwork: map[int]files.ArtworkSource{5: {Poster: "https://img/extraLarge/bx5-old.jpg"}},
+	}
+
+	lib := files.NewLibrarian(files.NewOSFileSystem())
+	// Primeiro job com a capa de sempre: escreve os nfo e deixa o poster.
+	if ok := writeLibraryMetadata(lib, fm, fm.configs); !ok {
+		t.Fatal("writeLibraryMetadata should succeed")
+	}
+	if downloads != 0 {
+		t.Fatalf("a capa registrada nao mudou, mas %d imagens foram baixadas", downloads)
+	}
+
+	// A capa muda; limpa o cache de 60s do GetMediaByID, que ainda teria a velha.
+	cover = "new"
+	anilist.MockAniListDo(nil)()
+	q := NewJobQueue(fm, filepath.Join(t.TempDir(), "jobs.json"))
+	pass := []anilist.MediaList{{Media: anilist.Media{Id: 5, CoverImage: anilist.CoverImage{Large: "https://img/large/bx5-old.jpg"}}}}
+	checkArtworkSources(fm, q, pass)
+	if len(q.jobs) != 0 {
+		t.Fatal("a mesma capa em outro tamanho nao e capa nova")
+	}
+	pass[0].Media.CoverImage.Large = "https://img/large/bx5-new.jpg"
+	checkArtworkSources(fm, q, pass)
+	if len(q.jobs) != 1 || q.jobs[0].Type != JobMetadata {
+		t.Fatalf("capa nova no passe devia enfileirar o job de metadados, jobs = %v", q.jobs)
+	}
+
+	// Nada falta no disco: so a conferencia das capas leva o job a consultar a serie.
+	if ok := writeLibraryMetadata(lib, fm, fm.configs); !ok {
+		t.Fatal("writeLibraryMetadata should succeed")
+	}
+	if data, _ := os.ReadFile(filepath.Join(showDir, files.PosterFileName)); string(data) != "new-poster" {
+		t.Errorf("poster = %q, want the new cover", data)
+	}
+	if got := fm.artwork[5].Poster; got != "https://img/extraLarge/bx5-new.jpg" {
+		t.Errorf("recorded poster = %q", got)
+	}
+}
+
+type countingLibrarian struct {
+	files.Librarian
+	writes *int
+}
+
+func (c *countingLibrarian) WriteNFOs(moves []files.LibraryMove, info *files.NFOInfo) {
+	*c.writes++
+	c.Librarian.WriteNFOs(moves, info)
+}
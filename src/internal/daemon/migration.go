@@ -36,6 +36,12 @@ func MigrateSavePath(fs files.FileSystem, fm FileManagerInterface, backend torre
 	if configs == nil || configs.SavePath == "" {
 		return nil // nada a migrar
 	}
+	// O save_path legado e da rain. Com um cliente externo, List devolve os torrents DELE, e
+	// a migracao moveria os dados do seedbox para dentro da biblioteca. O campo fica como
+	// esta: voltar para o embedded migra normalmente.
+	if torrents.IsRemoteClient(configs.TorrentClient) {
+		return nil
+	}
 	if configs.CompletedAnimePath == "" {
 		// Config incompleta: sem biblioteca nao ha destino. O passe de verificacao tenta
 		// de novo depois que o usuario salvar a configuracao.
@@ -0,0 +1,234 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// animeMeta copia da entrada da AniList o que os templates de nome da biblioteca precisam.
+// Fica no registro do episodio (denormalizado) porque o relink e o preview rodam sem a lista
+// carregada — e um anime que saiu da lista continua na biblioteca.
+func animeMeta(ml anilist.MediaList) *files.AnimeMeta {
+	meta := &files.AnimeMeta{}
+	if t := ml.Media.Title.Romaji; t != nil {
+		meta.TitleRomaji = *t
+	}
+	if t := ml.Media.Title.English; t != nil {
+		meta.TitleEnglish = *t
+	}
+	season, part := ExtractAnimeSeasonPart(ml.Media.Title, ml.Media.Synonyms)
+	if season != nil {
+		meta.Season = *season
+	}
+	if ml.Media.SeasonYear != nil {
+		meta.Year = *ml.Media.SeasonYear
+	}
+	meta.EpisodeOffset = ComputeEpisodeOffset(ml.Media.Relations, part)
+	meta.Format = string(ml.Media.Format)
+	return meta
+}
+
+// backfillAnimeMeta preenche o Meta dos registros anteriores aos templates, para o {year} e
+// os titulos funcionarem tambem nos episodios ja baixados, e o Format dos anteriores ao layout
+// de filme. So toca animes que estao na lista; os outros seguem com o fallback para AnimeName
+// (e o relink resolve o Format deles quando LibraryMovieLayout precisa).
+func backfillAnimeMeta(fileManager FileManagerInterface, animes []anilist.MediaList) {
+	saved, err := fileManager.LoadSavedEpisodes()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to load saved episodes for the anime metadata backfill")
+		return
+	}
+
+	byID := make(map[int]anilist.MediaList, len(animes))
+	for _, a := range animes {
+		byID[a.Media.Id] = a
+	}
+
+	var updated []files.EpisodeStruct
+	for _, ep := range saved {
+		if ep.Meta != nil && ep.Meta.Format != "" {
+			continue
+		}
+		ml, ok := byID[ep.AnimeID]
+		switch {
+		case !ok:
+			continue
+		case ep.Meta == nil:
+			ep.Meta = animeMeta(ml)
+		case ml.Media.Format != "":
+			ep.Meta.Format = string(ml.Media.Format)
+		default:
+			continue
+		}
+		updated = append(updated, ep)
+	}
+	if len(updated) == 0 {
+		return
+	}
+	if err := fileManager.UpsertEpisodes(updated); err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to save the anime metadata backfill")
+		return
+	}
+	logger.Logger.Info().Int("count", len(updated)).Msg("Backfilled anime metadata on saved episodes")
+}
+
+// relinkLibrary move os arquivos ja organizados para o nome que a config atual da. O plano e
+// o mesmo do preview (files.PlanLibraryMoves); cada move que da certo atualiza o LibraryPaths
+// do registro, entao uma falha no meio deixa registros e disco coerentes e o retry continua de
+// onde parou.
+//
+// Returns true when every move succeeded; false to retry with backoff.
+func relinkLibrary(librarian files.Librarian, fm FileManagerInterface, configs *files.Config) bool {
+	if configs.CompletedAnimePath == "" {
+		return true
+	}
+	saved, err := fm.LoadSavedEpisodes()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Relink: failed to load saved episodes")
+		return false
+	}
+
+	if configs.LibrarySeasonFolders {
+		if ok := resolveShowMeta(fm, saved); !ok {
+			return false
+		}
+	}
+	if configs.LibraryMovieLayout {
+		if ok := resolveMediaFormat(fm, saved); !ok {
+			return false
+		}
+	}
+
+	moved := make(map[string]string)
+	shows := make(map[string]files.LibraryMove)
+	failed := 0
+	for _, mv := range files.PlanLibraryMoves(saved, configs.CompletedAnimePath, configs.LibraryNaming()) {
+		if mv.From == mv.To {
+			continue
+		}
+		if err := librarian.MoveInLibrary(mv.From, mv.To); err != nil {
+			logger.Logger.Warn().Err(err).Str("from", mv.From).Str("to", mv.To).Msg("Relink: failed to move library file")
+			failed++
+			continue
+		}
+		moved[mv.From] = mv.To
+		if !mv.Movie {
+			shows[mv.ShowDir] = mv
+		}
+	}
+	// MoveInLibrary leva o nfo de pasta para pasta, mas nao para dentro de uma Season NN (la
+	// ele e o da serie): a pasta raiz nova ganha o dela aqui. O movie.nfo da pasta de filme vem
+	// do job de metadados, que o relink enfileira ao terminar.
+	for dir, mv := range shows {
+		librarian.EnsureShowNFO(dir, mv.ShowTitle, mv.ShowID)
+	}
+
+	if len(moved) > 0 {
+		var updated []files.EpisodeStruct
+		for _, ep := range saved {
+			changed := false
+			paths := make([]string, len(ep.LibraryPaths))
+			for i, p := range ep.LibraryPaths {
+				paths[i] = p
+				if to, ok := moved[p]; ok {
+					paths[i] = to
+					changed = true
+				}
+			}
+			if changed {
+				ep.LibraryPaths = paths
+				updated = append(updated, ep)
+			}
+		}
+		if err := fm.UpsertEpisodes(updated); err != nil {
+			// Os arquivos ja estao no lugar novo; o retry ve origem ausente e destino presente
+			// (MoveInLibrary trata como feito) e grava de novo.
+			logger.Logger.Warn().Err(err).Msg("Relink: failed to persist library paths")
+			return false
+		}
+		logger.Logger.Info().Int("files", len(moved)).Int("failed", failed).Msg("Relinked library files to the current naming")
+	}
+	return failed == 0
+}
+
+// resolveShowMeta preenche Meta.Show dos registros ja organizados, para o plano do relink
+// saber a serie de cada um. Grava o que resolveu mesmo quando uma consulta falha no meio: o
+// retry so consulta o que falta. Altera saved no lugar.
+func resolveShowMeta(fm FileManagerInterface, saved []files.EpisodeStruct) bool {
+	var updated []files.EpisodeStruct
+	ok := true
+	for i := range saved {
+		if len(saved[i].LibraryPaths) == 0 {
+			continue
+		}
+		changed, err := ensureShowMeta(&saved[i])
+		if err != nil {
+			logger.Logger.Warn().Err(err).Int("anime_id", saved[i].AnimeID).Msg("Relink: failed to resolve the series for season folders")
+			ok = false
+			break
+		}
+		if changed {
+			updated = append(updated, saved[i])
+		}
+	}
+	if len(updated) > 0 {
+		if err := fm.UpsertEpisodes(updated); err != nil {
+			logger.Logger.Warn().Err(err).Msg("Relink: failed to save the resolved series")
+			return false
+		}
+	}
+	return ok
+}
+
+// resolveMediaFormat preenche Meta.Format dos registros ja organizados que nao o tem, para o
+// plano do relink saber quais sao filmes (LibraryMovieLayout). Grava o que resolveu mesmo
+// quando uma consulta falha no meio. Altera saved no lugar.
+func resolveMediaFormat(fm FileManagerInterface, saved []files.EpisodeStruct) bool {
+	var updated []files.EpisodeStruct
+	ok := true
+	for i := range saved {
+		if len(saved[i].LibraryPaths) == 0 {
+			continue
+		}
+		changed, err := ensureMediaFormat(&saved[i])
+		if err != nil {
+			logger.Logger.Warn().Err(err).Int("anime_id", saved[i].AnimeID).Msg("Relink: failed to resolve the media format for the movie layout")
+			ok = false
+			break
+		}
+		if changed {
+			updated = append(updated, saved[i])
+		}
+	}
+	if len(updated) > 0 {
+		if err := fm.UpsertEpisodes(updated); err != nil {
+			logger.Logger.Warn().Err(err).Msg("Relink: failed to save the resolved media formats")
+			return false
+		}
+	}
+	return ok
+}
+
+// ensureMediaFormat preenche o Meta.Format de um registro anterior ao campo (ou de anime fora
+// da lista) pela AniList; GetMediaByID tem cache, entao os episodios de um anime custam uma
+// consulta. Anime que a AniList nao conhece fica sem Format, no layout de serie. Retorna true
+// quando mudou o registro.
+func ensureMediaFormat(ep *files.EpisodeStruct) (bool, error) {
+	if ep.AnimeID <= 0 || (ep.Meta != nil && ep.Meta.Format != "") {
+		return false, nil
+	}
+	ml, err := anilist.GetMediaByID(ep.AnimeID)
+	if err != nil {
+		return false, err
+	}
+	if ml == nil || ml.Media.Format == "" {
+		return false, nil
+	}
+	if ep.Meta == nil {
+		ep.Meta = animeMeta(*ml)
+	} else {
+		ep.Meta.Format = string(ml.Media.Format)
+	}
+	return true, nil
+}
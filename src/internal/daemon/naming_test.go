@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+)
+
+func TestAnimeMeta(t *testing.T) {
+	english := "Attack on Titan Season 3 Part 2"
+	romaji := "Shingeki no Kyojin Season 3 Part 2"
+	year := 2019
+	prequelEpisodes := 12
+	ml := anilist.MediaList{Media: anilist.Media{
+		Title:      anilist.Title{English: &english, Romaji: &romaji},
+		SeasonYear: &year,
+		Relations: anilist.MediaRelations{Edges: []anilist.MediaRelationEdge{
+			{RelationType: "PREQUEL", Node: anilist.MediaRelationNode{Episodes: &prequelEpisodes}},
+		}},
+	}}
+
+	got := animeMeta(ml)
+	want := files.AnimeMeta{TitleRomaji: romaji, TitleEnglish: english, Season: 3, Year: 2019, EpisodeOffset: 12}
+	if *got != want {
+		t.Errorf("animeMeta = %+v, want %+v", *got, want)
+	}
+}
+
+// Trocar os templates depois de organizar move os arquivos para o nome novo e atualiza os
+// LibraryPaths; um segundo passe nao tem mais nada a fazer.
+func TestRelinkLibraryMovesOrganizedFiles(t *testing.T) {
+	dataDir := makeTorrentDataDir(t)
+	completed := t.TempDir()
+	const hash = "0123456789abcdef0123456789abcdef01234567"
+
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted(hash, dataDir)
+
+	fm := &orchestrationFM{
+		saved: []files.EpisodeStruct{
+			{EpisodeHash: hash, AnimeID: 42, AnimeName: "My Anime", EpisodeNumber: 5, Meta: &files.AnimeMeta{Season: 2, Year: 2024}},
+		},
+		configs: &files.Config{CompletedAnimePath: completed, RenameFilesForJellyfin: true},
+	}
+	lib := files.NewLibrarian(files.NewOSFileSystem())
+	if ok := organizeTorrent(hash, backend, lib, fm, fm.configs); !ok {
+		t.Fatal("organizeTorrent should succeed")
+	}
+	oldPath := filepath.Join(completed, "My Anime", "My Anime - E05.mkv")
+	if fm.saved[0].LibraryPaths[0] != oldPath {
+		t.Fatalf("LibraryPaths = %v, want [%s]", fm.saved[0].LibraryPaths, oldPath)
+	}
+
+	fm.configs.LibraryFolderTemplate = "{title} ({year})"
+	fm.configs.LibraryFileTemplate = "{title} - S{season:02}E{episode:02}"
+	if ok := relinkLibrary(lib, fm, fm.configs); !ok {
+		t.Fatal("relinkLibrary should succeed")
+	}
+
+	newPath := filepath.Join(completed, "My Anime (2024)", "My Anime - S02E05.mkv")
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("expected relinked file %s: %v", newPath, err)
+	}
+	if _, err := os.Stat(filepath.Dir(oldPath)); !os.IsNotExist(err) {
+		t.Errorf("old anime folder should be gone: %v", err)
+	}
+	if got := fm.saved[0].LibraryPaths; len(got) != 1 || got[0] != newPath {
+		t.Errorf("LibraryPaths = %v, want [%s]", got, newPath)
+	}
+
+	upserts := len(fm.upserted)
+	if ok := relinkLibrary(lib, fm, fm.configs); !ok {
+		t.Fatal("second relinkLibrary should succeed")
+	}
+	if len(fm.upserted) != upserts {
+		t.Errorf("second relink wrote %d more upserts, want none", len(fm.upserted)-upserts)
+	}
+}
+
+// O backfill so completa registro sem Meta de anime que esta na lista; o Meta existente fica.
+func TestBackfillAnimeMeta(t *testing.T) {
+	romaji := "Sousou no Frieren"
+	fm := &orchestrationFM{saved: []files.EpisodeStruct{
+		{AnimeID: 1, AnimeName: "Frieren", EpisodeNumber: 1},
+		{AnimeID: 1, AnimeName: "Frieren", EpisodeNumber: 2, Meta: &files.AnimeMeta{TitleRomaji: "kept"}},
+		{AnimeID: 9, AnimeName: "Gone", EpisodeNumber: 3},
+	}}
+	backfillAnimeMeta(fm, []anilist.MediaList{{Media: anilist.Media{Id: 1, Title: anilist.Title{Romaji: &romaji}}}})
+
+	if len(fm.upserted) != 1 || len(fm.upserted[0]) != 1 {
+		t.Fatalf("upserted = %+v, want exactly the one record without Meta", fm.upserted)
+	}
+	if got := fm.upserted[0][0]; got.EpisodeNumber != 1 || got.Meta == nil || got.Meta.TitleRomaji != romaji {
+		t.Errorf("backfilled record = %+v", got)
+	}
+}
+
+// Meta anterior ao layout de filme ganha so o Format; o resto do Meta fica.
+func TestBackfillAnimeMetaFormat(t *testing.T) {
+	fm := &orchestrationFM{saved: []files.EpisodeStruct{
+		{AnimeID: 1, AnimeName: "Movie", EpisodeNumber: 1, Meta: &files.AnimeMeta{TitleRomaji: "kept", Year: 2016}},
+		{AnimeID: 1, AnimeName: "Movie", EpisodeNumber: 2, Meta: &files.AnimeMeta{Format: "MOVIE"}},
+	}}
+	backfillAnimeMeta(fm, []anilist.MediaList{{Media: anilist.Media{Id: 1, Format: anilist.MediaFormatMovie}}})
+
+	if len(fm.upserted) != 1 || len(fm.upserted[0]) != 1 {
+		t.Fatalf("upserted = %+v, want exactly the one record without Format", fm.upserted)
+	}
+	got := fm.upserted[0][0].Meta
+	if !got.IsMovie() || got.TitleRomaji != "kept" || got.Year != 2016 {
+		t.Errorf("backfilled meta = %+v", got)
+	}
+}
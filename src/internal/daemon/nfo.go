@@ -0,0 +1,88 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/idmap"
+
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	reDescriptionBreak = regexp.MustCompile(`(?i)<br\s*/?>`)
+	reDescriptionTag   = regexp.MustCompile(`<[^>]+>`)
+	reBlankLines       = regexp.MustCompile(`\n{3,}`)
+	// reStreamingEpisode le "Episode 3 - Titulo" (Crunchyroll e a maioria dos sites); o
+	// numero e o do site, que para a AniList e o da entrada.
+	reStreamingEpisode = regexp.MustCompile(`(?i)^Episode\s+(\d+)\s*[-:–]\s*(.+)$`)
+)
+
+// showInfo copia da midia da AniList o que vai no tvshow.nfo.
+func showInfo(m anilist.Media) files.ShowInfo {
+	info := files.ShowInfo{
+		Synonyms: m.Synonyms,
+		Genres:   m.Genres,
+		Status:   string(m.Status),
+	}
+	if m.Title.Romaji != nil {
+		info.OriginalTitle = *m.Title.Romaji
+	}
+	if m.SeasonYear != nil {
+		info.Year = *m.SeasonYear
+	}
+	if m.Description != nil {
+		info.Plot = plainDescription(*m.Description)
+	}
+	for _, s := range m.Studios.Nodes {
+		info.Studios = append(info.Studios, s.Name)
+	}
+	if ids, ok := idmap.ByAniList(m.Id); ok {
+		info.MyAnimeListID, info.AniDBID, info.KitsuID = ids.MyAnimeList, ids.AniDB, ids.Kitsu
+	}
+	return info
+}
+
+// plainDescription tira o HTML que a AniList deixa na descricao mesmo com asHtml: false
+// (<br>, <i>) e as entidades.
+func plainDescription(s string) string {
+	s = reDescriptionBreak.ReplaceAllString(s, "\n")
+	s = reDescriptionTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(strings.ReplaceAll(s, "\r", ""))
+	s = reBlankLines.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// episodeInfos monta os episodios da entrada pelo numero dela: titulo dos streamingEpisodes,
+// data dos nos do airingSchedule que ja foram ao ar. O airingSchedule vem clipado
+// (decisions.md #52), entao episodios antigos de animes longos ficam sem data.
+func episodeInfos(m anilist.Media) map[int]files.EpisodeInfo {
+	eps := make(map[int]files.EpisodeInfo)
+	for _, node := range m.AiringSchedule.Nodes {
+		if node.Episode <= 0 || node.AiringAt <= 0 || node.TimeUntilAiring > 0 {
+			continue
+		}
+		ep := eps[node.Episode]
+		ep.Aired = time.Unix(node.AiringAt, 0)
+		eps[node.Episode] = ep
+	}
+	for _, se := range m.StreamingEpisodes {
+		match := reStreamingEpisode.FindStringSubmatch(strings.TrimSpace(se.Title))
+		if match == nil {
+			continue
+		}
+		n, err := strconv.Atoi(match[1])
+		if err != nil || n <= 0 {
+			continue
+		}
+		ep := eps[n]
+		if ep.Title == "" {
+			ep.Title = strings.TrimSpace(match[2])
+		}
+		eps[n] = ep
+	}
+	return eps
+}
@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+)
+
+func TestShowInfoAndEpisodeInfos(t *testing.T) {
+	romaji := "Boku no Anime"
+	year := 2024
+	desc := "First line.<br><br>\n<i>Second</i> &amp; last.<br>"
+	m := anilist.Media{
+		Status:      anilist.MediaStatusFinished,
+		Title:       anilist.Title{Romaji: &romaji},
+		SeasonYear:  &year,
+		Description: &desc,
+		Genres:      []string{"Drama"},
+		AiringSchedule: anilist.AiringSchedule{Nodes: []anilist.AiringNode{
+			{Episode: 1, AiringAt: time.Date(2024, 1, 6, 15, 0, 0, 0, time.UTC).Unix(), TimeUntilAiring: -100},
+			// Ainda nao foi ao ar: sem data.
+			{Episode: 3, AiringAt: time.Now().Add(time.Hour).Unix(), TimeUntilAiring: 3600},
+		}},
+		StreamingEpisodes: []anilist.StreamingEpisode{
+			{Title: "Episode 1 - The Beginning"},
+			{Title: "Episode 2: Second Step"},
+			{Title: "Recap Special"},
+		},
+	}
+	m.Studios.Nodes = append(m.Studios.Nodes, struct {
+		Name string `json:"name"`
+	}{Name: "MAPPA"})
+
+	show := showInfo(m)
+	if show.OriginalTitle != romaji || show.Year != 2024 || show.Status != "FINISHED" || len(show.Studios) != 1 {
+		t.Errorf("showInfo = %+v", show)
+	}
+	if want := "First line.\n\nSecond & last."; show.Plot != want {
+		t.Errorf("Plot = %q, want %q", show.Plot, want)
+	}
+
+	eps := episodeInfos(m)
+	if eps[1].Title != "The Beginning" || eps[1].Aired.IsZero() {
+		t.Errorf("episode 1 = %+v", eps[1])
+	}
+	if eps[2].Title != "Second Step" || !eps[2].Aired.IsZero() {
+		t.Errorf("episode 2 = %+v", eps[2])
+	}
+	if _, ok := eps[3]; ok {
+		t.Errorf("an episode that has not aired should have no data: %+v", eps[3])
+	}
+}
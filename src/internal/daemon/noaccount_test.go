@@ -40,7 +40,7 @@ func TestSearchAnilist_NoAccountsStillProcessesStandalone(t *testing.T) {
 	configs.AnilistUsernames = []string{}
 
 	fm := &mockFileManagerForEpisodes{standaloneAnimes: []int{500}}
-	resp, err := searchAnilist(fm, configs, []int{500})
+	resp, _, err := searchAnilist(fm, configs, []int{500})
 	if err != nil {
 		t.Fatalf("sem conta nao e erro: %v", err)
 	}
@@ -54,7 +54,7 @@ func TestSearchAnilist_NoLibraryStillFails(t *testing.T) {
 	configs := standaloneTestConfig()
 	configs.CompletedAnimePath = ""
 
-	if _, err := searchAnilist(&mockFileManagerForEpisodes{}, configs, nil); err == nil {
+	if _, _, err := searchAnilist(&mockFileManagerForEpisodes{}, configs, nil); err == nil {
 		t.Fatal("quero erro sem biblioteca configurada")
 	}
 }
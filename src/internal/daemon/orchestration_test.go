@@ -19,6 +19,7 @@ type orchestrationFM struct {
 	upserted [][]files.EpisodeStruct
 	deleted  []files.EpisodeKey
 	configs  *files.Config
+	artwork  map[int]files.ArtworkSource
 }
 
 func (m *orchestrationFM) LoadSavedEpisodes() ([]files.EpisodeStruct, error) { return m.saved, nil }
@@ -35,6 +36,17 @@ func (m *orchestrationFM) UpsertEpisodes(eps []files.EpisodeStruct) error {
 	}
 	return nil
 }
+func (m *orchestrationFM) LoadArtworkSources() (map[int]files.ArtworkSource, error) {
+	out := map[int]files.ArtworkSource{}
+	for k, v := range m.artwork {
+		out[k] = v
+	}
+	return out, nil
+}
+func (m *orchestrationFM) SaveArtworkSources(sources map[int]files.ArtworkSource) error {
+	m.artwork = sources
+	return nil
+}
 func (m *orchestrationFM) DeleteEpisodesFromFile(keys []files.EpisodeKey) error {
 	m.deleted = append(m.deleted, keys...)
 	return nil
@@ -251,8 +263,9 @@ func TestTorrentCompletion_EnqueuesAndRunsOrganize(t *testing.T) {
 	// ...and the queue actually executes it. This leg had no coverage before.
 	q.processDueJobs()
 
-	if len(q.jobs) != 0 {
-		t.Errorf("job should be drained after a successful run, %d left", len(q.jobs))
+	// What is left is the nfo job the successful organize enqueues.
+	if len(q.jobs) != 1 || q.jobs[0].Type != JobMetadata {
+		t.Errorf("organize job should be drained after a successful run, leaving the nfo job; queue has %d", len(q.jobs))
 	}
 	wantLink := filepath.Join(completed, "My Anime", "My Anime - E05.mkv")
 	linkInfo, err := os.Stat(wantLink)
@@ -506,7 +519,7 @@ func TestReconcileLibrary(t *testing.T) {
 	}
 
 	q := NewJobQueue(&orchestrationFM{}, filepath.Join(t.TempDir(), "jobs.json"))
-	reconcileLibrary(downloaded, saved, q)
+	reconcileLibrary(&files.Config{}, downloaded, saved, q)
 
 	// Only the pending (completed, no LibraryPaths) torrent should be enqueued.
 	if len(q.jobs) != 1 {
@@ -538,6 +551,7 @@ func TestReconcileLibrary_Marker(t *testing.T) {
 		const hash = "1111111111111111111111111111111111111111"
 		q := newQueue(t)
 		reconcileLibrary(
+			&files.Config{},
 			[]torrents.TorrentInfo{{Hash: hash, Completed: true}},
 			[]files.EpisodeStruct{{EpisodeNumber: 1, EpisodeHash: hash}},
 			q,
@@ -556,6 +570,7 @@ func TestReconcileLibrary_Marker(t *testing.T) {
 
 		q := newQueue(t)
 		reconcileLibrary(
+			&files.Config{},
 			[]torrents.TorrentInfo{{Hash: hash, Completed: true}},
 			[]files.EpisodeStruct{{EpisodeNumber: 1, EpisodeHash: hash, LibraryPaths: []string{missing}}},
 			q,
@@ -569,6 +584,7 @@ func TestReconcileLibrary_Marker(t *testing.T) {
 		const hash = "3333333333333333333333333333333333333333"
 		q := newQueue(t)
 		reconcileLibrary(
+			&files.Config{},
 			[]torrents.TorrentInfo{{Hash: hash, Completed: false}},
 			[]files.EpisodeStruct{{EpisodeNumber: 1, EpisodeHash: hash}},
 			q,
@@ -582,6 +598,7 @@ func TestReconcileLibrary_Marker(t *testing.T) {
 		const hash = "4444444444444444444444444444444444444444"
 		q := newQueue(t)
 		reconcileLibrary(
+			&files.Config{},
 			[]torrents.TorrentInfo{{Hash: hash, Completed: true}},
 			[]files.EpisodeStruct{
 				{EpisodeNumber: 1, EpisodeHash: hash, IsBatch: true, LibraryPaths: []string{"/lib/a.mkv"}},
@@ -597,6 +614,7 @@ func TestReconcileLibrary_Marker(t *testing.T) {
 	t.Run("orphan torrent with no saved episode is not enqueued", func(t *testing.T) {
 		q := newQueue(t)
 		reconcileLibrary(
+			&files.Config{},
 			[]torrents.TorrentInfo{{Hash: "5555555555555555555555555555555555555555", Completed: true}},
 			nil,
 			q,
@@ -612,9 +630,9 @@ func TestReconcileLibrary_Marker(t *testing.T) {
 		saved := []files.EpisodeStruct{{EpisodeNumber: 1, EpisodeHash: hash}}
 
 		q := newQueue(t)
-		reconcileLibrary(downloaded, saved, q)
-		reconcileLibrary(downloaded, saved, q)
-		reconcileLibrary(downloaded, saved, q)
+		reconcileLibrary(&files.Config{}, downloaded, saved, q)
+		reconcileLibrary(&files.Config{}, downloaded, saved, q)
+		reconcileLibrary(&files.Config{}, downloaded, saved, q)
 
 		if got := queuedOrganizeHashes(t, q); len(got) != 1 {
 			t.Errorf("reconciliation runs every pass; enqueue must dedupe, got %v", got)
@@ -645,7 +663,7 @@ func TestClearLibraryPathsAfterRootSwap(t *testing.T) {
 		}
 
 		q := NewJobQueue(&orchestrationFM{}, filepath.Join(t.TempDir(), "jobs.json"))
-		reconcileLibrary([]torrents.TorrentInfo{{Hash: hash, Completed: true}}, fm.saved, q)
+		reconcileLibrary(&files.Config{}, []torrents.TorrentInfo{{Hash: hash, Completed: true}}, fm.saved, q)
 		if got := queuedOrganizeHashes(t, q); len(got) != 1 || got[0] != hash {
 			t.Errorf("a cleared record must be re-enqueued for organizing, got %v", got)
 		}
@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// PlaybackAction is what RecordPlayback did with a watched file.
+type PlaybackAction string
+
+const (
+	// PlaybackProgressUpdated: the standalone anime's progress moved up to the episode.
+	PlaybackProgressUpdated PlaybackAction = "progress_updated"
+	// PlaybackAlreadyWatched: the progress was already at or past the episode.
+	PlaybackAlreadyWatched PlaybackAction = "already_watched"
+	// PlaybackListAnime: the anime is in an AniList list, whose progress AniList owns. With
+	// anilist_playback_sync, Accounts reports the write on each account.
+	PlaybackListAnime PlaybackAction = "list_anime"
+	// PlaybackNotTracked: no episode record has the file among its LibraryPaths, or the file is
+	// not an episode of its pack (an extra, a trailer, a special).
+	PlaybackNotTracked PlaybackAction = "not_tracked"
+	// PlaybackIgnored: the webhook was not a "watched" event (a pause, a start, a new item).
+	PlaybackIgnored PlaybackAction = "ignored"
+)
+
+// PlaybackResult reports which episode a watched file was and what happened to its progress.
+type PlaybackResult struct {
+	Action        PlaybackAction `json:"action" example:"progress_updated"`
+	AnimeID       int            `json:"anime_id,omitempty" example:"154587"`
+	AnimeName     string         `json:"anime_name,omitempty" example:"Sousou no Frieren"`
+	EpisodeNumber int            `json:"episode_number,omitempty" example:"5"`
+	Progress      int            `json:"progress,omitempty" example:"5"`
+	// Accounts is the AniList write of a list anime, with anilist_playback_sync on.
+	Accounts []AccountWriteBack `json:"accounts,omitempty"`
+}
+
+// RecordPlayback marks the episode of a watched library file as seen: the file is looked up in
+// the LibraryPaths of the saved episodes, and a standalone anime's AnimeSettings.Progress moves
+// up to that episode. The progress never moves down — rewatching episode 1 keeps 12.
+//
+// Anime de lista so avanca com anilist_playback_sync, e na propria AniList, pelas contas com
+// login (writeBackProgress): o progresso dela e o da AniList. A poda de assistidos roda no
+// proximo passe, com o progresso novo, pelo mesmo caminho do progresso digitado na tela.
+func RecordPlayback(fm FileManagerInterface, configs *files.Config, path string) (*PlaybackResult, error) {
+	saved, err := fm.LoadSavedEpisodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saved episodes: %w", err)
+	}
+	ep, ok := episodeByLibraryPath(saved, path, configs.LibraryNaming())
+	if !ok {
+		logger.Logger.Debug().Str("path", path).Msg("Watched file is not in the library records, ignoring it")
+		return &PlaybackResult{Action: PlaybackNotTracked}, nil
+	}
+	result := &PlaybackResult{AnimeID: ep.AnimeID, AnimeName: ep.AnimeName, EpisodeNumber: ep.EpisodeNumber}
+
+	standalone, err := fm.LoadStandaloneAnimes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load standalone animes: %w", err)
+	}
+	if !slices.Contains(standalone, ep.AnimeID) {
+		result.Action = PlaybackListAnime
+		if !configs.AnilistPlaybackSync {
+			logger.Logger.Info().Int("anime_id", ep.AnimeID).Int("episode", ep.EpisodeNumber).
+				Msg("Watched an episode of a list anime; its progress is the one on AniList")
+			return result, nil
+		}
+		result.Accounts = writeBackProgress(fm, configs, ep.AnimeID, ep.EpisodeNumber)
+		return result, nil
+	}
+
+	progress, changed, err := advanceStandaloneProgress(fm, ep.AnimeID, ep.EpisodeNumber)
+	if err != nil {
+		return nil, err
+	}
+	result.Progress = progress
+	if !changed {
+		result.Action = PlaybackAlreadyWatched
+		return result, nil
+	}
+	result.Action = PlaybackProgressUpdated
+	logger.Logger.Info().Int("anime_id", ep.AnimeID).Str("anime", ep.AnimeName).Int("progress", progress).
+		Msg("Standalone anime progress advanced from playback")
+	return result, nil
+}
+
+// episodeByLibraryPath acha o registro do episodio que o arquivo e. Os dois lados passam pelo
+// filepath.Clean: o caminho vem do servidor de midia, remapeado, e pode sobrar uma barra.
+//
+// Todo registro de um pack tem todos os arquivos do pack nos LibraryPaths (o organize grava a
+// lista inteira em cada um), entao o arquivo so diz o torrent. O episodio sai do nome do
+// arquivo, como no relink; extras, trailers e especiais nao sao episodio de ninguem.
+func episodeByLibraryPath(saved []files.EpisodeStruct, path string, naming files.LibraryNaming) (files.EpisodeStruct, bool) {
+	path = filepath.Clean(path)
+	var candidates []files.EpisodeStruct
+	for _, ep := range saved {
+		if slices.ContainsFunc(ep.LibraryPaths, func(p string) bool { return filepath.Clean(p) == path }) {
+			candidates = append(candidates, ep)
+		}
+	}
+	if len(candidates) == 1 && !candidates[0].IsBatch {
+		return candidates[0], true
+	}
+	for _, ep := range candidates {
+		entry, ok := files.LibraryFileEpisode(ep, path, naming)
+		if !ok {
+			return files.EpisodeStruct{}, false
+		}
+		if ep.EpisodeNumber == entry {
+			return ep, true
+		}
+	}
+	return files.EpisodeStruct{}, false
+}
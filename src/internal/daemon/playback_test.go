@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"path/filepath"
+	"testing"
+
+	"AutoAnimeDownloader/src/internal/files"
+)
+
+// playbackFM grava o progresso salvo, que o mock de episodios descarta.
+type playbackFM struct {
+	mockFileManagerForEpisodes
+}
+
+func (m *playbackFM) SaveAnimeSettings(id int, s files.AnimeSettings) error {
+	if m.settings == nil {
+		m.settings = map[int]files.AnimeSettings{}
+	}
+	m.settings[id] = s
+	return nil
+}
+
+func TestRecordPlayback(t *testing.T) {
+	lib := t.TempDir()
+	standaloneEp := filepath.Join(lib, "Frieren", "Frieren - S01E05.mkv")
+	listEp := filepath.Join(lib, "Dandadan", "Dandadan - S01E02.mkv")
+	fm := &playbackFM{mockFileManagerForEpisodes{
+		savedEpisodes: []files.EpisodeStruct{
+			{AnimeID: 1, AnimeName: "Frieren", EpisodeNumber: 5, LibraryPaths: []string{standaloneEp}},
+			{AnimeID: 2, AnimeName: "Dandadan", EpisodeNumber: 2, LibraryPaths: []string{listEp}},
+		},
+		standaloneAnimes: []int{1},
+		settings:         map[int]files.AnimeSettings{1: {Progress: 3, CustomSearchQuery: "frieren"}},
+	}}
+
+	res, err := RecordPlayback(fm, &files.Config{}, standaloneEp+string(filepath.Separator))
+	if err != nil {
+		t.Fatalf("RecordPlayback: %v", err)
+	}
+	if res.Action != PlaybackProgressUpdated || res.Progress != 5 || res.AnimeID != 1 {
+		t.Fatalf("standalone result = %+v", res)
+	}
+	if s := fm.settings[1]; s.Progress != 5 || s.CustomSearchQuery != "frieren" {
+		t.Errorf("settings = %+v, want progress 5 and the search query kept", s)
+	}
+
+	// Rever um episodio antigo nao volta o progresso.
+	fm.settings[1] = files.AnimeSettings{Progress: 9}
+	if res, _ := RecordPlayback(fm, &files.Config{}, standaloneEp); res.Action != PlaybackAlreadyWatched || fm.settings[1].Progress != 9 {
+		t.Errorf("rewatch: result %+v, progress %d", res, fm.settings[1].Progress)
+	}
+
+	if res, _ := RecordPlayback(fm, &files.Config{}, listEp); res.Action != PlaybackListAnime {
+		t.Errorf("list anime: result %+v", res)
+	}
+	if _, ok := fm.settings[2]; ok {
+		t.Error("a list anime's settings must not get a progress")
+	}
+
+	if res, _ := RecordPlayback(fm, &files.Config{}, filepath.Join(lib, "Other", "x.mkv")); res.Action != PlaybackNotTracked {
+		t.Errorf("unknown file: result %+v", res)
+	}
+}
+
+// Todo registro de um pack tem todos os arquivos do pack: o episodio sai do nome do arquivo,
+// nao do primeiro registro que tem o caminho.
+func TestRecordPlayback_Batch(t *testing.T) {
+	lib := t.TempDir()
+	dir := filepath.Join(lib, "Frieren")
+	pack := []string{
+		filepath.Join(dir, "Frieren - S01E01.mkv"),
+		filepath.Join(dir, "Frieren - S01E05.mkv"),
+		filepath.Join(dir, "extras", "NCOP.mkv"),
+		filepath.Join(lib, "Frieren", "Specials", "Frieren - S00E01.mkv"),
+	}
+	fm := &playbackFM{mockFileManagerForEpisodes{
+		savedEpisodes: []files.EpisodeStruct{
+			{AnimeID: 1, AnimeName: "Frieren", EpisodeNumber: 1, EpisodeHash: "h", IsBatch: true, LibraryPaths: pack},
+			{AnimeID: 1, AnimeName: "Frieren", EpisodeNumber: 5, EpisodeHash: "h", IsBatch: true, LibraryPaths: pack},
+		},
+		standaloneAnimes: []int{1},
+		settings:         map[int]files.AnimeSettings{1: {Progress: 3}},
+	}}
+
+	res, err := RecordPlayback(fm, &files.Config{}, pack[1])
+	if err != nil {
+		t.Fatalf("RecordPlayback: %v", err)
+	}
+	if res.Action != PlaybackProgressUpdated || res.EpisodeNumber != 5 || fm.settings[1].Progress != 5 {
+		t.Fatalf("E05 of the pack: result %+v, progress %d", res, fm.settings[1].Progress)
+	}
+
+	for _, extra := range pack[2:] {
+		if res, _ := RecordPlayback(fm, &files.Config{}, extra); res.Action != PlaybackNotTracked {
+			t.Errorf("%s: result %+v, want not_tracked", extra, res)
+		}
+	}
+	if fm.settings[1].Progress != 5 {
+		t.Errorf("an extra moved the progress to %d", fm.settings[1].Progress)
+	}
+
+	// O pack traz um episodio que nenhum registro pediu: nao e de ninguem.
+	fm.savedEpisodes[0].LibraryPaths = append(pack, filepath.Join(dir, "Frieren - S01E09.mkv"))
+	fm.savedEpisodes[1].LibraryPaths = fm.savedEpisodes[0].LibraryPaths
+	if res, _ := RecordPlayback(fm, &files.Config{}, filepath.Join(dir, "Frieren - S01E09.mkv")); res.Action != PlaybackNotTracked {
+		t.Errorf("episode without a record: result %+v", res)
+	}
+}
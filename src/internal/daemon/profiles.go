@@ -0,0 +1,157 @@
+package daemon
+
+import (
+	"slices"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// listWants diz quais contas querem cada anime do passe: as que o tem num dos seus
+// DownloadStatuses, fora das suas listas excluidas. Avulso, sequencia seguida e trial nao
+// estao aqui — nenhuma conta os pediu, e eles ficam na raiz.
+type listWants struct {
+	accounts map[int][]string
+	// complete e false quando a busca de alguma conta falhou no passe: faltar uma conta e "nao
+	// sei", e ai nenhuma biblioteca perde um anime (ver syncProfileLibraries).
+	complete bool
+}
+
+func newListWants() listWants {
+	return listWants{accounts: make(map[int][]string), complete: true}
+}
+
+func (w listWants) add(mediaID int, account string) {
+	if !slices.Contains(w.accounts[mediaID], account) {
+		w.accounts[mediaID] = append(w.accounts[mediaID], account)
+	}
+}
+
+// anyDeleteStatuses diz se alguma conta apaga por status — pelos globais ou pelo seu perfil.
+func anyDeleteStatuses(configs *files.Config) bool {
+	if len(configs.DeleteStatuses) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(configs.AccountProfiles, func(p files.AccountProfile) bool { return len(p.DeleteStatuses) > 0 })
+}
+
+// configsForAnime e a config de um anime quando as contas que o querem tem perfil: o perfil
+// mais folgado vence, porque o torrent e um so para a casa toda. Sem teto ganha de qualquer
+// teto, o maior teto e o maior numero de assistidos guardados ganham, e assistido so e
+// apagado quando todo perfil que quer o anime apaga. Sem perfil envolvido devolve configs —
+// o mesmo ponteiro, que e como handleSavedEpisodes reconhece o caminho de antes dos perfis.
+func configsForAnime(configs *files.Config, accounts []string) *files.Config {
+	if !slices.ContainsFunc(accounts, configs.HasProfile) {
+		return configs
+	}
+	c := *configs
+	c.MaxEpisodesPerAnime = -1
+	c.DeleteWatchedEpisodes = true
+	c.WatchedEpisodesToKeep = 0
+	for _, acc := range accounts {
+		p := configs.ProfileFor(acc)
+		switch {
+		case p.MaxEpisodesPerAnime <= 0 || c.MaxEpisodesPerAnime == 0:
+			c.MaxEpisodesPerAnime = 0
+		default:
+			c.MaxEpisodesPerAnime = max(c.MaxEpisodesPerAnime, p.MaxEpisodesPerAnime)
+		}
+		c.DeleteWatchedEpisodes = c.DeleteWatchedEpisodes && p.DeleteWatchedEpisodes
+		c.WatchedEpisodesToKeep = max(c.WatchedEpisodesToKeep, p.WatchedEpisodesToKeep)
+	}
+	return &c
+}
+
+// splitKeysToDelete separa as delecoes do passe: as do anime sem perfil seguem o caminho
+// global (DeleteWatchedEpisodes), as aprovadas pela config do anime vao direto. Com alguma
+// busca falhada (!complete) as de perfil ficam para o proximo passe: a config do anime saiu so
+// das contas que responderam, e a que falhou pode ter o perfil mais folgado, que guardaria o
+// episodio.
+func splitKeysToDelete(configs *files.Config, animeConfigs map[int]*files.Config, complete bool, keys []files.EpisodeKey) (global, profile []files.EpisodeKey) {
+	for _, k := range keys {
+		switch cfg := animeConfigs[k.AnimeID]; {
+		case cfg == nil || cfg == configs:
+			global = append(global, k)
+		case complete && cfg.DeleteWatchedEpisodes:
+			profile = append(profile, k)
+		}
+	}
+	return global, profile
+}
+
+// librariesFor sao as bibliotecas de um anime: a de cada conta que o quer. nil quando e so a
+// raiz — o Libraries de um registro de antes dos perfis, que assim nunca precisa mudar.
+func librariesFor(configs *files.Config, accounts []string) []string {
+	var out []string
+	for _, acc := range accounts {
+		if sub := configs.ProfileFor(acc).LibrarySubpath; !slices.Contains(out, sub) {
+			out = append(out, sub)
+		}
+	}
+	return normalizeLibraries(out)
+}
+
+func normalizeLibraries(libs []string) []string {
+	if len(libs) == 0 || (len(libs) == 1 && libs[0] == "") {
+		return nil
+	}
+	libs = slices.Clone(libs)
+	slices.Sort(libs)
+	return slices.Compact(libs)
+}
+
+// syncProfileLibraries acompanha a mudanca de quem quer cada anime: a conta que passou a
+// querer ganha os links na sua biblioteca, a que deixou de querer os perde. So o Libraries dos
+// registros muda aqui; o organize (com Repair, sem webhook) e quem linka e deslinka. O torrent
+// fica enquanto alguma conta quiser o anime — quem apaga e handleSavedEpisodes.
+//
+// Le os episodios do disco de novo: roda depois do save dos novos, que ja nascem com o
+// Libraries certo.
+func syncProfileLibraries(fm FileManagerInterface, jobQueue *JobQueue, configs *files.Config, animes []anilist.MediaList, wants listWants) {
+	inPass := make(map[int]bool, len(animes))
+	for _, a := range animes {
+		inPass[a.Media.Id] = true
+	}
+
+	saved, err := fm.LoadSavedEpisodes()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Profile libraries: failed to load saved episodes")
+		return
+	}
+
+	var changed []files.EpisodeStruct
+	var hashes []string
+	for _, ep := range saved {
+		if !inPass[ep.AnimeID] || ep.ManuallyManaged {
+			continue
+		}
+		want := librariesFor(configs, wants.accounts[ep.AnimeID])
+		if !wants.complete {
+			want = normalizeLibraries(slices.Concat(ep.LibrarySubpaths(), want))
+		}
+		if slices.Equal(normalizeLibraries(ep.Libraries), want) {
+			continue
+		}
+		ep.Libraries = want
+		changed = append(changed, ep)
+		if len(ep.LibraryPaths) > 0 && ep.EpisodeHash != "" && !slices.Contains(hashes, ep.EpisodeHash) {
+			hashes = append(hashes, ep.EpisodeHash)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	if err := fm.UpsertEpisodes(changed); err != nil {
+		logger.Logger.Warn().Err(err).Msg("Profile libraries: failed to persist the libraries of the episodes")
+		return
+	}
+	logger.Logger.Info().Int("episodes", len(changed)).Msg("Profile libraries: episodes changed library")
+
+	if jobQueue == nil {
+		return
+	}
+	for _, hash := range hashes {
+		jobQueue.EnqueueReorganize(hash)
+	}
+}
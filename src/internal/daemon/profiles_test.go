@@ -0,0 +1,189 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+)
+
+func profileTestConfig(completed string) *files.Config {
+	return &files.Config{
+		CompletedAnimePath:    completed,
+		AnilistUsernames:      []string{"alice", "bob", "carol"},
+		MaxEpisodesPerAnime:   3,
+		DeleteWatchedEpisodes: true,
+		AccountProfiles: []files.AccountProfile{
+			{Account: "alice", LibrarySubpath: "alice", MaxEpisodesPerAnime: 5, DeleteWatchedEpisodes: true, WatchedEpisodesToKeep: 2},
+			{Account: "bob", LibrarySubpath: "bob", MaxEpisodesPerAnime: 0, DeleteWatchedEpisodes: false},
+		},
+	}
+}
+
+func TestConfigsForAnime(t *testing.T) {
+	configs := profileTestConfig("lib")
+
+	if got := configsForAnime(configs, []string{"carol"}); got != configs {
+		t.Error("anime sem perfil envolvido devia usar a config global, o mesmo ponteiro")
+	}
+	if got := configsForAnime(configs, nil); got != configs {
+		t.Error("avulso devia usar a config global")
+	}
+
+	got := configsForAnime(configs, []string{"alice", "carol"})
+	if got.MaxEpisodesPerAnime != 5 || !got.DeleteWatchedEpisodes || got.WatchedEpisodesToKeep != 2 {
+		t.Errorf("alice + carol: quero teto 5, apagando, guardando 2; veio %d, %v, %d", got.MaxEpisodesPerAnime, got.DeleteWatchedEpisodes, got.WatchedEpisodesToKeep)
+	}
+
+	// bob nao tem teto e nao apaga: o mais folgado vence.
+	got = configsForAnime(configs, []string{"alice", "bob"})
+	if got.MaxEpisodesPerAnime != 0 || got.DeleteWatchedEpisodes {
+		t.Errorf("alice + bob: quero sem teto e sem apagar; veio %d, %v", got.MaxEpisodesPerAnime, got.DeleteWatchedEpisodes)
+	}
+	if configs.MaxEpisodesPerAnime != 3 {
+		t.Error("configsForAnime nao pode mexer na config global")
+	}
+}
+
+func TestSplitKeysToDelete(t *testing.T) {
+	configs := profileTestConfig("lib")
+	animeConfigs := map[int]*files.Config{
+		1: configs,
+		2: configsForAnime(configs, []string{"alice"}),
+		3: configsForAnime(configs, []string{"alice", "bob"}),
+	}
+	keys := []files.EpisodeKey{{AnimeID: 1, Episode: 1}, {AnimeID: 2, Episode: 1}, {AnimeID: 3, Episode: 1}}
+
+	global, profile := splitKeysToDelete(configs, animeConfigs, true, keys)
+	if !slices.Equal(global, keys[:1]) || !slices.Equal(profile, keys[1:2]) {
+		t.Errorf("global = %v, perfil = %v; quero o anime 1 no global e o 2 no perfil (o 3 bob guarda)", global, profile)
+	}
+
+	// A busca de bob falhou: o anime 2 so tem alice na config, mas bob pode querer o episodio.
+	global, profile = splitKeysToDelete(configs, animeConfigs, false, keys)
+	if !slices.Equal(global, keys[:1]) || len(profile) != 0 {
+		t.Errorf("busca falhada: global = %v, perfil = %v; a delecao de perfil devia esperar", global, profile)
+	}
+}
+
+func TestLibrariesFor(t *testing.T) {
+	configs := profileTestConfig("lib")
+	if got := librariesFor(configs, []string{"carol"}); got != nil {
+		t.Errorf("so a raiz devia ser nil, veio %q", got)
+	}
+	if got := librariesFor(configs, []string{"bob", "carol", "alice"}); !slices.Equal(got, []string{"", "alice", "bob"}) {
+		t.Errorf("librariesFor = %q", got)
+	}
+}
+
+// Um torrent, uma copia: o organize linka em cada biblioteca que quer o anime, e quando um perfil
+// deixa de querer so a biblioteca dele perde o link.
+func TestOrganizeTorrent_ProfileLibraries(t *testing.T) {
+	dataDir := makeTorrentDataDir(t)
+	completed := t.TempDir()
+	const hash = "0123456789abcdef0123456789abcdef01234567"
+
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted(hash, dataDir)
+
+	fm := &orchestrationFM{
+		saved: []files.EpisodeStruct{
+			{EpisodeHash: hash, AnimeName: "My Anime", EpisodeNumber: 5, Libraries: []string{"", "alice"}},
+		},
+		configs: profileTestConfig(completed),
+	}
+	lib := files.NewLibrarian(files.NewOSFileSystem())
+
+	if ok := organizeTorrent(hash, backend, lib, fm, fm.configs); !ok {
+		t.Fatal("organizeTorrent should succeed")
+	}
+	rootLink := filepath.Join(completed, "My Anime", "episode.mkv")
+	aliceLink := filepath.Join(completed, "alice", "My Anime", "episode.mkv")
+	for _, p := range []string{rootLink, aliceLink} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("quero o link %s: %v", p, err)
+		}
+	}
+	if len(fm.saved[0].LibraryPaths) != 2 {
+		t.Fatalf("LibraryPaths = %v, quero os dois links", fm.saved[0].LibraryPaths)
+	}
+
+	// A raiz deixou de querer o anime: o link dela sai, o de alice e o torrent ficam.
+	fm.saved[0].Libraries = []string{"alice"}
+	if done, _ := organizePayload(OrganizePayload{Hash: hash, Repair: true}, backend, lib, fm, fm.configs); !done {
+		t.Fatal("reorganize should succeed")
+	}
+	if _, err := os.Stat(rootLink); !os.IsNotExist(err) {
+		t.Errorf("o link da raiz devia sair, stat = %v", err)
+	}
+	if _, err := os.Stat(aliceLink); err != nil {
+		t.Errorf("o link de alice devia ficar: %v", err)
+	}
+	if !slices.Equal(fm.saved[0].LibraryPaths, []string{aliceLink}) {
+		t.Errorf("LibraryPaths = %v, quero so o de alice", fm.saved[0].LibraryPaths)
+	}
+	if _, ok := backend.Get(hash); !ok {
+		t.Error("o torrent nao pode sair so porque uma biblioteca perdeu o anime")
+	}
+}
+
+// A delecao que a config do anime aprovou vale mesmo com o DeleteWatchedEpisodes global
+// desligado; a do caminho global continua esperando o flag.
+func TestHandleSavedEpisodes_ProfileKeysIgnoreGlobalFlag(t *testing.T) {
+	saved := []files.EpisodeStruct{
+		{AnimeID: 1, EpisodeNumber: 1, EpisodeHash: "h1"},
+		{AnimeID: 2, EpisodeNumber: 1, EpisodeHash: "h2"},
+	}
+	fm := &mockFileManagerForEpisodes{}
+	backend := fakeWithTorrents("h1", "h2")
+
+	handleSavedEpisodes(fm, &files.Config{DeleteWatchedEpisodes: false}, backend, testLibrarian(), handleEpisodesData{
+		savedEpisodes:       saved,
+		keysToDelete:        []files.EpisodeKey{{AnimeID: 1, Episode: 1}},
+		profileKeysToDelete: []files.EpisodeKey{{AnimeID: 2, Episode: 1}},
+		checkedEpisodes:     []files.EpisodeKey{{AnimeID: 1, Episode: 1}, {AnimeID: 2, Episode: 1}},
+	})
+
+	if !containsID(fm.deletedEpisodeKeys, files.EpisodeKey{AnimeID: 2, Episode: 1}) {
+		t.Errorf("a delecao aprovada pelo perfil devia rodar, apagados = %v", fm.deletedEpisodeKeys)
+	}
+	if containsID(fm.deletedEpisodeKeys, files.EpisodeKey{AnimeID: 1, Episode: 1}) {
+		t.Error("a delecao do caminho global devia esperar DeleteWatchedEpisodes")
+	}
+}
+
+func TestSyncProfileLibraries(t *testing.T) {
+	configs := profileTestConfig("lib")
+	animes := []anilist.MediaList{{Media: anilist.Media{Id: 1}}}
+
+	t.Run("conta que deixou de querer sai", func(t *testing.T) {
+		fm := &orchestrationFM{saved: []files.EpisodeStruct{
+			{AnimeID: 1, EpisodeNumber: 1, EpisodeHash: "h1", Libraries: []string{"alice", "bob"}, LibraryPaths: []string{"lib/alice/A/a.mkv", "lib/bob/A/a.mkv"}},
+		}}
+		wants := newListWants()
+		wants.add(1, "alice")
+
+		syncProfileLibraries(fm, nil, configs, animes, wants)
+		if got := fm.saved[0].Libraries; !slices.Equal(got, []string{"alice"}) {
+			t.Errorf("Libraries = %q, quero so alice", got)
+		}
+	})
+
+	t.Run("busca falhada nao tira biblioteca", func(t *testing.T) {
+		fm := &orchestrationFM{saved: []files.EpisodeStruct{
+			{AnimeID: 1, EpisodeNumber: 1, EpisodeHash: "h1", Libraries: []string{"alice", "bob"}},
+		}}
+		wants := newListWants()
+		wants.add(1, "alice")
+		wants.complete = false
+
+		syncProfileLibraries(fm, nil, configs, animes, wants)
+		if len(fm.upserted) != 0 {
+			t.Errorf("com uma conta sem resposta nada devia mudar, gravou %v", fm.upserted)
+		}
+	})
+}
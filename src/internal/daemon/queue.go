@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+	"time"
+)
+
+// airingWindow e ha quanto tempo um episodio pode ter ido ao ar e ainda contar como "da
+// semana" na politica airing_first. Uma semana cobre o ciclo de um anime semanal: o episodio
+// sai da frente da fila quando o seguinte vai ao ar.
+const airingWindow = 7 * 24 * time.Hour
+
+// ApplyQueuePolicy empurra a politica de fila para o backend. Mesmos tres lugares de
+// ApplyExtraTrackers — boot, PUT /config e o topo de todo passe. Politica desconhecida (ou "")
+// vale fifo no proprio backend, entao nao ha o que validar aqui.
+func ApplyQueuePolicy(backend torrents.TorrentBackend, configs *files.Config) {
+	backend.SetQueuePolicy(torrents.QueuePolicy(configs.QueuePolicy))
+}
+
+// queueHints monta o que a fila precisa saber de cada torrent para airing_first e fair_share:
+// o anime dono (pelo EpisodeHash dos episodios salvos e dos adicionados neste passe), o peso
+// do anime (AnimeSettings.QueueWeight) e se algum dos episodios do torrent foi ao ar dentro de
+// airingWindow num anime RELEASING. Torrent adicionado a mao fica sem hint.
+func queueHints(animes []anilist.MediaList, settings map[int]files.AnimeSettings, episodes []files.EpisodeStruct, now time.Time) map[string]torrents.QueueHint {
+	media := make(map[int]anilist.Media, len(animes))
+	for _, a := range animes {
+		media[a.Media.Id] = a.Media
+	}
+
+	hints := make(map[string]torrents.QueueHint)
+	for _, ep := range episodes {
+		if ep.EpisodeHash == "" {
+			continue
+		}
+		hint, ok := hints[ep.EpisodeHash]
+		if !ok {
+			hint = torrents.QueueHint{AnimeID: ep.AnimeID, Weight: settings[ep.AnimeID].QueueWeight}
+		}
+		if m, ok := media[ep.AnimeID]; ok && recentlyAired(m, ep.EpisodeNumber, now) {
+			hint.Airing = true
+		}
+		hints[ep.EpisodeHash] = hint
+	}
+	return hints
+}
+
+// recentlyAired reporta se o episodio de um anime em exibicao foi ao ar dentro de airingWindow.
+// A hora vem do airingSchedule; quando a AniList ja clipou o episodio de la (ver decisions.md
+// #52), o ultimo episodio antes de NextAiringEpisode conta como recente — e o da semana.
+func recentlyAired(m anilist.Media, episode int, now time.Time) bool {
+	if m.Status != anilist.MediaStatusReleasing {
+		return false
+	}
+	for _, n := range m.AiringSchedule.Nodes {
+		if n.Episode == episode && n.AiringAt > 0 {
+			aired := time.Unix(n.AiringAt, 0)
+			return !aired.After(now) && now.Sub(aired) <= airingWindow
+		}
+	}
+	return m.NextAiringEpisode != nil && episode == m.NextAiringEpisode.Episode-1
+}
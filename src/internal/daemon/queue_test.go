@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Hints join each torrent to its anime and weight; only an episode of a RELEASING anime that
+// aired inside the window is marked airing, and a batch hash shared by several episodes gets
+// one hint.
+func TestQueueHints(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	animes := []anilist.MediaList{
+		{Media: anilist.Media{Id: 1, Status: anilist.MediaStatusReleasing, AiringSchedule: anilist.AiringSchedule{Nodes: []anilist.AiringNode{
+			{Episode: 5, AiringAt: now.Add(-30 * 24 * time.Hour).Unix()},
+			{Episode: 8, AiringAt: now.Add(-2 * 24 * time.Hour).Unix()},
+		}}}},
+		// Schedule clipped by AniList: the episode before the next one is this week's.
+		{Media: anilist.Media{Id: 2, Status: anilist.MediaStatusReleasing, NextAiringEpisode: &anilist.AiringNode{Episode: 13}}},
+		{Media: anilist.Media{Id: 3, Status: anilist.MediaStatusFinished, NextAiringEpisode: &anilist.AiringNode{Episode: 13}}},
+	}
+	settings := map[int]files.AnimeSettings{1: {QueueWeight: 3}}
+	episodes := []files.EpisodeStruct{
+		{AnimeID: 1, EpisodeNumber: 5, EpisodeHash: "old"},
+		{AnimeID: 1, EpisodeNumber: 8, EpisodeHash: "new"},
+		{AnimeID: 2, EpisodeNumber: 12, EpisodeHash: "clipped"},
+		{AnimeID: 3, EpisodeNumber: 1, EpisodeHash: "batch", IsBatch: true},
+		{AnimeID: 3, EpisodeNumber: 12, EpisodeHash: "batch", IsBatch: true},
+		{AnimeID: 4, EpisodeNumber: 1, EpisodeHash: ""},
+	}
+
+	got := queueHints(animes, settings, episodes, now)
+
+	want := map[string]torrents.QueueHint{
+		"old":     {AnimeID: 1, Weight: 3},
+		"new":     {AnimeID: 1, Weight: 3, Airing: true},
+		"clipped": {AnimeID: 2, Airing: true},
+		"batch":   {AnimeID: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("queueHints() = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyQueuePolicy(t *testing.T) {
+	backend := torrents.NewFakeBackend()
+	ApplyQueuePolicy(backend, &files.Config{QueuePolicy: "fair_share"})
+	if backend.QueuePolicy != torrents.QueueFairShare {
+		t.Errorf("QueuePolicy = %q, want fair_share", backend.QueuePolicy)
+	}
+}
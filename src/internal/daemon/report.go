@@ -14,6 +14,12 @@ const (
 	IssueNoTorrentFound    = "no_torrent_found"
 	IssueDiskFull          = "disk_full"
 	IssueTorrentRejected   = "torrent_rejected"
+	// IssueDataCapReached e o teto mensal de dados (Config.DataCapGB): como disk_full, o
+	// episodio foi achado e nem chegou a ser adicionado.
+	IssueDataCapReached = "data_cap_reached"
+	// IssueDataCorrupted vem da verificacao de integridade (integritySweep), nao da busca: o
+	// episodio baixou, mas pecas dele no disco nao batem mais com o hash.
+	IssueDataCorrupted = "data_corrupted"
 )
 
 // Codigo de LIMITE: a config funcionando como configurada. Peso visual diferente na UI porque o
@@ -55,6 +61,10 @@ type Issue struct {
 	Downloaded   int    `json:"downloaded,omitempty" example:"12"`
 	Pending      int    `json:"pending,omitempty" example:"35"`
 	BatchSkipped string `json:"batch_skipped,omitempty" example:"no_result"`
+	// BadPieces e quantas pecas falharam na verificacao de integridade (data_corrupted). Ao
+	// contrario dos outros detalhes, e SOMADO na agregacao: dois torrents do mesmo anime com 2 e
+	// 3 pecas ruins sao 5 pecas ruins, um numero que existiu de fato.
+	BadPieces int `json:"bad_pieces,omitempty" example:"2"`
 }
 
 // CheckReport e o relatorio do ULTIMO passe, e so dele. Nao e historico.
@@ -63,6 +73,10 @@ type CheckReport struct {
 	PassError  string    `json:"pass_error" example:""`
 	Problems   []Issue   `json:"problems"`
 	Limits     []Issue   `json:"limits"`
+	// AniListStale marca o passe que rodou, no todo ou em parte, com respostas salvas porque a
+	// AniList nao respondeu; AniListStaleSince e de quando e a mais velha delas.
+	AniListStale      bool       `json:"anilist_stale" example:"false"`
+	AniListStaleSince *time.Time `json:"anilist_stale_since,omitempty" example:"2026-08-19T11:00:00Z"`
 }
 
 // isLimitCode separa as duas categorias. Hoje ha um unico codigo de limite; a funcao existe para
@@ -80,15 +94,21 @@ func isLimitCode(code string) bool {
 // exigiria um detalhe por episodio, que e o relatorio-por-episodio que a spec descartou. Se um
 // dia isso incomodar, o caminho e Detail []struct{Episode int; ...} dentro do Issue.
 func aggregateIssues(raw []Issue) (problems, limits []Issue) {
+	// O nome so entra na chave do anime 0: e o torrent sem episodio salvo (ver integritySweep),
+	// e dois torrents assim nao sao o mesmo anime.
 	type key struct {
 		animeID int
+		orphan  string
 		code    string
 	}
 	order := make([]key, 0, len(raw))
 	merged := make(map[key]*Issue, len(raw))
 
 	for _, in := range raw {
-		k := key{in.AnimeID, in.Code}
+		k := key{animeID: in.AnimeID, code: in.Code}
+		if in.AnimeID == 0 {
+			k.orphan = in.AnimeName
+		}
 		existing, ok := merged[k]
 		if !ok {
 			cp := in
@@ -98,6 +118,7 @@ func aggregateIssues(raw []Issue) (problems, limits []Issue) {
 			continue
 		}
 		existing.Episodes = append(existing.Episodes, in.Episodes...)
+		existing.BadPieces += in.BadPieces
 	}
 
 	for _, k := range order {
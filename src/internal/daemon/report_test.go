@@ -160,3 +160,25 @@ func TestSearchIssue_PrecedenciaEmCascata(t *testing.T) {
 		}
 	})
 }
+
+// TestAggregateIssuesDataCorrupted: BadPieces soma (e um contador, nao um detalhe de busca), e
+// torrents sem episodio salvo (anime 0) so se juntam quando sao o mesmo nome.
+func TestAggregateIssuesDataCorrupted(t *testing.T) {
+	raw := []Issue{
+		{AnimeID: 7, AnimeName: "Frieren", Episodes: []int{1}, Code: IssueDataCorrupted, BadPieces: 2},
+		{AnimeID: 7, AnimeName: "Frieren", Episodes: []int{4}, Code: IssueDataCorrupted, BadPieces: 3},
+		{AnimeName: "[Grupo] Pack A", Code: IssueDataCorrupted, BadPieces: 1},
+		{AnimeName: "[Grupo] Pack B", Code: IssueDataCorrupted, BadPieces: 1},
+	}
+
+	problems, _ := aggregateIssues(raw)
+
+	if len(problems) != 3 {
+		t.Fatalf("esperava 3 problemas, obteve %d (%+v)", len(problems), problems)
+	}
+	for _, p := range problems {
+		if p.AnimeID == 7 && (p.BadPieces != 5 || !reflect.DeepEqual(p.Episodes, []int{1, 4})) {
+			t.Errorf("Frieren agregado errado: %+v", p)
+		}
+	}
+}
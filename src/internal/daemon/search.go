@@ -2,8 +2,11 @@ package daemon
 
 import (
 	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/idmap"
 	"AutoAnimeDownloader/src/internal/logger"
 	"AutoAnimeDownloader/src/internal/nyaa"
+	"AutoAnimeDownloader/src/internal/stringutil"
+	"unicode"
 )
 
 // dropStats conta o que os filtros de busca descartaram numa chamada.
@@ -21,9 +24,9 @@ type dropStats struct {
 type nyaaSearchFunc func(title string) ([]nyaa.TorrentResult, error)
 
 type nyaaSearcher struct {
-	searchAnime         func(titles anilist.Title, synonyms []string, episodes []int, customQuery string) []nyaa.TorrentResult
-	searchSingleEpisode func(ep anilist.AiringNode, titles anilist.Title, synonyms []string, relations anilist.MediaRelations, customQuery string, totalEpisodes int) []nyaa.TorrentResult
-	searchMovie         func(titles anilist.Title, isFormatMovie bool, customQuery string) []nyaa.TorrentResult
+	searchAnime         func(mediaID int, titles anilist.Title, synonyms []string, episodes []int, customQuery string) []nyaa.TorrentResult
+	searchSingleEpisode func(mediaID int, ep anilist.AiringNode, titles anilist.Title, synonyms []string, relations anilist.MediaRelations, customQuery string, totalEpisodes int) []nyaa.TorrentResult
+	searchMovie         func(mediaID int, titles anilist.Title, isFormatMovie bool, customQuery string) []nyaa.TorrentResult
 }
 
 func defaultNyaaSearcher() nyaaSearcher {
@@ -99,7 +102,16 @@ func filterSearchResults(results []nyaa.TorrentResult, maxGB float64, minSeeders
 	return final, dropStats{Input: len(results), BySize: sizeDropped, BySeeders: seedersDropped}
 }
 
-func buildTitleVariants(titles anilist.Title, customQuery string) []string {
+// maxMappingVariants limita os titulos do mapeamento offline tentados depois dos da AniList.
+// Cada variante e uma busca a mais no Nyaa quando as anteriores voltam vazias, e o dataset
+// chega a ter dezenas de sinonimos por anime.
+const maxMappingVariants = 3
+
+// minMappingVariantLetters descarta siglas ("SnF", "FMA:B"): como busca no Nyaa elas casam
+// com qualquer coisa.
+const minMappingVariantLetters = 5
+
+func buildTitleVariants(mediaID int, titles anilist.Title, customQuery string) []string {
 	if customQuery != "" {
 		return []string{customQuery}
 	}
@@ -110,11 +122,50 @@ func buildTitleVariants(titles anilist.Title, customQuery string) []string {
 	if titles.English != nil {
 		english = *titles.English
 	}
-	return nyaa.GenerateSearchTitleVariants(romaji, english)
+	return appendMappingVariants(nyaa.GenerateSearchTitleVariants(romaji, english), mediaID)
+}
+
+// appendMappingVariants acrescenta, DEPOIS dos titulos da AniList, o titulo e os sinonimos do
+// mapeamento offline (idmap): o nome com que o fansub publicou nem sempre e o romaji nem o
+// ingles da AniList. Vao ja limpos, a forma que o Nyaa casa melhor (ver
+// GenerateSearchTitleVariants).
+func appendMappingVariants(variants []string, mediaID int) []string {
+	entry, ok := idmap.ByAniList(mediaID)
+	if !ok {
+		return variants
+	}
+	seen := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		seen[stringutil.RemoveSpecialCharacters(v)] = true
+	}
+	added := 0
+	for _, title := range append([]string{entry.Title}, entry.Synonyms...) {
+		if added == maxMappingVariants {
+			break
+		}
+		clean := stringutil.RemoveSpecialCharacters(title)
+		if seen[clean] || countLetters(clean) < minMappingVariantLetters {
+			continue
+		}
+		seen[clean] = true
+		variants = append(variants, clean)
+		added++
+	}
+	return variants
+}
+
+func countLetters(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			n++
+		}
+	}
+	return n
 }
 
-func searchNyaaWithVariants(titles anilist.Title, customQuery string, searchFn nyaaSearchFunc, logLabel string) []nyaa.TorrentResult {
-	variants := buildTitleVariants(titles, customQuery)
+func searchNyaaWithVariants(mediaID int, titles anilist.Title, customQuery string, searchFn nyaaSearchFunc, logLabel string) []nyaa.TorrentResult {
+	variants := buildTitleVariants(mediaID, titles, customQuery)
 
 	for i, variant := range variants {
 		logger.Logger.Debug().
@@ -146,10 +197,10 @@ func searchNyaaWithVariants(titles anilist.Title, customQuery string, searchFn n
 }
 
 // totalEpisodes vai para o nyaa apenas para decidir o zero-padding da query (0 = desconhecido).
-func searchNyaaForSingleEpisode(ep anilist.AiringNode, titles anilist.Title, synonyms []string, relations anilist.MediaRelations, customQuery string, totalEpisodes int) []nyaa.TorrentResult {
+func searchNyaaForSingleEpisode(mediaID int, ep anilist.AiringNode, titles anilist.Title, synonyms []string, relations anilist.MediaRelations, customQuery string, totalEpisodes int) []nyaa.TorrentResult {
 	season, part := ExtractAnimeSeasonPart(titles, synonyms)
 
-	results := searchNyaaWithVariants(titles, customQuery, func(title string) ([]nyaa.TorrentResult, error) {
+	results := searchNyaaWithVariants(mediaID, titles, customQuery, func(title string) ([]nyaa.TorrentResult, error) {
 		return nyaa.ScrapNyaa(title, ep.Episode, season, part, totalEpisodes)
 	}, "single episode")
 
@@ -160,7 +211,7 @@ func searchNyaaForSingleEpisode(ep anilist.AiringNode, titles anilist.Title, syn
 	// Fallback com offset: converte progresso relativo em número absoluto para fansubs
 	// com numeração contínua. Só aplica quando part >= 2 (gate obrigatório).
 	if offset := ComputeEpisodeOffset(relations, part); offset > 0 {
-		results = searchNyaaWithVariants(titles, customQuery, func(title string) ([]nyaa.TorrentResult, error) {
+		results = searchNyaaWithVariants(mediaID, titles, customQuery, func(title string) ([]nyaa.TorrentResult, error) {
 			return nyaa.ScrapNyaa(title, ep.Episode+offset, season, nil, totalEpisodes)
 		}, "single episode (offset fallback)")
 	}
@@ -168,17 +219,17 @@ func searchNyaaForSingleEpisode(ep anilist.AiringNode, titles anilist.Title, syn
 	return results
 }
 
-func searchNyaaForMovie(titles anilist.Title, isFormatMovie bool, customQuery string) []nyaa.TorrentResult {
-	return searchNyaaWithVariants(titles, customQuery, func(title string) ([]nyaa.TorrentResult, error) {
+func searchNyaaForMovie(mediaID int, titles anilist.Title, isFormatMovie bool, customQuery string) []nyaa.TorrentResult {
+	return searchNyaaWithVariants(mediaID, titles, customQuery, func(title string) ([]nyaa.TorrentResult, error) {
 		return nyaa.ScrapNyaaForMovie(title, isFormatMovie)
 	}, "movie")
 }
 
 // searchNyaaForAnime e a busca unica por anime: devolve packs e episodios na mesma lista (ver
 // nyaa.ScrapNyaaForAnime).
-func searchNyaaForAnime(titles anilist.Title, synonyms []string, episodes []int, customQuery string) []nyaa.TorrentResult {
+func searchNyaaForAnime(mediaID int, titles anilist.Title, synonyms []string, episodes []int, customQuery string) []nyaa.TorrentResult {
 	season, part := ExtractAnimeSeasonPart(titles, synonyms)
-	return searchNyaaWithVariants(titles, customQuery, func(title string) ([]nyaa.TorrentResult, error) {
+	return searchNyaaWithVariants(mediaID, titles, customQuery, func(title string) ([]nyaa.TorrentResult, error) {
 		return nyaa.ScrapNyaaForAnime(title, episodes, season, part)
 	}, "anime")
 }
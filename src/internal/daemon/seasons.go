@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+)
+
+// showFromChain numera a ultima entrada da cadeia dentro da serie (LibrarySeasonFolders). A
+// primeira temporada da nome a serie e e a Season 1; cada entrada seguinte:
+//   - "Part 2" (ou mais) com o total da anterior conhecido: mesma Season, numeracao continua;
+//   - marcador de season maior que o atual ("Season 3" depois da 1, sem a 2 na cadeia): ele;
+//   - o resto: a proxima Season.
+//
+// Uma Part cujo anterior nao tem total de episodios vira Season nova: na mesma Season ela
+// colidiria com os episodios da parte anterior (S01E01 duas vezes).
+func showFromChain(chain []anilist.SeasonEntry) *files.ShowMeta {
+	if len(chain) == 0 || !anilist.IsSeasonFormat(chain[len(chain)-1].Format) {
+		return nil
+	}
+	root := chain[0]
+	show := &files.ShowMeta{
+		ID:    root.Id,
+		Title: getAnimeTitleSafe(anilist.MediaList{Media: anilist.Media{Title: root.Title}}),
+	}
+	if root.Title.Romaji != nil {
+		show.TitleRomaji = *root.Title.Romaji
+	}
+	if root.Title.English != nil {
+		show.TitleEnglish = *root.Title.English
+	}
+	if root.SeasonYear != nil {
+		show.Year = *root.SeasonYear
+	}
+
+	season, offset := 1, 0
+	for i := 1; i < len(chain); i++ {
+		prev, cur := chain[i-1], chain[i]
+		s, part := ExtractAnimeSeasonPart(cur.Title, cur.Synonyms)
+		switch {
+		case part != nil && *part >= 2 && prev.Episodes != nil:
+			offset += *prev.Episodes
+		case s != nil && *s > season:
+			season, offset = *s, 0
+		default:
+			season, offset = season+1, 0
+		}
+	}
+	show.Season = season
+	show.EpisodeOffset = offset
+	return show
+}
+
+// ensureShowMeta resolve a serie do registro para LibrarySeasonFolders. Registro sem Meta
+// (anterior aos templates, anime fora da lista) ganha o Meta da propria entrada da cadeia.
+// Entrada que nao e temporada (filme, OVA) ou que a AniList nao conhece fica com Show nil, e
+// o layout a deixa numa pasta propria. Retorna true quando mudou o registro.
+func ensureShowMeta(ep *files.EpisodeStruct) (bool, error) {
+	if ep.AnimeID <= 0 || (ep.Meta != nil && ep.Meta.Show != nil) {
+		return false, nil
+	}
+	chain, err := anilist.GetSeasonChain(ep.AnimeID)
+	if err != nil {
+		return false, err
+	}
+	if len(chain) == 0 {
+		return false, nil
+	}
+
+	changed := false
+	if ep.Meta == nil {
+		entry := chain[len(chain)-1]
+		ml := anilist.MediaList{Media: anilist.Media{Title: entry.Title, Synonyms: entry.Synonyms, SeasonYear: entry.SeasonYear}}
+		if len(chain) > 1 {
+			ml.Media.Relations.Edges = []anilist.MediaRelationEdge{
+				{RelationType: "PREQUEL", Node: anilist.MediaRelationNode{Episodes: chain[len(chain)-2].Episodes}},
+			}
+		}
+		ep.Meta = animeMeta(ml)
+		changed = true
+	}
+	if show := showFromChain(chain); show != nil {
+		ep.Meta.Show = show
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,169 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+)
+
+func seasonEntry(id int, format anilist.MediaFormat, title string, episodes int) anilist.SeasonEntry {
+	e := anilist.SeasonEntry{Id: id, Format: format, Title: anilist.Title{Romaji: &title}}
+	if episodes > 0 {
+		e.Episodes = &episodes
+	}
+	return e
+}
+
+func TestShowFromChain(t *testing.T) {
+	aot := []anilist.SeasonEntry{
+		seasonEntry(1, anilist.MediaFormatTV, "Shingeki no Kyojin", 25),
+		seasonEntry(2, anilist.MediaFormatTV, "Shingeki no Kyojin Season 2", 12),
+		seasonEntry(3, anilist.MediaFormatTV, "Shingeki no Kyojin Season 3", 12),
+		seasonEntry(4, anilist.MediaFormatTV, "Shingeki no Kyojin Season 3 Part 2", 10),
+	}
+	cases := []struct {
+		name   string
+		chain  []anilist.SeasonEntry
+		season int
+		offset int
+	}{
+		{"first season", aot[:1], 1, 0},
+		{"numbered sequel", aot[:3], 3, 0},
+		{"split cour continues the season", aot, 3, 12},
+		{"unnumbered sequel is the next season", []anilist.SeasonEntry{
+			seasonEntry(1, anilist.MediaFormatTV, "Mushoku Tensei", 11),
+			seasonEntry(2, anilist.MediaFormatTV, "Mushoku Tensei Ni", 12),
+		}, 2, 0},
+		{"part without the previous total is a new season", []anilist.SeasonEntry{
+			seasonEntry(1, anilist.MediaFormatTV, "Show", 0),
+			seasonEntry(2, anilist.MediaFormatTV, "Show Part 2", 12),
+		}, 2, 0},
+	}
+	for _, c := range cases {
+		show := showFromChain(c.chain)
+		if show == nil {
+			t.Fatalf("%s: showFromChain = nil", c.name)
+		}
+		if show.ID != c.chain[0].Id || show.Title != *c.chain[0].Title.Romaji {
+			t.Errorf("%s: series = %d %q, want the first season", c.name, show.ID, show.Title)
+		}
+		if show.Season != c.season || show.EpisodeOffset != c.offset {
+			t.Errorf("%s: season %d offset %d, want %d and %d", c.name, show.Season, show.EpisodeOffset, c.season, c.offset)
+		}
+	}
+
+	movie := []anilist.SeasonEntry{seasonEntry(9, anilist.MediaFormatMovie, "Show Movie", 1)}
+	if show := showFromChain(movie); show != nil {
+		t.Errorf("a movie is not a season: %+v", show)
+	}
+}
+
+// mockSeasonChain responde GetSeasonEntry com as entradas dadas, cada uma PREQUEL da seguinte.
+func mockSeasonChain(t *testing.T, media map[int]string) func() {
+	t.Helper()
+	return anilist.MockAniListDo(func(req *http.Request) (*http.Response, error) {
+		var payload struct {
+			Variables struct {
+				Id int `json:"id"`
+			} `json:"variables"`
+		}
+		body, _ := io.ReadAll(req.Body)
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("corpo inesperado: %s", body)
+		}
+		resp, ok := media[payload.Variables.Id]
+		if !ok {
+			resp = `{"data":{"Media":null}}`
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(resp))}, nil
+	})
+}
+
+// Ligar as pastas de temporada move a segunda temporada para Season 02 da serie, com o nfo
+// da serie na raiz, e apaga a pasta da entrada.
+func TestRelinkLibraryMigratesToSeasonFolders(t *testing.T) {
+	defer mockSeasonChain(t, map[int]string{
+		1: `{"data":{"Media":{"id":1,"format":"TV","title":{"romaji":"Show"},"episodes":12,"seasonYear":2020,"relations":{"edges":[]}}}}`,
+		2: `{"data":{"Media":{"id":2,"format":"TV","title":{"romaji":"Show Season 2"},"episodes":12,"seasonYear":2022,
+			"relations":{"edges":[{"relationType":"PREQUEL","node":{"id":1,"format":"TV"}}]}}}}`,
+	})()
+
+	dataDir := makeTorrentDataDir(t)
+	completed := t.TempDir()
+	const hash = "0123456789abcdef0123456789abcdef01234567"
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted(hash, dataDir)
+
+	fm := &orchestrationFM{
+		saved:   []files.EpisodeStruct{{EpisodeHash: hash, AnimeID: 2, AnimeName: "Show Season 2", EpisodeNumber: 3}},
+		configs: &files.Config{CompletedAnimePath: completed, RenameFilesForJellyfin: true},
+	}
+	lib := files.NewLibrarian(files.NewOSFileSystem())
+	if ok := organizeTorrent(hash, backend, lib, fm, fm.configs); !ok {
+		t.Fatal("organizeTorrent should succeed")
+	}
+	oldDir := filepath.Join(completed, "Show Season 2")
+
+	fm.configs.LibrarySeasonFolders = true
+	fm.configs.LibraryFileTemplate = "{title} - S{season:02}E{episode:02}"
+	if ok := relinkLibrary(lib, fm, fm.configs); !ok {
+		t.Fatal("relinkLibrary should succeed")
+	}
+
+	newPath := filepath.Join(completed, "Show", "Season 02", "Show - S02E03.mkv")
+	if got := fm.saved[0].LibraryPaths; len(got) != 1 || got[0] != newPath {
+		t.Fatalf("LibraryPaths = %v, want [%s]", got, newPath)
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("expected relinked file: %v", err)
+	}
+	if _, err := os.Stat(oldDir); !os.IsNotExist(err) {
+		t.Errorf("entry folder should be gone: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(completed, "Show", "tvshow.nfo"))
+	if err != nil || !strings.Contains(string(data), "<title>Show</title>") {
+		t.Errorf("series tvshow.nfo = %q, %v", data, err)
+	}
+	if show := fm.saved[0].Meta.Show; show == nil || show.ID != 1 || show.Season != 2 {
+		t.Errorf("Meta.Show = %+v, want series 1 season 2", show)
+	}
+}
+
+// Com a opcao ligada, um episodio novo ja pousa na Season da serie.
+func TestOrganizeTorrent_SeasonFolders(t *testing.T) {
+	defer mockSeasonChain(t, map[int]string{
+		1: `{"data":{"Media":{"id":1,"format":"TV","title":{"romaji":"Show"},"episodes":12,"relations":{"edges":[]}}}}`,
+		2: `{"data":{"Media":{"id":2,"format":"TV","title":{"romaji":"Show Part 2"},"episodes":12,
+			"relations":{"edges":[{"relationType":"PREQUEL","node":{"id":1,"format":"TV"}}]}}}}`,
+	})()
+
+	dataDir := makeTorrentDataDir(t)
+	completed := t.TempDir()
+	const hash = "0123456789abcdef0123456789abcdef01234567"
+	backend := torrents.NewFakeBackend()
+	backend.AddCompleted(hash, dataDir)
+
+	fm := &orchestrationFM{
+		saved: []files.EpisodeStruct{{EpisodeHash: hash, AnimeID: 2, AnimeName: "Show Part 2", EpisodeNumber: 1}},
+		configs: &files.Config{
+			CompletedAnimePath: completed, RenameFilesForJellyfin: true, LibrarySeasonFolders: true,
+			LibraryFileTemplate: "{title} - S{season:02}E{episode:02}",
+		},
+	}
+	lib := files.NewLibrarian(files.NewOSFileSystem())
+	if ok := organizeTorrent(hash, backend, lib, fm, fm.configs); !ok {
+		t.Fatal("organizeTorrent should succeed")
+	}
+	want := filepath.Join(completed, "Show", "Season 01", "Show - S01E13.mkv")
+	if got := fm.saved[0].LibraryPaths; len(got) != 1 || got[0] != want {
+		t.Errorf("LibraryPaths = %v, want [%s]", got, want)
+	}
+}
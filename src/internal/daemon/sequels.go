@@ -0,0 +1,196 @@
+package daemon
+
+import (
+	"slices"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/notifications"
+)
+
+// followSequels segue a SEQUEL de cada anime do passe que terminou: a sequencia entra em
+// standalone_animes e volta ja como MediaList, para ser processada neste mesmo passe. Quem
+// decide e AnimeSettings.FollowSequels do anime terminado, e sem ele Config.FollowSequels.
+//
+// So segue sequencia no ar, ou anunciada com estreia dentro de sequel_lead_days. Uma sequencia
+// FINISHED nao entra: ligar o global num anime antigo da lista puxaria o catalogo inteiro da
+// franquia de uma vez, e nao e esse o pedido ("a temporada acabou, a proxima comecou").
+//
+// O guard e o mesmo do POST de avulso, montado com a lista do passe: sequencia que ja esta numa
+// lista, ja e avulsa, ja foi baixada inteira ou esta numa lista excluida nao entra. Cada
+// sequencia seguida fica em FollowedSequels do anterior e nunca e seguida de novo — o usuario
+// que a tira do acompanhamento nao a ve voltar no passe seguinte.
+//
+// O anterior que terminou quase sempre ja saiu dos status de download (o usuario o marca
+// COMPLETED), entao alem do passe entram os animes ja baixados (savedEpisodes) — ver
+// finishedTrackedPrequels.
+func followSequels(fm FileManagerInterface, configs *files.Config, animes []anilist.MediaList, savedEpisodes []files.EpisodeStruct, settings map[int]files.AnimeSettings, now time.Time) []anilist.MediaList {
+	var (
+		guard *StandaloneGuard
+		added []anilist.MediaList
+	)
+	prequels := slices.Concat(animes, finishedTrackedPrequels(configs, animes, savedEpisodes, settings))
+	for _, anime := range prequels {
+		if anime.Media.Status != anilist.MediaStatusFinished || animeIsInExcludedList(anime, configs.ExcludedLists) {
+			continue
+		}
+		own := settings[anime.Media.Id]
+		if !followsSequels(configs, own) {
+			continue
+		}
+
+		for _, edge := range anime.Media.Relations.Edges {
+			sequel := edge.Node
+			if edge.RelationType != "SEQUEL" || !isAnimeFormat(sequel.Format) ||
+				slices.Contains(own.FollowedSequels, sequel.Id) || !sequelDue(sequel, configs.SequelLeadDays, now) {
+				continue
+			}
+
+			// Montado so quando ha o que seguir: le dois arquivos, e a maioria dos passes nao
+			// tem sequencia nenhuma.
+			if guard == nil {
+				g, err := NewStandaloneGuard(fm, configs.ExcludedLists, animes)
+				if err != nil {
+					logger.Logger.Warn().Err(err).Msg("Failed to build the standalone guard; not following sequels this pass")
+					return added
+				}
+				guard = &g
+			}
+			total := 0
+			if sequel.Episodes != nil {
+				total = *sequel.Episodes
+			}
+			if reason := guard.BlockReason(sequel.Id, total); reason != "" {
+				logger.Logger.Debug().Int("anime_id", anime.Media.Id).Int("sequel_id", sequel.Id).
+					Str("reason", reason).Msg("Not following sequel")
+				continue
+			}
+
+			if err := fm.AddStandaloneAnime(sequel.Id); err != nil {
+				logger.Logger.Warn().Err(err).Int("sequel_id", sequel.Id).Msg("Failed to add the sequel as standalone")
+				continue
+			}
+			guard.standalone[sequel.Id] = true
+			own.FollowedSequels = append(own.FollowedSequels, sequel.Id)
+			recordFollowedSequel(fm, anime.Media.Id, sequel.Id, own.FollowSequels)
+
+			prequelTitle := getAnimeTitleSafe(anime)
+			sequelTitle := getAnimeTitleSafe(anilist.MediaList{Media: anilist.Media{Title: sequel.Title}})
+			logger.Logger.Info().Int("anime_id", anime.Media.Id).Int("sequel_id", sequel.Id).
+				Str("sequel", sequelTitle).Msg("Following sequel as standalone anime")
+			notifications.Notify(configs, notifications.SequelFollowed, sequelTitle, 0, prequelTitle)
+
+			// Falha aqui so adia: o registro avulso ja esta salvo e o proximo passe o busca.
+			ml, err := anilist.GetMediaByID(sequel.Id)
+			if err != nil || ml == nil {
+				logger.Logger.Warn().Err(err).Int("sequel_id", sequel.Id).
+					Msg("Failed to fetch the followed sequel; it will be processed next pass")
+				continue
+			}
+			added = append(added, *withStandaloneProgress(fm, ml))
+		}
+	}
+	return added
+}
+
+// finishedTrackedPrequels busca os animes ja baixados que nao vieram no passe e que seguem
+// sequencia, para o anterior marcado COMPLETED (ou em qualquer status fora dos de download)
+// ainda ter a sua sequencia seguida. Uma chamada so (GetMediaByIDs, com cache de resposta), e
+// nenhuma quando nada segue sequencia. Falha so adia para o proximo passe.
+func finishedTrackedPrequels(configs *files.Config, animes []anilist.MediaList, savedEpisodes []files.EpisodeStruct, settings map[int]files.AnimeSettings) []anilist.MediaList {
+	inPass := make(map[int]bool, len(animes))
+	for _, anime := range animes {
+		inPass[anime.Media.Id] = true
+	}
+	var ids []int
+	for _, ep := range savedEpisodes {
+		if ep.AnimeID == 0 || inPass[ep.AnimeID] || !followsSequels(configs, settings[ep.AnimeID]) {
+			continue
+		}
+		inPass[ep.AnimeID] = true
+		ids = append(ids, ep.AnimeID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	media, err := anilist.GetMediaByIDs(ids)
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to fetch downloaded animes for sequels; retrying next pass")
+		return nil
+	}
+	var prequels []anilist.MediaList
+	for _, id := range ids {
+		if ml, ok := media[id]; ok && ml != nil && ml.Media.Status == anilist.MediaStatusFinished {
+			prequels = append(prequels, *ml)
+		}
+	}
+	return prequels
+}
+
+func followsSequels(configs *files.Config, s files.AnimeSettings) bool {
+	switch s.FollowSequels {
+	case files.FollowSequelsAlways:
+		return true
+	case files.FollowSequelsNever:
+		return false
+	}
+	return configs.FollowSequels
+}
+
+// sequelDue diz se a sequencia ja pode entrar: no ar, ou com estreia dentro do lead time.
+// Anuncio sem data completa espera ela ir ao ar.
+func sequelDue(sequel anilist.MediaRelationNode, leadDays int, now time.Time) bool {
+	switch sequel.Status {
+	case anilist.MediaStatusReleasing:
+		return true
+	case anilist.MediaStatusNotYetReleased:
+		start, ok := sequel.StartDate.Time()
+		return leadDays > 0 && ok && !now.AddDate(0, 0, leadDays).Before(start)
+	}
+	return false
+}
+
+// isAnimeFormat separa as SEQUEL que sao anime. A relacao tambem aponta para manga e light
+// novel, e um clipe musical nao tem episodio para baixar. Formato vazio (a AniList nao sabe)
+// passa.
+func isAnimeFormat(format anilist.MediaFormat) bool {
+	switch format {
+	case anilist.MediaFormatManga, anilist.MediaFormatNovel, anilist.MediaFormatOneShot, anilist.MediaFormatMusic:
+		return false
+	}
+	return true
+}
+
+// recordFollowedSequel grava a sequencia em FollowedSequels do anterior. Um anterior com
+// "always" passa o "always" para a sequencia sem override proprio: a franquia que o usuario
+// mandou seguir continua sendo seguida quando a sequencia tambem terminar.
+func recordFollowedSequel(fm FileManagerInterface, prequelID, sequelID int, prequelFollow string) {
+	prequel := files.AnimeSettings{}
+	if s, err := fm.LoadAnimeSettings(prequelID); err == nil && s != nil {
+		prequel = *s
+	}
+	if !slices.Contains(prequel.FollowedSequels, sequelID) {
+		prequel.FollowedSequels = append(prequel.FollowedSequels, sequelID)
+	}
+	if err := fm.SaveAnimeSettings(prequelID, prequel); err != nil {
+		logger.Logger.Warn().Err(err).Int("anime_id", prequelID).Msg("Failed to record the followed sequel")
+	}
+
+	if prequelFollow != files.FollowSequelsAlways {
+		return
+	}
+	sequel := files.AnimeSettings{}
+	if s, err := fm.LoadAnimeSettings(sequelID); err == nil && s != nil {
+		sequel = *s
+	}
+	if sequel.FollowSequels != "" {
+		return
+	}
+	sequel.FollowSequels = files.FollowSequelsAlways
+	if err := fm.SaveAnimeSettings(sequelID, sequel); err != nil {
+		logger.Logger.Warn().Err(err).Int("anime_id", sequelID).Msg("Failed to pass follow_sequels on to the sequel")
+	}
+}
@@ -0,0 +1,196 @@
+package daemon
+
+import (
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+)
+
+// sequelsFM guarda o que o passe salva em AnimeSettings; o mock base descarta.
+type sequelsFM struct {
+	mockFileManagerForEpisodes
+}
+
+func (m *sequelsFM) SaveAnimeSettings(id int, s files.AnimeSettings) error {
+	if m.settings == nil {
+		m.settings = map[int]files.AnimeSettings{}
+	}
+	m.settings[id] = s
+	return nil
+}
+
+const followedSequelMedia = `{"data": {"Media": {
+	"id": 200, "format": "TV", "status": "RELEASING", "episodes": 12,
+	"title": {"english": "Show 2", "romaji": "Show 2"}
+}}}`
+
+func finishedWithSequel(status anilist.MediaStatus, format anilist.MediaFormat, start anilist.FuzzyDate) anilist.MediaList {
+	prequelTitle := "Show"
+	ml := anilist.MediaList{Media: anilist.Media{Id: 100, Status: anilist.MediaStatusFinished, Title: anilist.Title{English: &prequelTitle}}}
+	sequelTitle := "Show 2"
+	ml.Media.Relations.Edges = []anilist.MediaRelationEdge{
+		{RelationType: "PREQUEL", Node: anilist.MediaRelationNode{Id: 50, Format: anilist.MediaFormatTV, Status: anilist.MediaStatusReleasing}},
+		{RelationType: "SEQUEL", Node: anilist.MediaRelationNode{Id: 200, Format: format, Status: status, StartDate: start, Title: anilist.Title{English: &sequelTitle}}},
+	}
+	return ml
+}
+
+// O caso do pedido: a temporada acabou, a proxima esta no ar, e ela entra como avulsa ja neste
+// passe. Seguida uma vez, nao volta depois que o usuario a tira.
+func TestFollowSequels_AddsReleasingSequelOnce(t *testing.T) {
+	defer mockAniListRouter(t, `{"data": {"Page": {"mediaList": []}}}`, followedSequelMedia)()
+	fm := &sequelsFM{}
+	cfg := &files.Config{FollowSequels: true}
+	animes := []anilist.MediaList{finishedWithSequel(anilist.MediaStatusReleasing, anilist.MediaFormatTV, anilist.FuzzyDate{})}
+
+	added := followSequels(fm, cfg, animes, nil, nil, time.Now())
+	if len(added) != 1 || added[0].Media.Id != 200 {
+		t.Fatalf("esperava a sequencia 200 no passe, obteve %+v", added)
+	}
+	if !slices.Equal(fm.standaloneAnimes, []int{200}) {
+		t.Fatalf("esperava a sequencia em standalone_animes, obteve %v", fm.standaloneAnimes)
+	}
+	if got := fm.settings[100].FollowedSequels; !slices.Equal(got, []int{200}) {
+		t.Fatalf("esperava a sequencia registrada no anterior, obteve %v", got)
+	}
+
+	// O usuario tira a sequencia; o passe seguinte le os settings salvos.
+	fm.standaloneAnimes = nil
+	if added := followSequels(fm, cfg, animes, nil, fm.settings, time.Now()); len(added) != 0 || len(fm.standaloneAnimes) != 0 {
+		t.Fatalf("sequencia ja seguida nao pode voltar, obteve %+v / %v", added, fm.standaloneAnimes)
+	}
+}
+
+func TestFollowSequels_Skips(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	year, month, soon, later := 2026, 10, 10, 30
+	cases := []struct {
+		name     string
+		cfg      files.Config
+		settings map[int]files.AnimeSettings
+		anime    anilist.MediaList
+		tracked  bool
+	}{
+		{
+			name:  "global desligado",
+			anime: finishedWithSequel(anilist.MediaStatusReleasing, anilist.MediaFormatTV, anilist.FuzzyDate{}),
+		},
+		{
+			name:     "never no anime vence o global",
+			cfg:      files.Config{FollowSequels: true},
+			settings: map[int]files.AnimeSettings{100: {FollowSequels: files.FollowSequelsNever}},
+			anime:    finishedWithSequel(anilist.MediaStatusReleasing, anilist.MediaFormatTV, anilist.FuzzyDate{}),
+		},
+		{
+			name:  "sequencia em manga",
+			cfg:   files.Config{FollowSequels: true},
+			anime: finishedWithSequel(anilist.MediaStatusReleasing, anilist.MediaFormatManga, anilist.FuzzyDate{}),
+		},
+		{
+			name:  "sequencia ja terminada",
+			cfg:   files.Config{FollowSequels: true},
+			anime: finishedWithSequel(anilist.MediaStatusFinished, anilist.MediaFormatTV, anilist.FuzzyDate{}),
+		},
+		{
+			name:  "anunciada sem lead time",
+			cfg:   files.Config{FollowSequels: true},
+			anime: finishedWithSequel(anilist.MediaStatusNotYetReleased, anilist.MediaFormatTV, anilist.FuzzyDate{Year: &year, Month: &month, Day: &soon}),
+		},
+		{
+			name:  "anunciada fora do lead time",
+			cfg:   files.Config{FollowSequels: true, SequelLeadDays: 14},
+			anime: finishedWithSequel(anilist.MediaStatusNotYetReleased, anilist.MediaFormatTV, anilist.FuzzyDate{Year: &year, Month: &month, Day: &later}),
+		},
+		{
+			name:  "anunciada sem dia",
+			cfg:   files.Config{FollowSequels: true, SequelLeadDays: 60},
+			anime: finishedWithSequel(anilist.MediaStatusNotYetReleased, anilist.MediaFormatTV, anilist.FuzzyDate{Year: &year, Month: &month}),
+		},
+		{
+			name:    "sequencia ja numa lista (guard)",
+			cfg:     files.Config{FollowSequels: true},
+			anime:   finishedWithSequel(anilist.MediaStatusReleasing, anilist.MediaFormatTV, anilist.FuzzyDate{}),
+			tracked: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer mockAniListRouter(t, `{"data": {"Page": {"mediaList": []}}}`, followedSequelMedia)()
+			fm := &sequelsFM{}
+			animes := []anilist.MediaList{tc.anime}
+			if tc.tracked {
+				animes = append(animes, anilist.MediaList{Media: anilist.Media{Id: 200, Status: anilist.MediaStatusReleasing}})
+			}
+			if added := followSequels(fm, &tc.cfg, animes, nil, tc.settings, now); len(added) != 0 || len(fm.standaloneAnimes) != 0 {
+				t.Fatalf("nao deveria seguir, obteve %+v / %v", added, fm.standaloneAnimes)
+			}
+		})
+	}
+}
+
+// Anunciada com data dentro do lead time entra; "always" no anterior passa para a sequencia.
+func TestFollowSequels_LeadTimeAndAlwaysIsInherited(t *testing.T) {
+	defer mockAniListRouter(t, `{"data": {"Page": {"mediaList": []}}}`, followedSequelMedia)()
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	year, month, day := 2026, 10, 10
+	fm := &sequelsFM{}
+	settings := map[int]files.AnimeSettings{100: {FollowSequels: files.FollowSequelsAlways}}
+	fm.settings = map[int]files.AnimeSettings{100: settings[100]}
+	cfg := &files.Config{SequelLeadDays: 14}
+	animes := []anilist.MediaList{finishedWithSequel(anilist.MediaStatusNotYetReleased, anilist.MediaFormatTV, anilist.FuzzyDate{Year: &year, Month: &month, Day: &day})}
+
+	if added := followSequels(fm, cfg, animes, nil, settings, now); len(added) != 1 {
+		t.Fatalf("estreia em 9 dias com lead de 14 deveria entrar, obteve %+v", added)
+	}
+	if got := fm.settings[200].FollowSequels; got != files.FollowSequelsAlways {
+		t.Errorf("a sequencia deveria herdar always, obteve %q", got)
+	}
+}
+
+// O anterior terminado ja foi marcado COMPLETED: nao vem no passe (status de download), mas foi
+// baixado, e a sequencia dele ainda e seguida.
+func TestFollowSequels_CompletedPrequel(t *testing.T) {
+	var batchIDs string
+	defer anilist.MockAniListDo(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		payload := followedSequelMedia
+		if strings.Contains(string(body), "GetMediaByIDs") {
+			batchIDs = string(body)
+			payload = `{"data": {"Page": {"media": [
+				{"id": 100, "format": "TV", "status": "FINISHED", "episodes": 12, "title": {"romaji": "Show"},
+				 "relations": {"edges": [{"relationType": "SEQUEL",
+					"node": {"id": 200, "format": "TV", "status": "RELEASING", "title": {"romaji": "Show 2"}}}]}},
+				{"id": 300, "format": "TV", "status": "RELEASING", "episodes": 12, "title": {"romaji": "Airing"},
+				 "relations": {"edges": [{"relationType": "SEQUEL",
+					"node": {"id": 400, "format": "TV", "status": "RELEASING", "title": {"romaji": "Airing 2"}}}]}}
+			]}}}`
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(payload)), Header: make(http.Header)}, nil
+	})()
+	fm := &sequelsFM{}
+	cfg := &files.Config{FollowSequels: true}
+	saved := []files.EpisodeStruct{
+		{AnimeID: 100, EpisodeNumber: 11}, {AnimeID: 100, EpisodeNumber: 12},
+		{AnimeID: 300, EpisodeNumber: 1},
+		{AnimeID: 500, EpisodeNumber: 1},
+	}
+	// 500 nao segue sequencia: nem entra na consulta.
+	settings := map[int]files.AnimeSettings{500: {FollowSequels: files.FollowSequelsNever}}
+
+	added := followSequels(fm, cfg, nil, saved, settings, time.Now())
+	if len(added) != 1 || added[0].Media.Id != 200 {
+		t.Fatalf("esperava a sequencia 200 do anterior COMPLETED, obteve %+v", added)
+	}
+	if !slices.Equal(fm.standaloneAnimes, []int{200}) {
+		t.Errorf("so a sequencia de um anterior terminado entra, obteve %v", fm.standaloneAnimes)
+	}
+	if strings.Contains(batchIDs, "500") {
+		t.Errorf("anime que nao segue sequencia nao deveria ser consultado: %s", batchIDs)
+	}
+}
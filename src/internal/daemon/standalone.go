@@ -133,7 +133,7 @@ func DownloadStandaloneAnime(fm FileManagerInterface, backend torrents.TorrentBa
 		customQuery = s.CustomSearchQuery
 	}
 
-	result := processAnimeEpisodes(configs, backend, *anime, backend.List(), savedEpisodes, blockedMap, customQuery, defaultNyaaSearcher())
+	result := processAnimeEpisodes(configs, backend, *anime, backend.List(), savedEpisodes, blockedMap, customQuery, 0, defaultNyaaSearcher())
 
 	saveEpisodesToFile(fm, result.newEpisodes)
 
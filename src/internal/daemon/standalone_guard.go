@@ -0,0 +1,98 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/anilist"
+)
+
+// Motivos pelos quais um anime nao pode ser adicionado como avulso. Sao tambem os codigos de
+// erro do 409 do POST e as chaves de tooltip da tela de busca — um campo so, nao quatro
+// booleanos, porque sao mutuamente exclusivos por precedencia e o front precisa de um rotulo
+// unico por card.
+const (
+	BlockReasonBlacklist  = "blacklist"
+	BlockReasonStandalone = "standalone"
+	BlockReasonTracked    = "tracked"
+	BlockReasonDownloaded = "downloaded"
+)
+
+// StandaloneGuard answers the one question the API's add/search and the sequel follower ask:
+// can this anime be added as standalone?
+//
+// Uma funcao de bloqueio, tres consumidores (POST, busca e followSequels). E a mesma funcao
+// porque "o front nao deixa clicar" e "o back devolve erro" precisam concordar por construcao —
+// duas definicoes produziriam um card cinza que o backend aceita, ou o inverso. A sequencia
+// seguida sozinha tambem nao pode entrar por onde o usuario nao entraria.
+type StandaloneGuard struct {
+	standalone  map[int]bool // standalone_animes
+	downloaded  map[int]int  // mediaID → nº de registros em episodes.json
+	tracked     map[int]bool // snapshot do que o daemon PROCESSA (ver NewStandaloneGuard)
+	blacklisted map[int]bool // customLists ∩ excluded_lists
+}
+
+// BlockReason devolve "" quando o anime pode ser adicionado, senao um dos quatro motivos.
+//
+// Precedencia: blacklist > avulso > lista > baixado. Blacklist vem primeiro porque e o unico
+// motivo em que adicionar mudaria o comportamento PARA PIOR: um blacklisted em status fora de
+// download_statuses escapa do searchAnilist, o registro avulso sobrevive ao merge e o filtro do
+// usuario e contornado (o MediaList sintetico de GetMediaByID tem CustomLists nulo, entao
+// animeIsInExcludedList nunca dispara nele). Os outros tres sao inocuos — os registros
+// existentes ja fazem o loop pular tudo — e a mensagem e so clareza.
+func (g StandaloneGuard) BlockReason(mediaID, totalEpisodes int) string {
+	switch {
+	case g.blacklisted[mediaID]:
+		return BlockReasonBlacklist
+	case g.standalone[mediaID]:
+		return BlockReasonStandalone
+	case g.tracked[mediaID]:
+		return BlockReasonTracked
+	// So bloqueia com contagem CONHECIDA: um anime de 24 episodios com 12 registros (o limite
+	// por anime) nao e "ja baixado", e um total desconhecido nao autoriza afirmar nada.
+	case totalEpisodes > 0 && g.downloaded[mediaID] >= totalEpisodes:
+		return BlockReasonDownloaded
+	}
+	return ""
+}
+
+// NewStandaloneGuard builds the guard from the local files and the list entries the daemon
+// processes.
+//
+// entries e o conjunto que o daemon PROCESSA (download_statuses server-side +
+// MediaStatusAllowed), com os customLists ja sobrepostos — nao "existe entrada na AniList". Um
+// anime em PLANNING com download_statuses = [CURRENT] esta numa lista e o daemon o ignora, e
+// adiciona-lo como avulso e o caso de uso mais obvio da feature. A API monta entries com
+// fetchAniListEntries (o caminho com cache do frontend); o passe, com a lista que ja tem.
+func NewStandaloneGuard(fm FileManagerInterface, excludedLists []string, entries []anilist.MediaList) (StandaloneGuard, error) {
+	guard := StandaloneGuard{
+		standalone:  map[int]bool{},
+		downloaded:  map[int]int{},
+		tracked:     map[int]bool{},
+		blacklisted: map[int]bool{},
+	}
+
+	standaloneIDs, err := fm.LoadStandaloneAnimes()
+	if err != nil {
+		return guard, err
+	}
+	for _, id := range standaloneIDs {
+		guard.standalone[id] = true
+	}
+
+	episodes, err := fm.LoadSavedEpisodes()
+	if err != nil {
+		return guard, err
+	}
+	for _, ep := range episodes {
+		if ep.AnimeID != 0 {
+			guard.downloaded[ep.AnimeID]++
+		}
+	}
+
+	for _, ml := range entries {
+		guard.tracked[ml.Media.Id] = true
+		if animeIsInExcludedList(ml, excludedLists) {
+			guard.blacklisted[ml.Media.Id] = true
+		}
+	}
+
+	return guard, nil
+}
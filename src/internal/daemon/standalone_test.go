@@ -83,7 +83,7 @@ func TestSearchAnilist_StandaloneAppended(t *testing.T) {
 	defer mockAniListRouter(t, listWithAnime100, mediaForAnime500)()
 
 	fm := &mockFileManagerForEpisodes{standaloneAnimes: []int{500}}
-	resp, err := searchAnilist(fm, standaloneTestConfig(), []int{500})
+	resp, _, err := searchAnilist(fm, standaloneTestConfig(), []int{500})
 	if err != nil {
 		t.Fatalf("searchAnilist: %v", err)
 	}
@@ -110,7 +110,7 @@ func TestSearchAnilist_StandaloneAlsoInListDoesNotDuplicateNorResetProgress(t *t
 	defer mockAniListRouter(t, listWithAnime100, mediaForAnime100)()
 
 	fm := &mockFileManagerForEpisodes{standaloneAnimes: []int{100}}
-	resp, err := searchAnilist(fm, standaloneTestConfig(), []int{100})
+	resp, _, err := searchAnilist(fm, standaloneTestConfig(), []int{100})
 	if err != nil {
 		t.Fatalf("searchAnilist: %v", err)
 	}
@@ -129,7 +129,7 @@ func TestSearchAnilist_StandaloneAdoptedByListIsRemovedFromFile(t *testing.T) {
 	defer mockAniListRouter(t, listWithAnime100, mediaForAnime100)()
 
 	fm := &mockFileManagerForEpisodes{standaloneAnimes: []int{100}}
-	if _, err := searchAnilist(fm, standaloneTestConfig(), []int{100}); err != nil {
+	if _, _, err := searchAnilist(fm, standaloneTestConfig(), []int{100}); err != nil {
 		t.Fatalf("searchAnilist: %v", err)
 	}
 
@@ -150,7 +150,7 @@ func TestSearchAnilist_StandaloneOutsideDownloadStatusesStays(t *testing.T) {
 	defer mockAniListRouter(t, `{"data": {"Page": {"mediaList": []}}}`, mediaForAnime500)()
 
 	fm := &mockFileManagerForEpisodes{standaloneAnimes: []int{500}}
-	resp, err := searchAnilist(fm, standaloneTestConfig(), []int{500})
+	resp, _, err := searchAnilist(fm, standaloneTestConfig(), []int{500})
 	if err != nil {
 		t.Fatalf("searchAnilist: %v", err)
 	}
@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/torrents"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// trackersListMaxAge e de quanto em quanto tempo a lista de Config.TrackersListURL e baixada
+// de novo. As listas publicas sao regeneradas uma vez por dia; buscar a cada passe (10 min)
+// so gastaria a cota de quem as hospeda.
+const trackersListMaxAge = 24 * time.Hour
+
+// trackersListMaxBytes limita o corpo lido da URL: uma lista real tem poucos KB, e uma URL
+// errada apontando para um arquivo grande nao pode encher a memoria do daemon.
+const trackersListMaxBytes = 1 << 20
+
+var trackersListClient = &http.Client{Timeout: 15 * time.Second}
+
+// refreshTrackersList baixa a lista de trackers quando a salva tem mais de trackersListMaxAge,
+// ou veio de outra URL que a configurada.
+// Falha so e logada: a lista anterior continua em disco e continua valendo, e um GitHub fora
+// do ar nao e motivo para abortar o passe de verificacao.
+func refreshTrackersList(fm FileManagerInterface, configs *files.Config) {
+	if configs.TrackersListURL == "" {
+		return
+	}
+	_, source, savedAt, err := fm.LoadTrackersList()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to read the saved trackers list; fetching a new one")
+	} else if source == configs.TrackersListURL && time.Since(savedAt) < trackersListMaxAge {
+		return
+	}
+
+	trackers, err := fetchTrackersList(configs.TrackersListURL)
+	if err != nil {
+		logger.Logger.Warn().Err(err).Str("url", configs.TrackersListURL).Msg("Failed to fetch the trackers list; keeping the saved one")
+		return
+	}
+	if err := fm.SaveTrackersList(configs.TrackersListURL, trackers); err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to save the trackers list")
+		return
+	}
+	logger.Logger.Info().Int("trackers", len(trackers)).Str("url", configs.TrackersListURL).Msg("Trackers list refreshed")
+}
+
+func fetchTrackersList(url string) ([]string, error) {
+	resp, err := trackersListClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trackers list: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch trackers list: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, trackersListMaxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trackers list: %w", err)
+	}
+	return torrents.ParseTrackerList(string(body)), nil
+}
+
+// ExtraTrackers e a lista efetiva: Config.ExtraTrackers primeiro, depois a lista baixada de
+// TrackersListURL, sem repeticao. O que o usuario escreveu a mao vem antes porque e o que ele
+// sabe que funciona. O arquivo salvo so vale enquanto veio da URL configurada: apagar ou
+// trocar a URL tem de desligar a lista velha, nao congela-la ate a proxima busca dar certo.
+func ExtraTrackers(fm FileManagerInterface, configs *files.Config) []string {
+	out := []string{}
+	seen := make(map[string]bool)
+	add := func(trackers []string) {
+		for _, tr := range trackers {
+			if seen[tr] || !torrents.IsTrackerURL(tr) {
+				continue
+			}
+			seen[tr] = true
+			out = append(out, tr)
+		}
+	}
+
+	add(configs.ExtraTrackers)
+	if configs.TrackersListURL != "" {
+		listed, source, _, err := fm.LoadTrackersList()
+		if err != nil {
+			logger.Logger.Warn().Err(err).Msg("Failed to read the saved trackers list; using only the configured extra trackers")
+		} else if source == configs.TrackersListURL {
+			add(listed)
+		}
+	}
+	return out
+}
+
+// ApplyExtraTrackers empurra a lista efetiva para o backend. Chamado nos mesmos tres lugares
+// que SetMaxActiveDownloads — boot, PUT /config e o topo de todo passe —, pelo mesmo motivo:
+// um config.json editado a mao so passa pelo ultimo.
+func ApplyExtraTrackers(fm FileManagerInterface, backend torrents.TorrentBackend, configs *files.Config) {
+	backend.SetExtraTrackers(ExtraTrackers(fm, configs))
+}
@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/torrents"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func serveTrackersList(t *testing.T, body string) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+// The hand-written trackers come first, the downloaded list fills in after them, and a
+// tracker present in both appears once.
+func TestExtraTrackersMergesConfigAndDownloadedList(t *testing.T) {
+	srv, hits := serveTrackersList(t, "udp://b.tracker:1337/announce\n\nudp://a.tracker:1337/announce\n")
+	fm := tempFileManager(t)
+	configs := &files.Config{
+		ExtraTrackers:   []string{"udp://a.tracker:1337/announce", "wss://ignored.tracker"},
+		TrackersListURL: srv.URL,
+	}
+
+	refreshTrackersList(fm, configs)
+	refreshTrackersList(fm, configs)
+
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("list fetched %d times, want 1 (the saved one is fresh)", got)
+	}
+	got := ExtraTrackers(fm, configs)
+	want := []string{"udp://a.tracker:1337/announce", "udp://b.tracker:1337/announce"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtraTrackers() = %v, want %v", got, want)
+	}
+}
+
+// Clearing the URL must switch the downloaded list off, not freeze the last one.
+func TestExtraTrackersIgnoresSavedListWithoutURL(t *testing.T) {
+	fm := tempFileManager(t)
+	if err := fm.SaveTrackersList("https://lists.example/best.txt", []string{"udp://b.tracker:1337/announce"}); err != nil {
+		t.Fatalf("SaveTrackersList: %v", err)
+	}
+
+	got := ExtraTrackers(fm, &files.Config{ExtraTrackers: []string{}})
+	if len(got) != 0 {
+		t.Errorf("ExtraTrackers() = %v, want empty", got)
+	}
+}
+
+// A list URL that fails keeps the last good list in effect, even once it is stale.
+func TestRefreshTrackersListKeepsSavedListOnFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	fm := files.NewManager(
+		files.NewOSFileSystem(),
+		filepath.Join(dir, "config.json"),
+		filepath.Join(dir, "downloaded_episodes"),
+		filepath.Join(dir, "blocked_episodes"),
+		filepath.Join(dir, "anime_settings"),
+		filepath.Join(dir, "standalone_animes"),
+	)
+	if err := fm.SaveTrackersList(srv.URL, []string{"udp://b.tracker:1337/announce"}); err != nil {
+		t.Fatalf("SaveTrackersList: %v", err)
+	}
+	stale := time.Now().Add(-2 * trackersListMaxAge)
+	if err := os.Chtimes(filepath.Join(dir, "trackers_list"), stale, stale); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	configs := &files.Config{TrackersListURL: srv.URL}
+
+	refreshTrackersList(fm, configs)
+
+	got := ExtraTrackers(fm, configs)
+	if want := []string{"udp://b.tracker:1337/announce"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtraTrackers() = %v, want %v", got, want)
+	}
+}
+
+// A list saved from another URL is refetched right away, not once it is a day old, and is
+// not used in the meantime.
+func TestRefreshTrackersListRefetchesOnURLChange(t *testing.T) {
+	srv, hits := serveTrackersList(t, "udp://new.tracker:1337/announce\n")
+	fm := tempFileManager(t)
+	if err := fm.SaveTrackersList("https://old.example/list.txt", []string{"udp://old.tracker:1337/announce"}); err != nil {
+		t.Fatalf("SaveTrackersList: %v", err)
+	}
+	configs := &files.Config{TrackersListURL: srv.URL}
+
+	if got := ExtraTrackers(fm, configs); len(got) != 0 {
+		t.Errorf("before the refresh ExtraTrackers() = %v, want the old URL's list ignored", got)
+	}
+	refreshTrackersList(fm, configs)
+
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("list fetched %d times, want 1 (the saved one came from another URL)", got)
+	}
+	if got, want := ExtraTrackers(fm, configs), []string{"udp://new.tracker:1337/announce"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtraTrackers() = %v, want %v", got, want)
+	}
+}
+
+func TestApplyExtraTrackersReachesNewMagnets(t *testing.T) {
+	fm := tempFileManager(t)
+	backend := torrents.NewFakeBackend()
+
+	ApplyExtraTrackers(fm, backend, &files.Config{ExtraTrackers: []string{"udp://a.tracker:1337/announce"}})
+	hash, err := backend.Add("magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	trackers, _ := backend.Trackers(hash)
+	if len(trackers) != 1 || trackers[0].URL != "udp://a.tracker:1337/announce" {
+		t.Errorf("Trackers() = %v, want the extra tracker", trackers)
+	}
+}
@@ -3,6 +3,7 @@ package daemon
 import (
 	"AutoAnimeDownloader/src/internal/anilist"
 	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/lists"
 	"AutoAnimeDownloader/src/internal/logger"
 	"AutoAnimeDownloader/src/internal/torrents"
 	"context"
@@ -62,17 +63,18 @@ func AnimeVerification(ctx context.Context, fileManager FileManagerInterface, st
 		return
 	}
 
-	// A biblioteca e montada com hardlinks. O endpoint de save da config sonda isso, mas
-	// configs escritos antes deste upgrade (ou direto no config.json pelo
-	// docker/entrypoint.sh) nunca passaram por ele. Sem esta porta um filesystem sem
-	// suporte a hardlink baixa alegremente enquanto todo JobOrganize morre, e a UI mostra
+	// A biblioteca e montada com hardlinks (ou o LinkMode escolhido). O endpoint de save da
+	// config sonda isso, mas configs escritos antes deste upgrade (ou direto no config.json
+	// pelo docker/entrypoint.sh) nunca passaram por ele. Sem esta porta um filesystem sem
+	// suporte ao modo baixa alegremente enquanto todo JobOrganize morre, e a UI mostra
 	// um daemon saudavel. Sondar aqui devolve a mesma mensagem acionavel do endpoint, e
 	// aborta o passe: baixar o que nao da para organizar so enche o disco.
 	if librarian != nil {
-		if err := librarian.ProbePath(configs.CompletedAnimePath); err != nil {
+		if err := librarian.ProbePath(configs.CompletedAnimePath, configs.LinkMode()); err != nil {
 			logger.Logger.Error().Err(err).
 				Str("completed_anime_path", configs.CompletedAnimePath).
-				Msg("Completed anime path failed the hardlink probe; skipping verification")
+				Str("library_link_mode", string(configs.LinkMode())).
+				Msg("Completed anime path failed the link probe; skipping verification")
 			state.SetLastCheckError(err)
 			return
 		}
@@ -91,6 +93,19 @@ func AnimeVerification(ctx context.Context, fileManager FileManagerInterface, st
 	// seguinte. Roda ANTES do Ensure, quando ainda pode não haver sessão — o passo 0 do
 	// enforce é quem trata isso (ver decisions.md #41).
 	backend.SetMaxActiveDownloads(configs.MaxConcurrentDownloads)
+	// Antes do Ensure e de qualquer Add, pelo mesmo motivo do limite acima.
+	refreshTrackersList(fileManager, configs)
+	// Antes do fan-out: as contas do MAL/Kitsu, a busca e os nfo do passe consultam o indice.
+	refreshIDMapping(fileManager, configs)
+	// Antes da primeira consulta a AniList do passe: sem as respostas salvas, um restart com a
+	// AniList fora do ar nao tem de onde tirar a lista.
+	loadAniListCache(fileManager)
+	anilist.ResetStaleUse()
+	defer saveAniListCache(fileManager)
+	ApplyExtraTrackers(fileManager, backend, configs)
+	ApplyQueuePolicy(backend, configs)
+	// Antes do Ensure e do primeiro Add: o passe le o teto pela guarda checkDataCap.
+	ApplyDataCap(fileManager, backend, configs)
 
 	// Ensure the embedded torrent session exists for the current save path (created lazily,
 	// recreated if the save path changed or if the download folder was swapped underneath).
@@ -119,12 +134,15 @@ func AnimeVerification(ctx context.Context, fileManager FileManagerInterface, st
 	// Phase 1: fetch all independent data sources in parallel.
 	var (
 		anilistResponse  *anilist.AniListResponse
+		wants            listWants
 		savedEpisodes    []files.EpisodeStruct
 		blockedEpisodes  []files.EpisodeKey
 		animeSettingsMap map[int]files.AnimeSettings
 		// inDeleteStatus[username][mediaId] — quais animes cada conta tem em algum status de
 		// deleção. Uma conta cuja busca falhou fica ausente do mapa, e ausente nunca concorda.
 		inDeleteStatus map[string]map[int]bool
+		// listSnapshot e a lista inteira de cada conta, para o feed de mudancas.
+		listSnapshot files.ListSnapshot
 
 		errAnilist  error
 		errEpisodes error
@@ -136,6 +154,9 @@ func AnimeVerification(ctx context.Context, fileManager FileManagerInterface, st
 	if err != nil {
 		logger.Logger.Warn().Err(err).Msg("Failed to load standalone animes, continuing with the AniList lists only")
 		standaloneIDs = nil
+	} else {
+		// So com a lista lida: sem ela todo trial pareceria removido pelo usuario.
+		standaloneIDs = expireTrials(fileManager, backend, librarian, configs, standaloneIDs, time.Now())
 	}
 
 	var fetchWg sync.WaitGroup
@@ -143,7 +164,7 @@ func AnimeVerification(ctx context.Context, fileManager FileManagerInterface, st
 	fetchWg.Add(1)
 	go func() {
 		defer fetchWg.Done()
-		anilistResponse, errAnilist = searchAnilist(fileManager, configs, standaloneIDs)
+		anilistResponse, wants, errAnilist = searchAnilist(fileManager, configs, standaloneIDs)
 	}()
 
 	fetchWg.Add(1)
@@ -179,13 +200,25 @@ func AnimeVerification(ctx context.Context, fileManager FileManagerInterface, st
 		}
 	}()
 
-	if len(configs.DeleteStatuses) > 0 {
+	fetchWg.Add(1)
+	go func() {
+		defer fetchWg.Done()
+		listSnapshot = collectListSnapshot(configs)
+	}()
+
+	if anyDeleteStatuses(configs) {
 		fetchWg.Add(1)
 		go func() {
 			defer fetchWg.Done()
 			inDeleteStatus = make(map[string]map[int]bool, len(configs.AnilistUsernames))
 			for _, username := range configs.AnilistUsernames {
-				resp, e := anilist.GetAllCurrentAnime(username, configs.DeleteStatuses)
+				statuses := configs.ProfileFor(username).DeleteStatuses
+				if len(statuses) == 0 {
+					// Perfil que nunca apaga por status: respondeu, e nada dele e deletavel.
+					inDeleteStatus[username] = map[int]bool{}
+					continue
+				}
+				resp, e := anilist.GetAllCurrentAnime(username, statuses)
 				if e != nil {
 					logger.Logger.Warn().Err(e).Str("username", username).Msg("Failed to fetch AniList animes for delete statuses")
 					// Conta sem resposta nao pode concordar com a deleção — ver deletableMediaIDs.
@@ -201,6 +234,23 @@ func AnimeVerification(ctx context.Context, fileManager FileManagerInterface, st
 					Msg("Fetched animes from Anilist for delete statuses")
 				inDeleteStatus[username] = byMedia
 			}
+			// As contas do MAL e do Kitsu entram no mesmo mapa, pela Key: a lista delas vem
+			// inteira, entao o filtro por status e feito aqui.
+			for _, acc := range lists.Accounts(configs) {
+				entries, e := lists.Entries(acc, configs)
+				if e != nil {
+					logger.Logger.Warn().Err(e).Str("account", acc.Key()).Msg("Failed to fetch list animes for delete statuses")
+					continue
+				}
+				statuses := configs.ProfileFor(acc.Key()).DeleteStatuses
+				byMedia := make(map[int]bool, len(entries))
+				for _, en := range entries {
+					if isInDeleteStatuses(statuses, en.Status) {
+						byMedia[en.MediaID] = true
+					}
+				}
+				inDeleteStatus[acc.Key()] = byMedia
+			}
 		}()
 	}
 
@@ -215,10 +265,14 @@ func AnimeVerification(ctx context.Context, fileManager FileManagerInterface, st
 		return
 	}
 
+	// Antes do processamento: o feed explica o que este passe vai baixar ou apagar.
+	recordListChanges(fileManager, configs, listSnapshot, time.Now())
+
 	// Reconciliation (durable safety net): enqueue JobOrganize for any completed torrent
 	// whose episodes are not yet in the library. Covers completions missed while the daemon
 	// was down and a save-path change. JobOrganize is idempotent, so re-runs are no-ops.
-	reconcileLibrary(downloadedTorrents, savedEpisodes, jobQueue)
+	reconcileLibrary(configs, downloadedTorrents, savedEpisodes, jobQueue)
+	checkArtworkSources(fileManager, jobQueue, anilistResponse.Data.Page.MediaList)
 
 	blockedMap := make(map[files.EpisodeKey]bool, len(blockedEpisodes))
 	for _, k := range blockedEpisodes {
@@ -226,6 +280,11 @@ func AnimeVerification(ctx context.Context, fileManager FileManagerInterface, st
 	}
 
 	animes := anilistResponse.Data.Page.MediaList
+	// Antes do fan-out: a sequencia seguida agora ja e buscada neste passe.
+	animes = append(animes, followSequels(fileManager, configs, animes, savedEpisodes, animeSettingsMap, time.Now())...)
+	animes = append(animes, autoSubscribe(fileManager, configs, animes, time.Now())...)
+	// Depois do autoSubscribe, para o trial que acabou de entrar ja nascer limitado.
+	trialEpisodes := activeTrialEpisodes(fileManager)
 
 	// Regra de deleção por status: TODAS as contas que têm o anime precisam tê-lo em algum
 	// status de deleção (não necessariamente o mesmo). A regra de download é a oposta —
@@ -233,6 +292,10 @@ func AnimeVerification(ctx context.Context, fileManager FileManagerInterface, st
 	deletableMedia := deletableMediaIDs(configs, inDeleteStatus, savedEpisodes)
 
 	var keysToDelete []files.EpisodeKey
+	// profileKeysToDelete sao as delecoes dos animes com perfil envolvido, ja aprovadas pela
+	// config do anime (configsForAnime); so elas escapam do DeleteWatchedEpisodes global.
+	var profileKeysToDelete []files.EpisodeKey
+	animeConfigs := make(map[int]*files.Config, len(animes))
 
 	// Phase 2: process each anime concurrently, bounded by maxConcurrentAnimes.
 	sem := make(chan struct{}, maxConcurrentAnimes)
@@ -260,6 +323,10 @@ outer:
 			customQuery = s.CustomSearchQuery
 		}
 
+		trialLimit := trialEpisodes[anime.Media.Id]
+		animeCfg := configsForAnime(configs, wants.accounts[anime.Media.Id])
+		animeConfigs[anime.Media.Id] = animeCfg
+
 		animeWg.Add(1)
 		go func(a anilist.MediaList, q string) {
 			defer animeWg.Done()
@@ -272,7 +339,7 @@ outer:
 			default:
 			}
 
-			resultCh <- processAnimeEpisodes(configs, backend, a, downloadedTorrents, savedEpisodes, blockedMap, q, defaultNyaaSearcher())
+			resultCh <- processAnimeEpisodes(animeCfg, backend, a, downloadedTorrents, savedEpisodes, blockedMap, q, trialLimit, defaultNyaaSearcher())
 		}(anime, customQuery)
 	}
 
@@ -286,9 +353,16 @@ outer:
 	for r := range resultCh {
 		newEpisodes = append(newEpisodes, r.newEpisodes...)
 		checkedEpisodes = append(checkedEpisodes, r.checkedEpisodes...)
-		keysToDelete = append(keysToDelete, r.keysToDelete...)
+		global, profile := splitKeysToDelete(configs, animeConfigs, wants.complete, r.keysToDelete)
+		keysToDelete = append(keysToDelete, global...)
+		profileKeysToDelete = append(profileKeysToDelete, profile...)
 		issues = append(issues, r.issues...)
 	}
+	// Os episodios novos ja nascem nas bibliotecas de quem os quer: o organize linka em todas
+	// de uma vez.
+	for i := range newEpisodes {
+		newEpisodes[i].Libraries = librariesFor(configs, wants.accounts[newEpisodes[i].AnimeID])
+	}
 
 	select {
 	case <-ctx.Done():
@@ -298,15 +372,30 @@ outer:
 	default:
 	}
 
+	// Os hints entram antes da limpeza para que os torrents adicionados neste passe ja esperem
+	// na fila na posicao da politica, e nao so a partir do passe seguinte.
+	hintEpisodes := append(append([]files.EpisodeStruct(nil), savedEpisodes...), newEpisodes...)
+	backend.SetQueueHints(queueHints(animes, animeSettingsMap, hintEpisodes, time.Now()))
+
 	// Phase 3: sequential cleanup (file writes must not overlap).
 	deleteEpisodesByStatus(deletableMedia, fileManager, backend, librarian, savedEpisodes)
 
 	handleSavedEpisodes(fileManager, configs, backend, librarian, handleEpisodesData{
-		savedEpisodes:   savedEpisodes,
-		keysToDelete:    keysToDelete,
-		checkedEpisodes: checkedEpisodes,
-		newEpisodes:     newEpisodes,
+		savedEpisodes:       savedEpisodes,
+		keysToDelete:        keysToDelete,
+		profileKeysToDelete: profileKeysToDelete,
+		checkedEpisodes:     checkedEpisodes,
+		newEpisodes:         newEpisodes,
 	})
+	syncProfileLibraries(fileManager, jobQueue, configs, animes, wants)
+
+	// Depois do save dos episodios novos, que ja nascem com Meta: le de novo do disco e so
+	// completa os registros antigos.
+	backfillAnimeMeta(fileManager, animes)
+
+	// Depois da limpeza, para nao gastar o orcamento verificando torrent que acabou de ser
+	// apagado; antes do relatorio, que e onde os torrents corrompidos aparecem.
+	issues = append(issues, integritySweep(ctx, fileManager, backend, configs, savedEpisodes)...)
 
 	state.SetLastCheck(time.Now())
 	state.SetLastCheckError(nil)
@@ -315,11 +404,16 @@ outer:
 	// cancelamento acima tambem chama SetLastCheckError(nil) e retorna, entao passe interrompido
 	// nao deixa relatorio — que e o certo, ele estava incompleto.
 	problems, limits := aggregateIssues(issues)
-	state.SetLastCheckReport(CheckReport{
+	report := CheckReport{
 		FinishedAt: time.Now(),
 		Problems:   problems,
 		Limits:     limits,
-	})
+	}
+	if since, stale := anilist.StaleSince(); stale {
+		report.AniListStale = true
+		report.AniListStaleSince = &since
+	}
+	state.SetLastCheckReport(report)
 
 	if err := fileManager.DeleteEmptyFolders(configs.CompletedAnimePath); err != nil {
 		logger.Logger.Warn().Err(err).Msg("Failed to delete empty folders")
@@ -379,9 +473,10 @@ func clearLibraryPathsAfterRootSwap(fileManager FileManagerInterface, completedP
 }
 
 // reconcileLibrary enqueues JobOrganize for completed torrents whose saved episodes have
-// not yet been hardlinked into the library (empty LibraryPaths). Enqueue is deduped, so
-// repeated passes are cheap.
-func reconcileLibrary(downloaded []torrents.TorrentInfo, savedEpisodes []files.EpisodeStruct, jobQueue *JobQueue) {
+// not yet been hardlinked into the library (empty LibraryPaths), or whose links disagree with
+// the profile libraries they belong in (files.Config.LibraryLinksOutOfDate). Enqueue is
+// deduped, so repeated passes are cheap.
+func reconcileLibrary(configs *files.Config, downloaded []torrents.TorrentInfo, savedEpisodes []files.EpisodeStruct, jobQueue *JobQueue) {
 	if jobQueue == nil {
 		return
 	}
@@ -399,14 +494,20 @@ func reconcileLibrary(downloaded []torrents.TorrentInfo, savedEpisodes []files.E
 		if len(eps) == 0 {
 			continue // orphan torrent with no episode record; nothing to organize
 		}
-		needs := false
+		needs, organized := false, false
 		for _, ep := range eps {
-			if len(ep.LibraryPaths) == 0 {
+			if configs.LibraryLinksOutOfDate(ep) {
 				needs = true
-				break
+			}
+			if len(ep.LibraryPaths) > 0 {
+				organized = true
 			}
 		}
-		if needs {
+		switch {
+		case needs && organized:
+			// Ja pousou na biblioteca: o que falta e seguir os perfis, sem notificar de novo.
+			jobQueue.EnqueueReorganize(t.Hash)
+		case needs:
 			jobQueue.EnqueueOrganize(t.Hash)
 		}
 	}
@@ -423,7 +524,7 @@ func reconcileLibrary(downloaded []torrents.TorrentInfo, savedEpisodes []files.E
 // isso que mantém as consultas de desempate raras — um anime deletável some do disco no mesmo
 // passe e nunca mais volta a ser candidato.
 func deletableMediaIDs(configs *files.Config, inDeleteStatus map[string]map[int]bool, savedEpisodes []files.EpisodeStruct) map[int]bool {
-	if len(configs.DeleteStatuses) == 0 || len(inDeleteStatus) == 0 {
+	if !anyDeleteStatuses(configs) || len(inDeleteStatus) == 0 {
 		return nil
 	}
 
@@ -475,7 +576,7 @@ func allAccountsAgreeOnDelete(configs *files.Config, inDeleteStatus map[string]m
 			return false
 		case !tracked:
 			continue // conta não acompanha este anime, não veta
-		case isInDeleteStatuses(configs.DeleteStatuses, status):
+		case isInDeleteStatuses(configs.ProfileFor(username).DeleteStatuses, status):
 			continue // status de deleção diferente do que a busca por lista trouxe
 		default:
 			logger.Logger.Debug().Str("username", username).Int("media_id", mediaID).
@@ -483,16 +584,44 @@ func allAccountsAgreeOnDelete(configs *files.Config, inDeleteStatus map[string]m
 			return false
 		}
 	}
+	for _, acc := range lists.Accounts(configs) {
+		byMedia, fetched := inDeleteStatus[acc.Key()]
+		if !fetched {
+			logger.Logger.Debug().Str("account", acc.Key()).Int("media_id", mediaID).
+				Msg("Skipping status deletion: account list unavailable")
+			return false
+		}
+		if byMedia[mediaID] {
+			continue
+		}
+		// Mesmo desempate das contas da AniList, mas sem consulta nova: a lista ja veio inteira
+		// e esta no cache do pacote lists.
+		status, tracked, err := lists.Status(acc, configs, mediaID)
+		switch {
+		case err != nil:
+			logger.Logger.Warn().Err(err).Str("account", acc.Key()).Int("media_id", mediaID).
+				Msg("Skipping status deletion: could not resolve the account's status")
+			return false
+		case !tracked, isInDeleteStatuses(configs.ProfileFor(acc.Key()).DeleteStatuses, status):
+			continue
+		default:
+			logger.Logger.Debug().Str("account", acc.Key()).Int("media_id", mediaID).
+				Str("status", string(status)).Msg("Status deletion vetoed by account")
+			return false
+		}
+	}
 	return true
 }
 
 // searchAnilist monta o universo de animes do passe: a uniao das listas das contas
-// configuradas mais os animes avulsos (§ standalone).
+// configuradas mais os animes avulsos (§ standalone). Cada conta entra com os status e as
+// listas excluidas do seu perfil (files.AccountProfile), e o listWants devolvido diz quem quer
+// cada anime — o que decide a config do anime e as bibliotecas em que ele entra.
 //
 // fileManager entra aqui, e nao so a config, por causa da remocao automatica: um avulso que
 // depois apareceu numa lista da AniList sai do arquivo, e a unica hora em que se sabe disso e
 // exatamente aqui, com a lista mesclada na mao.
-func searchAnilist(fileManager FileManagerInterface, configs *files.Config, standaloneIDs []int) (*anilist.AniListResponse, error) {
+func searchAnilist(fileManager FileManagerInterface, configs *files.Config, standaloneIDs []int) (*anilist.AniListResponse, listWants, error) {
 	// Sem conta da AniList NAO e erro: o passe ainda tem trabalho a fazer (os avulsos), e
 	// abortar aqui faria a feature nunca rodar numa instalacao sem lista. Sem biblioteca e:
 	// nao ha para onde baixar.
@@ -502,21 +631,29 @@ func searchAnilist(fileManager FileManagerInterface, configs *files.Config, stan
 			Err(err).
 			Str("download_path", configs.DownloadPath()).
 			Msg("Missing required configuration: completed anime path")
-		return nil, err
+		return nil, listWants{}, err
 	}
 
 	merged := &anilist.AniListResponse{}
+	wants := newListWants()
 	var lastErr error
 	for _, username := range configs.AnilistUsernames {
+		profile := configs.ProfileFor(username)
+		// Perfil sem status de download nao pede nada; sem a guarda, status_in vazio traria a
+		// lista inteira. A config global vazia segue como sempre foi.
+		if configs.HasProfile(username) && len(profile.DownloadStatuses) == 0 {
+			continue
+		}
 		// Fetch customLists first via a minimal query (before the complex query that may null it out).
-		clMap := anilist.GetCustomListsMap(username, configs.DownloadStatuses)
+		clMap := anilist.GetCustomListsMap(username, profile.DownloadStatuses)
 
-		resp, err := anilist.GetAllCurrentAnime(username, configs.DownloadStatuses)
+		resp, err := anilist.GetAllCurrentAnime(username, profile.DownloadStatuses)
 		if err != nil {
 			logger.Logger.Error().Err(err).Stack().
 				Str("username", username).
 				Msg("Failed to search animes on Anilist")
 			lastErr = err
+			wants.complete = false
 			continue
 		}
 
@@ -533,7 +670,13 @@ func searchAnilist(fileManager FileManagerInterface, configs *files.Config, stan
 			if cl, ok := clMap[ml.Id]; ok && len(cl) > 0 {
 				ml.CustomLists = cl
 			}
+			// As listas excluidas do perfil valem so para a conta dele; as globais ficam para
+			// shouldSkipEpisode, depois do dedupe.
+			if animeIsInExcludedList(*ml, profile.ExcludedLists) {
+				continue
+			}
 			filtered = append(filtered, *ml)
+			wants.add(ml.Media.Id, username)
 		}
 		resp.Data.Page.MediaList = filtered
 
@@ -545,8 +688,30 @@ func searchAnilist(fileManager FileManagerInterface, configs *files.Config, stan
 		merged.Data.Page.MediaList = append(merged.Data.Page.MediaList, resp.Data.Page.MediaList...)
 	}
 
+	// Contas do MyAnimeList e do Kitsu: as entradas ja chegam como MediaList da AniList, e dai
+	// em diante o dedupe e a poda por progresso nao distinguem a origem.
+	for _, acc := range lists.Accounts(configs) {
+		entries, err := lists.MediaLists(acc, configs, configs.ProfileFor(acc.Key()).DownloadStatuses)
+		if err != nil {
+			logger.Logger.Error().Err(err).Str("account", acc.Key()).Msg("Failed to fetch list animes")
+			lastErr = err
+			wants.complete = false
+			continue
+		}
+		count := 0
+		for _, ml := range entries {
+			if !anilist.MediaStatusAllowed(configs.DownloadMediaStatuses, ml.Media.Status) {
+				continue
+			}
+			merged.Data.Page.MediaList = append(merged.Data.Page.MediaList, ml)
+			wants.add(ml.Media.Id, acc.Key())
+			count++
+		}
+		logger.Logger.Debug().Str("account", acc.Key()).Int("animes_found", count).Msg("Fetched animes from list account")
+	}
+
 	if len(merged.Data.Page.MediaList) == 0 && lastErr != nil {
-		return nil, fmt.Errorf("failed to search animes on Anilist: %w", lastErr)
+		return nil, listWants{}, fmt.Errorf("failed to search animes on Anilist: %w", lastErr)
 	}
 
 	merged.Data.Page.MediaList = anilist.DedupeByMedia(merged.Data.Page.MediaList)
@@ -561,5 +726,5 @@ func searchAnilist(fileManager FileManagerInterface, configs *files.Config, stan
 		Int("animes_found", len(merged.Data.Page.MediaList)).
 		Msg("Successfully fetched animes from Anilist")
 
-	return merged, nil
+	return merged, wants, nil
 }
@@ -44,7 +44,7 @@ func TestSearchAnilist_FiltersByMediaStatus(t *testing.T) {
 		DownloadMediaStatuses: []string{"RELEASING", "FINISHED"},
 	}
 
-	resp, err := searchAnilist(&mockFileManagerForEpisodes{}, config, nil)
+	resp, _, err := searchAnilist(&mockFileManagerForEpisodes{}, config, nil)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -79,7 +79,7 @@ func TestSearchAnilist_EmptyMediaStatusesAllowsNothing(t *testing.T) {
 		DownloadMediaStatuses: []string{},
 	}
 
-	resp, err := searchAnilist(&mockFileManagerForEpisodes{}, config, nil)
+	resp, _, err := searchAnilist(&mockFileManagerForEpisodes{}, config, nil)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -318,3 +318,46 @@ func TestAnimeVerification_CancelledLeavesNoReport(t *testing.T) {
 		t.Errorf("passe cancelado não deve deixar relatório, obteve %+v", report)
 	}
 }
+
+// TestAnimeVerification_StaleAniListFlagsReport: com a AniList fora do ar o passe roda com as
+// respostas salvas do passe anterior, e o relatorio diz isso. Sem a marca a tela mostraria um
+// passe limpo feito com dados que podem ter horas.
+func TestAnimeVerification_StaleAniListFlagsReport(t *testing.T) {
+	const body = `{"data": {"Page": {"mediaList": [
+		{"id": 1, "status": "CURRENT", "progress": 0, "customLists": {}, "media": {
+			"id": 900, "format": "TV", "status": "RELEASING", "episodes": 12,
+			"title": {"english": "Report Anime", "romaji": "Report Anime"},
+			"synonyms": [], "relations": {"edges": []},
+			"airingSchedule": {"nodes": [{"id": 1, "episode": 1, "timeUntilAiring": -100, "airingAt": 1}]}
+		}}
+	]}}}`
+	down := false
+	restore := anilist.MockAniListDo(func(req *http.Request) (*http.Response, error) {
+		if down {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header)}, nil
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header)}, nil
+	})
+	t.Cleanup(restore)
+	emptyNyaa(t)
+
+	fm := &lifecycleFM{configs: reportPassConfig(t)}
+	state := NewState()
+	AnimeVerification(context.Background(), fm, state, nil, torrents.NewFakeBackend(), files.NewLibrarian(files.NewOSFileSystem()))
+	if report := state.GetLastCheckReport(); report.AniListStale {
+		t.Fatalf("passe com a AniList no ar nao e velho: %+v", report)
+	}
+	if fm.anilistCache == nil {
+		t.Fatal("o passe deveria salvar as respostas da AniList")
+	}
+
+	down = true
+	AnimeVerification(context.Background(), fm, state, nil, torrents.NewFakeBackend(), files.NewLibrarian(files.NewOSFileSystem()))
+	report := state.GetLastCheckReport()
+	if report.FinishedAt.IsZero() || report.PassError != "" {
+		t.Fatalf("o passe deveria completar com as respostas salvas, obteve %+v", report)
+	}
+	if !report.AniListStale || report.AniListStaleSince == nil {
+		t.Errorf("relatorio deveria marcar a AniList como velha, obteve %+v", report)
+	}
+}
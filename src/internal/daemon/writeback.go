@@ -0,0 +1,154 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// WriteBackAction is what happened to one account's AniList entry.
+type WriteBackAction string
+
+const (
+	// WriteBackUpdated: the entry's progress moved up to the episode.
+	WriteBackUpdated WriteBackAction = "updated"
+	// WriteBackAlreadyWatched: the entry's progress was already at or past the episode.
+	WriteBackAlreadyWatched WriteBackAction = "already_watched"
+	// WriteBackNotInList: the account does not track the anime.
+	WriteBackNotInList WriteBackAction = "not_in_list"
+	// WriteBackNoToken: the account has no usable login; nothing was written.
+	WriteBackNoToken WriteBackAction = "no_token"
+	// WriteBackFailed: AniList refused or failed the write (Error says why).
+	WriteBackFailed WriteBackAction = "failed"
+)
+
+// AccountWriteBack is the outcome of a progress write on one configured account.
+type AccountWriteBack struct {
+	Username string          `json:"username" example:"someone"`
+	Action   WriteBackAction `json:"action" example:"updated"`
+	// Completed is true when the entry was also moved to COMPLETED.
+	Completed bool   `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// WatchedResult reports a "mark as watched": the standalone progress, or each account's write.
+type WatchedResult struct {
+	Standalone bool               `json:"standalone"`
+	Progress   int                `json:"progress,omitempty" example:"5"`
+	Accounts   []AccountWriteBack `json:"accounts,omitempty"`
+}
+
+// MarkWatched marks episode as watched on one anime: a standalone anime's AnimeSettings.Progress
+// moves up to it, and a list anime's progress moves up on AniList in every configured account
+// that tracks it and is logged in. Progress never moves down on either side.
+func MarkWatched(fm FileManagerInterface, configs *files.Config, mediaID, episode int) (*WatchedResult, error) {
+	standalone, err := fm.LoadStandaloneAnimes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load standalone animes: %w", err)
+	}
+	if slices.Contains(standalone, mediaID) {
+		progress, _, err := advanceStandaloneProgress(fm, mediaID, episode)
+		if err != nil {
+			return nil, err
+		}
+		return &WatchedResult{Standalone: true, Progress: progress}, nil
+	}
+	return &WatchedResult{Accounts: writeBackProgress(fm, configs, mediaID, episode)}, nil
+}
+
+// advanceStandaloneProgress sobe o progresso salvo do avulso ate episode. Devolve o progresso
+// que ficou e se ele mudou; o resto do AnimeSettings (busca, peso) fica como estava.
+func advanceStandaloneProgress(fm FileManagerInterface, mediaID, episode int) (int, bool, error) {
+	settings := files.AnimeSettings{}
+	if s, err := fm.LoadAnimeSettings(mediaID); err != nil {
+		return 0, false, fmt.Errorf("failed to load anime settings: %w", err)
+	} else if s != nil {
+		settings = *s
+	}
+	if settings.Progress >= episode {
+		return settings.Progress, false, nil
+	}
+	settings.Progress = episode
+	if err := fm.SaveAnimeSettings(mediaID, settings); err != nil {
+		return 0, false, fmt.Errorf("failed to save anime settings: %w", err)
+	}
+	return settings.Progress, true, nil
+}
+
+// writeBackProgress sobe o progresso do anime na AniList em cada conta configurada. Cada conta e
+// independente: uma sem login, ou que a AniList recusa, nao impede a escrita nas outras — o
+// DedupeByMedia usa o MENOR progresso, entao a conta que ficou para tras segura a poda ate
+// alguem fazer login nela, que e o comportamento certo.
+func writeBackProgress(fm FileManagerInterface, configs *files.Config, mediaID, episode int) []AccountWriteBack {
+	tokens, err := fm.LoadAnilistTokens()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Msg("Failed to load AniList tokens, writing back to no account")
+		tokens = nil
+	}
+
+	now := time.Now()
+	out := make([]AccountWriteBack, 0, len(configs.AnilistUsernames))
+	for _, username := range configs.AnilistUsernames {
+		res := AccountWriteBack{Username: username}
+		tok, ok := tokens[username]
+		if !ok || !tok.Usable(now) {
+			res.Action = WriteBackNoToken
+			out = append(out, res)
+			continue
+		}
+
+		entry, err := anilist.GetMediaListEntry(username, mediaID)
+		switch {
+		case err != nil:
+			res.Action, res.Error = WriteBackFailed, err.Error()
+		case entry == nil:
+			res.Action = WriteBackNotInList
+		case entry.Progress >= episode:
+			res.Action = WriteBackAlreadyWatched
+		default:
+			if err := anilist.SetProgress(tok.AccessToken, mediaID, episode); err != nil {
+				res.Action, res.Error = WriteBackFailed, writeBackError(err)
+				break
+			}
+			res.Action = WriteBackUpdated
+			if configs.AnilistCompleteOnLastEpisode && isLastEpisode(entry, episode) {
+				if err := anilist.SetStatus(tok.AccessToken, mediaID, anilist.MediaListStatusCompleted); err != nil {
+					// O progresso ja foi; o status fica para a proxima vez ou para a mao.
+					logger.Logger.Warn().Err(err).Str("username", username).Int("media_id", mediaID).
+						Msg("Failed to move the AniList entry to COMPLETED")
+				} else {
+					res.Completed = true
+				}
+			}
+		}
+
+		if res.Action == WriteBackFailed {
+			logger.Logger.Warn().Str("username", username).Int("media_id", mediaID).Str("error", res.Error).
+				Msg("Failed to write the progress back to AniList")
+		} else if res.Action == WriteBackUpdated {
+			logger.Logger.Info().Str("username", username).Int("media_id", mediaID).Int("progress", episode).
+				Bool("completed", res.Completed).Msg("AniList progress updated")
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+// isLastEpisode: so com o total conhecido. Um anime em exibicao sem total nunca completa, e um
+// REPEATING ja completo tambem nao e mexido.
+func isLastEpisode(entry *anilist.MediaList, episode int) bool {
+	return entry.Media.Episodes != nil && *entry.Media.Episodes > 0 && episode >= *entry.Media.Episodes &&
+		entry.Status != anilist.MediaListStatusCompleted && entry.Status != anilist.MediaListStatusRepeating
+}
+
+func writeBackError(err error) string {
+	if errors.Is(err, anilist.ErrUnauthorized) {
+		return "AniList rejected the login; log in again"
+	}
+	return err.Error()
+}
@@ -0,0 +1,155 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+)
+
+// mockAniListAccounts responde a entrada da lista de cada conta (progresso; ausente = fora da
+// lista) e registra as mutations, com o token que as mandou.
+func mockAniListAccounts(t *testing.T, progress map[string]int, episodes int, unauthorized string) (*[]string, func()) {
+	t.Helper()
+	var writes []string
+	restore := anilist.MockAniListDo(func(req *http.Request) (*http.Response, error) {
+		var payload struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		body, _ := io.ReadAll(req.Body)
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("corpo inesperado: %s", body)
+		}
+		resp := func(status int, s string) (*http.Response, error) {
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(s))}, nil
+		}
+		if strings.HasPrefix(payload.Query, "mutation") {
+			token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if token == unauthorized {
+				return resp(http.StatusUnauthorized, `{"errors":[{"message":"Invalid token"}]}`)
+			}
+			vars, _ := json.Marshal(payload.Variables)
+			writes = append(writes, token+" "+string(vars))
+			return resp(http.StatusOK, `{"data":{"SaveMediaListEntry":{"id":1}}}`)
+		}
+		p, ok := progress[payload.Variables["userName"].(string)]
+		if !ok {
+			return resp(http.StatusOK, `{"data":{"Page":{"mediaList":[]}}}`)
+		}
+		return resp(http.StatusOK, `{"data":{"Page":{"mediaList":[{"id":1,"status":"CURRENT","progress":`+
+			strconv.Itoa(p)+`,"media":{"id":7,"episodes":`+strconv.Itoa(episodes)+`}}]}}}`)
+	})
+	return &writes, restore
+}
+
+func TestMarkWatchedWritesBackPerAccount(t *testing.T) {
+	writes, restore := mockAniListAccounts(t, map[string]int{"ahead": 9, "behind": 3, "nologin": 1, "expired": 1, "revoked": 1}, 12, "tok-revoked")
+	defer restore()
+
+	future := time.Now().Add(time.Hour)
+	fm := &mockFileManagerForEpisodes{anilistTokens: map[string]files.AnilistToken{
+		"ahead":   {AccessToken: "tok-ahead", ExpiresAt: future},
+		"behind":  {AccessToken: "tok-behind", ExpiresAt: future},
+		"missing": {AccessToken: "tok-missing"},
+		"expired": {AccessToken: "tok-expired", ExpiresAt: time.Now().Add(-time.Hour)},
+		"revoked": {AccessToken: "tok-revoked", ExpiresAt: future},
+	}}
+	cfg := &files.Config{AnilistUsernames: []string{"ahead", "behind", "missing", "nologin", "expired", "revoked"}}
+
+	res, err := MarkWatched(fm, cfg, 7, 5)
+	if err != nil {
+		t.Fatalf("MarkWatched: %v", err)
+	}
+	want := map[string]WriteBackAction{
+		"ahead":   WriteBackAlreadyWatched,
+		"behind":  WriteBackUpdated,
+		"missing": WriteBackNotInList,
+		"nologin": WriteBackNoToken,
+		"expired": WriteBackNoToken,
+		"revoked": WriteBackFailed,
+	}
+	if res.Standalone || len(res.Accounts) != len(want) {
+		t.Fatalf("result = %+v", res)
+	}
+	for _, acc := range res.Accounts {
+		if acc.Action != want[acc.Username] {
+			t.Errorf("%s: action %q, want %q (error %q)", acc.Username, acc.Action, want[acc.Username], acc.Error)
+		}
+		if acc.Completed {
+			t.Errorf("%s: episode 5 of 12 must not complete the entry", acc.Username)
+		}
+	}
+	if len(*writes) != 1 || (*writes)[0] != `tok-behind {"mediaId":7,"progress":5}` {
+		t.Errorf("writes = %v, want only the progress of the account behind", *writes)
+	}
+}
+
+func TestMarkWatchedCompletesOnLastEpisode(t *testing.T) {
+	writes, restore := mockAniListAccounts(t, map[string]int{"someone": 11}, 12, "")
+	defer restore()
+
+	fm := &mockFileManagerForEpisodes{anilistTokens: map[string]files.AnilistToken{"someone": {AccessToken: "tok"}}}
+	cfg := &files.Config{AnilistUsernames: []string{"someone"}}
+
+	// Desligado, o ultimo episodio so sobe o progresso.
+	if res, _ := MarkWatched(fm, cfg, 7, 12); res.Accounts[0].Completed || len(*writes) != 1 {
+		t.Fatalf("without the option: result %+v, writes %v", res, *writes)
+	}
+
+	*writes = nil
+	cfg.AnilistCompleteOnLastEpisode = true
+	res, _ := MarkWatched(fm, cfg, 7, 12)
+	if !res.Accounts[0].Completed {
+		t.Errorf("result = %+v, want completed", res.Accounts[0])
+	}
+	if len(*writes) != 2 || (*writes)[1] != `tok {"mediaId":7,"status":"COMPLETED"}` {
+		t.Errorf("writes = %v", *writes)
+	}
+}
+
+func TestMarkWatchedStandalone(t *testing.T) {
+	defer anilist.MockAniListDo(func(*http.Request) (*http.Response, error) {
+		t.Fatal("a standalone anime must not touch AniList")
+		return nil, nil
+	})()
+
+	fm := &playbackFM{mockFileManagerForEpisodes{
+		standaloneAnimes: []int{7},
+		settings:         map[int]files.AnimeSettings{7: {Progress: 2}},
+		anilistTokens:    map[string]files.AnilistToken{"someone": {AccessToken: "tok"}},
+	}}
+	res, err := MarkWatched(fm, &files.Config{AnilistUsernames: []string{"someone"}}, 7, 4)
+	if err != nil {
+		t.Fatalf("MarkWatched: %v", err)
+	}
+	if !res.Standalone || res.Progress != 4 || fm.settings[7].Progress != 4 {
+		t.Errorf("result = %+v, settings = %+v", res, fm.settings[7])
+	}
+}
+
+func TestRecordPlaybackWritesBackWithSync(t *testing.T) {
+	writes, restore := mockAniListAccounts(t, map[string]int{"someone": 1}, 12, "")
+	defer restore()
+
+	fm := &mockFileManagerForEpisodes{
+		savedEpisodes: []files.EpisodeStruct{{AnimeID: 7, AnimeName: "Dandadan", EpisodeNumber: 2, LibraryPaths: []string{"/library/Dandadan/e02.mkv"}}},
+		anilistTokens: map[string]files.AnilistToken{"someone": {AccessToken: "tok"}},
+	}
+	cfg := &files.Config{AnilistUsernames: []string{"someone"}}
+
+	if res, _ := RecordPlayback(fm, cfg, "/library/Dandadan/e02.mkv"); res.Action != PlaybackListAnime || res.Accounts != nil || len(*writes) != 0 {
+		t.Fatalf("sync off: result %+v, writes %v", res, *writes)
+	}
+	cfg.AnilistPlaybackSync = true
+	res, _ := RecordPlayback(fm, cfg, "/library/Dandadan/e02.mkv")
+	if len(res.Accounts) != 1 || res.Accounts[0].Action != WriteBackUpdated || len(*writes) != 1 {
+		t.Errorf("sync on: result %+v, writes %v", res, *writes)
+	}
+}
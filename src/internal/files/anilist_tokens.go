@@ -0,0 +1,69 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// AnilistToken e o token de escrita de uma conta da AniList, do login OAuth (decisions.md #82).
+// Fica no arquivo anilist_tokens ao lado do config.json, e nao no config: o GET /config devolve a
+// config inteira para a tela, e o token escreve na lista de alguem.
+type AnilistToken struct {
+	AccessToken string `json:"access_token"`
+	// ExpiresAt e zero quando a validade e desconhecida.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	// ViewerID e ViewerName sao a conta que fez o login, conferida contra o username.
+	ViewerID   int    `json:"viewer_id"`
+	ViewerName string `json:"viewer_name"`
+}
+
+// Usable diz se o token ainda serve para escrever. Validade desconhecida conta como valida: a
+// AniList responde 401 se nao for.
+func (t AnilistToken) Usable(now time.Time) bool {
+	return t.AccessToken != "" && (t.ExpiresAt.IsZero() || now.Before(t.ExpiresAt))
+}
+
+// LoadAnilistTokens devolve o mapa username -> token. Arquivo ausente e mapa vazio: nenhuma
+// conta fez login.
+func (m *FileManager) LoadAnilistTokens() (map[string]AnilistToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.fs.Stat(m.anilistTokensPath)
+	if os.IsNotExist(err) {
+		return map[string]AnilistToken{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat anilist tokens file: %w", err)
+	}
+
+	b, err := m.fs.ReadFile(m.anilistTokensPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anilist tokens file: %w", err)
+	}
+
+	tokens := map[string]AnilistToken{}
+	if err := json.Unmarshal(b, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse anilist tokens file: %w", err)
+	}
+	return tokens, nil
+}
+
+// SaveAnilistTokens substitui o mapa salvo. O arquivo e 0600: o token escreve na lista.
+func (m *FileManager) SaveAnilistTokens(tokens map[string]AnilistToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if tokens == nil {
+		tokens = map[string]AnilistToken{}
+	}
+	b, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal anilist tokens: %w", err)
+	}
+	if err := m.writeAtomicMode(m.anilistTokensPath, b, 0600); err != nil {
+		return fmt.Errorf("failed to write anilist tokens file: %w", err)
+	}
+	return nil
+}
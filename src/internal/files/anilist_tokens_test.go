@@ -0,0 +1,61 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestAnilistTokens(t *testing.T) {
+	t.Run("arquivo ausente devolve mapa vazio, nao erro", func(t *testing.T) {
+		tokens, err := newTestManager(t).LoadAnilistTokens()
+		if err != nil || len(tokens) != 0 {
+			t.Fatalf("quero mapa vazio, veio %v, %v", tokens, err)
+		}
+	})
+
+	t.Run("ida e volta, em arquivo 0600", func(t *testing.T) {
+		dir := t.TempDir()
+		m := newTestManagerIn(t, dir)
+		exp := time.Date(2027, 1, 2, 3, 4, 5, 0, time.UTC)
+		if err := m.SaveAnilistTokens(map[string]AnilistToken{"someone": {AccessToken: "tok", ExpiresAt: exp, ViewerID: 5, ViewerName: "Someone"}}); err != nil {
+			t.Fatalf("SaveAnilistTokens: %v", err)
+		}
+		tokens, err := m.LoadAnilistTokens()
+		if err != nil {
+			t.Fatalf("LoadAnilistTokens: %v", err)
+		}
+		if got := tokens["someone"]; got.AccessToken != "tok" || !got.ExpiresAt.Equal(exp) || got.ViewerID != 5 {
+			t.Fatalf("token = %+v", got)
+		}
+
+		info, err := os.Stat(filepath.Join(dir, anilistTokensFileName))
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		// No Windows as permissoes unix nao existem.
+		if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+			t.Errorf("perm = %v, quero 0600", info.Mode().Perm())
+		}
+	})
+
+	t.Run("Usable", func(t *testing.T) {
+		now := time.Now()
+		cases := []struct {
+			tok  AnilistToken
+			want bool
+		}{
+			{AnilistToken{}, false},
+			{AnilistToken{AccessToken: "t"}, true},
+			{AnilistToken{AccessToken: "t", ExpiresAt: now.Add(time.Hour)}, true},
+			{AnilistToken{AccessToken: "t", ExpiresAt: now.Add(-time.Hour)}, false},
+		}
+		for _, c := range cases {
+			if got := c.tok.Usable(now); got != c.want {
+				t.Errorf("%+v.Usable = %v, quero %v", c.tok, got, c.want)
+			}
+		}
+	})
+}
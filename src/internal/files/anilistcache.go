@@ -0,0 +1,38 @@
+package files
+
+import (
+	"fmt"
+	"os"
+)
+
+// Cache de respostas da AniList (anilist.LoadResponseCache / ResponseCacheSnapshot), gravado
+// como o pacote anilist o serializa: o formato e dele, o disco so guarda.
+
+// LoadAniListCache devolve o cache salvo. Arquivo ausente e nil, nao erro.
+func (m *FileManager) LoadAniListCache() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.fs.Stat(m.anilistCachePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat anilist cache file: %w", err)
+	}
+	data, err := m.fs.ReadFile(m.anilistCachePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anilist cache file: %w", err)
+	}
+	return data, nil
+}
+
+// SaveAniListCache substitui o cache salvo.
+func (m *FileManager) SaveAniListCache(data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.writeAtomic(m.anilistCachePath, data); err != nil {
+		return fmt.Errorf("failed to write anilist cache file: %w", err)
+	}
+	return nil
+}
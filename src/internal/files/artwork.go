@@ -0,0 +1,90 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Nomes da arte da serie na pasta da biblioteca, os que o Jellyfin (e o Plex/Kodi) leem sem
+// configuracao.
+const (
+	PosterFileName = "poster.jpg"
+	FanartFileName = "fanart.jpg"
+)
+
+// ArtworkSource e a URL de onde veio cada imagem que o daemon baixou para uma serie. Uma
+// imagem presente sem URL registrada e do usuario e nunca e substituida; com URL registrada e
+// nossa, e uma URL nova na AniList (capa trocada) a substitui.
+type ArtworkSource struct {
+	Poster string `json:"poster,omitempty"`
+	Fanart string `json:"fanart,omitempty"`
+}
+
+// LoadArtworkSources devolve o mapa id da serie -> URLs baixadas. Arquivo ausente e mapa
+// vazio, nao erro: nenhuma arte foi baixada ainda. O arquivo fica ao lado do config.json, fora
+// da biblioteca, e a chave e o id da serie (LibraryMove.ShowID), nao a pasta: o relink move a
+// pasta e a arte vai junto.
+func (m *FileManager) LoadArtworkSources() (map[int]ArtworkSource, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.fs.Stat(m.artworkSourcesPath)
+	if os.IsNotExist(err) {
+		return map[int]ArtworkSource{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat artwork sources file: %w", err)
+	}
+
+	b, err := m.fs.ReadFile(m.artworkSourcesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artwork sources file: %w", err)
+	}
+
+	sources := map[int]ArtworkSource{}
+	if err := json.Unmarshal(b, &sources); err != nil {
+		return nil, fmt.Errorf("failed to parse artwork sources file: %w", err)
+	}
+	return sources, nil
+}
+
+// SaveArtworkSources substitui o mapa salvo.
+func (m *FileManager) SaveArtworkSources(sources map[int]ArtworkSource) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if sources == nil {
+		sources = map[int]ArtworkSource{}
+	}
+	b, err := json.MarshalIndent(sources, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal artwork sources: %w", err)
+	}
+	if err := m.writeAtomic(m.artworkSourcesPath, b); err != nil {
+		return fmt.Errorf("failed to write artwork sources file: %w", err)
+	}
+	return nil
+}
+
+func (o *organizer) HasArtwork(path string) bool {
+	_, err := o.fs.Stat(path)
+	return err == nil
+}
+
+func (o *organizer) SaveArtwork(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if _, err := o.fs.Stat(dir); err != nil {
+		return fmt.Errorf("library folder %s not found: %w", dir, err)
+	}
+	// Temporario com ponto: o scanner do Jellyfin nao pega uma imagem pela metade.
+	tmp := filepath.Join(dir, "."+filepath.Base(path)+".tmp")
+	if err := o.fs.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := o.fs.Rename(tmp, path); err != nil {
+		_ = o.fs.Remove(tmp)
+		return fmt.Errorf("failed to rename %s: %w", tmp, err)
+	}
+	return nil
+}
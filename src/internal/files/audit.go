@@ -0,0 +1,374 @@
+package files
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// AuditCategory classifica um achado da auditoria da biblioteca (AuditLibrary).
+type AuditCategory string
+
+const (
+	// AuditBrokenLink e um LibraryPaths de torrent completo que sumiu do disco ou que nao e mais
+	// o arquivo que o torrent semeia (substituido a mao, ou de antes de uma troca de raiz).
+	AuditBrokenLink AuditCategory = "broken_link"
+	// AuditOrphanTorrent e um torrent do cliente sem nenhum registro em downloaded_episodes.
+	AuditOrphanTorrent AuditCategory = "orphan_torrent"
+	// AuditOrphanFile e um video (ou uma pasta sem video nenhum) na biblioteca que nenhum
+	// registro aponta, ou uma entrada desconhecida no diretorio de download.
+	AuditOrphanFile AuditCategory = "orphan_file"
+	// AuditDuplicate e um video fora dos registros que e o mesmo arquivo de um que esta neles:
+	// um segundo nome para um episodio ja na biblioteca, que o Jellyfin mostra duas vezes.
+	AuditDuplicate AuditCategory = "duplicate"
+	// AuditRecordWithoutTorrent e um registro cujo torrent nao esta mais no cliente.
+	AuditRecordWithoutTorrent AuditCategory = "record_without_torrent"
+)
+
+// AuditCategories sao as categorias na ordem em que os achados saem.
+var AuditCategories = []AuditCategory{
+	AuditBrokenLink, AuditRecordWithoutTorrent, AuditOrphanTorrent, AuditDuplicate, AuditOrphanFile,
+}
+
+// IsAuditCategory reports whether s is one of AuditCategories.
+func IsAuditCategory(s string) bool {
+	return slices.Contains(AuditCategories, AuditCategory(s))
+}
+
+// AuditFinding e uma divergencia entre os registros, a biblioteca e o cliente de torrent.
+type AuditFinding struct {
+	Category AuditCategory `json:"category" swaggertype:"string" example:"broken_link"`
+	// Path e o arquivo ou pasta do achado; vazio nos que sao so de torrent ou de registro.
+	Path string `json:"path,omitempty" example:"/media/Animes/Frieren/Frieren - E05.mkv"`
+	// Hash e o torrent envolvido, quando ha um.
+	Hash           string `json:"hash,omitempty" example:"c9e15763f722f23e98a29decdfae341b98d53056"`
+	AnimeID        int    `json:"anime_id,omitempty" example:"154587"`
+	AnimeName      string `json:"anime_name,omitempty" example:"Frieren"`
+	EpisodeNumbers []int  `json:"episode_numbers,omitempty"`
+	// Detail diz o que esta errado, em ingles, para a tela.
+	Detail string `json:"detail" example:"library file is missing"`
+}
+
+// AuditTorrent e o que a auditoria precisa de cada torrent do cliente. Fica aqui, e nao como
+// torrents.TorrentInfo, porque files nao depende de torrents.
+type AuditTorrent struct {
+	Hash      string
+	Name      string
+	DataDir   string
+	Completed bool
+}
+
+// AuditRequest e a entrada de AuditLibrary: os registros e os torrents sao lidos por quem
+// chama, a biblioteca e lida do disco.
+type AuditRequest struct {
+	CompletedPath string
+	LinkMode      LinkMode
+	Episodes      []EpisodeStruct
+	Torrents      []AuditTorrent
+	// ScanDownloads procura entradas desconhecidas no diretorio de download. So vale com o
+	// cliente embutido: um cliente externo pode ter la torrents fora da categoria, que a lista
+	// dele nao traz e que a limpeza apagaria.
+	ScanDownloads bool
+}
+
+// seedingFiles devolve o FileInfo de cada video do torrent. Conteudo ausente e lista vazia:
+// sem o arquivo que semeia nao ha com o que comparar.
+func (o *organizer) seedingFiles(dataDir string) []fs.FileInfo {
+	root, videos, err := o.torrentVideoFiles(dataDir)
+	if err != nil {
+		return nil
+	}
+	var out []fs.FileInfo
+	for _, rel := range videos {
+		if info, err := o.fs.Stat(filepath.Join(root, rel)); err == nil {
+			out = append(out, info)
+		}
+	}
+	return out
+}
+
+func matchesAny(mode LinkMode, info fs.FileInfo, candidates []fs.FileInfo) bool {
+	for _, c := range candidates {
+		if sameLibraryFile(mode, c, info) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *organizer) AuditLibrary(req AuditRequest) ([]AuditFinding, error) {
+	if req.CompletedPath == "" {
+		return nil, fmt.Errorf("completed anime path is not configured")
+	}
+	if _, err := o.fs.Stat(req.CompletedPath); err != nil {
+		return nil, fmt.Errorf("cannot access completed path %s: %w", req.CompletedPath, err)
+	}
+	mode := req.LinkMode
+	if mode == "" {
+		mode = LinkHardlink
+	}
+
+	torrentsByHash := make(map[string]AuditTorrent, len(req.Torrents))
+	for _, t := range req.Torrents {
+		torrentsByHash[t.Hash] = t
+	}
+	// Um torrent e a unidade: os episodios de um batch dividem os LibraryPaths e saem num
+	// achado so. Registro sem hash (de antes do cliente embutido) e um grupo sozinho.
+	var groups [][]EpisodeStruct
+	groupOf := make(map[string]int)
+	for _, ep := range req.Episodes {
+		if ep.EpisodeHash == "" {
+			groups = append(groups, []EpisodeStruct{ep})
+			continue
+		}
+		i, ok := groupOf[ep.EpisodeHash]
+		if !ok {
+			i = len(groups)
+			groupOf[ep.EpisodeHash] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], ep)
+	}
+
+	var findings []AuditFinding
+	recorded := make(map[string]bool)
+	// known sao os arquivos que estao nos registros ou semeando, por tamanho: e com eles que um
+	// video fora dos registros e comparado para separar duplicata de orfao.
+	known := make(map[int64][]fs.FileInfo)
+	addKnown := func(info fs.FileInfo) { known[info.Size()] = append(known[info.Size()], info) }
+
+	for _, group := range groups {
+		first := group[0]
+		finding := AuditFinding{Hash: first.EpisodeHash, AnimeID: first.AnimeID, AnimeName: first.AnimeName}
+		for _, ep := range group {
+			finding.EpisodeNumbers = append(finding.EpisodeNumbers, ep.EpisodeNumber)
+		}
+		var paths []string
+		for _, ep := range group {
+			for _, p := range ep.LibraryPaths {
+				p = filepath.Clean(p)
+				if !recorded[p] {
+					recorded[p] = true
+					paths = append(paths, p)
+				}
+			}
+		}
+
+		t, ok := torrentsByHash[first.EpisodeHash]
+		if !ok {
+			f := finding
+			f.Category = AuditRecordWithoutTorrent
+			f.Detail = "torrent is no longer in the client"
+			findings = append(findings, f)
+			for _, p := range paths {
+				if info, err := o.fs.Stat(p); err == nil {
+					addKnown(info)
+				}
+			}
+			continue
+		}
+		// Torrent ainda baixando, ou completo e ainda nao organizado: e o reconcile que cuida.
+		if !t.Completed || len(paths) == 0 {
+			continue
+		}
+
+		seeding := o.seedingFiles(t.DataDir)
+		for _, info := range seeding {
+			addKnown(info)
+		}
+		for _, p := range paths {
+			f := finding
+			f.Category = AuditBrokenLink
+			f.Path = p
+			info, err := o.fs.Stat(p)
+			switch {
+			case err != nil:
+				f.Detail = "library file is missing"
+			case len(seeding) > 0 && !matchesAny(mode, info, seeding):
+				addKnown(info)
+				f.Detail = "library file is not the file the torrent is seeding"
+			default:
+				addKnown(info)
+				continue
+			}
+			findings = append(findings, f)
+		}
+	}
+
+	for _, t := range req.Torrents {
+		if _, ok := groupOf[t.Hash]; ok {
+			continue
+		}
+		findings = append(findings, AuditFinding{
+			Category: AuditOrphanTorrent,
+			Hash:     t.Hash,
+			Detail:   fmt.Sprintf("torrent %q has no episode record", t.Name),
+		})
+	}
+
+	libraryFindings, err := o.auditLibraryFiles(req.CompletedPath, mode, recorded, known)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, libraryFindings...)
+
+	if req.ScanDownloads {
+		findings = append(findings, o.auditDownloadDir(filepath.Join(req.CompletedPath, downloadDirName), req.Torrents)...)
+	}
+
+	slices.SortStableFunc(findings, func(a, b AuditFinding) int {
+		if d := slices.Index(AuditCategories, a.Category) - slices.Index(AuditCategories, b.Category); d != 0 {
+			return d
+		}
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Hash, b.Hash)
+	})
+	return findings, nil
+}
+
+// auditLibraryFiles percorre a biblioteca (sem o diretorio de download e sem nada com ponto)
+// atras de videos fora dos registros e de pastas sem video nenhum: a pasta que sobrou de um
+// anime apagado por fora, so com o tvshow.nfo e a arte. Nfo, arte, legenda e a pasta de fontes
+// nao sao achados; sao da pasta.
+func (o *organizer) auditLibraryFiles(completedPath string, mode LinkMode, recorded map[string]bool, known map[int64][]fs.FileInfo) ([]AuditFinding, error) {
+	var findings []AuditFinding
+	// walk devolve se ha algum video abaixo de dir.
+	var walk func(dir string) (bool, error)
+	walk = func(dir string) (bool, error) {
+		entries, err := o.fs.ReadDir(dir)
+		if err != nil {
+			return false, err
+		}
+		hasVideo := false
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name(), ".") || (e.IsDir() && e.Name() == libraryFontsDir) {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if e.IsDir() {
+				sub, err := walk(path)
+				if err != nil {
+					return false, err
+				}
+				if sub {
+					hasVideo = true
+				} else {
+					findings = append(findings, AuditFinding{Category: AuditOrphanFile, Path: path, Detail: "folder has no video file"})
+				}
+				continue
+			}
+			if !isVideoFile(e.Name()) {
+				continue
+			}
+			hasVideo = true
+			if recorded[path] {
+				continue
+			}
+			f := AuditFinding{Category: AuditOrphanFile, Path: path, Detail: "video file is not in any episode record"}
+			if info, err := o.fs.Stat(path); err == nil && matchesAny(mode, info, known[info.Size()]) {
+				f.Category = AuditDuplicate
+				f.Detail = "another name for a file already in the library"
+			}
+			findings = append(findings, f)
+		}
+		return hasVideo, nil
+	}
+	if _, err := walk(completedPath); err != nil {
+		return nil, fmt.Errorf("failed to scan library %s: %w", completedPath, err)
+	}
+
+	// Uma pasta sem video dentro de outra sem video sai uma vez so, pela de cima: apaga-la
+	// leva as de baixo.
+	var out []AuditFinding
+	for _, f := range findings {
+		nested := slices.ContainsFunc(findings, func(other AuditFinding) bool {
+			return other.Category == AuditOrphanFile && other.Path != f.Path &&
+				strings.HasPrefix(f.Path, other.Path+string(filepath.Separator))
+		})
+		if !nested {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// auditDownloadDir lista o que esta no diretorio de download sem ser o conteudo de um torrent
+// do cliente: sobra de um torrent removido por fora com os dados. Os marcadores (.ignore,
+// .aad_root) e temporarios comecam com ponto e ficam de fora.
+func (o *organizer) auditDownloadDir(downloadPath string, torrents []AuditTorrent) []AuditFinding {
+	entries, err := o.fs.ReadDir(downloadPath)
+	if err != nil {
+		return nil
+	}
+	owned := make(map[string]bool, len(torrents))
+	for _, t := range torrents {
+		rel, err := filepath.Rel(downloadPath, t.DataDir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		owned[strings.Split(rel, string(filepath.Separator))[0]] = true
+	}
+	var findings []AuditFinding
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") || owned[e.Name()] {
+			continue
+		}
+		findings = append(findings, AuditFinding{
+			Category: AuditOrphanFile,
+			Path:     filepath.Join(downloadPath, e.Name()),
+			Detail:   "download folder entry belongs to no torrent",
+		})
+	}
+	return findings
+}
+
+func (o *organizer) RemoveOrphan(completedPath, path string) error {
+	rel, err := filepath.Rel(completedPath, path)
+	if completedPath == "" || err != nil || rel == "." || rel == downloadDirName || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("refusing to remove %s: not inside the library %s", path, completedPath)
+	}
+	info, err := o.fs.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.IsDir() {
+		return o.removeTree(path)
+	}
+	if err := o.RemoveFromLibrary(path); err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != filepath.Clean(completedPath) && filepath.Base(dir) != downloadDirName {
+		o.removeDirIfOnlyShowFiles(dir)
+	}
+	return nil
+}
+
+// removeTree apaga path e tudo abaixo dele. Symlink e apagado, nunca seguido: o DirEntry de um
+// symlink nao e diretorio.
+func (o *organizer) removeTree(path string) error {
+	entries, err := o.fs.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		child := filepath.Join(path, e.Name())
+		if e.IsDir() {
+			if err := o.removeTree(child); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := o.fs.Remove(child); err != nil {
+			return err
+		}
+	}
+	return o.fs.Remove(path)
+}
@@ -0,0 +1,161 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAuditLibrary(t *testing.T) {
+	completed := t.TempDir()
+	downloads := filepath.Join(completed, downloadDirName)
+	show := filepath.Join(completed, "Show")
+
+	writeFile(t, filepath.Join(downloads, "t1", "ep1.mkv"), "one")
+	writeFile(t, filepath.Join(downloads, "t2", "ep2.mkv"), "two")
+	writeFile(t, filepath.Join(downloads, "t5", "ep5.mkv"), "five")
+	writeFile(t, filepath.Join(downloads, "leftover", "old.mkv"), "old")
+	writeFile(t, filepath.Join(downloads, ".ignore"), "")
+	if err := os.MkdirAll(show, 0755); err != nil {
+		t.Fatal(err)
+	}
+	ok1 := filepath.Join(show, "Show - E01.mkv")
+	if err := os.Link(filepath.Join(downloads, "t1", "ep1.mkv"), ok1); err != nil {
+		t.Skipf("hardlinks unsupported here: %v", err)
+	}
+	// Segundo nome do E01 fora dos registros (um relink que parou no meio).
+	duplicate := filepath.Join(show, "Show - S01E01.mkv")
+	if err := os.Link(ok1, duplicate); err != nil {
+		t.Fatal(err)
+	}
+	// O E05 foi trocado a mao por outro arquivo.
+	replaced := filepath.Join(show, "Show - E05.mkv")
+	writeFile(t, replaced, "not five")
+	writeFile(t, filepath.Join(show, "tvshow.nfo"), "<tvshow/>")
+	orphan := filepath.Join(completed, "Other", "random.mkv")
+	writeFile(t, orphan, "random")
+	writeFile(t, filepath.Join(completed, "Gone", "tvshow.nfo"), "<tvshow/>")
+	writeFile(t, filepath.Join(completed, "Gone", "Season 01", "poster.jpg"), "img")
+
+	missing := filepath.Join(show, "Show - E02.mkv")
+	req := AuditRequest{
+		CompletedPath: completed,
+		Episodes: []EpisodeStruct{
+			{AnimeID: 1, AnimeName: "Show", EpisodeNumber: 1, EpisodeHash: "h1", LibraryPaths: []string{ok1}},
+			{AnimeID: 1, AnimeName: "Show", EpisodeNumber: 2, EpisodeHash: "h2", LibraryPaths: []string{missing}},
+			{AnimeID: 1, AnimeName: "Show", EpisodeNumber: 3, EpisodeHash: "h3"},
+			{AnimeID: 1, AnimeName: "Show", EpisodeNumber: 5, EpisodeHash: "h5", LibraryPaths: []string{replaced}},
+		},
+		Torrents: []AuditTorrent{
+			{Hash: "h1", DataDir: filepath.Join(downloads, "t1"), Completed: true},
+			{Hash: "h2", DataDir: filepath.Join(downloads, "t2"), Completed: true},
+			{Hash: "h4", Name: "Unknown", DataDir: filepath.Join(downloads, "t4")},
+			{Hash: "h5", DataDir: filepath.Join(downloads, "t5"), Completed: true},
+		},
+		ScanDownloads: true,
+	}
+
+	findings, err := NewLibrarian(NewOSFileSystem()).AuditLibrary(req)
+	if err != nil {
+		t.Fatalf("AuditLibrary: %v", err)
+	}
+	type key struct {
+		category AuditCategory
+		path     string
+		hash     string
+	}
+	var got []key
+	for _, f := range findings {
+		got = append(got, key{f.Category, f.Path, f.Hash})
+	}
+	want := []key{
+		{AuditBrokenLink, missing, "h2"},
+		{AuditBrokenLink, replaced, "h5"},
+		{AuditRecordWithoutTorrent, "", "h3"},
+		{AuditOrphanTorrent, "", "h4"},
+		{AuditDuplicate, duplicate, ""},
+		{AuditOrphanFile, filepath.Join(downloads, "leftover"), ""},
+		{AuditOrphanFile, filepath.Join(completed, "Gone"), ""},
+		{AuditOrphanFile, orphan, ""},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("findings:\n got  %v\n want %v", got, want)
+	}
+
+	// Com um cliente externo o diretorio de download nao e varrido.
+	req.ScanDownloads = false
+	findings, _ = NewLibrarian(NewOSFileSystem()).AuditLibrary(req)
+	for _, f := range findings {
+		if f.Path == filepath.Join(downloads, "leftover") {
+			t.Error("download folder was scanned without ScanDownloads")
+		}
+	}
+}
+
+func TestAuditLibraryCopyMode(t *testing.T) {
+	completed := t.TempDir()
+	lib := NewLibrarian(NewOSFileSystem())
+	src := filepath.Join(completed, downloadDirName, "t1", "ep1.mkv")
+	writeFile(t, src, "one")
+	dest := filepath.Join(completed, "Show", "Show - E01.mkv")
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.fs.CopyFile(src, dest); err != nil {
+		t.Fatalf("CopyFile: %v", err)
+	}
+
+	req := AuditRequest{
+		CompletedPath: completed,
+		LinkMode:      LinkCopy,
+		Episodes:      []EpisodeStruct{{AnimeID: 1, EpisodeNumber: 1, EpisodeHash: "h1", LibraryPaths: []string{dest}}},
+		Torrents:      []AuditTorrent{{Hash: "h1", DataDir: filepath.Dir(src), Completed: true}},
+	}
+	findings, err := lib.AuditLibrary(req)
+	if err != nil || len(findings) != 0 {
+		t.Errorf("a copy is the seeding file in copy mode: %v, %v", findings, err)
+	}
+	// Em hardlink a mesma copia e outro arquivo.
+	req.LinkMode = LinkHardlink
+	if findings, _ := lib.AuditLibrary(req); len(findings) != 1 || findings[0].Category != AuditBrokenLink {
+		t.Errorf("hardlink mode findings = %v, want one broken_link", findings)
+	}
+}
+
+func TestRemoveOrphan(t *testing.T) {
+	completed := t.TempDir()
+	lib := NewLibrarian(NewOSFileSystem())
+
+	video := filepath.Join(completed, "Show", "random.mkv")
+	writeFile(t, video, "x")
+	writeFile(t, filepath.Join(completed, "Show", "tvshow.nfo"), "<tvshow/>")
+	if err := lib.RemoveOrphan(completed, video); err != nil {
+		t.Fatalf("RemoveOrphan(file): %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(completed, "Show")); !os.IsNotExist(err) {
+		t.Errorf("folder left with only the show nfo should be removed, stat err = %v", err)
+	}
+
+	leftover := filepath.Join(completed, downloadDirName, "leftover")
+	writeFile(t, filepath.Join(leftover, "sub", "a.mkv"), "a")
+	if err := lib.RemoveOrphan(completed, leftover); err != nil {
+		t.Fatalf("RemoveOrphan(dir): %v", err)
+	}
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Errorf("leftover folder should be removed, stat err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(completed, downloadDirName)); err != nil {
+		t.Errorf("download folder must stay: %v", err)
+	}
+
+	// Ausente nao e erro; fora da biblioteca, a propria biblioteca e o download sao recusados.
+	if err := lib.RemoveOrphan(completed, video); err != nil {
+		t.Errorf("missing path: %v", err)
+	}
+	for _, p := range []string{completed, filepath.Join(completed, downloadDirName), filepath.Dir(completed), filepath.Join(completed, "..", "x")} {
+		if err := lib.RemoveOrphan(completed, p); err == nil {
+			t.Errorf("RemoveOrphan(%s) should be refused", p)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// AutoSubscribeRule is a seasonal auto-subscribe rule: every anime of the season that passes
+// all of its criteria becomes a standalone anime in trial mode (see daemon.autoSubscribe).
+// Empty criteria match anything.
+type AutoSubscribeRule struct {
+	// Name identifica a regra na tela e no registro de cada trial. Unico e obrigatorio.
+	Name string `json:"name"`
+	// Enabled = false deixa a regra so na previa de GET /auto-subscribe/matches: da para ver o
+	// que ela pegaria antes de ela adicionar qualquer coisa.
+	Enabled bool `json:"enabled"`
+	// Formats e Genres sao "qualquer um de": TV ou ONA, Action ou Comedy.
+	Formats []string `json:"formats"`
+	Genres  []string `json:"genres"`
+	// MinPopularity e o numero de usuarios da AniList com o anime em alguma lista.
+	MinPopularity int `json:"min_popularity"`
+	// MinScore e a nota media, 0..100. Anime ainda sem nota nao passa quando MinScore > 0 — ele
+	// passa num passe seguinte, quando a nota aparecer.
+	MinScore int `json:"min_score"`
+	// Country e o countryOfOrigin da AniList ("JP", "CN", "KR").
+	Country      string `json:"country"`
+	ExcludeAdult bool   `json:"exclude_adult"`
+	// Season (WINTER, SPRING, SUMMER, FALL) e SeasonYear escolhem a temporada. "" e 0 seguem a
+	// temporada corrente, e a regra anda sozinha de uma temporada para a outra.
+	Season     string `json:"season"`
+	SeasonYear int    `json:"season_year"`
+	// TrialEpisodes e quantos episodios do comeco o trial baixa. >= 1.
+	TrialEpisodes int `json:"trial_episodes"`
+}
+
+// Formatos e temporadas da AniList, para a validacao: uma regra com "TV " nao casaria com nada
+// e ninguem descobriria por que.
+var (
+	autoSubscribeFormats = []string{"TV", "TV_SHORT", "MOVIE", "SPECIAL", "OVA", "ONA", "MUSIC"}
+	autoSubscribeSeasons = []string{"WINTER", "SPRING", "SUMMER", "FALL"}
+)
+
+// ValidateAutoSubscribeRules checks the rules of a PUT /config.
+func ValidateAutoSubscribeRules(rules []AutoSubscribeRule) error {
+	names := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			return fmt.Errorf("every rule needs a name")
+		}
+		if names[r.Name] {
+			return fmt.Errorf("duplicate rule name %q", r.Name)
+		}
+		names[r.Name] = true
+
+		for _, f := range r.Formats {
+			if !slices.Contains(autoSubscribeFormats, f) {
+				return fmt.Errorf("rule %q: unknown format %q", r.Name, f)
+			}
+		}
+		if r.Season != "" && !slices.Contains(autoSubscribeSeasons, r.Season) {
+			return fmt.Errorf("rule %q: season must be WINTER, SPRING, SUMMER, FALL or empty", r.Name)
+		}
+		if r.SeasonYear < 0 || r.MinPopularity < 0 {
+			return fmt.Errorf("rule %q: season year and minimum popularity must be non-negative", r.Name)
+		}
+		if r.MinScore < 0 || r.MinScore > 100 {
+			return fmt.Errorf("rule %q: minimum score must be between 0 and 100", r.Name)
+		}
+		if r.Country != "" && len(r.Country) != 2 {
+			return fmt.Errorf("rule %q: country must be a two-letter code", r.Name)
+		}
+		if r.TrialEpisodes < 1 {
+			return fmt.Errorf("rule %q: trial episodes must be at least 1", r.Name)
+		}
+	}
+	return nil
+}
+
+// Trial e um anime que uma regra adicionou. O registro fica depois que o trial termina: e ele
+// que impede a regra de adicionar de novo um anime que o usuario tirou ou deixou expirar.
+type Trial struct {
+	Rule  string `json:"rule"`
+	Title string `json:"title"`
+	// TrialEpisodes e copiado da regra na hora: editar a regra depois nao muda trial em curso.
+	TrialEpisodes int       `json:"trial_episodes"`
+	AddedAt       time.Time `json:"added_at"`
+	State         string    `json:"state"`
+	// EndedAt e quando o trial saiu de TrialActive.
+	EndedAt *time.Time `json:"ended_at,omitempty"`
+}
+
+// Valores de Trial.State.
+const (
+	// TrialActive: avulso limitado aos primeiros TrialEpisodes.
+	TrialActive = "trial"
+	// TrialKept: o usuario ficou com o anime; o limite sai e ele segue como avulso comum.
+	TrialKept = "kept"
+	// TrialExpired: trial_keep_days passou sem o usuario ficar com ele, e o passe o removeu.
+	TrialExpired = "expired"
+	// TrialLeft: saiu de standalone_animes antes do prazo — removido a mao, ou entrou numa lista
+	// da AniList. Nos dois casos o trial deixou de ser dele.
+	TrialLeft = "left"
+)
+
+// LoadTrials devolve os trials por media id. Arquivo ausente e mapa vazio, nao erro.
+func (m *FileManager) LoadTrials() (map[int]Trial, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loadTrialsLocked()
+}
+
+// UpdateTrials le, aplica fn e grava sob o mesmo lock: o passe e o "ficar com este anime" da
+// tela mexem no mesmo arquivo, e um load/save separado perderia a escrita do outro. fn devolve
+// false quando nao mudou nada, e ai o arquivo nao e regravado. fn nao pode chamar o FileManager.
+func (m *FileManager) UpdateTrials(fn func(trials map[int]Trial) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	trials, err := m.loadTrialsLocked()
+	if err != nil {
+		return err
+	}
+	if !fn(trials) {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(trials, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trials: %w", err)
+	}
+	if err := m.writeAtomic(m.trialsPath, b); err != nil {
+		return fmt.Errorf("failed to write trials file: %w", err)
+	}
+	return nil
+}
+
+func (m *FileManager) loadTrialsLocked() (map[int]Trial, error) {
+	_, err := m.fs.Stat(m.trialsPath)
+	if os.IsNotExist(err) {
+		return map[int]Trial{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat trials file: %w", err)
+	}
+
+	b, err := m.fs.ReadFile(m.trialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trials file: %w", err)
+	}
+
+	trials := map[int]Trial{}
+	if err := json.Unmarshal(b, &trials); err != nil {
+		return nil, fmt.Errorf("failed to parse trials file: %w", err)
+	}
+	return trials, nil
+}
@@ -0,0 +1,73 @@
+package files
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateAutoSubscribeRules(t *testing.T) {
+	valid := AutoSubscribeRule{Name: "acao", Formats: []string{"TV"}, MinScore: 70, Country: "JP", TrialEpisodes: 3}
+	if err := ValidateAutoSubscribeRules([]AutoSubscribeRule{valid}); err != nil {
+		t.Fatalf("regra valida recusada: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		edit func(r *AutoSubscribeRule)
+	}{
+		{"sem nome", func(r *AutoSubscribeRule) { r.Name = "" }},
+		{"formato desconhecido", func(r *AutoSubscribeRule) { r.Formats = []string{"tv"} }},
+		{"temporada desconhecida", func(r *AutoSubscribeRule) { r.Season = "AUTUMN" }},
+		{"nota acima de 100", func(r *AutoSubscribeRule) { r.MinScore = 101 }},
+		{"pais com tres letras", func(r *AutoSubscribeRule) { r.Country = "JPN" }},
+		{"trial sem episodio", func(r *AutoSubscribeRule) { r.TrialEpisodes = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.edit(&r)
+			if err := ValidateAutoSubscribeRules([]AutoSubscribeRule{r}); err == nil {
+				t.Errorf("quero erro para %+v", r)
+			}
+		})
+	}
+
+	t.Run("nome repetido", func(t *testing.T) {
+		if err := ValidateAutoSubscribeRules([]AutoSubscribeRule{valid, valid}); err == nil {
+			t.Error("quero erro para dois nomes iguais")
+		}
+	})
+}
+
+func TestTrials(t *testing.T) {
+	m := newTestManager(t)
+
+	trials, err := m.LoadTrials()
+	if err != nil || len(trials) != 0 {
+		t.Fatalf("arquivo ausente: quero mapa vazio, veio %v, %v", trials, err)
+	}
+
+	added := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	if err := m.UpdateTrials(func(saved map[int]Trial) bool {
+		saved[21] = Trial{Rule: "acao", TrialEpisodes: 3, AddedAt: added, State: TrialActive}
+		return true
+	}); err != nil {
+		t.Fatalf("UpdateTrials: %v", err)
+	}
+	// false nao grava: a mudanca feita dentro de fn se perde.
+	if err := m.UpdateTrials(func(saved map[int]Trial) bool {
+		delete(saved, 21)
+		return false
+	}); err != nil {
+		t.Fatalf("UpdateTrials sem mudanca: %v", err)
+	}
+
+	trials, err = m.LoadTrials()
+	if err != nil {
+		t.Fatalf("LoadTrials: %v", err)
+	}
+	got, ok := trials[21]
+	if !ok || got.Rule != "acao" || got.State != TrialActive || !got.AddedAt.Equal(added) {
+		t.Fatalf("trial salvo errado: %+v", trials)
+	}
+}
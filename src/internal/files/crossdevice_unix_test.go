@@ -6,6 +6,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 )
@@ -55,12 +56,79 @@ func TestProbePathFailsWhenLinkUnsupported(t *testing.T) {
 	completed := filepath.Join(tmp, "completed")
 
 	lib := &organizer{fs: NewOSFileSystem(), link: exdevLink}
-	if err := lib.ProbePath(completed); err == nil {
+	err := lib.ProbePath(completed, LinkHardlink)
+	if err == nil {
 		t.Fatalf("expected error from ProbePath when the link func fails")
 	}
+	// The error points at the modes that do work there.
+	if !strings.Contains(err.Error(), "modes that work here:") || !strings.Contains(err.Error(), "symlink, copy)") {
+		t.Errorf("error should list the working modes: %v", err)
+	}
+	// The chosen mode is what gets probed.
+	if err := lib.ProbePath(completed, LinkCopy); err != nil {
+		t.Errorf("ProbePath(copy) should pass without hardlinks: %v", err)
+	}
 	// Probe source cleaned up despite the failure.
 	downloadDir := filepath.Join(completed, downloadDirName)
 	if _, statErr := os.Stat(filepath.Join(downloadDir, ".aad_link_probe")); statErr == nil {
 		t.Errorf("probe source not cleaned up after failure")
 	}
 }
+
+// exdevRenameFS is a filesystem whose Rename always crosses volumes, like a relink moving a
+// movie into a library_movies_path on another disk.
+type exdevRenameFS struct {
+	*OSFileSystem
+}
+
+func (exdevRenameFS) Rename(oldpath, newpath string) error {
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+}
+
+func TestMoveInLibraryAcrossVolumes(t *testing.T) {
+	tmp := t.TempDir()
+	seeding := filepath.Join(tmp, "save", "movie.mkv")
+	writeFile(t, seeding, "movie")
+	lib := &organizer{fs: exdevRenameFS{NewOSFileSystem()}, link: os.Link}
+
+	t.Run("copy", func(t *testing.T) {
+		from := filepath.Join(tmp, "anime", "Movie", "Movie.mkv")
+		to := filepath.Join(tmp, "movies", "Movie (2020)", "Movie (2020).mkv")
+		writeFile(t, from, "movie")
+		writeFile(t, filepath.Join(tmp, "anime", "Movie", "Movie.en.ass"), "subs")
+
+		if err := lib.MoveInLibrary(from, to); err != nil {
+			t.Fatalf("MoveInLibrary: %v", err)
+		}
+		if data, err := os.ReadFile(to); err != nil || string(data) != "movie" {
+			t.Fatalf("destination = %q, %v; want the file copied", data, err)
+		}
+		if _, err := os.Lstat(from); !os.IsNotExist(err) {
+			t.Errorf("source still there after the move: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(tmp, "movies", "Movie (2020)", "Movie (2020).en.ass")); err != nil {
+			t.Errorf("subtitle did not follow across volumes: %v", err)
+		}
+	})
+
+	t.Run("symlink stays a symlink", func(t *testing.T) {
+		from := filepath.Join(tmp, "anime", "Linked", "Linked.mkv")
+		to := filepath.Join(tmp, "movies", "Linked", "Linked.mkv")
+		if err := os.MkdirAll(filepath.Dir(from), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(seeding, from); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := lib.MoveInLibrary(from, to); err != nil {
+			t.Fatalf("MoveInLibrary: %v", err)
+		}
+		if target, err := os.Readlink(to); err != nil || target != seeding {
+			t.Errorf("destination link = %q, %v; want a symlink to %s", target, err, seeding)
+		}
+		if _, err := os.Lstat(from); !os.IsNotExist(err) {
+			t.Errorf("source link still there after the move: %v", err)
+		}
+	})
+}
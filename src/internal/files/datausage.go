@@ -0,0 +1,110 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Ledger de trafego do cliente de torrent, por dia e por anime. Fica em arquivo proprio porque
+// e historico, nao estado de torrent: um episodio apagado continua tendo custado os bytes que
+// custou, e o teto mensal (Config.DataCapGB) precisa deles ate a virada do ciclo.
+
+// DataUsageDateLayout e o formato das chaves de DataUsageLedger.Days: a data LOCAL, porque e
+// no relogio do usuario que o dia e o ciclo da operadora viram.
+const DataUsageDateLayout = "2006-01-02"
+
+// ByteCounts e um par download/upload, em bytes.
+type ByteCounts struct {
+	Downloaded int64 `json:"downloaded"`
+	Uploaded   int64 `json:"uploaded"`
+}
+
+// Total e o que conta para o teto: download e upload somados.
+func (b ByteCounts) Total() int64 {
+	return b.Downloaded + b.Uploaded
+}
+
+// Add soma outro par a este.
+func (b *ByteCounts) Add(o ByteCounts) {
+	b.Downloaded += o.Downloaded
+	b.Uploaded += o.Uploaded
+}
+
+// DayUsage e o trafego de um dia: o total e a parte de cada anime. O total pode passar da soma
+// dos animes — torrent adicionado a mao nao tem anime.
+type DayUsage struct {
+	ByteCounts
+	Animes map[int]ByteCounts `json:"animes,omitempty"`
+}
+
+// DataUsageLedger e o arquivo data_usage inteiro.
+type DataUsageLedger struct {
+	// Days por data local (DataUsageDateLayout).
+	Days map[string]DayUsage `json:"days"`
+	// Counters e o ultimo contador acumulado (TorrentInfo.BytesDownloaded/BytesUploaded) lido
+	// de cada torrent; a proxima amostra atribui so a diferenca.
+	Counters map[string]ByteCounts `json:"counters"`
+	// SampledAt e a hora da ultima amostra. Zero = nunca amostrado: a primeira amostra so
+	// anota os contadores, senao o trafego de toda a vida dos torrents cairia no dia de hoje.
+	SampledAt time.Time `json:"sampled_at"`
+	// AnimeNames guarda o nome de cada anime que ja apareceu no ledger, para o relatorio
+	// continuar legivel depois que os episodios dele sairem de downloaded_episodes.
+	AnimeNames map[int]string `json:"anime_names,omitempty"`
+}
+
+// LoadDataUsage devolve o ledger salvo. Arquivo ausente e ledger vazio (SampledAt zero), nao
+// erro.
+func (m *FileManager) LoadDataUsage() (*DataUsageLedger, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ledger := &DataUsageLedger{
+		Days:       map[string]DayUsage{},
+		Counters:   map[string]ByteCounts{},
+		AnimeNames: map[int]string{},
+	}
+
+	_, err := m.fs.Stat(m.dataUsagePath)
+	if os.IsNotExist(err) {
+		return ledger, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat data usage file: %w", err)
+	}
+
+	b, err := m.fs.ReadFile(m.dataUsagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data usage file: %w", err)
+	}
+	if err := json.Unmarshal(b, ledger); err != nil {
+		return nil, fmt.Errorf("failed to parse data usage file: %w", err)
+	}
+	// Um campo null no arquivo zera o mapa do default; o chamador escreve neles sem checar.
+	if ledger.Days == nil {
+		ledger.Days = map[string]DayUsage{}
+	}
+	if ledger.Counters == nil {
+		ledger.Counters = map[string]ByteCounts{}
+	}
+	if ledger.AnimeNames == nil {
+		ledger.AnimeNames = map[int]string{}
+	}
+	return ledger, nil
+}
+
+// SaveDataUsage substitui o ledger salvo. A poda dos dias antigos e dos contadores de torrents
+// que sairam da sessao e do chamador (daemon.sampleDataUsage).
+func (m *FileManager) SaveDataUsage(ledger *DataUsageLedger) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal data usage: %w", err)
+	}
+	if err := m.writeAtomic(m.dataUsagePath, b); err != nil {
+		return fmt.Errorf("failed to write data usage file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package files
+
+import (
+	"AutoAnimeDownloader/src/internal/nyaa"
+
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Extras de um pack: abertura e encerramento sem creditos, PVs, menus e especiais. Soltos na
+// pasta do anime o Jellyfin os le como episodios; nas pastas que ele conhece viram extras
+// (extras/, trailers/) ou a temporada 0 (Specials/).
+
+// extraKind e onde um arquivo do pack vai na biblioteca.
+type extraKind int
+
+const (
+	extraNone    extraKind = iota // episodio
+	extraExtra                    // extras/: NCOP/NCED, menus, bonus
+	extraTrailer                  // trailers/: PV, CM, trailer, teaser, preview
+	extraSpecial                  // Specials/: especial ou OVA numerado (temporada 0)
+)
+
+const (
+	extrasDirName   = "extras"
+	trailersDirName = "trailers"
+	specialsDirName = "Specials"
+)
+
+var (
+	reLeadingGroup = regexp.MustCompile(`^\s*(?:\[[^\]]*\]|\([^)]*\))\s*`)
+	// Codigos curtos: valem mesmo com um numero de episodio legivel no nome ("NCED 2 (1080p)"
+	// le como episodio 2). OP/ED e PV/CM so em maiusculas: "Ed" e "Op" aparecem em titulos.
+	reExtraSpecial    = regexp.MustCompile(`(?i)\bS00E(\d{1,3})\b|\b(?:OVA|OAD|SP|Specials?)[\s._-]*(\d{1,3})(?:v\d)?(?:\b|_)`)
+	reExtraCreditless = regexp.MustCompile(`(?i)\bNC[\s_-]?(?:OP|ED)|\bcreditless\b|\bclean[\s_-]*(?:opening|ending)\b|\bnon[\s_-]?credit|\bmenu[\s_-]*\d+`)
+	reExtraSongCode   = regexp.MustCompile(`\b(?:OP|ED)(?:[\s_]?\d{1,2}[a-z]?)?(?:\b|_)`)
+	reExtraTrailCode  = regexp.MustCompile(`\b(?:PV|CM)(?:[\s_]?\d{1,2})?(?:\b|_)`)
+	// Palavras: so num arquivo sem numero de episodio, porque tambem aparecem em titulos de
+	// episodio ("Anime - 05 - The Secret Menu").
+	reExtraTrailWord = regexp.MustCompile(`(?i)\b(?:trailers?|teasers?|previews?|promo)\b`)
+	reExtraWord      = regexp.MustCompile(`(?i)\b(?:menu|bonus|extras?|opening|ending|omake|interview|making|ova|oad|specials?)\b`)
+)
+
+// extraDirKinds sao os nomes de pasta que os packs (e a propria biblioteca) usam para extras.
+var extraDirKinds = map[string]extraKind{
+	"extras": extraExtra, "extra": extraExtra, "bonus": extraExtra, "nc": extraExtra,
+	"ncop": extraExtra, "nced": extraExtra, "ncoped": extraExtra, "creditless": extraExtra,
+	"menu": extraExtra, "menus": extraExtra, "featurettes": extraExtra,
+	"trailers": extraTrailer, "trailer": extraTrailer, "pv": extraTrailer, "pvs": extraTrailer,
+	"cm": extraTrailer, "cms": extraTrailer, "previews": extraTrailer,
+	"specials": extraSpecial, "special": extraSpecial, "sp": extraSpecial, "sps": extraSpecial,
+	"ova": extraSpecial, "ovas": extraSpecial, "oad": extraSpecial, "oads": extraSpecial,
+	"season 00": extraSpecial,
+}
+
+// extraClass e a classificacao de um arquivo; number e o do especial.
+type extraClass struct {
+	kind   extraKind
+	number int
+}
+
+// classifyExtra classifica um arquivo do pack pelo caminho relativo a raiz do torrent (ou pela
+// pasta de cima e o nome, no relink): primeiro os codigos do nome, depois as pastas de dentro
+// para fora, e por ultimo as palavras, so quando nao ha numero de episodio. Especial sem numero
+// vai para extras/: a temporada 0 precisa do numero.
+func classifyExtra(rel string) extraClass {
+	base := filepath.Base(rel)
+	// O grupo sai antes dos codigos: "[OP-Subs]" nao e uma abertura.
+	stem := reLeadingGroup.ReplaceAllString(strings.TrimSuffix(base, filepath.Ext(base)), "")
+	if m := reExtraSpecial.FindStringSubmatch(stem); m != nil {
+		if n, err := strconv.Atoi(m[1] + m[2]); err == nil && n > 0 {
+			return extraClass{kind: extraSpecial, number: n}
+		}
+	}
+	if reExtraCreditless.MatchString(stem) || reExtraSongCode.MatchString(stem) {
+		return extraClass{kind: extraExtra}
+	}
+	if reExtraTrailCode.MatchString(stem) {
+		return extraClass{kind: extraTrailer}
+	}
+
+	episode := nyaa.ExtractEpisodeNumber(base)
+	dirs := strings.Split(filepath.Dir(rel), string(filepath.Separator))
+	for i := len(dirs) - 1; i >= 0; i-- {
+		kind, ok := extraDirKinds[strings.ToLower(dirs[i])]
+		if !ok {
+			continue
+		}
+		if kind == extraSpecial {
+			if episode == nil {
+				return extraClass{kind: extraExtra}
+			}
+			return extraClass{kind: extraSpecial, number: *episode}
+		}
+		return extraClass{kind: kind}
+	}
+
+	if episode == nil {
+		if reExtraTrailWord.MatchString(stem) {
+			return extraClass{kind: extraTrailer}
+		}
+		if reExtraWord.MatchString(stem) {
+			return extraClass{kind: extraExtra}
+		}
+	}
+	return extraClass{}
+}
+
+// classifyPack classifica os arquivos de um pack. Num pack sem nenhum episodio comum os
+// especiais sao a propria entrada (a AniList tem OVAs e especiais como entradas): eles ficam na
+// pasta do anime como episodios.
+func classifyPack(rels []string) []extraClass {
+	out := make([]extraClass, len(rels))
+	hasEpisode := false
+	for i, rel := range rels {
+		out[i] = classifyExtra(rel)
+		if out[i].kind == extraNone {
+			hasEpisode = true
+		}
+	}
+	if !hasEpisode {
+		for i := range out {
+			if out[i].kind == extraSpecial {
+				out[i] = extraClass{}
+			}
+		}
+	}
+	return out
+}
+
+// classifyMovie classifica os arquivos de um filme. Um filme nao tem temporada 0: o especial
+// vira extra. Um arquivo so e sempre o filme, e um pack em que tudo parece extra tambem — e o
+// filme com um nome que engana as palavras.
+func classifyMovie(rels []string) []extraClass {
+	out := make([]extraClass, len(rels))
+	if len(rels) < 2 {
+		return out
+	}
+	hasMain := false
+	for i, rel := range rels {
+		out[i] = movieExtraClass(classifyExtra(rel))
+		if out[i].kind == extraNone {
+			hasMain = true
+		}
+	}
+	if !hasMain {
+		return make([]extraClass, len(rels))
+	}
+	return out
+}
+
+// movieExtraClass leva a classificacao de um arquivo para o layout de filme: o especial vai
+// para extras/, com o nome cru.
+func movieExtraClass(class extraClass) extraClass {
+	if class.kind == extraSpecial {
+		return extraClass{kind: extraExtra}
+	}
+	return class
+}
+
+// extraDir e a pasta do extra: extras/ e trailers/ junto dos episodios, Specials/ na pasta da
+// serie, onde o Jellyfin procura a temporada 0.
+func (l libraryLayout) extraDir(kind extraKind) string {
+	switch kind {
+	case extraTrailer:
+		return filepath.Join(l.dir, trailersDirName)
+	case extraSpecial:
+		return filepath.Join(l.showDir, specialsDirName)
+	}
+	return filepath.Join(l.dir, extrasDirName)
+}
+
+// specialFileName e o nome do especial com RenameJellyfin: "Anime - S00E02.mkv". Fixo, e nao
+// pelo FileTemplate: um {season} zerado some do nome renderizado. "" sem numero.
+func (l libraryLayout) specialFileName(number int, ext string) string {
+	if number <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s - S00E%02d%s", sanitizeName(l.values.title), number, ext)
+}
+
+// isLibraryExtraDir diz se dir e uma das pastas de extras que o Organize cria.
+func isLibraryExtraDir(dir string) bool {
+	switch filepath.Base(dir) {
+	case extrasDirName, trailersDirName, specialsDirName:
+		return true
+	}
+	return false
+}
+
+// libraryExtraClass classifica um arquivo ja na biblioteca pela pasta em que o Organize o pos;
+// fora delas e episodio. O numero do especial sai do nome ("Anime - S00E02", ou o cru).
+func libraryExtraClass(path string) extraClass {
+	switch filepath.Base(filepath.Dir(path)) {
+	case extrasDirName:
+		return extraClass{kind: extraExtra}
+	case trailersDirName:
+		return extraClass{kind: extraTrailer}
+	case specialsDirName:
+		class := classifyExtra(filepath.Join(specialsDirName, filepath.Base(path)))
+		if class.kind != extraSpecial {
+			class = extraClass{}
+		}
+		return extraClass{kind: extraSpecial, number: class.number}
+	}
+	return extraClass{}
+}
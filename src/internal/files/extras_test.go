@@ -0,0 +1,112 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Nomes de packs reais (e variacoes deles): o caminho e relativo a raiz do torrent.
+func TestClassifyExtra(t *testing.T) {
+	cases := []struct {
+		rel  string
+		want extraClass
+	}{
+		{"[SubsPlease] Spy x Family - 05 (1080p) [ED12AB34].mkv", extraClass{}},
+		{"[OP-Subs] Anime - 05 [1080p].mkv", extraClass{}},
+		{"[Sub] Special A - 01 [1080p].mkv", extraClass{}},
+		{"[Sub] Anime - 05 - The Secret Menu [1080p].mkv", extraClass{}},
+		{"[VCB-Studio] Anime [01][Ma10p_1080p][x265_flac].mkv", extraClass{}},
+		{"[Judas] Kimetsu no Yaiba - NCOP01.mkv", extraClass{kind: extraExtra}},
+		{"[Sub] Anime - NCED 2 (1080p).mkv", extraClass{kind: extraExtra}},
+		{"[VCB-Studio] Anime [NCOP01_1][Ma10p_1080p][x265_flac].mkv", extraClass{kind: extraExtra}},
+		{"[VCB-Studio] Kimi no Na wa [Menu01_1][Ma10p_1080p][x265_flac].mkv", extraClass{kind: extraExtra}},
+		{"[Sub] Anime - OP2 [1080p].mkv", extraClass{kind: extraExtra}},
+		{"[Sub] Anime - Creditless Ending.mkv", extraClass{kind: extraExtra}},
+		{"[Sub] Anime - Opening.mkv", extraClass{kind: extraExtra}},
+		{filepath.Join("Bonus", "Making of.mkv"), extraClass{kind: extraExtra}},
+		{filepath.Join("Specials", "Recap.mkv"), extraClass{kind: extraExtra}},
+		{"[VCB-Studio] Anime [CM01][Ma10p_1080p][x265_flac].mkv", extraClass{kind: extraTrailer}},
+		{"[VCB-Studio] Anime [PV02][Ma10p_1080p][x265_flac].mkv", extraClass{kind: extraTrailer}},
+		{"[Sub] Anime - Trailer.mkv", extraClass{kind: extraTrailer}},
+		{filepath.Join("PV", "Anime Teaser 1.mkv"), extraClass{kind: extraTrailer}},
+		{"[VCB-Studio] Anime [SP01][Ma10p_1080p][x265_flac].mkv", extraClass{kind: extraSpecial, number: 1}},
+		{"[Sub] Anime OVA - 02 [1080p].mkv", extraClass{kind: extraSpecial, number: 2}},
+		{"[Sub] Anime - S00E03 [1080p].mkv", extraClass{kind: extraSpecial, number: 3}},
+		{filepath.Join("Specials", "[Sub] Anime - 04 [1080p].mkv"), extraClass{kind: extraSpecial, number: 4}},
+	}
+	for _, c := range cases {
+		if got := classifyExtra(c.rel); got != c.want {
+			t.Errorf("classifyExtra(%q) = %+v, want %+v", c.rel, got, c.want)
+		}
+	}
+}
+
+// Um pack so de OVAs e a propria entrada de OVA: os arquivos ficam como episodios.
+func TestClassifyPackOnlySpecials(t *testing.T) {
+	classes := classifyPack([]string{"[Sub] Anime OVA - 01.mkv", "[Sub] Anime OVA - 02.mkv", "NCOP.mkv"})
+	if classes[0].kind != extraNone || classes[1].kind != extraNone || classes[2].kind != extraExtra {
+		t.Errorf("classes = %+v, want two episodes and one extra", classes)
+	}
+}
+
+func TestOrganizeBatchExtras(t *testing.T) {
+	tmp := t.TempDir()
+	dataDir := filepath.Join(tmp, "save", "batchid")
+	completed := filepath.Join(tmp, "completed")
+	writeFile(t, filepath.Join(dataDir, "[Sub] Anime - 01 [1080p].mkv"), "a")
+	writeFile(t, filepath.Join(dataDir, "Extras", "[Sub] Anime - NCOP1 [1080p].mkv"), "b")
+	writeFile(t, filepath.Join(dataDir, "[Sub] Anime - PV1 [1080p].mkv"), "c")
+	writeFile(t, filepath.Join(dataDir, "[Sub] Anime - SP2 [1080p].mkv"), "d")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	req := OrganizeRequest{
+		TorrentDataDir: dataDir,
+		AnimeName:      "Anime",
+		CompletedPath:  completed,
+		IsBatch:        true,
+		RenameJellyfin: true,
+	}
+	created, err := lib.Organize(req)
+	if err != nil {
+		t.Fatalf("Organize: %v", err)
+	}
+	want := []string{
+		filepath.Join(completed, "Anime", "Anime - E01.mkv"),
+		filepath.Join(completed, "Anime", "extras", "[Sub] Anime - NCOP1 [1080p].mkv"),
+		filepath.Join(completed, "Anime", "trailers", "[Sub] Anime - PV1 [1080p].mkv"),
+		filepath.Join(completed, "Anime", "Specials", "Anime - S00E02.mkv"),
+	}
+	if len(created) != len(want) {
+		t.Fatalf("created = %v, want %v", created, want)
+	}
+	for _, p := range want {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("expected %s: %v", p, err)
+		}
+	}
+
+	// O relink com o layout de sempre nao mexe nos extras; um template novo os leva junto.
+	eps := []EpisodeStruct{{AnimeID: 1, AnimeName: "Anime", EpisodeNumber: 1, EpisodeHash: "h", IsBatch: true, LibraryPaths: created}}
+	for _, mv := range PlanLibraryMoves(eps, completed, LibraryNaming{Rename: true}) {
+		if mv.From != mv.To {
+			t.Errorf("default naming moves %s -> %s", mv.From, mv.To)
+		}
+	}
+	moves := PlanLibraryMoves(eps, completed, LibraryNaming{Rename: true, FolderTemplate: "{title} [{anilist_id}]"})
+	for _, mv := range moves {
+		if mv.From == mv.To || filepath.Base(mv.From) != filepath.Base(mv.To) {
+			t.Errorf("move %s -> %s should change only the folder", mv.From, mv.To)
+		}
+		if isLibraryExtraDir(filepath.Dir(mv.From)) && (filepath.Base(filepath.Dir(mv.To)) != filepath.Base(filepath.Dir(mv.From)) || mv.EpisodeNumber != 0) {
+			t.Errorf("extra %s planned as %s, episode %d", mv.From, mv.To, mv.EpisodeNumber)
+		}
+	}
+
+	if err := lib.RemoveFromLibrary(want[2]); err != nil {
+		t.Fatalf("RemoveFromLibrary: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(want[2])); !os.IsNotExist(err) {
+		t.Errorf("empty trailers folder should be removed, stat err = %v", err)
+	}
+}
@@ -19,6 +19,19 @@ const downloadedEpsFileName = "downloaded_episodes"
 const blockedEpsFileName = "blocked_episodes"
 const animeSettingsFileName = "anime_settings"
 const standaloneAnimesFileName = "standalone_animes"
+const trackersListFileName = "trackers_list"
+const integrityChecksFileName = "integrity_checks"
+const dataUsageFileName = "data_usage"
+const artworkSourcesFileName = "artwork_sources"
+const anilistTokensFileName = "anilist_tokens"
+const anilistCacheFileName = "anilist_cache"
+const trialsFileName = "trials"
+const listSnapshotFileName = "list_snapshot"
+const listChangesFileName = "list_changes"
+
+// idMappingFileName tem o nome do proprio dataset: quem baixa o arquivo a mao so o solta na
+// pasta do config.
+const idMappingFileName = "anime-offline-database.json"
 
 // EpisodeKey identifica um episodio. E (anime, numero do episodio) e nao o id do no de
 // airingSchedule da AniList, porque aquele id nao existe para todo episodio: a AniList guarda uma
@@ -49,6 +62,54 @@ type EpisodeStruct struct {
 	// JobOrganize. Empty means "not yet organized" — the marker JobOrganize uses to fire
 	// the completion webhook and write-back exactly once (idempotent across restarts).
 	LibraryPaths []string `json:"library_paths,omitempty"`
+	// Libraries sao as bibliotecas em que o episodio entra: os LibrarySubpath dos perfis que
+	// querem o anime, "" para a raiz. Vazio = so a raiz, como antes dos perfis.
+	Libraries []string `json:"libraries,omitempty"`
+	// TorrentName e o nome do torrent no momento do organize — de onde saem o {group} e o
+	// {resolution} dos templates depois que o arquivo da biblioteca ja foi renomeado.
+	TorrentName string `json:"torrent_name,omitempty"`
+	// Meta guarda o que os templates de nome precisam da AniList, para o preview e o relink
+	// rodarem sem consultar a AniList. nil em registro anterior ao campo ate o passe preencher.
+	Meta *AnimeMeta `json:"anime_meta,omitempty"`
+}
+
+// AnimeMeta is the AniList data the library naming templates read, denormalized onto each
+// episode record like AnimeName.
+type AnimeMeta struct {
+	TitleRomaji  string `json:"title_romaji,omitempty"`
+	TitleEnglish string `json:"title_english,omitempty"`
+	// Season vem do marcador no titulo ("Season 2", "2nd Season"); 0 = sem marcador.
+	Season int `json:"season,omitempty"`
+	Year   int `json:"year,omitempty"`
+	// EpisodeOffset e somado ao numero do episodio no {absolute}: o total do prequel, para a
+	// parte 2 de um anime dividido em cours (daemon.ComputeEpisodeOffset).
+	EpisodeOffset int `json:"episode_offset,omitempty"`
+	// Show e a serie da entrada, para LibrarySeasonFolders. nil = ainda nao resolvida, ou
+	// entrada que nao e temporada (filme, OVA): essas ficam numa pasta propria.
+	Show *ShowMeta `json:"show,omitempty"`
+	// Format e o MediaFormat da AniList ("TV", "MOVIE", ...); "" = registro anterior ao campo,
+	// que o relink resolve quando LibraryMovieLayout precisa dele.
+	Format string `json:"format,omitempty"`
+}
+
+// IsMovie diz se a entrada e um filme na AniList.
+func (m *AnimeMeta) IsMovie() bool {
+	return m != nil && m.Format == "MOVIE"
+}
+
+// ShowMeta places an AniList entry inside its series: the first season of the PREQUEL chain
+// names the root folder, and Season/EpisodeOffset say where the entry's episodes land in it.
+type ShowMeta struct {
+	// ID e Title sao da primeira temporada: viram o <uniqueid> e o titulo do tvshow.nfo.
+	ID           int    `json:"id"`
+	Title        string `json:"title"`
+	TitleRomaji  string `json:"title_romaji,omitempty"`
+	TitleEnglish string `json:"title_english,omitempty"`
+	Year         int    `json:"year,omitempty"`
+	Season       int    `json:"season"`
+	// EpisodeOffset e somado ao episodio da entrada para numerar dentro da temporada: o total
+	// das partes anteriores quando um cour dividido ("Part 2") fica na mesma Season.
+	EpisodeOffset int `json:"episode_offset,omitempty"`
 }
 
 type WebhookPreset struct {
@@ -69,6 +130,22 @@ type NotificationsConfig struct {
 	BatchWindowSeconds int `json:"batch_window_seconds"`
 }
 
+// MediaServer e um Jellyfin, Emby ou Plex que recebe o pedido de scan da biblioteca depois do
+// organize, do relink e das remocoes (pacote mediaserver, decisions.md #80).
+type MediaServer struct {
+	Name string `json:"name"`
+	// Type e "jellyfin", "emby" ou "plex".
+	Type string `json:"type"`
+	URL  string `json:"url"`
+	// Token e a API key do Jellyfin/Emby ou o X-Plex-Token.
+	Token string `json:"token"`
+	// LibraryPath e MoviesPath sao CompletedAnimePath e LibraryMoviesPath como o SERVIDOR os
+	// enxerga, quando ele roda em outro container ou maquina, como TorrentClientSavePath. "" =
+	// mesmo caminho.
+	LibraryPath string `json:"library_path"`
+	MoviesPath  string `json:"movies_path"`
+}
+
 type Config struct {
 	// SavePath e um campo LEGADO, lido apenas por daemon.MigrateSavePath. O diretorio de
 	// download deixou de ser configuravel e passou a ser derivado (ver DownloadPath). O
@@ -77,7 +154,25 @@ type Config struct {
 	CompletedAnimePath string   `json:"completed_anime_path"`
 	AnilistUsername    string   `json:"anilist_username,omitempty"`
 	AnilistUsernames   []string `json:"anilist_usernames"`
-	CheckInterval      int      `json:"check_interval"`
+	// AnilistClientID e AnilistClientSecret sao o cliente de API que o usuario registra na
+	// AniList (Settings > Developer) para o login que permite escrever na lista. Sem segredo o
+	// login e o implicito: a AniList mostra o token e o usuario o cola (decisions.md #82).
+	AnilistClientID     string `json:"anilist_client_id"`
+	AnilistClientSecret string `json:"anilist_client_secret"`
+	// AnilistPlaybackSync faz o webhook de reproducao (#81) avancar tambem o progresso do anime
+	// de lista, na AniList, em cada conta com login. Desligado, so o avulso avanca.
+	AnilistPlaybackSync bool `json:"anilist_playback_sync"`
+	// AnilistCompleteOnLastEpisode move a entrada para COMPLETED quando o episodio marcado como
+	// assistido e o ultimo do anime.
+	AnilistCompleteOnLastEpisode bool `json:"anilist_complete_on_last_episode"`
+	// MALUsernames e KitsuUsernames sao contas de fora da AniList cujas listas entram no passe
+	// junto com as de AnilistUsernames, com os ids mapeados para a AniList (pacote lists).
+	MALUsernames   []string `json:"mal_usernames"`
+	KitsuUsernames []string `json:"kitsu_usernames"`
+	// MALClientID e o cliente da API v2 do MyAnimeList. Vazio, a lista e lida pelo endpoint
+	// publico da pagina de lista, que nao tem contrato e pode mudar sem aviso.
+	MALClientID   string `json:"mal_client_id"`
+	CheckInterval int    `json:"check_interval"`
 	// MaxEpisodesPerAnime limita quantos episodios de um anime existem ao mesmo tempo, e vale
 	// APENAS no caminho episodio-a-episodio: um batch e um torrent so, entao limitar registros
 	// nao limitaria bytes nem arquivos na biblioteca (ver decisions.md). 0 significa SEM TETO
@@ -106,15 +201,96 @@ type Config struct {
 	//
 	// Nao precisa de migracao: LoadConfigs desserializa POR CIMA de getDefaultConfig(),
 	// entao um config.json anterior a este campo ja carrega valendo o default.
-	MaxConcurrentDownloads int      `json:"max_concurrent_downloads"`
+	MaxConcurrentDownloads int `json:"max_concurrent_downloads"`
+	// QueuePolicy e a ordem em que a fila comeca os torrents que esperam (torrents.QueuePolicy):
+	// "fifo", "smallest_first", "airing_first" ou "fair_share". Priorizar a mao vence qualquer
+	// uma. "" (config.json editado a mao) vale fifo.
+	QueuePolicy string `json:"queue_policy"`
+	// ExtraTrackers sao URLs de announce acrescentadas a TODO magnet antes do Add. Existem
+	// porque magnet antigo do Nyaa costuma listar so trackers mortos ha anos — boa parte do
+	// IssueNoSeeders de anime antigo e isso, e nao falta de gente semeando.
+	ExtraTrackers []string `json:"extra_trackers"`
+	// TrackersListURL aponta para uma lista publica de trackers (uma URL por linha), baixada
+	// para o arquivo trackers_list no maximo uma vez por dia e somada a ExtraTrackers. "" desliga.
+	TrackersListURL string `json:"trackers_list_url"`
+	// IDMappingURL aponta para o JSON do anime-offline-database, baixado para a pasta do config
+	// no maximo uma vez por semana (ver daemon.refreshIDMapping). "" nao baixa, mas um arquivo
+	// posto la a mao continua valendo.
+	IDMappingURL string `json:"id_mapping_url"`
+	// FollowSequels faz o passe seguir a SEQUEL de todo anime acompanhado que terminou: ela entra
+	// como avulso assim que estiver no ar (ver daemon.followSequels). AnimeSettings.FollowSequels
+	// vence por anime. Opt-in: seguir e baixar uma temporada inteira que ninguem pediu.
+	FollowSequels bool `json:"follow_sequels"`
+	// SequelLeadDays antecipa o seguir: uma sequencia anunciada com estreia a ate N dias ja
+	// entra, para a primeira busca nao esperar o proximo passe depois da estreia. So vale com a
+	// data completa na AniList. 0 = so quando ela estiver no ar.
+	SequelLeadDays int `json:"sequel_lead_days"`
+	// AutoSubscribeRules adicionam como avulso em trial os animes da temporada que casam com
+	// elas (ver daemon.autoSubscribe).
+	AutoSubscribeRules []AutoSubscribeRule `json:"auto_subscribe_rules"`
+	// TrialKeepDays e o prazo de um trial: passado ele sem o usuario ficar com o anime, o passe
+	// tira o avulso e apaga os episodios. 0 = trial nunca expira.
+	TrialKeepDays int `json:"trial_keep_days"`
+	// AccountProfiles dao a uma conta da casa a sua biblioteca e os seus status e limites
+	// (AccountProfile). Conta sem perfil segue os campos globais, na raiz da biblioteca.
+	AccountProfiles []AccountProfile `json:"account_profiles"`
+	// IntegrityCheckDays e de quantos em quantos dias cada torrent completo tem os dados
+	// re-verificados contra os hashes das pecas (ver daemon.integritySweep). Pega bit rot e
+	// arquivo mexido por fora antes que o episodio chegue ao player corrompido. 0 desliga: a
+	// verificacao le o torrent inteiro, e nem todo disco aguenta isso de graca.
+	IntegrityCheckDays int `json:"integrity_check_days"`
+	// DataCapGB e o teto mensal de trafego, download e upload somados — e o que a operadora de
+	// uma conexao medida cobra. Atingido, nenhum download novo e adicionado e tudo, seeding
+	// inclusive, fica parado ate o dia de virada (ver daemon.ApplyDataCap). 0 desliga; o
+	// ledger de uso (data_usage) e gravado mesmo assim.
+	DataCapGB float64 `json:"data_cap_gb"`
+	// DataCapBillingDay e o dia do mes em que o ciclo da operadora vira, de 1 a 28 — 28 e o
+	// maior dia que todo mes tem. Fora disso (config.json editado a mao) vale 1.
+	DataCapBillingDay int `json:"data_cap_billing_day"`
+	// TorrentClient escolhe quem baixa: "embedded" (a rain, dentro do processo),
+	// "qbittorrent" ou "transmission" (torrents.RemoteBackend, por HTTP). So vale no boot — o
+	// backend e criado uma vez em cmd/daemon e injetado em todo lugar. "" vale embedded.
+	TorrentClient string `json:"torrent_client"`
+	// TorrentClientURL e a raiz da WebUI do qBittorrent (http://host:8080) ou o endpoint RPC
+	// do Transmission (http://host:9091/transmission/rpc).
+	TorrentClientURL      string `json:"torrent_client_url"`
+	TorrentClientUsername string `json:"torrent_client_username"`
+	TorrentClientPassword string `json:"torrent_client_password"`
+	// TorrentClientCategory e a categoria (qBittorrent) ou label (Transmission) dos torrents
+	// da daemon. E tambem a fronteira do que ela gerencia: torrent do cliente fora dela nunca e
+	// pausado pela fila nem removido pela limpeza.
+	TorrentClientCategory string `json:"torrent_client_category"`
+	// TorrentClientSavePath e a pasta de download como o CLIENTE a enxerga, quando ele roda em
+	// outro container ou maquina com a pasta montada em outro caminho. Os caminhos que ele
+	// reporta sob ela sao traduzidos para DownloadPath() antes do hardlink. "" = mesmo caminho.
+	TorrentClientSavePath  string   `json:"torrent_client_save_path"`
 	DeleteWatchedEpisodes  bool     `json:"delete_watched_episodes"`
 	WatchedEpisodesToKeep  int      `json:"watched_episodes_to_keep"`
 	ExcludedList           string   `json:"excluded_list,omitempty"`
 	ExcludedLists          []string `json:"excluded_lists"`
 	RenameFilesForJellyfin bool     `json:"rename_files_for_jellyfin"`
-	DownloadStatuses       []string `json:"download_statuses"`
-	DownloadMediaStatuses  []string `json:"download_media_statuses"`
-	DeleteStatuses         []string `json:"delete_statuses"`
+	// LibraryFolderTemplate e LibraryFileTemplate dao nome a pasta de cada anime e aos arquivos
+	// da biblioteca (tokens em naming.go). O de arquivo so vale com RenameFilesForJellyfin; sem
+	// ele o nome cru do torrent fica. "" = o default, que reproduz o nome de antes dos templates.
+	LibraryFolderTemplate string `json:"library_folder_template"`
+	LibraryFileTemplate   string `json:"library_file_template"`
+	// LibrarySeasonFolders junta as temporadas de uma serie (cadeia de PREQUEL da AniList) numa
+	// pasta so, com subpastas Season NN. Opt-in: o default segue uma pasta por entrada da
+	// AniList (decisions.md #45 e #72).
+	LibrarySeasonFolders bool `json:"library_season_folders"`
+	// LibraryMovieLayout poe os filmes da AniList (formato MOVIE) no layout de filme do
+	// Jellyfin: "Titulo (Ano)/Titulo (Ano).mkv" com movie.nfo, em LibraryMoviesPath ("" = dentro
+	// de CompletedAnimePath). Opt-in; ligar ou desligar migra os filmes ja organizados pelo
+	// relink (decisions.md #79).
+	LibraryMovieLayout bool   `json:"library_movie_layout"`
+	LibraryMoviesPath  string `json:"library_movies_path"`
+	// LibraryLinkMode e como o episodio completo entra na biblioteca: "hardlink" (default),
+	// "reflink", "symlink" ou "copy" (LinkMode). Os tres ultimos existem para filesystems sem
+	// hardlink (exFAT, alguns shares SMB/NFS). "" vale hardlink.
+	LibraryLinkMode       string   `json:"library_link_mode"`
+	DownloadStatuses      []string `json:"download_statuses"`
+	DownloadMediaStatuses []string `json:"download_media_statuses"`
+	DeleteStatuses        []string `json:"delete_statuses"`
 	// AnimeIDsAreMediaIDs marca que daemon.MigrateAnimeIDsToMedia ja converteu os AnimeID
 	// gravados de id de ENTRADA (MediaList, por conta) para id de MIDIA (ver decisions.md #43).
 	// O default e false de proposito: um config.json anterior a este campo desserializa por
@@ -122,6 +298,7 @@ type Config struct {
 	// e liga o campo no primeiro passe.
 	AnimeIDsAreMediaIDs bool                `json:"anime_ids_are_media_ids"`
 	Notifications       NotificationsConfig `json:"notifications"`
+	MediaServers        []MediaServer       `json:"media_servers"`
 	Priorities          nyaa.Priorities     `json:"priorities"`
 }
 
@@ -152,8 +329,23 @@ type AnimeSettings struct {
 	// de packs sucessivos nao tem o que o mova. Ausente le 0, que e o comportamento de antes.
 	// Anime de lista nunca usa este campo: quem manda la e a AniList.
 	Progress int `json:"progress,omitempty"`
+	// QueueWeight e o peso do anime na politica de fila fair_share: peso 2 recebe dois slots
+	// de download para cada um de um anime de peso 1. Ausente (0) vale 1.
+	QueueWeight int `json:"queue_weight,omitempty"`
+	// FollowSequels sobrepoe Config.FollowSequels para este anime: FollowSequelsAlways ou
+	// FollowSequelsNever. "" segue o global.
+	FollowSequels string `json:"follow_sequels,omitempty"`
+	// FollowedSequels sao as sequencias que o passe ja seguiu a partir deste anime. Cada uma e
+	// seguida uma vez so: tirada do acompanhamento pelo usuario, ela nao volta no passe seguinte.
+	FollowedSequels []int `json:"followed_sequels,omitempty"`
 }
 
+// Valores de AnimeSettings.FollowSequels.
+const (
+	FollowSequelsAlways = "always"
+	FollowSequelsNever  = "never"
+)
+
 type FileManager struct {
 	fs                   FileSystem
 	configPath           string
@@ -161,7 +353,20 @@ type FileManager struct {
 	blockedEpisodesPath  string
 	animeSettingsPath    string
 	standaloneAnimesPath string
-	mu                   sync.Mutex
+	// trackersListPath, integrityChecksPath, dataUsagePath, artworkSourcesPath,
+	// anilistTokensPath, idMappingPath, anilistCachePath, trialsPath, listSnapshotPath e listChangesPath nao sao parametros de NewManager: sao derivados da
+	// pasta do config.json, como o resto do estado que vive ao lado dele.
+	trackersListPath    string
+	integrityChecksPath string
+	dataUsagePath       string
+	artworkSourcesPath  string
+	anilistTokensPath   string
+	idMappingPath       string
+	anilistCachePath    string
+	trialsPath          string
+	listSnapshotPath    string
+	listChangesPath     string
+	mu                  sync.Mutex
 }
 
 // applyNyaaSettings empurra para o pacote nyaa os campos de config que ele consome. Chamado em
@@ -185,6 +390,8 @@ func getDefaultConfig() *Config {
 		SavePath:               "",
 		CompletedAnimePath:     completedPath,
 		AnilistUsernames:       []string{},
+		MALUsernames:           []string{},
+		KitsuUsernames:         []string{},
 		CheckInterval:          10,
 		MaxEpisodesPerAnime:    12,
 		MaxBatchTorrentSizeGB:  100,
@@ -193,6 +400,14 @@ func getDefaultConfig() *Config {
 		MinFreeDiskPercent:     10,
 		EpisodeRetryLimit:      5,
 		MaxConcurrentDownloads: 3,
+		QueuePolicy:            "fifo",
+		ExtraTrackers:          []string{},
+		DataCapBillingDay:      1,
+		TorrentClient:          "embedded",
+		TorrentClientCategory:  "autoanimedownloader",
+		LibraryFolderTemplate:  DefaultFolderTemplate,
+		LibraryFileTemplate:    DefaultFileTemplate,
+		LibraryLinkMode:        string(LinkHardlink),
 		DeleteWatchedEpisodes:  true,
 		WatchedEpisodesToKeep:  0,
 		ExcludedLists:          []string{},
@@ -200,6 +415,10 @@ func getDefaultConfig() *Config {
 		DownloadMediaStatuses:  []string{"RELEASING", "FINISHED"},
 		DeleteStatuses:         []string{},
 		Notifications:          NotificationsConfig{Webhooks: []WebhookPreset{}, BatchWindowSeconds: 60},
+		MediaServers:           []MediaServer{},
+		AutoSubscribeRules:     []AutoSubscribeRule{},
+		AccountProfiles:        []AccountProfile{},
+		TrialKeepDays:          14,
 		Priorities:             nyaa.DefaultPriorities(),
 	}
 }
@@ -239,6 +458,16 @@ func NewManager(fs FileSystem, configPath, episodesPath, blockedEpisodesPath, an
 		blockedEpisodesPath:  blockedEpisodesPath,
 		animeSettingsPath:    animeSettingsPath,
 		standaloneAnimesPath: standaloneAnimesPath,
+		trackersListPath:     filepath.Join(filepath.Dir(configPath), trackersListFileName),
+		integrityChecksPath:  filepath.Join(filepath.Dir(configPath), integrityChecksFileName),
+		dataUsagePath:        filepath.Join(filepath.Dir(configPath), dataUsageFileName),
+		artworkSourcesPath:   filepath.Join(filepath.Dir(configPath), artworkSourcesFileName),
+		anilistTokensPath:    filepath.Join(filepath.Dir(configPath), anilistTokensFileName),
+		idMappingPath:        filepath.Join(filepath.Dir(configPath), idMappingFileName),
+		anilistCachePath:     filepath.Join(filepath.Dir(configPath), anilistCacheFileName),
+		trialsPath:           filepath.Join(filepath.Dir(configPath), trialsFileName),
+		listSnapshotPath:     filepath.Join(filepath.Dir(configPath), listSnapshotFileName),
+		listChangesPath:      filepath.Join(filepath.Dir(configPath), listChangesFileName),
 	}
 }
 
@@ -341,6 +570,28 @@ func (m *FileManager) LoadConfigs() (*Config, error) {
 	if config.AnilistUsernames == nil {
 		config.AnilistUsernames = []string{}
 	}
+	if config.MALUsernames == nil {
+		config.MALUsernames = []string{}
+	}
+	if config.KitsuUsernames == nil {
+		config.KitsuUsernames = []string{}
+	}
+
+	if config.ExtraTrackers == nil {
+		config.ExtraTrackers = []string{}
+	}
+
+	if config.MediaServers == nil {
+		config.MediaServers = []MediaServer{}
+	}
+
+	if config.AutoSubscribeRules == nil {
+		config.AutoSubscribeRules = []AutoSubscribeRule{}
+	}
+
+	if config.AccountProfiles == nil {
+		config.AccountProfiles = []AccountProfile{}
+	}
 
 	applyNyaaSettings(config)
 	return config, nil
@@ -357,8 +608,14 @@ func (m *FileManager) SaveConfigs(config *Config) error {
 // aqui e reescrito por inteiro a cada alteracao, entao um WriteFile direto deixa uma
 // janela em que o arquivo esta pela metade — foi assim que o arquivo de episodios corrompeu.
 func (m *FileManager) writeAtomic(path string, data []byte) error {
+	return m.writeAtomicMode(path, data, 0644)
+}
+
+// writeAtomicMode e o writeAtomic com a permissao do arquivo, para o que nao deve ser legivel
+// por outros usuarios da maquina.
+func (m *FileManager) writeAtomicMode(path string, data []byte, perm os.FileMode) error {
 	tmpPath := path + ".tmp"
-	if err := m.fs.WriteFile(tmpPath, data, 0644); err != nil {
+	if err := m.fs.WriteFile(tmpPath, data, perm); err != nil {
 		return fmt.Errorf("failed to write temp file %s: %w", tmpPath, err)
 	}
 
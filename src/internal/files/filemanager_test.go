@@ -231,3 +231,81 @@ func TestEpisodesFileConcurrency(t *testing.T) {
 		}
 	})
 }
+
+// A lista de trackers ausente e o estado antes da primeira busca: lista vazia e hora zero,
+// para o daemon buscar na hora — nunca erro.
+func TestTrackersListRoundTrip(t *testing.T) {
+	m := newTestManager(t)
+
+	trackers, source, savedAt, err := m.LoadTrackersList()
+	if err != nil {
+		t.Fatalf("LoadTrackersList sem arquivo: %v", err)
+	}
+	if len(trackers) != 0 || source != "" || !savedAt.IsZero() {
+		t.Errorf("sem arquivo: lista %v, origem %q, hora %v; quero vazia e hora zero", trackers, source, savedAt)
+	}
+
+	const url = "https://lists.example/best.txt"
+	want := []string{"udp://a.tracker:1337/announce", "https://b.tracker/announce"}
+	if err := m.SaveTrackersList(url, want); err != nil {
+		t.Fatalf("SaveTrackersList: %v", err)
+	}
+	trackers, source, savedAt, err = m.LoadTrackersList()
+	if err != nil {
+		t.Fatalf("LoadTrackersList: %v", err)
+	}
+	if fmt.Sprint(trackers) != fmt.Sprint(want) {
+		t.Errorf("lista = %v, quero %v", trackers, want)
+	}
+	if source != url {
+		t.Errorf("origem = %q, quero %q", source, url)
+	}
+	if time.Since(savedAt) > time.Minute {
+		t.Errorf("hora da lista = %v, quero a da gravacao", savedAt)
+	}
+}
+
+func TestIntegrityChecksRoundTrip(t *testing.T) {
+	m := newTestManager(t)
+
+	checks, err := m.LoadIntegrityChecks()
+	if err != nil {
+		t.Fatalf("LoadIntegrityChecks sem arquivo: %v", err)
+	}
+	if len(checks) != 0 {
+		t.Errorf("sem arquivo: %v; quero mapa vazio", checks)
+	}
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := m.SaveIntegrityChecks(map[string]time.Time{"abc": at}); err != nil {
+		t.Fatalf("SaveIntegrityChecks: %v", err)
+	}
+	checks, err = m.LoadIntegrityChecks()
+	if err != nil {
+		t.Fatalf("LoadIntegrityChecks: %v", err)
+	}
+	if len(checks) != 1 || !checks["abc"].Equal(at) {
+		t.Errorf("mapa = %v, quero abc -> %v", checks, at)
+	}
+}
+
+func TestArtworkSourcesRoundTrip(t *testing.T) {
+	m := newTestManager(t)
+
+	sources, err := m.LoadArtworkSources()
+	if err != nil || len(sources) != 0 {
+		t.Fatalf("sem arquivo: %v, %v; quero mapa vazio", sources, err)
+	}
+
+	want := ArtworkSource{Poster: "https://img/p.jpg", Fanart: "https://img/f.jpg"}
+	if err := m.SaveArtworkSources(map[int]ArtworkSource{21: want}); err != nil {
+		t.Fatalf("SaveArtworkSources: %v", err)
+	}
+	sources, err = m.LoadArtworkSources()
+	if err != nil {
+		t.Fatalf("LoadArtworkSources: %v", err)
+	}
+	if len(sources) != 1 || sources[21] != want {
+		t.Errorf("mapa = %v, quero 21 -> %+v", sources, want)
+	}
+}
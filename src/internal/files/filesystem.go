@@ -15,6 +15,13 @@ type FileSystem interface {
 	Remove(filename string) error
 	Rename(oldpath, newpath string) error
 	Link(oldname, newname string) error
+	Symlink(oldname, newname string) error
+	Readlink(name string) (string, error)
+	// Reflink e CopyFile gravam newname inteiro ou nada, preservando o mtime de oldname
+	// (LinkMode).
+	Reflink(oldname, newname string) error
+	CopyFile(oldname, newname string) error
+	Lstat(filename string) (fs.FileInfo, error)
 	Mkdir(dirname string, perm fs.FileMode) error
 	MkdirAll(dirname string, perm fs.FileMode) error
 }
@@ -61,6 +68,26 @@ func (osfs *OSFileSystem) Link(oldname, newname string) error {
 	return os.Link(oldname, newname)
 }
 
+func (osfs *OSFileSystem) Symlink(oldname, newname string) error {
+	return os.Symlink(oldname, newname)
+}
+
+func (osfs *OSFileSystem) Readlink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
+func (osfs *OSFileSystem) Reflink(oldname, newname string) error {
+	return cloneFile(oldname, newname, reflinkContents)
+}
+
+func (osfs *OSFileSystem) CopyFile(oldname, newname string) error {
+	return cloneFile(oldname, newname, copyContents)
+}
+
+func (osfs *OSFileSystem) Lstat(filename string) (fs.FileInfo, error) {
+	return os.Lstat(filename)
+}
+
 func (osfs *OSFileSystem) Mkdir(dirname string, perm fs.FileMode) error {
 	return os.Mkdir(dirname, perm)
 }
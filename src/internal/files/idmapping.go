@@ -0,0 +1,55 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Dataset de mapeamento de ids (anime-offline-database), baixado de Config.IDMappingURL ou posto
+// a mao na pasta do config. O arquivo e guardado como veio: o indice em memoria e do pacote
+// idmap, e o disco so serve para o daemon reiniciar sem baixar dezenas de MB de novo.
+
+// IDMappingSavedAt devolve quando o dataset foi gravado, sem ler o arquivo (sao dezenas de MB).
+// Arquivo ausente e hora zero, nao erro.
+func (m *FileManager) IDMappingSavedAt() (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info, err := m.fs.Stat(m.idMappingPath)
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat id mapping file: %w", err)
+	}
+	return info.ModTime(), nil
+}
+
+// LoadIDMapping devolve o dataset salvo e quando foi gravado. Arquivo ausente e nil com hora zero.
+func (m *FileManager) LoadIDMapping() ([]byte, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info, err := m.fs.Stat(m.idMappingPath)
+	if os.IsNotExist(err) {
+		return nil, time.Time{}, nil
+	} else if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to stat id mapping file: %w", err)
+	}
+	data, err := m.fs.ReadFile(m.idMappingPath)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read id mapping file: %w", err)
+	}
+	return data, info.ModTime(), nil
+}
+
+// SaveIDMapping substitui o dataset salvo.
+func (m *FileManager) SaveIDMapping(data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.writeAtomic(m.idMappingPath, data); err != nil {
+		return fmt.Errorf("failed to write id mapping file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Hora da ultima verificacao de integridade de cada torrent, por info hash. Fica em arquivo
+// proprio, e nao em downloaded_episodes, porque a unidade verificada e o torrent: um batch
+// tem um hash so para dezenas de episodios, e um torrent adicionado a mao nao tem episodio
+// nenhum. Sem este arquivo, todo restart do daemon reverificaria a biblioteca inteira.
+
+// LoadIntegrityChecks devolve o mapa hash -> hora da ultima verificacao. Arquivo ausente e
+// mapa vazio, nao erro: nenhum torrent foi verificado ainda.
+func (m *FileManager) LoadIntegrityChecks() (map[string]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.fs.Stat(m.integrityChecksPath)
+	if os.IsNotExist(err) {
+		return map[string]time.Time{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat integrity checks file: %w", err)
+	}
+
+	b, err := m.fs.ReadFile(m.integrityChecksPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read integrity checks file: %w", err)
+	}
+
+	checks := map[string]time.Time{}
+	if err := json.Unmarshal(b, &checks); err != nil {
+		return nil, fmt.Errorf("failed to parse integrity checks file: %w", err)
+	}
+	return checks, nil
+}
+
+// SaveIntegrityChecks substitui o mapa salvo. O chamador grava o mapa inteiro, ja sem os
+// hashes que sairam da sessao — senao o arquivo so cresceria.
+func (m *FileManager) SaveIntegrityChecks(checks map[string]time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if checks == nil {
+		checks = map[string]time.Time{}
+	}
+	b, err := json.MarshalIndent(checks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal integrity checks: %w", err)
+	}
+	if err := m.writeAtomic(m.integrityChecksPath, b); err != nil {
+		return fmt.Errorf("failed to write integrity checks file: %w", err)
+	}
+	return nil
+}
@@ -4,42 +4,87 @@ import (
 	"AutoAnimeDownloader/src/internal/logger"
 	"AutoAnimeDownloader/src/internal/nyaa"
 
-	"encoding/xml"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
+	"slices"
 	"strings"
 )
 
 // Librarian organizes completed torrent files into the Jellyfin library by creating
-// hardlinks. The original files stay in place (kept seeding); the library holds a second
-// name pointing at the same bytes, so no space is duplicated.
+// hardlinks (or, with another LinkMode, reflinks, symlinks or copies). The original files stay
+// in place (kept seeding); with a hardlink the library holds a second name pointing at the
+// same bytes, so no space is duplicated.
 type Librarian interface {
-	// Organize creates hardlinks for the completed video files of a torrent in the
-	// library. With RenameJellyfin it uses the Jellyfin name ("Anime - E05.mkv") — from
-	// the record for a single episode, from each file's own name for a batch; a file
+	// Organize links the completed video files of a torrent into the library with
+	// req.LinkMode, in the folder FolderTemplate names (under Season NN of the series' folder
+	// with SeasonFolders and a resolved Meta.Show). With RenameJellyfin it names the files
+	// with FileTemplate ("Anime - E05.mkv" by default) — the number from the record for a
+	// single episode, from each file's own name for a batch; a file
 	// whose number can't be read (and everything without the flag) keeps the raw name.
+	// A movie with MovieLayout goes to its own "Title (Year)" folder instead, named after it.
 	// It returns the absolute paths of the library links it created (or that already
 	// existed) so the caller can record them for later removal. It is idempotent: a
-	// destination that is already the same file (same inode) is reported and skipped.
-	// A destination holding a *different* file is replaced by the new hardlink, which
-	// is what the redownload/replace flows want.
+	// destination that is already the same file (same inode, or for a copy/reflink the same
+	// size and mtime) is reported and skipped.
+	// A destination holding a *different* file is replaced by the new link, which
+	// is what the redownload/replace flows want. It writes only the minimal tvshow.nfo that
+	// is missing; the AniList-backed .nfo files come from WriteNFOs.
 	Organize(req OrganizeRequest) ([]string, error)
-	// RemoveFromLibrary deletes a single library hardlink. A missing file is not an error.
+	// RemoveFromLibrary deletes a single library file and its episode .nfo. A symlink is
+	// removed itself, never its target (the seeding copy). A missing file is not an error.
 	RemoveFromLibrary(path string) error
+	// MoveInLibrary renomeia um arquivo da biblioteca (relink apos trocar os templates de
+	// nome). Idempotente: origem ausente com destino presente e um move ja feito. Leva o
+	// tvshow.nfo e a arte junto quando a pasta muda e apaga a pasta antiga que ficar vazia. O
+	// nfo do episodio gerado por nos e apagado (o job de metadados o regera com a numeracao
+	// nova); o do usuario vai junto com o video.
+	MoveInLibrary(from, to string) error
+	// EnsureShowNFO escreve o tvshow.nfo de dir se ele nao existe. O relink chama na pasta
+	// raiz de cada serie que mudou: com Season NN o nfo da pasta antiga nao vai junto, porque
+	// e o da entrada, nao o da serie.
+	EnsureShowNFO(dir, animeName string, animeID int)
+	// MissingNFOs diz se falta algum .nfo aos arquivos ja no lugar (From == To) de um anime,
+	// ou se o tvshow.nfo e o minimo de versoes anteriores: o backfill so consulta a AniList
+	// para os animes em que ha o que escrever.
+	MissingNFOs(moves []LibraryMove) bool
+	// WriteNFOs escreve o tvshow.nfo e o nfo de cada episodio ja no lugar, regerando os que
+	// sao nossos (nfoMarker) e deixando os do usuario.
+	WriteNFOs(moves []LibraryMove, info *NFOInfo)
+	// HasArtwork diz se a imagem existe; SaveArtwork a grava de uma vez (temporario + rename),
+	// substituindo a anterior. Quem decide se uma imagem existente e do usuario e o daemon,
+	// pelas URLs registradas (ArtworkSource).
+	HasArtwork(path string) bool
+	SaveArtwork(path string, data []byte) error
 	// ProbePath valida, no save da config e a cada passe de verificacao, que a biblioteca
-	// suporta hardlinks. O cheque de volume cruzado deixou de ser necessario (o diretorio
-	// de download e derivado da biblioteca, entao estao sempre no mesmo filesystem), mas
-	// existem filesystems sem hardlink nenhum: exFAT, FAT32, alguns mounts SMB/NFS. Usa a
-	// mesma funcao de link que Organize usa, entao nunca discorda dele. Tambem cria o
-	// diretorio de download e o marcador .ignore.
-	ProbePath(completedPath string) error
+	// suporta o LinkMode escolhido. O cheque de volume cruzado deixou de ser necessario (o
+	// diretorio de download e derivado da biblioteca, entao estao sempre no mesmo
+	// filesystem), mas existem filesystems sem hardlink nenhum: exFAT, FAT32, alguns mounts
+	// SMB/NFS. Usa a mesma funcao de link que Organize usa, entao nunca discorda dele. Na
+	// falha, o erro lista os modos que funcionam ali. Tambem cria o diretorio de download e o
+	// marcador .ignore.
+	ProbePath(completedPath string, mode LinkMode) error
+	// ProbeMoviesPath faz a mesma sonda de ProbePath entre o diretorio de download e a pasta
+	// de filmes (LibraryMoviesPath), que pode estar em outro volume. Cria a pasta.
+	ProbeMoviesPath(completedPath, moviesPath string, mode LinkMode) error
+	// ProbeLinkModes devolve os modos que funcionam na biblioteca, na ordem de LinkModes, com
+	// a mesma sonda e os mesmos efeitos de ProbePath.
+	ProbeLinkModes(completedPath string) ([]LinkMode, error)
+	// AuditLibrary confronta os registros, os torrents e o que esta em disco e devolve as
+	// divergencias por categoria (AuditCategory), sem mexer em nada. Quem corrige e o daemon
+	// (RepairLibrary); so o que e de disco passa por RemoveOrphan.
+	AuditLibrary(req AuditRequest) ([]AuditFinding, error)
+	// RemoveOrphan apaga um arquivo ou pasta de um achado orphan_file ou duplicate, com o nfo
+	// do episodio e a pasta do anime que ficar so com o nfo e a arte. Recusa o que estiver
+	// fora de completedPath, a propria biblioteca e o diretorio de download. Ausente nao e erro.
+	RemoveOrphan(completedPath, path string) error
 }
 
 // OrganizeRequest describes one torrent to organize into the library.
 type OrganizeRequest struct {
-	// TorrentDataDir is the on-disk root of the torrent's content (<DataDir>/<id>).
+	// TorrentDataDir is the on-disk root of the torrent's content: <DataDir>/<id> for the
+	// embedded client, and the content path an external client reports — which, for a
+	// single-file torrent, is the file itself.
 	TorrentDataDir string
 	AnimeName      string
 	// AnimeID e o id de MIDIA da AniList; vira o <uniqueid> do tvshow.nfo para o Jellyfin
@@ -52,8 +97,25 @@ type OrganizeRequest struct {
 	// IsBatch marks multi-episode/movie torrents: the episode number comes from each
 	// file's own name instead of EpisodeNumber.
 	IsBatch bool
-	// RenameJellyfin enables the "Anime - E05.ext" naming.
+	// RenameJellyfin enables renaming the files with FileTemplate ("Anime - E05.ext" by
+	// default).
 	RenameJellyfin bool
+	// FolderTemplate e FileTemplate sao os templates de nome da biblioteca (naming.go);
+	// vazio = default, que reproduz os nomes de antes dos templates.
+	FolderTemplate string
+	FileTemplate   string
+	// SeasonFolders poe o anime em <serie>/Season NN, segundo Meta.Show.
+	SeasonFolders bool
+	// MovieLayout poe um filme (Meta.Format MOVIE) em "Titulo (Ano)/Titulo (Ano).ext" com
+	// movie.nfo, sob MoviesPath ("" = CompletedPath).
+	MovieLayout bool
+	MoviesPath  string
+	// TorrentName e Meta alimentam os tokens: {group}/{resolution} caem no nome do torrent
+	// quando o arquivo nao os tem; titulos, season, ano e offset vem do Meta.
+	TorrentName string
+	Meta        *AnimeMeta
+	// LinkMode e como os arquivos entram na biblioteca; "" = hardlink.
+	LinkMode LinkMode
 }
 
 type organizer struct {
@@ -61,8 +123,9 @@ type organizer struct {
 	link func(oldname, newname string) error
 }
 
-// NewLibrarian returns a Librarian backed by the given FileSystem. The link function
-// defaults to fs.Link; both Organize and ProbePath use it, so they never diverge.
+// NewLibrarian returns a Librarian backed by the given FileSystem. The hardlink function
+// defaults to fs.Link; the other modes use the FileSystem directly (linkFunc). Organize and
+// ProbePath both go through linkFunc, so they never diverge.
 func NewLibrarian(fs FileSystem) *organizer {
 	return &organizer{fs: fs, link: fs.Link}
 }
@@ -107,7 +170,7 @@ func (o *organizer) Organize(req OrganizeRequest) ([]string, error) {
 		return nil, fmt.Errorf("completed anime path is not configured")
 	}
 
-	videoFiles, err := o.collectVideoFiles(req.TorrentDataDir)
+	srcRoot, videoFiles, err := o.torrentVideoFiles(req.TorrentDataDir)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +178,21 @@ func (o *organizer) Organize(req OrganizeRequest) ([]string, error) {
 		return nil, fmt.Errorf("no video files found in %s", req.TorrentDataDir)
 	}
 
-	destDir := filepath.Join(req.CompletedPath, sanitizeName(req.AnimeName))
+	naming := LibraryNaming{
+		FolderTemplate: req.FolderTemplate,
+		FileTemplate:   req.FileTemplate,
+		Rename:         req.RenameJellyfin,
+		SeasonFolders:  req.SeasonFolders,
+		MovieLayout:    req.MovieLayout,
+		MoviesPath:     req.MoviesPath,
+	}.withDefaults()
+	layout := naming.layout(req.CompletedPath, req.AnimeName, req.AnimeID, req.Meta)
+	destDir := layout.dir
+	mode := req.LinkMode
+	if mode == "" {
+		mode = LinkHardlink
+	}
+	link := o.linkFunc(mode)
 
 	// Track whether we created destDir, so we can clean it up on a cross-device failure
 	// without leaving an orphan folder in the library.
@@ -123,6 +200,10 @@ func (o *organizer) Organize(req OrganizeRequest) ([]string, error) {
 	if _, statErr := o.fs.Stat(destDir); statErr != nil {
 		dirExisted = false
 	}
+	showDirExisted := true
+	if _, statErr := o.fs.Stat(layout.showDir); statErr != nil {
+		showDirExisted = false
+	}
 	if err := o.fs.MkdirAll(destDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create library folder %s: %w", destDir, err)
 	}
@@ -133,109 +214,112 @@ func (o *organizer) Organize(req OrganizeRequest) ([]string, error) {
 	singleJellyfin := !req.IsBatch && req.RenameJellyfin && req.EpisodeNumber != nil &&
 		*req.EpisodeNumber > 0 && len(videoFiles) == 1
 
+	// Extras so existem num pack: o episodio avulso e o que foi pedido. Num filme os videos que
+	// nao sao extra sao as partes dele.
+	classes := make([]extraClass, len(videoFiles))
+	switch {
+	case layout.movie:
+		classes = classifyMovie(videoFiles)
+	case req.IsBatch:
+		classes = classifyPack(videoFiles)
+	}
+	parts, part := 0, 0
+	for _, class := range classes {
+		if class.kind == extraNone {
+			parts++
+		}
+	}
+
 	used := make(map[string]bool, len(videoFiles))
 	var created []string
-	for _, rel := range videoFiles {
-		src := filepath.Join(req.TorrentDataDir, rel)
+	// placed liga cada video do torrent (relativo a srcRoot) ao arquivo dele na biblioteca,
+	// para as legendas irem para o lado com o mesmo nome.
+	placed := make(map[string]string, len(videoFiles))
+	for i, rel := range videoFiles {
+		src := filepath.Join(srcRoot, rel)
 
 		destName := filepath.Base(rel)
-		switch {
+		ext := filepath.Ext(rel)
+		dir := destDir
+		switch class := classes[i]; {
+		case class.kind != extraNone:
+			// Extra e trailer ficam com o nome cru, que o Jellyfin mostra como titulo.
+			dir = layout.extraDir(class.kind)
+			if class.kind == extraSpecial && req.RenameJellyfin {
+				if name := layout.specialFileName(class.number, ext); name != "" && !used[filepath.Join(dir, name)] {
+					destName = name
+				}
+			}
+			if err := o.fs.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("failed to create library folder %s: %w", dir, err)
+			}
+		case layout.movie:
+			part++
+			if req.RenameJellyfin {
+				if name := layout.movieFileName(part, parts, ext); !used[filepath.Join(dir, name)] {
+					destName = name
+				}
+			}
 		case singleJellyfin:
-			destName = jellyfinName(req.AnimeName, *req.EpisodeNumber, filepath.Ext(rel))
+			if jf := naming.fileName(layout.values.forFile(layout.episode(*req.EpisodeNumber), ext, destName, req.TorrentName)); jf != "" {
+				destName = jf
+			}
 		case req.IsBatch && req.RenameJellyfin:
 			// Pack: o numero sai do proprio nome do arquivo, para os episodios do pack se
 			// misturarem na pasta com os avulsos em vez de manter o nome cru do fansub.
 			// Sem numero legivel (NCOP/NCED, extra, filme) ou com colisao entre dois
 			// arquivos do mesmo pack, fica o nome cru — que e unico dentro do torrent.
 			if n := nyaa.ExtractEpisodeNumber(destName); n != nil {
-				if jf := jellyfinName(req.AnimeName, *n, filepath.Ext(rel)); !used[jf] {
+				if jf := naming.fileName(layout.values.forFile(layout.episode(layout.entryEpisode(*n)), ext, destName, req.TorrentName)); jf != "" && !used[filepath.Join(destDir, jf)] {
 					destName = jf
 				}
 			}
 		}
-		used[destName] = true
-		dest := filepath.Join(destDir, destName)
+		dest := filepath.Join(dir, destName)
+		used[dest] = true
 
-		if destInfo, statErr := o.fs.Stat(dest); statErr == nil {
-			srcInfo, srcErr := o.fs.Stat(src)
-			if srcErr != nil {
-				return nil, fmt.Errorf("failed to stat source %s: %w", src, srcErr)
-			}
-			if os.SameFile(srcInfo, destInfo) {
-				// Idempotent: this exact file is already linked (reconciliation/retry).
-				created = append(created, dest)
-				continue
-			}
-			// Different bytes under the same name (redownload/replace): the user asked
-			// for the swap, so the new file wins.
-			logger.Logger.Info().
-				Str("source", src).
-				Str("destination", dest).
-				Msg("Replacing existing library file with the newly downloaded one")
-			if err := o.fs.Remove(dest); err != nil {
-				o.cleanupIfEmpty(destDir, dirExisted)
-				return nil, fmt.Errorf("failed to replace existing library file %s: %w", dest, err)
-			}
+		already, err := o.prepareDest(mode, src, dest)
+		if err != nil {
+			o.cleanupIfEmpty(destDir, dirExisted)
+			o.cleanupIfEmpty(layout.showDir, showDirExisted)
+			return nil, err
+		}
+		if already {
+			// Idempotent: this exact file is already linked (reconciliation/retry).
+			created = append(created, dest)
+			placed[rel] = dest
+			continue
 		}
 
-		if err := o.link(src, dest); err != nil {
+		if err := link(src, dest); err != nil {
 			o.cleanupIfEmpty(destDir, dirExisted)
+			o.cleanupIfEmpty(layout.showDir, showDirExisted)
 			if isCrossDevice(err) {
-				return nil, fmt.Errorf("cannot hardlink %s -> %s: save path and completed path must be on the same volume: %w", src, dest, err)
+				return nil, fmt.Errorf("cannot %s %s -> %s: save path and completed path must be on the same volume: %w", mode, src, dest, err)
 			}
-			return nil, fmt.Errorf("failed to hardlink %s -> %s: %w", src, dest, err)
+			return nil, fmt.Errorf("failed to %s %s -> %s: %w", mode, src, dest, err)
 		}
 		created = append(created, dest)
+		placed[rel] = dest
 	}
 
-	// Depois dos links: se falhar antes, cleanupIfEmpty nao conseguiria remover a pasta.
-	o.writeShowNFO(destDir, req.AnimeName, req.AnimeID)
-
-	return created, nil
-}
-
-type nfoUniqueID struct {
-	Type    string `xml:"type,attr"`
-	Default bool   `xml:"default,attr"`
-	Value   string `xml:",chardata"`
-}
-
-type nfoTVShow struct {
-	XMLName  xml.Name    `xml:"tvshow"`
-	Title    string      `xml:"title"`
-	UniqueID nfoUniqueID `xml:"uniqueid"`
-}
-
-// writeShowNFO escreve o tvshow.nfo com o id da AniList para o Jellyfin (plugin AniList)
-// casar pelo id. Nao sobrescreve um nfo existente — o usuario pode ter ajustado o match a
-// mao. Falha aqui nao invalida os hardlinks, entao so loga. Retorna true se escreveu.
-func (o *organizer) writeShowNFO(destDir, animeName string, animeID int) bool {
-	if animeID <= 0 {
-		return false
-	}
-	path := filepath.Join(destDir, "tvshow.nfo")
-	if _, err := o.fs.Stat(path); err == nil {
-		return false
-	}
-
-	data, err := xml.MarshalIndent(nfoTVShow{
-		Title:    animeName,
-		// "AniList" com essa capitalizacao e o valor de ProviderNames.AniList no
-		// jellyfin-plugin-anilist. O ProviderIds do Jellyfin e OrdinalIgnoreCase, entao
-		// minusculo tambem casaria — escrevemos igual ao provider para nao depender disso.
-		UniqueID: nfoUniqueID{Type: "AniList", Default: true, Value: strconv.Itoa(animeID)},
-	}, "", "  ")
-	if err != nil {
-		logger.Logger.Warn().Err(err).Str("path", path).Msg("Failed to build tvshow.nfo")
-		return false
+	// Legendas e fontes so saem de uma pasta de torrent: o content path de um torrent de um
+	// arquivo so (srcRoot e a pasta de cima) e a pasta de download inteira do cliente externo.
+	if srcRoot == req.TorrentDataDir {
+		o.linkSidecars(srcRoot, placed, link, mode)
 	}
-	data = append([]byte(xml.Header), append(data, '\n')...)
 
-	if err := o.fs.WriteFile(path, data, 0644); err != nil {
-		logger.Logger.Warn().Err(err).Str("path", path).Msg("Failed to write tvshow.nfo")
-		return false
+	// Depois dos links: se falhar antes, cleanupIfEmpty nao conseguiria remover a pasta.
+	// Com Season NN o nfo e o da serie, na pasta raiz; num filme e o movie.nfo.
+	// O resto dos nfo precisa da AniList: fica para o job de metadados (WriteNFOs), que o daemon
+	// enfileira depois do organize.
+	if layout.movie {
+		o.writeMovieNFO(layout.showDir, layout.showTitle, layout.showID, nil)
+	} else {
+		o.writeShowNFO(layout.showDir, layout.showTitle, layout.showID, nil)
 	}
-	return true
+
+	return created, nil
 }
 
 // BackfillShowNFOs escreve o tvshow.nfo das pastas que ja estavam na biblioteca antes de o
@@ -254,6 +338,19 @@ func (o *organizer) BackfillShowNFOs(episodes []EpisodeStruct) {
 			continue
 		}
 		dir := filepath.Dir(ep.LibraryPaths[0])
+		if isLibraryExtraDir(dir) {
+			// O primeiro arquivo de um pack pode ser um extra: o nfo e da pasta de cima.
+			dir = filepath.Dir(dir)
+		}
+		name, id := ep.AnimeName, ep.AnimeID
+		if isSeasonFolder(dir) {
+			// Season NN de LibrarySeasonFolders: o nfo e o da serie, na pasta de cima.
+			if ep.Meta == nil || ep.Meta.Show == nil || ep.Meta.Show.ID <= 0 {
+				continue
+			}
+			dir = filepath.Dir(dir)
+			name, id = ep.Meta.Show.Title, ep.Meta.Show.ID
+		}
 		if seen[dir] {
 			continue
 		}
@@ -261,7 +358,10 @@ func (o *organizer) BackfillShowNFOs(episodes []EpisodeStruct) {
 		if _, err := o.fs.Stat(dir); err != nil {
 			continue // pasta removida da biblioteca por fora
 		}
-		if o.writeShowNFO(dir, ep.AnimeName, ep.AnimeID) {
+		if _, err := o.fs.Stat(filepath.Join(dir, movieNFOName)); err == nil {
+			continue // pasta de filme do layout de filme: o nfo dela e o movie.nfo
+		}
+		if o.writeShowNFO(dir, name, id, nil) {
 			written++
 		}
 	}
@@ -274,27 +374,290 @@ func (o *organizer) RemoveFromLibrary(path string) error {
 	if path == "" {
 		return nil
 	}
+	// O nfo e as legendas descrevem um video que deixa de existir, sejam nossos ou do usuario.
+	_ = o.fs.Remove(episodeNFOPath(path))
+	for _, sub := range o.librarySidecars(path) {
+		_ = o.fs.Remove(sub)
+	}
+	// Remove apaga o proprio symlink, nunca o alvo. Lstat no fallback: um symlink cujo alvo
+	// sumiu ainda esta la.
 	if err := o.fs.Remove(path); err != nil {
-		if _, statErr := o.fs.Stat(path); statErr != nil {
+		if _, statErr := o.fs.Lstat(path); statErr != nil {
 			// Already gone — not an error.
 			return nil
 		}
 		return err
 	}
+	// A pasta de extras criada pelo Organize nao fica vazia para tras.
+	if dir := filepath.Dir(path); isLibraryExtraDir(dir) {
+		o.removeDirIfOnlyShowFiles(dir)
+	}
+	return nil
+}
+
+func (o *organizer) MoveInLibrary(from, to string) error {
+	if from == to {
+		return nil
+	}
+	srcInfo, srcErr := o.fs.Stat(from)
+	destInfo, destErr := o.fs.Stat(to)
+	switch {
+	case srcErr != nil && destErr == nil:
+		// Move de um passe anterior que caiu antes de gravar os LibraryPaths.
+		return nil
+	case srcErr != nil:
+		return fmt.Errorf("library file %s not found: %w", from, srcErr)
+	case destErr == nil:
+		if !os.SameFile(srcInfo, destInfo) {
+			return fmt.Errorf("cannot move %s: %s already exists", from, to)
+		}
+		if err := o.fs.Remove(from); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", from, err)
+		}
+	default:
+		if err := o.fs.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			return fmt.Errorf("failed to create library folder %s: %w", filepath.Dir(to), err)
+		}
+		if err := o.move(from, to); err != nil {
+			return fmt.Errorf("failed to move %s -> %s: %w", from, to, err)
+		}
+	}
+
+	o.moveEpisodeNFO(from, to)
+	o.moveSidecars(from, to)
+
+	oldDir, newDir := filepath.Dir(from), filepath.Dir(to)
+	if oldDir == newDir {
+		return nil
+	}
+	// O nfo e a arte podem ter sido ajustados a mao (writeShowNFO so reescreve o que tem o
+	// nfoMarker): vao junto em vez de serem regerados. Uma copia, porque a pasta antiga ainda
+	// pode ter outros episodios. Para dentro de uma Season NN nao: la eles sao os da serie, na
+	// pasta raiz (EnsureShowNFO e o job de metadados).
+	if !isSeasonFolder(newDir) && !isLibraryExtraDir(newDir) {
+		for _, name := range showFiles {
+			oldFile, newFile := filepath.Join(oldDir, name), filepath.Join(newDir, name)
+			if _, err := o.fs.Stat(newFile); err == nil {
+				continue
+			}
+			if data, err := o.fs.ReadFile(oldFile); err == nil {
+				if err := o.fs.WriteFile(newFile, data, 0644); err != nil {
+					logger.Logger.Warn().Err(err).Str("path", newFile).Msg("Failed to copy show file")
+				}
+			}
+		}
+	}
+	// As fontes sao das legendas, que vao junto com o episodio para qualquer pasta.
+	o.copyFonts(oldDir, newDir)
+	o.removeDirIfOnlyShowFiles(oldDir)
+	if isSeasonFolder(oldDir) || isLibraryExtraDir(oldDir) {
+		// A ultima Season (ou pasta de extras) de uma serie que saiu dali leva junto a pasta
+		// de cima.
+		o.removeDirIfOnlyShowFiles(filepath.Dir(oldDir))
+	}
+	return nil
+}
+
+func (o *organizer) EnsureShowNFO(dir, animeName string, animeID int) {
+	if _, err := o.fs.Stat(dir); err != nil {
+		return
+	}
+	o.writeShowNFO(dir, animeName, animeID, nil)
+}
+
+func (o *organizer) MissingNFOs(moves []LibraryMove) bool {
+	for _, mv := range moves {
+		if mv.From != mv.To || mv.AnimeID <= 0 {
+			continue
+		}
+		if mv.Movie {
+			if _, err := o.fs.Stat(filepath.Join(mv.ShowDir, movieNFOName)); err != nil {
+				return true
+			}
+			continue
+		}
+		if mv.ShowID > 0 {
+			data, err := o.fs.ReadFile(filepath.Join(mv.ShowDir, "tvshow.nfo"))
+			if err != nil {
+				return true
+			}
+			if _, legacy := legacyShowNFOID(data); legacy && !isGeneratedNFO(data) {
+				return true
+			}
+		}
+		if mv.EpisodeNumber > 0 {
+			if _, err := o.fs.Stat(episodeNFOPath(mv.To)); err != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (o *organizer) WriteNFOs(moves []LibraryMove, info *NFOInfo) {
+	if info == nil {
+		return
+	}
+	shows := make(map[string]bool)
+	for _, mv := range moves {
+		if mv.From != mv.To || mv.AnimeID <= 0 {
+			continue
+		}
+		if _, err := o.fs.Stat(mv.To); err != nil {
+			continue // arquivo apagado por fora: sem video, sem nfo
+		}
+		if mv.Movie {
+			// Filme: o movie.nfo descreve o arquivo, sem nfo de episodio.
+			if !shows[mv.ShowDir] {
+				shows[mv.ShowDir] = true
+				o.writeMovieNFO(mv.ShowDir, mv.ShowTitle, mv.ShowID, &info.Show)
+			}
+			continue
+		}
+		if !shows[mv.ShowDir] {
+			shows[mv.ShowDir] = true
+			o.writeShowNFO(mv.ShowDir, mv.ShowTitle, mv.ShowID, &info.Show)
+		}
+		o.writeEpisodeNFO(mv.To, mv.ShowTitle, mv.AnimeID, mv.Season, mv.EpisodeNumber, mv.EntryEpisode, info)
+	}
+}
+
+// move e o Rename de um arquivo da biblioteca, inclusive entre volumes: library_movies_path
+// pode ficar em outro disco (decisions.md #79), e o relink que leva um filme para dentro ou
+// para fora dele receberia EXDEV. Ai o symlink e recriado apontando para o mesmo arquivo que
+// semeia, e o resto e copiado — hardlink nao atravessa volume, entao do outro lado so copia ou
+// symlink passam no probe — e so depois apagado da origem.
+func (o *organizer) move(from, to string) error {
+	err := o.fs.Rename(from, to)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+	info, err := o.fs.Lstat(from)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := o.fs.Readlink(from)
+		if err != nil {
+			return err
+		}
+		if err := o.fs.Symlink(target, to); err != nil {
+			return err
+		}
+	} else if err := o.fs.CopyFile(from, to); err != nil {
+		return err
+	}
+	// A copia ja esta no destino: uma origem que nao saiu vira orfa para a auditoria, em vez
+	// de travar o relink em "destino ja existe" a cada nova tentativa.
+	if err := o.fs.Remove(from); err != nil {
+		logger.Logger.Warn().Err(err).Str("path", from).Msg("Moved a library file across volumes but failed to remove the original")
+	}
+	return nil
+}
+
+// moveEpisodeNFO cuida do nfo do episodio num move. O nosso e apagado: a temporada e o numero
+// dentro dele podem ter mudado. O do usuario vai junto, a menos que o destino ja tenha um.
+func (o *organizer) moveEpisodeNFO(from, to string) {
+	oldNFO, newNFO := episodeNFOPath(from), episodeNFOPath(to)
+	if oldNFO == newNFO {
+		return
+	}
+	data, err := o.fs.ReadFile(oldNFO)
+	if err != nil {
+		return
+	}
+	if isGeneratedNFO(data) {
+		_ = o.fs.Remove(oldNFO)
+		return
+	}
+	if _, err := o.fs.Stat(newNFO); err == nil {
+		return
+	}
+	if err := o.move(oldNFO, newNFO); err != nil {
+		logger.Logger.Warn().Err(err).Str("from", oldNFO).Str("to", newNFO).Msg("Failed to move episode nfo")
+	}
+}
+
+// showFiles sao os arquivos da serie, e nao de um episodio, na pasta do anime.
+var showFiles = []string{"tvshow.nfo", PosterFileName, FanartFileName}
+
+// removeDirIfOnlyShowFiles apaga a pasta de anime que o relink esvaziou. Pasta com qualquer
+// outra coisa alem do nfo (tvshow.nfo ou movie.nfo), da arte da serie e da pasta de fontes
+// (episodio nao organizado por nos, legenda solta) fica.
+func (o *organizer) removeDirIfOnlyShowFiles(dir string) {
+	entries, err := o.fs.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !slices.Contains(showFiles, e.Name()) && e.Name() != movieNFOName && !(e.IsDir() && e.Name() == libraryFontsDir) {
+			return
+		}
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			_ = o.removeTree(filepath.Join(dir, e.Name()))
+			continue
+		}
+		_ = o.fs.Remove(filepath.Join(dir, e.Name()))
+	}
+	_ = o.fs.Remove(dir)
+}
+
+func (o *organizer) ProbePath(completedPath string, mode LinkMode) error {
+	probeSrc, err := o.prepareProbe(completedPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = o.fs.Remove(probeSrc) }()
+
+	if err := o.probeLink(probeSrc, completedPath, mode); err != nil {
+		return fmt.Errorf("this filesystem does not support %s, which library_link_mode %q requires (modes that work here: %s): %w",
+			describeLinkMode(mode), mode, joinLinkModes(o.workingLinkModes(probeSrc, completedPath)), err)
+	}
 	return nil
 }
 
-func (o *organizer) ProbePath(completedPath string) error {
+func (o *organizer) ProbeMoviesPath(completedPath, moviesPath string, mode LinkMode) error {
+	if err := o.fs.MkdirAll(moviesPath, 0755); err != nil {
+		return fmt.Errorf("cannot access movies path %s: %w", moviesPath, err)
+	}
+	probeSrc, err := o.prepareProbe(completedPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = o.fs.Remove(probeSrc) }()
+
+	if err := o.probeLink(probeSrc, moviesPath, mode); err != nil {
+		return fmt.Errorf("the movies path does not support %s from the download folder, which library_link_mode %q requires (modes that work there: %s): %w",
+			describeLinkMode(mode), mode, joinLinkModes(o.workingLinkModes(probeSrc, moviesPath)), err)
+	}
+	return nil
+}
+
+func (o *organizer) ProbeLinkModes(completedPath string) ([]LinkMode, error) {
+	probeSrc, err := o.prepareProbe(completedPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = o.fs.Remove(probeSrc) }()
+	return o.workingLinkModes(probeSrc, completedPath), nil
+}
+
+// prepareProbe cria a biblioteca, o diretorio de download com o .ignore e o arquivo de origem
+// da sonda, dentro do diretorio de download como um torrent de verdade. Quem chama apaga a
+// origem.
+func (o *organizer) prepareProbe(completedPath string) (string, error) {
 	if completedPath == "" {
-		return fmt.Errorf("completed anime path must be set")
+		return "", fmt.Errorf("completed anime path must be set")
 	}
 	if err := o.fs.MkdirAll(completedPath, 0755); err != nil {
-		return fmt.Errorf("cannot access completed path %s: %w", completedPath, err)
+		return "", fmt.Errorf("cannot access completed path %s: %w", completedPath, err)
 	}
 
 	downloadPath := filepath.Join(completedPath, downloadDirName)
 	if err := o.fs.MkdirAll(downloadPath, 0755); err != nil {
-		return fmt.Errorf("cannot create download folder %s: %w", downloadPath, err)
+		return "", fmt.Errorf("cannot create download folder %s: %w", downloadPath, err)
 	}
 
 	// O prefixo com ponto esconde a pasta do scanner do Jellyfin no Linux; o .ignore cobre
@@ -303,32 +666,63 @@ func (o *organizer) ProbePath(completedPath string) error {
 	ignorePath := filepath.Join(downloadPath, ".ignore")
 	if _, err := o.fs.Stat(ignorePath); err != nil {
 		if err := o.fs.WriteFile(ignorePath, nil, 0644); err != nil {
-			return fmt.Errorf("cannot write ignore marker %s: %w", ignorePath, err)
+			return "", fmt.Errorf("cannot write ignore marker %s: %w", ignorePath, err)
 		}
 	}
 
 	probeSrc := filepath.Join(downloadPath, ".aad_link_probe")
-	probeDst := filepath.Join(completedPath, ".aad_link_probe")
-
 	// Limpa sobras de uma sonda anterior.
 	_ = o.fs.Remove(probeSrc)
-	_ = o.fs.Remove(probeDst)
+	_ = o.fs.Remove(filepath.Join(completedPath, ".aad_link_probe"))
 
 	if err := o.fs.WriteFile(probeSrc, []byte("probe"), 0644); err != nil {
-		return fmt.Errorf("cannot write to download path %s: %w", downloadPath, err)
+		return "", fmt.Errorf("cannot write to download path %s: %w", downloadPath, err)
 	}
-	defer func() { _ = o.fs.Remove(probeSrc) }()
+	return probeSrc, nil
+}
 
-	if err := o.link(probeSrc, probeDst); err != nil {
-		return fmt.Errorf("this filesystem does not support hardlinks, which the library requires: %w", err)
+// probeLink poe probeSrc na raiz da biblioteca com o modo e apaga o resultado.
+func (o *organizer) probeLink(probeSrc, completedPath string, mode LinkMode) error {
+	probeDst := filepath.Join(completedPath, ".aad_link_probe")
+	if err := o.linkFunc(mode)(probeSrc, probeDst); err != nil {
+		return err
 	}
 	_ = o.fs.Remove(probeDst)
-
 	return nil
 }
 
+func (o *organizer) workingLinkModes(probeSrc, completedPath string) []LinkMode {
+	modes := []LinkMode{}
+	for _, m := range LinkModes {
+		if o.probeLink(probeSrc, completedPath, m) == nil {
+			modes = append(modes, m)
+		}
+	}
+	return modes
+}
+
+// torrentVideoFiles returns the folder the video files are relative to, and the files. A
+// single-file content path (what qBittorrent and Transmission report for a one-file torrent)
+// is its own only candidate, relative to its parent: walking the parent instead would pick up
+// every other torrent in the client's download folder.
+func (o *organizer) torrentVideoFiles(dataDir string) (string, []string, error) {
+	if info, err := o.fs.Stat(dataDir); err == nil && !info.IsDir() {
+		if !isVideoFile(info.Name()) {
+			return dataDir, nil, nil
+		}
+		return filepath.Dir(dataDir), []string{filepath.Base(dataDir)}, nil
+	}
+	files, err := o.collectVideoFiles(dataDir)
+	return dataDir, files, err
+}
+
 // collectVideoFiles returns the video-file paths under root, relative to root.
 func (o *organizer) collectVideoFiles(root string) ([]string, error) {
+	return o.collectFiles(root, isVideoFile)
+}
+
+// collectFiles returns the paths under root whose name passes keep, relative to root.
+func (o *organizer) collectFiles(root string, keep func(name string) bool) ([]string, error) {
 	var out []string
 	var walk func(dir, rel string) error
 	walk = func(dir, rel string) error {
@@ -345,7 +739,7 @@ func (o *organizer) collectVideoFiles(root string) ([]string, error) {
 				}
 				continue
 			}
-			if isVideoFile(e.Name()) {
+			if keep(e.Name()) {
 				out = append(out, childRel)
 			}
 		}
@@ -357,6 +751,31 @@ func (o *organizer) collectVideoFiles(root string) ([]string, error) {
 	return out, nil
 }
 
+// prepareDest libera o nome dest para o link de src. Devolve true quando dest ja e o proprio
+// src (nada a fazer). Um arquivo diferente no nome (redownload/replace) e apagado: o usuario
+// pediu a troca, entao o novo ganha. Lstat: um symlink cujo alvo sumiu ainda ocupa o nome e
+// precisa ser trocado.
+func (o *organizer) prepareDest(mode LinkMode, src, dest string) (bool, error) {
+	if _, lstatErr := o.fs.Lstat(dest); lstatErr != nil {
+		return false, nil
+	}
+	srcInfo, srcErr := o.fs.Stat(src)
+	if srcErr != nil {
+		return false, fmt.Errorf("failed to stat source %s: %w", src, srcErr)
+	}
+	if destInfo, statErr := o.fs.Stat(dest); statErr == nil && sameLibraryFile(mode, srcInfo, destInfo) {
+		return true, nil
+	}
+	logger.Logger.Info().
+		Str("source", src).
+		Str("destination", dest).
+		Msg("Replacing existing library file with the newly downloaded one")
+	if err := o.fs.Remove(dest); err != nil {
+		return false, fmt.Errorf("failed to replace existing library file %s: %w", dest, err)
+	}
+	return false, nil
+}
+
 func (o *organizer) cleanupIfEmpty(dir string, dirExisted bool) {
 	if dirExisted {
 		return
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -210,6 +211,43 @@ func TestOrganizeSingleEpisodeJellyfin(t *testing.T) {
 	}
 }
 
+// qBittorrent and Transmission report a one-file torrent's content path as the file itself,
+// sitting in the client's shared download folder next to every other torrent. Only that file
+// may be linked.
+func TestOrganizeSingleFileContentPath(t *testing.T) {
+	tmp := t.TempDir()
+	saveDir := filepath.Join(tmp, "seedbox")
+	completed := filepath.Join(tmp, "completed")
+	src := filepath.Join(saveDir, "[Group] My Anime - 05.mkv")
+	writeFile(t, src, "video-bytes")
+	writeFile(t, filepath.Join(saveDir, "[Group] Other Anime - 01.mkv"), "other")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	created, err := lib.Organize(OrganizeRequest{
+		TorrentDataDir: src,
+		AnimeName:      "My Anime",
+		CompletedPath:  completed,
+		EpisodeNumber:  intPtr(5),
+		RenameJellyfin: true,
+	})
+	if err != nil {
+		t.Fatalf("Organize: %v", err)
+	}
+
+	wantDest := filepath.Join(completed, "My Anime", "My Anime - E05.mkv")
+	if len(created) != 1 || created[0] != wantDest {
+		t.Fatalf("created = %v, want [%s]", created, wantDest)
+	}
+	srcInfo, _ := os.Stat(src)
+	destInfo, err := os.Stat(wantDest)
+	if err != nil {
+		t.Fatalf("dest missing: %v", err)
+	}
+	if !os.SameFile(srcInfo, destInfo) {
+		t.Errorf("dest is not a hardlink of src (different inode)")
+	}
+}
+
 func TestOrganizeBatchRawNames(t *testing.T) {
 	tmp := t.TempDir()
 	dataDir := filepath.Join(tmp, "save", "batchid")
@@ -272,8 +310,8 @@ func TestOrganizeBatchJellyfinNames(t *testing.T) {
 	for _, name := range []string{
 		"Anime - E01.mkv",
 		"Anime - E02.mkv",
-		"[Sub] Anime - 02v2 [1080p].mkv", // colisao com E02: nome cru
-		"NCOP.mkv",                       // sem numero: nome cru
+		"[Sub] Anime - 02v2 [1080p].mkv",    // colisao com E02: nome cru
+		filepath.Join("extras", "NCOP.mkv"), // abertura sem creditos: extras, nome cru
 	} {
 		if _, err := os.Stat(filepath.Join(completed, "Anime", name)); err != nil {
 			t.Errorf("expected link %s: %v", name, err)
@@ -552,7 +590,7 @@ func TestProbePath(t *testing.T) {
 		completed := filepath.Join(t.TempDir(), "library")
 		lib := NewLibrarian(NewOSFileSystem())
 
-		if err := lib.ProbePath(completed); err != nil {
+		if err := lib.ProbePath(completed, LinkHardlink); err != nil {
 			t.Fatalf("ProbePath: %v", err)
 		}
 
@@ -569,7 +607,7 @@ func TestProbePath(t *testing.T) {
 		completed := filepath.Join(t.TempDir(), "library")
 		lib := NewLibrarian(NewOSFileSystem())
 
-		if err := lib.ProbePath(completed); err != nil {
+		if err := lib.ProbePath(completed, LinkHardlink); err != nil {
 			t.Fatalf("ProbePath: %v", err)
 		}
 
@@ -585,7 +623,7 @@ func TestProbePath(t *testing.T) {
 
 	t.Run("rejeita biblioteca vazia", func(t *testing.T) {
 		lib := NewLibrarian(NewOSFileSystem())
-		if err := lib.ProbePath(""); err == nil {
+		if err := lib.ProbePath("", LinkHardlink); err == nil {
 			t.Error("quero erro para caminho vazio, veio nil")
 		}
 	})
@@ -594,11 +632,114 @@ func TestProbePath(t *testing.T) {
 		completed := filepath.Join(t.TempDir(), "library")
 		lib := NewLibrarian(NewOSFileSystem())
 
-		if err := lib.ProbePath(completed); err != nil {
+		if err := lib.ProbePath(completed, LinkHardlink); err != nil {
 			t.Fatalf("primeira chamada: %v", err)
 		}
-		if err := lib.ProbePath(completed); err != nil {
+		if err := lib.ProbePath(completed, LinkHardlink); err != nil {
 			t.Fatalf("segunda chamada: %v", err)
 		}
 	})
 }
+
+// Each LinkMode puts the file in the library its own way, and a second Organize recognizes what
+// the first one created instead of replacing it.
+func TestOrganizeLinkModes(t *testing.T) {
+	for _, mode := range []LinkMode{LinkSymlink, LinkCopy} {
+		t.Run(string(mode), func(t *testing.T) {
+			tmp := t.TempDir()
+			dataDir := filepath.Join(tmp, "save", "id")
+			completed := filepath.Join(tmp, "completed")
+			src := filepath.Join(dataDir, "ep.mkv")
+			writeFile(t, src, "video-bytes")
+
+			lib := NewLibrarian(NewOSFileSystem())
+			req := OrganizeRequest{
+				TorrentDataDir: dataDir, AnimeName: "A", CompletedPath: completed,
+				EpisodeNumber: intPtr(1), RenameJellyfin: true, LinkMode: mode,
+			}
+			created, err := lib.Organize(req)
+			if err != nil {
+				t.Fatalf("Organize: %v", err)
+			}
+			dest := created[0]
+			linfo, err := os.Lstat(dest)
+			if err != nil {
+				t.Fatalf("lstat dest: %v", err)
+			}
+			if isLink := linfo.Mode()&os.ModeSymlink != 0; isLink != (mode == LinkSymlink) {
+				t.Errorf("dest symlink = %v, want %v", isLink, mode == LinkSymlink)
+			}
+			srcInfo, _ := os.Stat(src)
+			destInfo, _ := os.Stat(dest)
+			if sameInode := os.SameFile(srcInfo, destInfo); sameInode != (mode == LinkSymlink) {
+				t.Errorf("dest shares the source inode = %v, want %v", sameInode, mode == LinkSymlink)
+			}
+			if data, _ := os.ReadFile(dest); string(data) != "video-bytes" {
+				t.Errorf("dest content = %q", data)
+			}
+
+			before := linfo.ModTime()
+			if _, err := lib.Organize(req); err != nil {
+				t.Fatalf("second Organize: %v", err)
+			}
+			if again, _ := os.Lstat(dest); !again.ModTime().Equal(before) || !os.SameFile(linfo, again) {
+				t.Error("second Organize replaced the file instead of recognizing it")
+			}
+
+			// Removing the library entry never touches the seeding copy.
+			if err := lib.RemoveFromLibrary(dest); err != nil {
+				t.Fatalf("RemoveFromLibrary: %v", err)
+			}
+			if _, err := os.Lstat(dest); !os.IsNotExist(err) {
+				t.Errorf("library entry still present: %v", err)
+			}
+			if _, err := os.Stat(src); err != nil {
+				t.Errorf("seeding copy removed with the library entry: %v", err)
+			}
+		})
+	}
+}
+
+// A symlink whose target is gone (torrent removed by hand) is replaced, not reported as done.
+func TestOrganizeReplacesDanglingSymlink(t *testing.T) {
+	tmp := t.TempDir()
+	dataDir := filepath.Join(tmp, "save", "id")
+	completed := filepath.Join(tmp, "completed")
+	writeFile(t, filepath.Join(dataDir, "ep.mkv"), "video-bytes")
+	dest := filepath.Join(completed, "A", "A - E01.mkv")
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(tmp, "gone.mkv"), dest); err != nil {
+		t.Fatal(err)
+	}
+
+	lib := NewLibrarian(NewOSFileSystem())
+	if _, err := lib.Organize(OrganizeRequest{
+		TorrentDataDir: dataDir, AnimeName: "A", CompletedPath: completed,
+		EpisodeNumber: intPtr(1), RenameJellyfin: true, LinkMode: LinkSymlink,
+	}); err != nil {
+		t.Fatalf("Organize: %v", err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "video-bytes" {
+		t.Errorf("dest = %q, %v; want the new link", data, err)
+	}
+}
+
+func TestProbeLinkModes(t *testing.T) {
+	completed := filepath.Join(t.TempDir(), "library")
+	lib := NewLibrarian(NewOSFileSystem())
+	modes, err := lib.ProbeLinkModes(completed)
+	if err != nil {
+		t.Fatalf("ProbeLinkModes: %v", err)
+	}
+	// Reflink depends on the test machine's filesystem; the other three work on any local disk.
+	for _, want := range []LinkMode{LinkHardlink, LinkSymlink, LinkCopy} {
+		if !slices.Contains(modes, want) {
+			t.Errorf("modes = %v, want %s among them", modes, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(completed, ".torrents", ".aad_link_probe")); err == nil {
+		t.Error("probe source left behind")
+	}
+}
@@ -0,0 +1,169 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LinkMode e como Organize poe um arquivo completo na biblioteca (Config.LibraryLinkMode).
+type LinkMode string
+
+const (
+	// LinkHardlink e o default: um segundo nome para os mesmos bytes, sem espaco duplicado, e
+	// o arquivo sobrevive a remocao do torrent. Exige um filesystem com hardlink.
+	LinkHardlink LinkMode = "hardlink"
+	// LinkReflink clona o arquivo copy-on-write (Btrfs, XFS, APFS, ZFS recente): inode proprio,
+	// mas os blocos sao compartilhados ate alguem escrever em um dos lados.
+	LinkReflink LinkMode = "reflink"
+	// LinkSymlink aponta para o arquivo que semeia. Nao duplica nada e funciona em quase todo
+	// share de rede, mas o episodio some da biblioteca junto com o torrent, e o Jellyfin precisa
+	// enxergar o caminho de destino igual ao daemon.
+	LinkSymlink LinkMode = "symlink"
+	// LinkCopy copia os bytes: funciona em qualquer filesystem e ocupa o espaco duas vezes
+	// enquanto o torrent semeia (checkDiskSpace conta isso).
+	LinkCopy LinkMode = "copy"
+)
+
+// LinkModes sao os modos na ordem em que a tela de config os oferece.
+var LinkModes = []LinkMode{LinkHardlink, LinkReflink, LinkSymlink, LinkCopy}
+
+// IsLinkMode reports whether s is one of LinkModes.
+func IsLinkMode(s string) bool {
+	for _, m := range LinkModes {
+		if string(m) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkMode devolve o modo em vigor. "" (config.json anterior ao campo) e qualquer valor
+// desconhecido valem hardlink, o comportamento de antes.
+func (c *Config) LinkMode() LinkMode {
+	if IsLinkMode(c.LibraryLinkMode) {
+		return LinkMode(c.LibraryLinkMode)
+	}
+	return LinkHardlink
+}
+
+// Duplicates diz se o modo ocupa o espaco do arquivo uma segunda vez.
+func (m LinkMode) Duplicates() bool {
+	return m == LinkCopy
+}
+
+// ErrReflinkUnsupported e devolvido pelo reflink nas plataformas sem clone de arquivo.
+var ErrReflinkUnsupported = errors.New("reflinks are not supported on this platform")
+
+// linkFunc devolve a funcao que poe oldname na biblioteca como newname no modo escolhido.
+// Organize, ProbePath e ProbeLinkModes passam todos por aqui, entao nunca discordam.
+func (o *organizer) linkFunc(mode LinkMode) func(oldname, newname string) error {
+	switch mode {
+	case LinkReflink:
+		return o.fs.Reflink
+	case LinkSymlink:
+		return o.symlink
+	case LinkCopy:
+		return o.fs.CopyFile
+	}
+	return o.link
+}
+
+// symlink cria o link com o caminho absoluto: um relativo quebraria no relink, que move o
+// link para outra pasta.
+func (o *organizer) symlink(oldname, newname string) error {
+	abs, err := filepath.Abs(oldname)
+	if err != nil {
+		return err
+	}
+	return o.fs.Symlink(abs, newname)
+}
+
+// sameLibraryFile diz se dest ja e o arquivo de src, para o Organize repetido nao refazer o
+// trabalho. Hardlink e symlink sao o mesmo inode (Stat segue o symlink). A copia e o reflink
+// tem inode proprio e casam por tamanho e mtime, que cloneFile preserva. So nesses dois
+// modos: num hardlink, um arquivo novo do mesmo tamanho gravado no mesmo tick do relogio do
+// kernel passaria por igual.
+func sameLibraryFile(mode LinkMode, src, dest fs.FileInfo) bool {
+	if os.SameFile(src, dest) {
+		return true
+	}
+	return (mode == LinkCopy || mode == LinkReflink) &&
+		src.Size() == dest.Size() && src.ModTime().Equal(dest.ModTime())
+}
+
+// describeLinkMode e o nome do modo nas mensagens de erro.
+func describeLinkMode(mode LinkMode) string {
+	switch mode {
+	case LinkReflink:
+		return "reflinks"
+	case LinkSymlink:
+		return "symlinks"
+	case LinkCopy:
+		return "copying files"
+	}
+	return "hardlinks"
+}
+
+// joinLinkModes formata a lista de modos para as mensagens.
+func joinLinkModes(modes []LinkMode) string {
+	if len(modes) == 0 {
+		return "none"
+	}
+	names := make([]string, len(modes))
+	for i, m := range modes {
+		names[i] = string(m)
+	}
+	return strings.Join(names, ", ")
+}
+
+// cloneFile grava dst com o conteudo de src via fill, num temporario com ponto na mesma pasta
+// e depois rename: o scanner do Jellyfin nao pega um episodio pela metade, e um dst presente
+// e sempre completo. Preserva o modo e o mtime de src (sameLibraryFile depende do mtime).
+func cloneFile(src, dst string, fill func(src, tmp string) error) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".aadtmp")
+	_ = os.Remove(tmp) // sobra de uma copia interrompida
+	if err := fill(src, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, info.Mode().Perm()); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// copyContents copia os bytes de src para tmp, que nao pode existir.
+func copyContents(src, tmp string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Close()
+}
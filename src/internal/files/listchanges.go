@@ -0,0 +1,131 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// maxListChanges limita o feed: ele explica o passe de agora, nao e um historico da conta. Com
+// o limite o arquivo nao cresce para sempre numa lista que muda todo dia.
+const maxListChanges = 500
+
+// ListSnapshotEntry is what the last pass saw of one anime in one account's list.
+type ListSnapshotEntry struct {
+	Status   string `json:"status"`
+	Progress int    `json:"progress"`
+	// Title e vazio nas contas do MAL/Kitsu, cuja lista nao traz titulo: so o anime que mudou
+	// tem o titulo resolvido, na hora do evento.
+	Title string `json:"title,omitempty"`
+}
+
+// ListSnapshot is every account's list as of the last pass: account key (the AniList username,
+// or lists.Account.Key for MyAnimeList/Kitsu) → media id → entry.
+type ListSnapshot map[string]map[int]ListSnapshotEntry
+
+// Valores de ListChange.Kind.
+const (
+	ListChangeAdded    = "added"
+	ListChangeRemoved  = "removed"
+	ListChangeStatus   = "status_changed"
+	ListChangeProgress = "progress_changed"
+)
+
+// ListChange is one difference between two snapshots of an account's list.
+type ListChange struct {
+	At      time.Time `json:"at"`
+	Account string    `json:"account"`
+	MediaID int       `json:"media_id"`
+	Title   string    `json:"title"`
+	Kind    string    `json:"kind"`
+	// Old* vem vazio em "added", New* em "removed".
+	OldStatus   string `json:"old_status,omitempty"`
+	NewStatus   string `json:"new_status,omitempty"`
+	OldProgress int    `json:"old_progress"`
+	NewProgress int    `json:"new_progress"`
+}
+
+// LoadListSnapshot devolve o snapshot do ultimo passe. Arquivo ausente e snapshot vazio.
+func (m *FileManager) LoadListSnapshot() (ListSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := ListSnapshot{}
+	if err := m.loadJSONLocked(m.listSnapshotPath, &snapshot); err != nil {
+		return nil, fmt.Errorf("list snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+func (m *FileManager) SaveListSnapshot(snapshot ListSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal list snapshot: %w", err)
+	}
+	if err := m.writeAtomic(m.listSnapshotPath, b); err != nil {
+		return fmt.Errorf("failed to write list snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadListChanges devolve o feed na ordem em que foi gravado, o mais antigo primeiro.
+func (m *FileManager) LoadListChanges() ([]ListChange, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changes := []ListChange{}
+	if err := m.loadJSONLocked(m.listChangesPath, &changes); err != nil {
+		return nil, fmt.Errorf("list changes: %w", err)
+	}
+	return changes, nil
+}
+
+// AppendListChanges acrescenta ao feed e descarta os mais antigos alem de maxListChanges.
+func (m *FileManager) AppendListChanges(changes []ListChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feed := []ListChange{}
+	if err := m.loadJSONLocked(m.listChangesPath, &feed); err != nil {
+		return fmt.Errorf("list changes: %w", err)
+	}
+	feed = append(feed, changes...)
+	if len(feed) > maxListChanges {
+		feed = feed[len(feed)-maxListChanges:]
+	}
+
+	b, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal list changes: %w", err)
+	}
+	if err := m.writeAtomic(m.listChangesPath, b); err != nil {
+		return fmt.Errorf("failed to write list changes: %w", err)
+	}
+	return nil
+}
+
+// loadJSONLocked le path em out; arquivo ausente deixa out como veio.
+func (m *FileManager) loadJSONLocked(path string, out any) error {
+	_, err := m.fs.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	b, err := m.fs.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("failed to parse file: %w", err)
+	}
+	return nil
+}
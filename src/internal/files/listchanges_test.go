@@ -0,0 +1,55 @@
+package files
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListSnapshot_RoundTrip(t *testing.T) {
+	m := newTestManager(t)
+
+	snapshot, err := m.LoadListSnapshot()
+	if err != nil || len(snapshot) != 0 {
+		t.Fatalf("arquivo ausente: quero snapshot vazio, veio %v, %v", snapshot, err)
+	}
+
+	want := ListSnapshot{"user1": {21: {Status: "CURRENT", Progress: 3, Title: "One Piece"}}}
+	if err := m.SaveListSnapshot(want); err != nil {
+		t.Fatalf("SaveListSnapshot: %v", err)
+	}
+	got, err := m.LoadListSnapshot()
+	if err != nil {
+		t.Fatalf("LoadListSnapshot: %v", err)
+	}
+	if got["user1"][21] != want["user1"][21] {
+		t.Fatalf("quero %+v, veio %+v", want, got)
+	}
+}
+
+// O feed guarda so os maxListChanges mais novos, na ordem em que chegaram.
+func TestAppendListChanges_KeepsNewest(t *testing.T) {
+	m := newTestManager(t)
+	at := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	batch := make([]ListChange, maxListChanges)
+	for i := range batch {
+		batch[i] = ListChange{At: at, MediaID: i, Kind: ListChangeAdded}
+	}
+	if err := m.AppendListChanges(batch); err != nil {
+		t.Fatalf("AppendListChanges: %v", err)
+	}
+	if err := m.AppendListChanges([]ListChange{{At: at, MediaID: 9999, Kind: ListChangeRemoved}}); err != nil {
+		t.Fatalf("AppendListChanges: %v", err)
+	}
+
+	feed, err := m.LoadListChanges()
+	if err != nil {
+		t.Fatalf("LoadListChanges: %v", err)
+	}
+	if len(feed) != maxListChanges {
+		t.Fatalf("quero %d mudancas, veio %d", maxListChanges, len(feed))
+	}
+	if feed[0].MediaID != 1 || feed[len(feed)-1].MediaID != 9999 {
+		t.Fatalf("quero a mais antiga descartada e a nova no fim, veio %d ... %d", feed[0].MediaID, feed[len(feed)-1].MediaID)
+	}
+}
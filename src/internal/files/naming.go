@@ -0,0 +1,700 @@
+package files
+
+import (
+	"AutoAnimeDownloader/src/internal/nyaa"
+
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Templates de nome da biblioteca. Os defaults reproduzem exatamente os nomes de antes dos
+// templates (sanitizeName(AnimeName) e jellyfinName), entao uma config sem os campos nao move
+// nada.
+const (
+	DefaultFolderTemplate = "{title}"
+	DefaultFileTemplate   = "{title} - E{episode:02}"
+)
+
+// namingToken describes one {token} of the naming templates.
+type namingToken struct {
+	// numeric tokens accept a zero-pad width ({episode:02}) and render 0 as empty.
+	numeric bool
+	// folder tokens are the same for every file of an anime, so they may name its folder.
+	folder bool
+}
+
+var namingTokens = map[string]namingToken{
+	"title":         {folder: true},
+	"title_romaji":  {folder: true},
+	"title_english": {folder: true},
+	"season":        {numeric: true, folder: true},
+	"year":          {numeric: true, folder: true},
+	"anilist_id":    {numeric: true, folder: true},
+	"episode":       {numeric: true},
+	"absolute":      {numeric: true},
+	"group":         {},
+	"resolution":    {},
+	"ext":           {},
+}
+
+// NamingTokens lists the tokens the naming templates accept, in display order.
+var NamingTokens = []string{
+	"title", "title_romaji", "title_english", "season", "episode", "absolute",
+	"group", "resolution", "year", "anilist_id", "ext",
+}
+
+type namingPart struct {
+	literal string
+	token   string
+	width   int
+}
+
+type namingTemplate struct {
+	parts []namingPart
+}
+
+var reNamingWidth = regexp.MustCompile(`^0?[1-9]$`)
+
+func parseNamingTemplate(tmpl string) (*namingTemplate, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		return nil, fmt.Errorf("template is empty")
+	}
+	if strings.ContainsAny(tmpl, `/\`) {
+		return nil, fmt.Errorf("template must not contain path separators")
+	}
+	t := &namingTemplate{}
+	rest := tmpl
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if closeIdx := strings.IndexByte(rest, '}'); closeIdx >= 0 && (open < 0 || closeIdx < open) {
+			return nil, fmt.Errorf("unexpected '}' in %q", tmpl)
+		}
+		if open < 0 {
+			t.parts = append(t.parts, namingPart{literal: rest})
+			break
+		}
+		if open > 0 {
+			t.parts = append(t.parts, namingPart{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed '{' in %q", tmpl)
+		}
+		inner := rest[open+1 : open+end]
+		if strings.ContainsRune(inner, '{') {
+			return nil, fmt.Errorf("unclosed '{' in %q", tmpl)
+		}
+		name, spec, hasSpec := strings.Cut(inner, ":")
+		tok, ok := namingTokens[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown token {%s}", name)
+		}
+		part := namingPart{token: name}
+		if hasSpec {
+			if !tok.numeric {
+				return nil, fmt.Errorf("token {%s} does not take a width", name)
+			}
+			if !reNamingWidth.MatchString(spec) {
+				return nil, fmt.Errorf("invalid width %q in {%s}: use 1-9, optionally zero-prefixed ({%s:02})", spec, inner, name)
+			}
+			part.width, _ = strconv.Atoi(spec)
+		}
+		t.parts = append(t.parts, part)
+		rest = rest[open+end+1:]
+	}
+	return t, nil
+}
+
+func (t *namingTemplate) uses(token string) bool {
+	for _, p := range t.parts {
+		if p.token == token {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFolderTemplate checks a library_folder_template: known tokens, only the ones that
+// are the same for a whole anime, and at least one that tells animes apart.
+func ValidateFolderTemplate(tmpl string) error {
+	t, err := parseNamingTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+	for _, p := range t.parts {
+		if p.token != "" && !namingTokens[p.token].folder {
+			return fmt.Errorf("token {%s} changes from file to file and cannot name the anime folder", p.token)
+		}
+	}
+	if !t.uses("title") && !t.uses("title_romaji") && !t.uses("title_english") && !t.uses("anilist_id") {
+		return fmt.Errorf("folder template needs a title or {anilist_id}, or every anime would share one folder")
+	}
+	return nil
+}
+
+// ValidateFileTemplate checks a library_file_template: known tokens and an episode number, or
+// every episode of an anime would land on the same name.
+func ValidateFileTemplate(tmpl string) error {
+	t, err := parseNamingTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+	if !t.uses("episode") && !t.uses("absolute") {
+		return fmt.Errorf("file template needs {episode} or {absolute}, or every episode would get the same name")
+	}
+	return nil
+}
+
+// namingValues sao os valores dos tokens para UM arquivo. Os campos por anime vem do registro
+// (baseNamingValues); episodio, grupo, resolucao e extensao sao por arquivo (forFile).
+type namingValues struct {
+	title        string
+	titleRomaji  string
+	titleEnglish string
+	season       int
+	year         int
+	anilistID    int
+	offset       int
+
+	episode    int
+	group      string
+	resolution string
+	ext        string
+}
+
+func baseNamingValues(animeName string, animeID int, meta *AnimeMeta) namingValues {
+	v := namingValues{title: animeName, anilistID: animeID, season: 1}
+	if meta != nil {
+		v.titleRomaji = meta.TitleRomaji
+		v.titleEnglish = meta.TitleEnglish
+		if meta.Season > 0 {
+			v.season = meta.Season
+		}
+		v.year = meta.Year
+		v.offset = meta.EpisodeOffset
+	}
+	// Registro anterior ao Meta (ou titulo que a AniList nao tem): cai no nome que o registro
+	// ja usa, para o template nunca produzir uma pasta sem titulo.
+	if v.titleRomaji == "" {
+		v.titleRomaji = animeName
+	}
+	if v.titleEnglish == "" {
+		v.titleEnglish = v.titleRomaji
+	}
+	return v
+}
+
+// forFile fills the per-file values. Group and resolution come from the first name that has
+// them: the file's own name, then the torrent's.
+func (v namingValues) forFile(episode int, ext string, names ...string) namingValues {
+	v.episode = episode
+	v.ext = strings.TrimPrefix(ext, ".")
+	for _, n := range names {
+		if v.group == "" {
+			v.group = nyaa.ExtractGroup(n)
+		}
+		if v.resolution == "" {
+			v.resolution = nyaa.ExtractResolution(n)
+		}
+	}
+	return v
+}
+
+func (v namingValues) value(token string) (string, int) {
+	switch token {
+	case "title":
+		return v.title, 0
+	case "title_romaji":
+		return v.titleRomaji, 0
+	case "title_english":
+		return v.titleEnglish, 0
+	case "season":
+		return "", v.season
+	case "year":
+		return "", v.year
+	case "anilist_id":
+		return "", v.anilistID
+	case "episode":
+		return "", v.episode
+	case "absolute":
+		if v.episode <= 0 {
+			return "", 0
+		}
+		return "", v.episode + v.offset
+	case "group":
+		return v.group, 0
+	case "resolution":
+		return v.resolution, 0
+	case "ext":
+		return v.ext, 0
+	}
+	return "", 0
+}
+
+var (
+	reEmptyBrackets = regexp.MustCompile(`\(\s*\)|\[\s*\]`)
+	reMultiSpace    = regexp.MustCompile(`\s{2,}`)
+)
+
+// render preenche o template. Token sem valor (grupo ausente, ano desconhecido) vira vazio, e
+// a limpeza tira o que sobra dele: colchetes vazios, espacos dobrados e separadores nas pontas.
+// A limpeza so roda quando algum token saiu vazio: com todos preenchidos o resultado tem de ser
+// byte a byte o de antes dos templates, ou o relink moveria a pasta de todo titulo que termina
+// em ponto.
+func (t *namingTemplate) render(v namingValues) string {
+	var b strings.Builder
+	missing := false
+	for _, p := range t.parts {
+		if p.token == "" {
+			b.WriteString(p.literal)
+			continue
+		}
+		s, n := v.value(p.token)
+		if namingTokens[p.token].numeric {
+			if n > 0 {
+				b.WriteString(fmt.Sprintf("%0*d", p.width, n))
+			} else {
+				missing = true
+			}
+			continue
+		}
+		// Sem isso um titulo com "/" ("Fate/Zero") criaria uma subpasta.
+		s = sanitizeName(s)
+		missing = missing || s == ""
+		b.WriteString(s)
+	}
+	out := b.String()
+	if missing {
+		out = reEmptyBrackets.ReplaceAllString(out, "")
+		out = reMultiSpace.ReplaceAllString(out, " ")
+		out = strings.Trim(out, " -_.")
+	}
+	// Os valores ja vieram limpos; falta o que o proprio template trouxe ("{title}: E..."). Nao
+	// passa por sanitizeName de novo: ele nao e idempotente com espacos triplos.
+	return strings.TrimSpace(stripInvalidChars(out))
+}
+
+// LibraryNaming is the effective naming of the library: the two templates with their
+// defaults applied, whether files are renamed at all, whether the seasons of a series
+// share one folder, and whether movies get the movie layout (under MoviesPath, or the
+// library itself when it is empty).
+type LibraryNaming struct {
+	FolderTemplate string
+	FileTemplate   string
+	Rename         bool
+	SeasonFolders  bool
+	MovieLayout    bool
+	MoviesPath     string
+	// Subpaths sao as bibliotecas dos perfis (AccountProfile.LibrarySubpath), dentro da raiz:
+	// cada arquivo e planejado dentro da biblioteca em que ja esta.
+	Subpaths []string
+}
+
+// LibraryNaming returns the naming the config asks for; an empty template means its default.
+func (c *Config) LibraryNaming() LibraryNaming {
+	return LibraryNaming{
+		FolderTemplate: c.LibraryFolderTemplate,
+		FileTemplate:   c.LibraryFileTemplate,
+		Rename:         c.RenameFilesForJellyfin,
+		SeasonFolders:  c.LibrarySeasonFolders,
+		MovieLayout:    c.LibraryMovieLayout,
+		MoviesPath:     c.LibraryMoviesPath,
+		Subpaths:       c.librarySubpaths(),
+	}.withDefaults()
+}
+
+// Equal reports whether two namings place every file in the same spot.
+func (n LibraryNaming) Equal(o LibraryNaming) bool {
+	return n.FolderTemplate == o.FolderTemplate &&
+		n.FileTemplate == o.FileTemplate &&
+		n.Rename == o.Rename &&
+		n.SeasonFolders == o.SeasonFolders &&
+		n.MovieLayout == o.MovieLayout &&
+		n.MoviesPath == o.MoviesPath &&
+		slices.Equal(n.Subpaths, o.Subpaths)
+}
+
+func (n LibraryNaming) withDefaults() LibraryNaming {
+	if n.FolderTemplate == "" {
+		n.FolderTemplate = DefaultFolderTemplate
+	}
+	if n.FileTemplate == "" {
+		n.FileTemplate = DefaultFileTemplate
+	}
+	return n
+}
+
+// folderName e o nome da pasta do anime. Um template invalido (config.json editado a mao, o
+// PUT /config valida) ou que renderiza vazio cai no nome de antes dos templates.
+func (n LibraryNaming) folderName(v namingValues) string {
+	if t, err := parseNamingTemplate(n.FolderTemplate); err == nil {
+		if name := t.render(v); name != "" {
+			return name
+		}
+	}
+	return sanitizeName(v.title)
+}
+
+// fileName e o nome do arquivo na biblioteca, com a extensao garantida no fim. "" quando o
+// arquivo deve manter o nome cru: sem numero de episodio, ou template invalido.
+func (n LibraryNaming) fileName(v namingValues) string {
+	if v.episode <= 0 {
+		return ""
+	}
+	t, err := parseNamingTemplate(n.FileTemplate)
+	if err != nil {
+		return ""
+	}
+	name := t.render(v)
+	if name == "" {
+		return ""
+	}
+	if ext := "." + v.ext; v.ext != "" && !strings.HasSuffix(strings.ToLower(name), strings.ToLower(ext)) {
+		name += ext
+	}
+	return name
+}
+
+var reSeasonFolder = regexp.MustCompile(`^Season \d+$`)
+
+// seasonFolderName is the subfolder of one season: "Season 01", what Jellyfin and Plex read.
+func seasonFolderName(season int) string {
+	return fmt.Sprintf("Season %02d", season)
+}
+
+func isSeasonFolder(dir string) bool {
+	return reSeasonFolder.MatchString(filepath.Base(dir))
+}
+
+// LibraryShowDir e a pasta da serie (ou do filme) de um arquivo da biblioteca: sobe das pastas
+// de extras e da Season NN que o Organize cria. E a pasta que o scan do servidor de midia recebe.
+func LibraryShowDir(path string) string {
+	dir := filepath.Dir(path)
+	if isLibraryExtraDir(dir) {
+		dir = filepath.Dir(dir)
+	}
+	if isSeasonFolder(dir) {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// libraryLayout e onde os arquivos de um anime moram na biblioteca.
+type libraryLayout struct {
+	// showDir e a pasta da serie, a do tvshow.nfo; dir e a dos arquivos: a propria showDir, ou
+	// showDir/Season NN com SeasonFolders.
+	showDir string
+	dir     string
+	// showTitle e showID vao para o tvshow.nfo.
+	showTitle string
+	showID    int
+	values    namingValues
+	// shift e o Show.EpisodeOffset: o que as partes anteriores ja numeraram na mesma Season.
+	shift int
+	// movie e o layout de filme: showDir e dir sao a pasta "Titulo (Ano)", e o nfo e o
+	// movie.nfo.
+	movie bool
+}
+
+// movieFolderName e o nome que o Jellyfin le num filme: "Titulo (Ano)", sem o ano quando a
+// AniList nao o tem.
+func movieFolderName(v namingValues) string {
+	name := sanitizeName(v.title)
+	if v.year > 0 {
+		name = fmt.Sprintf("%s (%d)", name, v.year)
+	}
+	return name
+}
+
+// movieFileName e o nome de um video do filme: o da pasta, com " - partN" quando o filme vem
+// em mais de um arquivo (o que o Jellyfin junta como partes de um filme so).
+func (l libraryLayout) movieFileName(part, parts int, ext string) string {
+	name := filepath.Base(l.dir)
+	if parts > 1 {
+		name = fmt.Sprintf("%s - part%d", name, part)
+	}
+	return name + ext
+}
+
+// layout resolve pasta e valores dos tokens de um anime. Um filme com MovieLayout vai para a
+// pasta de filme, fora dos templates. Sem SeasonFolders, ou sem a serie resolvida (Meta.Show
+// nil: filme, OVA, registro ainda nao migrado), e uma pasta por entrada como antes. Com ela, os
+// tokens de pasta e o {title}/{season} do arquivo vem da serie, e o {absolute} continua o da
+// entrada.
+func (n LibraryNaming) layout(completedPath, animeName string, animeID int, meta *AnimeMeta) libraryLayout {
+	base := baseNamingValues(animeName, animeID, meta)
+	if n.MovieLayout && meta.IsMovie() {
+		root := n.MoviesPath
+		if root == "" {
+			root = completedPath
+		}
+		dir := filepath.Join(root, movieFolderName(base))
+		return libraryLayout{showDir: dir, dir: dir, showTitle: animeName, showID: animeID, values: base, movie: true}
+	}
+	if !n.SeasonFolders || meta == nil || meta.Show == nil || meta.Show.ID <= 0 {
+		dir := filepath.Join(completedPath, n.folderName(base))
+		return libraryLayout{showDir: dir, dir: dir, showTitle: animeName, showID: animeID, values: base}
+	}
+
+	show := meta.Show
+	values := baseNamingValues(show.Title, show.ID, &AnimeMeta{
+		TitleRomaji:  show.TitleRomaji,
+		TitleEnglish: show.TitleEnglish,
+		Season:       show.Season,
+		Year:         show.Year,
+		// O episodio ja chega deslocado pelo shift; o {absolute} tem de dar o mesmo de antes.
+		EpisodeOffset: meta.EpisodeOffset - show.EpisodeOffset,
+	})
+	showDir := filepath.Join(completedPath, n.folderName(values))
+	return libraryLayout{
+		showDir:   showDir,
+		dir:       filepath.Join(showDir, seasonFolderName(values.season)),
+		showTitle: show.Title,
+		showID:    show.ID,
+		values:    values,
+		shift:     show.EpisodeOffset,
+	}
+}
+
+// episode leva o numero do episodio na entrada para o numero na Season.
+func (l libraryLayout) episode(entry int) int {
+	if entry <= 0 {
+		return entry
+	}
+	return entry + l.shift
+}
+
+// entryEpisode e o inverso para um numero lido do nome do arquivo (pack, relink), que pode ja
+// vir contado na temporada — o fansub que segue a numeracao do cour anterior, ou o arquivo que
+// o relink ja renomeou —: acima do shift ele e tratado como numero da Season.
+func (l libraryLayout) entryEpisode(n int) int {
+	if l.shift > 0 && n > l.shift {
+		return n - l.shift
+	}
+	return n
+}
+
+// LibraryFileEpisode tells which episode of its AniList entry a library file of a batch is,
+// read from the file's name the way PlanLibraryMoves reads it. ok is false for a file
+// Organize put in an extras/, trailers/ or Specials/ folder, for a movie's videos, and for a
+// name without an episode number.
+func LibraryFileEpisode(ep EpisodeStruct, path string, naming LibraryNaming) (episode int, ok bool) {
+	if libraryExtraClass(path).kind != extraNone {
+		return 0, false
+	}
+	// So o shift importa aqui, e ele nao depende da raiz: a biblioteca do perfil serve igual.
+	layout := naming.withDefaults().layout("", ep.AnimeName, ep.AnimeID, ep.Meta)
+	if layout.movie {
+		return 0, false
+	}
+	n := nyaa.ExtractEpisodeNumber(filepath.Base(path))
+	if n == nil || *n <= 0 {
+		return 0, false
+	}
+	return layout.entryEpisode(*n), true
+}
+
+// LibraryMove is one organized library file and where the naming puts it. From == To when
+// the file is already where it belongs.
+type LibraryMove struct {
+	Hash          string `json:"hash"`
+	AnimeID       int    `json:"anime_id"`
+	AnimeName     string `json:"anime_name"`
+	EpisodeNumber int    `json:"episode_number,omitempty"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	// ShowDir, ShowTitle e ShowID sao o tvshow.nfo do destino; o relink garante o nfo na
+	// pasta raiz da serie quando SeasonFolders cria uma.
+	ShowDir   string `json:"-"`
+	ShowTitle string `json:"-"`
+	ShowID    int    `json:"-"`
+	// Season e EntryEpisode numeram o nfo do episodio: a Season do layout, e o numero do
+	// episodio na propria entrada da AniList (EpisodeNumber e o da Season).
+	Season       int `json:"-"`
+	EntryEpisode int `json:"-"`
+	// Movie marca o layout de filme: ShowDir e a pasta do filme, com movie.nfo no lugar do
+	// tvshow.nfo e sem nfo de episodio.
+	Movie bool `json:"-"`
+}
+
+// PlanLibraryMoves computes, for every organized episode, where its library files belong
+// under naming. It reads only the records — no disk, no torrent — so the preview and the
+// relink job agree by construction.
+//
+// The file's own episode number comes from the record for a single episode, and from the
+// current library name for a batch (what Organize did with the torrent's file name). Without
+// Rename, or without a readable number, a file keeps its current name and only changes folder:
+// the raw torrent name of a file already renamed is not recoverable. Two files planned onto
+// the same path keep their current names. A batch file Organize put in an extras/, trailers/
+// or Specials/ folder moves to that folder under the new layout, with no episode number; one
+// organized before those folders existed stays an episode, so the default naming still moves
+// nothing. With MovieLayout a movie's videos become the parts of the movie in its own
+// folder, and its Specials/ go to extras/.
+func PlanLibraryMoves(episodes []EpisodeStruct, completedPath string, naming LibraryNaming) []LibraryMove {
+	naming = naming.withDefaults()
+
+	// O grupo e o torrent DENTRO de uma biblioteca: o mesmo torrent linkado em dois perfis e
+	// planejado uma vez em cada um, cada um com a sua raiz.
+	type group struct {
+		first EpisodeStruct
+		size  int
+		sub   string
+		paths []string
+	}
+	var order []string
+	groups := make(map[string]*group)
+	for _, ep := range episodes {
+		if len(ep.LibraryPaths) == 0 {
+			continue
+		}
+		key := ep.EpisodeHash
+		if key == "" {
+			key = fmt.Sprintf("%d/%d", ep.AnimeID, ep.EpisodeNumber)
+		}
+		bySub := make(map[string][]string)
+		var subs []string
+		for _, path := range ep.LibraryPaths {
+			sub, _ := librarySubpathOf(path, completedPath, naming.MoviesPath, naming.Subpaths)
+			if _, ok := bySub[sub]; !ok {
+				subs = append(subs, sub)
+			}
+			bySub[sub] = append(bySub[sub], path)
+		}
+		for _, sub := range subs {
+			gk := key + "\x00" + sub
+			if g, ok := groups[gk]; ok {
+				g.size++
+				continue
+			}
+			groups[gk] = &group{first: ep, size: 1, sub: sub, paths: bySub[sub]}
+			order = append(order, gk)
+		}
+	}
+
+	used := make(map[string]bool)
+	seen := make(map[string]bool)
+	var moves []LibraryMove
+	for _, key := range order {
+		g := groups[key]
+		ep := g.first
+		rootNaming := naming
+		if g.sub != "" && naming.MoviesPath != "" {
+			rootNaming.MoviesPath = filepath.Join(naming.MoviesPath, g.sub)
+		}
+		layout := rootNaming.layout(filepath.Join(completedPath, g.sub), ep.AnimeName, ep.AnimeID, ep.Meta)
+		destDir := layout.dir
+		single := !ep.IsBatch && g.size == 1 && len(g.paths) == 1
+		parts, part := 0, 0
+		if layout.movie {
+			for _, from := range g.paths {
+				if single || libraryExtraClass(from).kind == extraNone {
+					parts++
+				}
+			}
+		}
+		for _, from := range g.paths {
+			if seen[from] {
+				continue
+			}
+			seen[from] = true
+			current := filepath.Base(from)
+			ext := filepath.Ext(current)
+
+			if class := libraryExtraClass(from); !single && class.kind != extraNone {
+				if layout.movie {
+					class = movieExtraClass(class)
+				}
+				dir := layout.extraDir(class.kind)
+				name := current
+				if class.kind == extraSpecial && naming.Rename {
+					if sp := layout.specialFileName(class.number, ext); sp != "" && !used[filepath.Join(dir, sp)] {
+						name = sp
+					}
+				}
+				to := filepath.Join(dir, name)
+				if used[to] {
+					to = from
+				}
+				used[to] = true
+				moves = append(moves, LibraryMove{
+					Hash:      ep.EpisodeHash,
+					AnimeID:   ep.AnimeID,
+					AnimeName: ep.AnimeName,
+					From:      from,
+					To:        to,
+					ShowDir:   layout.showDir,
+					ShowTitle: layout.showTitle,
+					ShowID:    layout.showID,
+					Season:    layout.values.season,
+					Movie:     layout.movie,
+				})
+				continue
+			}
+
+			if layout.movie {
+				part++
+				name := current
+				if naming.Rename {
+					if mf := layout.movieFileName(part, parts, ext); !used[filepath.Join(destDir, mf)] {
+						name = mf
+					}
+				}
+				to := filepath.Join(destDir, name)
+				if used[to] {
+					to = from
+				}
+				used[to] = true
+				moves = append(moves, LibraryMove{
+					Hash:      ep.EpisodeHash,
+					AnimeID:   ep.AnimeID,
+					AnimeName: ep.AnimeName,
+					From:      from,
+					To:        to,
+					ShowDir:   layout.showDir,
+					ShowTitle: layout.showTitle,
+					ShowID:    layout.showID,
+					Movie:     true,
+				})
+				continue
+			}
+
+			name := current
+			entry := 0
+			if single {
+				entry = ep.EpisodeNumber
+			} else if n := nyaa.ExtractEpisodeNumber(current); n != nil {
+				entry = layout.entryEpisode(*n)
+			}
+			episode := layout.episode(entry)
+			if naming.Rename {
+				if jf := naming.fileName(layout.values.forFile(episode, ext, current, ep.TorrentName)); jf != "" && !used[filepath.Join(destDir, jf)] {
+					name = jf
+				}
+			}
+			to := filepath.Join(destDir, name)
+			if used[to] {
+				to = from
+			}
+			used[to] = true
+
+			moves = append(moves, LibraryMove{
+				Hash:          ep.EpisodeHash,
+				AnimeID:       ep.AnimeID,
+				AnimeName:     ep.AnimeName,
+				EpisodeNumber: episode,
+				From:          from,
+				To:            to,
+				ShowDir:       layout.showDir,
+				ShowTitle:     layout.showTitle,
+				ShowID:        layout.showID,
+				Season:        layout.values.season,
+				EntryEpisode:  entry,
+			})
+		}
+	}
+	return moves
+}
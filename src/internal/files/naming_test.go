@@ -0,0 +1,478 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateNamingTemplates(t *testing.T) {
+	folder := []struct {
+		tmpl    string
+		wantErr string
+	}{
+		{"{title}", ""},
+		{"{title_romaji} ({year}) [anilist-{anilist_id}]", ""},
+		{"{anilist_id}", ""},
+		{"", "empty"},
+		{"Season {season}", "needs a title"},
+		{"{title} - {episode}", "changes from file to file"},
+		{"{title}/{season}", "path separators"},
+		{"{titel}", "unknown token"},
+		{"{title", "unclosed"},
+		{"title}", "unexpected"},
+		{"{title:02}", "does not take a width"},
+	}
+	for _, c := range folder {
+		err := ValidateFolderTemplate(c.tmpl)
+		if c.wantErr == "" && err != nil {
+			t.Errorf("ValidateFolderTemplate(%q) = %v, want nil", c.tmpl, err)
+		}
+		if c.wantErr != "" && (err == nil || !strings.Contains(err.Error(), c.wantErr)) {
+			t.Errorf("ValidateFolderTemplate(%q) = %v, want error containing %q", c.tmpl, err, c.wantErr)
+		}
+	}
+
+	file := []struct {
+		tmpl    string
+		wantErr string
+	}{
+		{DefaultFileTemplate, ""},
+		{"{title} - S{season:02}E{episode:02} [{group}][{resolution}].{ext}", ""},
+		{"{title_english} - {absolute:3}", ""},
+		{"{title} [{group}]", "needs {episode} or {absolute}"},
+		{"{title} - E{episode:10}", "invalid width"},
+		{"{title} - E{episode:00}", "invalid width"},
+		{"{title} - {{episode}}", "unclosed"},
+	}
+	for _, c := range file {
+		err := ValidateFileTemplate(c.tmpl)
+		if c.wantErr == "" && err != nil {
+			t.Errorf("ValidateFileTemplate(%q) = %v, want nil", c.tmpl, err)
+		}
+		if c.wantErr != "" && (err == nil || !strings.Contains(err.Error(), c.wantErr)) {
+			t.Errorf("ValidateFileTemplate(%q) = %v, want error containing %q", c.tmpl, err, c.wantErr)
+		}
+	}
+}
+
+// The defaults must name exactly what Organize named before the templates, or the first
+// relink after an upgrade would move every library folder.
+func TestDefaultNamingReproducesLegacyNames(t *testing.T) {
+	naming := LibraryNaming{Rename: true}.withDefaults()
+	for _, title := range []string{"My Anime", "Fate/Zero", "Re:Zero  Season 2", "Dr. Stone.", "  Spaced  ", "Three   Spaces"} {
+		v := baseNamingValues(title, 1, nil)
+		if got, want := naming.folderName(v), sanitizeName(title); got != want {
+			t.Errorf("folderName(%q) = %q, want %q", title, got, want)
+		}
+		if got, want := naming.fileName(v.forFile(5, ".mkv")), jellyfinName(title, 5, ".mkv"); got != want {
+			t.Errorf("fileName(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestRenderNamingTemplate(t *testing.T) {
+	meta := &AnimeMeta{TitleRomaji: "Shingeki no Kyojin", TitleEnglish: "Attack on Titan", Season: 3, Year: 2019, EpisodeOffset: 12}
+	base := baseNamingValues("Attack on Titan Season 3 Part 2", 104578, meta)
+	file := "[SubsPlease] Shingeki no Kyojin S3 - 05 (1080p) [ABCD1234].mkv"
+
+	cases := []struct {
+		name   string
+		tmpl   string
+		values namingValues
+		want   string
+	}{
+		{"all tokens", "{title_romaji} - S{season:02}E{episode:02} - {absolute} [{group}][{resolution}]", base.forFile(5, ".mkv", file), "Shingeki no Kyojin - S03E05 - 17 [SubsPlease][1080p].mkv"},
+		{"ext token keeps a single extension", "{title_english} {episode:3}.{ext}", base.forFile(5, ".mkv"), "Attack on Titan 005.mkv"},
+		{"group falls back to the torrent name", "{episode} [{group}]", base.forFile(5, ".mkv", "05.mkv", "[Erai-raws] Batch"), "5 [Erai-raws].mkv"},
+		{"missing tokens leave no empty brackets", "{title_romaji} ({year}) - E{episode:02} [{group}]", baseNamingValues("X", 1, nil).forFile(2, ".mkv"), "X - E02.mkv"},
+		{"invalid characters in values are stripped", "{title} - {episode}", baseNamingValues("Fate/Zero: Part?", 1, nil).forFile(1, ".mkv"), "FateZero Part - 1.mkv"},
+	}
+	for _, c := range cases {
+		naming := LibraryNaming{FileTemplate: c.tmpl, Rename: true}.withDefaults()
+		if got := naming.fileName(c.values); got != c.want {
+			t.Errorf("%s: fileName = %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	folder := LibraryNaming{FolderTemplate: "{title_romaji} ({year}) [{anilist_id}]"}.withDefaults()
+	if got := folder.folderName(base); got != "Shingeki no Kyojin (2019) [104578]" {
+		t.Errorf("folderName = %q", got)
+	}
+	if got := folder.folderName(baseNamingValues("Old Record", 7, nil)); got != "Old Record [7]" {
+		t.Errorf("folderName without meta = %q, want the record name and no empty year", got)
+	}
+}
+
+func TestPlanLibraryMoves(t *testing.T) {
+	root := filepath.Join("lib")
+	episodes := []EpisodeStruct{
+		{
+			AnimeID: 1, AnimeName: "Show", EpisodeHash: "h1", EpisodeNumber: 3,
+			TorrentName:  "[SubsPlease] Show - 03 (1080p).mkv",
+			Meta:         &AnimeMeta{Year: 2024},
+			LibraryPaths: []string{filepath.Join(root, "Show", "Show - E03.mkv")},
+		},
+		// A batch: two records sharing the hash and the library files.
+		{
+			AnimeID: 2, AnimeName: "Pack", EpisodeHash: "h2", EpisodeNumber: 1, IsBatch: true,
+			LibraryPaths: []string{filepath.Join(root, "Pack", "Pack - E01.mkv"), filepath.Join(root, "Pack", "NCOP.mkv")},
+		},
+		{
+			AnimeID: 2, AnimeName: "Pack", EpisodeHash: "h2", EpisodeNumber: 2, IsBatch: true,
+			LibraryPaths: []string{filepath.Join(root, "Pack", "Pack - E01.mkv"), filepath.Join(root, "Pack", "NCOP.mkv")},
+		},
+		// Not organized yet: not part of the plan.
+		{AnimeID: 3, AnimeName: "Pending", EpisodeHash: "h3", EpisodeNumber: 1},
+	}
+
+	// Defaults: nothing moves.
+	for _, mv := range PlanLibraryMoves(episodes, root, LibraryNaming{Rename: true}) {
+		if mv.From != mv.To {
+			t.Errorf("default naming moves %s -> %s", mv.From, mv.To)
+		}
+	}
+
+	naming := LibraryNaming{FolderTemplate: "{title} ({year})", FileTemplate: "{title} - E{episode:02} [{resolution}]", Rename: true}
+	moves := PlanLibraryMoves(episodes, root, naming)
+	want := map[string]string{
+		filepath.Join(root, "Show", "Show - E03.mkv"): filepath.Join(root, "Show (2024)", "Show - E03 [1080p].mkv"),
+		filepath.Join(root, "Pack", "Pack - E01.mkv"): filepath.Join(root, "Pack", "Pack - E01.mkv"),
+		// No readable number: keeps its name.
+		filepath.Join(root, "Pack", "NCOP.mkv"): filepath.Join(root, "Pack", "NCOP.mkv"),
+	}
+	if len(moves) != len(want) {
+		t.Fatalf("got %d moves, want %d: %+v", len(moves), len(want), moves)
+	}
+	for _, mv := range moves {
+		if want[mv.From] != mv.To {
+			t.Errorf("move %s -> %s, want -> %s", mv.From, mv.To, want[mv.From])
+		}
+	}
+
+	// Without renaming, files keep their names and only follow the folder.
+	moves = PlanLibraryMoves(episodes[:1], root, LibraryNaming{FolderTemplate: "{title} ({year})"})
+	if len(moves) != 1 || moves[0].To != filepath.Join(root, "Show (2024)", "Show - E03.mkv") {
+		t.Errorf("plan without rename = %+v", moves)
+	}
+}
+
+// Two files planned onto the same name keep their current names instead of overwriting each
+// other.
+func TestPlanLibraryMovesCollision(t *testing.T) {
+	episodes := []EpisodeStruct{
+		{AnimeID: 1, AnimeName: "Show", EpisodeHash: "a", EpisodeNumber: 1, LibraryPaths: []string{"lib/Show/a.mkv"}},
+		{AnimeID: 1, AnimeName: "Show", EpisodeHash: "b", EpisodeNumber: 1, LibraryPaths: []string{"lib/Show/b.mkv"}},
+	}
+	moves := PlanLibraryMoves(episodes, "lib", LibraryNaming{FileTemplate: "{title} {episode}", Rename: true})
+	if len(moves) != 2 {
+		t.Fatalf("moves = %+v", moves)
+	}
+	if moves[0].To != filepath.Join("lib", "Show", "Show 1.mkv") || moves[1].To != moves[1].From {
+		t.Errorf("moves = %+v, want the second file to stay put", moves)
+	}
+}
+
+func TestOrganizeUsesNamingTemplates(t *testing.T) {
+	tmp := t.TempDir()
+	dataDir := filepath.Join(tmp, "save", "torrentid")
+	completed := filepath.Join(tmp, "completed")
+	writeFile(t, filepath.Join(dataDir, "[Group] Show - 04 (720p).mkv"), "video")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	created, err := lib.Organize(OrganizeRequest{
+		TorrentDataDir: dataDir,
+		AnimeName:      "Show",
+		CompletedPath:  completed,
+		EpisodeNumber:  intPtr(4),
+		RenameJellyfin: true,
+		FolderTemplate: "{title_romaji} ({year})",
+		FileTemplate:   "{title_romaji} S{season:02}E{episode:02} [{group}][{resolution}]",
+		Meta:           &AnimeMeta{TitleRomaji: "Sho", Season: 2, Year: 2023},
+	})
+	if err != nil {
+		t.Fatalf("Organize: %v", err)
+	}
+	want := filepath.Join(completed, "Sho (2023)", "Sho S02E04 [Group][720p].mkv")
+	if len(created) != 1 || created[0] != want {
+		t.Fatalf("created = %v, want [%s]", created, want)
+	}
+}
+
+func TestMoveInLibrary(t *testing.T) {
+	tmp := t.TempDir()
+	oldDir := filepath.Join(tmp, "Show")
+	newDir := filepath.Join(tmp, "Show (2024)")
+	from := filepath.Join(oldDir, "Show - E01.mkv")
+	to := filepath.Join(newDir, "Show - E01 [1080p].mkv")
+	writeFile(t, from, "video")
+	writeFile(t, filepath.Join(oldDir, "tvshow.nfo"), "<tvshow>edited</tvshow>")
+	writeFile(t, filepath.Join(oldDir, PosterFileName), "poster")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	if err := lib.MoveInLibrary(from, to); err != nil {
+		t.Fatalf("MoveInLibrary: %v", err)
+	}
+	if _, err := os.Stat(to); err != nil {
+		t.Fatalf("destination missing: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(newDir, "tvshow.nfo")); err != nil || string(data) != "<tvshow>edited</tvshow>" {
+		t.Errorf("tvshow.nfo not carried over: %q, %v", data, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(newDir, PosterFileName)); err != nil || string(data) != "poster" {
+		t.Errorf("poster not carried over: %q, %v", data, err)
+	}
+	if _, err := os.Stat(oldDir); !os.IsNotExist(err) {
+		t.Errorf("old folder left behind: %v", err)
+	}
+
+	// Idempotent: the move already happened.
+	if err := lib.MoveInLibrary(from, to); err != nil {
+		t.Errorf("second MoveInLibrary: %v", err)
+	}
+
+	// A different file already at the destination is never overwritten.
+	other := filepath.Join(newDir, "other.mkv")
+	writeFile(t, other, "other")
+	if err := lib.MoveInLibrary(other, to); err == nil {
+		t.Error("MoveInLibrary overwrote a different file")
+	}
+	if data, _ := os.ReadFile(to); string(data) != "video" {
+		t.Errorf("destination content = %q", data)
+	}
+}
+
+// A folder that still holds something the relink did not move (another episode, a subtitle)
+// stays, with its nfo.
+func TestMoveInLibraryKeepsNonEmptyFolder(t *testing.T) {
+	tmp := t.TempDir()
+	oldDir := filepath.Join(tmp, "Show")
+	writeFile(t, filepath.Join(oldDir, "Show - E01.mkv"), "one")
+	writeFile(t, filepath.Join(oldDir, "Show - E02.en.srt"), "subs")
+	writeFile(t, filepath.Join(oldDir, "tvshow.nfo"), "nfo")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	if err := lib.MoveInLibrary(filepath.Join(oldDir, "Show - E01.mkv"), filepath.Join(tmp, "New", "Show - E01.mkv")); err != nil {
+		t.Fatalf("MoveInLibrary: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(oldDir, "tvshow.nfo")); err != nil {
+		t.Errorf("nfo removed from a folder that still has files: %v", err)
+	}
+}
+
+// With SeasonFolders the two cours of a split season land in one Season folder of the series,
+// the second numbered after the first, and the nfo at the root is the series'.
+func TestOrganizeSeasonFolders(t *testing.T) {
+	tmp := t.TempDir()
+	dataDir := filepath.Join(tmp, "save", "torrentid")
+	completed := filepath.Join(tmp, "completed")
+	writeFile(t, filepath.Join(dataDir, "[Group] Show S3 Part 2 - 01 (1080p).mkv"), "video")
+
+	show := &ShowMeta{ID: 100, Title: "Show", Year: 2013, Season: 3, EpisodeOffset: 10}
+	lib := NewLibrarian(NewOSFileSystem())
+	created, err := lib.Organize(OrganizeRequest{
+		TorrentDataDir: dataDir,
+		AnimeName:      "Show Season 3 Part 2",
+		AnimeID:        300,
+		CompletedPath:  completed,
+		EpisodeNumber:  intPtr(1),
+		RenameJellyfin: true,
+		FolderTemplate: "{title} ({year})",
+		FileTemplate:   "{title} - S{season:02}E{episode:02} ({absolute})",
+		SeasonFolders:  true,
+		Meta:           &AnimeMeta{Season: 3, Year: 2019, EpisodeOffset: 47, Show: show},
+	})
+	if err != nil {
+		t.Fatalf("Organize: %v", err)
+	}
+	want := filepath.Join(completed, "Show (2013)", "Season 03", "Show - S03E11 (48).mkv")
+	if len(created) != 1 || created[0] != want {
+		t.Fatalf("created = %v, want [%s]", created, want)
+	}
+	data, err := os.ReadFile(filepath.Join(completed, "Show (2013)", "tvshow.nfo"))
+	if err != nil || !strings.Contains(string(data), ">100<") {
+		t.Errorf("series tvshow.nfo = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(completed, "Show (2013)", "Season 03", "tvshow.nfo")); !os.IsNotExist(err) {
+		t.Errorf("season folder must not get an nfo: %v", err)
+	}
+}
+
+func TestPlanLibraryMovesSeasonFolders(t *testing.T) {
+	root := "lib"
+	show := &ShowMeta{ID: 1, Title: "Show", Season: 2, EpisodeOffset: 12}
+	episodes := []EpisodeStruct{
+		{AnimeID: 2, AnimeName: "Show Part 2", EpisodeHash: "a", EpisodeNumber: 1, Meta: &AnimeMeta{Show: show},
+			LibraryPaths: []string{filepath.Join(root, "Show Part 2", "Show Part 2 - E01.mkv")}},
+		// A batch numbered after the first cour by the fansub.
+		{AnimeID: 2, AnimeName: "Show Part 2", EpisodeHash: "b", IsBatch: true, Meta: &AnimeMeta{Show: show},
+			LibraryPaths: []string{filepath.Join(root, "Show Part 2", "Show Part 2 - E14.mkv")}},
+		// Not a season (a movie): stays in its own folder.
+		{AnimeID: 9, AnimeName: "Show Movie", EpisodeHash: "c", EpisodeNumber: 1, Meta: &AnimeMeta{},
+			LibraryPaths: []string{filepath.Join(root, "Show Movie", "Show Movie - E01.mkv")}},
+	}
+	naming := LibraryNaming{FileTemplate: "{title} - S{season:02}E{episode:02}", Rename: true, SeasonFolders: true}
+	moves := PlanLibraryMoves(episodes, root, naming)
+	want := []string{
+		filepath.Join(root, "Show", "Season 02", "Show - S02E13.mkv"),
+		filepath.Join(root, "Show", "Season 02", "Show - S02E14.mkv"),
+		filepath.Join(root, "Show Movie", "Show Movie - S01E01.mkv"),
+	}
+	if len(moves) != len(want) {
+		t.Fatalf("moves = %+v", moves)
+	}
+	for i, mv := range moves {
+		if mv.To != want[i] {
+			t.Errorf("move %d -> %s, want %s", i, mv.To, want[i])
+		}
+	}
+	if moves[0].ShowDir != filepath.Join(root, "Show") || moves[0].ShowID != 1 {
+		t.Errorf("show of the move = %q/%d", moves[0].ShowDir, moves[0].ShowID)
+	}
+
+	// The plan is stable: the files already in place do not move again.
+	episodes[0].LibraryPaths = []string{want[0]}
+	episodes[1].LibraryPaths = []string{want[1]}
+	for _, mv := range PlanLibraryMoves(episodes[:2], root, naming) {
+		if mv.From != mv.To {
+			t.Errorf("second plan moves %s -> %s", mv.From, mv.To)
+		}
+	}
+}
+
+// Turning the option off moves the files back out and removes the emptied series folder.
+func TestMoveInLibraryOutOfSeasonFolder(t *testing.T) {
+	tmp := t.TempDir()
+	showDir := filepath.Join(tmp, "Show")
+	from := filepath.Join(showDir, "Season 02", "Show - S02E01.mkv")
+	to := filepath.Join(tmp, "Show Season 2", "Show Season 2 - E01.mkv")
+	writeFile(t, from, "video")
+	writeFile(t, filepath.Join(showDir, "tvshow.nfo"), "<tvshow>series</tvshow>")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	if err := lib.MoveInLibrary(from, to); err != nil {
+		t.Fatalf("MoveInLibrary: %v", err)
+	}
+	if _, err := os.Stat(showDir); !os.IsNotExist(err) {
+		t.Errorf("emptied series folder left behind: %v", err)
+	}
+	lib.EnsureShowNFO(filepath.Dir(to), "Show Season 2", 2)
+	if data, err := os.ReadFile(filepath.Join(filepath.Dir(to), "tvshow.nfo")); err != nil || !strings.Contains(string(data), ">2<") {
+		t.Errorf("entry tvshow.nfo = %q, %v", data, err)
+	}
+}
+
+// With MovieLayout a movie goes to "Title (Year)" under the movies path, named after the
+// folder, with its extras next to it and a movie.nfo instead of a tvshow.nfo.
+func TestOrganizeMovieLayout(t *testing.T) {
+	tmp := t.TempDir()
+	dataDir := filepath.Join(tmp, "save", "movieid")
+	completed := filepath.Join(tmp, "completed")
+	movies := filepath.Join(tmp, "movies")
+	writeFile(t, filepath.Join(dataDir, "[Group] Kimi no Na wa [BD 1080p].mkv"), "movie")
+	writeFile(t, filepath.Join(dataDir, "Extras", "[Group] Kimi no Na wa - PV1 [BD 1080p].mkv"), "pv")
+	writeFile(t, filepath.Join(dataDir, "[Group] Kimi no Na wa - SP1 [BD 1080p].mkv"), "special")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	created, err := lib.Organize(OrganizeRequest{
+		TorrentDataDir: dataDir,
+		AnimeName:      "Kimi no Na wa.",
+		AnimeID:        21519,
+		CompletedPath:  completed,
+		IsBatch:        true,
+		RenameJellyfin: true,
+		MovieLayout:    true,
+		MoviesPath:     movies,
+		Meta:           &AnimeMeta{Year: 2016, Format: "MOVIE"},
+	})
+	if err != nil {
+		t.Fatalf("Organize: %v", err)
+	}
+	dir := filepath.Join(movies, "Kimi no Na wa. (2016)")
+	want := []string{
+		filepath.Join(dir, "Kimi no Na wa. (2016).mkv"),
+		filepath.Join(dir, "trailers", "[Group] Kimi no Na wa - PV1 [BD 1080p].mkv"),
+		filepath.Join(dir, "extras", "[Group] Kimi no Na wa - SP1 [BD 1080p].mkv"),
+	}
+	if len(created) != len(want) {
+		t.Fatalf("created = %v, want %v", created, want)
+	}
+	for _, p := range want {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("expected %s: %v", p, err)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "movie.nfo")); err != nil || !strings.Contains(string(data), "<movie>") || !strings.Contains(string(data), ">21519<") {
+		t.Errorf("movie.nfo = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tvshow.nfo")); !os.IsNotExist(err) {
+		t.Errorf("movie folder must not get a tvshow.nfo: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(completed, "Kimi no Na wa.")); !os.IsNotExist(err) {
+		t.Errorf("no series folder expected in the library: %v", err)
+	}
+}
+
+// A movie organized as a series stays put until MovieLayout is turned on; then the relink
+// moves it into the movie folder, and the metadata job swaps the tvshow.nfo for a movie.nfo.
+func TestMovieLayoutMigration(t *testing.T) {
+	tmp := t.TempDir()
+	completed := filepath.Join(tmp, "completed")
+	movies := filepath.Join(tmp, "movies")
+	oldDir := filepath.Join(completed, "Movie")
+	from := filepath.Join(oldDir, "Movie - E01.mkv")
+	writeFile(t, from, "movie")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	lib.EnsureShowNFO(oldDir, "Movie", 5)
+	episodes := []EpisodeStruct{
+		{AnimeID: 5, AnimeName: "Movie", EpisodeHash: "m", EpisodeNumber: 1, Meta: &AnimeMeta{Year: 2020, Format: "MOVIE"},
+			LibraryPaths: []string{from}},
+		{AnimeID: 6, AnimeName: "Show", EpisodeHash: "s", EpisodeNumber: 1, Meta: &AnimeMeta{Format: "TV"},
+			LibraryPaths: []string{filepath.Join(completed, "Show", "Show - E01.mkv")}},
+	}
+	for _, mv := range PlanLibraryMoves(episodes, completed, LibraryNaming{Rename: true, MoviesPath: movies}) {
+		if mv.From != mv.To || mv.Movie {
+			t.Errorf("without MovieLayout %s -> %s (movie %v)", mv.From, mv.To, mv.Movie)
+		}
+	}
+
+	naming := LibraryNaming{Rename: true, MovieLayout: true, MoviesPath: movies}
+	moves := PlanLibraryMoves(episodes, completed, naming)
+	to := filepath.Join(movies, "Movie (2020)", "Movie (2020).mkv")
+	if len(moves) != 2 || moves[0].To != to || !moves[0].Movie || moves[0].ShowDir != filepath.Dir(to) || moves[0].EpisodeNumber != 0 {
+		t.Fatalf("moves = %+v", moves)
+	}
+	if moves[1].From != moves[1].To || moves[1].Movie {
+		t.Errorf("series moved by the movie layout: %+v", moves[1])
+	}
+
+	if err := lib.MoveInLibrary(from, to); err != nil {
+		t.Fatalf("MoveInLibrary: %v", err)
+	}
+	if _, err := os.Stat(oldDir); !os.IsNotExist(err) {
+		t.Errorf("old series folder left behind: %v", err)
+	}
+	episodes[0].LibraryPaths = []string{to}
+	moves = PlanLibraryMoves(episodes[:1], completed, naming)
+	if moves[0].From != moves[0].To {
+		t.Fatalf("second plan moves %s -> %s", moves[0].From, moves[0].To)
+	}
+	if !lib.MissingNFOs(moves) {
+		t.Fatal("MissingNFOs = false without a movie.nfo")
+	}
+	lib.WriteNFOs(moves, &NFOInfo{Show: ShowInfo{OriginalTitle: "Eiga", Year: 2020}})
+	nfo := readNFO(t, filepath.Join(filepath.Dir(to), "movie.nfo"))
+	if !strings.Contains(nfo, "<title>Movie</title>") || !strings.Contains(nfo, "<year>2020</year>") || !strings.Contains(nfo, ">5<") {
+		t.Errorf("movie.nfo = %s", nfo)
+	}
+	for _, name := range []string{"tvshow.nfo", "Movie (2020).nfo"} {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(to), name)); !os.IsNotExist(err) {
+			t.Errorf("%s in the movie folder: %v", name, err)
+		}
+	}
+	if lib.MissingNFOs(moves) {
+		t.Error("MissingNFOs = true after WriteNFOs")
+	}
+}
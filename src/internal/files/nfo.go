@@ -0,0 +1,289 @@
+package files
+
+import (
+	"AutoAnimeDownloader/src/internal/logger"
+
+	"bytes"
+	"encoding/xml"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nfoMarker vai na segunda linha de todo .nfo que o daemon escreve. Um nfo com ele e nosso e
+// pode ser regerado (dados novos da AniList, numeracao nova depois de um relink); sem ele o
+// arquivo e do usuario e nunca e tocado. Apagar a linha e como o usuario assume um nfo gerado.
+const nfoMarker = "<!-- Generated by AutoAnimeDownloader. Delete this line to keep manual edits. -->"
+
+// movieNFOName e o nfo da pasta de um filme no layout de filme.
+const movieNFOName = "movie.nfo"
+
+// ShowInfo is the AniList data of a series for its tvshow.nfo (or of a movie, for its
+// movie.nfo).
+// The <title> stays the name the library uses for the series.
+type ShowInfo struct {
+	OriginalTitle string
+	Synonyms      []string
+	Genres        []string
+	Studios       []string
+	// Status is the AniList media status (RELEASING, FINISHED, ...).
+	Status string
+	Year   int
+	Plot   string
+	// MyAnimeListID, AniDBID and KitsuID come from the offline id mapping (idmap); 0 when it
+	// does not know the media. Each one is one more <uniqueid>, for the metadata plugins of
+	// those sites.
+	MyAnimeListID int
+	AniDBID       int
+	KitsuID       int
+}
+
+// EpisodeInfo is the AniList data of one episode. Both fields are optional: AniList only has
+// titles for episodes on an official streaming site, and dates inside its schedule window.
+type EpisodeInfo struct {
+	Title string
+	Aired time.Time
+}
+
+// NFOInfo is what the .nfo files carry beyond numbers and the AniList id: the series of the
+// library folder, and the entry's episodes keyed by the entry's own episode number.
+type NFOInfo struct {
+	Show     ShowInfo
+	Episodes map[int]EpisodeInfo
+}
+
+type nfoUniqueID struct {
+	Type    string `xml:"type,attr"`
+	Default bool   `xml:"default,attr,omitempty"`
+	Value   string `xml:",chardata"`
+}
+
+type nfoTVShow struct {
+	XMLName       xml.Name      `xml:"tvshow"`
+	Title         string        `xml:"title"`
+	OriginalTitle string        `xml:"originaltitle,omitempty"`
+	Plot          string        `xml:"plot,omitempty"`
+	Year          int           `xml:"year,omitempty"`
+	Status        string        `xml:"status,omitempty"`
+	Genres        []string      `xml:"genre"`
+	Studios       []string      `xml:"studio"`
+	Tags          []string      `xml:"tag"`
+	UniqueIDs     []nfoUniqueID `xml:"uniqueid"`
+}
+
+// nfoMovie e o movie.nfo: os campos da serie que fazem sentido num filme.
+type nfoMovie struct {
+	XMLName       xml.Name      `xml:"movie"`
+	Title         string        `xml:"title"`
+	OriginalTitle string        `xml:"originaltitle,omitempty"`
+	Plot          string        `xml:"plot,omitempty"`
+	Year          int           `xml:"year,omitempty"`
+	Genres        []string      `xml:"genre"`
+	Studios       []string      `xml:"studio"`
+	Tags          []string      `xml:"tag"`
+	UniqueIDs     []nfoUniqueID `xml:"uniqueid"`
+}
+
+type nfoEpisode struct {
+	XMLName   xml.Name    `xml:"episodedetails"`
+	Title     string      `xml:"title"`
+	ShowTitle string      `xml:"showtitle,omitempty"`
+	Season    int         `xml:"season"`
+	Episode   int         `xml:"episode"`
+	Aired     string      `xml:"aired,omitempty"`
+	UniqueID  nfoUniqueID `xml:"uniqueid"`
+}
+
+// anilistUniqueID e o <uniqueid> de todo nfo. "AniList" com essa capitalizacao e o valor de
+// ProviderNames.AniList no jellyfin-plugin-anilist. O ProviderIds do Jellyfin e
+// OrdinalIgnoreCase, entao minusculo tambem casaria — escrevemos igual ao provider para nao
+// depender disso.
+func anilistUniqueID(id int) nfoUniqueID {
+	return nfoUniqueID{Type: "AniList", Default: true, Value: strconv.Itoa(id)}
+}
+
+// showUniqueIDs e o <uniqueid> da AniList, o default, seguido dos ids de outros sites que o
+// mapeamento conhece. Os types sao os nomes de provider dos plugins do Jellyfin (AniDB, Kitsu)
+// e o usado para o MAL pelos scrapers de anime. So a serie e o filme levam os extras: os ids de
+// episodio desses sites nao existem no dataset.
+func showUniqueIDs(animeID int, info *ShowInfo) []nfoUniqueID {
+	ids := []nfoUniqueID{anilistUniqueID(animeID)}
+	if info == nil {
+		return ids
+	}
+	for _, extra := range []struct {
+		kind string
+		id   int
+	}{{"MyAnimeList", info.MyAnimeListID}, {"AniDB", info.AniDBID}, {"Kitsu", info.KitsuID}} {
+		if extra.id > 0 {
+			ids = append(ids, nfoUniqueID{Type: extra.kind, Value: strconv.Itoa(extra.id)})
+		}
+	}
+	return ids
+}
+
+// nfoStatus traduz o status da AniList para o do Jellyfin; o resto fica sem status.
+func nfoStatus(status string) string {
+	switch status {
+	case "RELEASING", "HIATUS":
+		return "Continuing"
+	case "FINISHED", "CANCELLED":
+		return "Ended"
+	}
+	return ""
+}
+
+// episodeNFOPath e o nfo ao lado do video: mesmo nome, extensao .nfo.
+func episodeNFOPath(videoPath string) string {
+	return strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + ".nfo"
+}
+
+func marshalNFO(v any) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte(xml.Header), nfoMarker...)
+	out = append(out, '\n')
+	out = append(out, data...)
+	return append(out, '\n'), nil
+}
+
+func isGeneratedNFO(data []byte) bool {
+	return bytes.Contains(data, []byte(nfoMarker))
+}
+
+// legacyShowNFOID reconhece o tvshow.nfo minimo de antes do marcador (so <title> e
+// <uniqueid>, escrito por versoes anteriores) e devolve o id dele. O id e preservado no upgrade:
+// ele pode ter sido corrigido a mao para acertar o match.
+func legacyShowNFOID(data []byte) (string, bool) {
+	var v struct {
+		XMLName  xml.Name    `xml:"tvshow"`
+		Title    string      `xml:"title"`
+		UniqueID nfoUniqueID `xml:"uniqueid"`
+		Other    []struct {
+			XMLName xml.Name
+		} `xml:",any"`
+	}
+	if err := xml.Unmarshal(data, &v); err != nil || len(v.Other) > 0 || v.UniqueID.Value == "" {
+		return "", false
+	}
+	return v.UniqueID.Value, true
+}
+
+// writeNFO grava um nfo que falta, ou reescreve um nosso (com o marcador) que mudou. O do
+// usuario fica. Falha aqui nao invalida os hardlinks, entao so loga. Retorna true se escreveu.
+func (o *organizer) writeNFO(path string, v any) bool {
+	data, err := marshalNFO(v)
+	if err != nil {
+		logger.Logger.Warn().Err(err).Str("path", path).Msg("Failed to build nfo")
+		return false
+	}
+	if existing, err := o.fs.ReadFile(path); err == nil {
+		if !isGeneratedNFO(existing) || bytes.Equal(existing, data) {
+			return false
+		}
+	} else if _, statErr := o.fs.Stat(path); statErr == nil {
+		return false // existe mas nao deu para ler: nao arrisca
+	}
+	if err := o.fs.WriteFile(path, data, 0644); err != nil {
+		logger.Logger.Warn().Err(err).Str("path", path).Msg("Failed to write nfo")
+		return false
+	}
+	return true
+}
+
+// writeShowNFO escreve o tvshow.nfo com o id da AniList para o Jellyfin (plugin AniList)
+// casar pelo id. Sem info (a AniList nao respondeu, ou quem chama so tem o registro) so escreve
+// o nfo minimo que falta, nunca rebaixa um completo. Com info completa tambem o nfo minimo de
+// versoes anteriores, mantendo o id dele.
+func (o *organizer) writeShowNFO(destDir, animeName string, animeID int, info *ShowInfo) bool {
+	if animeID <= 0 {
+		return false
+	}
+	path := filepath.Join(destDir, "tvshow.nfo")
+	nfo := nfoTVShow{Title: animeName, UniqueIDs: showUniqueIDs(animeID, info)}
+	if info == nil {
+		if _, err := o.fs.Stat(path); err == nil {
+			return false
+		}
+		return o.writeNFO(path, nfo)
+	}
+
+	if info.OriginalTitle != nfo.Title {
+		nfo.OriginalTitle = info.OriginalTitle
+	}
+	nfo.Plot = info.Plot
+	nfo.Year = info.Year
+	nfo.Status = nfoStatus(info.Status)
+	nfo.Genres = info.Genres
+	nfo.Studios = info.Studios
+	// O Jellyfin nao tem campo de titulo alternativo no nfo; como tag os sinonimos ao menos
+	// aparecem na pagina da serie.
+	nfo.Tags = info.Synonyms
+
+	if existing, err := o.fs.ReadFile(path); err == nil && !isGeneratedNFO(existing) {
+		id, ok := legacyShowNFOID(existing)
+		if !ok {
+			return false
+		}
+		nfo.UniqueIDs[0].Value = id
+		if err := o.fs.Remove(path); err != nil {
+			return false
+		}
+	}
+	return o.writeNFO(path, nfo)
+}
+
+// writeMovieNFO escreve o movie.nfo da pasta de um filme, com as mesmas regras do
+// writeShowNFO: sem info so o minimo que falta. O tvshow.nfo gerado por nos que o relink trouxe
+// da pasta de serie sai: numa pasta de filme ele faria o Jellyfin ler uma serie.
+func (o *organizer) writeMovieNFO(destDir, title string, animeID int, info *ShowInfo) bool {
+	if animeID <= 0 {
+		return false
+	}
+	if data, err := o.fs.ReadFile(filepath.Join(destDir, "tvshow.nfo")); err == nil && isGeneratedNFO(data) {
+		_ = o.fs.Remove(filepath.Join(destDir, "tvshow.nfo"))
+	}
+	path := filepath.Join(destDir, movieNFOName)
+	nfo := nfoMovie{Title: title, UniqueIDs: showUniqueIDs(animeID, info)}
+	if info == nil {
+		if _, err := o.fs.Stat(path); err == nil {
+			return false
+		}
+		return o.writeNFO(path, nfo)
+	}
+	if info.OriginalTitle != nfo.Title {
+		nfo.OriginalTitle = info.OriginalTitle
+	}
+	nfo.Plot = info.Plot
+	nfo.Year = info.Year
+	nfo.Genres = info.Genres
+	nfo.Studios = info.Studios
+	nfo.Tags = info.Synonyms
+	return o.writeNFO(path, nfo)
+}
+
+// writeEpisodeNFO escreve o nfo de um episodio ao lado do video. Titulo sem streaming oficial
+// cai em "Episode N"; plot de episodio a AniList nao tem.
+func (o *organizer) writeEpisodeNFO(videoPath, showTitle string, animeID, season, episode, entryEpisode int, info *NFOInfo) bool {
+	if animeID <= 0 || episode <= 0 || info == nil {
+		return false
+	}
+	ep := info.Episodes[entryEpisode]
+	nfo := nfoEpisode{
+		Title:     ep.Title,
+		ShowTitle: showTitle,
+		Season:    season,
+		Episode:   episode,
+		UniqueID:  anilistUniqueID(animeID),
+	}
+	if nfo.Title == "" {
+		nfo.Title = "Episode " + strconv.Itoa(episode)
+	}
+	if !ep.Aired.IsZero() {
+		nfo.Aired = ep.Aired.UTC().Format("2006-01-02")
+	}
+	return o.writeNFO(episodeNFOPath(videoPath), nfo)
+}
@@ -0,0 +1,147 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readNFO(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestWriteNFOs(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "completed")
+	dir := filepath.Join(root, "My Anime")
+	ep3 := filepath.Join(dir, "My Anime - E03.mkv")
+	ep4 := filepath.Join(dir, "My Anime - E04.mkv")
+	writeFile(t, ep3, "video-3")
+	writeFile(t, ep4, "video-4")
+	writeFile(t, filepath.Join(dir, "NCOP.mkv"), "extra")
+	// O nfo de episodio do usuario (sem o marcador) nunca e tocado.
+	writeFile(t, filepath.Join(dir, "My Anime - E04.nfo"), "<episodedetails><title>Mine</title></episodedetails>")
+
+	moves := PlanLibraryMoves([]EpisodeStruct{
+		{AnimeID: 7, AnimeName: "My Anime", EpisodeHash: "h3", EpisodeNumber: 3, LibraryPaths: []string{ep3}},
+		{AnimeID: 7, AnimeName: "My Anime", EpisodeHash: "h4", EpisodeNumber: 4, LibraryPaths: []string{ep4}},
+		{AnimeID: 7, AnimeName: "My Anime", EpisodeHash: "h5", IsBatch: true, LibraryPaths: []string{filepath.Join(dir, "NCOP.mkv")}},
+	}, root, LibraryNaming{Rename: true})
+
+	lib := NewLibrarian(NewOSFileSystem())
+	if !lib.MissingNFOs(moves) {
+		t.Fatal("MissingNFOs = false with no nfo on disk")
+	}
+	info := &NFOInfo{
+		Show: ShowInfo{OriginalTitle: "Boku no Anime", Plot: "A plot & more.", Year: 2024, Status: "RELEASING", Genres: []string{"Action"}, Studios: []string{"MAPPA"},
+			MyAnimeListID: 52991, AniDBID: 17617},
+		Episodes: map[int]EpisodeInfo{
+			3: {Title: "The Third", Aired: time.Date(2024, 4, 20, 15, 0, 0, 0, time.UTC)},
+		},
+	}
+	lib.WriteNFOs(moves, info)
+
+	show := readNFO(t, filepath.Join(dir, "tvshow.nfo"))
+	for _, want := range []string{nfoMarker, "<title>My Anime</title>", "<originaltitle>Boku no Anime</originaltitle>",
+		"<plot>A plot &amp; more.</plot>", "<status>Continuing</status>", "<genre>Action</genre>", "<studio>MAPPA</studio>", ">7</uniqueid>",
+		`<uniqueid type="MyAnimeList">52991</uniqueid>`, `<uniqueid type="AniDB">17617</uniqueid>`} {
+		if !strings.Contains(show, want) {
+			t.Errorf("tvshow.nfo missing %q:\n%s", want, show)
+		}
+	}
+	episode := readNFO(t, filepath.Join(dir, "My Anime - E03.nfo"))
+	for _, want := range []string{nfoMarker, "<title>The Third</title>", "<season>1</season>", "<episode>3</episode>", "<aired>2024-04-20</aired>"} {
+		if !strings.Contains(episode, want) {
+			t.Errorf("episode nfo missing %q:\n%s", want, episode)
+		}
+	}
+	if got := readNFO(t, filepath.Join(dir, "My Anime - E04.nfo")); !strings.Contains(got, "Mine") {
+		t.Errorf("user nfo was overwritten:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "NCOP.nfo")); !os.IsNotExist(err) {
+		t.Errorf("a file without an episode number got an nfo: %v", err)
+	}
+	if lib.MissingNFOs(moves) {
+		t.Error("MissingNFOs = true after WriteNFOs")
+	}
+
+	// Dados novos da AniList regeram o nosso nfo.
+	info.Episodes[3] = EpisodeInfo{Title: "Renamed"}
+	lib.WriteNFOs(moves, info)
+	if got := readNFO(t, filepath.Join(dir, "My Anime - E03.nfo")); !strings.Contains(got, "<title>Renamed</title>") {
+		t.Errorf("generated nfo was not refreshed:\n%s", got)
+	}
+}
+
+// O tvshow.nfo minimo de versoes anteriores e completado, mantendo o id que o usuario pode ter
+// corrigido a mao. Um nfo do usuario com mais campos fica como esta.
+func TestWriteNFOsUpgradesLegacyShowNFO(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "completed")
+	legacy := filepath.Join(root, "Legacy")
+	custom := filepath.Join(root, "Custom")
+	writeFile(t, filepath.Join(legacy, "Legacy - E01.mkv"), "video")
+	writeFile(t, filepath.Join(custom, "Custom - E01.mkv"), "video")
+	writeFile(t, filepath.Join(legacy, "tvshow.nfo"),
+		"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<tvshow>\n  <title>Legacy</title>\n  <uniqueid type=\"AniList\" default=\"true\">999</uniqueid>\n</tvshow>\n")
+	customNFO := "<tvshow><title>Custom</title><plot>Mine</plot><uniqueid type=\"AniList\">2</uniqueid></tvshow>"
+	writeFile(t, filepath.Join(custom, "tvshow.nfo"), customNFO)
+
+	lib := NewLibrarian(NewOSFileSystem())
+	info := &NFOInfo{Show: ShowInfo{Plot: "From AniList"}}
+	for id, name := range map[int]string{1: "Legacy", 2: "Custom"} {
+		path := filepath.Join(root, name, name+" - E01.mkv")
+		moves := PlanLibraryMoves([]EpisodeStruct{{AnimeID: id, AnimeName: name, EpisodeHash: name, EpisodeNumber: 1, LibraryPaths: []string{path}}}, root, LibraryNaming{Rename: true})
+		lib.WriteNFOs(moves, info)
+	}
+
+	got := readNFO(t, filepath.Join(legacy, "tvshow.nfo"))
+	if !strings.Contains(got, "<plot>From AniList</plot>") || !strings.Contains(got, ">999</uniqueid>") {
+		t.Errorf("legacy nfo not upgraded with its own id:\n%s", got)
+	}
+	if got := readNFO(t, filepath.Join(custom, "tvshow.nfo")); got != customNFO {
+		t.Errorf("user tvshow.nfo was touched:\n%s", got)
+	}
+}
+
+// Num move o nfo gerado e apagado (o job o regera com a numeracao nova) e o do usuario vai
+// junto com o video. Remover o video leva o nfo dele.
+func TestEpisodeNFOFollowsLibraryFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Show")
+	lib := NewLibrarian(NewOSFileSystem())
+
+	generated := filepath.Join(dir, "Show - E01.mkv")
+	writeFile(t, generated, "video-1")
+	lib.writeEpisodeNFO(generated, "Show", 1, 1, 1, 1, &NFOInfo{})
+	if err := lib.MoveInLibrary(generated, filepath.Join(dir, "Show - S01E01.mkv")); err != nil {
+		t.Fatalf("MoveInLibrary: %v", err)
+	}
+	for _, nfo := range []string{"Show - E01.nfo", "Show - S01E01.nfo"} {
+		if _, err := os.Stat(filepath.Join(dir, nfo)); !os.IsNotExist(err) {
+			t.Errorf("generated nfo %s should be gone until the nfo job runs: %v", nfo, err)
+		}
+	}
+
+	manual := filepath.Join(dir, "Show - E02.mkv")
+	writeFile(t, manual, "video-2")
+	writeFile(t, filepath.Join(dir, "Show - E02.nfo"), "<episodedetails><title>Mine</title></episodedetails>")
+	moved := filepath.Join(dir, "Show - S01E02.mkv")
+	if err := lib.MoveInLibrary(manual, moved); err != nil {
+		t.Fatalf("MoveInLibrary: %v", err)
+	}
+	if got := readNFO(t, filepath.Join(dir, "Show - S01E02.nfo")); !strings.Contains(got, "Mine") {
+		t.Errorf("user nfo did not follow the file:\n%s", got)
+	}
+
+	if err := lib.RemoveFromLibrary(moved); err != nil {
+		t.Fatalf("RemoveFromLibrary: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Show - S01E02.nfo")); !os.IsNotExist(err) {
+		t.Errorf("nfo of a removed file should be gone: %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package files
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// AccountProfile is the library and the list settings of one account of a multi-user
+// household. A profile replaces the global settings for its account; an account without a
+// profile keeps the globals and the root library. Torrents stay shared: an anime two profiles
+// want is downloaded and seeded once and linked into both libraries (see decisions.md #89).
+type AccountProfile struct {
+	// Account e o usuario da AniList, ou "myanimelist:usuario"/"kitsu:usuario" — a mesma chave
+	// de conta do passe (lists.Account.Key).
+	Account string `json:"account"`
+	// LibrarySubpath e a biblioteca do perfil, relativa a CompletedAnimePath (e a
+	// LibraryMoviesPath, para os filmes). "" divide a raiz com as contas sem perfil.
+	LibrarySubpath   string   `json:"library_subpath"`
+	DownloadStatuses []string `json:"download_statuses"`
+	DeleteStatuses   []string `json:"delete_statuses"`
+	// MaxEpisodesPerAnime, DeleteWatchedEpisodes e WatchedEpisodesToKeep valem por anime para
+	// o conjunto de perfis que o quer: o mais folgado vence (ver daemon.configsForAnime).
+	MaxEpisodesPerAnime   int  `json:"max_episodes_per_anime"`
+	DeleteWatchedEpisodes bool `json:"delete_watched_episodes"`
+	WatchedEpisodesToKeep int  `json:"watched_episodes_to_keep"`
+	// ExcludedLists soma as listas customizadas da conta a Config.ExcludedLists: a global e da
+	// casa toda, esta so do perfil.
+	ExcludedLists []string `json:"excluded_lists"`
+}
+
+// Status de lista da AniList, para a validacao: files nao importa anilist.
+var profileListStatuses = []string{"CURRENT", "PLANNING", "COMPLETED", "DROPPED", "PAUSED", "REPEATING"}
+
+// AccountKeys lists the key of every configured list account: the bare AniList username, or
+// "provider:user" for MyAnimeList and Kitsu (the same keys as lists.Account.Key).
+func (c *Config) AccountKeys() []string {
+	keys := make([]string, 0, len(c.AnilistUsernames)+len(c.MALUsernames)+len(c.KitsuUsernames))
+	keys = append(keys, c.AnilistUsernames...)
+	for _, u := range c.MALUsernames {
+		keys = append(keys, "myanimelist:"+u)
+	}
+	for _, u := range c.KitsuUsernames {
+		keys = append(keys, "kitsu:"+u)
+	}
+	return keys
+}
+
+// ProfileFor returns the profile of an account. An account without one gets a profile built
+// from the global settings, in the root library.
+func (c *Config) ProfileFor(account string) AccountProfile {
+	for _, p := range c.AccountProfiles {
+		if p.Account == account {
+			return p
+		}
+	}
+	return AccountProfile{
+		Account:               account,
+		DownloadStatuses:      c.DownloadStatuses,
+		DeleteStatuses:        c.DeleteStatuses,
+		MaxEpisodesPerAnime:   c.MaxEpisodesPerAnime,
+		DeleteWatchedEpisodes: c.DeleteWatchedEpisodes,
+		WatchedEpisodesToKeep: c.WatchedEpisodesToKeep,
+	}
+}
+
+// HasProfile reports whether the account has a profile of its own.
+func (c *Config) HasProfile(account string) bool {
+	return slices.ContainsFunc(c.AccountProfiles, func(p AccountProfile) bool { return p.Account == account })
+}
+
+// LibraryRoot is the root of the series library of a profile subpath.
+func (c *Config) LibraryRoot(subpath string) string {
+	return filepath.Join(c.CompletedAnimePath, subpath)
+}
+
+// MoviesRoot is the root of the movie library of a profile subpath; "" when the movies live
+// inside the series library (LibraryMoviesPath empty).
+func (c *Config) MoviesRoot(subpath string) string {
+	if c.LibraryMoviesPath == "" {
+		return ""
+	}
+	return filepath.Join(c.LibraryMoviesPath, subpath)
+}
+
+// LibrarySubpathOf tells which library a library path lives in: the subpath of the profile,
+// or "" for the root. ok is false for a path under no library of the current config (a
+// CompletedAnimePath changed by hand, say).
+func (c *Config) LibrarySubpathOf(path string) (subpath string, ok bool) {
+	return librarySubpathOf(path, c.CompletedAnimePath, c.LibraryMoviesPath, c.librarySubpaths())
+}
+
+// librarySubpaths sao os subpaths distintos dos perfis, sem a raiz.
+func (c *Config) librarySubpaths() []string {
+	var subpaths []string
+	for _, p := range c.AccountProfiles {
+		if p.LibrarySubpath != "" && !slices.Contains(subpaths, p.LibrarySubpath) {
+			subpaths = append(subpaths, p.LibrarySubpath)
+		}
+	}
+	return subpaths
+}
+
+// librarySubpathOf acha a biblioteca de um caminho. A raiz contem toda biblioteca de perfil,
+// entao vence a raiz mais longa que contem o caminho.
+func librarySubpathOf(path, completedPath, moviesPath string, subpaths []string) (subpath string, ok bool) {
+	best := -1
+	for _, sub := range append([]string{""}, subpaths...) {
+		roots := []string{filepath.Join(completedPath, sub)}
+		if moviesPath != "" {
+			roots = append(roots, filepath.Join(moviesPath, sub))
+		}
+		for _, root := range roots {
+			if !pathWithin(path, root) || len(root) <= best {
+				continue
+			}
+			best, subpath, ok = len(root), sub, true
+		}
+	}
+	return subpath, ok
+}
+
+func pathWithin(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// LibrarySubpaths returns the libraries an episode belongs in; the root alone when Libraries
+// is empty.
+func (e EpisodeStruct) LibrarySubpaths() []string {
+	if len(e.Libraries) == 0 {
+		return []string{""}
+	}
+	return e.Libraries
+}
+
+// LibraryLinksOutOfDate reports whether an episode's library links disagree with the
+// libraries it belongs in: not organized at all, missing from one of its libraries, or still
+// linked into one it left. Paths under no library of the config are left alone, and an
+// episode with only such paths counts as organized, as it did before profiles.
+func (c *Config) LibraryLinksOutOfDate(ep EpisodeStruct) bool {
+	if len(ep.LibraryPaths) == 0 {
+		return true
+	}
+	want := ep.LibrarySubpaths()
+	linked := make(map[string]bool, len(want))
+	for _, path := range ep.LibraryPaths {
+		sub, ok := c.LibrarySubpathOf(path)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(want, sub) {
+			return true
+		}
+		linked[sub] = true
+	}
+	if len(linked) == 0 {
+		return false
+	}
+	for _, sub := range want {
+		if !linked[sub] {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAccountProfiles checks the profiles of a PUT /config against its accounts.
+func ValidateAccountProfiles(c *Config) error {
+	accounts := c.AccountKeys()
+	seen := make(map[string]bool, len(c.AccountProfiles))
+	for _, p := range c.AccountProfiles {
+		if p.Account == "" {
+			return fmt.Errorf("every profile needs an account")
+		}
+		if !slices.Contains(accounts, p.Account) {
+			return fmt.Errorf("profile %q: account is not configured", p.Account)
+		}
+		if seen[p.Account] {
+			return fmt.Errorf("duplicate profile for account %q", p.Account)
+		}
+		seen[p.Account] = true
+
+		if sub := p.LibrarySubpath; sub != "" {
+			if filepath.IsAbs(sub) || filepath.Clean(sub) != sub || sub == "." || sub == ".." || strings.HasPrefix(sub, ".."+string(filepath.Separator)) {
+				return fmt.Errorf("profile %q: library subpath must be a clean relative path inside the library", p.Account)
+			}
+			// A pasta de download mora dentro de CompletedAnimePath: biblioteca la dentro seria
+			// escondida do scanner e misturada com os torrents.
+			if sub == downloadDirName || strings.HasPrefix(sub, downloadDirName+string(filepath.Separator)) {
+				return fmt.Errorf("profile %q: library subpath cannot be inside %s", p.Account, downloadDirName)
+			}
+		}
+		for _, s := range slices.Concat(p.DownloadStatuses, p.DeleteStatuses) {
+			if !slices.Contains(profileListStatuses, s) {
+				return fmt.Errorf("profile %q: unknown list status %q", p.Account, s)
+			}
+		}
+		if p.MaxEpisodesPerAnime < 0 || p.WatchedEpisodesToKeep < 0 {
+			return fmt.Errorf("profile %q: episode limits must be non-negative", p.Account)
+		}
+	}
+	return nil
+}
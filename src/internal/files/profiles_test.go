@@ -0,0 +1,118 @@
+package files
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateAccountProfiles(t *testing.T) {
+	valid := AccountProfile{Account: "alice", LibrarySubpath: filepath.Join("profiles", "alice"), DownloadStatuses: []string{"CURRENT"}, DeleteStatuses: []string{"DROPPED"}}
+	base := Config{AnilistUsernames: []string{"alice", "bob"}, MALUsernames: []string{"carol"}}
+
+	c := base
+	c.AccountProfiles = []AccountProfile{valid, {Account: "myanimelist:carol"}}
+	if err := ValidateAccountProfiles(&c); err != nil {
+		t.Fatalf("perfis validos recusados: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		edit func(p *AccountProfile)
+	}{
+		{"sem conta", func(p *AccountProfile) { p.Account = "" }},
+		{"conta fora da config", func(p *AccountProfile) { p.Account = "dave" }},
+		{"subpath absoluto", func(p *AccountProfile) { p.LibrarySubpath = string(filepath.Separator) + "alice" }},
+		{"subpath saindo da biblioteca", func(p *AccountProfile) { p.LibrarySubpath = filepath.Join("..", "alice") }},
+		{"subpath sujo", func(p *AccountProfile) { p.LibrarySubpath = "alice/" }},
+		{"subpath na pasta de download", func(p *AccountProfile) { p.LibrarySubpath = filepath.Join(".torrents", "alice") }},
+		{"status desconhecido", func(p *AccountProfile) { p.DeleteStatuses = []string{"dropped"} }},
+		{"limite negativo", func(p *AccountProfile) { p.MaxEpisodesPerAnime = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.edit(&p)
+			c := base
+			c.AccountProfiles = []AccountProfile{p}
+			if err := ValidateAccountProfiles(&c); err == nil {
+				t.Errorf("quero erro para %+v", p)
+			}
+		})
+	}
+
+	t.Run("perfil repetido", func(t *testing.T) {
+		c := base
+		c.AccountProfiles = []AccountProfile{valid, valid}
+		if err := ValidateAccountProfiles(&c); err == nil {
+			t.Error("quero erro para dois perfis da mesma conta")
+		}
+	})
+}
+
+func TestProfileForFallsBackToGlobals(t *testing.T) {
+	c := Config{
+		DownloadStatuses:    []string{"CURRENT"},
+		MaxEpisodesPerAnime: 4,
+		AccountProfiles:     []AccountProfile{{Account: "alice", LibrarySubpath: "alice", MaxEpisodesPerAnime: 0}},
+	}
+	if p := c.ProfileFor("alice"); p.LibrarySubpath != "alice" || p.MaxEpisodesPerAnime != 0 || len(p.DownloadStatuses) != 0 {
+		t.Errorf("perfil proprio = %+v", p)
+	}
+	if p := c.ProfileFor("bob"); p.LibrarySubpath != "" || p.MaxEpisodesPerAnime != 4 || len(p.DownloadStatuses) != 1 {
+		t.Errorf("conta sem perfil devia seguir os globais, veio %+v", p)
+	}
+}
+
+func TestLibraryLinksOutOfDate(t *testing.T) {
+	root := filepath.Join("srv", "anime")
+	c := Config{CompletedAnimePath: root, AccountProfiles: []AccountProfile{{Account: "alice", LibrarySubpath: "alice"}}}
+	inRoot := filepath.Join(root, "Show", "Show - E01.mkv")
+	inAlice := filepath.Join(root, "alice", "Show", "Show - E01.mkv")
+
+	if sub, ok := c.LibrarySubpathOf(inAlice); !ok || sub != "alice" {
+		t.Errorf("LibrarySubpathOf(%s) = %q, %v; a raiz mais longa devia vencer", inAlice, sub, ok)
+	}
+
+	tests := []struct {
+		name string
+		ep   EpisodeStruct
+		want bool
+	}{
+		{"nao organizado", EpisodeStruct{}, true},
+		{"so na raiz, sem perfis", EpisodeStruct{LibraryPaths: []string{inRoot}}, false},
+		{"falta um perfil", EpisodeStruct{Libraries: []string{"", "alice"}, LibraryPaths: []string{inRoot}}, true},
+		{"nos dois", EpisodeStruct{Libraries: []string{"", "alice"}, LibraryPaths: []string{inRoot, inAlice}}, false},
+		{"ainda na raiz que deixou", EpisodeStruct{Libraries: []string{"alice"}, LibraryPaths: []string{inRoot, inAlice}}, true},
+		{"fora de toda biblioteca", EpisodeStruct{Libraries: []string{"alice"}, LibraryPaths: []string{filepath.Join("old", "Show.mkv")}}, false},
+	}
+	for _, tt := range tests {
+		if got := c.LibraryLinksOutOfDate(tt.ep); got != tt.want {
+			t.Errorf("%s: LibraryLinksOutOfDate = %v, quero %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// O mesmo torrent linkado em dois perfis e planejado dentro de cada biblioteca, sem que o
+// arquivo de um perfil va parar na pasta do outro.
+func TestPlanLibraryMovesPerProfile(t *testing.T) {
+	root := filepath.Join("srv", "anime")
+	ep := EpisodeStruct{
+		AnimeID: 1, AnimeName: "Show", EpisodeHash: "h1", EpisodeNumber: 3,
+		LibraryPaths: []string{filepath.Join(root, "Show", "raw.mkv"), filepath.Join(root, "alice", "Show", "raw.mkv")},
+	}
+	naming := LibraryNaming{FileTemplate: "{title} - E{episode:02}", Rename: true, Subpaths: []string{"alice"}}
+
+	want := map[string]string{
+		filepath.Join(root, "Show", "raw.mkv"):          filepath.Join(root, "Show", "Show - E03.mkv"),
+		filepath.Join(root, "alice", "Show", "raw.mkv"): filepath.Join(root, "alice", "Show", "Show - E03.mkv"),
+	}
+	moves := PlanLibraryMoves([]EpisodeStruct{ep}, root, naming)
+	if len(moves) != len(want) {
+		t.Fatalf("quero %d movimentos, veio %+v", len(want), moves)
+	}
+	for _, mv := range moves {
+		if want[mv.From] != mv.To {
+			t.Errorf("move %s -> %s, quero -> %s", mv.From, mv.To, want[mv.From])
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build darwin
+
+package files
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflinkContents clona src em tmp com clonefile(2), que so o APFS suporta.
+func reflinkContents(src, tmp string) error {
+	if err := unix.Clonefile(src, tmp, unix.CLONE_NOFOLLOW); err != nil {
+		return &os.LinkError{Op: "reflink", Old: src, New: tmp, Err: err}
+	}
+	return nil
+}
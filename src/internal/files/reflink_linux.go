@@ -0,0 +1,28 @@
+//go:build linux
+
+package files
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflinkContents clona src em tmp com o ioctl FICLONE. Filesystem sem suporte (ext4, exFAT,
+// shares de rede) devolve EOPNOTSUPP, EXDEV ou EINVAL, e o probe os reporta como tal.
+func reflinkContents(src, tmp string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if err := unix.IoctlFileClone(int(out.Fd()), int(in.Fd())); err != nil {
+		out.Close()
+		return &os.LinkError{Op: "reflink", Old: src, New: tmp, Err: err}
+	}
+	return out.Close()
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package files
+
+// reflinkContents nao tem implementacao aqui: o block cloning do ReFS no Windows fica de fora.
+func reflinkContents(src, tmp string) error {
+	return ErrReflinkUnsupported
+}
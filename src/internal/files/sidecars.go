@@ -0,0 +1,294 @@
+package files
+
+import (
+	"AutoAnimeDownloader/src/internal/logger"
+	"AutoAnimeDownloader/src/internal/nyaa"
+
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Sidecars sao as legendas externas e as fontes que alguns fansubs mandam fora do mkv. Na
+// biblioteca a legenda fica ao lado do video com o nome dele mais a lingua ("Anime -
+// E05.en.ass"), que e o que o Jellyfin reconhece; as fontes vao para a pasta fonts do anime,
+// compartilhada pelos episodios. Nao entram nos LibraryPaths: saem do nome do video, como o
+// nfo do episodio, e vao e voltam junto com ele (RemoveFromLibrary, MoveInLibrary).
+
+var subtitleExtensions = map[string]bool{
+	".ass": true, ".ssa": true, ".srt": true, ".vtt": true, ".sub": true, ".idx": true, ".sup": true,
+}
+
+var fontExtensions = map[string]bool{
+	".ttf": true, ".otf": true, ".ttc": true, ".woff": true, ".woff2": true,
+}
+
+// libraryFontsDir e a pasta das fontes dentro da pasta do anime. Nao tem video, entao o
+// Jellyfin a ignora; e da pasta, como o nfo e a arte (removeDirIfOnlyShowFiles).
+const libraryFontsDir = "fonts"
+
+func isSubtitleFile(name string) bool {
+	return subtitleExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+func isFontFile(name string) bool {
+	return fontExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// isSubtitleDir diz se a pasta e uma das que os fansubs usam para as legendas.
+func isSubtitleDir(name string) bool {
+	switch strings.ToLower(name) {
+	case "subs", "sub", "subtitles", "subtitle":
+		return true
+	}
+	return false
+}
+
+// inSubtitleDir diz se algum nivel de rel (relativo a raiz do torrent) e uma pasta de legendas.
+func inSubtitleDir(rel string) bool {
+	for _, part := range strings.Split(filepath.Dir(rel), string(filepath.Separator)) {
+		if isSubtitleDir(part) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// reSubtitleOrder e a numeracao na frente do nome ("2_English.ass") que alguns packs usam
+	// para ordenar as faixas.
+	reSubtitleOrder = regexp.MustCompile(`^\d+[_ .-]+`)
+	reLanguageWord  = regexp.MustCompile(`^[A-Za-z][A-Za-z -]{1,24}$`)
+	reLanguageTag   = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z]{2,4})?$`)
+)
+
+// subtitleFlags sao os sufixos de faixa que o Jellyfin le depois da lingua.
+var subtitleFlags = map[string]bool{"forced": true, "sdh": true, "cc": true, "hi": true, "default": true}
+
+// subtitleLanguage tira a lingua do nome de uma legenda que nao tem o nome do video: o nome
+// inteiro quando e uma palavra ("English", "2_English"), senao as tags do fim ("Anime -
+// 05.pt-BR.forced" -> "pt-BR.forced"). Vazio quando nao ha nada que pareca lingua.
+func subtitleLanguage(name string) string {
+	stem := reSubtitleOrder.ReplaceAllString(strings.TrimSuffix(name, filepath.Ext(name)), "")
+	if reLanguageWord.MatchString(stem) {
+		return stem
+	}
+	parts := strings.Split(stem, ".")
+	i := len(parts)
+	for i > 1 && (reLanguageTag.MatchString(parts[i-1]) || subtitleFlags[strings.ToLower(parts[i-1])]) {
+		i--
+	}
+	return strings.Join(parts[i:], ".")
+}
+
+// sidecar e uma legenda do torrent ja ligada ao seu video.
+type sidecar struct {
+	rel    string // relativo a raiz do torrent
+	video  string // rel do video
+	suffix string // lingua e flags, sem os pontos das pontas ("en", "English.forced")
+}
+
+// matchSidecars liga cada legenda do torrent a um video, nesta ordem:
+//
+//  1. mesmo nome do video mais um sufixo ("Anime - 05.en.ass"), na pasta do video ou numa
+//     pasta de legendas;
+//  2. pasta com o nome do video dentro de uma pasta de legendas ("Subs/Anime - 05/English.ass");
+//  3. numa pasta de legendas, o mesmo numero de episodio de um unico video do pack;
+//  4. torrent com um video so: qualquer legenda dele.
+//
+// Legenda que nao casa com nenhum video (a de um NCOP sem video, por exemplo) fica de fora.
+func matchSidecars(videos, subtitles []string) []sidecar {
+	type videoInfo struct {
+		rel, dir, stem string
+	}
+	infos := make([]videoInfo, len(videos))
+	byNumber := make(map[int][]string)
+	for i, v := range videos {
+		base := filepath.Base(v)
+		infos[i] = videoInfo{rel: v, dir: filepath.Dir(v), stem: strings.TrimSuffix(base, filepath.Ext(base))}
+		if n := nyaa.ExtractEpisodeNumber(base); n != nil {
+			byNumber[*n] = append(byNumber[*n], v)
+		}
+	}
+
+	var out []sidecar
+	for _, s := range subtitles {
+		base, dir := filepath.Base(s), filepath.Dir(s)
+		subStem := strings.TrimSuffix(base, filepath.Ext(base))
+		inSubs := inSubtitleDir(s)
+		matched := false
+		for _, v := range infos {
+			if (dir == v.dir || inSubs) && (subStem == v.stem || strings.HasPrefix(subStem, v.stem+".")) {
+				out = append(out, sidecar{rel: s, video: v.rel, suffix: strings.TrimPrefix(subStem, v.stem)})
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		for _, v := range infos {
+			if inSubs && filepath.Base(dir) == v.stem {
+				out = append(out, sidecar{rel: s, video: v.rel, suffix: subtitleLanguage(base)})
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		if inSubs {
+			if n := nyaa.ExtractEpisodeNumber(base); n != nil && len(byNumber[*n]) == 1 {
+				out = append(out, sidecar{rel: s, video: byNumber[*n][0], suffix: subtitleLanguage(base)})
+				continue
+			}
+		}
+		if len(videos) == 1 {
+			out = append(out, sidecar{rel: s, video: videos[0], suffix: subtitleLanguage(base)})
+		}
+	}
+	for i := range out {
+		out[i].suffix = strings.Trim(stripInvalidChars(out[i].suffix), ". ")
+	}
+	return out
+}
+
+// sidecarName e o nome da legenda na biblioteca: o do video, o sufixo e a extensao.
+func sidecarName(videoPath, suffix, ext string) string {
+	name := strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath))
+	if suffix != "" {
+		name += "." + suffix
+	}
+	return name + strings.ToLower(ext)
+}
+
+// linkSidecars poe na biblioteca as legendas dos videos que o Organize colocou (placed: rel do
+// video -> arquivo na biblioteca) e, se alguma entrou, as fontes do torrent. E o melhor
+// esforco: uma legenda que falha fica no log e o episodio continua organizado.
+func (o *organizer) linkSidecars(srcRoot string, placed map[string]string, link func(oldname, newname string) error, mode LinkMode) {
+	subtitles, err := o.collectFiles(srcRoot, isSubtitleFile)
+	if err != nil || len(subtitles) == 0 {
+		return
+	}
+	videos := make([]string, 0, len(placed))
+	for rel := range placed {
+		videos = append(videos, rel)
+	}
+	slices.Sort(videos)
+
+	used := make(map[string]bool)
+	fontDirs := make(map[string]bool)
+	for _, sc := range matchSidecars(videos, subtitles) {
+		videoDest := placed[sc.video]
+		dest := filepath.Join(filepath.Dir(videoDest), sidecarName(videoDest, sc.suffix, filepath.Ext(sc.rel)))
+		if used[dest] {
+			// Duas faixas com a mesma lingua: fica a primeira.
+			logger.Logger.Debug().Str("subtitle", sc.rel).Str("destination", dest).Msg("Skipping subtitle with the same library name as another one")
+			continue
+		}
+		used[dest] = true
+		if err := o.placeSidecar(link, mode, filepath.Join(srcRoot, sc.rel), dest); err != nil {
+			logger.Logger.Warn().Err(err).Str("destination", dest).Msg("Failed to link subtitle into the library")
+			continue
+		}
+		fontDirs[filepath.Dir(videoDest)] = true
+	}
+	if len(fontDirs) == 0 {
+		return
+	}
+
+	fonts, err := o.collectFiles(srcRoot, isFontFile)
+	if err != nil {
+		return
+	}
+	for dir := range fontDirs {
+		for _, f := range fonts {
+			dest := filepath.Join(dir, libraryFontsDir, filepath.Base(f))
+			if _, err := o.fs.Lstat(dest); err == nil {
+				// Mesma fonte de outro episodio ou release: a que ja esta serve.
+				continue
+			}
+			if err := o.fs.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				logger.Logger.Warn().Err(err).Str("path", filepath.Dir(dest)).Msg("Failed to create fonts folder")
+				break
+			}
+			if err := link(filepath.Join(srcRoot, f), dest); err != nil {
+				logger.Logger.Warn().Err(err).Str("destination", dest).Msg("Failed to link font into the library")
+			}
+		}
+	}
+}
+
+func (o *organizer) placeSidecar(link func(oldname, newname string) error, mode LinkMode, src, dest string) error {
+	already, err := o.prepareDest(mode, src, dest)
+	if err != nil || already {
+		return err
+	}
+	return link(src, dest)
+}
+
+// librarySidecars devolve as legendas ao lado de um video da biblioteca: mesmo nome, com ou
+// sem sufixo de lingua.
+func (o *organizer) librarySidecars(videoPath string) []string {
+	entries, err := o.fs.ReadDir(filepath.Dir(videoPath))
+	if err != nil {
+		return nil
+	}
+	stem := strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath))
+	var out []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !isSubtitleFile(name) {
+			continue
+		}
+		if subStem := strings.TrimSuffix(name, filepath.Ext(name)); subStem == stem || strings.HasPrefix(subStem, stem+".") {
+			out = append(out, filepath.Join(filepath.Dir(videoPath), name))
+		}
+	}
+	return out
+}
+
+// moveSidecars leva as legendas de from para o nome de to, mantendo o sufixo. Uma que ja existe
+// no destino fica onde esta.
+func (o *organizer) moveSidecars(from, to string) {
+	fromStem := strings.TrimSuffix(filepath.Base(from), filepath.Ext(from))
+	for _, sub := range o.librarySidecars(from) {
+		name := filepath.Base(sub)
+		suffix := strings.TrimPrefix(strings.TrimSuffix(name, filepath.Ext(name)), fromStem)
+		dest := filepath.Join(filepath.Dir(to), sidecarName(to, strings.TrimPrefix(suffix, "."), filepath.Ext(name)))
+		if _, err := o.fs.Lstat(dest); err == nil {
+			continue
+		}
+		if err := o.move(sub, dest); err != nil {
+			logger.Logger.Warn().Err(err).Str("from", sub).Str("to", dest).Msg("Failed to move subtitle")
+		}
+	}
+}
+
+// copyFonts poe em newDir/fonts as fontes de oldDir/fonts que faltam, quando o relink muda a
+// pasta de um episodio. Hardlink, e copia se nao der: a pasta antiga pode ter outros episodios.
+func (o *organizer) copyFonts(oldDir, newDir string) {
+	entries, err := o.fs.ReadDir(filepath.Join(oldDir, libraryFontsDir))
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		src := filepath.Join(oldDir, libraryFontsDir, e.Name())
+		dest := filepath.Join(newDir, libraryFontsDir, e.Name())
+		if _, err := o.fs.Lstat(dest); err == nil {
+			continue
+		}
+		if err := o.fs.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return
+		}
+		if err := o.fs.Link(src, dest); err != nil {
+			if err := o.fs.CopyFile(src, dest); err != nil {
+				logger.Logger.Warn().Err(err).Str("path", dest).Msg("Failed to copy font")
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSubtitleLanguage(t *testing.T) {
+	cases := map[string]string{
+		"English.ass":                  "English",
+		"2_English.srt":                "English",
+		"Anime - 05.pt-BR.forced.ass":  "pt-BR.forced",
+		"Anime - 05 [Signs].en.ass":    "en",
+		"[Sub] Anime - 05 [1080p].ass": "",
+	}
+	for name, want := range cases {
+		if got := subtitleLanguage(name); got != want {
+			t.Errorf("subtitleLanguage(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// Um episodio avulso: a legenda com o nome do video mantem o sufixo, a da pasta Subs ganha a
+// lingua do proprio nome, e as fontes vao para a pasta fonts. RemoveFromLibrary leva as legendas.
+func TestOrganizeSidecars(t *testing.T) {
+	tmp := t.TempDir()
+	dataDir := filepath.Join(tmp, "save", "torrentid")
+	completed := filepath.Join(tmp, "completed")
+	writeFile(t, filepath.Join(dataDir, "[Sub] Anime - 05 [1080p].mkv"), "video")
+	writeFile(t, filepath.Join(dataDir, "[Sub] Anime - 05 [1080p].en.ass"), "en")
+	writeFile(t, filepath.Join(dataDir, "[Sub] Anime - 05 [1080p].ass"), "default")
+	writeFile(t, filepath.Join(dataDir, "Subs", "2_Portuguese.SRT"), "pt")
+	writeFile(t, filepath.Join(dataDir, "Fonts", "Font.ttf"), "font")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	created, err := lib.Organize(OrganizeRequest{
+		TorrentDataDir: dataDir,
+		AnimeName:      "Anime",
+		CompletedPath:  completed,
+		EpisodeNumber:  intPtr(5),
+		RenameJellyfin: true,
+	})
+	if err != nil {
+		t.Fatalf("Organize: %v", err)
+	}
+	video := filepath.Join(completed, "Anime", "Anime - E05.mkv")
+	if len(created) != 1 || created[0] != video {
+		t.Fatalf("created = %v, want only the video (sidecars are not LibraryPaths)", created)
+	}
+	for _, name := range []string{"Anime - E05.en.ass", "Anime - E05.ass", "Anime - E05.Portuguese.srt", filepath.Join("fonts", "Font.ttf")} {
+		if _, err := os.Stat(filepath.Join(completed, "Anime", name)); err != nil {
+			t.Errorf("expected sidecar %s: %v", name, err)
+		}
+	}
+
+	// Idempotente: de novo nao falha nem duplica.
+	if _, err := lib.Organize(OrganizeRequest{TorrentDataDir: dataDir, AnimeName: "Anime", CompletedPath: completed, EpisodeNumber: intPtr(5), RenameJellyfin: true}); err != nil {
+		t.Fatalf("second Organize: %v", err)
+	}
+
+	if err := lib.RemoveFromLibrary(video); err != nil {
+		t.Fatalf("RemoveFromLibrary: %v", err)
+	}
+	for _, name := range []string{"Anime - E05.en.ass", "Anime - E05.ass", "Anime - E05.Portuguese.srt"} {
+		if _, err := os.Stat(filepath.Join(completed, "Anime", name)); !os.IsNotExist(err) {
+			t.Errorf("sidecar %s should go with the video, stat err = %v", name, err)
+		}
+	}
+}
+
+// Num pack cada legenda vai para o seu episodio: pela pasta com o nome do video ou pelo numero.
+// A que nao casa com nenhum video fica de fora.
+func TestOrganizeBatchSidecars(t *testing.T) {
+	tmp := t.TempDir()
+	dataDir := filepath.Join(tmp, "save", "batchid")
+	completed := filepath.Join(tmp, "completed")
+	writeFile(t, filepath.Join(dataDir, "[Sub] Anime - 01 [1080p].mkv"), "a")
+	writeFile(t, filepath.Join(dataDir, "[Sub] Anime - 02 [1080p].mkv"), "b")
+	writeFile(t, filepath.Join(dataDir, "Subs", "[Sub] Anime - 01 [1080p]", "English.ass"), "1")
+	writeFile(t, filepath.Join(dataDir, "Subs", "Anime - 02 [Signs].en.ass"), "2")
+	writeFile(t, filepath.Join(dataDir, "Subs", "NCOP.ass"), "op")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	if _, err := lib.Organize(OrganizeRequest{
+		TorrentDataDir: dataDir,
+		AnimeName:      "Anime",
+		CompletedPath:  completed,
+		IsBatch:        true,
+		RenameJellyfin: true,
+	}); err != nil {
+		t.Fatalf("Organize: %v", err)
+	}
+	entries, err := os.ReadDir(filepath.Join(completed, "Anime"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	want := []string{"Anime - E01.English.ass", "Anime - E01.mkv", "Anime - E02.en.ass", "Anime - E02.mkv"}
+	if !slices.Equal(got, want) {
+		t.Errorf("library = %v, want %v", got, want)
+	}
+}
+
+// O relink leva as legendas com o nome novo e as fontes para a pasta nova; a pasta antiga, so
+// com as fontes, sai.
+func TestMoveInLibraryMovesSidecars(t *testing.T) {
+	tmp := t.TempDir()
+	oldDir := filepath.Join(tmp, "Show")
+	writeFile(t, filepath.Join(oldDir, "Show - E01.mkv"), "one")
+	writeFile(t, filepath.Join(oldDir, "Show - E01.en.ass"), "subs")
+	writeFile(t, filepath.Join(oldDir, "fonts", "Font.ttf"), "font")
+
+	lib := NewLibrarian(NewOSFileSystem())
+	to := filepath.Join(tmp, "New", "Show - S01E01.mkv")
+	if err := lib.MoveInLibrary(filepath.Join(oldDir, "Show - E01.mkv"), to); err != nil {
+		t.Fatalf("MoveInLibrary: %v", err)
+	}
+	for _, p := range []string{filepath.Join(tmp, "New", "Show - S01E01.en.ass"), filepath.Join(tmp, "New", "fonts", "Font.ttf")} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("expected %s: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(oldDir); !os.IsNotExist(err) {
+		t.Errorf("old folder left with only fonts should be removed, stat err = %v", err)
+	}
+}
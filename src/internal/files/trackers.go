@@ -0,0 +1,72 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Lista de trackers baixada de Config.TrackersListURL. Fica em disco, e nao so em memoria, por
+// dois motivos: o daemon reinicia sem ter de buscar a lista de novo antes do primeiro Add, e
+// uma URL fora do ar (sao repositorios no GitHub) deixa valendo a ultima lista boa em vez de
+// lista nenhuma. O formato e o das proprias listas publicas — uma URL por linha —, entao o
+// arquivo pode ser lido e editado a mao. A primeira linha e um comentario com a URL de onde a
+// lista veio: trocar a URL na config tem de trocar a lista no passe seguinte, sem esperar a
+// lista velha envelhecer.
+
+// trackersListSourcePrefix abre a linha que guarda a URL de origem. Linha com "#" nao e
+// tracker, entao um comentario escrito a mao tambem e so ignorado.
+const trackersListSourcePrefix = "# source: "
+
+// LoadTrackersList devolve a lista salva, a URL de onde ela veio e quando ela foi gravada.
+// Arquivo ausente e lista vazia com hora zero, nao erro: e o estado antes da primeira busca, e
+// hora zero faz o chamador buscar na hora. Arquivo sem a linha de origem (gravado antes dela)
+// tem source "", que nao bate com URL nenhuma.
+func (m *FileManager) LoadTrackersList() (trackers []string, source string, savedAt time.Time, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info, err := m.fs.Stat(m.trackersListPath)
+	if os.IsNotExist(err) {
+		return []string{}, "", time.Time{}, nil
+	} else if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("failed to stat trackers list file: %w", err)
+	}
+
+	b, err := m.fs.ReadFile(m.trackersListPath)
+	if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("failed to read trackers list file: %w", err)
+	}
+
+	trackers = []string{}
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, trackersListSourcePrefix):
+			source = strings.TrimPrefix(line, trackersListSourcePrefix)
+		case line == "" || strings.HasPrefix(line, "#"):
+		default:
+			trackers = append(trackers, line)
+		}
+	}
+	return trackers, source, info.ModTime(), nil
+}
+
+// SaveTrackersList substitui a lista salva, baixada de source. Uma lista vazia tambem e
+// gravada: e o que marca a busca como feita, para o passe seguinte nao repetir o download
+// antes do intervalo.
+func (m *FileManager) SaveTrackersList(source string, trackers []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(trackersListSourcePrefix + source + "\n")
+	for _, tr := range trackers {
+		b.WriteString(tr + "\n")
+	}
+	if err := m.writeAtomic(m.trackersListPath, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to write trackers list file: %w", err)
+	}
+	return nil
+}
@@ -117,6 +117,11 @@ export interface StatusResponse {
   /** Livre abaixo de min_free_disk_percent: o daemon parou de adicionar torrents. Calculado no
    *  servidor de proposito — um limiar duplicado no frontend discordaria do daemon. */
   disk_low: boolean
+  /** Pausa global (pause-all) em vigor; `downloads_paused_until` ausente = até o resume-all. */
+  downloads_paused: boolean
+  downloads_paused_until?: string
+  /** Teto mensal de dados atingido: downloads e seeding parados até a virada do ciclo. */
+  data_cap_reached: boolean
 }
 
 export interface WebhookPreset {
@@ -128,6 +133,24 @@ export interface WebhookPreset {
   events: string[]
 }
 
+export type MediaServerType = 'jellyfin' | 'emby' | 'plex'
+
+export interface MediaServer {
+  name: string
+  type: MediaServerType
+  url: string
+  /** API key do Jellyfin/Emby ou X-Plex-Token. */
+  token: string
+  /** completed_anime_path e library_movies_path como o servidor os enxerga. Vazio = o mesmo. */
+  library_path: string
+  movies_path: string
+}
+
+export interface MediaServerInfo {
+  name: string
+  version: string
+}
+
 export interface Priorities {
   criteria_order: string[]
   fansubs: string[]
@@ -138,9 +161,56 @@ export interface Priorities {
   ignore_list: string[]
 }
 
+export type QueuePolicy = 'fifo' | 'smallest_first' | 'airing_first' | 'fair_share'
+
+export type TorrentClient = 'embedded' | 'qbittorrent' | 'transmission'
+
+export type LibraryLinkMode = 'hardlink' | 'reflink' | 'symlink' | 'copy'
+
+/**
+ * Perfil de uma conta: substitui os globais para ela. O torrent é um só para a casa toda e entra
+ * por link na biblioteca de cada perfil que quer o anime.
+ */
+export interface AccountProfile {
+  /** Usuário da AniList, ou "myanimelist:usuario" / "kitsu:usuario". */
+  account: string
+  /** Biblioteca do perfil, relativa a completed_anime_path. Vazio = a raiz. */
+  library_subpath: string
+  download_statuses: string[]
+  delete_statuses: string[]
+  /** 0 = sem teto. Entre perfis que querem o mesmo anime, o mais folgado vence. */
+  max_episodes_per_anime: number
+  delete_watched_episodes: boolean
+  watched_episodes_to_keep: number
+  /** Somadas às listas excluídas globais, só para esta conta. */
+  excluded_lists: string[]
+}
+
 export interface Config {
   anilist_username?: string
   anilist_usernames: string[]
+  /** Contas do MyAnimeList e do Kitsu, só leitura, mapeadas para os ids da AniList. */
+  mal_usernames: string[]
+  kitsu_usernames: string[]
+  /** Client id da API v2 do MAL. Vazio usa a lista pública. */
+  mal_client_id: string
+  /** Cliente de API da AniList para o login de escrita. Sem o secret, o login e implicito (colar o token). */
+  anilist_client_id: string
+  anilist_client_secret: string
+  /** Assistir no Jellyfin/Plex sobe o progresso na AniList das contas com login. */
+  anilist_playback_sync: boolean
+  /** Marcar o último episódio move a entrada para COMPLETED. */
+  anilist_complete_on_last_episode: boolean
+  /** Seguir a sequência de um anime que terminou: ela entra como avulso quando estiver no ar. */
+  follow_sequels: boolean
+  /** Com follow_sequels: dias antes da estreia em que a sequência anunciada já entra. 0 = só no ar. */
+  sequel_lead_days: number
+  /** Regras da temporada: o que casa entra como avulso em teste. */
+  auto_subscribe_rules: AutoSubscribeRule[]
+  /** Dias até um anime em teste ser removido se ninguém ficar com ele. 0 = nunca expira. */
+  trial_keep_days: number
+  /** Biblioteca, status e limites próprios de uma conta da casa. Conta sem perfil segue os globais. */
+  account_profiles: AccountProfile[]
   completed_anime_path: string
   check_interval: number
   max_episodes_per_anime: number
@@ -155,11 +225,47 @@ export interface Config {
   min_free_disk_percent: number
   episode_retry_limit: number
   max_concurrent_downloads: number
+  /** Ordem em que a fila começa os torrents que esperam. Priorizar à mão vence qualquer uma. */
+  queue_policy: QueuePolicy
+  /** Trackers somados a todo magnet novo. */
+  extra_trackers: string[]
+  /** Lista publica de trackers (um por linha), baixada uma vez por dia. Vazio desliga. */
+  trackers_list_url: string
+  /** JSON do anime-offline-database (ids de MAL/AniDB/Kitsu e sinônimos), baixado uma vez por semana. Vazio usa só o arquivo da pasta do config. */
+  id_mapping_url: string
+  /** De quantos em quantos dias cada torrent completo tem os dados re-verificados. 0 desliga. */
+  integrity_check_days: number
+  /** Teto mensal de trafego em GB, download e upload somados. Atingido, tudo para até a virada. 0 desliga. */
+  data_cap_gb: number
+  /** Dia do mês (1-28) em que o ciclo da operadora vira. */
+  data_cap_billing_day: number
+  /** Quem baixa: o cliente embutido ou um qBittorrent/Transmission externo. Trocar exige reiniciar o daemon. */
+  torrent_client: TorrentClient
+  /** URL da WebUI do qBittorrent ou do RPC do Transmission. */
+  torrent_client_url: string
+  torrent_client_username: string
+  torrent_client_password: string
+  /** Categoria (qBittorrent) ou label (Transmission) dos torrents do daemon. Os demais nao sao tocados. */
+  torrent_client_category: string
+  /** A pasta de download como o cliente externo a enxerga, quando difere da daqui. Vazio = a mesma. */
+  torrent_client_save_path: string
   delete_watched_episodes: boolean
   watched_episodes_to_keep: number
   excluded_list?: string
   excluded_lists: string[]
   rename_files_for_jellyfin: boolean
+  /** Template da pasta de cada anime. Só tokens que valem para o anime inteiro. */
+  library_folder_template: string
+  /** Template do nome de cada episódio; só vale com rename_files_for_jellyfin. */
+  library_file_template: string
+  /** Junta as temporadas de uma série (PREQUEL da AniList) numa pasta, com Season NN. */
+  library_season_folders: boolean
+  /** Põe os filmes (formato MOVIE) em "Título (Ano)/Título (Ano).mkv" com movie.nfo. */
+  library_movie_layout: boolean
+  /** Raiz dos filmes no layout de filme; vazio = dentro de completed_anime_path. */
+  library_movies_path: string
+  /** Como o episódio entra na biblioteca. Os três além de hardlink são para filesystems sem hardlink. */
+  library_link_mode: LibraryLinkMode
   download_statuses: string[]
   download_media_statuses: string[]
   delete_statuses: string[]
@@ -167,6 +273,8 @@ export interface Config {
     webhooks: WebhookPreset[]
     batch_window_seconds: number
   }
+  /** Servidores que recebem o scan da biblioteca depois do organize e das remoções. */
+  media_servers: MediaServer[]
   priorities: Priorities
 }
 
@@ -228,6 +336,27 @@ export interface TorrentInfo {
   seeded_for_seconds: number
 }
 
+export interface TrackerInfo {
+  url: string
+  /** not_contacted | contacting | working | not_working | unknown */
+  status: string
+  seeders: number
+  leechers: number
+  error?: string
+  last_announce: string | null
+  next_announce: string | null
+}
+
+export interface TorrentDetail extends TorrentInfo {
+  trackers: TrackerInfo[]
+}
+
+export interface TrackersResult {
+  /** Quantos trackers extras eram novos para o torrent. Sempre 0 no announce. */
+  added: number
+  trackers: TrackerInfo[]
+}
+
 /** Uma linha do relatório da última verificação: um par (anime, código). */
 export interface Issue {
   anime_id: number
@@ -241,6 +370,8 @@ export interface Issue {
   downloaded?: number
   pending?: number
   batch_skipped?: string
+  /** Só em data_corrupted: peças que falharam na verificação de integridade. */
+  bad_pieces?: number
 }
 
 /** O relatório do ÚLTIMO passe, e só dele. Não é histórico. */
@@ -249,6 +380,9 @@ export interface CheckReport {
   pass_error: string
   problems: Issue[]
   limits: Issue[]
+  // O passe rodou com respostas da AniList salvas em disco porque ela não respondeu.
+  anilist_stale: boolean
+  anilist_stale_since?: string
 }
 
 export async function getStatus(): Promise<StatusResponse> {
@@ -302,14 +436,25 @@ export interface AnimeDetailResponse {
   cover_image?: string
   episodes: AnimeEpisodeInfo[]
   custom_search_query?: string
+  queue_weight?: number
+  follow_sequels?: FollowSequels
+  /** Só vem enquanto o anime está em teste de uma regra de auto-subscribe. */
+  trial?: Trial
 }
 
 export interface AnimeSettings {
   custom_search_query?: string
   /** Progresso manual — só é lido para anime avulso (o de lista vem da AniList). */
   progress?: number
+  /** Peso do anime na política de fila fair_share. 0 volta ao padrão (1). */
+  queue_weight?: number
+  /** Override do follow_sequels global para este anime. Vazio volta ao global. */
+  follow_sequels?: FollowSequels
 }
 
+export type FollowSequels = '' | 'always' | 'never'
+
+
 export async function getAnimeDetail(animeId: number): Promise<AnimeDetailResponse> {
   return apiRequest<AnimeDetailResponse>('GET', `/animes/${animeId}/episodes`)
 }
@@ -366,6 +511,10 @@ export async function getTorrents(): Promise<TorrentInfo[]> {
   return apiRequest<TorrentInfo[]>('GET', '/torrents', null, { silent: true })
 }
 
+export async function getTorrent(hash: string): Promise<TorrentDetail> {
+  return apiRequest<TorrentDetail>('GET', `/torrents/${hash}`)
+}
+
 export async function pauseTorrent(hash: string): Promise<void> {
   return apiRequest<void>('POST', `/torrents/${hash}/pause`)
 }
@@ -388,10 +537,219 @@ export async function prioritizeTorrents(hashes: string[]): Promise<void> {
   return apiRequest<void>('POST', '/torrents/prioritize', { hashes })
 }
 
+export interface DataUsageBytes {
+  downloaded: number
+  uploaded: number
+  total: number
+}
+
+export interface DataUsageDay extends DataUsageBytes {
+  date: string
+}
+
+export interface DataUsagePeriod extends DataUsageBytes {
+  start: string
+  end: string
+}
+
+export interface DataUsageAnime extends DataUsageBytes {
+  anime_id: number
+  anime_name: string
+}
+
+/** Tráfego do ciclo atual da operadora e dos anteriores; com anime_id, só daquele anime. */
+export interface DataUsage {
+  /** 0 = sem teto. */
+  cap_bytes: number
+  cap_reached: boolean
+  billing_day: number
+  period_start: string
+  /** A virada: o teto atingido só é liberado aqui. */
+  period_end: string
+  anime_id?: number
+  period: DataUsageBytes
+  days: DataUsageDay[]
+  /** Os últimos ciclos, o atual primeiro. */
+  periods: DataUsagePeriod[]
+  /** Ciclo atual por anime, o que mais gastou primeiro. */
+  animes: DataUsageAnime[]
+}
+
+export async function getDataUsage(animeId?: number): Promise<DataUsage> {
+  const query = animeId ? `?anime_id=${animeId}` : ''
+  return apiRequest<DataUsage>('GET', `/data-usage${query}`)
+}
+
+/** Ids do mesmo anime em outros sites, do mapeamento offline. 0 = o dataset não conhece. */
+export interface AnimeIDs {
+  anilist: number
+  myanimelist?: number
+  anidb: number
+  kitsu: number
+}
+
+export interface IDMappingStatus {
+  loaded: boolean
+  entries: number
+  /** lastUpdate do próprio dataset. */
+  dataset_date?: string
+  saved_at?: string
+}
+
+/** Rejects with 404 when the dataset is not loaded or does not have this anime. */
+export async function getAnimeIDs(mediaId: number): Promise<AnimeIDs> {
+  return apiRequest<AnimeIDs>('GET', `/animes/${mediaId}/ids`)
+}
+
+export async function getIDMappingStatus(): Promise<IDMappingStatus> {
+  return apiRequest<IDMappingStatus>('GET', '/id-mapping')
+}
+
+/**
+ * Downloads the dataset from id_mapping_url right away and reloads it (without a URL, re-reads
+ * the file in the config folder). A failed download keeps the previous dataset.
+ */
+export async function refreshIDMapping(): Promise<IDMappingStatus> {
+  return apiRequest<IDMappingStatus>('POST', '/id-mapping/refresh')
+}
+
+export interface LibraryMove {
+  hash: string
+  anime_id: number
+  anime_name: string
+  episode_number?: number
+  from: string
+  /** Igual a `from` quando o arquivo já está onde os templates o colocariam. */
+  to: string
+}
+
+export interface NamingPreview {
+  /** Todos os arquivos organizados na biblioteca. */
+  total: number
+  /** Quantos deles os templates moveriam. */
+  changed: number
+  tokens: string[]
+  /** Os que mudam primeiro, depois os que ficam, até o limite. */
+  items: LibraryMove[]
+}
+
+/**
+ * Renders the naming templates against the library without touching the disk. Fields left
+ * out use the saved config.
+ */
+export async function previewLibraryNaming(body: {
+  library_folder_template?: string
+  library_file_template?: string
+  rename_files_for_jellyfin?: boolean
+  library_season_folders?: boolean
+  library_movie_layout?: boolean
+  library_movies_path?: string
+  limit?: number
+}): Promise<NamingPreview> {
+  return apiRequest<NamingPreview>('POST', '/library/naming/preview', body)
+}
+
+export interface LinkProbe {
+  completed_anime_path: string
+  /** Os modos que funcionaram, na ordem de `modes`. */
+  supported: LibraryLinkMode[]
+  modes: LibraryLinkMode[]
+  current: LibraryLinkMode
+}
+
+/**
+ * Tries every library link mode on the library's filesystem. An empty path probes the saved
+ * library.
+ */
+export async function probeLibraryLinks(completedAnimePath?: string): Promise<LinkProbe> {
+  return apiRequest<LinkProbe>('POST', '/library/link-probe', { completed_anime_path: completedAnimePath ?? '' })
+}
+
+export type AuditCategory =
+  | 'broken_link'
+  | 'record_without_torrent'
+  | 'orphan_torrent'
+  | 'duplicate'
+  | 'orphan_file'
+
+export interface AuditFinding {
+  category: AuditCategory
+  path?: string
+  hash?: string
+  anime_id?: number
+  anime_name?: string
+  episode_numbers?: number[]
+  detail: string
+}
+
+export interface LibraryAudit {
+  findings: AuditFinding[]
+  /** Todas as categorias, inclusive as zeradas. */
+  counts: Record<AuditCategory, number>
+}
+
+export interface LibraryRepairResult {
+  repaired: Partial<Record<AuditCategory, number>>
+  failed: { finding: AuditFinding; error: string }[]
+}
+
+/** Cross-checks the records, the library on disk and the torrent client. Changes nothing. */
+export async function auditLibrary(): Promise<LibraryAudit> {
+  return apiRequest<LibraryAudit>('GET', '/library/audit')
+}
+
+/** Audits again and fixes the findings of the given categories. */
+export async function repairLibrary(categories: AuditCategory[]): Promise<LibraryRepairResult> {
+  return apiRequest<LibraryRepairResult>('POST', '/library/audit/repair', { categories })
+}
+
+export interface PauseAllResult {
+  paused: boolean
+  /** Prazo da pausa; null quando ela vale até o resume-all. */
+  until: string | null
+}
+
+/**
+ * Pauses every torrent, downloading and seeding. `durationMinutes` > 0 resumes on its own after
+ * that long; 0 pauses until `resumeAllTorrents`.
+ */
+export async function pauseAllTorrents(durationMinutes = 0): Promise<PauseAllResult> {
+  return apiRequest<PauseAllResult>('POST', '/torrents/pause-all', { duration_minutes: durationMinutes })
+}
+
+/** Ends a pause-all. Torrents paused one by one before it stay paused. */
+export async function resumeAllTorrents(): Promise<PauseAllResult> {
+  return apiRequest<PauseAllResult>('POST', '/torrents/resume-all')
+}
+
 export async function announceTorrent(hash: string): Promise<void> {
   return apiRequest<void>('POST', `/torrents/${hash}/announce`)
 }
 
+/**
+ * Adds the configured extra trackers to a torrent already in the session. Torrents added
+ * after the trackers were configured already have them; this is for the older ones.
+ */
+export async function addTorrentTrackers(hash: string): Promise<TrackersResult> {
+  return apiRequest<TrackersResult>('POST', `/torrents/${hash}/trackers`)
+}
+
+export interface RecheckResult {
+  pieces_total: number
+  pieces_have: number
+  /** Peças que o torrent tinha e falharam na verificação; estão sendo baixadas de novo. */
+  pieces_failed: number
+  completed: boolean
+}
+
+/**
+ * Re-verifies the torrent's data on disk. Resolves only when the verification ends — seconds
+ * for an episode, minutes for a large batch.
+ */
+export async function recheckTorrent(hash: string): Promise<RecheckResult> {
+  return apiRequest<RecheckResult>('POST', `/torrents/${hash}/recheck`)
+}
+
 export async function deleteTorrent(
   hash: string,
   opts: { keepData: boolean; block: boolean },
@@ -444,3 +802,176 @@ export async function testWebhook(name: string): Promise<void> {
   }
 }
 
+/** Testa a conexão com um servidor de mídia já salvo; devolve o nome e a versão que ele reporta. */
+export async function testMediaServer(name: string): Promise<MediaServerInfo> {
+  return apiRequest<MediaServerInfo>('POST', `/media-servers/${encodeURIComponent(name)}/test`, null, { silent: true })
+}
+
+/**
+ * URL do webhook de reprodução que o servidor chama quando um episódio é assistido (plugin
+ * Webhook do Jellyfin, Webhooks do Plex). O Emby manda outro payload e não tem URL.
+ */
+export function playbackWebhookUrl(server: MediaServer): string | null {
+  if (server.type !== 'jellyfin' && server.type !== 'plex') return null
+  return `${API_BASE_URL}/integrations/${server.type}/playback?server=${encodeURIComponent(server.name)}`
+}
+
+export interface AnilistAccountStatus {
+  username: string
+  logged_in: boolean
+  expired?: boolean
+  expires_at?: string
+}
+
+export interface AnilistAccounts {
+  accounts: AnilistAccountStatus[]
+  /** Falso até anilist_client_id existir: antes disso não há login possível. */
+  client_configured: boolean
+  /** Sem client secret o login mostra um token para colar em saveAnilistToken. */
+  implicit: boolean
+  /** URL a registrar no cliente de API da AniList para o login por código. */
+  redirect_uri: string
+}
+
+export type WriteBackAction = 'updated' | 'already_watched' | 'not_in_list' | 'no_token' | 'failed'
+
+export interface AccountWriteBack {
+  username: string
+  action: WriteBackAction
+  completed?: boolean
+  error?: string
+}
+
+export interface WatchedResult {
+  standalone: boolean
+  progress?: number
+  accounts?: AccountWriteBack[]
+}
+
+export async function getAnilistAccounts(): Promise<AnilistAccounts> {
+  return apiRequest<AnilistAccounts>('GET', '/anilist/accounts')
+}
+
+/** Devolve a URL de login da AniList para a conta; com implicit, a AniList mostra um token para colar. */
+export async function startAnilistLogin(username: string): Promise<{ url: string; implicit: boolean }> {
+  return apiRequest<{ url: string; implicit: boolean }>('POST', `/anilist/accounts/${encodeURIComponent(username)}/login`)
+}
+
+export async function saveAnilistToken(username: string, accessToken: string): Promise<void> {
+  return apiRequest<void>('PUT', `/anilist/accounts/${encodeURIComponent(username)}/token`, { access_token: accessToken })
+}
+
+export async function logoutAnilist(username: string): Promise<void> {
+  return apiRequest<void>('DELETE', `/anilist/accounts/${encodeURIComponent(username)}/token`)
+}
+
+/**
+ * Marca o episódio como assistido: sobe o progresso do avulso, ou o da AniList em cada conta
+ * com login. Conta sem login não é erro — vem em `accounts` com a ação de cada uma.
+ */
+export async function markEpisodeWatched(animeId: number, episodeNumber: number): Promise<WatchedResult> {
+  return apiRequest<WatchedResult>('POST', `/animes/${animeId}/episodes/${episodeNumber}/watched`)
+}
+
+/** Coloca o avulso na lista da AniList da conta, com o progresso salvo. */
+export async function addStandaloneToList(mediaId: number, username: string, status = 'CURRENT'): Promise<void> {
+  return apiRequest<void>('POST', `/standalone-animes/${mediaId}/list`, { username, status })
+}
+
+export type MediaSeason = 'WINTER' | 'SPRING' | 'SUMMER' | 'FALL'
+
+/** Critério vazio casa com tudo; formats e genres são "qualquer um de". */
+export interface AutoSubscribeRule {
+  name: string
+  /** Desligada, a regra só aparece na prévia de getRuleMatches. */
+  enabled: boolean
+  formats: string[]
+  genres: string[]
+  min_popularity: number
+  /** Nota média 0..100. Anime ainda sem nota não passa quando > 0. */
+  min_score: number
+  country: string
+  exclude_adult: boolean
+  /** '' e 0 seguem a temporada corrente. */
+  season: '' | MediaSeason
+  season_year: number
+  /** Quantos episódios do começo o teste baixa. */
+  trial_episodes: number
+}
+
+export type TrialState = 'trial' | 'kept' | 'expired' | 'left'
+
+export interface RuleMatch {
+  media_id: number
+  title: string
+  format: string
+  popularity: number
+  average_score: number | null
+  cover?: string
+  /** Um motivo por critério da regra, já formatado pelo servidor ("score 78 >= 70"). */
+  reasons: string[]
+  /** Já houve teste deste anime: a regra não o adiciona de novo. */
+  trial_state?: TrialState
+  block_reason?: BlockReason
+}
+
+export interface RuleMatches {
+  rule: string
+  enabled: boolean
+  season: MediaSeason
+  season_year: number
+  /** Falha ao ler a temporada na AniList; matches vem vazio. */
+  error?: string
+  matches: RuleMatch[]
+}
+
+export interface Trial {
+  media_id: number
+  rule: string
+  title: string
+  trial_episodes: number
+  state: TrialState
+  added_at: string
+  ended_at?: string
+  /** Quando o daemon remove o teste. Ausente quando ele não expira. */
+  expires_at?: string
+}
+
+/** Prévia das regras: o que cada uma pega na temporada, inclusive as desligadas. */
+export async function getRuleMatches(): Promise<RuleMatches[]> {
+  return apiRequest<RuleMatches[]>('GET', '/auto-subscribe/matches')
+}
+
+export async function getTrials(): Promise<Trial[]> {
+  return apiRequest<Trial[]>('GET', '/trials')
+}
+
+/** Fica com o anime: o limite de episódios sai e ele nunca expira. */
+export async function keepTrial(mediaId: number): Promise<void> {
+  return apiRequest<void>('POST', `/trials/${mediaId}/keep`)
+}
+
+export type ListChangeKind = 'added' | 'removed' | 'status_changed' | 'progress_changed'
+
+export interface ListChange {
+  at: string
+  /** Usuário da AniList, ou "myanimelist:usuario" / "kitsu:usuario". */
+  account: string
+  media_id: number
+  title: string
+  kind: ListChangeKind
+  /** Vazio em "added"; new_status vazio em "removed". */
+  old_status?: string
+  new_status?: string
+  old_progress: number
+  new_progress: number
+}
+
+/** O que mudou nas listas entre os passes, o mais novo primeiro. */
+export async function getListChanges(params: { limit?: number; account?: string } = {}): Promise<ListChange[]> {
+  const query = new URLSearchParams()
+  if (params.limit) query.set('limit', String(params.limit))
+  if (params.account) query.set('account', params.account)
+  const qs = query.toString()
+  return apiRequest<ListChange[]>('GET', `/list-changes${qs ? `?${qs}` : ''}`)
+}
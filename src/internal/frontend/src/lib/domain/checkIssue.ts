@@ -20,8 +20,12 @@ export function issueMessage(issue: Issue): string {
       return m.lastcheck_no_torrent_found()
     case 'disk_full':
       return m.lastcheck_disk_full()
+    case 'data_cap_reached':
+      return m.lastcheck_data_cap_reached()
     case 'torrent_rejected':
       return m.lastcheck_torrent_rejected({ candidates: issue.candidates ?? 0 })
+    case 'data_corrupted':
+      return m.lastcheck_data_corrupted({ pieces: issue.bad_pieces ?? 0 })
     case 'max_episodes_per_anime':
       return m.lastcheck_max_episodes_per_anime({
         downloaded: issue.downloaded ?? 0,
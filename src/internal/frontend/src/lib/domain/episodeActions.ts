@@ -22,7 +22,7 @@ import type { AnimeEpisodeInfo, TorrentInfo } from '../api/client.js'
  * step; this module only flags that requirement, it doesn't enforce it.
  */
 
-export type EpisodeActionId = 'download' | 'redownload' | 'delete' | 'release' | 'replace' | 'watchedHere'
+export type EpisodeActionId = 'download' | 'redownload' | 'delete' | 'release' | 'replace' | 'watchedHere' | 'markWatched'
 
 export interface Action {
   id: EpisodeActionId
@@ -44,6 +44,7 @@ const DELETE: Action = { id: 'delete', labelKey: 'delete', variant: 'warn', dest
 const RELEASE: Action = { id: 'release', labelKey: 'release', variant: 'ghost' }
 const REPLACE: Action = { id: 'replace', labelKey: 'replace', variant: 'ghost' }
 const WATCHED_HERE: Action = { id: 'watchedHere', labelKey: 'watchedHere', variant: 'ghost' }
+const MARK_WATCHED: Action = { id: 'markWatched', labelKey: 'markWatched', variant: 'ghost' }
 
 export interface EpisodeActionOptions {
   /**
@@ -88,9 +89,11 @@ function classify(ep: AnimeEpisodeInfo, torrent: TorrentInfo | undefined): Episo
 }
 
 /**
- * Wraps `classify` with the standalone-only "Assisti até aqui" action. Kept separate from the
- * cascade above because it isn't a state of the episode itself — it's a capability of the
- * anime (avulso vs. de lista) that applies on top of whatever state `classify` already picked.
+ * Wraps `classify` with the progress action. Kept separate from the cascade above because it
+ * isn't a state of the episode itself — it's a capability of the anime (avulso vs. de lista)
+ * that applies on top of whatever state `classify` already picked: the standalone-only
+ * "Assisti até aqui", or, on a list anime, "Marcar como assistido", which moves the AniList
+ * progress of the logged-in accounts (decisions.md #82).
  */
 export function episodeActions(
   ep: AnimeEpisodeInfo,
@@ -98,6 +101,6 @@ export function episodeActions(
   opts: EpisodeActionOptions = {},
 ): EpisodeActionSet {
   const set = classify(ep, torrent)
-  if (!opts.standalone || !ep.is_aired) return set
-  return { ...set, menu: [...set.menu, WATCHED_HERE] }
+  if (!ep.is_aired) return set
+  return { ...set, menu: [...set.menu, opts.standalone ? WATCHED_HERE : MARK_WATCHED] }
 }
@@ -12,7 +12,7 @@
  * assim a troca de idioma dispara um novo render. Quem consumir este array deve mapear
  * `item.label()` dentro desse bloco reativo, não direto no template.
  */
-import { Activity, Bell, Download, Ellipsis, ListOrdered, Plus, ScrollText, Settings } from '@lucide/svelte'
+import { Activity, Bell, CalendarPlus, Download, Ellipsis, History, ListOrdered, Plus, ScrollText, Settings, Users } from '@lucide/svelte'
 import * as m from './i18n/messages.js'
 
 /** Todo ícone Lucide usado aqui tem essa mesma assinatura de componente. */
@@ -50,8 +50,11 @@ export const secondaryNavItems: NavItem[] = [
 
 /** Itens hospedados dentro do MoreMenu, atrás do gatilho "Mais". */
 export const moreMenuItems: NavItem[] = [
+  { id: 'auto-subscribe', path: '/auto-subscribe', icon: CalendarPlus, label: m.nav_auto_subscribe },
+  { id: 'list-changes', path: '/list-changes', icon: History, label: m.nav_list_changes },
   { id: 'notifications', path: '/notifications', icon: Bell, label: m.nav_notifications },
   { id: 'priorities', path: '/priorities', icon: ListOrdered, label: m.nav_priorities },
+  { id: 'profiles', path: '/profiles', icon: Users, label: m.nav_profiles },
   { id: 'logs', path: '/logs', icon: ScrollText, label: m.nav_logs },
 ]
 
@@ -96,6 +96,10 @@ export const baseConfig = {
     watched_episodes_to_keep: 0,
     excluded_lists: [],
     rename_files_for_jellyfin: false,
+    library_folder_template: '{title}',
+    library_file_template: '{title} - E{episode:02}',
+    library_season_folders: false,
+    library_link_mode: 'hardlink',
     download_statuses: ['CURRENT', 'REPEATING'],
     download_media_statuses: ['RELEASING', 'FINISHED'],
     delete_statuses: [],
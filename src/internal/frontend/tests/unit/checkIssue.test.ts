@@ -25,6 +25,10 @@ describe('issueMessage', () => {
     expect(text).toContain('35')
   })
 
+  it('interpola as peças corrompidas', () => {
+    expect(issueMessage(issue({ code: 'data_corrupted', bad_pieces: 7 }))).toContain('7')
+  })
+
   it('tem frase para cada código conhecido', () => {
     const codes = [
       'all_above_size_limit',
@@ -32,6 +36,7 @@ describe('issueMessage', () => {
       'no_torrent_found',
       'disk_full',
       'torrent_rejected',
+      'data_corrupted',
       'max_episodes_per_anime',
     ]
     for (const code of codes) {
@@ -0,0 +1,236 @@
+// Package idmap is the offline anime id mapping: an index, kept in memory, of the
+// anime-offline-database dataset (github.com/manami-project/anime-offline-database), which
+// cross-references every anime between AniList, MyAnimeList, AniDB and Kitsu among others.
+//
+// The daemon keys everything by AniList media id; this is how an id from another site (a list
+// site, an indexer, a media server plugin) finds its way to that key, and back.
+package idmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// IDs is one anime's id on each site the mapping covers; 0 when the dataset has none.
+type IDs struct {
+	AniList     int `json:"anilist" example:"154587"`
+	MyAnimeList int `json:"myanimelist,omitempty" example:"52991"`
+	AniDB       int `json:"anidb,omitempty" example:"17617"`
+	Kitsu       int `json:"kitsu,omitempty" example:"46474"`
+}
+
+// Entry is one anime of the dataset.
+type Entry struct {
+	IDs
+	Title string
+	// Synonyms sao so os de escrita latina: servem de variante de busca no Nyaa, onde titulo em
+	// japones ou em cirilico nao casa com o nome dos torrents.
+	Synonyms []string
+}
+
+// Status describes the index in memory.
+type Status struct {
+	Loaded  bool `json:"loaded"`
+	Entries int  `json:"entries" example:"39000"`
+	// DatasetDate is the dataset's own lastUpdate, as it wrote it.
+	DatasetDate string `json:"dataset_date,omitempty" example:"2026-10-12"`
+	// SavedAt is when the file on disk was written (downloaded or dropped in the config folder).
+	SavedAt time.Time `json:"saved_at,omitempty"`
+}
+
+// Index is a parsed dataset.
+type Index struct {
+	entries     []Entry
+	byAniList   map[int]int
+	byMAL       map[int]int
+	byAniDB     map[int]int
+	byKitsu     map[int]int
+	datasetDate string
+}
+
+// dataset e o formato do anime-offline-database (o JSON completo e o minified sao o mesmo):
+// so os campos lidos aqui.
+type dataset struct {
+	LastUpdate string `json:"lastUpdate"`
+	Data       []struct {
+		Sources  []string `json:"sources"`
+		Title    string   `json:"title"`
+		Synonyms []string `json:"synonyms"`
+	} `json:"data"`
+}
+
+// Parse reads an anime-offline-database JSON file. An entry without an AniList source is kept
+// out: nothing in the daemon could use it.
+func Parse(data []byte) (*Index, error) {
+	var ds dataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return nil, fmt.Errorf("invalid anime-offline-database file: %w", err)
+	}
+	idx := &Index{
+		byAniList:   make(map[int]int, len(ds.Data)),
+		byMAL:       make(map[int]int, len(ds.Data)),
+		byAniDB:     make(map[int]int, len(ds.Data)),
+		byKitsu:     make(map[int]int, len(ds.Data)),
+		datasetDate: ds.LastUpdate,
+	}
+	for _, d := range ds.Data {
+		var e Entry
+		for _, src := range d.Sources {
+			site, id := sourceID(src)
+			switch site {
+			case "anilist":
+				e.AniList = id
+			case "myanimelist":
+				e.MyAnimeList = id
+			case "anidb":
+				e.AniDB = id
+			case "kitsu":
+				e.Kitsu = id
+			}
+		}
+		// Uma midia que a AniList juntou e o dataset nao (ou o contrario) aparece duas vezes;
+		// a primeira fica, como em qualquer busca por id.
+		if e.AniList == 0 || idx.byAniList[e.AniList] != 0 {
+			continue
+		}
+		e.Title = d.Title
+		for _, s := range d.Synonyms {
+			if isLatin(s) {
+				e.Synonyms = append(e.Synonyms, s)
+			}
+		}
+		idx.entries = append(idx.entries, e)
+		n := len(idx.entries) // 1-based: 0 e "ausente" nos mapas
+		idx.byAniList[e.AniList] = n
+		setIfAbsent(idx.byMAL, e.MyAnimeList, n)
+		setIfAbsent(idx.byAniDB, e.AniDB, n)
+		setIfAbsent(idx.byKitsu, e.Kitsu, n)
+	}
+	if len(idx.entries) == 0 {
+		return nil, fmt.Errorf("anime-offline-database file has no AniList entries")
+	}
+	return idx, nil
+}
+
+func setIfAbsent(m map[int]int, id, n int) {
+	if id != 0 && m[id] == 0 {
+		m[id] = n
+	}
+}
+
+// sourceID le "https://anilist.co/anime/154587" como ("anilist", 154587). O Kitsu mudou de
+// kitsu.io para kitsu.app; datasets antigos ainda tem o dominio velho.
+func sourceID(src string) (string, int) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return "", 0
+	}
+	rest, ok := strings.CutPrefix(u.Path, "/anime/")
+	if !ok {
+		return "", 0
+	}
+	id, err := strconv.Atoi(strings.Trim(rest, "/"))
+	if err != nil || id <= 0 {
+		return "", 0
+	}
+	switch strings.TrimPrefix(u.Host, "www.") {
+	case "anilist.co":
+		return "anilist", id
+	case "myanimelist.net":
+		return "myanimelist", id
+	case "anidb.net":
+		return "anidb", id
+	case "kitsu.app", "kitsu.io":
+		return "kitsu", id
+	}
+	return "", 0
+}
+
+// isLatin aceita um titulo sem letra fora do alfabeto latino (acentos e digitos passam).
+func isLatin(s string) bool {
+	hasLetter := false
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			if !unicode.Is(unicode.Latin, r) {
+				return false
+			}
+			hasLetter = true
+		}
+	}
+	return hasLetter
+}
+
+var (
+	mu      sync.RWMutex
+	current *Index
+	savedAt time.Time
+)
+
+// Load parses a dataset and makes it the index every lookup uses. savedAt is the file's write
+// time, reported by CurrentStatus. A file that fails to parse leaves the previous index in place.
+func Load(data []byte, at time.Time) error {
+	idx, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	current, savedAt = idx, at
+	return nil
+}
+
+// Reset drops the index; every lookup misses until the next Load.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	current, savedAt = nil, time.Time{}
+}
+
+// CurrentStatus reports the index in memory.
+func CurrentStatus() Status {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current == nil {
+		return Status{}
+	}
+	return Status{Loaded: true, Entries: len(current.entries), DatasetDate: current.datasetDate, SavedAt: savedAt}
+}
+
+func lookup(pick func(*Index) map[int]int, id int) (Entry, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current == nil || id <= 0 {
+		return Entry{}, false
+	}
+	n := pick(current)[id]
+	if n == 0 {
+		return Entry{}, false
+	}
+	return current.entries[n-1], true
+}
+
+// ByAniList looks an anime up by AniList media id.
+func ByAniList(id int) (Entry, bool) {
+	return lookup(func(i *Index) map[int]int { return i.byAniList }, id)
+}
+
+// ByMyAnimeList looks an anime up by MyAnimeList id.
+func ByMyAnimeList(id int) (Entry, bool) {
+	return lookup(func(i *Index) map[int]int { return i.byMAL }, id)
+}
+
+// ByAniDB looks an anime up by AniDB anime id (the aid).
+func ByAniDB(id int) (Entry, bool) {
+	return lookup(func(i *Index) map[int]int { return i.byAniDB }, id)
+}
+
+// ByKitsu looks an anime up by Kitsu anime id.
+func ByKitsu(id int) (Entry, bool) {
+	return lookup(func(i *Index) map[int]int { return i.byKitsu }, id)
+}
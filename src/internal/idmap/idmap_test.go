@@ -0,0 +1,103 @@
+package idmap
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+const sampleDataset = `{
+	"license": {"name": "ODbL"},
+	"lastUpdate": "2026-10-12",
+	"data": [
+		{
+			"sources": [
+				"https://anidb.net/anime/17617",
+				"https://anilist.co/anime/154587",
+				"https://kitsu.app/anime/46474",
+				"https://myanimelist.net/anime/52991",
+				"https://anime-planet.com/anime/frieren-beyond-journeys-end"
+			],
+			"title": "Sousou no Frieren",
+			"type": "TV",
+			"episodes": 28,
+			"synonyms": ["Frieren: Beyond Journey's End", "葬送のフリーレン", "Frieren – Nach dem Ende der Reise", "Фрирен"]
+		},
+		{
+			"sources": ["https://kitsu.io/anime/1376", "https://anilist.co/anime/1535/"],
+			"title": "Death Note",
+			"synonyms": []
+		},
+		{
+			"sources": ["https://anilist.co/anime/1535", "https://myanimelist.net/anime/99999"],
+			"title": "Death Note (duplicate)"
+		},
+		{
+			"sources": ["https://myanimelist.net/anime/1"],
+			"title": "Not on AniList"
+		}
+	]
+}`
+
+func TestParseIndexesEverySite(t *testing.T) {
+	t.Cleanup(Reset)
+	at := time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)
+	if err := Load([]byte(sampleDataset), at); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := IDs{AniList: 154587, MyAnimeList: 52991, AniDB: 17617, Kitsu: 46474}
+	for name, lookup := range map[string]func() (Entry, bool){
+		"anilist":     func() (Entry, bool) { return ByAniList(154587) },
+		"myanimelist": func() (Entry, bool) { return ByMyAnimeList(52991) },
+		"anidb":       func() (Entry, bool) { return ByAniDB(17617) },
+		"kitsu":       func() (Entry, bool) { return ByKitsu(46474) },
+	} {
+		e, ok := lookup()
+		if !ok || e.IDs != want {
+			t.Errorf("%s lookup = %+v, %v; want %+v", name, e.IDs, ok, want)
+		}
+	}
+
+	e, _ := ByAniList(154587)
+	if !slices.Equal(e.Synonyms, []string{"Frieren: Beyond Journey's End", "Frieren – Nach dem Ende der Reise"}) {
+		t.Errorf("synonyms = %q, want only the Latin-script ones", e.Synonyms)
+	}
+
+	// kitsu.io e barra final ainda valem; a entrada repetida nao substitui a primeira.
+	if e, ok := ByKitsu(1376); !ok || e.AniList != 1535 || e.Title != "Death Note" {
+		t.Errorf("old kitsu domain = %+v, %v", e, ok)
+	}
+	if _, ok := ByMyAnimeList(99999); ok {
+		t.Error("a duplicated AniList id must not index the second entry")
+	}
+	if _, ok := ByMyAnimeList(1); ok {
+		t.Error("an entry without AniList source must be left out")
+	}
+
+	st := CurrentStatus()
+	if !st.Loaded || st.Entries != 2 || st.DatasetDate != "2026-10-12" || !st.SavedAt.Equal(at) {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestLoadKeepsPreviousIndexOnBadFile(t *testing.T) {
+	t.Cleanup(Reset)
+	if err := Load([]byte(sampleDataset), time.Now()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := Load([]byte(`<html>rate limited</html>`), time.Now()); err == nil {
+		t.Fatal("want an error for a file that is not the dataset")
+	}
+	if err := Load([]byte(`{"data":[]}`), time.Now()); err == nil {
+		t.Fatal("want an error for a dataset without AniList entries")
+	}
+	if _, ok := ByAniList(154587); !ok {
+		t.Error("a bad file replaced the index in memory")
+	}
+
+	Reset()
+	if _, ok := ByAniList(154587); ok || CurrentStatus().Loaded {
+		t.Error("Reset must empty the index")
+	}
+}
@@ -0,0 +1,145 @@
+package lists
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/idmap"
+)
+
+// kitsuBase e a API JSON:API do Kitsu; variavel para os testes.
+var kitsuBase = "https://kitsu.app/api/edge"
+
+// kitsuPageSize e o maior page[limit] que o Kitsu aceita em library-entries.
+const kitsuPageSize = 500
+
+// kitsuProvider le a biblioteca publica do usuario. O id da AniList vem dos mappings do
+// proprio Kitsu; sem ele, o do MAL, que a AniList mapeia.
+type kitsuProvider struct{}
+
+var kitsuStatuses = map[string]anilist.MediaListStatus{
+	"current":   anilist.MediaListStatusCurrent,
+	"planned":   anilist.MediaListStatusPlanning,
+	"completed": anilist.MediaListStatusCompleted,
+	"on_hold":   anilist.MediaListStatusPaused,
+	"dropped":   anilist.MediaListStatusDropped,
+}
+
+type kitsuRef struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+func (p *kitsuProvider) Entries(username string) ([]Entry, error) {
+	userID, err := kitsuUserID(username)
+	if err != nil {
+		return nil, err
+	}
+
+	type page struct {
+		Data []struct {
+			Attributes struct {
+				Status      string `json:"status"`
+				Progress    int    `json:"progress"`
+				Reconsuming bool   `json:"reconsuming"`
+			} `json:"attributes"`
+			Relationships struct {
+				Anime struct {
+					Data *kitsuRef `json:"data"`
+				} `json:"anime"`
+			} `json:"relationships"`
+		} `json:"data"`
+		Included []struct {
+			kitsuRef
+			Attributes struct {
+				ExternalSite string `json:"externalSite"`
+				ExternalID   string `json:"externalId"`
+			} `json:"attributes"`
+			Relationships struct {
+				Mappings struct {
+					Data []kitsuRef `json:"data"`
+				} `json:"mappings"`
+			} `json:"relationships"`
+		} `json:"included"`
+		Links struct {
+			Next string `json:"next"`
+		} `json:"links"`
+	}
+
+	var out []Entry
+	next := fmt.Sprintf("%s/library-entries?filter[userId]=%s&filter[kind]=anime&include=anime.mappings&page[limit]=%d",
+		kitsuBase, url.QueryEscape(userID), kitsuPageSize)
+	for next != "" {
+		var pg page
+		if err := getJSON(next, nil, &pg); err != nil {
+			return nil, err
+		}
+
+		// O JSON:API poe os mappings soltos em included; o anime aponta para eles por id.
+		mappings := map[string][2]string{}
+		animeMappings := map[string][]kitsuRef{}
+		for _, inc := range pg.Included {
+			switch inc.Type {
+			case "mappings":
+				mappings[inc.ID] = [2]string{inc.Attributes.ExternalSite, inc.Attributes.ExternalID}
+			case "anime":
+				animeMappings[inc.ID] = inc.Relationships.Mappings.Data
+			}
+		}
+
+		for _, d := range pg.Data {
+			status, ok := kitsuStatuses[d.Attributes.Status]
+			if !ok || d.Relationships.Anime.Data == nil {
+				continue
+			}
+			if d.Attributes.Reconsuming {
+				status = anilist.MediaListStatusRepeating
+			}
+			e := Entry{Status: status, Progress: d.Attributes.Progress}
+			for _, ref := range animeMappings[d.Relationships.Anime.Data.ID] {
+				m := mappings[ref.ID]
+				id, err := strconv.Atoi(m[1])
+				if err != nil {
+					continue
+				}
+				switch m[0] {
+				case "anilist/anime":
+					e.MediaID = id
+				case "myanimelist/anime":
+					e.MalID = id
+				}
+			}
+			if e.MediaID == 0 && e.MalID == 0 {
+				// Sem mapping no Kitsu, o dataset offline ainda pode conhecer o id dele.
+				kitsuID, _ := strconv.Atoi(d.Relationships.Anime.Data.ID)
+				m, ok := idmap.ByKitsu(kitsuID)
+				if !ok {
+					continue
+				}
+				e.MediaID = m.AniList
+			}
+			out = append(out, e)
+		}
+		next = pg.Links.Next
+	}
+	return out, nil
+}
+
+// kitsuUserID acha o usuario pelo slug da URL do perfil e, sem ele, pelo nome de exibicao.
+func kitsuUserID(username string) (string, error) {
+	type users struct {
+		Data []kitsuRef `json:"data"`
+	}
+	for _, filter := range []string{"slug", "name"} {
+		var u users
+		if err := getJSON(fmt.Sprintf("%s/users?filter[%s]=%s", kitsuBase, filter, url.QueryEscape(username)), nil, &u); err != nil {
+			return "", err
+		}
+		if len(u.Data) > 0 {
+			return u.Data[0].ID, nil
+		}
+	}
+	return "", ErrUserNotFound
+}
@@ -0,0 +1,276 @@
+// Package lists reads the anime lists of MyAnimeList and Kitsu accounts and maps them onto
+// AniList media ids, so an account on another site feeds the daemon through the same
+// anilist.MediaList the AniList accounts do: DownloadStatuses, DeleteStatuses, the per-account
+// dedupe and the pruning by progress never learn where a list came from.
+package lists
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/idmap"
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// List sites besides AniList (Account.Provider).
+const (
+	ProviderMyAnimeList = "myanimelist"
+	ProviderKitsu       = "kitsu"
+)
+
+// httpTimeout bounds every call to a list site.
+const httpTimeout = 30 * time.Second
+
+// entriesTTL segura a lista lida por um minuto, como o frontendListCache da AniList: o passe
+// le a lista para baixar e de novo para deletar, e a tela faz poll de 30s.
+const entriesTTL = 60 * time.Second
+
+var httpDo = func(req *http.Request) (*http.Response, error) {
+	client := &http.Client{Timeout: httpTimeout}
+	return client.Do(req)
+}
+
+// ErrUserNotFound is a username the list site does not know.
+var ErrUserNotFound = errors.New("list user not found")
+
+// Account is one list account outside AniList.
+type Account struct {
+	Provider string
+	Username string
+}
+
+// Key identifies the account among every account of the pass, AniList's included (those are
+// keyed by the bare username, which never has a colon).
+func (a Account) Key() string {
+	return a.Provider + ":" + a.Username
+}
+
+// Accounts lists the configured MyAnimeList and Kitsu accounts.
+func Accounts(cfg *files.Config) []Account {
+	out := make([]Account, 0, len(cfg.MALUsernames)+len(cfg.KitsuUsernames))
+	for _, u := range cfg.MALUsernames {
+		out = append(out, Account{Provider: ProviderMyAnimeList, Username: u})
+	}
+	for _, u := range cfg.KitsuUsernames {
+		out = append(out, Account{Provider: ProviderKitsu, Username: u})
+	}
+	return out
+}
+
+// Entry is one anime of a list, with its status mapped onto AniList's.
+type Entry struct {
+	// MediaID is the AniList media id; 0 until Entries maps it.
+	MediaID int
+	// MalID is the MyAnimeList id, when the site knows it (MAL always, Kitsu through its mappings).
+	MalID    int
+	Status   anilist.MediaListStatus
+	Progress int
+}
+
+// ListProvider reads the whole anime list of a user of one list site.
+type ListProvider interface {
+	// Entries returns every anime on the list. A provider fills MediaID only when the site
+	// itself knows the AniList id; the rest is mapped through MalID.
+	Entries(username string) ([]Entry, error)
+}
+
+// NewProvider returns the provider of an account.
+func NewProvider(acc Account, cfg *files.Config) (ListProvider, error) {
+	switch acc.Provider {
+	case ProviderMyAnimeList:
+		return &malProvider{clientID: cfg.MALClientID}, nil
+	case ProviderKitsu:
+		return &kitsuProvider{}, nil
+	}
+	return nil, fmt.Errorf("unknown list provider %q", acc.Provider)
+}
+
+type cachedEntries struct {
+	entries []Entry
+	expires time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[string]cachedEntries{}
+)
+
+// MockListsDo troca o transporte HTTP dos sites de lista e esquece as listas lidas, como o
+// anilist.MockAniListDo: o teste precisa ver as respostas do proprio mock.
+func MockListsDo(fn func(*http.Request) (*http.Response, error)) (restore func()) {
+	prev := httpDo
+	clearCache()
+	if fn != nil {
+		httpDo = fn
+	}
+	return func() { httpDo = prev; clearCache() }
+}
+
+func clearCache() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cache = map[string]cachedEntries{}
+}
+
+// Entries reads the account's list and maps every entry to its AniList media id. An entry
+// AniList does not know is left out: nothing downstream can search or name it.
+func Entries(acc Account, cfg *files.Config) ([]Entry, error) {
+	cacheMu.Lock()
+	c, ok := cache[acc.Key()]
+	cacheMu.Unlock()
+	if ok && time.Now().Before(c.expires) {
+		return c.entries, nil
+	}
+
+	p, err := NewProvider(acc, cfg)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := p.Entries(acc.Username)
+	if err != nil {
+		return nil, fmt.Errorf("%s list of %q: %w", acc.Provider, acc.Username, err)
+	}
+	if entries, err = mapToAniList(entries); err != nil {
+		return nil, fmt.Errorf("mapping the %s list of %q to AniList: %w", acc.Provider, acc.Username, err)
+	}
+
+	cacheMu.Lock()
+	cache[acc.Key()] = cachedEntries{entries: entries, expires: time.Now().Add(entriesTTL)}
+	cacheMu.Unlock()
+	return entries, nil
+}
+
+// mapToAniList preenche o MediaID pelo id do MAL e descarta o que a AniList nao conhece. O
+// mapeamento offline (idmap) vem primeiro e nao custa request; a AniList so e perguntada pelo
+// que ele nao conhece — anime novo demais para o dataset da semana, ou nenhum dataset.
+func mapToAniList(entries []Entry) ([]Entry, error) {
+	var malIDs []int
+	for i, e := range entries {
+		if e.MediaID != 0 || e.MalID == 0 {
+			continue
+		}
+		if m, ok := idmap.ByMyAnimeList(e.MalID); ok {
+			entries[i].MediaID = m.AniList
+			continue
+		}
+		malIDs = append(malIDs, e.MalID)
+	}
+	byMAL, err := anilist.MediaIDsByMAL(malIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if e.MediaID == 0 {
+			e.MediaID = byMAL[e.MalID]
+		}
+		if e.MediaID == 0 {
+			logger.Logger.Debug().Int("mal_id", e.MalID).Msg("List entry has no AniList media, leaving it out")
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// MediaLists is the account's list restricted to statuses, as the anilist.MediaList the AniList
+// accounts produce: the media from AniList, the status and progress from the list site. Id and
+// CustomLists stay empty — an AniList entry id and AniList custom lists do not exist there.
+func MediaLists(acc Account, cfg *files.Config, statuses []string) ([]anilist.MediaList, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+	entries, err := Entries(acc, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var wanted []Entry
+	ids := make([]int, 0, len(entries))
+	for _, e := range entries {
+		if slices.Contains(statuses, string(e.Status)) {
+			wanted = append(wanted, e)
+			ids = append(ids, e.MediaID)
+		}
+	}
+	media, err := anilist.GetMediaByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]anilist.MediaList, 0, len(wanted))
+	for _, e := range wanted {
+		ml, ok := media[e.MediaID]
+		if !ok {
+			continue
+		}
+		entry := *ml
+		entry.Status = e.Status
+		entry.Progress = e.Progress
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+// Status is the account's status for one media; tracked is false when the media is not on the
+// list. The list-site twin of anilist.GetMediaListStatus.
+func Status(acc Account, cfg *files.Config, mediaID int) (anilist.MediaListStatus, bool, error) {
+	entries, err := Entries(acc, cfg)
+	if err != nil {
+		return "", false, err
+	}
+	for _, e := range entries {
+		if e.MediaID == mediaID {
+			return e.Status, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// Find returns the account's entry for one media, nil when it is not on the list.
+func Find(acc Account, cfg *files.Config, mediaID int) (*Entry, error) {
+	entries, err := Entries(acc, cfg)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].MediaID == mediaID {
+			e := entries[i]
+			return &e, nil
+		}
+	}
+	return nil, nil
+}
+
+// getJSON faz um GET e decodifica o corpo. 404 vira ErrUserNotFound: nos dois sites e o unico
+// recurso pedido por nome.
+func getJSON(url string, header map[string]string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpDo(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrUserNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s answered %d", req.URL.Host, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
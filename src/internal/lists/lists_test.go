@@ -0,0 +1,203 @@
+package lists
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+	"AutoAnimeDownloader/src/internal/files"
+)
+
+func jsonResponse(status int, body string) (*http.Response, error) {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+// mockAniListMapping answers idMal_in with malToMedia and id_in with a bare media per id.
+func mockAniListMapping(t *testing.T, malToMedia map[int]int) func() {
+	t.Helper()
+	return anilist.MockAniListDo(func(req *http.Request) (*http.Response, error) {
+		var payload struct {
+			Query     string `json:"query"`
+			Variables struct {
+				IDs []int `json:"ids"`
+			} `json:"variables"`
+		}
+		body, _ := io.ReadAll(req.Body)
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("unexpected body: %s", body)
+		}
+		type media struct {
+			ID    int `json:"id"`
+			IDMal int `json:"idMal,omitempty"`
+		}
+		var out []media
+		for _, id := range payload.Variables.IDs {
+			if strings.Contains(payload.Query, "idMal_in") {
+				if mediaID, ok := malToMedia[id]; ok {
+					out = append(out, media{ID: mediaID, IDMal: id})
+				}
+				continue
+			}
+			out = append(out, media{ID: id})
+		}
+		data, _ := json.Marshal(map[string]any{"data": map[string]any{"Page": map[string]any{"media": out}}})
+		return jsonResponse(http.StatusOK, string(data))
+	})
+}
+
+func TestMALPublicListMappedToAniList(t *testing.T) {
+	restoreAL := mockAniListMapping(t, map[int]int{52991: 154587, 1: 1})
+	defer restoreAL()
+	var requested []string
+	restore := MockListsDo(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.String())
+		if req.URL.Path != "/animelist/someone/load.json" {
+			return jsonResponse(http.StatusNotFound, `{}`)
+		}
+		return jsonResponse(http.StatusOK, `[
+			{"anime_id":52991,"status":1,"num_watched_episodes":5,"is_rewatching":0},
+			{"anime_id":1,"status":2,"num_watched_episodes":26,"is_rewatching":1},
+			{"anime_id":999999,"status":6,"num_watched_episodes":0,"is_rewatching":false}
+		]`)
+	})
+	defer restore()
+
+	acc := Account{Provider: ProviderMyAnimeList, Username: "someone"}
+	entries, err := Entries(acc, &files.Config{})
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	want := []Entry{
+		{MediaID: 154587, MalID: 52991, Status: anilist.MediaListStatusCurrent, Progress: 5},
+		{MediaID: 1, MalID: 1, Status: anilist.MediaListStatusRepeating, Progress: 26},
+	}
+	if !slices.Equal(entries, want) {
+		t.Fatalf("entries = %+v, want %+v (unknown MAL id must be left out)", entries, want)
+	}
+	if len(requested) != 1 || !strings.Contains(requested[0], "offset=0") {
+		t.Fatalf("requests = %v, want a single page", requested)
+	}
+
+	// Cached: a second read does not hit MAL again.
+	if _, err := Entries(acc, &files.Config{}); err != nil || len(requested) != 1 {
+		t.Fatalf("second read requested %v (err %v), want the cached list", requested, err)
+	}
+}
+
+func TestMALAPIFollowsPagingWithClientID(t *testing.T) {
+	restoreAL := mockAniListMapping(t, map[int]int{10: 100, 20: 200})
+	defer restoreAL()
+	restore := MockListsDo(func(req *http.Request) (*http.Response, error) {
+		if got := req.Header.Get("X-MAL-CLIENT-ID"); got != "cid" {
+			t.Errorf("X-MAL-CLIENT-ID = %q", got)
+		}
+		if req.URL.Query().Get("offset") == "" {
+			return jsonResponse(http.StatusOK, `{"data":[{"node":{"id":10},"list_status":{"status":"on_hold","num_episodes_watched":3}}],
+				"paging":{"next":"`+malAPIBase+`/users/someone/animelist?offset=1"}}`)
+		}
+		return jsonResponse(http.StatusOK, `{"data":[{"node":{"id":20},"list_status":{"status":"watching","num_episodes_watched":1,"is_rewatching":true}}],"paging":{}}`)
+	})
+	defer restore()
+
+	entries, err := Entries(Account{Provider: ProviderMyAnimeList, Username: "someone"}, &files.Config{MALClientID: "cid"})
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	want := []Entry{
+		{MediaID: 100, MalID: 10, Status: anilist.MediaListStatusPaused, Progress: 3},
+		{MediaID: 200, MalID: 20, Status: anilist.MediaListStatusRepeating, Progress: 1},
+	}
+	if !slices.Equal(entries, want) {
+		t.Fatalf("entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestKitsuListUsesMappings(t *testing.T) {
+	restoreAL := mockAniListMapping(t, map[int]int{30: 300})
+	defer restoreAL()
+	restore := MockListsDo(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		switch {
+		case req.URL.Path == "/api/edge/users" && q.Get("filter[slug]") != "":
+			return jsonResponse(http.StatusOK, `{"data":[]}`)
+		case req.URL.Path == "/api/edge/users" && q.Get("filter[name]") == "Some One":
+			return jsonResponse(http.StatusOK, `{"data":[{"id":"42","type":"users"}]}`)
+		case req.URL.Path == "/api/edge/library-entries" && q.Get("filter[userId]") == "42":
+			return jsonResponse(http.StatusOK, `{
+				"data":[
+					{"attributes":{"status":"current","progress":4},"relationships":{"anime":{"data":{"id":"1","type":"anime"}}}},
+					{"attributes":{"status":"completed","progress":12,"reconsuming":true},"relationships":{"anime":{"data":{"id":"2","type":"anime"}}}},
+					{"attributes":{"status":"planned","progress":0},"relationships":{"anime":{"data":{"id":"3","type":"anime"}}}}
+				],
+				"included":[
+					{"id":"1","type":"anime","relationships":{"mappings":{"data":[{"id":"m1","type":"mappings"}]}}},
+					{"id":"2","type":"anime","relationships":{"mappings":{"data":[{"id":"m2","type":"mappings"}]}}},
+					{"id":"3","type":"anime","relationships":{"mappings":{"data":[]}}},
+					{"id":"m1","type":"mappings","attributes":{"externalSite":"anilist/anime","externalId":"154587"}},
+					{"id":"m2","type":"mappings","attributes":{"externalSite":"myanimelist/anime","externalId":"30"}}
+				],
+				"links":{}
+			}`)
+		}
+		return jsonResponse(http.StatusNotFound, `{}`)
+	})
+	defer restore()
+
+	entries, err := Entries(Account{Provider: ProviderKitsu, Username: "Some One"}, &files.Config{})
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	want := []Entry{
+		{MediaID: 154587, Status: anilist.MediaListStatusCurrent, Progress: 4},
+		{MediaID: 300, MalID: 30, Status: anilist.MediaListStatusRepeating, Progress: 12},
+	}
+	if !slices.Equal(entries, want) {
+		t.Fatalf("entries = %+v, want %+v (anime without mappings must be left out)", entries, want)
+	}
+}
+
+func TestMediaListsFiltersStatusesAndKeepsProgress(t *testing.T) {
+	restoreAL := mockAniListMapping(t, map[int]int{10: 100, 20: 200})
+	defer restoreAL()
+	restore := MockListsDo(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusOK, `[
+			{"anime_id":10,"status":1,"num_watched_episodes":7},
+			{"anime_id":20,"status":4,"num_watched_episodes":2}
+		]`)
+	})
+	defer restore()
+
+	acc := Account{Provider: ProviderMyAnimeList, Username: "someone"}
+	got, err := MediaLists(acc, &files.Config{}, []string{"CURRENT", "REPEATING"})
+	if err != nil {
+		t.Fatalf("MediaLists: %v", err)
+	}
+	if len(got) != 1 || got[0].Media.Id != 100 || got[0].Progress != 7 || got[0].Status != anilist.MediaListStatusCurrent {
+		t.Fatalf("MediaLists = %+v, want only media 100 at progress 7", got)
+	}
+
+	status, tracked, err := Status(acc, &files.Config{}, 200)
+	if err != nil || !tracked || status != anilist.MediaListStatusDropped {
+		t.Fatalf("Status(200) = %q, %v, %v", status, tracked, err)
+	}
+	if _, tracked, _ := Status(acc, &files.Config{}, 300); tracked {
+		t.Fatal("media not on the list reported as tracked")
+	}
+}
+
+func TestUnknownUserIsReported(t *testing.T) {
+	restore := MockListsDo(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusNotFound, `{}`)
+	})
+	defer restore()
+
+	_, err := Entries(Account{Provider: ProviderMyAnimeList, Username: "ghost"}, &files.Config{})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("err = %v, want ErrUserNotFound", err)
+	}
+}
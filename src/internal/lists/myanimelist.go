@@ -0,0 +1,127 @@
+package lists
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+
+	"AutoAnimeDownloader/src/internal/anilist"
+)
+
+// Enderecos do MAL; variaveis para os testes apontarem para um httptest.
+var (
+	malAPIBase    = "https://api.myanimelist.net/v2"
+	malPublicBase = "https://myanimelist.net"
+)
+
+// malPublicPageSize e quantos itens o load.json devolve por pagina.
+const malPublicPageSize = 300
+
+// malProvider le a lista pela API v2 quando ha client id (mal_client_id) e, sem ele, pelo
+// load.json publico que a propria pagina da lista usa. Os dois so enxergam listas publicas.
+type malProvider struct {
+	clientID string
+}
+
+func (p *malProvider) Entries(username string) ([]Entry, error) {
+	if p.clientID != "" {
+		return p.apiEntries(username)
+	}
+	return p.publicEntries(username)
+}
+
+// malAPIStatuses traduz o list_status.status da API v2.
+var malAPIStatuses = map[string]anilist.MediaListStatus{
+	"watching":      anilist.MediaListStatusCurrent,
+	"completed":     anilist.MediaListStatusCompleted,
+	"on_hold":       anilist.MediaListStatusPaused,
+	"dropped":       anilist.MediaListStatusDropped,
+	"plan_to_watch": anilist.MediaListStatusPlanning,
+}
+
+func (p *malProvider) apiEntries(username string) ([]Entry, error) {
+	type page struct {
+		Data []struct {
+			Node struct {
+				ID int `json:"id"`
+			} `json:"node"`
+			ListStatus struct {
+				Status             string `json:"status"`
+				NumEpisodesWatched int    `json:"num_episodes_watched"`
+				IsRewatching       bool   `json:"is_rewatching"`
+			} `json:"list_status"`
+		} `json:"data"`
+		Paging struct {
+			Next string `json:"next"`
+		} `json:"paging"`
+	}
+
+	var out []Entry
+	next := fmt.Sprintf("%s/users/%s/animelist?fields=list_status&limit=1000&nsfw=true", malAPIBase, url.PathEscape(username))
+	for next != "" {
+		var pg page
+		if err := getJSON(next, map[string]string{"X-MAL-CLIENT-ID": p.clientID}, &pg); err != nil {
+			return nil, err
+		}
+		for _, d := range pg.Data {
+			status, ok := malAPIStatuses[d.ListStatus.Status]
+			if !ok {
+				continue
+			}
+			if d.ListStatus.IsRewatching {
+				status = anilist.MediaListStatusRepeating
+			}
+			out = append(out, Entry{MalID: d.Node.ID, Status: status, Progress: d.ListStatus.NumEpisodesWatched})
+		}
+		next = pg.Paging.Next
+	}
+	return out, nil
+}
+
+// malPublicStatuses traduz o status numerico do load.json (5 nao existe).
+var malPublicStatuses = map[int]anilist.MediaListStatus{
+	1: anilist.MediaListStatusCurrent,
+	2: anilist.MediaListStatusCompleted,
+	3: anilist.MediaListStatusPaused,
+	4: anilist.MediaListStatusDropped,
+	6: anilist.MediaListStatusPlanning,
+}
+
+// flexBool aceita o is_rewatching do load.json, que chega como 0/1 ou como bool conforme a conta.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	*b = flexBool(bytes.Equal(data, []byte("true")) || bytes.Equal(data, []byte("1")))
+	return nil
+}
+
+func (p *malProvider) publicEntries(username string) ([]Entry, error) {
+	type item struct {
+		AnimeID            int      `json:"anime_id"`
+		Status             int      `json:"status"`
+		NumWatchedEpisodes int      `json:"num_watched_episodes"`
+		IsRewatching       flexBool `json:"is_rewatching"`
+	}
+
+	var out []Entry
+	for offset := 0; ; offset += malPublicPageSize {
+		var items []item
+		u := fmt.Sprintf("%s/animelist/%s/load.json?status=7&offset=%d", malPublicBase, url.PathEscape(username), offset)
+		if err := getJSON(u, nil, &items); err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			status, ok := malPublicStatuses[it.Status]
+			if !ok {
+				continue
+			}
+			if it.IsRewatching {
+				status = anilist.MediaListStatusRepeating
+			}
+			out = append(out, Entry{MalID: it.AnimeID, Status: status, Progress: it.NumWatchedEpisodes})
+		}
+		if len(items) < malPublicPageSize {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package mediaserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// embyClient speaks the API Jellyfin inherited from Emby: the same endpoints for both, only the
+// authentication header differs.
+type embyClient struct {
+	base     string
+	token    string
+	jellyfin bool
+	http     *http.Client
+}
+
+// embyMediaUpdate is one entry of /Library/Media/Updated. "Modified" on a folder makes the
+// server rescan it; a folder that no longer exists is resolved to the nearest parent it knows,
+// which is what a deletion needs.
+type embyMediaUpdate struct {
+	Path       string `json:"Path"`
+	UpdateType string `json:"UpdateType"`
+}
+
+func (c *embyClient) name() string {
+	if c.jellyfin {
+		return "Jellyfin"
+	}
+	return "Emby"
+}
+
+func (c *embyClient) do(method, path string, body any) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, c.base+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// O Jellyfin 10.11 deixou de aceitar o header do Emby por padrao; o Emby nunca aceitou o
+	// do Jellyfin.
+	if c.jellyfin {
+		req.Header.Set("Authorization", fmt.Sprintf("MediaBrowser Token=%q", c.token))
+	} else {
+		req.Header.Set("X-Emby-Token", c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s answered %s: %s", c.name(), path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+func (c *embyClient) info() (Info, error) {
+	data, err := c.do(http.MethodGet, "/System/Info", nil)
+	if err != nil {
+		return Info{}, err
+	}
+	var out struct {
+		ServerName string `json:"ServerName"`
+		Version    string `json:"Version"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Info{}, fmt.Errorf("%s /System/Info: %w", c.name(), err)
+	}
+	return Info{Name: out.ServerName, Version: out.Version}, nil
+}
+
+func (c *embyClient) refreshPaths(paths []string) error {
+	updates := make([]embyMediaUpdate, 0, len(paths))
+	for _, p := range paths {
+		updates = append(updates, embyMediaUpdate{Path: p, UpdateType: "Modified"})
+	}
+	_, err := c.do(http.MethodPost, "/Library/Media/Updated", map[string]any{"Updates": updates})
+	return err
+}
+
+func (c *embyClient) itemPath(id string) (string, error) {
+	data, err := c.do(http.MethodGet, "/Items?Ids="+url.QueryEscape(id)+"&Fields=Path", nil)
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		Items []struct {
+			Path string `json:"Path"`
+		} `json:"Items"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("%s /Items: %w", c.name(), err)
+	}
+	if len(out.Items) == 0 || out.Items[0].Path == "" {
+		return "", fmt.Errorf("%s has no file for item %s", c.name(), id)
+	}
+	return out.Items[0].Path, nil
+}
+
+func (c *embyClient) refreshAll() error {
+	_, err := c.do(http.MethodPost, "/Library/Refresh", nil)
+	return err
+}
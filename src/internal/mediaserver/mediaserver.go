@@ -0,0 +1,166 @@
+// Package mediaserver asks Jellyfin, Emby and Plex to rescan the library after the daemon
+// changes it. The daemon never waits on the server's own filesystem watcher: on network shares
+// and in containers it often sees nothing, and a full scheduled scan can be hours away.
+package mediaserver
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"AutoAnimeDownloader/src/internal/logger"
+
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Media servers the daemon can notify (files.MediaServer.Type).
+const (
+	TypeJellyfin = "jellyfin"
+	TypeEmby     = "emby"
+	TypePlex     = "plex"
+)
+
+// httpTimeout bounds every call to a media server. A refresh only queues the scan on the
+// server, so anything slower than this is a server that is not answering.
+const httpTimeout = 15 * time.Second
+
+// errNoSection is a targeted refresh of a path no library of the server contains: the path
+// mapping is wrong, or the library was never added there. Refresh falls back to a full scan.
+var errNoSection = errors.New("no library of the server contains the path")
+
+// Info is what a successful connection test reports about the server.
+type Info struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// connector is the API of one media server, reduced to what the daemon needs.
+type connector interface {
+	// info checks the URL and the token.
+	info() (Info, error)
+	// refreshPaths rescans the given folders, in the server's view of the filesystem.
+	refreshPaths(paths []string) error
+	// refreshAll rescans every library of the server.
+	refreshAll() error
+	// itemPath is the file of one item, in the server's view of the filesystem.
+	itemPath(id string) (string, error)
+}
+
+// IsType reports whether s is one of the supported media servers.
+func IsType(s string) bool {
+	switch s {
+	case TypeJellyfin, TypeEmby, TypePlex:
+		return true
+	}
+	return false
+}
+
+// Validate checks the media servers of a config update: a unique name, a known type, an
+// http(s) URL and a token each.
+func Validate(servers []files.MediaServer) error {
+	seen := make(map[string]bool, len(servers))
+	for _, s := range servers {
+		if strings.TrimSpace(s.Name) == "" {
+			return errors.New("media server name is required")
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("duplicate media server name %q", s.Name)
+		}
+		seen[s.Name] = true
+		if !IsType(s.Type) {
+			return fmt.Errorf("media server %q: type must be one of jellyfin, emby, plex", s.Name)
+		}
+		if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("media server %q: URL must be an http(s) URL", s.Name)
+		}
+		if s.Token == "" {
+			return fmt.Errorf("media server %q: token is required", s.Name)
+		}
+	}
+	return nil
+}
+
+func newConnector(s files.MediaServer) (connector, error) {
+	base := strings.TrimRight(s.URL, "/")
+	client := &http.Client{Timeout: httpTimeout}
+	switch s.Type {
+	case TypeJellyfin, TypeEmby:
+		return &embyClient{base: base, token: s.Token, jellyfin: s.Type == TypeJellyfin, http: client}, nil
+	case TypePlex:
+		return &plexClient{base: base, token: s.Token, http: client}, nil
+	}
+	return nil, fmt.Errorf("unknown media server type %q", s.Type)
+}
+
+// Test checks that the server answers with the configured token.
+func Test(s files.MediaServer) (Info, error) {
+	c, err := newConnector(s)
+	if err != nil {
+		return Info{}, err
+	}
+	return c.info()
+}
+
+// Refresh asks one server to rescan dirs, library folders in the daemon's view. Empty dirs
+// rescans the whole library, and so does a targeted refresh the server refuses: a full scan is
+// slower, but it never misses the change.
+func Refresh(s files.MediaServer, cfg *files.Config, dirs []string) error {
+	c, err := newConnector(s)
+	if err != nil {
+		return err
+	}
+	if len(dirs) > 0 {
+		paths := make([]string, 0, len(dirs))
+		for _, d := range dirs {
+			paths = append(paths, serverPath(s, cfg, d))
+		}
+		err := c.refreshPaths(paths)
+		if err == nil {
+			return nil
+		}
+		logger.Logger.Warn().Err(err).Str("media_server", s.Name).Strs("paths", paths).Msg("Targeted media server refresh failed, refreshing the whole library")
+	}
+	if err := c.refreshAll(); err != nil {
+		return fmt.Errorf("media server %q: %w", s.Name, err)
+	}
+	return nil
+}
+
+// serverPath rewrites a library folder into the server's view: the part under
+// LibraryMoviesPath moves under MoviesPath, the part under CompletedAnimePath under
+// LibraryPath. The movies folder is tried first because it may live inside the library. A
+// path outside both, or with no mapping configured, is sent as is.
+func serverPath(s files.MediaServer, cfg *files.Config, p string) string {
+	roots := []struct{ local, remote string }{
+		{cfg.LibraryMoviesPath, s.MoviesPath},
+		{cfg.CompletedAnimePath, s.LibraryPath},
+	}
+	for _, r := range roots {
+		if r.local == "" || r.remote == "" {
+			continue
+		}
+		rel, err := filepath.Rel(r.local, p)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return joinRemote(r.remote, rel)
+	}
+	return p
+}
+
+// joinRemote junta rel a uma raiz no formato do servidor: um Jellyfin no Windows recebe a
+// barra invertida mesmo com a daemon no Linux.
+func joinRemote(root, rel string) string {
+	sep := "/"
+	if strings.Contains(root, `\`) && !strings.Contains(root, "/") {
+		sep = `\`
+	}
+	root = strings.TrimRight(root, `/\`)
+	if rel == "." {
+		return root
+	}
+	return root + sep + strings.ReplaceAll(filepath.ToSlash(rel), "/", sep)
+}
@@ -0,0 +1,227 @@
+package mediaserver
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeServer records the calls a connector makes and answers them from handlers keyed by
+// "METHOD /path".
+type fakeServer struct {
+	srv *httptest.Server
+
+	mu    sync.Mutex
+	calls []fakeCall
+	// fail answers 500 to these "METHOD /path" keys.
+	fail map[string]bool
+	// sections is what the Plex /library/sections lists.
+	sections []plexSection
+}
+
+type fakeCall struct {
+	key    string
+	query  string
+	header http.Header
+	body   string
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	f := &fakeServer{fail: map[string]bool{}}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func (f *fakeServer) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	key := r.Method + " " + r.URL.Path
+	f.mu.Lock()
+	f.calls = append(f.calls, fakeCall{key: key, query: r.URL.Query().Get("path"), header: r.Header.Clone(), body: string(body)})
+	fail := f.fail[key]
+	sections := f.sections
+	f.mu.Unlock()
+
+	if fail {
+		http.Error(w, "boom", http.StatusInternalServerError)
+		return
+	}
+	switch key {
+	case "GET /System/Info":
+		_ = json.NewEncoder(w).Encode(map[string]string{"ServerName": "jelly", "Version": "10.10.3"})
+	case "GET /":
+		_ = json.NewEncoder(w).Encode(map[string]any{"MediaContainer": map[string]string{"friendlyName": "plexy", "version": "1.41.0"}})
+	case "GET /library/sections":
+		_ = json.NewEncoder(w).Encode(map[string]any{"MediaContainer": map[string]any{"Directory": sections}})
+	case "GET /Items":
+		_, _ = w.Write([]byte(`{"Items":[{"Id":"abc","Path":"/media/anime/Frieren/Frieren - S01E05.mkv"}]}`))
+	case "GET /library/metadata/42":
+		_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":[{"Media":[{"Part":[{"file":"/data/anime/Frieren/Frieren - S01E05.mkv"}]}]}]}}`))
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeServer) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]string, 0, len(f.calls))
+	for _, c := range f.calls {
+		out = append(out, c.key)
+	}
+	return out
+}
+
+func plexSectionAt(key, path string) plexSection {
+	s := plexSection{Key: key, Title: "Anime"}
+	s.Location = append(s.Location, struct {
+		Path string `json:"path"`
+	}{Path: path})
+	return s
+}
+
+func TestValidate(t *testing.T) {
+	ok := files.MediaServer{Name: "home", Type: TypeJellyfin, URL: "http://jellyfin:8096", Token: "k"}
+	if err := Validate([]files.MediaServer{ok}); err != nil {
+		t.Fatalf("valid server rejected: %v", err)
+	}
+	bad := map[string]files.MediaServer{
+		"no name":   {Type: TypePlex, URL: "http://plex:32400", Token: "k"},
+		"bad type":  {Name: "x", Type: "kodi", URL: "http://kodi", Token: "k"},
+		"bad url":   {Name: "x", Type: TypeEmby, URL: "emby:8096", Token: "k"},
+		"no token":  {Name: "x", Type: TypeEmby, URL: "http://emby:8096"},
+		"duplicate": ok,
+	}
+	for name, s := range bad {
+		servers := []files.MediaServer{s}
+		if name == "duplicate" {
+			servers = append(servers, ok)
+		}
+		if err := Validate(servers); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+// Jellyfin recebe o token no Authorization e o scan das pastas em /Library/Media/Updated, ja no
+// caminho do servidor.
+func TestRefreshJellyfinTargeted(t *testing.T) {
+	f := newFakeServer(t)
+	cfg := &files.Config{CompletedAnimePath: "/srv/anime"}
+	s := files.MediaServer{Name: "home", Type: TypeJellyfin, URL: f.srv.URL + "/", Token: "secret", LibraryPath: "/media/anime"}
+
+	if err := Refresh(s, cfg, []string{filepath.Join("/srv/anime", "Frieren")}); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := f.keys(); !slices.Equal(got, []string{"POST /Library/Media/Updated"}) {
+		t.Fatalf("calls = %v", got)
+	}
+	call := f.calls[0]
+	if auth := call.header.Get("Authorization"); auth != `MediaBrowser Token="secret"` {
+		t.Errorf("Authorization = %q", auth)
+	}
+	var body struct {
+		Updates []embyMediaUpdate
+	}
+	if err := json.Unmarshal([]byte(call.body), &body); err != nil {
+		t.Fatalf("body %q: %v", call.body, err)
+	}
+	if len(body.Updates) != 1 || body.Updates[0].Path != "/media/anime/Frieren" {
+		t.Errorf("updates = %+v, want the mapped show folder", body.Updates)
+	}
+}
+
+// Um servidor que recusa o scan da pasta recebe o da biblioteca inteira.
+func TestRefreshEmbyFallsBackToFullScan(t *testing.T) {
+	f := newFakeServer(t)
+	f.fail["POST /Library/Media/Updated"] = true
+	s := files.MediaServer{Name: "emby", Type: TypeEmby, URL: f.srv.URL, Token: "secret"}
+
+	if err := Refresh(s, &files.Config{}, []string{"/srv/anime/Show"}); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := f.keys(); !slices.Equal(got, []string{"POST /Library/Media/Updated", "POST /Library/Refresh"}) {
+		t.Fatalf("calls = %v", got)
+	}
+	if tok := f.calls[1].header.Get("X-Emby-Token"); tok != "secret" {
+		t.Errorf("X-Emby-Token = %q", tok)
+	}
+
+	f.fail["POST /Library/Refresh"] = true
+	if err := Refresh(s, &files.Config{}, nil); err == nil {
+		t.Error("a failed full scan must be an error, so the job retries")
+	}
+}
+
+// O Plex atualiza a secao que contem a pasta; uma pasta fora de toda secao vira o scan de todas.
+func TestRefreshPlex(t *testing.T) {
+	f := newFakeServer(t)
+	f.sections = []plexSection{plexSectionAt("1", "/data/anime"), plexSectionAt("2", "/data/movies")}
+	s := files.MediaServer{Name: "plex", Type: TypePlex, URL: f.srv.URL, Token: "secret"}
+
+	if err := Refresh(s, &files.Config{}, []string{"/data/anime/Frieren"}); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := f.keys(); !slices.Equal(got, []string{"GET /library/sections", "GET /library/sections/1/refresh"}) {
+		t.Fatalf("calls = %v", got)
+	}
+	if f.calls[1].query != "/data/anime/Frieren" {
+		t.Errorf("refresh path = %q", f.calls[1].query)
+	}
+	if tok := f.calls[1].header.Get("X-Plex-Token"); tok != "secret" {
+		t.Errorf("X-Plex-Token = %q", tok)
+	}
+
+	f.calls = nil
+	if err := Refresh(s, &files.Config{}, []string{"/elsewhere/Show"}); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	want := []string{"GET /library/sections", "GET /library/sections", "GET /library/sections/1/refresh", "GET /library/sections/2/refresh"}
+	if got := f.keys(); !slices.Equal(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+}
+
+func TestTest(t *testing.T) {
+	f := newFakeServer(t)
+	info, err := Test(files.MediaServer{Name: "home", Type: TypeJellyfin, URL: f.srv.URL, Token: "k"})
+	if err != nil || info.Name != "jelly" || info.Version != "10.10.3" {
+		t.Errorf("jellyfin info = %+v, %v", info, err)
+	}
+	info, err = Test(files.MediaServer{Name: "plex", Type: TypePlex, URL: f.srv.URL, Token: "k"})
+	if err != nil || info.Name != "plexy" || info.Version != "1.41.0" {
+		t.Errorf("plex info = %+v, %v", info, err)
+	}
+
+	f.fail["GET /System/Info"] = true
+	if _, err := Test(files.MediaServer{Name: "home", Type: TypeJellyfin, URL: f.srv.URL, Token: "k"}); err == nil {
+		t.Error("expected an error from a server answering 500")
+	}
+}
+
+func TestServerPath(t *testing.T) {
+	cfg := &files.Config{CompletedAnimePath: "/srv/anime", LibraryMoviesPath: "/srv/anime/Movies"}
+	s := files.MediaServer{LibraryPath: "/media/anime", MoviesPath: `D:\Movies`}
+	cases := map[string]string{
+		"/srv/anime/Frieren":             "/media/anime/Frieren",
+		"/srv/anime":                     "/media/anime",
+		"/srv/anime/Movies/Akira (1988)": `D:\Movies\Akira (1988)`,
+		"/other/Show":                    "/other/Show",
+		"/srv/animeX/Show":               "/srv/animeX/Show",
+	}
+	for in, want := range cases {
+		if got := serverPath(s, cfg, in); got != want {
+			t.Errorf("serverPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := serverPath(files.MediaServer{}, cfg, "/srv/anime/Frieren"); got != "/srv/anime/Frieren" {
+		t.Errorf("without a mapping the path goes as is, got %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package mediaserver
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Playback is a playback webhook reduced to what marks an episode watched.
+type Playback struct {
+	// Watched is true for the events that mean "seen to the end": the Jellyfin Webhook plugin's
+	// PlaybackStop with PlayedToCompletion, or UserDataSaved with Played (the "mark played"
+	// button), and Plex's media.scrobble. Every other event is ignored.
+	Watched bool
+	// ItemID is the server's id of the item (Jellyfin ItemId, Plex ratingKey), resolved to a
+	// file by ItemPath.
+	ItemID string
+	// Path is the file in the server's view, when the payload carries it (a Jellyfin template
+	// with {{Path}}). It saves the ItemPath call.
+	Path string
+	// ItemType is the server's kind of item ("Episode", "Movie", "episode", ...), for the logs.
+	ItemType string
+	// User is who watched, for the logs.
+	User string
+}
+
+// ParseJellyfinPlayback reads a Jellyfin Webhook plugin payload. The plugin's templates are
+// user-editable, so only the fields of the default "Generic" template are required: a payload
+// without NotificationType is rejected, one without ItemId and Path is not an item.
+func ParseJellyfinPlayback(data []byte) (Playback, error) {
+	var p struct {
+		NotificationType   string `json:"NotificationType"`
+		ItemID             string `json:"ItemId"`
+		ItemType           string `json:"ItemType"`
+		Path               string `json:"Path"`
+		NotificationUser   string `json:"NotificationUsername"`
+		PlayedToCompletion bool   `json:"PlayedToCompletion"`
+		Played             bool   `json:"Played"`
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Playback{}, fmt.Errorf("invalid Jellyfin webhook payload: %w", err)
+	}
+	if p.NotificationType == "" {
+		return Playback{}, errors.New("invalid Jellyfin webhook payload: missing NotificationType")
+	}
+	watched := (p.NotificationType == "PlaybackStop" && p.PlayedToCompletion) ||
+		(p.NotificationType == "UserDataSaved" && p.Played)
+	return Playback{
+		Watched:  watched && (p.ItemID != "" || p.Path != ""),
+		ItemID:   p.ItemID,
+		Path:     p.Path,
+		ItemType: p.ItemType,
+		User:     p.NotificationUser,
+	}, nil
+}
+
+// ParsePlexPlayback reads the JSON of a Plex webhook (the "payload" field of its multipart
+// body). Plex sends media.scrobble once playback passes 90%, and never a file path.
+func ParsePlexPlayback(data []byte) (Playback, error) {
+	var p struct {
+		Event   string `json:"event"`
+		Account struct {
+			Title string `json:"title"`
+		} `json:"Account"`
+		Metadata struct {
+			RatingKey string `json:"ratingKey"`
+			Type      string `json:"type"`
+		} `json:"Metadata"`
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Playback{}, fmt.Errorf("invalid Plex webhook payload: %w", err)
+	}
+	if p.Event == "" {
+		return Playback{}, errors.New("invalid Plex webhook payload: missing event")
+	}
+	return Playback{
+		Watched:  p.Event == "media.scrobble" && p.Metadata.RatingKey != "",
+		ItemID:   p.Metadata.RatingKey,
+		ItemType: p.Metadata.Type,
+		User:     p.Account.Title,
+	}, nil
+}
+
+// ItemPath asks the server for the file of an item, in the server's view of the filesystem.
+func ItemPath(s files.MediaServer, itemID string) (string, error) {
+	c, err := newConnector(s)
+	if err != nil {
+		return "", err
+	}
+	p, err := c.itemPath(itemID)
+	if err != nil {
+		return "", fmt.Errorf("media server %q: %w", s.Name, err)
+	}
+	return p, nil
+}
+
+// LocalPath is the reverse of serverPath: a file in the server's view back into the daemon's,
+// to be looked up in EpisodeStruct.LibraryPaths. A path outside both mapped roots is returned
+// as is, which is right when no mapping is configured.
+func LocalPath(s files.MediaServer, cfg *files.Config, p string) string {
+	roots := []struct{ remote, local string }{
+		{s.MoviesPath, cfg.LibraryMoviesPath},
+		{s.LibraryPath, cfg.CompletedAnimePath},
+	}
+	for _, r := range roots {
+		if r.local == "" || r.remote == "" || !underPath(p, r.remote) {
+			continue
+		}
+		rel := strings.TrimLeft(p[len(strings.TrimRight(r.remote, `/\`)):], `/\`)
+		if rel == "" {
+			return filepath.Clean(r.local)
+		}
+		// O caminho do servidor pode ser Windows; as duas barras viram o separador local.
+		parts := strings.FieldsFunc(rel, func(c rune) bool { return c == '/' || c == '\\' })
+		return filepath.Join(append([]string{r.local}, parts...)...)
+	}
+	return p
+}
@@ -0,0 +1,78 @@
+package mediaserver
+
+import (
+	"AutoAnimeDownloader/src/internal/files"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseJellyfinPlayback(t *testing.T) {
+	cases := map[string]bool{
+		`{"NotificationType":"PlaybackStop","ItemId":"abc","PlayedToCompletion":true}`:  true,
+		`{"NotificationType":"PlaybackStop","ItemId":"abc","PlayedToCompletion":false}`: false,
+		`{"NotificationType":"UserDataSaved","ItemId":"abc","Played":true}`:             true,
+		`{"NotificationType":"PlaybackStart","ItemId":"abc"}`:                           false,
+		`{"NotificationType":"PlaybackStop","PlayedToCompletion":true}`:                 false,
+	}
+	for body, want := range cases {
+		p, err := ParseJellyfinPlayback([]byte(body))
+		if err != nil {
+			t.Fatalf("%s: %v", body, err)
+		}
+		if p.Watched != want {
+			t.Errorf("%s: Watched = %v, want %v", body, p.Watched, want)
+		}
+	}
+	if _, err := ParseJellyfinPlayback([]byte(`{"ItemId":"abc"}`)); err == nil {
+		t.Error("a payload without NotificationType must be rejected")
+	}
+}
+
+func TestParsePlexPlayback(t *testing.T) {
+	p, err := ParsePlexPlayback([]byte(`{"event":"media.scrobble","Account":{"title":"ana"},"Metadata":{"ratingKey":"42","type":"episode"}}`))
+	if err != nil || !p.Watched || p.ItemID != "42" || p.User != "ana" {
+		t.Errorf("scrobble = %+v, %v", p, err)
+	}
+	if p, _ := ParsePlexPlayback([]byte(`{"event":"media.pause","Metadata":{"ratingKey":"42"}}`)); p.Watched {
+		t.Error("media.pause is not a watched event")
+	}
+	if _, err := ParsePlexPlayback([]byte(`not json`)); err == nil {
+		t.Error("expected an error for a malformed payload")
+	}
+}
+
+func TestItemPath(t *testing.T) {
+	f := newFakeServer(t)
+	got, err := ItemPath(files.MediaServer{Name: "home", Type: TypeJellyfin, URL: f.srv.URL, Token: "k"}, "abc")
+	if err != nil || got != "/media/anime/Frieren/Frieren - S01E05.mkv" {
+		t.Errorf("jellyfin item path = %q, %v", got, err)
+	}
+	got, err = ItemPath(files.MediaServer{Name: "plex", Type: TypePlex, URL: f.srv.URL, Token: "k"}, "42")
+	if err != nil || got != "/data/anime/Frieren/Frieren - S01E05.mkv" {
+		t.Errorf("plex item path = %q, %v", got, err)
+	}
+	if _, err := ItemPath(files.MediaServer{Name: "plex", Type: TypePlex, URL: f.srv.URL, Token: "k"}, "7"); err == nil {
+		t.Error("expected an error for an item the server does not have")
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	cfg := &files.Config{CompletedAnimePath: "/srv/anime", LibraryMoviesPath: "/srv/movies"}
+	s := files.MediaServer{LibraryPath: "/media/anime/", MoviesPath: `D:\Movies`}
+	cases := map[string]string{
+		"/media/anime/Frieren/Frieren - S01E05.mkv": filepath.Join("/srv/anime", "Frieren", "Frieren - S01E05.mkv"),
+		`D:\Movies\Akira (1988)\Akira (1988).mkv`:   filepath.Join("/srv/movies", "Akira (1988)", "Akira (1988).mkv"),
+		"/media/animeX/Show/ep.mkv":                 "/media/animeX/Show/ep.mkv",
+		"/elsewhere/ep.mkv":                         "/elsewhere/ep.mkv",
+	}
+	for in, want := range cases {
+		if got := LocalPath(s, cfg, in); got != want {
+			t.Errorf("LocalPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+	// O caminho que serverPath manda volta igual.
+	local := filepath.Join("/srv/anime", "Frieren")
+	if got := LocalPath(s, cfg, serverPath(s, cfg, local)); got != local {
+		t.Errorf("round trip = %q, want %q", got, local)
+	}
+}
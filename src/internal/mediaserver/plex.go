@@ -0,0 +1,170 @@
+package mediaserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// plexClient speaks the Plex Media Server API. Plex has no "this path changed" call: a partial
+// scan is the refresh of the library section that holds the folder, with the folder as path.
+type plexClient struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+// plexSection is one library of the server and the folders it scans.
+type plexSection struct {
+	Key      string `json:"key"`
+	Title    string `json:"title"`
+	Location []struct {
+		Path string `json:"path"`
+	} `json:"Location"`
+}
+
+func (c *plexClient) do(path string, query url.Values) ([]byte, error) {
+	target := c.base + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Plex-Token", c.token)
+	// Sem o Accept o Plex responde XML.
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("Plex %s answered %s: %s", path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+func (c *plexClient) info() (Info, error) {
+	data, err := c.do("/", nil)
+	if err != nil {
+		return Info{}, err
+	}
+	var out struct {
+		MediaContainer struct {
+			FriendlyName string `json:"friendlyName"`
+			Version      string `json:"version"`
+		} `json:"MediaContainer"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Info{}, fmt.Errorf("Plex /: %w", err)
+	}
+	return Info{Name: out.MediaContainer.FriendlyName, Version: out.MediaContainer.Version}, nil
+}
+
+func (c *plexClient) sections() ([]plexSection, error) {
+	data, err := c.do("/library/sections", nil)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		MediaContainer struct {
+			Directory []plexSection `json:"Directory"`
+		} `json:"MediaContainer"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("Plex /library/sections: %w", err)
+	}
+	return out.MediaContainer.Directory, nil
+}
+
+// refreshPaths refreshes, for each path, every section with a folder that contains it. A path
+// no section contains fails the whole call with errNoSection, so Refresh scans everything.
+func (c *plexClient) refreshPaths(paths []string) error {
+	sections, err := c.sections()
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		found := false
+		for _, s := range sections {
+			for _, loc := range s.Location {
+				if !underPath(p, loc.Path) {
+					continue
+				}
+				found = true
+				if _, err := c.do("/library/sections/"+url.PathEscape(s.Key)+"/refresh", url.Values{"path": {p}}); err != nil {
+					return err
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %s", errNoSection, p)
+		}
+	}
+	return nil
+}
+
+func (c *plexClient) refreshAll() error {
+	sections, err := c.sections()
+	if err != nil {
+		return err
+	}
+	for _, s := range sections {
+		if _, err := c.do("/library/sections/"+url.PathEscape(s.Key)+"/refresh", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemPath le o arquivo da primeira parte da primeira midia: um episodio em varias versoes
+// tem uma Media por versao, e qualquer uma delas e o mesmo episodio.
+func (c *plexClient) itemPath(id string) (string, error) {
+	data, err := c.do("/library/metadata/"+url.PathEscape(id), nil)
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		MediaContainer struct {
+			Metadata []struct {
+				Media []struct {
+					Part []struct {
+						File string `json:"file"`
+					} `json:"Part"`
+				} `json:"Media"`
+			} `json:"Metadata"`
+		} `json:"MediaContainer"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("Plex /library/metadata: %w", err)
+	}
+	for _, m := range out.MediaContainer.Metadata {
+		for _, media := range m.Media {
+			for _, part := range media.Part {
+				if part.File != "" {
+					return part.File, nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("Plex has no file for item %s", id)
+}
+
+// underPath diz se p e root ou esta dentro dela. Compara com as duas barras: o caminho e o do
+// servidor, que pode ser Windows.
+func underPath(p, root string) bool {
+	root = strings.TrimRight(root, `/\`)
+	if root == "" {
+		return false
+	}
+	return p == root || strings.HasPrefix(p, root+"/") || strings.HasPrefix(p, root+`\`)
+}
@@ -19,6 +19,25 @@ const (
 	NewEpisode Event = iota
 	DownloadFailed
 	DownloadCompleted
+	// DataCorrupted e a verificacao de integridade achando pecas que nao batem com o hash. O
+	// episodio ja estava na biblioteca, entao quem recebe precisa saber: o arquivo que o player
+	// abre esta com defeito ate o torrent baixar as pecas de novo.
+	DataCorrupted
+	// SequelFollowed e o passe seguindo sozinho a sequencia de um anime que terminou
+	// (daemon.followSequels). O anime e a sequencia; {{reason}} leva o titulo do anterior.
+	SequelFollowed
+	// TrialAdded e TrialExpired sao as duas pontas de um trial de auto-inscricao
+	// (daemon.autoSubscribe / expireTrials). {{reason}} leva o nome da regra. O aviso de expirar
+	// existe porque o passe apaga os episodios do trial junto.
+	TrialAdded
+	TrialExpired
+	// ListAdded, ListRemoved, ListStatusChanged e ListProgressChanged sao o diff da lista de uma
+	// conta entre dois passes (daemon.diffListSnapshots). {{reason}} leva a conta; na mudanca de
+	// status leva tambem "ANTIGO → NOVO". {{episode}} e o progresso novo.
+	ListAdded
+	ListRemoved
+	ListStatusChanged
+	ListProgressChanged
 )
 
 // Motivos de falha de download, usados como {{reason}} e na mensagem padrão.
@@ -26,6 +45,7 @@ const (
 	ReasonNotFound         = "nenhum torrent encontrado"
 	ReasonDownloadRejected = "torrent rejeitado"
 	ReasonNoDiskSpace      = "espaço em disco insuficiente"
+	ReasonDataCapReached   = "teto mensal de dados atingido"
 )
 
 var reVar = regexp.MustCompile(`\{\{(\w+)\}\}`)
@@ -38,6 +58,22 @@ func eventString(e Event) string {
 		return "download_failed"
 	case DownloadCompleted:
 		return "download_completed"
+	case DataCorrupted:
+		return "data_corrupted"
+	case SequelFollowed:
+		return "sequel_followed"
+	case TrialAdded:
+		return "trial_added"
+	case TrialExpired:
+		return "trial_expired"
+	case ListAdded:
+		return "list_added"
+	case ListRemoved:
+		return "list_removed"
+	case ListStatusChanged:
+		return "list_status_changed"
+	case ListProgressChanged:
+		return "list_progress_changed"
 	}
 	return ""
 }
@@ -100,6 +136,22 @@ func batchTitle(event Event, items []item) string {
 		return fmt.Sprintf("%d erros no download", len(items))
 	case DownloadCompleted:
 		return fmt.Sprintf("%d downloads concluídos", len(items))
+	case DataCorrupted:
+		return fmt.Sprintf("%d torrents com dados corrompidos", len(items))
+	case SequelFollowed:
+		return fmt.Sprintf("%d sequências adicionadas", len(items))
+	case TrialAdded:
+		return fmt.Sprintf("%d animes em teste adicionados", len(items))
+	case TrialExpired:
+		return fmt.Sprintf("%d testes expirados", len(items))
+	case ListAdded:
+		return fmt.Sprintf("%d animes entraram na lista", len(items))
+	case ListRemoved:
+		return fmt.Sprintf("%d animes saíram da lista", len(items))
+	case ListStatusChanged:
+		return fmt.Sprintf("%d mudanças de status na lista", len(items))
+	case ListProgressChanged:
+		return fmt.Sprintf("%d mudanças de progresso na lista", len(items))
 	}
 	return ""
 }
@@ -118,6 +170,35 @@ func eventStrings(animeName string, episode int, event Event, reason string) (ti
 	case DownloadCompleted:
 		return "Download concluído",
 			fmt.Sprintf("%s EP %d foi baixado com sucesso", animeName, episode)
+	case DataCorrupted:
+		// Episodio 0 e o torrent sem episodio: batch inteiro, ou adicionado a mao.
+		if episode == 0 {
+			return "Dados corrompidos",
+				fmt.Sprintf("%s tem dados corrompidos: %s", animeName, reason)
+		}
+		return "Dados corrompidos",
+			fmt.Sprintf("%s EP %d tem dados corrompidos: %s", animeName, episode, reason)
+	case SequelFollowed:
+		return "Sequência adicionada",
+			fmt.Sprintf("%s, sequência de %s, passou a ser acompanhado", animeName, reason)
+	case TrialAdded:
+		return "Anime em teste adicionado",
+			fmt.Sprintf("%s entrou em teste pela regra %s", animeName, reason)
+	case TrialExpired:
+		return "Teste expirado",
+			fmt.Sprintf("%s saiu do teste da regra %s e os episódios foram apagados", animeName, reason)
+	case ListAdded:
+		return "Anime entrou na lista",
+			fmt.Sprintf("%s entrou na lista: %s", animeName, reason)
+	case ListRemoved:
+		return "Anime saiu da lista",
+			fmt.Sprintf("%s saiu da lista: %s", animeName, reason)
+	case ListStatusChanged:
+		return "Status alterado na lista",
+			fmt.Sprintf("%s mudou de status na lista: %s", animeName, reason)
+	case ListProgressChanged:
+		return "Progresso alterado na lista",
+			fmt.Sprintf("%s foi para o EP %d na lista: %s", animeName, episode, reason)
 	}
 	return "", ""
 }
@@ -68,6 +68,54 @@ func TestBuildVarsDownloadFailedReason(t *testing.T) {
 	}
 }
 
+// Um torrent sem episodio (batch, ou adicionado a mao) chega com episodio 0, e a mensagem nao
+// pode dizer "EP 0".
+func TestBuildVarsDataCorruptedWithoutEpisode(t *testing.T) {
+	vars := buildVars("Frieren", 0, DataCorrupted, "2 pecas baixando de novo")
+	if want := "Frieren tem dados corrompidos: 2 pecas baixando de novo"; vars["message"] != want {
+		t.Fatalf("message = %q, want %q", vars["message"], want)
+	}
+	vars = buildVars("Frieren", 5, DataCorrupted, "1 peca baixando de novo")
+	if want := "Frieren EP 5 tem dados corrompidos: 1 peca baixando de novo"; vars["message"] != want {
+		t.Fatalf("message = %q, want %q", vars["message"], want)
+	}
+}
+
+// A sequencia seguida nao tem episodio: o anterior vai no {{reason}}.
+func TestBuildVarsSequelFollowed(t *testing.T) {
+	vars := buildVars("Frieren 2", 0, SequelFollowed, "Frieren")
+	if want := "Frieren 2, sequência de Frieren, passou a ser acompanhado"; vars["message"] != want {
+		t.Fatalf("message = %q, want %q", vars["message"], want)
+	}
+	if vars["reason"] != "Frieren" {
+		t.Fatalf("reason var = %q", vars["reason"])
+	}
+}
+
+func TestBuildVarsTrialEvents(t *testing.T) {
+	if got := buildVars("Dandadan", 0, TrialAdded, "acao")["message"]; got != "Dandadan entrou em teste pela regra acao" {
+		t.Errorf("trial_added message = %q", got)
+	}
+	vars := buildVars("Dandadan", 0, TrialExpired, "acao")
+	if vars["title"] != "Teste expirado" || vars["reason"] != "acao" {
+		t.Errorf("trial_expired vars = %v", vars)
+	}
+}
+
+// O diff da lista leva a conta no {{reason}}; a mudanca de status leva tambem a transicao.
+func TestBuildVarsListEvents(t *testing.T) {
+	vars := buildVars("Dandadan", 4, ListStatusChanged, "user1: CURRENT → DROPPED")
+	if want := "Dandadan mudou de status na lista: user1: CURRENT → DROPPED"; vars["message"] != want {
+		t.Errorf("message = %q, want %q", vars["message"], want)
+	}
+	if got := buildVars("Dandadan", 5, ListProgressChanged, "user1")["message"]; got != "Dandadan foi para o EP 5 na lista: user1" {
+		t.Errorf("list_progress_changed message = %q", got)
+	}
+	if eventString(ListAdded) != "list_added" || eventString(ListRemoved) != "list_removed" {
+		t.Errorf("event strings: %q %q", eventString(ListAdded), eventString(ListRemoved))
+	}
+}
+
 func TestFireTestWebhookNotFound(t *testing.T) {
 	cfg := &files.Config{}
 	err := FireTestWebhook(cfg, "nonexistent")
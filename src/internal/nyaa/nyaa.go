@@ -193,6 +193,29 @@ func ExtractEpisodeNumber(name string) *int {
 	return extractEpisodeNumber(name)
 }
 
+// ExtractGroup devolve o fansub do nome como foi escrito — extractFansub baixa a caixa para
+// comparar com as prioridades, e o {group} dos templates de nome da biblioteca quer "SubsPlease",
+// nao "subsplease".
+func ExtractGroup(name string) string {
+	matches := reFansub.FindStringSubmatch(name)
+	if len(matches) == 0 {
+		return ""
+	}
+	// Um grupo por forma: [Grupo] ou (Grupo).
+	for _, m := range matches[1:] {
+		if g := strings.TrimSpace(m); g != "" {
+			return g
+		}
+	}
+	return ""
+}
+
+// ExtractResolution é a versão exportável de extractResolution, para o {resolution} dos
+// templates de nome da biblioteca.
+func ExtractResolution(name string) string {
+	return extractResolution(name)
+}
+
 // ExtractSeason é uma versão exportável de extractSeason para uso externo ao pacote
 func ExtractSeason(name string) *int {
 	return extractSeason(name)
@@ -28,6 +28,11 @@ type TorrentInfo struct {
 	// BytesTotal is the torrent's total size. It is 0 until the metadata arrives (a magnet
 	// spends real time in downloading_metadata) — guard every division on it.
 	BytesTotal int64
+	// BytesDownloaded is the number of bytes received from the swarm, wasted pieces included —
+	// what the connection actually carried, so it can exceed BytesTotal. Like BytesUploaded,
+	// rain keeps it in the resume data, so both are lifetime counters that survive a restart
+	// (up to the last resume-data flush); the data usage ledger samples their deltas.
+	BytesDownloaded int64
 	// BytesUploaded is the number of bytes uploaded to the swarm.
 	BytesUploaded int64
 	// DownloadSpeed and UploadSpeed are bytes per second, as a 1-minute moving average.
@@ -124,9 +129,52 @@ type TorrentBackend interface {
 	// SetMaxActiveDownloads caps how many incomplete torrents run at the same time; the rest
 	// wait in the queue. 0 (or negative) disables the limit. Seeding is never capped.
 	SetMaxActiveDownloads(n int)
+	// SetQueuePolicy chooses how the torrents waiting in the queue are ordered (QueueFIFO,
+	// QueueSmallestFirst, QueueAiringFirst, QueueFairShare). Prioritized torrents come first
+	// under every policy. An unknown policy behaves as FIFO.
+	SetQueuePolicy(policy QueuePolicy)
+	// SetQueueHints replaces what the queue knows about each torrent beyond rain's stats — its
+	// anime, the anime's weight and whether it is a freshly aired episode. Only airing_first
+	// and fair_share read them; a torrent without a hint is its own anime, weight 1, not airing.
+	SetQueueHints(hints map[string]QueueHint)
+	// PauseAll stops every torrent, downloading and seeding, and keeps them stopped until
+	// ResumeAll — or for d when d > 0, after which it resumes on its own. Torrents the user
+	// had already paused one by one stay paused after ResumeAll. Survives a restart.
+	PauseAll(d time.Duration)
+	// ResumeAll ends a PauseAll: the queue starts downloads again within its limit and the
+	// seeders PauseAll stopped resume. No-op when nothing is paused.
+	ResumeAll()
+	// PausedAll reports whether a PauseAll is in effect and its deadline (zero = until
+	// ResumeAll).
+	PausedAll() (paused bool, until time.Time)
+	// SetDataCapReached holds every torrent the way PauseAll does, on behalf of the monthly
+	// data cap rather than the user: ResumeAll does not lift it, only SetDataCapReached(false)
+	// does. It is not persisted — the daemon recomputes it from the usage ledger and pushes it
+	// at boot, on every sample and on every pass.
+	SetDataCapReached(reached bool)
+	// DataCapReached reports the last SetDataCapReached.
+	DataCapReached() bool
 	// Announce forces a re-announce to all trackers and DHT. It does not override the
 	// trackers' minimum interval, so calling it in a loop achieves nothing.
 	Announce(hash string) error
+	// SetExtraTrackers sets the tracker URLs Add appends to every magnet (see WithTrackers).
+	// Old Nyaa magnets often list only trackers that died years ago, which leaves DHT as the
+	// one way to find peers; the extra trackers give those torrents a second chance.
+	SetExtraTrackers(trackers []string)
+	// AddExtraTrackers adds the trackers set by SetExtraTrackers to a torrent that is already
+	// in the session — the ones added before the list was configured never got them. Trackers
+	// the torrent already has are skipped, so calling it twice is harmless. Returns how many
+	// were actually added, and re-announces when that is more than zero.
+	AddExtraTrackers(hash string) (int, error)
+	// Trackers returns the torrent's trackers with their last announce results.
+	Trackers(hash string) ([]TrackerInfo, error)
+	// Recheck re-verifies every piece on disk against its hash and BLOCKS until that is done —
+	// it reads the whole torrent, so it takes seconds for an episode and minutes for a batch.
+	// Pieces that fail are downloaded again: a torrent that was seeding goes to the FRONT of
+	// the download queue, since it was already in the library. A torrent the user had paused
+	// stays paused (the failed pieces wait for the resume), and one that was still
+	// downloading keeps its place in the queue. Errors when the torrent has no metadata yet.
+	Recheck(hash string) (RecheckResult, error)
 	// SetCallbacks registers handlers invoked when a torrent completes or fails. It also
 	// arms listeners for torrents already present (e.g. loaded from resume data), except
 	// those already completed (handled by startup reconciliation, not events).
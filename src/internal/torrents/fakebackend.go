@@ -2,6 +2,7 @@ package torrents
 
 import (
 	"fmt"
+	"net/url"
 	"sync"
 	"time"
 )
@@ -36,6 +37,27 @@ type FakeBackend struct {
 	// enforce a queue — that logic is unit-tested directly in queue_test.go, and modelling
 	// it here would make every daemon test depend on it.
 	MaxActiveDownloads int
+	// QueuePolicy and QueueHints record the last SetQueuePolicy/SetQueueHints, for the same
+	// reason: the ordering itself is tested in queuepolicy_test.go.
+	QueuePolicy QueuePolicy
+	QueueHints  map[string]QueueHint
+	// AllPaused and AllPausedUntil record PauseAll/ResumeAll; the torrents themselves are not
+	// stopped (that is the queue's job, tested in pauseall_test.go).
+	AllPaused      bool
+	AllPausedUntil time.Time
+	// DataCapped records the last SetDataCapReached, under the same rule.
+	DataCapped bool
+	// ExtraTrackers records the last SetExtraTrackers(trackers). Unlike the queue, the fake
+	// does apply them: Add and AddExtraTrackers merge them into the per-torrent list that
+	// Trackers returns, so the API tests can see the effect end to end.
+	ExtraTrackers []string
+	// trackers holds each torrent's tracker URLs: the magnet's tr= params plus the extras.
+	trackers map[string][]string
+	// CorruptPieces makes the next Recheck of a hash report that many failed pieces and turn
+	// the torrent incomplete ("downloading"), as the real backend does before the repair.
+	CorruptPieces map[string]uint32
+	// recheckCalls records every Recheck(hash) for assertions.
+	recheckCalls []string
 }
 
 var _ TorrentBackend = (*FakeBackend)(nil)
@@ -44,6 +66,8 @@ func NewFakeBackend() *FakeBackend {
 	return &FakeBackend{
 		torrents:        make(map[string]*TorrentInfo),
 		RemovedKeepData: make(map[string]bool),
+		trackers:        make(map[string][]string),
+		CorruptPieces:   make(map[string]uint32),
 	}
 }
 
@@ -96,6 +120,9 @@ func (f *FakeBackend) Add(magnet string) (string, error) {
 			Status:  "downloading",
 			AddedAt: time.Now(),
 		}
+		if u, err := url.Parse(WithTrackers(magnet, f.ExtraTrackers)); err == nil {
+			f.trackers[hash] = u.Query()["tr"]
+		}
 	}
 	return hash, nil
 }
@@ -132,6 +159,7 @@ func (f *FakeBackend) Remove(hash string, keepData bool) error {
 	}
 	f.RemovedKeepData[hash] = keepData
 	delete(f.torrents, hash)
+	delete(f.trackers, hash)
 	return nil
 }
 
@@ -211,6 +239,53 @@ func (f *FakeBackend) SetMaxActiveDownloads(n int) {
 	f.MaxActiveDownloads = n
 }
 
+func (f *FakeBackend) SetQueuePolicy(policy QueuePolicy) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.QueuePolicy = policy
+}
+
+func (f *FakeBackend) SetQueueHints(hints map[string]QueueHint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.QueueHints = hints
+}
+
+func (f *FakeBackend) PauseAll(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.AllPaused = true
+	f.AllPausedUntil = time.Time{}
+	if d > 0 {
+		f.AllPausedUntil = time.Now().Add(d)
+	}
+}
+
+func (f *FakeBackend) ResumeAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.AllPaused = false
+	f.AllPausedUntil = time.Time{}
+}
+
+func (f *FakeBackend) PausedAll() (bool, time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.AllPaused, f.AllPausedUntil
+}
+
+func (f *FakeBackend) SetDataCapReached(reached bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.DataCapped = reached
+}
+
+func (f *FakeBackend) DataCapReached() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.DataCapped
+}
+
 // AnnounceCalls returns the hashes passed to Announce, in order.
 func (f *FakeBackend) AnnounceCalls() []string {
 	f.mu.Lock()
@@ -218,6 +293,48 @@ func (f *FakeBackend) AnnounceCalls() []string {
 	return append([]string(nil), f.announceCalls...)
 }
 
+func (f *FakeBackend) SetExtraTrackers(trackers []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ExtraTrackers = append([]string(nil), trackers...)
+}
+
+func (f *FakeBackend) AddExtraTrackers(hash string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.torrents[hash]; !ok {
+		return 0, fmt.Errorf("fake: torrent %s not found", hash)
+	}
+	present := make(map[string]bool)
+	for _, tr := range f.trackers[hash] {
+		present[tr] = true
+	}
+	added := 0
+	for _, tr := range f.ExtraTrackers {
+		if present[tr] {
+			continue
+		}
+		present[tr] = true
+		f.trackers[hash] = append(f.trackers[hash], tr)
+		added++
+	}
+	return added, nil
+}
+
+// Trackers reports every tracker as not contacted: the fake never announces.
+func (f *FakeBackend) Trackers(hash string) ([]TrackerInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.torrents[hash]; !ok {
+		return nil, fmt.Errorf("fake: torrent %s not found", hash)
+	}
+	out := make([]TrackerInfo, 0, len(f.trackers[hash]))
+	for _, tr := range f.trackers[hash] {
+		out = append(out, TrackerInfo{URL: tr, Status: "not_contacted"})
+	}
+	return out, nil
+}
+
 func (f *FakeBackend) SetCallbacks(onComplete func(hash string), onFailed func(hash string, err error)) {
 	f.mu.Lock()
 	f.onComplete = onComplete
@@ -256,6 +373,25 @@ func (f *FakeBackend) AddPaused(hash, name string, piecesHave, piecesTotal uint3
 	}
 }
 
+// SetTraffic sets a torrent's lifetime byte counters, as rain's Stats() would report them.
+func (f *FakeBackend) SetTraffic(hash string, downloaded, uploaded int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t, ok := f.torrents[hash]; ok {
+		t.BytesDownloaded = downloaded
+		t.BytesUploaded = uploaded
+	}
+}
+
+// SetSize sets a torrent's total size, as it reads once the metadata has arrived.
+func (f *FakeBackend) SetSize(hash string, total int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t, ok := f.torrents[hash]; ok {
+		t.BytesTotal = total
+	}
+}
+
 // CompleteTorrent marks a torrent seeding and fires the onComplete callback.
 func (f *FakeBackend) CompleteTorrent(hash, dataDir string) {
 	f.mu.Lock()
@@ -282,3 +418,41 @@ func (f *FakeBackend) FailTorrent(hash string, err error) {
 		cb(hash, err)
 	}
 }
+
+// Recheck records the call and applies CorruptPieces once: the entry is consumed, so a second
+// recheck finds the torrent clean, like a real one after the repair.
+func (f *FakeBackend) Recheck(hash string) (RecheckResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.torrents[hash]
+	if !ok {
+		return RecheckResult{}, fmt.Errorf("torrent %s not found", hash)
+	}
+	f.recheckCalls = append(f.recheckCalls, hash)
+	failed := f.CorruptPieces[hash]
+	delete(f.CorruptPieces, hash)
+	if failed > 0 {
+		t.Completed = false
+		t.Status = "downloading"
+	}
+	// The fixtures mostly leave the piece counts at 0; the failed count is reported as set
+	// regardless, and PiecesHave only drops as far as 0.
+	have := uint32(0)
+	if t.PiecesHave > failed {
+		have = t.PiecesHave - failed
+	}
+	t.PiecesHave = have
+	return RecheckResult{
+		PiecesTotal:  t.PiecesTotal,
+		PiecesHave:   have,
+		PiecesFailed: failed,
+		Completed:    t.Completed,
+	}, nil
+}
+
+// RecheckCalls returns the hashes passed to Recheck, in order.
+func (f *FakeBackend) RecheckCalls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.recheckCalls...)
+}
@@ -0,0 +1,108 @@
+package torrents
+
+import (
+	"time"
+
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// pauseAll liga a pausa global. until zero = ate o resume-all. Chamar de novo com a pausa ja
+// ligada so troca o prazo. O chamador roda enforce em seguida — e o enforce quem para os
+// torrents e anota os seeders.
+func (q *queue) pauseAll(until time.Time) {
+	q.mu.Lock()
+	q.pausedAll = true
+	q.pausedUntil = until
+	q.mu.Unlock()
+}
+
+// resumeAll desliga a pausa global. Os incompletos voltam pelo passo 3 do enforce seguinte,
+// respeitando limite e paused; os seeders anotados voltam pelo passo 4b.
+func (q *queue) resumeAll() {
+	q.mu.Lock()
+	q.pausedAll = false
+	q.pausedUntil = time.Time{}
+	q.mu.Unlock()
+}
+
+// pauseState reporta a pausa global. Um prazo vencido conta como nao pausado mesmo antes do
+// enforce que o consome.
+func (q *queue) pauseState() (bool, time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.pausedAll || (!q.pausedUntil.IsZero() && !time.Now().Before(q.pausedUntil)) {
+		return false, time.Time{}
+	}
+	return true, q.pausedUntil
+}
+
+// setDataCapped liga ou desliga a retencao do teto de dados. Como pauseAll, o chamador roda
+// enforce em seguida.
+func (q *queue) setDataCapped(capped bool) {
+	q.mu.Lock()
+	q.dataCapped = capped
+	q.mu.Unlock()
+}
+
+// holding reporta se alguma retencao global esta ligada — a do usuario ou a do teto de dados.
+// Os dois seguram a mesma coisa (downloads e seeders), mas cada um so e desligado por quem o
+// ligou. Chamado com q.mu segurado.
+func (q *queue) holding() bool {
+	return q.pausedAll || q.dataCapped
+}
+
+// expirePauseAll desliga a pausa global cujo prazo venceu. Chamado com q.mu segurado, no
+// inicio de todo enforce.
+func (q *queue) expirePauseAll(now time.Time) {
+	if q.pausedAll && !q.pausedUntil.IsZero() && !now.Before(q.pausedUntil) {
+		q.pausedAll = false
+		q.pausedUntil = time.Time{}
+		logger.Logger.Info().Msg("Pause-all timer expired; resuming downloads")
+	}
+}
+
+// applyPauseAll e o passo 4b do enforce, o unico que olha os completos. Com a pausa global
+// (ou o teto de dados) ligada, para todo seeder rodando e o anota em pausedSeeders — inclusive
+// um que o usuario retomou a mao no meio da pausa: a retencao segura tudo ate ser desligada.
+// Com as duas desligadas, retoma os anotados e esquece a lista. Chamado com q.mu segurado.
+//
+// "stopping" fica de fora dos dois lados, pelo mesmo motivo do passo 4: um seeder ainda
+// parando no resume-all continua anotado e volta no enforce seguinte.
+func (q *queue) applyPauseAll(ops queueOps, all []TorrentInfo) {
+	present := make(map[string]TorrentInfo, len(all))
+	for _, t := range all {
+		present[t.Hash] = t
+	}
+	q.pausedSeeders = filter(q.pausedSeeders, func(h string) bool {
+		t, ok := present[h]
+		return ok && t.Completed
+	})
+
+	if q.holding() {
+		for _, t := range all {
+			if !t.Completed || t.Status == StatusStopped || t.Status == StatusStopping {
+				continue
+			}
+			if err := ops.pause(t.Hash); err != nil {
+				logger.Logger.Warn().Err(err).Str("hash", t.Hash).Msg("Pause-all: failed to pause seeding torrent")
+				continue
+			}
+			if !contains(q.pausedSeeders, t.Hash) {
+				q.pausedSeeders = append(q.pausedSeeders, t.Hash)
+			}
+		}
+		return
+	}
+
+	q.pausedSeeders = filter(q.pausedSeeders, func(h string) bool {
+		switch present[h].Status {
+		case StatusStopping:
+			return true
+		case StatusStopped:
+			if err := ops.resume(h); err != nil {
+				logger.Logger.Warn().Err(err).Str("hash", h).Msg("Resume-all: failed to resume seeding torrent")
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,131 @@
+package torrents
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Pause-all stops downloads and seeders alike, and resume-all brings back exactly what it
+// stopped: the torrent and the seeder the user had paused by hand stay paused.
+func TestQueuePauseAllAndResumeAll(t *testing.T) {
+	userSeeder := seeding("seed-user")
+	userSeeder.Status = StatusStopped
+	f := &queueFake{torrents: withAddOrder(
+		downloading("a", 1, 10),
+		stopped("user"),
+		seeding("seed"),
+		userSeeder,
+	)}
+	q := newQueue(2)
+	q.paused = []string{"user"}
+	q.enforce(f)
+
+	q.pauseAll(time.Time{})
+	q.enforce(f)
+	if got := f.hashesWithStatus(StatusStopped); len(got) != 4 {
+		t.Fatalf("expected every torrent stopped, got %v", f.statuses())
+	}
+	if len(q.queued) != 0 {
+		t.Errorf("nothing should read as queued during a pause-all, got %v", q.queued)
+	}
+	assertOrder(t, q.pausedSeeders, "seed")
+
+	q.resumeAll()
+	q.enforce(f)
+	st := f.statuses()
+	if st["a"] == StatusStopped || st["seed"] == StatusStopped {
+		t.Errorf("expected a and seed running again, got %v", st)
+	}
+	if st["user"] != StatusStopped || st["seed-user"] != StatusStopped {
+		t.Errorf("the torrents the user paused must stay paused, got %v", st)
+	}
+	if len(q.pausedSeeders) != 0 {
+		t.Errorf("pausedSeeders = %v, want empty after resume-all", q.pausedSeeders)
+	}
+}
+
+func TestQueuePauseAllExpires(t *testing.T) {
+	f := &queueFake{torrents: withAddOrder(downloading("a", 1, 10), seeding("seed"))}
+	q := newQueue(1)
+	q.pauseAll(time.Now().Add(time.Hour))
+	q.enforce(f)
+	if paused, _ := q.pauseState(); !paused {
+		t.Fatal("expected the pause to be in effect")
+	}
+
+	q.pausedUntil = time.Now().Add(-time.Second)
+	if paused, _ := q.pauseState(); paused {
+		t.Error("an expired pause must not read as paused")
+	}
+	q.enforce(f)
+	if st := f.statuses(); st["a"] == StatusStopped || st["seed"] == StatusStopped {
+		t.Errorf("expected everything back after the deadline, got %v", st)
+	}
+}
+
+func TestQueuePauseAllSurvivesReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	f := &queueFake{torrents: withAddOrder(seeding("seed"))}
+	q := &queue{path: path}
+	until := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	q.pauseAll(until)
+	q.enforce(f)
+
+	var reloaded queue
+	reloaded.load(path)
+	if !reloaded.pausedAll || !reloaded.pausedUntil.Equal(until) {
+		t.Errorf("reloaded pause = %v until %v, want true until %v", reloaded.pausedAll, reloaded.pausedUntil, until)
+	}
+	assertOrder(t, reloaded.pausedSeeders, "seed")
+}
+
+// The timer armed by a timed PauseAll resumes on its own, without anything else calling
+// into the manager.
+func TestSessionManagerPauseAllTimerResumes(t *testing.T) {
+	m, savePath, _ := newTestManager(t)
+	if _, err := m.Ensure(savePath); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+
+	m.PauseAll(50 * time.Millisecond)
+	if paused, until := m.PausedAll(); !paused || until.IsZero() {
+		t.Fatalf("PausedAll() = %v, %v; want paused with a deadline", paused, until)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		m.queue.mu.Lock()
+		done := !m.queue.pausedAll
+		m.queue.mu.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Error("the pause-all timer never resumed")
+}
+
+// The data cap holds like a pause-all but is lifted only by its own switch: a resume-all in the
+// middle leaves everything stopped.
+func TestQueueDataCapHoldsUntilLifted(t *testing.T) {
+	f := &queueFake{torrents: withAddOrder(downloading("a", 1, 10), seeding("seed"))}
+	q := newQueue(1)
+	q.setDataCapped(true)
+	q.enforce(f)
+	if got := f.hashesWithStatus(StatusStopped); len(got) != 2 {
+		t.Fatalf("expected everything stopped under the cap, got %v", f.statuses())
+	}
+
+	q.resumeAll()
+	q.enforce(f)
+	if got := f.hashesWithStatus(StatusStopped); len(got) != 2 {
+		t.Errorf("resume-all must not lift the data cap, got %v", f.statuses())
+	}
+
+	q.setDataCapped(false)
+	q.enforce(f)
+	if st := f.statuses(); st["a"] == StatusStopped || st["seed"] == StatusStopped {
+		t.Errorf("expected everything back once the cap is lifted, got %v", st)
+	}
+}
@@ -0,0 +1,361 @@
+package torrents
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// remoteHTTPTimeout bounds every call to an external client. A seedbox across the internet
+// can be slow, but a List that hangs holds the queue lock behind it.
+const remoteHTTPTimeout = 30 * time.Second
+
+// qbitInfiniteETA is what qBittorrent reports as the ETA of a torrent that is not moving.
+const qbitInfiniteETA = 8640000
+
+// errQBitForbidden is a 403: the SID cookie expired or was never issued.
+var errQBitForbidden = errors.New("qBittorrent answered 403 Forbidden")
+
+// qbittorrentClient speaks the qBittorrent WebUI API v2. Authentication is the SID cookie the
+// login sets, kept in the jar; a 403 on any call logs in again once and retries.
+type qbittorrentClient struct {
+	base     string
+	username string
+	password string
+	category string
+	http     *http.Client
+
+	// loginMu serializes re-logins, so a burst of calls hitting an expired cookie logs in once.
+	loginMu sync.Mutex
+}
+
+func newQBittorrentClient(opts RemoteOptions) *qbittorrentClient {
+	jar, _ := cookiejar.New(nil)
+	return &qbittorrentClient{
+		base:     strings.TrimRight(opts.URL, "/"),
+		username: opts.Username,
+		password: opts.Password,
+		category: opts.Category,
+		http:     &http.Client{Timeout: remoteHTTPTimeout, Jar: jar},
+	}
+}
+
+func (c *qbittorrentClient) login() error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+	body, err := c.do(http.MethodPost, "/api/v2/auth/login", url.Values{"username": {c.username}, "password": {c.password}})
+	if err != nil {
+		return err
+	}
+	// Credencial errada e 200 com "Fails.", nao um status de erro.
+	if strings.TrimSpace(string(body)) != "Ok." {
+		return fmt.Errorf("qBittorrent rejected the login for user %q", c.username)
+	}
+	return nil
+}
+
+// do sends one request. Form values go in the body for POST and in the query for GET.
+func (c *qbittorrentClient) do(method, path string, form url.Values) ([]byte, error) {
+	var req *http.Request
+	var err error
+	if method == http.MethodGet {
+		req, err = http.NewRequest(method, c.base+path+"?"+form.Encode(), nil)
+	} else {
+		req, err = http.NewRequest(method, c.base+path, strings.NewReader(form.Encode()))
+		if req != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Com a protecao CSRF ligada (o default), o qBittorrent recusa um Referer de outro host;
+	// mandar o proprio host passa em qualquer configuracao.
+	req.Header.Set("Referer", c.base)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		return nil, errQBitForbidden
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("qBittorrent %s: %w", path, errQBitNotFound)
+	case resp.StatusCode == http.StatusConflict:
+		return nil, fmt.Errorf("qBittorrent %s: %w: %s", path, errQBitConflict, strings.TrimSpace(string(body)))
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("qBittorrent %s answered %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// errQBitNotFound is a 404: an endpoint this qBittorrent version does not have, or (for the
+// per-torrent GETs) a hash it does not know. errQBitConflict is a 409: the category already
+// exists, or (5.x) the torrent is already in the client.
+var (
+	errQBitNotFound = errors.New("not found")
+	errQBitConflict = errors.New("conflict")
+)
+
+// call is do with the re-login: one retry after a 403.
+func (c *qbittorrentClient) call(method, path string, form url.Values) ([]byte, error) {
+	body, err := c.do(method, path, form)
+	if !errors.Is(err, errQBitForbidden) {
+		return body, err
+	}
+	if err := c.login(); err != nil {
+		return nil, err
+	}
+	return c.do(method, path, form)
+}
+
+// connect logs in and creates the category. A 409 from createCategory means it already
+// exists, which is the normal case after the first run.
+func (c *qbittorrentClient) connect() error {
+	if err := c.login(); err != nil {
+		return err
+	}
+	_, err := c.call(http.MethodPost, "/api/v2/torrents/createCategory", url.Values{"category": {c.category}})
+	if err != nil && !errors.Is(err, errQBitConflict) {
+		return fmt.Errorf("failed to create category %q: %w", c.category, err)
+	}
+	return nil
+}
+
+func (c *qbittorrentClient) add(magnet, savePath string) error {
+	body, err := c.call(http.MethodPost, "/api/v2/torrents/add", url.Values{
+		"urls":     {magnet},
+		"savepath": {savePath},
+		"category": {c.category},
+	})
+	if err != nil {
+		return err
+	}
+	// Duplicata (inclusive numa categoria que a daemon nao le) e magnet invalido voltam como
+	// 200 "Fails." ate a 4.x; a 5.x responde 409, que do ja transforma em erro.
+	if strings.TrimSpace(string(body)) == "Fails." {
+		return fmt.Errorf("qBittorrent refused the magnet (invalid, or already in the client under another category)")
+	}
+	return nil
+}
+
+// qbitTorrent is the subset of /api/v2/torrents/info the backend reads.
+type qbitTorrent struct {
+	Hash        string  `json:"hash"`
+	Name        string  `json:"name"`
+	ContentPath string  `json:"content_path"`
+	State       string  `json:"state"`
+	Progress    float64 `json:"progress"`
+	Size        int64   `json:"size"`
+	Completed   int64   `json:"completed"`
+	Downloaded  int64   `json:"downloaded"`
+	Uploaded    int64   `json:"uploaded"`
+	DLSpeed     int     `json:"dlspeed"`
+	UPSpeed     int     `json:"upspeed"`
+	NumSeeds    int     `json:"num_seeds"`
+	NumLeechs   int     `json:"num_leechs"`
+	ETA         int64   `json:"eta"`
+	AddedOn     int64   `json:"added_on"`
+	SeedingTime int64   `json:"seeding_time"`
+	Priority    int     `json:"priority"`
+}
+
+func (c *qbittorrentClient) torrents(hashes ...string) ([]remoteTorrent, error) {
+	form := url.Values{"category": {c.category}}
+	if len(hashes) > 0 {
+		form.Set("hashes", strings.Join(hashes, "|"))
+	}
+	body, err := c.call(http.MethodGet, "/api/v2/torrents/info", form)
+	if err != nil {
+		return nil, err
+	}
+	var raw []qbitTorrent
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse qBittorrent torrent list: %w", err)
+	}
+	out := make([]remoteTorrent, 0, len(raw))
+	for _, t := range raw {
+		out = append(out, t.toRemote())
+	}
+	return out, nil
+}
+
+func (t qbitTorrent) toRemote() remoteTorrent {
+	info := TorrentInfo{
+		Hash:             strings.ToLower(t.Hash),
+		Name:             t.Name,
+		DataDir:          t.ContentPath,
+		Completed:        t.Progress >= 1 && t.State != "metaDL" && t.State != "forcedMetaDL",
+		Status:           qbitStatusSlug(t.State),
+		BytesCompleted:   t.Completed,
+		BytesTotal:       t.Size,
+		BytesDownloaded:  t.Downloaded,
+		BytesUploaded:    t.Uploaded,
+		DownloadSpeed:    t.DLSpeed,
+		UploadSpeed:      t.UPSpeed,
+		PeersTotal:       t.NumSeeds + t.NumLeechs,
+		SeededForSeconds: t.SeedingTime,
+		AddedAt:          time.Unix(t.AddedOn, 0),
+	}
+	if t.ETA >= 0 && t.ETA < qbitInfiniteETA && !info.Completed {
+		eta := t.ETA
+		info.ETASeconds = &eta
+	}
+	// A fila do proprio qBittorrent (Options > BitTorrent > Torrent Queueing) ainda segura o
+	// torrent que a daemon quer rodando; a posicao dela e a unica que sabe quando ele comeca.
+	if info.Status == StatusQueued && t.Priority > 0 {
+		info.QueuePosition = t.Priority
+	}
+	rt := remoteTorrent{info: info}
+	switch t.State {
+	case "error":
+		rt.failure = "qBittorrent stopped the torrent with an I/O error"
+	case "missingFiles":
+		rt.failure = "qBittorrent reports the torrent's files missing"
+	}
+	return rt
+}
+
+// qbitStatusSlug maps qBittorrent's torrent state to the API slug statusSlug uses for rain.
+// "paused*" became "stopped*" in qBittorrent 5; both are accepted. A torrent stopped on an
+// error reads "stopped" — the queue then leaves it alone until the failure handler removes it.
+func qbitStatusSlug(state string) string {
+	switch state {
+	case "pausedDL", "pausedUP", "stoppedDL", "stoppedUP", "error", "missingFiles":
+		return StatusStopped
+	case "metaDL", "forcedMetaDL":
+		return "downloading_metadata"
+	case "allocating":
+		return "allocating"
+	case "checkingDL", "checkingUP", "checkingResumeData":
+		return "verifying"
+	case "downloading", "stalledDL", "forcedDL":
+		return "downloading"
+	case "uploading", "stalledUP", "forcedUP":
+		return "seeding"
+	case "queuedDL", "queuedUP":
+		return StatusQueued
+	default:
+		return "unknown"
+	}
+}
+
+func (c *qbittorrentClient) remove(hash string, deleteData bool) error {
+	_, err := c.call(http.MethodPost, "/api/v2/torrents/delete", url.Values{
+		"hashes":      {hash},
+		"deleteFiles": {fmt.Sprint(deleteData)},
+	})
+	return err
+}
+
+// stop/start try the qBittorrent 5 endpoint names first and fall back to the 4.x ones.
+func (c *qbittorrentClient) stop(hash string) error {
+	return c.postVersioned("/api/v2/torrents/stop", "/api/v2/torrents/pause", url.Values{"hashes": {hash}})
+}
+
+func (c *qbittorrentClient) start(hash string) error {
+	return c.postVersioned("/api/v2/torrents/start", "/api/v2/torrents/resume", url.Values{"hashes": {hash}})
+}
+
+func (c *qbittorrentClient) postVersioned(path, legacy string, form url.Values) error {
+	_, err := c.call(http.MethodPost, path, form)
+	if errors.Is(err, errQBitNotFound) {
+		_, err = c.call(http.MethodPost, legacy, form)
+	}
+	return err
+}
+
+func (c *qbittorrentClient) reannounce(hash string) error {
+	_, err := c.call(http.MethodPost, "/api/v2/torrents/reannounce", url.Values{"hashes": {hash}})
+	return err
+}
+
+type qbitTracker struct {
+	URL       string `json:"url"`
+	Status    int    `json:"status"`
+	NumSeeds  int    `json:"num_seeds"`
+	NumLeechs int    `json:"num_leeches"`
+	Msg       string `json:"msg"`
+}
+
+func (c *qbittorrentClient) trackers(hash string) ([]TrackerInfo, error) {
+	body, err := c.call(http.MethodGet, "/api/v2/torrents/trackers", url.Values{"hash": {hash}})
+	if err != nil {
+		return nil, err
+	}
+	var raw []qbitTracker
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse qBittorrent trackers: %w", err)
+	}
+	out := make([]TrackerInfo, 0, len(raw))
+	for _, tr := range raw {
+		// DHT, PeX e LSD vem como pseudo-trackers "** [DHT] **".
+		if strings.HasPrefix(tr.URL, "** [") {
+			continue
+		}
+		info := TrackerInfo{URL: tr.URL, Status: qbitTrackerStatusSlug(tr.Status), Seeders: max(tr.NumSeeds, 0), Leechers: max(tr.NumLeechs, 0)}
+		if tr.Status == 4 {
+			info.Error = tr.Msg
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// qbitTrackerStatusSlug maps qBittorrent's tracker status (0 disabled, 1 not contacted,
+// 2 working, 3 updating, 4 not working) to trackerStatusSlug's slugs.
+func qbitTrackerStatusSlug(status int) string {
+	switch status {
+	case 1:
+		return "not_contacted"
+	case 2:
+		return "working"
+	case 3:
+		return "contacting"
+	case 4:
+		return "not_working"
+	default:
+		return "unknown"
+	}
+}
+
+func (c *qbittorrentClient) addTrackers(hash string, urls []string) error {
+	_, err := c.call(http.MethodPost, "/api/v2/torrents/addTrackers", url.Values{
+		"hash": {hash},
+		"urls": {strings.Join(urls, "\n")},
+	})
+	return err
+}
+
+func (c *qbittorrentClient) recheck(hash string) error {
+	_, err := c.call(http.MethodPost, "/api/v2/torrents/recheck", url.Values{"hashes": {hash}})
+	return err
+}
+
+func (c *qbittorrentClient) pieces(hash string) (uint32, uint32, error) {
+	body, err := c.call(http.MethodGet, "/api/v2/torrents/properties", url.Values{"hash": {hash}})
+	if err != nil {
+		return 0, 0, err
+	}
+	var props struct {
+		PiecesHave int64 `json:"pieces_have"`
+		PiecesNum  int64 `json:"pieces_num"`
+	}
+	if err := json.Unmarshal(body, &props); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse qBittorrent torrent properties: %w", err)
+	}
+	// Sem metadata o qBittorrent devolve -1 nos dois.
+	return uint32(max(props.PiecesHave, 0)), uint32(max(props.PiecesNum, 0)), nil
+}
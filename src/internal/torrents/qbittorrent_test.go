@@ -0,0 +1,414 @@
+package torrents
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeQBit is an in-process qBittorrent WebUI API: the endpoints RemoteBackend calls, over an
+// in-memory torrent map, with the SID cookie checked on every call.
+type fakeQBit struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu sync.Mutex
+	// sid is the cookie the last login issued; "" makes every call answer 403.
+	sid        string
+	logins     int
+	categories map[string]bool
+	torrents   map[string]*fakeQBitTorrent
+	// addCalls counts /torrents/add requests.
+	addCalls int
+	// deleteFiles records the deleteFiles argument of each /torrents/delete, by hash.
+	deleteFiles map[string]bool
+	// legacy serves only the 4.x /pause and /resume, answering 404 on /stop and /start.
+	legacy bool
+}
+
+type fakeQBitTorrent struct {
+	qbitTorrent
+	category string
+	savePath string
+	magnet   string
+}
+
+func newFakeQBit(t *testing.T) *fakeQBit {
+	t.Helper()
+	f := &fakeQBit{
+		t:           t,
+		categories:  map[string]bool{},
+		torrents:    map[string]*fakeQBitTorrent{},
+		deleteFiles: map[string]bool{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/auth/login", f.handleLogin)
+	mux.HandleFunc("/api/v2/torrents/createCategory", f.authed(f.handleCreateCategory))
+	mux.HandleFunc("/api/v2/torrents/add", f.authed(f.handleAdd))
+	mux.HandleFunc("/api/v2/torrents/info", f.authed(f.handleInfo))
+	mux.HandleFunc("/api/v2/torrents/delete", f.authed(f.handleDelete))
+	mux.HandleFunc("/api/v2/torrents/stop", f.authed(f.handleState("stoppedDL", false)))
+	mux.HandleFunc("/api/v2/torrents/start", f.authed(f.handleState("downloading", false)))
+	mux.HandleFunc("/api/v2/torrents/pause", f.authed(f.handleState("pausedDL", true)))
+	mux.HandleFunc("/api/v2/torrents/resume", f.authed(f.handleState("downloading", true)))
+	f.srv = httptest.NewServer(mux)
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func (f *fakeQBit) handleLogin(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.FormValue("username") != "admin" || r.FormValue("password") != "secret" {
+		_, _ = w.Write([]byte("Fails."))
+		return
+	}
+	f.logins++
+	f.sid = "sid-" + string(rune('a'+f.logins))
+	http.SetCookie(w, &http.Cookie{Name: "SID", Value: f.sid, Path: "/"})
+	_, _ = w.Write([]byte("Ok."))
+}
+
+func (f *fakeQBit) authed(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		c, err := r.Cookie("SID")
+		ok := err == nil && f.sid != "" && c.Value == f.sid
+		f.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (f *fakeQBit) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := r.FormValue("category")
+	if f.categories[name] {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	f.categories[name] = true
+}
+
+func (f *fakeQBit) handleAdd(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.addCalls++
+	magnet := r.FormValue("urls")
+	hash, err := parseInfoHash(magnet)
+	if err != nil || f.torrents[hash] != nil {
+		_, _ = w.Write([]byte("Fails."))
+		return
+	}
+	savePath := r.FormValue("savepath")
+	f.torrents[hash] = &fakeQBitTorrent{
+		qbitTorrent: qbitTorrent{
+			Hash:        hash,
+			Name:        "Episode " + hash[:4] + ".mkv",
+			ContentPath: savePath + "/Episode " + hash[:4] + ".mkv",
+			State:       "downloading",
+			Size:        1000,
+			ETA:         qbitInfiniteETA,
+			AddedOn:     time.Now().Unix(),
+		},
+		category: r.FormValue("category"),
+		savePath: savePath,
+		magnet:   magnet,
+	}
+	_, _ = w.Write([]byte("Ok."))
+}
+
+func (f *fakeQBit) handleInfo(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var wanted map[string]bool
+	if hs := r.URL.Query().Get("hashes"); hs != "" {
+		wanted = map[string]bool{}
+		for _, h := range strings.Split(hs, "|") {
+			wanted[h] = true
+		}
+	}
+	out := []qbitTorrent{}
+	for h, t := range f.torrents {
+		if t.category != r.URL.Query().Get("category") || (wanted != nil && !wanted[h]) {
+			continue
+		}
+		out = append(out, t.qbitTorrent)
+	}
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func (f *fakeQBit) handleDelete(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h := r.FormValue("hashes")
+	delete(f.torrents, h)
+	f.deleteFiles[h] = r.FormValue("deleteFiles") == "true"
+}
+
+func (f *fakeQBit) handleState(state string, legacyEndpoint bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.legacy != legacyEndpoint {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if t := f.torrents[r.FormValue("hashes")]; t != nil {
+			t.State = state
+		}
+	}
+}
+
+// set edits a torrent under the lock.
+func (f *fakeQBit) set(hash string, edit func(t *fakeQBitTorrent)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	edit(f.torrents[hash])
+}
+
+func (f *fakeQBit) get(hash string) fakeQBitTorrent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t := f.torrents[hash]; t != nil {
+		return *t
+	}
+	return fakeQBitTorrent{}
+}
+
+func newQBitBackend(t *testing.T, f *fakeQBit) *RemoteBackend {
+	t.Helper()
+	b, err := NewRemoteBackend(RemoteOptions{
+		Client:         ClientQBittorrent,
+		URL:            f.srv.URL,
+		Username:       "admin",
+		Password:       "secret",
+		Category:       "anime",
+		RemoteSavePath: "/downloads",
+	}, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewRemoteBackend: %v", err)
+	}
+	t.Cleanup(func() { _ = b.Close() })
+	return b
+}
+
+const qbitTestMagnet = "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567&dn=ep"
+
+func TestQBittorrentBackendAddListRemove(t *testing.T) {
+	f := newFakeQBit(t)
+	b := newQBitBackend(t, f)
+	local := filepath.Join(t.TempDir(), "library", ".torrents")
+
+	// Before Ensure there is no connection: nil, not an empty list.
+	if got := b.List(); got != nil {
+		t.Fatalf("List before Ensure = %v, want nil", got)
+	}
+	if _, err := b.Add(qbitTestMagnet); !errors.Is(err, ErrSessionNotReady) {
+		t.Fatalf("Add before Ensure = %v, want ErrSessionNotReady", err)
+	}
+
+	created, err := b.Ensure(local)
+	if err != nil || !created {
+		t.Fatalf("Ensure = (%v, %v), want (true, nil)", created, err)
+	}
+	if !f.categories["anime"] {
+		t.Error("Ensure did not create the category")
+	}
+	if created, err := b.Ensure(local); err != nil || created {
+		t.Errorf("second Ensure = (%v, %v), want (false, nil)", created, err)
+	}
+
+	b.SetExtraTrackers([]string{"udp://tracker.example:1337/announce"})
+	hash, err := b.Add(qbitTestMagnet)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if hash != "0123456789abcdef0123456789abcdef01234567" {
+		t.Fatalf("Add hash = %s", hash)
+	}
+	added := f.get(hash)
+	if added.category != "anime" || added.savePath != "/downloads" {
+		t.Errorf("added with category %q, savepath %q; want anime, /downloads", added.category, added.savePath)
+	}
+	if !strings.Contains(added.magnet, "tr="+url.QueryEscape("udp://tracker.example:1337/announce")) {
+		t.Errorf("extra tracker missing from the magnet: %s", added.magnet)
+	}
+
+	// Adding it again returns the same hash without a second add.
+	if again, err := b.Add(qbitTestMagnet); err != nil || again != hash || f.addCalls != 1 {
+		t.Errorf("re-Add = (%s, %v) with %d add calls, want the same hash and 1 call", again, err, f.addCalls)
+	}
+
+	// A torrent the user added by hand, outside the category, is not the daemon's.
+	f.mu.Lock()
+	f.torrents["ffffffffffffffffffffffffffffffffffffffff"] = &fakeQBitTorrent{
+		qbitTorrent: qbitTorrent{Hash: "ffffffffffffffffffffffffffffffffffffffff", State: "downloading"},
+	}
+	f.mu.Unlock()
+
+	list := b.List()
+	if len(list) != 1 || list[0].Hash != hash {
+		t.Fatalf("List = %+v, want only %s", list, hash)
+	}
+	// The content path is translated from the client's view to the daemon's.
+	if want := filepath.Join(local, "Episode 0123.mkv"); list[0].DataDir != want {
+		t.Errorf("DataDir = %q, want %q", list[0].DataDir, want)
+	}
+	if list[0].Status != "downloading" || list[0].Completed {
+		t.Errorf("status = %s completed = %v, want downloading, incomplete", list[0].Status, list[0].Completed)
+	}
+
+	if err := b.Remove(hash, false); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if !f.deleteFiles[hash] {
+		t.Error("Remove(keepData=false) did not ask qBittorrent to delete the files")
+	}
+}
+
+// qBittorrent 4.x only has /pause and /resume; 5.x renamed them to /stop and /start.
+func TestQBittorrentPauseFallsBackToLegacyEndpoints(t *testing.T) {
+	for _, legacy := range []bool{false, true} {
+		f := newFakeQBit(t)
+		f.legacy = legacy
+		b := newQBitBackend(t, f)
+		if _, err := b.Ensure(t.TempDir()); err != nil {
+			t.Fatalf("Ensure: %v", err)
+		}
+		hash, err := b.Add(qbitTestMagnet)
+		if err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if err := b.Pause(hash); err != nil {
+			t.Fatalf("legacy=%v: Pause: %v", legacy, err)
+		}
+		if got, _ := b.Get(hash); got.Status != StatusStopped {
+			t.Errorf("legacy=%v: status after Pause = %s, want stopped", legacy, got.Status)
+		}
+		if err := b.Resume(hash); err != nil {
+			t.Fatalf("legacy=%v: Resume: %v", legacy, err)
+		}
+		if got, _ := b.Get(hash); got.Status != "downloading" {
+			t.Errorf("legacy=%v: status after Resume = %s, want downloading", legacy, got.Status)
+		}
+	}
+}
+
+// An expired SID answers 403; the client logs in again once and the call goes through.
+func TestQBittorrentReloginOnForbidden(t *testing.T) {
+	f := newFakeQBit(t)
+	b := newQBitBackend(t, f)
+	if _, err := b.Ensure(t.TempDir()); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	f.mu.Lock()
+	f.sid = "expired-on-the-server"
+	f.mu.Unlock()
+
+	if got := b.List(); got == nil {
+		t.Fatal("List after the session expired = nil, want a re-login and a list")
+	}
+	if f.logins != 2 {
+		t.Errorf("logins = %d, want 2", f.logins)
+	}
+}
+
+func TestQBittorrentEnsureRejectsBadCredentials(t *testing.T) {
+	f := newFakeQBit(t)
+	b, err := NewRemoteBackend(RemoteOptions{Client: ClientQBittorrent, URL: f.srv.URL, Username: "admin", Password: "wrong", Category: "anime"}, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewRemoteBackend: %v", err)
+	}
+	if created, err := b.Ensure(t.TempDir()); err == nil || created {
+		t.Fatalf("Ensure with a wrong password = (%v, %v), want an error", created, err)
+	}
+	if got := b.List(); got != nil {
+		t.Errorf("List after a failed Ensure = %v, want nil", got)
+	}
+}
+
+// Polling replaces rain's completion listeners: a torrent that finishes fires onComplete once,
+// and one the client stops on an error fires onFailed once.
+func TestQBittorrentPollDrivesCallbacks(t *testing.T) {
+	f := newFakeQBit(t)
+	b := newQBitBackend(t, f)
+	var mu sync.Mutex
+	var completed, failed []string
+	b.SetCallbacks(
+		func(hash string) { mu.Lock(); completed = append(completed, hash); mu.Unlock() },
+		func(hash string, err error) { mu.Lock(); failed = append(failed, hash); mu.Unlock() },
+	)
+	if _, err := b.Ensure(t.TempDir()); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	hash, err := b.Add(qbitTestMagnet)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	b.poll() // first poll: records only
+	f.set(hash, func(t *fakeQBitTorrent) { t.Progress = 1; t.State = "uploading"; t.Completed = 1000 })
+	b.poll()
+	b.poll()
+	mu.Lock()
+	if len(completed) != 1 || completed[0] != hash {
+		t.Errorf("completed = %v, want [%s] once", completed, hash)
+	}
+	mu.Unlock()
+
+	f.set(hash, func(t *fakeQBitTorrent) { t.State = "error" })
+	b.poll()
+	b.poll()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failed) != 1 || failed[0] != hash {
+		t.Errorf("failed = %v, want [%s] once", failed, hash)
+	}
+}
+
+// A client that stops answering reads as "do not know" (nil), so the queue does not prune
+// everything it knows against an empty list.
+func TestQBittorrentUnreachableListsNil(t *testing.T) {
+	f := newFakeQBit(t)
+	b := newQBitBackend(t, f)
+	if _, err := b.Ensure(t.TempDir()); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	if got := b.List(); got == nil {
+		t.Fatal("List with the client up = nil, want an empty list")
+	}
+	f.srv.Close()
+	if got := b.List(); got != nil {
+		t.Errorf("List with the client down = %v, want nil", got)
+	}
+}
+
+func TestQBitStatusSlug(t *testing.T) {
+	cases := map[string]string{
+		"pausedDL":   StatusStopped,
+		"stoppedUP":  StatusStopped,
+		"metaDL":     "downloading_metadata",
+		"stalledDL":  "downloading",
+		"stalledUP":  "seeding",
+		"queuedDL":   StatusQueued,
+		"checkingUP": "verifying",
+		"moving":     "unknown",
+	}
+	for state, want := range cases {
+		if got := qbitStatusSlug(state); got != want {
+			t.Errorf("qbitStatusSlug(%q) = %q, want %q", state, got, want)
+		}
+	}
+}
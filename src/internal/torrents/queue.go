@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 	"sync"
+	"time"
 
 	"AutoAnimeDownloader/src/internal/logger"
 )
@@ -26,7 +27,8 @@ type queueOps interface {
 // queue e a lista ordenada de TODOS os torrents incompletos da sessao: os `limit` primeiros
 // que nao estao pausados baixam, o resto espera. Conter tudo (e nao so quem a fila pausou) e
 // o que faz "priorizar" preemptar de verdade e o que da uma posicao estavel para mostrar na
-// tela — nada e inserido no meio, entao a posicao so muda por acao do usuario.
+// tela — na politica fifo nada e inserido no meio, entao a posicao so muda por acao do
+// usuario. As outras politicas reordenam por cima de order (ver effectiveOrder).
 //
 // Ordem de lock: queue.mu -> SessionManager.mu, NUNCA o contrario. enforce segura o
 // queue.mu enquanto chama list/pause/resume, que pegam o RLock do manager; por isso todo
@@ -38,6 +40,29 @@ type queue struct {
 	paused []string // pausados pelo usuario: nao ocupam slot, nunca sobem sozinhos.
 	// SO hashes incompletos: Pause/Resume de um torrent completo delegam direto para a rain
 	// (seeding nunca ocupou slot, entao a fila nao tem escrituracao para ele).
+	// prioritized sao os hashes que o usuario priorizou, o mais recente primeiro. Na fifo e
+	// redundante com o inicio de order; nas outras politicas e o que faz o "priorizar" vencer
+	// a politica (ver effectiveOrder). Sai quando o torrent completa ou e retomado com Resume.
+	prioritized []string
+
+	// policy e hints alimentam effectiveOrder. Nao sao persistidos: vem da config e do daemon,
+	// que os reenviam a cada passe.
+	policy QueuePolicy
+	hints  map[string]QueueHint
+
+	// Pausa global (pause-all, ver pauseall.go). Enquanto pausedAll, nenhum incompleto comeca
+	// e todo seeder e parado; pausedUntil zero = ate o resume-all. pausedSeeders sao os
+	// completos que a pausa global parou — so eles voltam no resume-all, o seeder que o
+	// usuario ja tinha parado a mao continua parado. Os incompletos nao precisam de lista
+	// propria: quem o usuario pausou ja esta em paused.
+	pausedAll     bool
+	pausedUntil   time.Time
+	pausedSeeders []string
+	// dataCapped e a mesma retencao, ligada pelo teto mensal de dados (ver
+	// daemon.ApplyDataCap) e nao pelo usuario: por isso fica separada de pausedAll, e um
+	// resume-all nao a desfaz. Nao e persistida — o daemon a recalcula do ledger e a reenvia
+	// no boot, a cada amostra e a cada passe.
+	dataCapped bool
 
 	// queued e o resultado do passo 3 do ultimo enforce: quem esta em order, nao esta em
 	// paused e ficou fora dos `limit` primeiros — mapeado para a POSICAO (1-based) na
@@ -60,6 +85,13 @@ const queueFileName = "queue.json"
 type queueState struct {
 	Order  []string `json:"order"`
 	Paused []string `json:"paused"`
+	// Prioritized e omitempty para o queue.json de quem nunca priorizou nada continuar
+	// identico ao de antes das politicas.
+	Prioritized []string `json:"prioritized,omitempty"`
+	// A pausa global sobrevive a restart pelo mesmo arquivo; omitempty pelo mesmo motivo.
+	PausedAll     bool       `json:"paused_all,omitempty"`
+	PausedUntil   *time.Time `json:"paused_until,omitempty"`
+	PausedSeeders []string   `json:"paused_seeders,omitempty"`
 }
 
 // load le queue.json. Arquivo ausente, vazio ou ilegivel = fila vazia; nunca impede o daemon
@@ -84,11 +116,15 @@ func (q *queue) load(path string) {
 		logger.Logger.Warn().Err(err).Str("path", path).Msg("The download queue file is corrupted; rebuilding the queue from scratch")
 		return
 	}
-	q.order, q.paused = state.Order, state.Paused
+	q.order, q.paused, q.prioritized = state.Order, state.Paused, state.Prioritized
+	q.pausedAll, q.pausedSeeders = state.PausedAll, state.PausedSeeders
+	if state.PausedUntil != nil {
+		q.pausedUntil = *state.PausedUntil
+	}
 	q.lastSaved = data
 }
 
-// save grava order/paused quando diferem do ultimo estado gravado. Chamada com q.mu segurado.
+// save grava o estado da fila quando diferem do ultimo estado gravado. Chamada com q.mu segurado.
 //
 // lastSaved SO e atualizado depois do Rename bem-sucedido: atualiza-lo junto com a tentativa
 // transformaria uma falha transitoria (disco cheio por um minuto) em perda permanente — o
@@ -97,7 +133,11 @@ func (q *queue) save() {
 	if q.path == "" {
 		return
 	}
-	data, err := json.Marshal(queueState{Order: q.order, Paused: q.paused})
+	state := queueState{Order: q.order, Paused: q.paused, Prioritized: q.prioritized, PausedAll: q.pausedAll, PausedSeeders: q.pausedSeeders}
+	if !q.pausedUntil.IsZero() {
+		state.PausedUntil = &q.pausedUntil
+	}
+	data, err := json.Marshal(state)
 	if err != nil || bytes.Equal(data, q.lastSaved) {
 		return
 	}
@@ -122,20 +162,38 @@ func (q *queue) setLimit(n int) {
 	q.mu.Unlock()
 }
 
+// setPolicy troca a politica de ordenacao. O chamador roda enforce em seguida.
+func (q *queue) setPolicy(p QueuePolicy) {
+	q.mu.Lock()
+	q.policy = p
+	q.mu.Unlock()
+}
+
+// setHints substitui os hints inteiros: o daemon manda o retrato completo a cada passe, e
+// hint de torrent que saiu da sessao simplesmente deixa de vir.
+func (q *queue) setHints(hints map[string]QueueHint) {
+	q.mu.Lock()
+	q.hints = hints
+	q.mu.Unlock()
+}
+
 // drop tira um hash da fila inteira. Usado pela remocao.
 func (q *queue) drop(hash string) {
 	q.mu.Lock()
 	q.order = without(q.order, hash)
 	q.paused = without(q.paused, hash)
+	q.prioritized = without(q.prioritized, hash)
 	q.mu.Unlock()
 }
 
 // pushBack manda o hash para o FIM da fila e o tira de paused. E o que um resume do usuario
-// faz: volta para a fila, nao para o topo dela.
+// faz: volta para a fila, nao para o topo dela — e deixa de ser priorizado, senao o "fim" nas
+// outras politicas seria o inicio.
 func (q *queue) pushBack(hash string) {
 	q.mu.Lock()
 	q.order = append(without(q.order, hash), hash)
 	q.paused = without(q.paused, hash)
+	q.prioritized = without(q.prioritized, hash)
 	q.mu.Unlock()
 }
 
@@ -162,15 +220,18 @@ func (q *queue) prioritize(hashes []string) {
 
 	front := make([]string, 0, len(hashes))
 	rest := q.order
+	pinned := q.prioritized
 	for _, h := range hashes {
 		if contains(front, h) {
 			continue
 		}
 		front = append(front, h)
 		rest = without(rest, h)
+		pinned = without(pinned, h)
 		q.paused = without(q.paused, h)
 	}
 	q.order = append(front, rest...)
+	q.prioritized = append(append([]string(nil), front...), pinned...)
 }
 
 // markQueued escreve o slug "queued" e a posicao nos torrents que estao esperando. E o unico
@@ -202,6 +263,10 @@ func (q *queue) enforce(ops queueOps) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
+	// Antes do passo 0: o prazo da pausa global vence mesmo sem sessao, para PausedAll nao
+	// mentir. Os seeders parados voltam no primeiro enforce com sessao (passo 4b).
+	q.expirePauseAll(time.Now())
+
 	// 0. Sem sessao, sai na hora. list() devolve nil enquanto nao ha sessao; uma sessao sem
 	//    torrents devolve slice vazio NAO-nil. A distincao e exatamente a que importa: nil e
 	//    "nao sei nada", nao "nao ha nada". Sem esta guarda o passo 1 podaria order/paused
@@ -231,6 +296,7 @@ func (q *queue) enforce(ops queueOps) {
 	// 1. Poda: sai de order/paused quem sumiu da sessao ou completou.
 	q.order = filter(q.order, incomplete)
 	q.paused = filter(q.paused, incomplete)
+	q.prioritized = filter(q.prioritized, incomplete)
 
 	// Latch de upgrade, uma vez na vida da instalacao: sem queue.json nao ha como distinguir
 	// uma pausa manual de um torrent que a fila antiga (so memoria) tinha pausado, entao os
@@ -259,14 +325,19 @@ func (q *queue) enforce(ops queueOps) {
 		}
 	}
 
-	// 3. Calcula o desejado: percorre order pulando paused, marcando os `limit` primeiros como
-	//    ativos e o resto como enfileirados, numerados a partir de 1 na ordem em que vao
-	//    comecar. A numeracao nao custa uma segunda passada.
+	// 3. Calcula o desejado: percorre a ordem efetiva (priorizados + politica) pulando paused,
+	//    marcando os `limit` primeiros como ativos e o resto como enfileirados, numerados a
+	//    partir de 1 na ordem em que vao comecar. A numeracao nao custa uma segunda passada, e
+	//    por sair daqui QueuePosition reflete a politica.
+	//
+	//    Com a pausa global (ou o teto de dados) ninguem e desejado nem enfileirado: a tela
+	//    mostra tudo parado, e uma posicao na fila prometeria um inicio que nao vai acontecer
+	//    ate o resume-all.
 	wanted := make(map[string]bool, len(q.order))
 	queued := make(map[string]int)
 	active, waiting := 0, 0
-	for _, h := range q.order {
-		if contains(q.paused, h) {
+	for _, h := range q.effectiveOrder(byHash) {
+		if q.holding() || contains(q.paused, h) {
 			continue
 		}
 		if q.limit <= 0 || active < q.limit {
@@ -310,6 +381,10 @@ func (q *queue) enforce(ops queueOps) {
 		}
 	}
 
+	// 4b. Seeders: fora de order, entao so a pausa global e o teto de dados mexem neles (ver
+	//     applyPauseAll).
+	q.applyPauseAll(ops, all)
+
 	// 5. Salva, se mudou.
 	q.save()
 }
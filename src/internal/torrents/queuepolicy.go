@@ -0,0 +1,143 @@
+package torrents
+
+import "sort"
+
+// QueuePolicy decide em que ordem os torrents esperando na fila comecam a baixar. Quem o
+// usuario priorizou vem SEMPRE antes, em qualquer politica (ver queue.effectiveOrder): a
+// politica ordena so o resto.
+type QueuePolicy string
+
+const (
+	// QueueFIFO e a ordem de adicao — o comportamento de antes das politicas.
+	QueueFIFO QueuePolicy = "fifo"
+	// QueueSmallestFirst poe na frente quem falta menos baixar. E o que tira o episodio da
+	// semana de tras de um pack de 60 GiB adicionado antes dele.
+	QueueSmallestFirst QueuePolicy = "smallest_first"
+	// QueueAiringFirst poe na frente os torrents marcados como Airing em QueueHint: episodio
+	// de anime em exibicao que foi ao ar ha pouco. O resto segue em FIFO.
+	QueueAiringFirst QueuePolicy = "airing_first"
+	// QueueFairShare reveza entre animes em vez de esgotar um antes do proximo, na proporcao
+	// dos pesos (QueueHint.Weight).
+	QueueFairShare QueuePolicy = "fair_share"
+)
+
+// IsQueuePolicy reporta se s e uma das politicas conhecidas.
+func IsQueuePolicy(s string) bool {
+	switch QueuePolicy(s) {
+	case QueueFIFO, QueueSmallestFirst, QueueAiringFirst, QueueFairShare:
+		return true
+	}
+	return false
+}
+
+// QueueHint e o que a fila sabe de um torrent alem do que a rain reporta. Vem do daemon (o
+// pacote torrents nao conhece anime nem AniList) e so as politicas airing_first e fair_share
+// o usam. Torrent sem hint conta como anime proprio, peso 1, fora do ar.
+type QueueHint struct {
+	// AnimeID agrupa os torrents do mesmo anime no fair_share. 0 = sem anime conhecido.
+	AnimeID int
+	// Weight e o peso do anime no fair_share: peso 2 recebe dois slots para cada um de um
+	// anime de peso 1. 0 ou negativo vale 1.
+	Weight int
+	// Airing marca episodio de anime em exibicao que foi ao ar ha pouco.
+	Airing bool
+}
+
+// effectiveOrder e a ordem em que os torrents de order comecam: os priorizados primeiro, na
+// ordem em que foram priorizados, depois o resto ordenado pela politica. A ordenacao e
+// ESTAVEL sobre order, entao todo empate — e a politica fifo inteira — cai na ordem de adicao.
+// Chamado com q.mu segurado.
+//
+// order continua sendo a fonte persistida; isto e recalculado a cada enforce, porque tamanho
+// restante e hints mudam sem acao do usuario.
+func (q *queue) effectiveOrder(byHash map[string]TorrentInfo) []string {
+	out := make([]string, 0, len(q.order))
+	for _, h := range q.prioritized {
+		if contains(q.order, h) {
+			out = append(out, h)
+		}
+	}
+	rest := filter(append([]string(nil), q.order...), func(h string) bool { return !contains(out, h) })
+
+	switch q.policy {
+	case QueueSmallestFirst:
+		sort.SliceStable(rest, func(i, j int) bool {
+			return remainingBytes(byHash[rest[i]]) < remainingBytes(byHash[rest[j]])
+		})
+	case QueueAiringFirst:
+		sort.SliceStable(rest, func(i, j int) bool {
+			return q.hints[rest[i]].Airing && !q.hints[rest[j]].Airing
+		})
+	case QueueFairShare:
+		rest = q.fairShare(rest)
+	}
+	return append(out, rest...)
+}
+
+// remainingBytes e quanto falta baixar. Vem das pecas, nao de BytesCompleted: pausar zera
+// BytesCompleted na rain (ver TorrentInfo.PiecesHave), e a fila pausa torrents o tempo todo —
+// um pack pausado em 99% pareceria ter tudo por baixar.
+//
+// Os backends remotos nao trazem pecas no poll (so o recheck as le), e neles BytesCompleted
+// sobrevive a pausa — o qBittorrent e o Transmission o guardam —, entao sem PiecesTotal vale
+// BytesTotal-BytesCompleted.
+//
+// Sem metadata o tamanho e desconhecido e conta como 0, ou seja, vai para a frente: o magnet
+// precisa estar ativo para buscar a metadata, e parado no fim da fila nunca saberia o proprio
+// tamanho. Quando ela chega, o enforce seguinte o reposiciona.
+func remainingBytes(t TorrentInfo) int64 {
+	if t.BytesTotal <= 0 {
+		return 0
+	}
+	if t.PiecesTotal == 0 {
+		return max(t.BytesTotal-t.BytesCompleted, 0)
+	}
+	return t.BytesTotal - t.BytesTotal*int64(t.PiecesHave)/int64(t.PiecesTotal)
+}
+
+// fairShare intercala os animes pela ordem de "tempo virtual": o k-esimo torrent (a partir de
+// 1) de um anime de peso w tem tempo k/w, e os menores vao primeiro. Com pesos iguais e um
+// revezamento simples; com peso 2 contra 1, dois de um para cada do outro. Quem esta em
+// paused fica de fora da contagem — nao ocupa slot, entao nao pode gastar a vez do anime — e
+// vai para o fim.
+func (q *queue) fairShare(rest []string) []string {
+	type slot struct {
+		hash   string
+		k      int // posicao dentro do anime, a partir de 1
+		weight int
+		index  int // posicao em rest, o desempate
+	}
+	seen := make(map[int]int)
+	slots := make([]slot, 0, len(rest))
+	var paused []string
+	for i, h := range rest {
+		if contains(q.paused, h) {
+			paused = append(paused, h)
+			continue
+		}
+		hint := q.hints[h]
+		w := hint.Weight
+		if w <= 0 {
+			w = 1
+		}
+		k := 1
+		if hint.AnimeID != 0 {
+			seen[hint.AnimeID]++
+			k = seen[hint.AnimeID]
+		}
+		slots = append(slots, slot{hash: h, k: k, weight: w, index: i})
+	}
+	// k_i/w_i < k_j/w_j sem divisao: inteiros nao empatam errado por arredondamento.
+	sort.SliceStable(slots, func(i, j int) bool {
+		a, b := slots[i].k*slots[j].weight, slots[j].k*slots[i].weight
+		if a != b {
+			return a < b
+		}
+		return slots[i].index < slots[j].index
+	})
+	out := make([]string, 0, len(rest))
+	for _, s := range slots {
+		out = append(out, s.hash)
+	}
+	return append(out, paused...)
+}
@@ -0,0 +1,177 @@
+package torrents
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// sized builds a stopped torrent with a known size and piece progress.
+func sized(hash string, bytesTotal int64, have, total uint32) TorrentInfo {
+	return TorrentInfo{Hash: hash, Status: StatusStopped, BytesTotal: bytesTotal, PiecesHave: have, PiecesTotal: total}
+}
+
+// The case the policy exists for: a huge catch-up pack added first no longer blocks the
+// small episode added after it.
+func TestQueueSmallestFirstStartsTheSmallestRemaining(t *testing.T) {
+	f := &queueFake{torrents: withAddOrder(
+		sized("pack", 60<<30, 0, 100),
+		sized("ep", 300<<20, 0, 100),
+		sized("half", 2<<30, 50, 100), // 1 GiB left
+	)}
+	q := newQueue(1)
+	q.policy = QueueSmallestFirst
+
+	q.enforce(f)
+
+	if f.statuses()["ep"] != "downloading" {
+		t.Fatalf("expected the smallest torrent to start, got %v", f.statuses())
+	}
+	if q.queued["half"] != 1 || q.queued["pack"] != 2 {
+		t.Fatalf("expected half=#1 and pack=#2, got %v", q.queued)
+	}
+	// order keeps the add order: the policy reorders on top of it, it does not rewrite it.
+	assertOrder(t, q.order, "pack", "ep", "half")
+}
+
+// Without metadata the size is unknown, and the torrent has to run to learn it.
+func TestQueueSmallestFirstRunsTorrentsWithoutMetadata(t *testing.T) {
+	f := &queueFake{torrents: withAddOrder(
+		sized("known", 300<<20, 0, 100),
+		stopped("magnet"),
+	)}
+	q := newQueue(1)
+	q.policy = QueueSmallestFirst
+
+	q.enforce(f)
+
+	if f.statuses()["magnet"] != "downloading" {
+		t.Fatalf("expected the torrent without metadata to start, got %v", f.statuses())
+	}
+}
+
+func TestQueueAiringFirst(t *testing.T) {
+	f := &queueFake{torrents: withAddOrder(stopped("old1"), stopped("old2"), stopped("new"))}
+	q := newQueue(1)
+	q.policy = QueueAiringFirst
+	q.hints = map[string]QueueHint{"new": {AnimeID: 2, Airing: true}}
+
+	q.enforce(f)
+
+	if f.statuses()["new"] != "downloading" {
+		t.Fatalf("expected the airing episode to start, got %v", f.statuses())
+	}
+	if q.queued["old1"] != 1 || q.queued["old2"] != 2 {
+		t.Fatalf("the rest must stay in add order, got %v", q.queued)
+	}
+}
+
+// Weight 2 against weight 1: two of A for each of B, ties broken by add order.
+func TestQueueFairShareInterleavesByWeight(t *testing.T) {
+	f := &queueFake{torrents: withAddOrder(
+		stopped("a1"), stopped("a2"), stopped("a3"), stopped("a4"),
+		stopped("b1"), stopped("b2"),
+	)}
+	q := newQueue(1)
+	q.policy = QueueFairShare
+	q.hints = map[string]QueueHint{
+		"a1": {AnimeID: 1, Weight: 2}, "a2": {AnimeID: 1, Weight: 2},
+		"a3": {AnimeID: 1, Weight: 2}, "a4": {AnimeID: 1, Weight: 2},
+		"b1": {AnimeID: 2}, "b2": {AnimeID: 2},
+	}
+
+	q.enforce(f)
+
+	// Virtual times: a1 .5, a2 1, b1 1, a3 1.5, a4 2, b2 2.
+	want := []string{"a2", "b1", "a3", "a4", "b2"}
+	for i, h := range want {
+		if q.queued[h] != i+1 {
+			t.Fatalf("expected %s at #%d, got %v", h, i+1, q.queued)
+		}
+	}
+}
+
+// Manual prioritize wins over any policy, and resuming takes the pin away.
+func TestQueuePrioritizeWinsOverThePolicy(t *testing.T) {
+	f := &queueFake{torrents: withAddOrder(
+		sized("small", 100<<20, 0, 100),
+		sized("pack", 60<<30, 0, 100),
+	)}
+	q := newQueue(1)
+	q.policy = QueueSmallestFirst
+	q.enforce(f)
+
+	q.prioritize([]string{"pack"})
+	q.enforce(f)
+	if f.statuses()["pack"] != "downloading" || f.statuses()["small"] != StatusStopped {
+		t.Fatalf("expected the prioritized pack to preempt, got %v", f.statuses())
+	}
+
+	q.pushBack("pack")
+	q.enforce(f)
+	if f.statuses()["small"] != "downloading" {
+		t.Fatalf("after a resume the policy must decide again, got %v", f.statuses())
+	}
+}
+
+func TestQueuePrioritizedSurvivesReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	f := &queueFake{torrents: withAddOrder(stopped("a"), stopped("b"))}
+	q := &queue{limit: 1, path: path}
+	q.prioritize([]string{"b"})
+	q.enforce(f)
+
+	var reloaded queue
+	reloaded.load(path)
+	assertOrder(t, reloaded.prioritized, "b")
+}
+
+func TestIsQueuePolicy(t *testing.T) {
+	for _, p := range []string{"fifo", "smallest_first", "airing_first", "fair_share"} {
+		if !IsQueuePolicy(p) {
+			t.Errorf("IsQueuePolicy(%q) = false", p)
+		}
+	}
+	if IsQueuePolicy("") || IsQueuePolicy("random") {
+		t.Error("IsQueuePolicy accepted an unknown policy")
+	}
+}
+
+// The remote clients report no pieces outside a recheck, and keep the downloaded bytes across
+// a pause: smallest_first has to rank them by those bytes, or it falls back to add order.
+func TestQueueSmallestFirstOnRemoteBackend(t *testing.T) {
+	f := newFakeQBit(t)
+	b := newQBitBackend(t, f)
+	if _, err := b.Ensure(t.TempDir()); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	b.SetMaxActiveDownloads(1)
+
+	magnets := []string{
+		"magnet:?xt=urn:btih:1111111111111111111111111111111111111111&dn=pack",
+		"magnet:?xt=urn:btih:2222222222222222222222222222222222222222&dn=ep",
+		"magnet:?xt=urn:btih:3333333333333333333333333333333333333333&dn=half",
+	}
+	var hashes []string
+	for _, m := range magnets {
+		hash, err := b.Add(m)
+		if err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	pack, ep, half := hashes[0], hashes[1], hashes[2]
+	f.set(pack, func(t *fakeQBitTorrent) { t.Size = 60 << 30 })
+	f.set(ep, func(t *fakeQBitTorrent) { t.Size = 300 << 20 })
+	f.set(half, func(t *fakeQBitTorrent) { t.Size = 400 << 20; t.Completed = 300 << 20 }) // 100 MiB left
+
+	b.SetQueuePolicy(QueueSmallestFirst)
+
+	if got := f.get(half).State; got != "downloading" {
+		t.Errorf("half = %s, want downloading (the least left to download)", got)
+	}
+	for _, h := range []string{pack, ep} {
+		if got := f.get(h).State; got == "downloading" {
+			t.Errorf("%s is downloading, want only the smallest remaining running", h)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package torrents
+
+import (
+	"fmt"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/logger"
+
+	"github.com/cenkalti/rain/v2/torrent"
+)
+
+// RecheckResult is the outcome of re-verifying a torrent's data against its piece hashes.
+type RecheckResult struct {
+	PiecesTotal uint32
+	// PiecesHave is how many pieces passed verification.
+	PiecesHave uint32
+	// PiecesFailed is how many pieces the torrent had before the recheck and no longer has —
+	// the ones whose bytes on disk did not match their hash. They are downloaded again.
+	PiecesFailed uint32
+	// Completed reports whether every piece passed, i.e. the torrent is still seedable as is.
+	Completed bool
+}
+
+// recheckPollInterval is how often the end of a verification is polled. rain has no "verify
+// done" notification: the torrent just ends up stopped.
+const recheckPollInterval = 250 * time.Millisecond
+
+// recheckTimeout bounds the wait. Verification reads every byte of the torrent, so a 100 GB
+// batch on a slow disk takes many minutes; the bound only exists so a torrent that never
+// stops cannot hang the caller forever.
+const recheckTimeout = 2 * time.Hour
+
+// beginRecheck starts a verification. It must run under the manager's read lock (it reads the
+// session's torrent map); the wait in awaitRecheck must not, since it can take minutes.
+//
+// rain's Verify drops the stored bitfield, stops the torrent, hashes every piece on disk and
+// leaves the torrent STOPPED — restarting it is the caller's call (see SessionManager.Recheck).
+func (s *Session) beginRecheck(hash string) (*torrent.Torrent, error) {
+	t := s.ses.GetTorrent(hash)
+	if t == nil {
+		return nil, fmt.Errorf("torrent %s not found", hash)
+	}
+	if t.Stats().Pieces.Total == 0 {
+		return nil, fmt.Errorf("torrent %s has no metadata yet; there is nothing to verify", hash)
+	}
+	if err := t.Verify(); err != nil {
+		return nil, fmt.Errorf("failed to start verification of torrent %s: %w", hash, err)
+	}
+	logger.Logger.Info().Str("hash", hash).Msg("Started torrent data verification")
+	return t, nil
+}
+
+// awaitRecheck waits for the verification started by beginRecheck and compares the pieces
+// with haveBefore. It only touches t, never the session, so it is safe without the manager's
+// lock: a torrent closed in the meantime (removed, or the session recreated) is detected
+// through NotifyClose instead of read as a zero Stats — which would report every piece lost.
+//
+// The first Stats after Verify already sees the verification underway: Verify hands the
+// command to the torrent's goroutine over an unbuffered channel, and Stats is answered by that
+// same goroutine, after the command.
+func awaitRecheck(t *torrent.Torrent, haveBefore uint32) (RecheckResult, error) {
+	hash := t.InfoHash().String()
+	deadline := time.Now().Add(recheckTimeout)
+	for {
+		st := t.Stats()
+		select {
+		case <-t.NotifyClose():
+			return RecheckResult{}, fmt.Errorf("torrent %s was removed during verification", hash)
+		default:
+		}
+		if st.Status == torrent.Stopped {
+			if st.Error != nil {
+				return RecheckResult{}, fmt.Errorf("verification of torrent %s failed: %w", hash, st.Error)
+			}
+			res := RecheckResult{
+				PiecesTotal: st.Pieces.Total,
+				PiecesHave:  st.Pieces.Have,
+				Completed:   completedFromStats(st),
+			}
+			if haveBefore > st.Pieces.Have {
+				res.PiecesFailed = haveBefore - st.Pieces.Have
+			}
+			logger.Logger.Info().Str("hash", hash).
+				Uint32("pieces_failed", res.PiecesFailed).
+				Uint32("pieces_have", res.PiecesHave).
+				Uint32("pieces_total", res.PiecesTotal).
+				Msg("Torrent data verification finished")
+			return res, nil
+		}
+		if time.Now().After(deadline) {
+			return RecheckResult{}, fmt.Errorf("verification of torrent %s did not finish within %s", hash, recheckTimeout)
+		}
+		time.Sleep(recheckPollInterval)
+	}
+}
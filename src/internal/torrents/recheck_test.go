@@ -0,0 +1,126 @@
+package torrents
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/rain/v2/torrent"
+)
+
+const testPieceLength = 16 * 1024
+
+// addSeedingTorrent writes a 3-piece file into the session's data dir and adds a .torrent
+// for it, so the torrent verifies its data at start and ends up seeding — a real torrent with
+// metadata, which a magnet without peers never reaches. Returns the hash and the file path.
+func addSeedingTorrent(t *testing.T, m *SessionManager, savePath string) (string, string) {
+	t.Helper()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 3*testPieceLength/16)
+	var pieces []byte
+	for off := 0; off < len(data); off += testPieceLength {
+		sum := sha1.Sum(data[off : off+testPieceLength])
+		pieces = append(pieces, sum[:]...)
+	}
+	// Bencoded by hand: rain's metainfo package is internal. Keys in sorted order.
+	info := fmt.Sprintf("d6:lengthi%de4:name8:ep01.mkv12:piece lengthi%de6:pieces%d:%se",
+		len(data), testPieceLength, len(pieces), pieces)
+	infoHash := sha1.Sum([]byte(info))
+	hash := hex.EncodeToString(infoHash[:])
+
+	dir := filepath.Join(savePath, hash)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "ep01.mkv")
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m.mu.RLock()
+	_, err := m.session.ses.AddTorrent(bytes.NewReader([]byte("d4:info"+info+"e")), &torrent.AddTorrentOptions{ID: hash})
+	m.mu.RUnlock()
+	if err != nil {
+		t.Fatalf("AddTorrent: %v", err)
+	}
+	waitForStatus(t, m, hash, "seeding")
+	return hash, file
+}
+
+func waitForStatus(t *testing.T, m *SessionManager, hash, want string) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if info, ok := m.Get(hash); ok && info.Status == want {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	info, _ := m.Get(hash)
+	t.Fatalf("torrent never reached %q (last status %q)", want, info.Status)
+}
+
+func TestSessionManagerRecheckCleanTorrentKeepsSeeding(t *testing.T) {
+	m, savePath, _ := newTestManager(t)
+	if _, err := m.Ensure(savePath); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	hash, _ := addSeedingTorrent(t, m, savePath)
+
+	res, err := m.Recheck(hash)
+	if err != nil {
+		t.Fatalf("Recheck: %v", err)
+	}
+	if res.PiecesFailed != 0 || !res.Completed || res.PiecesHave != 3 {
+		t.Errorf("Recheck() = %+v, want 3/3 pieces and none failed", res)
+	}
+	waitForStatus(t, m, hash, "seeding")
+}
+
+// A byte changed on disk fails its piece: the torrent is incomplete again and goes to the
+// front of the queue to download it back.
+func TestSessionManagerRecheckDetectsCorruptedPiece(t *testing.T) {
+	m, savePath, _ := newTestManager(t)
+	if _, err := m.Ensure(savePath); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	hash, file := addSeedingTorrent(t, m, savePath)
+
+	f, err := os.OpenFile(file, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("X"), testPieceLength+10); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	res, err := m.Recheck(hash)
+	if err != nil {
+		t.Fatalf("Recheck: %v", err)
+	}
+	if res.PiecesFailed != 1 || res.Completed || res.PiecesHave != 2 {
+		t.Errorf("Recheck() = %+v, want 1 failed piece and 2/3 left", res)
+	}
+	m.queue.mu.Lock()
+	order := append([]string(nil), m.queue.order...)
+	m.queue.mu.Unlock()
+	if len(order) == 0 || order[0] != hash {
+		t.Errorf("queue order = %v, want the rechecked torrent first", order)
+	}
+	waitForStatus(t, m, hash, "downloading")
+}
+
+func TestSessionManagerRecheckUnknownHash(t *testing.T) {
+	m, savePath, _ := newTestManager(t)
+	if _, err := m.Ensure(savePath); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	if _, err := m.Recheck("ffffffffffffffffffffffffffffffffffffffff"); err == nil {
+		t.Error("Recheck of an unknown hash returned no error")
+	}
+}
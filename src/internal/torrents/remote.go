@@ -0,0 +1,712 @@
+package torrents
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"AutoAnimeDownloader/src/internal/logger"
+)
+
+// Torrent clients the daemon can drive (Config.TorrentClient). The embedded one is the rain
+// session behind SessionManager; the other two are reached over HTTP by RemoteBackend.
+const (
+	ClientEmbedded     = "embedded"
+	ClientQBittorrent  = "qbittorrent"
+	ClientTransmission = "transmission"
+)
+
+// DefaultCategory is the category (qBittorrent) or label (Transmission) used when none is
+// configured.
+const DefaultCategory = "autoanimedownloader"
+
+// IsTorrentClient reports whether s is one of the supported clients.
+func IsTorrentClient(s string) bool {
+	switch s {
+	case ClientEmbedded, ClientQBittorrent, ClientTransmission:
+		return true
+	}
+	return false
+}
+
+// IsRemoteClient reports whether s names a client RemoteBackend talks to.
+func IsRemoteClient(s string) bool {
+	return s == ClientQBittorrent || s == ClientTransmission
+}
+
+// remotePollInterval is how often RemoteBackend polls the client for completions and
+// failures. An external client has no event stream the way rain has NotifyComplete, so
+// this is also the delay between a download finishing and its JobOrganize.
+const remotePollInterval = 5 * time.Second
+
+// remoteRecheckGrace is how long a recheck may take to show up as "verifying". Both clients
+// queue the check and answer the request first, so the first snapshots after it can still
+// show the previous state.
+const remoteRecheckGrace = 10 * time.Second
+
+// RemoteOptions configures a RemoteBackend.
+type RemoteOptions struct {
+	// Client is ClientQBittorrent or ClientTransmission.
+	Client string
+	// URL is the WebUI root for qBittorrent (http://host:8080) and the RPC endpoint for
+	// Transmission (http://host:9091/transmission/rpc).
+	URL      string
+	Username string
+	Password string
+	// Category is the qBittorrent category or the Transmission label every torrent the daemon
+	// adds gets. It is also the boundary of what the daemon manages: List only returns torrents
+	// carrying it, so the queue never pauses a torrent the user added to the client by hand.
+	Category string
+	// RemoteSavePath is the download folder as the CLIENT sees it, when that differs from the
+	// path the daemon uses (the client in another container, or on the seedbox host with the
+	// folder mounted here). Content paths the client reports under it are rewritten to the
+	// daemon's save path before anyone hardlinks from them. Empty = both see the same path.
+	RemoteSavePath string
+}
+
+// remoteTorrent is one torrent as a client reports it: the snapshot in the backend-agnostic
+// shape, with DataDir still in the client's view, plus the client's error for it, if any.
+type remoteTorrent struct {
+	info TorrentInfo
+	// failure is a local error that stopped the torrent (disk full, files missing). Tracker
+	// errors are not failures: the torrent keeps going with DHT and the other trackers.
+	failure string
+}
+
+// remoteClient is the API of one external client, reduced to what RemoteBackend needs. Every
+// call is a round-trip; implementations log in again on their own when the session expires.
+type remoteClient interface {
+	// connect logs in (or does the session handshake) and prepares the category.
+	connect() error
+	// add hands a magnet to the client, downloading into savePath (the client's view) under
+	// the category.
+	add(magnet, savePath string) error
+	// torrents lists the torrents in the category; with hashes, only those.
+	torrents(hashes ...string) ([]remoteTorrent, error)
+	remove(hash string, deleteData bool) error
+	stop(hash string) error
+	start(hash string) error
+	reannounce(hash string) error
+	trackers(hash string) ([]TrackerInfo, error)
+	addTrackers(hash string, urls []string) error
+	recheck(hash string) error
+	// pieces reports how many pieces are verified; total 0 means no metadata yet.
+	pieces(hash string) (have, total uint32, err error)
+}
+
+// RemoteBackend implements TorrentBackend over an external client — qBittorrent's WebUI API
+// or Transmission's RPC. The queue, the pause-all and the data cap are the same ones
+// SessionManager uses: they only need list/pause/resume, so the client's own queueing is left
+// to whatever the user configured, and the daemon's runs on top of it within the category.
+//
+// There is no session to create, so Ensure only checks that the client answers and records
+// the save path. There are no download-root markers either: the swap they detect is a rain
+// file-descriptor problem (decisions.md #34), and an external client reports the real path of
+// every torrent on each call.
+type RemoteBackend struct {
+	client remoteClient
+	opts   RemoteOptions
+
+	mu sync.RWMutex
+	// savePath is the daemon's view of the download folder, from the last Ensure. Empty until
+	// the client first answered.
+	savePath      string
+	onComplete    func(hash string)
+	onFailed      func(hash string, err error)
+	extraTrackers []string
+	resumeTimer   *time.Timer
+	// seen is what the poller knew about each torrent at the previous poll; nil before the
+	// first one. See poll.
+	seen     map[string]remoteSeen
+	stopPoll chan struct{}
+
+	queue queue
+	// pollInterval is remotePollInterval outside the tests.
+	pollInterval time.Duration
+}
+
+type remoteSeen struct {
+	completed bool
+	failed    bool
+}
+
+// ErrUnknownTorrentClient is returned by NewRemoteBackend for a client it does not speak.
+var ErrUnknownTorrentClient = errors.New("unknown torrent client")
+
+var _ queueOps = (*RemoteBackend)(nil)
+
+var _ TorrentBackend = (*RemoteBackend)(nil)
+
+// remoteQueueFileName is the download queue of a RemoteBackend. It is its own file, not the
+// embedded client's queue.json, so switching clients back and forth does not prune one queue
+// against the other client's torrents.
+const remoteQueueFileName = "remote_queue.json"
+
+// NewRemoteBackend creates a backend for opts.Client. stateDir is the folder the queue file
+// lives in — the config folder, where SessionManager keeps queue.json. Nothing is contacted
+// until Ensure.
+func NewRemoteBackend(opts RemoteOptions, stateDir string) (*RemoteBackend, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("torrent client URL must be an http(s) URL, got %q", opts.URL)
+	}
+	var client remoteClient
+	switch opts.Client {
+	case ClientQBittorrent:
+		client = newQBittorrentClient(opts)
+	case ClientTransmission:
+		client = newTransmissionClient(opts)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTorrentClient, opts.Client)
+	}
+	return newRemoteBackend(client, opts, filepath.Join(stateDir, remoteQueueFileName)), nil
+}
+
+func newRemoteBackend(client remoteClient, opts RemoteOptions, queuePath string) *RemoteBackend {
+	b := &RemoteBackend{client: client, opts: opts, pollInterval: remotePollInterval}
+	b.queue.load(queuePath)
+	if paused, until := b.queue.pauseState(); paused {
+		b.armResumeTimer(until)
+	}
+	return b
+}
+
+// Ensure checks that the client answers and records savePath, the folder Add downloads into.
+// It reports true the first time — the caller's startup reconciliation runs then — and starts
+// the poller that drives the SetCallbacks handlers.
+func (b *RemoteBackend) Ensure(savePath string) (bool, error) {
+	if savePath == "" {
+		return false, ErrSessionNotReady
+	}
+	b.mu.Lock()
+	if b.savePath != "" {
+		b.savePath = savePath
+		b.mu.Unlock()
+		return false, nil
+	}
+	b.mu.Unlock()
+
+	// Fora do lock: e um round-trip, e um cliente fora do ar segura ate o timeout.
+	if err := b.client.connect(); err != nil {
+		return false, fmt.Errorf("failed to connect to %s at %s: %w", b.opts.Client, b.opts.URL, err)
+	}
+
+	b.mu.Lock()
+	created := b.savePath == ""
+	b.savePath = savePath
+	if created && b.stopPoll == nil {
+		b.stopPoll = make(chan struct{})
+		go b.pollLoop(b.stopPoll)
+	}
+	b.mu.Unlock()
+
+	if created {
+		logger.Logger.Info().Str("client", b.opts.Client).Str("url", b.opts.URL).Str("category", b.opts.Category).
+			Msg("Connected to the external torrent client")
+		b.queue.enforce(b)
+	}
+	return created, nil
+}
+
+// ConsumeRootSwap is always false: see the type docs.
+func (b *RemoteBackend) ConsumeRootSwap() bool {
+	return false
+}
+
+func (b *RemoteBackend) SetCallbacks(onComplete func(hash string), onFailed func(hash string, err error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onComplete = onComplete
+	b.onFailed = onFailed
+}
+
+// remoteSavePath is where the client must save, in its own view of the filesystem.
+func (b *RemoteBackend) remoteSavePath(savePath string) string {
+	if b.opts.RemoteSavePath != "" {
+		return b.opts.RemoteSavePath
+	}
+	return savePath
+}
+
+// localPath rewrites a path the client reported into the daemon's view: the part under
+// RemoteSavePath moves under savePath. Anything else is returned as is — a torrent the user
+// moved elsewhere in the client is still hardlinked from where it really is, if the daemon
+// can see it.
+func (b *RemoteBackend) localPath(p, savePath string) string {
+	root := strings.TrimRight(b.opts.RemoteSavePath, `/\`)
+	if root == "" || savePath == "" {
+		return p
+	}
+	if p == root {
+		return savePath
+	}
+	for _, sep := range []string{"/", `\`} {
+		if rest, ok := strings.CutPrefix(p, root+sep); ok {
+			return filepath.Join(savePath, filepath.FromSlash(strings.ReplaceAll(rest, `\`, "/")))
+		}
+	}
+	return p
+}
+
+func (b *RemoteBackend) Add(magnet string) (string, error) {
+	hash, err := parseInfoHash(magnet)
+	if err != nil {
+		return "", fmt.Errorf("invalid magnet: %w", err)
+	}
+	b.mu.RLock()
+	savePath, trackers := b.savePath, b.extraTrackers
+	b.mu.RUnlock()
+	if savePath == "" {
+		return "", ErrSessionNotReady
+	}
+
+	// Mesmo contrato da Session: adicionar o que ja esta no cliente devolve o hash existente.
+	// Os dois clientes recusam a duplicata, cada um com um erro diferente.
+	present, err := b.client.torrents(hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to query %s: %w", b.opts.Client, err)
+	}
+	if len(present) == 0 {
+		if err := b.client.add(WithTrackers(magnet, trackers), b.remoteSavePath(savePath)); err != nil {
+			return "", fmt.Errorf("failed to add torrent to %s: %w", b.opts.Client, err)
+		}
+		logger.Logger.Info().Str("hash", hash).Str("client", b.opts.Client).Msg("Added torrent to external client")
+	}
+	b.queue.enforce(b)
+	return hash, nil
+}
+
+func (b *RemoteBackend) List() []TorrentInfo {
+	infos := b.list()
+	if infos == nil {
+		return nil
+	}
+	return b.queue.markQueued(infos)
+}
+
+// list is the raw snapshot, like SessionManager.list. nil = "do not know": before the first
+// Ensure and whenever the client does not answer. An empty, non-nil slice is a client that
+// answered with nothing in the category — the distinction the queue's step 0 depends on.
+func (b *RemoteBackend) list() []TorrentInfo {
+	infos, err := b.fetch()
+	if err != nil {
+		logger.Logger.Warn().Err(err).Str("client", b.opts.Client).Msg("Failed to list torrents from the external client")
+		return nil
+	}
+	return infos
+}
+
+func (b *RemoteBackend) fetch(hashes ...string) ([]TorrentInfo, error) {
+	ts, err := b.fetchRaw(hashes...)
+	if ts == nil {
+		return nil, err
+	}
+	infos := make([]TorrentInfo, 0, len(ts))
+	for _, t := range ts {
+		infos = append(infos, t.info)
+	}
+	return infos, nil
+}
+
+// fetchRaw lists through the client and maps every DataDir to the daemon's view. (nil, nil)
+// before the first Ensure.
+func (b *RemoteBackend) fetchRaw(hashes ...string) ([]remoteTorrent, error) {
+	b.mu.RLock()
+	savePath := b.savePath
+	b.mu.RUnlock()
+	if savePath == "" {
+		return nil, nil
+	}
+	ts, err := b.client.torrents(hashes...)
+	if err != nil {
+		return nil, err
+	}
+	if ts == nil {
+		ts = []remoteTorrent{}
+	}
+	for i := range ts {
+		ts[i].info.DataDir = b.localPath(ts[i].info.DataDir, savePath)
+	}
+	return ts, nil
+}
+
+func (b *RemoteBackend) Get(hash string) (TorrentInfo, bool) {
+	infos, err := b.fetch(hash)
+	if err != nil {
+		logger.Logger.Warn().Err(err).Str("hash", hash).Str("client", b.opts.Client).Msg("Failed to query torrent from the external client")
+		return TorrentInfo{}, false
+	}
+	for _, t := range infos {
+		if t.Hash == hash {
+			return b.queue.markQueued([]TorrentInfo{t})[0], true
+		}
+	}
+	return TorrentInfo{}, false
+}
+
+func (b *RemoteBackend) connectedOrErr() error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.savePath == "" {
+		return ErrSessionNotReady
+	}
+	return nil
+}
+
+func (b *RemoteBackend) Remove(hash string, keepData bool) error {
+	if err := b.connectedOrErr(); err != nil {
+		return err
+	}
+	if err := b.client.remove(hash, !keepData); err != nil {
+		return fmt.Errorf("failed to remove torrent %s: %w", hash, err)
+	}
+	b.mu.Lock()
+	delete(b.seen, hash)
+	b.mu.Unlock()
+	b.queue.drop(hash)
+	b.queue.enforce(b)
+	return nil
+}
+
+// pause/resume are the raw delegations the queue drives; same rule as SessionManager's.
+func (b *RemoteBackend) pause(hash string) error {
+	if err := b.connectedOrErr(); err != nil {
+		return err
+	}
+	if err := b.client.stop(hash); err != nil {
+		return fmt.Errorf("failed to pause torrent %s: %w", hash, err)
+	}
+	return nil
+}
+
+func (b *RemoteBackend) resume(hash string) error {
+	if err := b.connectedOrErr(); err != nil {
+		return err
+	}
+	if err := b.client.start(hash); err != nil {
+		return fmt.Errorf("failed to resume torrent %s: %w", hash, err)
+	}
+	return nil
+}
+
+func (b *RemoteBackend) Pause(hash string) error {
+	t, ok := b.Get(hash)
+	if !ok {
+		return fmt.Errorf("torrent %s not found", hash)
+	}
+	if t.Completed {
+		return b.pause(hash)
+	}
+	b.queue.markPaused(hash)
+	if err := b.pause(hash); err != nil {
+		return err
+	}
+	b.queue.enforce(b)
+	return nil
+}
+
+func (b *RemoteBackend) Resume(hash string) error {
+	t, ok := b.Get(hash)
+	if !ok {
+		return fmt.Errorf("torrent %s not found", hash)
+	}
+	if t.Completed {
+		return b.resume(hash)
+	}
+	b.queue.pushBack(hash)
+	b.queue.enforce(b)
+	return nil
+}
+
+func (b *RemoteBackend) Prioritize(hash string) error {
+	t, ok := b.Get(hash)
+	if !ok {
+		return fmt.Errorf("torrent %s not found", hash)
+	}
+	if t.Completed {
+		return fmt.Errorf("torrent %s already completed", hash)
+	}
+	return b.PrioritizeAll([]string{hash})
+}
+
+func (b *RemoteBackend) PrioritizeAll(hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	b.queue.prioritize(hashes)
+	b.queue.enforce(b)
+	return nil
+}
+
+func (b *RemoteBackend) SetMaxActiveDownloads(n int) {
+	b.queue.setLimit(n)
+	b.queue.enforce(b)
+}
+
+func (b *RemoteBackend) SetQueuePolicy(policy QueuePolicy) {
+	b.queue.setPolicy(policy)
+	b.queue.enforce(b)
+}
+
+func (b *RemoteBackend) SetQueueHints(hints map[string]QueueHint) {
+	b.queue.setHints(hints)
+	b.queue.enforce(b)
+}
+
+func (b *RemoteBackend) PauseAll(d time.Duration) {
+	var until time.Time
+	if d > 0 {
+		until = time.Now().Add(d)
+	}
+	b.queue.pauseAll(until)
+	b.armResumeTimer(until)
+	b.queue.enforce(b)
+}
+
+func (b *RemoteBackend) ResumeAll() {
+	b.queue.resumeAll()
+	b.armResumeTimer(time.Time{})
+	b.queue.enforce(b)
+}
+
+func (b *RemoteBackend) PausedAll() (bool, time.Time) {
+	return b.queue.pauseState()
+}
+
+func (b *RemoteBackend) SetDataCapReached(reached bool) {
+	b.queue.setDataCapped(reached)
+	b.queue.enforce(b)
+}
+
+func (b *RemoteBackend) DataCapReached() bool {
+	b.queue.mu.Lock()
+	defer b.queue.mu.Unlock()
+	return b.queue.dataCapped
+}
+
+// armResumeTimer is SessionManager.armResumeTimer's twin.
+func (b *RemoteBackend) armResumeTimer(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.resumeTimer != nil {
+		b.resumeTimer.Stop()
+		b.resumeTimer = nil
+	}
+	if until.IsZero() {
+		return
+	}
+	b.resumeTimer = time.AfterFunc(time.Until(until), func() { b.queue.enforce(b) })
+}
+
+func (b *RemoteBackend) Announce(hash string) error {
+	if err := b.connectedOrErr(); err != nil {
+		return err
+	}
+	if err := b.client.reannounce(hash); err != nil {
+		return fmt.Errorf("failed to re-announce torrent %s: %w", hash, err)
+	}
+	logger.Logger.Info().Str("hash", hash).Msg("Forced torrent re-announce")
+	return nil
+}
+
+func (b *RemoteBackend) SetExtraTrackers(trackers []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.extraTrackers = append([]string(nil), trackers...)
+}
+
+// AddExtraTrackers dedupes against the torrent's trackers itself, like Session.AddTrackers:
+// neither client promises to ignore a tracker it already has.
+func (b *RemoteBackend) AddExtraTrackers(hash string) (int, error) {
+	if err := b.connectedOrErr(); err != nil {
+		return 0, err
+	}
+	b.mu.RLock()
+	extra := b.extraTrackers
+	b.mu.RUnlock()
+
+	current, err := b.client.trackers(hash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read trackers of torrent %s: %w", hash, err)
+	}
+	present := make(map[string]bool, len(current))
+	for _, tr := range current {
+		present[tr.URL] = true
+	}
+	var missing []string
+	for _, u := range extra {
+		if !present[u] {
+			missing = append(missing, u)
+			present[u] = true
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+	if err := b.client.addTrackers(hash, missing); err != nil {
+		return 0, fmt.Errorf("failed to add trackers to torrent %s: %w", hash, err)
+	}
+	if err := b.client.reannounce(hash); err != nil {
+		logger.Logger.Warn().Err(err).Str("hash", hash).Msg("Failed to re-announce after adding trackers")
+	}
+	logger.Logger.Info().Str("hash", hash).Int("added", len(missing)).Msg("Added extra trackers to torrent")
+	return len(missing), nil
+}
+
+func (b *RemoteBackend) Trackers(hash string) ([]TrackerInfo, error) {
+	if err := b.connectedOrErr(); err != nil {
+		return nil, err
+	}
+	return b.client.trackers(hash)
+}
+
+// Recheck asks the client to verify the torrent and polls until it stops verifying. Unlike
+// rain, both clients put the torrent back in the state it was in before the check, so the
+// only follow-up is the one SessionManager.Recheck also does for a seeder that lost pieces.
+func (b *RemoteBackend) Recheck(hash string) (RecheckResult, error) {
+	before, ok := b.Get(hash)
+	if !ok {
+		return RecheckResult{}, fmt.Errorf("torrent %s not found", hash)
+	}
+	haveBefore, total, err := b.client.pieces(hash)
+	if err != nil {
+		return RecheckResult{}, fmt.Errorf("failed to read pieces of torrent %s: %w", hash, err)
+	}
+	if total == 0 {
+		return RecheckResult{}, fmt.Errorf("torrent %s has no metadata yet; there is nothing to verify", hash)
+	}
+	if err := b.client.recheck(hash); err != nil {
+		return RecheckResult{}, fmt.Errorf("failed to start verification of torrent %s: %w", hash, err)
+	}
+	logger.Logger.Info().Str("hash", hash).Msg("Started torrent data verification")
+
+	start := time.Now()
+	deadline := start.Add(recheckTimeout)
+	seenVerifying := false
+	for {
+		t, ok := b.Get(hash)
+		if !ok {
+			return RecheckResult{}, fmt.Errorf("torrent %s was removed during verification", hash)
+		}
+		verifying := t.Status == "verifying"
+		seenVerifying = seenVerifying || verifying
+		if !verifying && (seenVerifying || time.Since(start) > remoteRecheckGrace) {
+			break
+		}
+		if time.Now().After(deadline) {
+			return RecheckResult{}, fmt.Errorf("verification of torrent %s did not finish within %s", hash, recheckTimeout)
+		}
+		time.Sleep(recheckPollInterval)
+	}
+
+	have, total, err := b.client.pieces(hash)
+	if err != nil {
+		return RecheckResult{}, fmt.Errorf("failed to read pieces of torrent %s: %w", hash, err)
+	}
+	res := RecheckResult{PiecesTotal: total, PiecesHave: have, Completed: total > 0 && have >= total}
+	if haveBefore > have {
+		res.PiecesFailed = haveBefore - have
+	}
+	logger.Logger.Info().Str("hash", hash).
+		Uint32("pieces_failed", res.PiecesFailed).
+		Uint32("pieces_have", res.PiecesHave).
+		Uint32("pieces_total", res.PiecesTotal).
+		Msg("Torrent data verification finished")
+
+	if before.Completed && !res.Completed {
+		b.queue.prioritize([]string{hash})
+	}
+	b.queue.enforce(b)
+	return res, nil
+}
+
+// pollLoop polls until stop is closed.
+func (b *RemoteBackend) pollLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		b.poll()
+	}
+}
+
+// poll is the external clients' replacement for rain's per-torrent listeners: it diffs the
+// snapshot against the previous one and fires the SetCallbacks handlers.
+//
+// A completion fires when a torrent is complete now and was incomplete — or unknown — at the
+// previous poll, which covers a small torrent added and finished between two polls. The FIRST
+// poll only records: torrents that completed while the daemon was down are the startup
+// reconciliation's job, the same rule SessionManager follows for rain's resume data. A failure
+// fires once per torrent, on the first poll too: nothing else would ever notice a torrent the
+// client stopped with an error while the daemon was down.
+func (b *RemoteBackend) poll() {
+	ts, err := b.fetchRaw()
+	if ts == nil {
+		if err != nil {
+			logger.Logger.Debug().Err(err).Str("client", b.opts.Client).Msg("External client poll failed")
+		}
+		return
+	}
+
+	type failure struct{ hash, reason string }
+	var completed []string
+	var failed []failure
+
+	b.mu.Lock()
+	first := b.seen == nil
+	next := make(map[string]remoteSeen, len(ts))
+	for _, t := range ts {
+		h := t.info.Hash
+		prev, known := b.seen[h]
+		cur := remoteSeen{completed: t.info.Completed, failed: t.failure != ""}
+		next[h] = cur
+		if cur.completed && !first && (!known || !prev.completed) {
+			completed = append(completed, h)
+		}
+		if cur.failed && !prev.failed {
+			failed = append(failed, failure{h, t.failure})
+		}
+	}
+	b.seen = next
+	onComplete, onFailed := b.onComplete, b.onFailed
+	b.mu.Unlock()
+
+	// Fora do lock: o enforce pega queue.mu, que vem antes de b.mu.
+	if len(completed) > 0 {
+		b.queue.enforce(b)
+	}
+	if onComplete != nil {
+		for _, h := range completed {
+			onComplete(h)
+		}
+	}
+	if onFailed != nil {
+		for _, f := range failed {
+			onFailed(f.hash, errors.New(f.reason))
+		}
+	}
+}
+
+// Close stops the poller and the pause-all timer. The client itself keeps running — and
+// seeding — without the daemon.
+func (b *RemoteBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopPoll != nil {
+		close(b.stopPoll)
+		b.stopPoll = nil
+	}
+	if b.resumeTimer != nil {
+		b.resumeTimer.Stop()
+		b.resumeTimer = nil
+	}
+	return nil
+}
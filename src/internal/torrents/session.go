@@ -154,6 +154,51 @@ func (s *Session) Announce(hash string) error {
 	return nil
 }
 
+// AddTrackers adds every tracker in urls the torrent does not have yet and returns how many
+// were added. rain's AddTracker does not deduplicate (it appends to the persisted list), so
+// the check against Trackers() is what keeps repeated calls from stacking copies.
+func (s *Session) AddTrackers(hash string, urls []string) (int, error) {
+	t := s.ses.GetTorrent(hash)
+	if t == nil {
+		return 0, fmt.Errorf("torrent %s not found", hash)
+	}
+	present := make(map[string]bool)
+	for _, tr := range t.Trackers() {
+		present[tr.URL] = true
+	}
+	added := 0
+	for _, u := range urls {
+		if present[u] {
+			continue
+		}
+		if err := t.AddTracker(u); err != nil {
+			return added, fmt.Errorf("failed to add tracker %s to torrent %s: %w", u, hash, err)
+		}
+		present[u] = true
+		added++
+	}
+	if added > 0 {
+		// Without it the new trackers wait for the next scheduled announce, which for a
+		// torrent stuck at 0 peers is exactly the wait the user is trying to skip.
+		t.Announce()
+		logger.Logger.Info().Str("hash", hash).Int("added", added).Msg("Added extra trackers to torrent")
+	}
+	return added, nil
+}
+
+func (s *Session) Trackers(hash string) ([]TrackerInfo, error) {
+	t := s.ses.GetTorrent(hash)
+	if t == nil {
+		return nil, fmt.Errorf("torrent %s not found", hash)
+	}
+	trs := t.Trackers()
+	out := make([]TrackerInfo, 0, len(trs))
+	for _, tr := range trs {
+		out = append(out, toTrackerInfo(tr))
+	}
+	return out, nil
+}
+
 func (s *Session) SetCallbacks(onComplete func(hash string), onFailed func(hash string, err error)) {
 	s.mu.Lock()
 	s.onComplete = onComplete
@@ -234,6 +279,7 @@ func toInfo(t *torrent.Torrent) TorrentInfo {
 		Status:           statusSlug(st.Status),
 		BytesCompleted:   st.Bytes.Completed,
 		BytesTotal:       st.Bytes.Total,
+		BytesDownloaded:  st.Bytes.Downloaded,
 		BytesUploaded:    st.Bytes.Uploaded,
 		DownloadSpeed:    st.Speed.Download,
 		UploadSpeed:      st.Speed.Upload,
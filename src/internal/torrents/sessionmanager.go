@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"AutoAnimeDownloader/src/internal/logger"
 )
@@ -43,6 +44,13 @@ type SessionManager struct {
 	// queue caps how many incomplete torrents run at once. It lives on the manager, not on
 	// the Session, so it survives the session being torn down and rebuilt by Ensure.
 	queue queue
+	// extraTrackers is appended to every magnet Add receives. Same reason as queue for living
+	// here: the config pushes it once, and a rebuilt session must not lose it.
+	extraTrackers []string
+	// resumeTimer fires a timed PauseAll's expiry. It only runs an enforce: the queue itself
+	// notices the deadline passed, so a timer lost to a restart costs nothing but precision —
+	// NewSessionManager re-arms it from queue.json.
+	resumeTimer *time.Timer
 }
 
 // queue.mu is taken BEFORE m.mu (queue.enforce calls List/pause/resume). Every exported
@@ -63,6 +71,9 @@ func NewSessionManager(dbPath string) *SessionManager {
 	// queue.json fica ao lado do banco de resume pelo mesmo motivo do download_root.id: e
 	// estado do torrent client, e precisa acompanhar o banco.
 	m.queue.load(filepath.Join(filepath.Dir(dbPath), queueFileName))
+	if paused, until := m.queue.pauseState(); paused {
+		m.armResumeTimer(until)
+	}
 	return m
 }
 
@@ -212,7 +223,7 @@ func (m *SessionManager) Add(magnet string) (string, error) {
 		if m.session == nil {
 			return "", ErrSessionNotReady
 		}
-		return m.session.Add(magnet)
+		return m.session.Add(WithTrackers(magnet, m.extraTrackers))
 	}()
 	if err != nil {
 		return "", err
@@ -359,6 +370,65 @@ func (m *SessionManager) SetMaxActiveDownloads(n int) {
 	m.queue.enforce(m)
 }
 
+func (m *SessionManager) SetQueuePolicy(policy QueuePolicy) {
+	m.queue.setPolicy(policy)
+	m.queue.enforce(m)
+}
+
+func (m *SessionManager) SetQueueHints(hints map[string]QueueHint) {
+	m.queue.setHints(hints)
+	m.queue.enforce(m)
+}
+
+// PauseAll holds every torrent — downloads and seeding alike — until ResumeAll, or for d when
+// d > 0. The torrents the user had paused one by one are left out of the bookkeeping, so
+// ResumeAll does not start them.
+func (m *SessionManager) PauseAll(d time.Duration) {
+	var until time.Time
+	if d > 0 {
+		until = time.Now().Add(d)
+	}
+	m.queue.pauseAll(until)
+	m.armResumeTimer(until)
+	m.queue.enforce(m)
+}
+
+func (m *SessionManager) ResumeAll() {
+	m.queue.resumeAll()
+	m.armResumeTimer(time.Time{})
+	m.queue.enforce(m)
+}
+
+func (m *SessionManager) PausedAll() (bool, time.Time) {
+	return m.queue.pauseState()
+}
+
+func (m *SessionManager) SetDataCapReached(reached bool) {
+	m.queue.setDataCapped(reached)
+	m.queue.enforce(m)
+}
+
+func (m *SessionManager) DataCapReached() bool {
+	m.queue.mu.Lock()
+	defer m.queue.mu.Unlock()
+	return m.queue.dataCapped
+}
+
+// armResumeTimer replaces the pending expiry timer; a zero until just cancels it. Takes m.mu
+// on its own, never with queue.mu held.
+func (m *SessionManager) armResumeTimer(until time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.resumeTimer != nil {
+		m.resumeTimer.Stop()
+		m.resumeTimer = nil
+	}
+	if until.IsZero() {
+		return
+	}
+	m.resumeTimer = time.AfterFunc(time.Until(until), func() { m.queue.enforce(m) })
+}
+
 func (m *SessionManager) Announce(hash string) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -368,6 +438,85 @@ func (m *SessionManager) Announce(hash string) error {
 	return m.session.Announce(hash)
 }
 
+func (m *SessionManager) SetExtraTrackers(trackers []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.extraTrackers = append([]string(nil), trackers...)
+}
+
+func (m *SessionManager) AddExtraTrackers(hash string) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.session == nil {
+		return 0, ErrSessionNotReady
+	}
+	return m.session.AddTrackers(hash, m.extraTrackers)
+}
+
+func (m *SessionManager) Trackers(hash string) ([]TrackerInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.session == nil {
+		return nil, ErrSessionNotReady
+	}
+	return m.session.Trackers(hash)
+}
+
+func (m *SessionManager) Recheck(hash string) (RecheckResult, error) {
+	before, ok := m.Get(hash)
+	if !ok {
+		return RecheckResult{}, fmt.Errorf("torrent %s not found", hash)
+	}
+	// Read through Get, so after markQueued: a torrent the queue is holding reads "queued",
+	// and "stopped" is left meaning paused by the user.
+	userStopped := before.Status == StatusStopped || before.Status == StatusStopping
+
+	// Only the start runs under the lock. The wait can take minutes, and holding the read lock
+	// through it would park every Ensure — and, behind that queued writer, every List.
+	m.mu.RLock()
+	if m.session == nil {
+		m.mu.RUnlock()
+		return RecheckResult{}, ErrSessionNotReady
+	}
+	t, err := m.session.beginRecheck(hash)
+	m.mu.RUnlock()
+	if err != nil {
+		return RecheckResult{}, err
+	}
+
+	res, err := awaitRecheck(t, before.PiecesHave)
+	if err != nil {
+		return RecheckResult{}, err
+	}
+
+	// rain leaves the torrent stopped after a verification; put it back where it was.
+	switch {
+	case !before.Completed:
+		// Still downloading: it has a place in the queue, and enforce restarts it if that
+		// place has a slot (a paused one stays in `paused`).
+		m.queue.enforce(m)
+	case userStopped:
+		if !res.Completed {
+			// Without this, enforce's step 2 would pick the now-incomplete torrent up as new
+			// and start it, undoing the user's pause.
+			m.queue.markPaused(hash)
+			m.queue.enforce(m)
+		}
+	case res.Completed:
+		if err := m.resume(hash); err != nil {
+			return res, err
+		}
+		// Under a pause-all the seeder must stop again right away, not at the next pass.
+		m.queue.enforce(m)
+	default:
+		// A damaged episode the user already had is repaired ahead of the ones still waiting,
+		// and the repair is only the failed pieces — usually a few MB.
+		m.queue.prioritize([]string{hash})
+		m.queue.enforce(m)
+	}
+	return res, nil
+}
+
 func (m *SessionManager) Close() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
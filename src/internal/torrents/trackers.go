@@ -0,0 +1,129 @@
+package torrents
+
+import (
+	"bufio"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/rain/v2/torrent"
+)
+
+// TrackerInfo is one tracker of a torrent as the announcer last saw it. It is what makes the
+// extra trackers observable: after adding them, the torrent detail shows whether each one
+// answered and how many seeders it reported — the figure that a dead Nyaa tracker leaves at 0.
+type TrackerInfo struct {
+	URL string
+	// Status is the API slug for rain's tracker status: not_contacted, contacting, working,
+	// not_working (or unknown).
+	Status   string
+	Seeders  int
+	Leechers int
+	// Error is the last announce error, "" when the last announce succeeded or none ran yet.
+	Error        string
+	LastAnnounce time.Time
+	NextAnnounce time.Time
+}
+
+// trackerStatusSlug maps rain's tracker status to a stable API slug, for the same reason as
+// statusSlug: rain's own strings are display text ("Not contacted yet").
+func trackerStatusSlug(s torrent.TrackerStatus) string {
+	switch s {
+	case torrent.NotContactedYet:
+		return "not_contacted"
+	case torrent.Contacting:
+		return "contacting"
+	case torrent.Working:
+		return "working"
+	case torrent.NotWorking:
+		return "not_working"
+	default:
+		return "unknown"
+	}
+}
+
+func toTrackerInfo(tr torrent.Tracker) TrackerInfo {
+	info := TrackerInfo{
+		URL:          tr.URL,
+		Status:       trackerStatusSlug(tr.Status),
+		Seeders:      tr.Seeders,
+		Leechers:     tr.Leechers,
+		LastAnnounce: tr.LastAnnounce,
+		NextAnnounce: tr.NextAnnounce,
+	}
+	if tr.Error != nil {
+		info.Error = tr.Error.Error()
+	}
+	return info
+}
+
+// IsTrackerURL reports whether s is an announce URL rain can use: udp, http or https, with a
+// host. Anything else (a wss:// WebTorrent tracker, a typo) would make AddURI reject the
+// whole magnet, so it is filtered out before it ever reaches one.
+func IsTrackerURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "udp", "http", "https":
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseTrackerList reads a trackers-list file in the format the public lists use: one
+// announce URL per line, blank lines between them. Comments (#) and invalid URLs are
+// skipped, and duplicates are collapsed keeping the first occurrence — the lists are ranked,
+// so the order carries information.
+func ParseTrackerList(text string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || !IsTrackerURL(line) || seen[line] {
+			continue
+		}
+		seen[line] = true
+		out = append(out, line)
+	}
+	return out
+}
+
+// WithTrackers appends a tr= param to the magnet for every tracker it does not list yet.
+//
+// The params are appended to the raw string instead of re-encoding the parsed query: a
+// round-trip through url.Values sorts the keys and re-escapes every value, and the magnet
+// that reaches rain should be the one Nyaa published plus the extra trackers, nothing else.
+// An unparseable magnet is returned untouched — Add reports the error with the original text.
+func WithTrackers(magnet string, trackers []string) string {
+	if len(trackers) == 0 {
+		return magnet
+	}
+	u, err := url.Parse(magnet)
+	if err != nil || u.Scheme != "magnet" {
+		return magnet
+	}
+	present := make(map[string]bool)
+	for _, tr := range u.Query()["tr"] {
+		present[tr] = true
+	}
+
+	var b strings.Builder
+	b.WriteString(magnet)
+	sep := "&"
+	if !strings.Contains(magnet, "?") {
+		sep = "?"
+	}
+	for _, tr := range trackers {
+		if present[tr] || !IsTrackerURL(tr) {
+			continue
+		}
+		present[tr] = true
+		b.WriteString(sep + "tr=" + url.QueryEscape(tr))
+		sep = "&"
+	}
+	return b.String()
+}
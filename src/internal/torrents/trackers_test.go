@@ -0,0 +1,80 @@
+package torrents
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseTrackerListSkipsCommentsInvalidAndDuplicates(t *testing.T) {
+	text := "# best trackers\n" +
+		"udp://tracker.opentrackr.org:1337/announce\n" +
+		"\n" +
+		"http://tracker.example.com:80/announce\n" +
+		"\n" +
+		"wss://tracker.webtorrent.dev\n" +
+		"not a url\n" +
+		"udp://tracker.opentrackr.org:1337/announce\n" +
+		"  https://tracker.example.org/announce  \r\n"
+
+	got := ParseTrackerList(text)
+	want := []string{
+		"udp://tracker.opentrackr.org:1337/announce",
+		"http://tracker.example.com:80/announce",
+		"https://tracker.example.org/announce",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTrackerList() = %v, want %v", got, want)
+	}
+}
+
+func TestWithTrackersAppendsOnlyMissingTrackers(t *testing.T) {
+	magnet := testMagnet + "&dn=Some+Anime&tr=udp%3A%2F%2Fold.tracker%3A1337%2Fannounce"
+
+	got := WithTrackers(magnet, []string{
+		"udp://old.tracker:1337/announce",
+		"udp://tracker.opentrackr.org:1337/announce",
+		"wss://tracker.webtorrent.dev",
+		"udp://tracker.opentrackr.org:1337/announce",
+	})
+
+	// The original text is kept byte for byte; only the new tracker is appended.
+	want := magnet + "&tr=" + url.QueryEscape("udp://tracker.opentrackr.org:1337/announce")
+	if got != want {
+		t.Errorf("WithTrackers() = %q, want %q", got, want)
+	}
+	hash, err := parseInfoHash(got)
+	if err != nil || hash != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("parseInfoHash(WithTrackers()) = %q, %v", hash, err)
+	}
+}
+
+func TestWithTrackersLeavesInvalidMagnetUntouched(t *testing.T) {
+	for _, magnet := range []string{"", "http://example.com/file.torrent", "%zz"} {
+		if got := WithTrackers(magnet, []string{"udp://tracker.opentrackr.org:1337/announce"}); got != magnet {
+			t.Errorf("WithTrackers(%q) = %q, want it untouched", magnet, got)
+		}
+	}
+}
+
+func TestFakeBackendAddExtraTrackersSkipsPresentOnes(t *testing.T) {
+	f := NewFakeBackend()
+	f.SetExtraTrackers([]string{"udp://a.tracker:1337/announce"})
+	hash, _ := f.Add(testMagnet)
+
+	f.SetExtraTrackers([]string{"udp://a.tracker:1337/announce", "udp://b.tracker:1337/announce"})
+	added, err := f.AddExtraTrackers(hash)
+	if err != nil {
+		t.Fatalf("AddExtraTrackers failed: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("added = %d, want 1", added)
+	}
+	if again, _ := f.AddExtraTrackers(hash); again != 0 {
+		t.Errorf("second call added = %d, want 0", again)
+	}
+	trackers, _ := f.Trackers(hash)
+	if len(trackers) != 2 {
+		t.Errorf("Trackers() = %v, want 2 entries", trackers)
+	}
+}
@@ -0,0 +1,369 @@
+package torrents
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// transmissionSessionHeader carries Transmission's CSRF token. Any call without the current
+// one is answered 409 with the token in this header, and the call is simply sent again.
+const transmissionSessionHeader = "X-Transmission-Session-Id"
+
+// transmissionFields is what torrent-get asks for: everything TorrentInfo is built from.
+var transmissionFields = []string{
+	"hashString", "name", "downloadDir", "status", "labels", "error", "errorString",
+	"percentDone", "metadataPercentComplete", "leftUntilDone", "sizeWhenDone", "haveValid",
+	"downloadedEver", "uploadedEver", "rateDownload", "rateUpload", "peersConnected", "eta",
+	"addedDate", "secondsSeeding", "queuePosition",
+}
+
+// Transmission's torrent status enum.
+const (
+	trStopped = iota
+	trCheckWait
+	trCheck
+	trDownloadWait
+	trDownload
+	trSeedWait
+	trSeed
+)
+
+// trLocalError is the error code of a torrent Transmission stopped on a local problem (disk
+// full, files gone). Codes 1 and 2 are tracker warnings and errors, which do not stop it.
+const trLocalError = 3
+
+// transmissionClient speaks Transmission's JSON RPC. The category is a torrent label, which
+// Transmission supports since 3.0.
+type transmissionClient struct {
+	url      string
+	username string
+	password string
+	label    string
+	http     *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+func newTransmissionClient(opts RemoteOptions) *transmissionClient {
+	return &transmissionClient{
+		url:      opts.URL,
+		username: opts.Username,
+		password: opts.Password,
+		label:    opts.Category,
+		http:     &http.Client{Timeout: remoteHTTPTimeout},
+	}
+}
+
+// rpc sends one call and decodes its arguments into out (when not nil). A 409 refreshes the
+// session id and resends once.
+func (c *transmissionClient) rpc(method string, args any, out any) error {
+	payload, err := json.Marshal(map[string]any{"method": method, "arguments": args})
+	if err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.username != "" {
+			req.SetBasicAuth(c.username, c.password)
+		}
+		c.mu.Lock()
+		if c.sessionID != "" {
+			req.Header.Set(transmissionSessionHeader, c.sessionID)
+		}
+		c.mu.Unlock()
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusConflict && attempt == 0 {
+			c.mu.Lock()
+			c.sessionID = resp.Header.Get(transmissionSessionHeader)
+			c.mu.Unlock()
+			continue
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("transmission rejected the credentials for user %q", c.username)
+		}
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("transmission %s answered %s", method, resp.Status)
+		}
+
+		var envelope struct {
+			Result    string          `json:"result"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return fmt.Errorf("failed to parse transmission %s response: %w", method, err)
+		}
+		if envelope.Result != "success" {
+			return fmt.Errorf("transmission %s: %s", method, envelope.Result)
+		}
+		if out != nil && len(envelope.Arguments) > 0 {
+			if err := json.Unmarshal(envelope.Arguments, out); err != nil {
+				return fmt.Errorf("failed to parse transmission %s arguments: %w", method, err)
+			}
+		}
+		return nil
+	}
+}
+
+// connect does the session-id handshake (through any call) and checks the credentials.
+// Labels need no setup.
+func (c *transmissionClient) connect() error {
+	return c.rpc("session-get", map[string]any{"fields": []string{"version"}}, nil)
+}
+
+func (c *transmissionClient) add(magnet, savePath string) error {
+	return c.rpc("torrent-add", map[string]any{
+		"filename":     magnet,
+		"download-dir": savePath,
+		"labels":       []string{c.label},
+		"paused":       false,
+	}, nil)
+}
+
+type transmissionTorrent struct {
+	HashString              string   `json:"hashString"`
+	Name                    string   `json:"name"`
+	DownloadDir             string   `json:"downloadDir"`
+	Status                  int      `json:"status"`
+	Labels                  []string `json:"labels"`
+	Error                   int      `json:"error"`
+	ErrorString             string   `json:"errorString"`
+	PercentDone             float64  `json:"percentDone"`
+	MetadataPercentComplete float64  `json:"metadataPercentComplete"`
+	LeftUntilDone           int64    `json:"leftUntilDone"`
+	SizeWhenDone            int64    `json:"sizeWhenDone"`
+	HaveValid               int64    `json:"haveValid"`
+	DownloadedEver          int64    `json:"downloadedEver"`
+	UploadedEver            int64    `json:"uploadedEver"`
+	RateDownload            int      `json:"rateDownload"`
+	RateUpload              int      `json:"rateUpload"`
+	PeersConnected          int      `json:"peersConnected"`
+	ETA                     int64    `json:"eta"`
+	AddedDate               int64    `json:"addedDate"`
+	SecondsSeeding          int64    `json:"secondsSeeding"`
+	QueuePosition           int      `json:"queuePosition"`
+}
+
+func (c *transmissionClient) get(fields []string, hashes []string) ([]transmissionTorrent, error) {
+	args := map[string]any{"fields": fields}
+	if len(hashes) > 0 {
+		args["ids"] = hashes
+	}
+	var out struct {
+		Torrents []transmissionTorrent `json:"torrents"`
+	}
+	if err := c.rpc("torrent-get", args, &out); err != nil {
+		return nil, err
+	}
+	return out.Torrents, nil
+}
+
+// torrents filters by label on this side: torrent-get has no filter besides ids.
+func (c *transmissionClient) torrents(hashes ...string) ([]remoteTorrent, error) {
+	raw, err := c.get(transmissionFields, hashes)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]remoteTorrent, 0, len(raw))
+	for _, t := range raw {
+		if !slices.Contains(t.Labels, c.label) {
+			continue
+		}
+		out = append(out, t.toRemote())
+	}
+	return out, nil
+}
+
+func (t transmissionTorrent) toRemote() remoteTorrent {
+	hasMetadata := t.MetadataPercentComplete >= 1
+	info := TorrentInfo{
+		Hash: strings.ToLower(t.HashString),
+		Name: t.Name,
+		// O name e a raiz do conteudo: a pasta num torrent de varios arquivos, o proprio
+		// arquivo num de um so. O separador e o do servidor, nao o daqui.
+		DataDir:          path.Join(t.DownloadDir, t.Name),
+		Completed:        hasMetadata && t.SizeWhenDone > 0 && t.LeftUntilDone == 0,
+		Status:           transmissionStatusSlug(t.Status, hasMetadata),
+		BytesCompleted:   t.HaveValid,
+		BytesTotal:       t.SizeWhenDone,
+		BytesDownloaded:  t.DownloadedEver,
+		BytesUploaded:    t.UploadedEver,
+		DownloadSpeed:    t.RateDownload,
+		UploadSpeed:      t.RateUpload,
+		PeersTotal:       t.PeersConnected,
+		SeededForSeconds: t.SecondsSeeding,
+		AddedAt:          time.Unix(t.AddedDate, 0),
+	}
+	if !hasMetadata {
+		info.BytesTotal = 0
+	}
+	// -1 = sem estimativa, -2 = desconhecido.
+	if t.ETA >= 0 && !info.Completed {
+		eta := t.ETA
+		info.ETASeconds = &eta
+	}
+	if t.Status == trDownloadWait {
+		info.QueuePosition = t.QueuePosition + 1
+	}
+	rt := remoteTorrent{info: info}
+	if t.Error == trLocalError {
+		rt.failure = t.ErrorString
+	}
+	return rt
+}
+
+// transmissionStatusSlug maps Transmission's status to the API slug statusSlug uses for rain.
+// Seed-wait (Transmission's own seeding queue) reads as seeding: the daemon never limits
+// seeding, and "queued" would suggest a download slot it is waiting for.
+func transmissionStatusSlug(status int, hasMetadata bool) string {
+	switch status {
+	case trStopped:
+		return StatusStopped
+	case trCheckWait, trCheck:
+		return "verifying"
+	case trDownloadWait:
+		return StatusQueued
+	case trDownload:
+		if !hasMetadata {
+			return "downloading_metadata"
+		}
+		return "downloading"
+	case trSeedWait, trSeed:
+		return "seeding"
+	default:
+		return "unknown"
+	}
+}
+
+func (c *transmissionClient) ids(hash string) map[string]any {
+	return map[string]any{"ids": []string{hash}}
+}
+
+func (c *transmissionClient) remove(hash string, deleteData bool) error {
+	return c.rpc("torrent-remove", map[string]any{"ids": []string{hash}, "delete-local-data": deleteData}, nil)
+}
+
+func (c *transmissionClient) stop(hash string) error {
+	return c.rpc("torrent-stop", c.ids(hash), nil)
+}
+
+func (c *transmissionClient) start(hash string) error {
+	return c.rpc("torrent-start", c.ids(hash), nil)
+}
+
+func (c *transmissionClient) reannounce(hash string) error {
+	return c.rpc("torrent-reannounce", c.ids(hash), nil)
+}
+
+type transmissionTrackerStat struct {
+	Announce              string `json:"announce"`
+	AnnounceState         int    `json:"announceState"`
+	HasAnnounced          bool   `json:"hasAnnounced"`
+	LastAnnounceSucceeded bool   `json:"lastAnnounceSucceeded"`
+	LastAnnounceResult    string `json:"lastAnnounceResult"`
+	LastAnnounceTime      int64  `json:"lastAnnounceTime"`
+	NextAnnounceTime      int64  `json:"nextAnnounceTime"`
+	SeederCount           int    `json:"seederCount"`
+	LeecherCount          int    `json:"leecherCount"`
+}
+
+func (c *transmissionClient) trackers(hash string) ([]TrackerInfo, error) {
+	var out struct {
+		Torrents []struct {
+			TrackerStats []transmissionTrackerStat `json:"trackerStats"`
+		} `json:"torrents"`
+	}
+	if err := c.rpc("torrent-get", map[string]any{"fields": []string{"trackerStats"}, "ids": []string{hash}}, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Torrents) == 0 {
+		return nil, fmt.Errorf("torrent %s not found", hash)
+	}
+	stats := out.Torrents[0].TrackerStats
+	trackers := make([]TrackerInfo, 0, len(stats))
+	for _, st := range stats {
+		info := TrackerInfo{
+			URL:      st.Announce,
+			Seeders:  max(st.SeederCount, 0),
+			Leechers: max(st.LeecherCount, 0),
+		}
+		// announceState 3 = anunciando agora.
+		switch {
+		case st.AnnounceState == 3:
+			info.Status = "contacting"
+		case !st.HasAnnounced:
+			info.Status = "not_contacted"
+		case st.LastAnnounceSucceeded:
+			info.Status = "working"
+		default:
+			info.Status = "not_working"
+			info.Error = st.LastAnnounceResult
+		}
+		if st.LastAnnounceTime > 0 {
+			info.LastAnnounce = time.Unix(st.LastAnnounceTime, 0)
+		}
+		if st.NextAnnounceTime > 0 {
+			info.NextAnnounce = time.Unix(st.NextAnnounceTime, 0)
+		}
+		trackers = append(trackers, info)
+	}
+	return trackers, nil
+}
+
+// addTrackers uses trackerAdd, which Transmission 4 still accepts alongside trackerList.
+func (c *transmissionClient) addTrackers(hash string, urls []string) error {
+	return c.rpc("torrent-set", map[string]any{"ids": []string{hash}, "trackerAdd": urls}, nil)
+}
+
+func (c *transmissionClient) recheck(hash string) error {
+	return c.rpc("torrent-verify", c.ids(hash), nil)
+}
+
+// pieces counts the bits of the "pieces" bitfield (base64).
+func (c *transmissionClient) pieces(hash string) (uint32, uint32, error) {
+	var out struct {
+		Torrents []struct {
+			Pieces     string `json:"pieces"`
+			PieceCount int64  `json:"pieceCount"`
+		} `json:"torrents"`
+	}
+	if err := c.rpc("torrent-get", map[string]any{"fields": []string{"pieces", "pieceCount"}, "ids": []string{hash}}, &out); err != nil {
+		return 0, 0, err
+	}
+	if len(out.Torrents) == 0 {
+		return 0, 0, fmt.Errorf("torrent %s not found", hash)
+	}
+	t := out.Torrents[0]
+	bitfield, err := base64.StdEncoding.DecodeString(t.Pieces)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decode transmission pieces bitfield: %w", err)
+	}
+	have := 0
+	for _, b := range bitfield {
+		have += bits.OnesCount8(b)
+	}
+	return uint32(have), uint32(max(t.PieceCount, 0)), nil
+}
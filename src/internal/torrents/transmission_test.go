@@ -0,0 +1,350 @@
+package torrents
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+const fakeTransmissionSession = "tr-session-1"
+
+// fakeTransmission is an in-process Transmission RPC endpoint: the session-id handshake, basic
+// auth and the methods RemoteBackend calls, over an in-memory torrent map.
+type fakeTransmission struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu         sync.Mutex
+	handshakes int
+	torrents   map[string]*fakeTrTorrent
+	// removed records delete-local-data of each torrent-remove, by hash.
+	removed map[string]bool
+	calls   map[string]int
+}
+
+type fakeTrTorrent struct {
+	transmissionTorrent
+	TrackerStats []transmissionTrackerStat `json:"trackerStats"`
+	Pieces       string                    `json:"pieces"`
+	PieceCount   int64                     `json:"pieceCount"`
+
+	// afterVerify is the status torrent-verify returns to once one torrent-get saw it checking.
+	afterVerify     int
+	verifying       bool
+	piecesAfterScan string
+}
+
+func newFakeTransmission(t *testing.T) *fakeTransmission {
+	t.Helper()
+	f := &fakeTransmission{t: t, torrents: map[string]*fakeTrTorrent{}, removed: map[string]bool{}, calls: map[string]int{}}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func (f *fakeTransmission) handle(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get(transmissionSessionHeader) != fakeTransmissionSession {
+		f.handshakes++
+		w.Header().Set(transmissionSessionHeader, fakeTransmissionSession)
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	var req struct {
+		Method    string `json:"method"`
+		Arguments struct {
+			IDs         []string `json:"ids"`
+			Filename    string   `json:"filename"`
+			DownloadDir string   `json:"download-dir"`
+			Labels      []string `json:"labels"`
+			DeleteData  bool     `json:"delete-local-data"`
+			TrackerAdd  []string `json:"trackerAdd"`
+		} `json:"arguments"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		f.t.Errorf("bad rpc body: %v", err)
+		return
+	}
+	f.calls[req.Method]++
+	args := req.Arguments
+	var result any
+	each := func(fn func(t *fakeTrTorrent)) {
+		for _, id := range args.IDs {
+			if t := f.torrents[id]; t != nil {
+				fn(t)
+			}
+		}
+	}
+
+	switch req.Method {
+	case "session-get":
+	case "torrent-add":
+		hash, err := parseInfoHash(args.Filename)
+		if err != nil {
+			f.t.Errorf("torrent-add with a bad magnet: %v", err)
+			break
+		}
+		f.torrents[hash] = &fakeTrTorrent{transmissionTorrent: transmissionTorrent{
+			HashString:  hash,
+			Name:        "Show - 01.mkv",
+			DownloadDir: args.DownloadDir,
+			Labels:      args.Labels,
+			Status:      trDownload,
+			ETA:         -1,
+		}}
+	case "torrent-get":
+		out := []fakeTrTorrent{}
+		for id, t := range f.torrents {
+			if len(args.IDs) > 0 && !slices.Contains(args.IDs, id) {
+				continue
+			}
+			if t.verifying && t.Status == trCheck {
+				// One read shows the check running; the next one sees it done.
+				out = append(out, *t)
+				t.Status, t.verifying, t.Pieces = t.afterVerify, false, t.piecesAfterScan
+				continue
+			}
+			out = append(out, *t)
+		}
+		result = map[string]any{"torrents": out}
+	case "torrent-remove":
+		each(func(t *fakeTrTorrent) {
+			delete(f.torrents, t.HashString)
+			f.removed[t.HashString] = args.DeleteData
+		})
+	case "torrent-stop":
+		each(func(t *fakeTrTorrent) { t.Status = trStopped })
+	case "torrent-start":
+		each(func(t *fakeTrTorrent) { t.Status = trDownload })
+	case "torrent-set":
+		each(func(t *fakeTrTorrent) {
+			for _, u := range args.TrackerAdd {
+				t.TrackerStats = append(t.TrackerStats, transmissionTrackerStat{Announce: u})
+			}
+		})
+	case "torrent-reannounce":
+	case "torrent-verify":
+		each(func(t *fakeTrTorrent) {
+			t.afterVerify, t.verifying, t.Status = t.Status, true, trCheck
+		})
+	default:
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": "method name not recognized"})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": "success", "arguments": result})
+}
+
+func (f *fakeTransmission) set(hash string, edit func(t *fakeTrTorrent)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	edit(f.torrents[hash])
+}
+
+func newTransmissionBackend(t *testing.T, f *fakeTransmission, local string) *RemoteBackend {
+	t.Helper()
+	b, err := NewRemoteBackend(RemoteOptions{
+		Client:         ClientTransmission,
+		URL:            f.srv.URL,
+		Username:       "admin",
+		Password:       "secret",
+		Category:       "anime",
+		RemoteSavePath: "/downloads",
+	}, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewRemoteBackend: %v", err)
+	}
+	t.Cleanup(func() { _ = b.Close() })
+	if _, err := b.Ensure(local); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	return b
+}
+
+const trTestMagnet = "magnet:?xt=urn:btih:89abcdef0123456789abcdef0123456789abcdef&dn=ep"
+const trTestHash = "89abcdef0123456789abcdef0123456789abcdef"
+
+func TestTransmissionBackendAddListRemove(t *testing.T) {
+	f := newFakeTransmission(t)
+	local := filepath.Join(t.TempDir(), "library", ".torrents")
+	b := newTransmissionBackend(t, f, local)
+	if f.handshakes != 1 {
+		t.Errorf("handshakes = %d, want 1 (the first call learns the session id)", f.handshakes)
+	}
+
+	hash, err := b.Add(trTestMagnet)
+	if err != nil || hash != trTestHash {
+		t.Fatalf("Add = (%s, %v)", hash, err)
+	}
+	added := f.torrents[hash]
+	if added.DownloadDir != "/downloads" || !slices.Contains(added.Labels, "anime") {
+		t.Errorf("added with download-dir %q, labels %v; want /downloads, [anime]", added.DownloadDir, added.Labels)
+	}
+
+	// Unlabelled torrents are the user's own and stay invisible.
+	f.mu.Lock()
+	f.torrents["ffffffffffffffffffffffffffffffffffffffff"] = &fakeTrTorrent{transmissionTorrent: transmissionTorrent{HashString: "ffffffffffffffffffffffffffffffffffffffff"}}
+	f.mu.Unlock()
+
+	list := b.List()
+	if len(list) != 1 || list[0].Hash != hash {
+		t.Fatalf("List = %+v, want only %s", list, hash)
+	}
+	if want := filepath.Join(local, "Show - 01.mkv"); list[0].DataDir != want {
+		t.Errorf("DataDir = %q, want %q", list[0].DataDir, want)
+	}
+	// Without metadata the torrent is still fetching it.
+	if list[0].Status != "downloading_metadata" {
+		t.Errorf("status = %s, want downloading_metadata", list[0].Status)
+	}
+
+	if err := b.Pause(hash); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if got, _ := b.Get(hash); got.Status != StatusStopped {
+		t.Errorf("status after Pause = %s, want stopped", got.Status)
+	}
+	if err := b.Resume(hash); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+
+	if err := b.Remove(hash, true); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if deleted, ok := f.removed[hash]; !ok || deleted {
+		t.Errorf("Remove(keepData=true): removed=%v delete-local-data=%v, want removed without data", ok, deleted)
+	}
+}
+
+func TestTransmissionPollDrivesCallbacks(t *testing.T) {
+	f := newFakeTransmission(t)
+	b := newTransmissionBackend(t, f, t.TempDir())
+	var completed, failed []string
+	b.SetCallbacks(
+		func(hash string) { completed = append(completed, hash) },
+		func(hash string, err error) { failed = append(failed, hash) },
+	)
+	hash, err := b.Add(trTestMagnet)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	b.poll()
+	f.set(hash, func(t *fakeTrTorrent) {
+		t.MetadataPercentComplete, t.SizeWhenDone, t.LeftUntilDone, t.Status = 1, 1000, 0, trSeed
+	})
+	b.poll()
+	b.poll()
+	if len(completed) != 1 {
+		t.Errorf("completed = %v, want one call", completed)
+	}
+
+	// Tracker errors (codes 1 and 2) do not stop a torrent; a local error does.
+	f.set(hash, func(t *fakeTrTorrent) { t.Error, t.ErrorString = 2, "tracker gone" })
+	b.poll()
+	if len(failed) != 0 {
+		t.Fatalf("a tracker error fired onFailed: %v", failed)
+	}
+	f.set(hash, func(t *fakeTrTorrent) { t.Error, t.ErrorString, t.Status = trLocalError, "No data found", trStopped })
+	b.poll()
+	b.poll()
+	if len(failed) != 1 {
+		t.Errorf("failed = %v, want one call", failed)
+	}
+}
+
+func TestTransmissionExtraTrackersAndStatus(t *testing.T) {
+	f := newFakeTransmission(t)
+	b := newTransmissionBackend(t, f, t.TempDir())
+	hash, err := b.Add(trTestMagnet)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	f.set(hash, func(t *fakeTrTorrent) {
+		t.TrackerStats = []transmissionTrackerStat{
+			{Announce: "udp://a.example/announce", HasAnnounced: true, LastAnnounceSucceeded: true, SeederCount: 7, LeecherCount: -1},
+			{Announce: "udp://b.example/announce", HasAnnounced: true, LastAnnounceResult: "Connection failed"},
+		}
+	})
+
+	b.SetExtraTrackers([]string{"udp://a.example/announce", "udp://c.example/announce"})
+	added, err := b.AddExtraTrackers(hash)
+	if err != nil || added != 1 {
+		t.Fatalf("AddExtraTrackers = (%d, %v), want (1, nil): the first one is already there", added, err)
+	}
+	if f.calls["torrent-reannounce"] != 1 {
+		t.Errorf("reannounce calls = %d, want 1", f.calls["torrent-reannounce"])
+	}
+
+	trackers, err := b.Trackers(hash)
+	if err != nil || len(trackers) != 3 {
+		t.Fatalf("Trackers = (%+v, %v), want 3", trackers, err)
+	}
+	if trackers[0].Status != "working" || trackers[0].Seeders != 7 || trackers[0].Leechers != 0 {
+		t.Errorf("tracker a = %+v, want working with 7 seeders and 0 leechers", trackers[0])
+	}
+	if trackers[1].Status != "not_working" || trackers[1].Error != "Connection failed" {
+		t.Errorf("tracker b = %+v, want not_working with the announce error", trackers[1])
+	}
+	if trackers[2].Status != "not_contacted" {
+		t.Errorf("tracker c = %+v, want not_contacted", trackers[2])
+	}
+}
+
+// A seeder whose data went bad: the check finds fewer pieces and the torrent goes back to
+// the front of the download queue.
+func TestTransmissionRecheckCountsFailedPieces(t *testing.T) {
+	f := newFakeTransmission(t)
+	b := newTransmissionBackend(t, f, t.TempDir())
+	hash, err := b.Add(trTestMagnet)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	f.set(hash, func(t *fakeTrTorrent) {
+		t.MetadataPercentComplete, t.SizeWhenDone, t.LeftUntilDone, t.Status = 1, 1000, 0, trSeed
+		t.PieceCount = 16
+		t.Pieces = base64.StdEncoding.EncodeToString([]byte{0xff, 0xff})
+		t.piecesAfterScan = base64.StdEncoding.EncodeToString([]byte{0xff, 0x0f})
+	})
+
+	res, err := b.Recheck(hash)
+	if err != nil {
+		t.Fatalf("Recheck: %v", err)
+	}
+	want := RecheckResult{PiecesTotal: 16, PiecesHave: 12, PiecesFailed: 4, Completed: false}
+	if res != want {
+		t.Errorf("Recheck = %+v, want %+v", res, want)
+	}
+	if f.calls["torrent-verify"] != 1 {
+		t.Errorf("torrent-verify calls = %d, want 1", f.calls["torrent-verify"])
+	}
+}
+
+func TestTransmissionStatusSlug(t *testing.T) {
+	cases := []struct {
+		status      int
+		hasMetadata bool
+		want        string
+	}{
+		{trStopped, true, StatusStopped},
+		{trCheckWait, true, "verifying"},
+		{trDownloadWait, true, StatusQueued},
+		{trDownload, false, "downloading_metadata"},
+		{trDownload, true, "downloading"},
+		{trSeedWait, true, "seeding"},
+		{trSeed, true, "seeding"},
+	}
+	for _, c := range cases {
+		if got := transmissionStatusSlug(c.status, c.hasMetadata); got != c.want {
+			t.Errorf("transmissionStatusSlug(%d, %v) = %q, want %q", c.status, c.hasMetadata, got, c.want)
+		}
+	}
+}
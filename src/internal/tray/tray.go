@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"os/exec"
 	"runtime"
+	"strings"
 	"time"
 
 	"fyne.io/systray"
@@ -76,6 +77,14 @@ func (tm *TrayManager) onReady() {
 
 	systray.AddSeparator()
 
+	mPauseAll := systray.AddMenuItem("Pause all downloads", "Pauses every torrent, downloading and seeding")
+	mPauseUntilResumed := mPauseAll.AddSubMenuItem("Until resumed", "Pauses until Resume all downloads")
+	mPause1h := mPauseAll.AddSubMenuItem("For 1 hour", "Pauses and resumes on its own after 1 hour")
+	mPause2h := mPauseAll.AddSubMenuItem("For 2 hours", "Pauses and resumes on its own after 2 hours")
+	mResumeAll := systray.AddMenuItem("Resume all downloads", "Resumes the torrents paused by Pause all downloads")
+
+	systray.AddSeparator()
+
 	mQuit := systray.AddMenuItem("Quit", "Shuts the app down")
 
 	// Handle menu events
@@ -86,6 +95,14 @@ func (tm *TrayManager) onReady() {
 				tm.openWebUI()
 			case <-mCheckEpisodes.ClickedCh:
 				tm.triggerCheck()
+			case <-mPauseUntilResumed.ClickedCh:
+				tm.pauseAll(0)
+			case <-mPause1h.ClickedCh:
+				tm.pauseAll(60)
+			case <-mPause2h.ClickedCh:
+				tm.pauseAll(120)
+			case <-mResumeAll.ClickedCh:
+				tm.postAction("/api/v1/torrents/resume-all", "", "resume all torrents")
 			case <-mQuit.ClickedCh:
 				systray.Quit()
 				return
@@ -120,18 +137,33 @@ func (tm *TrayManager) getWebUiURL() string {
 
 func (tm *TrayManager) triggerCheck() {
 	logger.Logger.Info().Msg("Triggering manual episode check via tray icon")
+	tm.postAction("/api/v1/check", "", "trigger check")
+}
+
+// pauseAll pauses every torrent; minutes 0 = until resumed.
+func (tm *TrayManager) pauseAll(minutes int) {
+	logger.Logger.Info().Int("minutes", minutes).Msg("Pausing all torrents via tray icon")
+	body := ""
+	if minutes > 0 {
+		body = fmt.Sprintf(`{"duration_minutes":%d}`, minutes)
+	}
+	tm.postAction("/api/v1/torrents/pause-all", body, "pause all torrents")
+}
 
+// postAction POSTs to the daemon's own API in the background and logs the outcome; what is
+// the action's description in the log lines.
+func (tm *TrayManager) postAction(path, body, what string) {
 	go func() {
-		url := fmt.Sprintf("%s/api/v1/check", tm.getWebUiURL())
+		url := tm.getWebUiURL() + path
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+		req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(body))
 		if err != nil {
 			logger.Logger.Error().
 				Err(err).
-				Msg("Failed to create check request")
+				Msgf("Failed to create request to %s", what)
 			return
 		}
 
@@ -142,17 +174,17 @@ func (tm *TrayManager) triggerCheck() {
 		if err != nil {
 			logger.Logger.Error().
 				Err(err).
-				Msg("Failed to trigger check")
+				Msgf("Failed to %s", what)
 			return
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode == http.StatusOK {
-			logger.Logger.Info().Msg("Manual check triggered successfully")
+			logger.Logger.Info().Msgf("Tray action succeeded: %s", what)
 		} else {
 			logger.Logger.Error().
 				Int("status", resp.StatusCode).
-				Msg("Failed to trigger check")
+				Msgf("Failed to %s", what)
 		}
 	}()
 }
@@ -478,6 +478,26 @@ func (m *mockFileSystemForDaemon) Link(oldname, newname string) error {
 	return nil
 }
 
+func (m *mockFileSystemForDaemon) Symlink(oldname, newname string) error {
+	return nil
+}
+
+func (m *mockFileSystemForDaemon) Readlink(name string) (string, error) {
+	return "", nil
+}
+
+func (m *mockFileSystemForDaemon) Reflink(oldname, newname string) error {
+	return nil
+}
+
+func (m *mockFileSystemForDaemon) CopyFile(oldname, newname string) error {
+	return nil
+}
+
+func (m *mockFileSystemForDaemon) Lstat(filename string) (fs.FileInfo, error) {
+	return m.Stat(filename)
+}
+
 func (m *mockFileSystemForDaemon) Mkdir(dirname string, perm fs.FileMode) error {
 	return nil
 }
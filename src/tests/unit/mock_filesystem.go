@@ -267,6 +267,28 @@ func (m *MockFileSystem) Link(oldname, newname string) error {
 	return nil
 }
 
+// Symlink, Reflink e CopyFile: no mock todo modo de LinkMode e so "o nome novo tem os bytes".
+func (m *MockFileSystem) Symlink(oldname, newname string) error {
+	return m.Link(oldname, newname)
+}
+
+func (m *MockFileSystem) Readlink(name string) (string, error) {
+	// O mock nao tem symlink: todo LinkMode vira "o nome novo tem os bytes".
+	return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+}
+
+func (m *MockFileSystem) Reflink(oldname, newname string) error {
+	return m.Link(oldname, newname)
+}
+
+func (m *MockFileSystem) CopyFile(oldname, newname string) error {
+	return m.Link(oldname, newname)
+}
+
+func (m *MockFileSystem) Lstat(filename string) (fs.FileInfo, error) {
+	return m.Stat(filename)
+}
+
 func (m *MockFileSystem) Mkdir(dirname string, perm fs.FileMode) error {
 	if m.writeError != nil {
 		return m.writeError